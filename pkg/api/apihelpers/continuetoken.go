@@ -0,0 +1,34 @@
+package apihelpers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// EncodeContinueToken returns an opaque continue token for the given list
+// position key.  It is used by virtual list endpoints that materialize their
+// items from other stored resources and therefore cannot rely on the storage
+// layer's continue tokens.
+func EncodeContinueToken(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// ContinueTokenKey joins the fields identifying a list position into a single
+// position key.  The fields are separated by a NUL byte, which cannot appear
+// in object names, so comparing keys compares the fields positionally: a name
+// that is a prefix of another never sorts past it, and fields from different
+// positions are never conflated.
+func ContinueTokenKey(fields ...string) string {
+	return strings.Join(fields, "\x00")
+}
+
+// DecodeContinueToken returns the list position key carried by a token
+// produced by EncodeContinueToken.
+func DecodeContinueToken(token string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", fmt.Errorf("continue token is not valid: %v", err)
+	}
+	return string(key), nil
+}
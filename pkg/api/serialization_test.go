@@ -264,6 +264,11 @@ func originFuzzer(t *testing.T, seed int64) *fuzz.Fuzzer {
 			c.FuzzNoCustom(j)
 			j.RunPolicy = build.BuildRunPolicySerial
 		},
+		func(j *build.BuildVolumeSource, c fuzz.Continue) {
+			c.FuzzNoCustom(j)
+			// the v1 API cannot carry projected sources yet
+			j.Projected = nil
+		},
 		func(j *build.SourceBuildStrategy, c fuzz.Continue) {
 			c.FuzzNoCustom(j)
 			j.From.Kind = "ImageStreamTag"
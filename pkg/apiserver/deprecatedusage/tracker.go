@@ -0,0 +1,116 @@
+package deprecatedusage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	apiserverfilters "github.com/openshift/openshift-apiserver/pkg/apiserver/filters"
+)
+
+// Signals name the deprecated API surfaces whose use is counted. The values
+// appear verbatim in the deprecated usage report, so they spell out the
+// resource and field or URL form a consumer has to move away from.
+const (
+	// SignalImageStreamSpecDockerImageRepository counts writes that set the
+	// deprecated spec.dockerImageRepository field of an image stream.
+	SignalImageStreamSpecDockerImageRepository = "imagestreams/spec.dockerImageRepository"
+	// SignalBuildConfigWebHookLegacyURL counts webhook invocations
+	// authenticated by the deprecated inline trigger secret embedded in the
+	// webhook URL instead of a secret reference.
+	SignalBuildConfigWebHookLegacyURL = "buildconfigs/webhooks legacy inline-secret URL"
+	// SignalBuildConfigSpecLastTriggeredImageID counts writes that set the
+	// deprecated spec.triggers.imageChange.lastTriggeredImageID field of a
+	// build config; status.imageChangeTriggers is authoritative.
+	SignalBuildConfigSpecLastTriggeredImageID = "buildconfigs/spec.triggers.imageChange.lastTriggeredImageID"
+)
+
+// Usage is one aggregated counter of the deprecated usage report: how often
+// one signal was observed from one user agent in one namespace.
+type Usage struct {
+	Signal    string `json:"signal"`
+	UserAgent string `json:"userAgent,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Count     int64  `json:"count"`
+	// LastSeen is the time the signal was last observed.
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+type usageKey struct {
+	signal    string
+	userAgent string
+	namespace string
+}
+
+// Tracker aggregates in-memory counters of deprecated API usage. The counters
+// reset on restart; the report is meant to find remaining consumers, not to be
+// an audit trail. Tracker is safe for concurrent use.
+type Tracker struct {
+	lock   sync.Mutex
+	now    func() time.Time
+	usages map[usageKey]*Usage
+}
+
+// NewTracker returns an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		now:    time.Now,
+		usages: make(map[usageKey]*Usage),
+	}
+}
+
+// Record counts one observation of signal from the given user agent and
+// namespace.
+func (t *Tracker) Record(signal, userAgent, namespace string) {
+	key := usageKey{signal: signal, userAgent: userAgent, namespace: namespace}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	usage, ok := t.usages[key]
+	if !ok {
+		usage = &Usage{Signal: signal, UserAgent: userAgent, Namespace: namespace}
+		t.usages[key] = usage
+	}
+	usage.Count++
+	usage.LastSeen = t.now()
+}
+
+// Snapshot returns the aggregated counters ordered by signal, then by count
+// with the most frequent consumers first.
+func (t *Tracker) Snapshot() []Usage {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	usages := make([]Usage, 0, len(t.usages))
+	for _, usage := range t.usages {
+		usages = append(usages, *usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if a.Signal != b.Signal {
+			return a.Signal < b.Signal
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.UserAgent != b.UserAgent {
+			return a.UserAgent < b.UserAgent
+		}
+		return a.Namespace < b.Namespace
+	})
+	return usages
+}
+
+// DefaultTracker collects the deprecated usage observed by this process. The
+// recording sites live in registry strategies that are package-level values,
+// so the tracker is one as well.
+var DefaultTracker = NewTracker()
+
+// Record counts one observation of signal on the DefaultTracker, attributing
+// it to the user agent and namespace of the request in ctx.
+func Record(ctx context.Context, signal string) {
+	userAgent, _ := apiserverfilters.RequestUserAgentFrom(ctx)
+	DefaultTracker.Record(signal, userAgent, apirequest.NamespaceValue(ctx))
+}
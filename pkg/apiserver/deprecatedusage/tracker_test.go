@@ -0,0 +1,57 @@
+package deprecatedusage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	apiserverfilters "github.com/openshift/openshift-apiserver/pkg/apiserver/filters"
+)
+
+func TestTrackerSnapshot(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return now }
+
+	tracker.Record(SignalImageStreamSpecDockerImageRepository, "oc/4.12", "app")
+	tracker.Record(SignalImageStreamSpecDockerImageRepository, "oc/4.12", "app")
+	tracker.Record(SignalImageStreamSpecDockerImageRepository, "deployer/1.0", "ci")
+	tracker.Record(SignalBuildConfigSpecLastTriggeredImageID, "oc/4.12", "app")
+
+	expected := []Usage{
+		{Signal: SignalBuildConfigSpecLastTriggeredImageID, UserAgent: "oc/4.12", Namespace: "app", Count: 1, LastSeen: now},
+		{Signal: SignalImageStreamSpecDockerImageRepository, UserAgent: "oc/4.12", Namespace: "app", Count: 2, LastSeen: now},
+		{Signal: SignalImageStreamSpecDockerImageRepository, UserAgent: "deployer/1.0", Namespace: "ci", Count: 1, LastSeen: now},
+	}
+	if snapshot := tracker.Snapshot(); !reflect.DeepEqual(snapshot, expected) {
+		t.Errorf("expected %#v, got %#v", expected, snapshot)
+	}
+}
+
+func TestRecordAttributesRequest(t *testing.T) {
+	tracker := NewTracker()
+	defer func(previous *Tracker) { DefaultTracker = previous }(DefaultTracker)
+	DefaultTracker = tracker
+
+	handler := apiserverfilters.WithRequestUserAgent(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := apirequest.WithNamespace(req.Context(), "app")
+		Record(ctx, SignalImageStreamSpecDockerImageRepository)
+	}))
+
+	req := httptest.NewRequest("POST", "/apis/image.openshift.io/v1/namespaces/app/imagestreams", nil)
+	req.Header.Set("User-Agent", "oc/4.12")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	snapshot := tracker.Snapshot()
+	if len(snapshot) != 1 {
+		t.Fatalf("expected one usage, got %#v", snapshot)
+	}
+	usage := snapshot[0]
+	if usage.UserAgent != "oc/4.12" || usage.Namespace != "app" || usage.Count != 1 {
+		t.Errorf("unexpected usage: %#v", usage)
+	}
+}
@@ -0,0 +1,172 @@
+package filters
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	kjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/warning"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	templatev1 "github.com/openshift/api/template/v1"
+)
+
+// resourceKey identifies a request target whose body is re-decoded strictly.
+type resourceKey struct {
+	group       string
+	resource    string
+	subresource string
+}
+
+// validatedKinds maps the resources whose request bodies are checked for
+// unknown and duplicate fields to the versioned kind the body is expected to
+// carry.
+var validatedKinds = map[resourceKey]schema.GroupVersionKind{
+	{group: buildv1.GroupName, resource: "builds", subresource: "clone"}:             buildv1.GroupVersion.WithKind("BuildRequest"),
+	{group: buildv1.GroupName, resource: "buildconfigs", subresource: "instantiate"}: buildv1.GroupVersion.WithKind("BuildRequest"),
+	{group: imagev1.GroupName, resource: "imagestreamimports"}:                       imagev1.GroupVersion.WithKind("ImageStreamImport"),
+	{group: templatev1.GroupName, resource: "processedtemplates"}:                    templatev1.GroupVersion.WithKind("Template"),
+	{group: routev1.GroupName, resource: "routes"}:                                   routev1.GroupVersion.WithKind("Route"),
+}
+
+var (
+	fieldValidationScheme = runtime.NewScheme()
+	fieldValidationCodecs serializer.CodecFactory
+	strictJSONSerializer  runtime.Serializer
+	strictYAMLSerializer  runtime.Serializer
+)
+
+func init() {
+	utilruntime.Must(buildv1.Install(fieldValidationScheme))
+	utilruntime.Must(imagev1.Install(fieldValidationScheme))
+	utilruntime.Must(templatev1.Install(fieldValidationScheme))
+	utilruntime.Must(routev1.Install(fieldValidationScheme))
+	fieldValidationCodecs = serializer.NewCodecFactory(fieldValidationScheme)
+	strictJSONSerializer = kjson.NewSerializerWithOptions(kjson.DefaultMetaFactory, fieldValidationScheme, fieldValidationScheme, kjson.SerializerOptions{Strict: true})
+	strictYAMLSerializer = kjson.NewSerializerWithOptions(kjson.DefaultMetaFactory, fieldValidationScheme, fieldValidationScheme, kjson.SerializerOptions{Yaml: true, Strict: true})
+}
+
+// WithFieldValidation honors the fieldValidation query parameter for the
+// OpenShift resources that are decoded with a lenient serializer, notably the
+// virtual subresources.  The regular create and update paths silently drop
+// unknown fields, so a body with a typo in it is accepted and quietly
+// misinterpreted even when the client asked for strict validation.  For the
+// resources listed in validatedKinds this filter re-decodes the request body
+// with a strict serializer and either rejects the request (Strict) or records
+// a warning on the response (Warn) when the body carries unknown or duplicate
+// fields.
+func WithFieldValidation(handler http.Handler, maxRequestBodyBytes int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestInfo, ok := apirequest.RequestInfoFrom(req.Context())
+		if !ok || !requestInfo.IsResourceRequest {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		if requestInfo.Verb != "create" && requestInfo.Verb != "update" {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		gvk, ok := validatedKinds[resourceKey{group: requestInfo.APIGroup, resource: requestInfo.Resource, subresource: requestInfo.Subresource}]
+		if !ok {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		directive := req.URL.Query().Get("fieldValidation")
+		switch directive {
+		case "", "Ignore":
+			handler.ServeHTTP(w, req)
+			return
+		case "Warn", "Strict":
+		default:
+			err := kapierrors.NewBadRequest(fmt.Sprintf("fieldValidation must be one of Ignore, Warn or Strict, not %q", directive))
+			responsewriters.ErrorNegotiated(err, fieldValidationCodecs, gvk.GroupVersion(), w, req)
+			return
+		}
+
+		strictSerializer, ok := strictSerializerFor(req.Header.Get("Content-Type"))
+		if !ok {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		body, complete, err := bufferRequestBody(req, maxRequestBodyBytes)
+		if err != nil || !complete {
+			// oversized or unreadable bodies are left for the regular
+			// handlers to reject
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		obj, err := fieldValidationScheme.New(gvk)
+		if err != nil {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		if _, _, err := strictSerializer.Decode(body, &gvk, obj); runtime.IsStrictDecodingError(err) {
+			msg := fmt.Sprintf("strict decoding of %s failed: %v", gvk.Kind, err)
+			if directive == "Strict" {
+				responsewriters.ErrorNegotiated(kapierrors.NewBadRequest(msg), fieldValidationCodecs, gvk.GroupVersion(), w, req)
+				return
+			}
+			warning.AddWarning(req.Context(), "", msg)
+		}
+
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// strictSerializerFor returns the strict serializer matching the request
+// content type, or false for media types the filter cannot re-decode.
+func strictSerializerFor(contentType string) (runtime.Serializer, bool) {
+	if len(contentType) == 0 {
+		return strictJSONSerializer, true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	switch mediaType {
+	case "application/json":
+		return strictJSONSerializer, true
+	case "application/yaml":
+		return strictYAMLSerializer, true
+	}
+	return nil, false
+}
+
+// bufferRequestBody reads the request body so it can be inspected, and
+// replaces req.Body so downstream handlers observe the original stream.  It
+// reports false if the body exceeds limit and was only partially read.
+func bufferRequestBody(req *http.Request, limit int64) ([]byte, bool, error) {
+	if req.Body == nil {
+		return nil, true, nil
+	}
+	reader := io.Reader(req.Body)
+	if limit > 0 {
+		reader = io.LimitReader(req.Body, limit+1)
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, false, err
+	}
+	if limit > 0 && int64(len(body)) > limit {
+		req.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+		return nil, false, nil
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, true, nil
+}
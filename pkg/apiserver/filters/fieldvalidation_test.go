@@ -0,0 +1,156 @@
+package filters
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/warning"
+)
+
+type recordingWarningRecorder struct {
+	warnings []string
+}
+
+func (r *recordingWarningRecorder) AddWarning(agent, text string) {
+	r.warnings = append(r.warnings, text)
+}
+
+func TestWithFieldValidation(t *testing.T) {
+	routeInfo := &apirequest.RequestInfo{
+		IsResourceRequest: true,
+		Verb:              "create",
+		APIGroup:          "route.openshift.io",
+		APIVersion:        "v1",
+		Resource:          "routes",
+	}
+	cloneInfo := &apirequest.RequestInfo{
+		IsResourceRequest: true,
+		Verb:              "create",
+		APIGroup:          "build.openshift.io",
+		APIVersion:        "v1",
+		Resource:          "builds",
+		Subresource:       "clone",
+	}
+	validRoute := `{"apiVersion":"route.openshift.io/v1","kind":"Route","metadata":{"name":"test"},"spec":{"host":"example.com","to":{"kind":"Service","name":"frontend"}}}`
+	bogusRoute := `{"apiVersion":"route.openshift.io/v1","kind":"Route","metadata":{"name":"test"},"spec":{"host":"example.com","bogus":true,"to":{"kind":"Service","name":"frontend"}}}`
+	bogusClone := `{"apiVersion":"build.openshift.io/v1","kind":"BuildRequest","metadata":{"name":"test"},"bogus":true}`
+
+	tests := []struct {
+		name             string
+		requestInfo      *apirequest.RequestInfo
+		directive        string
+		body             string
+		expectedStatus   int
+		expectHandled    bool
+		expectedWarnings int
+	}{
+		{
+			name:           "no directive passes through",
+			requestInfo:    routeInfo,
+			body:           bogusRoute,
+			expectedStatus: http.StatusOK,
+			expectHandled:  true,
+		},
+		{
+			name:           "ignore passes through",
+			requestInfo:    routeInfo,
+			directive:      "Ignore",
+			body:           bogusRoute,
+			expectedStatus: http.StatusOK,
+			expectHandled:  true,
+		},
+		{
+			name:           "strict rejects unknown fields",
+			requestInfo:    routeInfo,
+			directive:      "Strict",
+			body:           bogusRoute,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "strict accepts a valid body",
+			requestInfo:    routeInfo,
+			directive:      "Strict",
+			body:           validRoute,
+			expectedStatus: http.StatusOK,
+			expectHandled:  true,
+		},
+		{
+			name:             "warn records a warning and passes through",
+			requestInfo:      routeInfo,
+			directive:        "Warn",
+			body:             bogusRoute,
+			expectedStatus:   http.StatusOK,
+			expectHandled:    true,
+			expectedWarnings: 1,
+		},
+		{
+			name:           "strict rejects unknown fields on virtual subresources",
+			requestInfo:    cloneInfo,
+			directive:      "Strict",
+			body:           bogusClone,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid directive is rejected",
+			requestInfo:    routeInfo,
+			directive:      "Loose",
+			body:           validRoute,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unvalidated resources pass through",
+			requestInfo:    &apirequest.RequestInfo{IsResourceRequest: true, Verb: "create", APIGroup: "image.openshift.io", APIVersion: "v1", Resource: "images"},
+			directive:      "Strict",
+			body:           bogusRoute,
+			expectedStatus: http.StatusOK,
+			expectHandled:  true,
+		},
+	}
+
+	for _, test := range tests {
+		handled := false
+		var observedBody string
+		handler := WithFieldValidation(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			handled = true
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				t.Errorf("%s: unexpected error reading body: %v", test.name, err)
+			}
+			observedBody = string(body)
+		}), 0)
+
+		url := "/apis/route.openshift.io/v1/namespaces/default/routes"
+		if len(test.directive) > 0 {
+			url += "?fieldValidation=" + test.directive
+		}
+		req := httptest.NewRequest("POST", url, bytes.NewBufferString(test.body))
+		req.Header.Set("Content-Type", "application/json")
+		recorder := &recordingWarningRecorder{}
+		ctx := warning.WithWarningRecorder(req.Context(), recorder)
+		req = req.WithContext(apirequest.WithRequestInfo(ctx, test.requestInfo))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != test.expectedStatus {
+			t.Errorf("%s: expected status %d, got %d: %s", test.name, test.expectedStatus, w.Code, w.Body.String())
+		}
+		if handled != test.expectHandled {
+			t.Errorf("%s: expected handled=%t, got %t", test.name, test.expectHandled, handled)
+		}
+		if handled && observedBody != test.body {
+			t.Errorf("%s: body was not restored for downstream handlers", test.name)
+		}
+		if len(recorder.warnings) != test.expectedWarnings {
+			t.Errorf("%s: expected %d warnings, got %v", test.name, test.expectedWarnings, recorder.warnings)
+		}
+		if test.expectedStatus == http.StatusBadRequest && !strings.Contains(w.Body.String(), "Failure") {
+			t.Errorf("%s: expected a status failure body, got %s", test.name, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+// SinceLineParameter is the query parameter that carries the reconnect
+// cursor of a build log request: the number of log lines the client has
+// already received.  BuildLogOptions is a stable, versioned API type that
+// cannot grow new fields, so the cursor travels out of band and is handed to
+// the buildlog registry through the request context.
+const SinceLineParameter = "sinceLine"
+
+type sinceLineKeyType int
+
+const sinceLineKey sinceLineKeyType = iota
+
+// WithBuildLogSinceLine parses the sinceLine query parameter of build log
+// requests and records it in the request context for the buildlog registry.
+// Values that are not non-negative integers are rejected so a client with a
+// corrupt cursor fails loudly instead of silently re-downloading the whole
+// log.
+func WithBuildLogSinceLine(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestInfo, ok := apirequest.RequestInfoFrom(req.Context())
+		if ok && requestInfo.IsResourceRequest &&
+			requestInfo.APIGroup == buildv1.GroupName &&
+			requestInfo.Resource == "builds" &&
+			requestInfo.Subresource == "log" {
+			if value := req.URL.Query().Get(SinceLineParameter); len(value) > 0 {
+				lines, err := strconv.ParseInt(value, 10, 64)
+				if err != nil || lines < 0 {
+					badRequest := kapierrors.NewBadRequest(fmt.Sprintf("%s must be a non-negative integer, not %q", SinceLineParameter, value))
+					responsewriters.ErrorNegotiated(badRequest, fieldValidationCodecs, buildv1.GroupVersion, w, req)
+					return
+				}
+				req = req.WithContext(context.WithValue(req.Context(), sinceLineKey, lines))
+			}
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// BuildLogSinceLineFrom returns the reconnect cursor recorded by
+// WithBuildLogSinceLine, if the request carried one.
+func BuildLogSinceLineFrom(ctx context.Context) (int64, bool) {
+	lines, ok := ctx.Value(sinceLineKey).(int64)
+	return lines, ok
+}
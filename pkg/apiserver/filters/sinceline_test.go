@@ -0,0 +1,105 @@
+package filters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestWithBuildLogSinceLine(t *testing.T) {
+	logInfo := &apirequest.RequestInfo{
+		IsResourceRequest: true,
+		Verb:              "get",
+		APIGroup:          "build.openshift.io",
+		APIVersion:        "v1",
+		Resource:          "builds",
+		Subresource:       "log",
+	}
+	buildInfo := &apirequest.RequestInfo{
+		IsResourceRequest: true,
+		Verb:              "get",
+		APIGroup:          "build.openshift.io",
+		APIVersion:        "v1",
+		Resource:          "builds",
+	}
+
+	tests := []struct {
+		name           string
+		requestInfo    *apirequest.RequestInfo
+		query          string
+		expectedStatus int
+		expectCursor   bool
+		expectedCursor int64
+	}{
+		{
+			name:           "no parameter passes through",
+			requestInfo:    logInfo,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "valid cursor is recorded",
+			requestInfo:    logInfo,
+			query:          "?sinceLine=42",
+			expectedStatus: http.StatusOK,
+			expectCursor:   true,
+			expectedCursor: 42,
+		},
+		{
+			name:           "zero is a valid cursor",
+			requestInfo:    logInfo,
+			query:          "?sinceLine=0",
+			expectedStatus: http.StatusOK,
+			expectCursor:   true,
+			expectedCursor: 0,
+		},
+		{
+			name:           "negative cursor is rejected",
+			requestInfo:    logInfo,
+			query:          "?sinceLine=-1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "non-numeric cursor is rejected",
+			requestInfo:    logInfo,
+			query:          "?sinceLine=bogus",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "other resources are not inspected",
+			requestInfo:    buildInfo,
+			query:          "?sinceLine=bogus",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		handled := false
+		var cursor int64
+		var hasCursor bool
+		handler := WithBuildLogSinceLine(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			handled = true
+			cursor, hasCursor = BuildLogSinceLineFrom(req.Context())
+		}))
+
+		req := httptest.NewRequest("GET", "/apis/build.openshift.io/v1/namespaces/ns/builds/test/log"+test.query, nil)
+		req = req.WithContext(apirequest.WithRequestInfo(req.Context(), test.requestInfo))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != test.expectedStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.expectedStatus, recorder.Code)
+		}
+		if expectHandled := test.expectedStatus == http.StatusOK; handled != expectHandled {
+			t.Errorf("%s: expected handled=%v, got %v", test.name, expectHandled, handled)
+		}
+		if hasCursor != test.expectCursor {
+			t.Errorf("%s: expected cursor present=%v, got %v", test.name, test.expectCursor, hasCursor)
+			continue
+		}
+		if hasCursor && cursor != test.expectedCursor {
+			t.Errorf("%s: expected cursor %d, got %d", test.name, test.expectedCursor, cursor)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package filters
+
+import (
+	"context"
+	"net/http"
+)
+
+type userAgentKeyType int
+
+const userAgentKey userAgentKeyType = iota
+
+// WithRequestUserAgent records the User-Agent header of every request in the
+// request context. Registry code runs far below the http handler and has no
+// access to the request, but deprecated usage reporting needs to attribute
+// calls to the client that made them.
+func WithRequestUserAgent(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if userAgent := req.UserAgent(); len(userAgent) > 0 {
+			req = req.WithContext(context.WithValue(req.Context(), userAgentKey, userAgent))
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// RequestUserAgentFrom returns the User-Agent header recorded by
+// WithRequestUserAgent, if the request carried one.
+func RequestUserAgentFrom(ctx context.Context) (string, bool) {
+	userAgent, ok := ctx.Value(userAgentKey).(string)
+	return userAgent, ok
+}
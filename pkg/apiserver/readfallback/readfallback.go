@@ -0,0 +1,46 @@
+// Package readfallback decides when a failed read may be answered from a
+// local cache instead of surfacing the storage error, and annotates such
+// responses so clients know they may be stale. It backs the optional graceful
+// degradation mode for etcd outages.
+package readfallback
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/warning"
+)
+
+// ShouldFallback reports whether the error indicates the storage backend is
+// unavailable or overloaded, as opposed to a definitive answer like not-found
+// or forbidden that a cache must not override.
+func ShouldFallback(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch {
+	case kapierrors.IsServerTimeout(err),
+		kapierrors.IsTimeout(err),
+		kapierrors.IsTooManyRequests(err),
+		kapierrors.IsServiceUnavailable(err):
+		return true
+	case kapierrors.IsInternalError(err):
+		// etcd client errors surface as internal errors; only treat the
+		// unavailability flavors as fallback-worthy
+		message := err.Error()
+		return strings.Contains(message, "etcdserver: request timed out") ||
+			strings.Contains(message, "etcdserver: leader changed") ||
+			strings.Contains(message, "etcdserver: too many requests") ||
+			strings.Contains(message, "etcdserver: server stopped") ||
+			strings.Contains(message, "connection refused")
+	}
+	return false
+}
+
+// AddStaleReadWarning records a Warning header on the response explaining
+// that the answer came from a cache because storage could not be reached.
+func AddStaleReadWarning(ctx context.Context, resource string) {
+	warning.AddWarning(ctx, "", fmt.Sprintf("the storage backend for %s is unavailable; returning possibly stale data from the local cache", resource))
+}
@@ -0,0 +1,62 @@
+package readfallback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/warning"
+)
+
+func TestShouldFallback(t *testing.T) {
+	groupResource := schema.GroupResource{Group: "image.openshift.io", Resource: "imagestreams"}
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "server timeout", err: kapierrors.NewServerTimeout(groupResource, "get", 1), expected: true},
+		{name: "timeout", err: kapierrors.NewTimeoutError("too slow", 1), expected: true},
+		{name: "too many requests", err: kapierrors.NewTooManyRequestsError("throttled"), expected: true},
+		{name: "service unavailable", err: kapierrors.NewServiceUnavailable("down"), expected: true},
+		{name: "etcd request timed out", err: kapierrors.NewInternalError(fmt.Errorf("etcdserver: request timed out")), expected: true},
+		{name: "etcd leader changed", err: kapierrors.NewInternalError(fmt.Errorf("etcdserver: leader changed")), expected: true},
+		{name: "etcd connection refused", err: kapierrors.NewInternalError(fmt.Errorf("dial tcp 10.0.0.1:2379: connect: connection refused")), expected: true},
+		{name: "other internal error", err: kapierrors.NewInternalError(fmt.Errorf("conversion failed")), expected: false},
+		{name: "not found", err: kapierrors.NewNotFound(groupResource, "test"), expected: false},
+		{name: "forbidden", err: kapierrors.NewForbidden(groupResource, "test", fmt.Errorf("denied")), expected: false},
+		{name: "plain error", err: errors.New("boom"), expected: false},
+	}
+	for _, test := range tests {
+		if actual := ShouldFallback(test.err); actual != test.expected {
+			t.Errorf("%s: expected %t, got %t", test.name, test.expected, actual)
+		}
+	}
+}
+
+type recordingWarningRecorder struct {
+	warnings []string
+}
+
+func (r *recordingWarningRecorder) AddWarning(agent, text string) {
+	r.warnings = append(r.warnings, text)
+}
+
+func TestAddStaleReadWarning(t *testing.T) {
+	recorder := &recordingWarningRecorder{}
+	ctx := warning.WithWarningRecorder(context.Background(), recorder)
+
+	AddStaleReadWarning(ctx, "imagestreams")
+
+	if len(recorder.warnings) != 1 {
+		t.Fatalf("expected one warning, got %v", recorder.warnings)
+	}
+	if !strings.Contains(recorder.warnings[0], "imagestreams") || !strings.Contains(recorder.warnings[0], "stale") {
+		t.Errorf("unexpected warning: %q", recorder.warnings[0])
+	}
+}
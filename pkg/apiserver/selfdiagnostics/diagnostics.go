@@ -0,0 +1,263 @@
+package selfdiagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/client-go/tools/cache"
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/klog/v2"
+)
+
+// etcdRequestDurationMetric is the apiserver storage histogram the report
+// derives its latency percentiles from.
+const etcdRequestDurationMetric = "etcd_request_duration_seconds"
+
+// Report is the payload of the diagnostics endpoint: the sync state of every
+// cache this process depends on and the etcd latency it has observed, so an
+// "OpenShift APIs are slow but kube is fine" incident can be narrowed down
+// from one request.
+type Report struct {
+	// Caches lists every registered informer or cache ordered by name.
+	Caches []CacheStatus `json:"caches"`
+	// Storage lists the etcd request latency observed by this process per
+	// object type and operation, ordered slowest p99 first.
+	Storage []StorageLatency `json:"storage"`
+}
+
+// CacheStatus is the sync state of one informer or cache.
+type CacheStatus struct {
+	Name   string `json:"name"`
+	Synced bool   `json:"synced"`
+	// LastEvent is the time the cache last observed a watch event, for the
+	// caches that report one.  A synced cache with an old last event may be
+	// lagging behind a partitioned watch.
+	LastEvent *time.Time `json:"lastEvent,omitempty"`
+}
+
+// StorageLatency summarizes the etcd request latency for one object type and
+// operation since the process started.  The percentiles are estimated from
+// histogram buckets and are approximate.
+type StorageLatency struct {
+	Type      string  `json:"type"`
+	Operation string  `json:"operation"`
+	Count     uint64  `json:"count"`
+	P50       float64 `json:"p50Seconds"`
+	P90       float64 `json:"p90Seconds"`
+	P99       float64 `json:"p99Seconds"`
+}
+
+// SyncStatusFunc reports the current sync state of a group of caches by name.
+type SyncStatusFunc func() map[string]bool
+
+// Registry collects the cache checks the diagnostics report is built from.
+// Registry is safe for concurrent use.
+type Registry struct {
+	lock       sync.Mutex
+	checks     map[string]func() bool
+	providers  []SyncStatusFunc
+	lastEvents map[string]time.Time
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		checks:     make(map[string]func() bool),
+		lastEvents: make(map[string]time.Time),
+	}
+}
+
+// RegisterCheck adds a single named cache whose sync state is read from
+// synced.
+func (r *Registry) RegisterCheck(name string, synced func() bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.checks[name] = synced
+}
+
+// RegisterSyncStatusProvider adds a group of caches whose names and sync
+// states are read from provider on every report.
+func (r *Registry) RegisterSyncStatusProvider(provider SyncStatusFunc) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.providers = append(r.providers, provider)
+}
+
+// ObserveEvent records that the named cache observed a watch event at the
+// given time.
+func (r *Registry) ObserveEvent(name string, at time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.lastEvents[name] = at
+}
+
+// EventHandler returns a resource event handler that timestamps the named
+// cache on every event it observes.  It is meant to be added alongside the
+// real handlers of an informer that is already running; it never adds a watch
+// of its own.
+func (r *Registry) EventHandler(name string) cache.ResourceEventHandler {
+	observe := func(interface{}) { r.ObserveEvent(name, time.Now()) }
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    observe,
+		UpdateFunc: func(_, obj interface{}) { observe(obj) },
+		DeleteFunc: observe,
+	}
+}
+
+// caches returns the current state of every registered cache ordered by name.
+func (r *Registry) caches() []CacheStatus {
+	r.lock.Lock()
+	checks := make(map[string]func() bool, len(r.checks))
+	for name, synced := range r.checks {
+		checks[name] = synced
+	}
+	providers := make([]SyncStatusFunc, len(r.providers))
+	copy(providers, r.providers)
+	lastEvents := make(map[string]time.Time, len(r.lastEvents))
+	for name, at := range r.lastEvents {
+		lastEvents[name] = at
+	}
+	r.lock.Unlock()
+
+	state := make(map[string]bool)
+	for name, synced := range checks {
+		state[name] = synced()
+	}
+	for _, provider := range providers {
+		for name, synced := range provider() {
+			state[name] = synced
+		}
+	}
+
+	statuses := make([]CacheStatus, 0, len(state))
+	for name, synced := range state {
+		status := CacheStatus{Name: name, Synced: synced}
+		if at, ok := lastEvents[name]; ok {
+			at := at
+			status.LastEvent = &at
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Handler serves the diagnostics report, reading the storage latencies from
+// gatherer.
+func (r *Registry) Handler(gatherer compbasemetrics.Gatherer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := Report{
+			Caches:  r.caches(),
+			Storage: storageLatencies(gatherer),
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// storageLatencies derives per-type, per-operation latency summaries from the
+// etcd request duration histogram of gatherer.
+func storageLatencies(gatherer compbasemetrics.Gatherer) []StorageLatency {
+	families, err := gatherer.Gather()
+	if err != nil {
+		klog.V(2).Infof("Error gathering metrics for the diagnostics report: %v", err)
+		return nil
+	}
+	var latencies []StorageLatency
+	for _, family := range families {
+		if family.GetName() != etcdRequestDurationMetric {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			histogram := metric.GetHistogram()
+			if histogram == nil {
+				continue
+			}
+			latency := StorageLatency{Count: histogram.GetSampleCount()}
+			for _, label := range metric.GetLabel() {
+				switch label.GetName() {
+				case "type":
+					latency.Type = label.GetValue()
+				case "operation":
+					latency.Operation = label.GetValue()
+				}
+			}
+			latency.P50 = histogramQuantile(histogram, 0.5)
+			latency.P90 = histogramQuantile(histogram, 0.9)
+			latency.P99 = histogramQuantile(histogram, 0.99)
+			latencies = append(latencies, latency)
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		a, b := latencies[i], latencies[j]
+		if a.P99 != b.P99 {
+			return a.P99 > b.P99
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Operation < b.Operation
+	})
+	return latencies
+}
+
+// histogramQuantile estimates the q-th quantile of histogram by linear
+// interpolation within the bucket the quantile falls into, the same way
+// Prometheus evaluates histogram_quantile.  Samples beyond the last finite
+// bucket are reported as that bucket's upper bound.
+func histogramQuantile(histogram *dto.Histogram, q float64) float64 {
+	count := float64(histogram.GetSampleCount())
+	if count == 0 {
+		return 0
+	}
+	rank := q * count
+	lowerBound, lowerCount := 0.0, 0.0
+	for _, bucket := range histogram.GetBucket() {
+		upperBound := bucket.GetUpperBound()
+		upperCount := float64(bucket.GetCumulativeCount())
+		if upperCount < rank {
+			lowerBound, lowerCount = upperBound, upperCount
+			continue
+		}
+		if upperCount == lowerCount {
+			return upperBound
+		}
+		return lowerBound + (upperBound-lowerBound)*(rank-lowerCount)/(upperCount-lowerCount)
+	}
+	return lowerBound
+}
+
+// DefaultRegistry collects the caches registered by this process. The
+// registration sites are spread across config assembly, so the registry is a
+// package-level value like the deprecated usage tracker.
+var DefaultRegistry = NewRegistry()
+
+// RegisterCheck adds a single named cache to the DefaultRegistry.
+func RegisterCheck(name string, synced func() bool) {
+	DefaultRegistry.RegisterCheck(name, synced)
+}
+
+// RegisterSyncStatusProvider adds a group of caches to the DefaultRegistry.
+func RegisterSyncStatusProvider(provider SyncStatusFunc) {
+	DefaultRegistry.RegisterSyncStatusProvider(provider)
+}
+
+// EventHandler returns a resource event handler that timestamps the named
+// cache on the DefaultRegistry.
+func EventHandler(name string) cache.ResourceEventHandler {
+	return DefaultRegistry.EventHandler(name)
+}
+
+// Handler serves the diagnostics report for the DefaultRegistry.
+func Handler(gatherer compbasemetrics.Gatherer) http.Handler {
+	return DefaultRegistry.Handler(gatherer)
+}
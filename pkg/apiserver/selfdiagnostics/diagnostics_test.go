@@ -0,0 +1,128 @@
+package selfdiagnostics
+
+import (
+	"encoding/json"
+	"math"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+type fakeGatherer []*dto.MetricFamily
+
+func (g fakeGatherer) Gather() ([]*dto.MetricFamily, error) {
+	return g, nil
+}
+
+func strp(s string) *string     { return &s }
+func uintp(i uint64) *uint64    { return &i }
+func floatp(f float64) *float64 { return &f }
+
+func etcdHistogram(objectType, operation string, counts map[float64]uint64, total uint64) *dto.Metric {
+	histogram := &dto.Histogram{SampleCount: uintp(total)}
+	for _, bound := range []float64{0.005, 0.025, 0.1, 0.25, 0.5, 1.0} {
+		count := counts[bound]
+		histogram.Bucket = append(histogram.Bucket, &dto.Bucket{UpperBound: floatp(bound), CumulativeCount: uintp(count)})
+	}
+	return &dto.Metric{
+		Label: []*dto.LabelPair{
+			{Name: strp("operation"), Value: strp(operation)},
+			{Name: strp("type"), Value: strp(objectType)},
+		},
+		Histogram: histogram,
+	}
+}
+
+func TestReport(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterCheck("project.openshift.io/authorization-cache", func() bool { return true })
+	registry.RegisterSyncStatusProvider(func() map[string]bool {
+		return map[string]bool{
+			"kubernetes/*v1.Namespace":           true,
+			"image.openshift.io/*v1.ImageStream": false,
+		}
+	})
+	eventTime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	registry.ObserveEvent("kubernetes/*v1.Namespace", eventTime)
+
+	gatherer := fakeGatherer{
+		{
+			Name: strp(etcdRequestDurationMetric),
+			Metric: []*dto.Metric{
+				etcdHistogram("imagestreams", "list", map[float64]uint64{0.005: 0, 0.025: 10, 0.1: 10, 0.25: 10, 0.5: 19, 1.0: 20}, 20),
+				etcdHistogram("builds", "get", map[float64]uint64{0.005: 100, 0.025: 100, 0.1: 100, 0.25: 100, 0.5: 100, 1.0: 100}, 100),
+			},
+		},
+		{Name: strp("unrelated_metric")},
+	}
+
+	recorder := httptest.NewRecorder()
+	registry.Handler(gatherer).ServeHTTP(recorder, httptest.NewRequest("GET", "/healthz/diagnostics", nil))
+
+	var report Report
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatalf("unexpected response %q: %v", recorder.Body.String(), err)
+	}
+
+	if len(report.Caches) != 3 {
+		t.Fatalf("expected 3 caches, got %#v", report.Caches)
+	}
+	// caches are ordered by name
+	if report.Caches[0].Name != "image.openshift.io/*v1.ImageStream" || report.Caches[0].Synced {
+		t.Errorf("expected the image stream informer to be reported unsynced, got %#v", report.Caches[0])
+	}
+	if report.Caches[1].Name != "kubernetes/*v1.Namespace" || !report.Caches[1].Synced {
+		t.Errorf("expected the namespace informer to be reported synced, got %#v", report.Caches[1])
+	}
+	if report.Caches[1].LastEvent == nil || !report.Caches[1].LastEvent.Equal(eventTime) {
+		t.Errorf("expected the namespace informer to carry the event time, got %#v", report.Caches[1])
+	}
+	if report.Caches[2].LastEvent != nil {
+		t.Errorf("expected no event time for the authorization cache, got %#v", report.Caches[2])
+	}
+
+	if len(report.Storage) != 2 {
+		t.Fatalf("expected 2 storage entries, got %#v", report.Storage)
+	}
+	// storage entries are ordered slowest p99 first
+	slow := report.Storage[0]
+	if slow.Type != "imagestreams" || slow.Operation != "list" || slow.Count != 20 {
+		t.Fatalf("expected the imagestreams list histogram first, got %#v", slow)
+	}
+	// half the rank falls in the (0.005, 0.025] bucket: 0.005 + 0.02*10/10
+	if math.Abs(slow.P50-0.025) > 0.0001 {
+		t.Errorf("expected p50 of 0.025, got %v", slow.P50)
+	}
+	// rank 18 of 19 in the (0.25, 0.5] bucket: 0.25 + 0.25*8/9
+	if math.Abs(slow.P90-(0.25+0.25*8/9)) > 0.0001 {
+		t.Errorf("expected p90 of %v, got %v", 0.25+0.25*8/9, slow.P90)
+	}
+	fast := report.Storage[1]
+	if fast.Type != "builds" || fast.P99 > 0.005 {
+		t.Errorf("expected the builds get histogram to stay in the first bucket, got %#v", fast)
+	}
+}
+
+func TestEventHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterCheck("kubernetes/*v1.Namespace", func() bool { return true })
+	handler := registry.EventHandler("kubernetes/*v1.Namespace")
+
+	before := time.Now()
+	handler.OnAdd(nil)
+	caches := registry.caches()
+	if len(caches) != 1 || caches[0].LastEvent == nil {
+		t.Fatalf("expected the event to be recorded, got %#v", caches)
+	}
+	if caches[0].LastEvent.Before(before) {
+		t.Errorf("expected a current event time, got %v", caches[0].LastEvent)
+	}
+}
+
+func TestHistogramQuantileEmpty(t *testing.T) {
+	if q := histogramQuantile(&dto.Histogram{SampleCount: uintp(0)}, 0.99); q != 0 {
+		t.Errorf("expected 0 for an empty histogram, got %v", q)
+	}
+}
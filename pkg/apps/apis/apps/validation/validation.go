@@ -183,6 +183,18 @@ func ValidateDeploymentConfigRollback(rollback *appsapi.DeploymentConfigRollback
 		result = append(result, field.Invalid(specPath.Child("revision"), rollback.Spec.Revision, "must be non-negative"))
 	}
 
+	if len(rollback.Spec.From.Name) > 0 {
+		if len(rollback.Spec.From.Kind) == 0 {
+			rollback.Spec.From.Kind = "ReplicationController"
+		}
+		if rollback.Spec.From.Kind != "ReplicationController" {
+			result = append(result, field.Invalid(specPath.Child("from", "kind"), rollback.Spec.From.Kind, "the kind of the rollback target must be 'ReplicationController'"))
+		}
+		if rollback.Spec.Revision != 0 {
+			result = append(result, field.Invalid(specPath.Child("revision"), rollback.Spec.Revision, "may not be combined with a rollback target in 'from'"))
+		}
+	}
+
 	return result
 }
 
@@ -2,15 +2,18 @@ package instantiate
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	v1 "github.com/openshift/openshift-apiserver/pkg/apps/apis/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/audit"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/generic/registry"
 	"k8s.io/apiserver/pkg/registry/rest"
@@ -21,9 +24,11 @@ import (
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
 	"k8s.io/kubernetes/pkg/apis/core"
 	"k8s.io/kubernetes/pkg/apis/core/helper"
+	corev1conversions "k8s.io/kubernetes/pkg/apis/core/v1"
 
 	"github.com/openshift/api/apps"
 	appsv1 "github.com/openshift/api/apps/v1"
+	imagev1 "github.com/openshift/api/image/v1"
 	imagev1client "github.com/openshift/client-go/image/clientset/versioned"
 	imagev1typedclient "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
 	"github.com/openshift/library-go/pkg/apps/appsserialization"
@@ -33,6 +38,48 @@ import (
 	"github.com/openshift/openshift-apiserver/pkg/apps/apis/apps/validation"
 )
 
+// instantiateEnvAnnotation carries one-off environment variable overrides for
+// the next rollout as a JSON array of EnvVars. The versioned DeploymentRequest
+// has no object metadata to carry them on, so clients set the annotation on
+// the deployment config itself; instantiate applies the overrides to every
+// container of the template and strips the annotation in the same update that
+// bumps latestVersion, making the overrides single-shot.
+const instantiateEnvAnnotation = "openshift.io/instantiate.env"
+
+// imageResolutionAuditAnnotationPrefix, suffixed with the ImageStreamTag being
+// resolved, is set on the audit event of an instantiate request whenever an
+// image change trigger rewrites the pod template to a new image reference.
+// The value is a JSON object recording the original tag reference, the
+// resolved pull spec and the UID of the image stream it came from.
+const imageResolutionAuditAnnotationPrefix = "apps.openshift.io/image-resolution."
+
+// imageResolutionAudit is the value of an image resolution audit annotation.
+type imageResolutionAudit struct {
+	Kind           string `json:"kind"`
+	Original       string `json:"original"`
+	Resolved       string `json:"resolved"`
+	ImageStreamUID string `json:"imageStreamUID,omitempty"`
+}
+
+// recordImageResolution leaves an audit trail for a trigger resolution: which
+// tag was asked for, which pull spec the template ended up with, and which
+// image stream answered, so security teams can trace how a workload came to
+// run a given digest.
+func recordImageResolution(ctx context.Context, from core.ObjectReference, resolved string, stream *imagev1.ImageStream) {
+	value := imageResolutionAudit{
+		Kind:           "ImageStreamTag",
+		Original:       from.Namespace + "/" + from.Name,
+		Resolved:       resolved,
+		ImageStreamUID: string(stream.UID),
+	}
+	encoded, err := json.Marshal(&value)
+	if err != nil {
+		klog.V(2).Infof("Unable to encode image resolution audit annotation for %s/%s: %v", from.Namespace, from.Name, err)
+		return
+	}
+	audit.AddAuditAnnotation(ctx, imageResolutionAuditAnnotationPrefix+value.Original, string(encoded))
+}
+
 // NewREST provides new REST storage for the apps API group.
 func NewREST(store registry.Store, imagesclient imagev1client.Interface, kc kubernetes.Interface, admission admission.Interface) *REST {
 	store.UpdateStrategy = Strategy
@@ -80,7 +127,11 @@ func (s *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 			}
 		}
 
-		canTrigger, causes, err := canTrigger(ctx, config, s.rn, req.Force)
+		if err := applyEnvOverrides(config); err != nil {
+			return err
+		}
+
+		canTrigger, causes, err := canTrigger(ctx, config, s.rn, req.Force, req.ExcludeTriggers)
 		if err != nil {
 			return err
 		}
@@ -177,6 +228,8 @@ func processTriggers(ctx context.Context, config *appsapi.DeploymentConfig, is i
 			continue
 		}
 
+		recordImageResolution(ctx, params.From, latestReference, stream)
+
 		// Update containers
 		names := sets.NewString(params.ContainerNames...)
 		for i := range config.Spec.Template.Spec.Containers {
@@ -212,6 +265,67 @@ func processTriggers(ctx context.Context, config *appsapi.DeploymentConfig, is i
 	return nil
 }
 
+// applyEnvOverrides injects the environment variable overrides carried by the
+// instantiateEnvAnnotation into every container and init container of the
+// template, replacing variables with the same name, and removes the annotation
+// so the overrides apply to this rollout only.
+func applyEnvOverrides(config *appsapi.DeploymentConfig) error {
+	value, ok := config.Annotations[instantiateEnvAnnotation]
+	if !ok {
+		return nil
+	}
+	delete(config.Annotations, instantiateEnvAnnotation)
+
+	var versioned []corev1.EnvVar
+	if err := json.Unmarshal([]byte(value), &versioned); err != nil {
+		return errors.NewBadRequest(fmt.Sprintf("the %s annotation must contain a JSON array of environment variables: %v", instantiateEnvAnnotation, err))
+	}
+	env := make([]core.EnvVar, 0, len(versioned))
+	for i := range versioned {
+		if len(versioned[i].Name) == 0 {
+			return errors.NewBadRequest(fmt.Sprintf("the %s annotation contains an environment variable without a name", instantiateEnvAnnotation))
+		}
+		var converted core.EnvVar
+		if err := corev1conversions.Convert_v1_EnvVar_To_core_EnvVar(&versioned[i], &converted, nil); err != nil {
+			return errors.NewInternalError(err)
+		}
+		env = append(env, converted)
+	}
+	if len(env) == 0 {
+		return nil
+	}
+
+	for i := range config.Spec.Template.Spec.Containers {
+		container := &config.Spec.Template.Spec.Containers[i]
+		container.Env = mergeEnv(container.Env, env)
+	}
+	for i := range config.Spec.Template.Spec.InitContainers {
+		container := &config.Spec.Template.Spec.InitContainers[i]
+		container.Env = mergeEnv(container.Env, env)
+	}
+	return nil
+}
+
+// mergeEnv overlays the overrides on the existing environment. Variables with
+// the same name are replaced in place; new variables are appended in order.
+func mergeEnv(existing, overrides []core.EnvVar) []core.EnvVar {
+	merged := existing
+	for _, override := range overrides {
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == override.Name {
+				merged[i] = override
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, override)
+		}
+	}
+	return merged
+}
+
 func containsTriggerType(types []appsapi.DeploymentTriggerType, triggerType appsapi.DeploymentTriggerType) bool {
 	for _, t := range types {
 		if t == triggerType {
@@ -222,11 +336,13 @@ func containsTriggerType(types []appsapi.DeploymentTriggerType, triggerType apps
 }
 
 // canTrigger determines if we can trigger a new deployment for config based on the various deployment triggers.
+// Triggers whose type is listed in exclude are not considered.
 func canTrigger(
 	ctx context.Context,
 	config *appsapi.DeploymentConfig,
 	rn corev1client.ReplicationControllersGetter,
 	force bool,
+	exclude []appsapi.DeploymentTriggerType,
 ) (bool, []appsapi.DeploymentCause, error) {
 
 	decoded, err := decodeFromLatestDeployment(ctx, config, rn)
@@ -241,6 +357,11 @@ func canTrigger(
 		if t.Type != appsapi.DeploymentTriggerOnImageChange {
 			continue
 		}
+		// Excluded triggers neither produce causes nor count towards the
+		// resolved-image check.
+		if containsTriggerType(exclude, t.Type) {
+			continue
+		}
 		ictCount++
 
 		// If the image is yet to be resolved then we cannot process this trigger.
@@ -295,6 +416,7 @@ func canTrigger(
 		return false, nil, err
 	}
 	if appsutil.HasChangeTrigger(externalConfig) && // Our deployment config has a config change trigger
+		!containsTriggerType(exclude, appsapi.DeploymentTriggerOnConfigChange) && // and it is not excluded from the request
 		len(causes) == 0 && // and no other trigger has triggered.
 		(config.Status.LatestVersion == 0 || // Either it's the initial deployment
 			!helper.Semantic.DeepEqual(config.Spec.Template, decoded.Spec.Template)) /* or a config change happened so we need to trigger */ {
@@ -2,12 +2,15 @@ package instantiate
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/client-go/kubernetes/fake"
 	clientgotesting "k8s.io/client-go/testing"
 	kapi "k8s.io/kubernetes/pkg/apis/core"
@@ -283,6 +286,50 @@ func TestProcess_matchScenarios(t *testing.T) {
 	}
 }
 
+func TestProcess_recordsImageResolutionAudit(t *testing.T) {
+	fake := &imagev1fakeclient.Clientset{}
+	fake.AddReactor("get", "imagestreams", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		stream := fakeStream(appstest.ImageStreamName, imagev1.DefaultImageTag, appstest.DockerImageReference, appstest.ImageID)
+		stream.UID = "stream-uid"
+		return true, stream, nil
+	})
+
+	config := appstest.OkDeploymentConfig(1)
+	config.Namespace = metav1.NamespaceDefault
+	config.Spec.Triggers = []appsapi.DeploymentTriggerPolicy{
+		{
+			Type: appsapi.DeploymentTriggerOnImageChange,
+			ImageChangeParams: &appsapi.DeploymentTriggerImageChangeParams{
+				Automatic:      true,
+				ContainerNames: []string{"container1"},
+				From:           kapi.ObjectReference{Namespace: metav1.NamespaceDefault, Name: imageutil.JoinImageStreamTag(appstest.ImageStreamName, imagev1.DefaultImageTag)},
+			},
+		},
+	}
+
+	event := &auditinternal.Event{Level: auditinternal.LevelMetadata}
+	ctx := apirequest.WithAuditEvent(context.TODO(), event)
+	if err := processTriggers(ctx, config, fake.ImageV1(), false, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key := imageResolutionAuditAnnotationPrefix + metav1.NamespaceDefault + "/" + imageutil.JoinImageStreamTag(appstest.ImageStreamName, imagev1.DefaultImageTag)
+	encoded, ok := event.Annotations[key]
+	if !ok {
+		t.Fatalf("expected audit annotation %q, got %v", key, event.Annotations)
+	}
+	value := imageResolutionAudit{}
+	if err := json.Unmarshal([]byte(encoded), &value); err != nil {
+		t.Fatalf("unable to decode audit annotation %q: %v", encoded, err)
+	}
+	if value.Resolved != appstest.DockerImageReference {
+		t.Errorf("expected resolved reference %q, got %q", appstest.DockerImageReference, value.Resolved)
+	}
+	if value.ImageStreamUID != "stream-uid" {
+		t.Errorf("expected image stream uid to be recorded, got %#v", value)
+	}
+}
+
 func fakeStream(name, tag, dir, image string) *imagev1.ImageStream {
 	return &imagev1.ImageStream{
 		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceDefault},
@@ -301,6 +348,7 @@ func TestCanTrigger(t *testing.T) {
 		config  *appsapi.DeploymentConfig
 		decoded *appsapi.DeploymentConfig
 		force   bool
+		exclude []appsapi.DeploymentTriggerType
 
 		expected       bool
 		expectedCauses []appsapi.DeploymentCause
@@ -687,6 +735,64 @@ func TestCanTrigger(t *testing.T) {
 			},
 			force: false,
 
+			expected:       false,
+			expectedCauses: nil,
+		},
+		{
+			name: "image change trigger only [automatic=true][w/ image change][excluded]",
+
+			config: &appsapi.DeploymentConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "config"},
+				Spec: appsapi.DeploymentConfigSpec{
+					Template: appstest.OkPodTemplateChanged(),
+					Triggers: []appsapi.DeploymentTriggerPolicy{
+						OkTriggeredImageChange(),
+					},
+				},
+				Status: appstest.OkDeploymentConfigStatus(1),
+			},
+			decoded: &appsapi.DeploymentConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "config"},
+				Spec: appsapi.DeploymentConfigSpec{
+					Template: appstest.OkPodTemplate(),
+					Triggers: []appsapi.DeploymentTriggerPolicy{
+						appstest.OkImageChangeTrigger(),
+					},
+				},
+				Status: appstest.OkDeploymentConfigStatus(1),
+			},
+			force:   false,
+			exclude: []appsapi.DeploymentTriggerType{appsapi.DeploymentTriggerOnImageChange},
+
+			expected:       false,
+			expectedCauses: nil,
+		},
+		{
+			name: "config change trigger only [w/ podtemplate change][excluded]",
+
+			config: &appsapi.DeploymentConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "config"},
+				Spec: appsapi.DeploymentConfigSpec{
+					Template: appstest.OkPodTemplateChanged(),
+					Triggers: []appsapi.DeploymentTriggerPolicy{
+						appstest.OkConfigChangeTrigger(),
+					},
+				},
+				Status: appstest.OkDeploymentConfigStatus(1),
+			},
+			decoded: &appsapi.DeploymentConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "config"},
+				Spec: appsapi.DeploymentConfigSpec{
+					Template: appstest.OkPodTemplate(),
+					Triggers: []appsapi.DeploymentTriggerPolicy{
+						appstest.OkConfigChangeTrigger(),
+					},
+				},
+				Status: appstest.OkDeploymentConfigStatus(1),
+			},
+			force:   false,
+			exclude: []appsapi.DeploymentTriggerType{appsapi.DeploymentTriggerOnConfigChange},
+
 			expected:       false,
 			expectedCauses: nil,
 		},
@@ -708,7 +814,7 @@ func TestCanTrigger(t *testing.T) {
 
 		test.config = RoundTripConfig(t, test.config)
 
-		got, gotCauses, err := canTrigger(context.TODO(), test.config, client.CoreV1(), test.force)
+		got, gotCauses, err := canTrigger(context.TODO(), test.config, client.CoreV1(), test.force, test.exclude)
 		if err != nil && !test.expectedErr {
 			t.Errorf("unexpected error: %v", err)
 			continue
@@ -792,3 +898,57 @@ func RoundTripConfig(t *testing.T, config *appsapi.DeploymentConfig) *appsapi.De
 	}
 	return defaulted.(*appsapi.DeploymentConfig)
 }
+
+func TestApplyEnvOverrides(t *testing.T) {
+	config := &appsapi.DeploymentConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "config",
+			Annotations: map[string]string{
+				instantiateEnvAnnotation: `[{"name":"ENV1","value":"OVERRIDDEN"},{"name":"EXTRA","value":"NEW"}]`,
+			},
+		},
+		Spec: appsapi.DeploymentConfigSpec{
+			Template: appstest.OkPodTemplate(),
+		},
+	}
+
+	if err := applyEnvOverrides(config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := config.Annotations[instantiateEnvAnnotation]; ok {
+		t.Errorf("expected the %s annotation to be stripped", instantiateEnvAnnotation)
+	}
+	expected := []kapi.EnvVar{
+		{Name: "ENV1", Value: "OVERRIDDEN"},
+		{Name: "EXTRA", Value: "NEW"},
+	}
+	if !kapihelper.Semantic.DeepEqual(expected, config.Spec.Template.Spec.Containers[0].Env) {
+		t.Errorf("expected env:\n%#v\ngot:\n%#v", expected, config.Spec.Template.Spec.Containers[0].Env)
+	}
+	expected = []kapi.EnvVar{
+		{Name: "ENV1", Value: "OVERRIDDEN"},
+		{Name: "EXTRA", Value: "NEW"},
+	}
+	if !kapihelper.Semantic.DeepEqual(expected, config.Spec.Template.Spec.Containers[1].Env) {
+		t.Errorf("expected env:\n%#v\ngot:\n%#v", expected, config.Spec.Template.Spec.Containers[1].Env)
+	}
+}
+
+func TestApplyEnvOverridesRejectsMalformedAnnotations(t *testing.T) {
+	config := &appsapi.DeploymentConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "config",
+			Annotations: map[string]string{
+				instantiateEnvAnnotation: `{"name":"ENV1"}`,
+			},
+		},
+		Spec: appsapi.DeploymentConfigSpec{
+			Template: appstest.OkPodTemplate(),
+		},
+	}
+
+	err := applyEnvOverrides(config)
+	if err == nil || !errors.IsBadRequest(err) {
+		t.Fatalf("expected a bad request error, got %v", err)
+	}
+}
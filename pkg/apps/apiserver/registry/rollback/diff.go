@@ -0,0 +1,126 @@
+package rollback
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	appsapi "github.com/openshift/openshift-apiserver/pkg/apps/apis/apps"
+)
+
+const (
+	// includeDiffAnnotation requests a structured diff of the pod template
+	// changes. Clients set it to "true" in the updatedAnnotations of the
+	// rollback request.
+	includeDiffAnnotation = "openshift.io/rollback.include-diff"
+	// podTemplateDiffAnnotation carries the JSON encoded diff on the
+	// generated config.
+	podTemplateDiffAnnotation = "openshift.io/rollback.pod-template-diff"
+)
+
+// podTemplateChange describes a single difference between the current pod
+// template and the one a rollback would restore.
+type podTemplateChange struct {
+	// Container names the affected container; empty for template level
+	// changes.
+	Container string `json:"container,omitempty"`
+	// Field is the changed field, e.g. "image" or "env/LOG_LEVEL".
+	Field string `json:"field"`
+	// From is the current value and To the value after the rollback.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// attachPodTemplateDiff replaces the diff request annotation on the generated
+// config with the JSON encoded pod template changes the rollback would make.
+func attachPodTemplateDiff(config *appsapi.DeploymentConfig, current, target *kapi.PodTemplateSpec) {
+	delete(config.Annotations, includeDiffAnnotation)
+	diff, err := json.Marshal(diffPodTemplates(current, target))
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("unable to encode the pod template diff for config %q: %v", config.Name, err))
+		return
+	}
+	if config.Annotations == nil {
+		config.Annotations = map[string]string{}
+	}
+	config.Annotations[podTemplateDiffAnnotation] = string(diff)
+}
+
+// diffPodTemplates summarizes container level differences between the current
+// and the target pod template. It covers the fields the console renders:
+// container membership, image, command, args and environment variables.
+func diffPodTemplates(current, target *kapi.PodTemplateSpec) []podTemplateChange {
+	changes := []podTemplateChange{}
+	if current == nil || target == nil {
+		return changes
+	}
+
+	targetContainers := map[string]*kapi.Container{}
+	for i := range target.Spec.Containers {
+		targetContainers[target.Spec.Containers[i].Name] = &target.Spec.Containers[i]
+	}
+	currentContainers := map[string]*kapi.Container{}
+	for i := range current.Spec.Containers {
+		currentContainers[current.Spec.Containers[i].Name] = &current.Spec.Containers[i]
+	}
+
+	for i := range current.Spec.Containers {
+		currentContainer := &current.Spec.Containers[i]
+		targetContainer, ok := targetContainers[currentContainer.Name]
+		if !ok {
+			changes = append(changes, podTemplateChange{Container: currentContainer.Name, Field: "container", From: "present", To: "absent"})
+			continue
+		}
+		changes = append(changes, diffContainers(currentContainer, targetContainer)...)
+	}
+	for i := range target.Spec.Containers {
+		targetContainer := &target.Spec.Containers[i]
+		if _, ok := currentContainers[targetContainer.Name]; !ok {
+			changes = append(changes, podTemplateChange{Container: targetContainer.Name, Field: "container", From: "absent", To: "present"})
+		}
+	}
+	return changes
+}
+
+func diffContainers(current, target *kapi.Container) []podTemplateChange {
+	var changes []podTemplateChange
+	if current.Image != target.Image {
+		changes = append(changes, podTemplateChange{Container: current.Name, Field: "image", From: current.Image, To: target.Image})
+	}
+	if currentCommand, targetCommand := strings.Join(current.Command, " "), strings.Join(target.Command, " "); currentCommand != targetCommand {
+		changes = append(changes, podTemplateChange{Container: current.Name, Field: "command", From: currentCommand, To: targetCommand})
+	}
+	if currentArgs, targetArgs := strings.Join(current.Args, " "), strings.Join(target.Args, " "); currentArgs != targetArgs {
+		changes = append(changes, podTemplateChange{Container: current.Name, Field: "args", From: currentArgs, To: targetArgs})
+	}
+	changes = append(changes, diffEnv(current, target)...)
+	return changes
+}
+
+func diffEnv(current, target *kapi.Container) []podTemplateChange {
+	var changes []podTemplateChange
+	targetEnv := map[string]string{}
+	for _, env := range target.Env {
+		targetEnv[env.Name] = env.Value
+	}
+	currentEnv := map[string]string{}
+	for _, env := range current.Env {
+		currentEnv[env.Name] = env.Value
+	}
+
+	for _, env := range current.Env {
+		targetValue, ok := targetEnv[env.Name]
+		if !ok || targetValue != env.Value {
+			changes = append(changes, podTemplateChange{Container: current.Name, Field: "env/" + env.Name, From: env.Value, To: targetValue})
+		}
+	}
+	for _, env := range target.Env {
+		if _, ok := currentEnv[env.Name]; !ok {
+			changes = append(changes, podTemplateChange{Container: current.Name, Field: "env/" + env.Name, To: env.Value})
+		}
+	}
+	return changes
+}
@@ -3,19 +3,24 @@ package rollback
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	hashutil "k8s.io/kubernetes/pkg/util/hash"
 
 	v1 "github.com/openshift/openshift-apiserver/pkg/apps/apis/apps/v1"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 
 	"github.com/openshift/api/apps"
+	appsv1 "github.com/openshift/api/apps/v1"
 	appsclient "github.com/openshift/client-go/apps/clientset/versioned"
 	appsclienttyped "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
 
@@ -81,16 +86,23 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		return nil, newInvalidError(rollback, fmt.Sprintf("version %d is already the latest", rollback.Spec.Revision))
 	}
 
-	revision := from.Status.LatestVersion - 1
-	if rollback.Spec.Revision > 0 {
-		revision = rollback.Spec.Revision
-	}
-
 	// Find the target deployment and decode its config.
-	name := appsutil.DeploymentNameForConfigVersion(from.Name, revision)
-	targetDeployment, err := r.rn.ReplicationControllers(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return nil, newInvalidError(rollback, err.Error())
+	var targetDeployment *corev1.ReplicationController
+	if len(rollback.Spec.From.Name) > 0 {
+		targetDeployment, err = r.findRollbackTarget(ctx, namespace, from, rollback)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		revision := from.Status.LatestVersion - 1
+		if rollback.Spec.Revision > 0 {
+			revision = rollback.Spec.Revision
+		}
+		name := appsutil.DeploymentNameForConfigVersion(from.Name, revision)
+		targetDeployment, err = r.rn.ReplicationControllers(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, newInvalidError(rollback, err.Error())
+		}
 	}
 
 	fromConfig, err := appsserialization.DecodeDeploymentConfig(targetDeployment)
@@ -115,7 +127,65 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		return nil, apierrors.NewInternalError(err)
 	}
 
-	return r.generator.GenerateRollback(fromInternal, toConfig, &rollback.Spec)
+	result, err := r.generator.GenerateRollback(fromInternal, toConfig, &rollback.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if rollback.UpdatedAnnotations[includeDiffAnnotation] == "true" {
+		attachPodTemplateDiff(result, fromInternal.Spec.Template, toConfig.Spec.Template)
+	}
+	return result, nil
+}
+
+// findRollbackTarget resolves the rollback target named in spec.from, which
+// may be the name of a replication controller belonging to the config or the
+// pod template hash of an earlier deployment.
+func (r *REST) findRollbackTarget(ctx context.Context, namespace string, from *appsv1.DeploymentConfig, rollback *appsapi.DeploymentConfigRollback) (*corev1.ReplicationController, error) {
+	target, err := r.rn.ReplicationControllers(namespace).Get(ctx, rollback.Spec.From.Name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		if appsutil.DeploymentConfigNameFor(target) != rollback.Name {
+			return nil, newInvalidError(rollback, fmt.Sprintf("replication controller %q is not a deployment of config %q", target.Name, rollback.Name))
+		}
+	case apierrors.IsNotFound(err):
+		target, err = r.findDeploymentByTemplateHash(ctx, namespace, rollback)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, newInvalidError(rollback, err.Error())
+	}
+	if appsutil.DeploymentVersionFor(target) == from.Status.LatestVersion {
+		return nil, newInvalidError(rollback, fmt.Sprintf("deployment %q is already the latest", target.Name))
+	}
+	return target, nil
+}
+
+// findDeploymentByTemplateHash returns the deployment of the config whose pod
+// template hashes to the value given in spec.from.name.
+func (r *REST) findDeploymentByTemplateHash(ctx context.Context, namespace string, rollback *appsapi.DeploymentConfigRollback) (*corev1.ReplicationController, error) {
+	deployments, err := r.rn.ReplicationControllers(namespace).List(ctx, metav1.ListOptions{LabelSelector: appsutil.ConfigSelector(rollback.Name).String()})
+	if err != nil {
+		return nil, newInvalidError(rollback, err.Error())
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if deployment.Spec.Template == nil {
+			continue
+		}
+		if podTemplateHash(deployment.Spec.Template) == rollback.Spec.From.Name {
+			return deployment, nil
+		}
+	}
+	return nil, newInvalidError(rollback, fmt.Sprintf("no deployment of config %q matches name or template hash %q", rollback.Name, rollback.Spec.From.Name))
+}
+
+// podTemplateHash returns the hash identifying a deployment's pod template,
+// computed the same way the Deployment controller hashes templates.
+func podTemplateHash(template *corev1.PodTemplateSpec) string {
+	hasher := fnv.New32a()
+	hashutil.DeepHashObject(hasher, *template)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
 }
 
 func newInvalidError(rollback *appsapi.DeploymentConfigRollback, reason string) error {
@@ -14,6 +14,7 @@ import (
 
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	apiserverrest "k8s.io/apiserver/pkg/registry/rest"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
 
 	"github.com/openshift/api/apps"
 	appsv1 "github.com/openshift/api/apps/v1"
@@ -227,3 +228,119 @@ func TestCreateMissingDeploymentConfig(t *testing.T) {
 		t.Error("Unexpected result obj")
 	}
 }
+
+func TestCreateRollbackByName(t *testing.T) {
+	oc := &appsfake.Clientset{}
+	oc.AddReactor("get", "deploymentconfigs", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		return true, appstest.OkDeploymentConfig(3), nil
+	})
+	kc := &fake.Clientset{}
+	kc.AddReactor("get", "replicationcontrollers", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		deployment, _ := appsutil.MakeDeployment(appstest.OkDeploymentConfig(1))
+		return true, deployment, nil
+	})
+
+	obj, err := NewREST(oc, kc).Create(apirequest.NewDefaultContext(), &appsapi.DeploymentConfigRollback{
+		Name: "config",
+		Spec: appsapi.DeploymentConfigRollbackSpec{
+			From: kapi.ObjectReference{Name: "config-1"},
+		},
+	}, apiserverrest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, ok := obj.(*appsapi.DeploymentConfig); !ok {
+		t.Errorf("expected a deployment config, got a %#v", obj)
+	}
+}
+
+func TestCreateRollbackByNameOfLatest(t *testing.T) {
+	oc := &appsfake.Clientset{}
+	oc.AddReactor("get", "deploymentconfigs", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		return true, appstest.OkDeploymentConfig(2), nil
+	})
+	kc := &fake.Clientset{}
+	kc.AddReactor("get", "replicationcontrollers", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		deployment, _ := appsutil.MakeDeployment(appstest.OkDeploymentConfig(2))
+		return true, deployment, nil
+	})
+
+	_, err := NewREST(oc, kc).Create(apirequest.NewDefaultContext(), &appsapi.DeploymentConfigRollback{
+		Name: "config",
+		Spec: appsapi.DeploymentConfigRollbackSpec{
+			From: kapi.ObjectReference{Name: "config-2"},
+		},
+	}, apiserverrest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+
+	if err == nil || !strings.Contains(err.Error(), "already the latest") {
+		t.Errorf("expected an error when rolling back to the deployed target, got %v", err)
+	}
+}
+
+func TestCreateRollbackByTemplateHash(t *testing.T) {
+	deployment, _ := appsutil.MakeDeployment(appstest.OkDeploymentConfig(1))
+
+	oc := &appsfake.Clientset{}
+	oc.AddReactor("get", "deploymentconfigs", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		return true, appstest.OkDeploymentConfig(3), nil
+	})
+	kc := &fake.Clientset{}
+	kc.AddReactor("get", "replicationcontrollers", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		return true, nil, kerrors.NewNotFound(corev1.Resource("replicationController"), action.(clientgotesting.GetAction).GetName())
+	})
+	kc.AddReactor("list", "replicationcontrollers", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		return true, &corev1.ReplicationControllerList{Items: []corev1.ReplicationController{*deployment}}, nil
+	})
+
+	obj, err := NewREST(oc, kc).Create(apirequest.NewDefaultContext(), &appsapi.DeploymentConfigRollback{
+		Name: "config",
+		Spec: appsapi.DeploymentConfigRollbackSpec{
+			From: kapi.ObjectReference{Name: podTemplateHash(deployment.Spec.Template)},
+		},
+	}, apiserverrest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, ok := obj.(*appsapi.DeploymentConfig); !ok {
+		t.Errorf("expected a deployment config, got a %#v", obj)
+	}
+}
+
+func TestCreateRollbackWithDiff(t *testing.T) {
+	oc := &appsfake.Clientset{}
+	oc.AddReactor("get", "deploymentconfigs", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		config := appstest.OkDeploymentConfig(2)
+		config.Spec.Template.Spec.Containers[0].Image = "registry:8080/repo1:ref1b"
+		return true, config, nil
+	})
+	kc := &fake.Clientset{}
+	kc.AddReactor("get", "replicationcontrollers", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		deployment, _ := appsutil.MakeDeployment(appstest.OkDeploymentConfig(1))
+		return true, deployment, nil
+	})
+
+	obj, err := NewREST(oc, kc).Create(apirequest.NewDefaultContext(), &appsapi.DeploymentConfigRollback{
+		Name:               "config",
+		UpdatedAnnotations: map[string]string{includeDiffAnnotation: "true"},
+		Spec: appsapi.DeploymentConfigRollbackSpec{
+			Revision: 1,
+		},
+	}, apiserverrest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	config := obj.(*appsapi.DeploymentConfig)
+	if _, ok := config.Annotations[includeDiffAnnotation]; ok {
+		t.Errorf("expected the diff request annotation to be removed, got %v", config.Annotations)
+	}
+	diff := config.Annotations[podTemplateDiffAnnotation]
+	if !strings.Contains(diff, `"field":"image"`) || !strings.Contains(diff, "ref1b") {
+		t.Errorf("expected an image change in the diff, got %q", diff)
+	}
+}
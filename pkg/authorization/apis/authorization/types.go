@@ -432,6 +432,12 @@ type UserRestriction struct {
 
 	// Selectors specifies a list of label selectors over user labels.
 	Selectors []metav1.LabelSelector
+
+	// GroupSelectors specifies a list of label selectors over the labels of
+	// user.openshift.io Group objects, such as the labels applied by LDAP
+	// group sync.  A user matches when it is a member, directly or through
+	// nested groups, of any selected group.
+	GroupSelectors []metav1.LabelSelector
 }
 
 // GroupRestriction matches a group either by a string match on the group name
@@ -454,6 +460,12 @@ type ServiceAccountRestriction struct {
 	// Namespaces specifies a list of literal namespace names.  ServiceAccounts
 	// from inside the whitelisted namespaces are allowed to be bound to roles.
 	Namespaces []string
+
+	// GroupSelectors specifies a list of label selectors over the labels of
+	// user.openshift.io Group objects.  A service account matches when its
+	// username is a member, directly or through nested groups, of any
+	// selected group.
+	GroupSelectors []metav1.LabelSelector
 }
 
 // ServiceAccountReference specifies a service account and namespace by their
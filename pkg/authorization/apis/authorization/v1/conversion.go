@@ -1,8 +1,11 @@
 package v1
 
 import (
+	"encoding/json"
+
 	v1 "github.com/openshift/api/authorization/v1"
 	newer "github.com/openshift/openshift-apiserver/pkg/authorization/apis/authorization"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -170,6 +173,98 @@ func Convert_authorization_ClusterRoleBinding_To_v1_ClusterRoleBinding(in *newer
 	return nil
 }
 
+// GroupSelectorsAnnotation carries the group selectors of a
+// RoleBindingRestriction's user or service-account restriction on the v1
+// serialization, which has no fields for them.  The value is a JSON object
+// with optional userRestriction and serviceAccountRestriction selector
+// lists.
+const GroupSelectorsAnnotation = "authorization.openshift.io/group-selectors"
+
+// GroupSelectors is the serialized form of the group selectors of a
+// RoleBindingRestriction's restrictions.
+type GroupSelectors struct {
+	UserRestriction           []metav1.LabelSelector `json:"userRestriction,omitempty"`
+	ServiceAccountRestriction []metav1.LabelSelector `json:"serviceAccountRestriction,omitempty"`
+}
+
+func Convert_authorization_UserRestriction_To_v1_UserRestriction(in *newer.UserRestriction, out *v1.UserRestriction, s conversion.Scope) error {
+	// in.GroupSelectors is carried by the GroupSelectorsAnnotation on the
+	// enclosing RoleBindingRestriction.
+	return autoConvert_authorization_UserRestriction_To_v1_UserRestriction(in, out, s)
+}
+
+func Convert_authorization_ServiceAccountRestriction_To_v1_ServiceAccountRestriction(in *newer.ServiceAccountRestriction, out *v1.ServiceAccountRestriction, s conversion.Scope) error {
+	// in.GroupSelectors is carried by the GroupSelectorsAnnotation on the
+	// enclosing RoleBindingRestriction.
+	return autoConvert_authorization_ServiceAccountRestriction_To_v1_ServiceAccountRestriction(in, out, s)
+}
+
+func Convert_v1_RoleBindingRestriction_To_authorization_RoleBindingRestriction(in *v1.RoleBindingRestriction, out *newer.RoleBindingRestriction, s conversion.Scope) error {
+	if err := autoConvert_v1_RoleBindingRestriction_To_authorization_RoleBindingRestriction(in, out, s); err != nil {
+		return err
+	}
+
+	value, ok := in.Annotations[GroupSelectorsAnnotation]
+	if !ok {
+		return nil
+	}
+	parsed := GroupSelectors{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		// a stored object may carry a malformed value that predates any
+		// validation; failing conversion would make it unreadable, so the
+		// value is left in place for validation to reject on writes
+		return nil
+	}
+	if out.Spec.UserRestriction != nil {
+		out.Spec.UserRestriction.GroupSelectors = parsed.UserRestriction
+	}
+	if out.Spec.ServiceAccountRestriction != nil {
+		out.Spec.ServiceAccountRestriction.GroupSelectors = parsed.ServiceAccountRestriction
+	}
+
+	annotations := make(map[string]string, len(in.Annotations))
+	for k, v := range in.Annotations {
+		if k == GroupSelectorsAnnotation {
+			continue
+		}
+		annotations[k] = v
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	out.Annotations = annotations
+	return nil
+}
+
+func Convert_authorization_RoleBindingRestriction_To_v1_RoleBindingRestriction(in *newer.RoleBindingRestriction, out *v1.RoleBindingRestriction, s conversion.Scope) error {
+	if err := autoConvert_authorization_RoleBindingRestriction_To_v1_RoleBindingRestriction(in, out, s); err != nil {
+		return err
+	}
+
+	value := GroupSelectors{}
+	if in.Spec.UserRestriction != nil {
+		value.UserRestriction = in.Spec.UserRestriction.GroupSelectors
+	}
+	if in.Spec.ServiceAccountRestriction != nil {
+		value.ServiceAccountRestriction = in.Spec.ServiceAccountRestriction.GroupSelectors
+	}
+	if len(value.UserRestriction) == 0 && len(value.ServiceAccountRestriction) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(&value)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(out.Annotations)+1)
+	for k, v := range out.Annotations {
+		annotations[k] = v
+	}
+	annotations[GroupSelectorsAnnotation] = string(data)
+	out.Annotations = annotations
+	return nil
+}
+
 func AddFieldSelectorKeyConversions(scheme *runtime.Scheme) error {
 	return nil
 }
@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"testing"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	v1 "github.com/openshift/api/authorization/v1"
+	internal "github.com/openshift/openshift-apiserver/pkg/authorization/apis/authorization"
+)
+
+func TestRoleBindingRestrictionGroupSelectorsConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := RegisterConversions(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	selector := metav1.LabelSelector{
+		MatchLabels: map[string]string{"openshift.io/ldap.host": "ldap.example.com"},
+	}
+	in := &internal.RoleBindingRestriction{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "match-ldap-users"},
+		Spec: internal.RoleBindingRestrictionSpec{
+			UserRestriction: &internal.UserRestriction{
+				GroupSelectors: []metav1.LabelSelector{selector},
+			},
+		},
+	}
+
+	external := &v1.RoleBindingRestriction{}
+	if err := scheme.Convert(in, external, nil); err != nil {
+		t.Fatal(err)
+	}
+	if external.Spec.UserRestriction == nil || len(external.Spec.UserRestriction.Selectors) != 0 {
+		t.Errorf("unexpected external user restriction: %#v", external.Spec.UserRestriction)
+	}
+	expected := `{"userRestriction":[{"matchLabels":{"openshift.io/ldap.host":"ldap.example.com"}}]}`
+	if external.Annotations[GroupSelectorsAnnotation] != expected {
+		t.Errorf("expected annotation %s, got %q", expected, external.Annotations[GroupSelectorsAnnotation])
+	}
+
+	roundTripped := &internal.RoleBindingRestriction{}
+	if err := scheme.Convert(external, roundTripped, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := roundTripped.Annotations[GroupSelectorsAnnotation]; ok {
+		t.Errorf("expected the annotation to be dropped on the internal object, got %v", roundTripped.Annotations)
+	}
+	if !apiequality.Semantic.DeepEqual(in, roundTripped) {
+		t.Errorf("round trip changed the object: %#v", roundTripped)
+	}
+}
+
+func TestRoleBindingRestrictionWithoutGroupSelectorsHasNoAnnotation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := RegisterConversions(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	in := &internal.RoleBindingRestriction{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "match-literal-users"},
+		Spec: internal.RoleBindingRestrictionSpec{
+			UserRestriction: &internal.UserRestriction{Users: []string{"alice"}},
+		},
+	}
+
+	external := &v1.RoleBindingRestriction{}
+	if err := scheme.Convert(in, external, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := external.Annotations[GroupSelectorsAnnotation]; ok {
+		t.Errorf("expected no annotation, got %v", external.Annotations)
+	}
+}
+
+func TestRoleBindingRestrictionMalformedGroupSelectorsTolerated(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := RegisterConversions(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	// a malformed annotation on a stored object is tolerated so the object
+	// stays readable
+	external := &v1.RoleBindingRestriction{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "match-ldap-users",
+			Annotations: map[string]string{GroupSelectorsAnnotation: "not json"},
+		},
+		Spec: v1.RoleBindingRestrictionSpec{
+			UserRestriction: &v1.UserRestriction{Users: []string{"alice"}},
+		},
+	}
+
+	tolerated := &internal.RoleBindingRestriction{}
+	if err := scheme.Convert(external, tolerated, nil); err != nil {
+		t.Fatalf("expected a malformed annotation to be tolerated, got %v", err)
+	}
+	if tolerated.Spec.UserRestriction == nil || len(tolerated.Spec.UserRestriction.GroupSelectors) != 0 {
+		t.Errorf("expected no group selectors from a malformed annotation, got %#v", tolerated.Spec.UserRestriction)
+	}
+	if tolerated.Annotations[GroupSelectorsAnnotation] != "not json" {
+		t.Errorf("expected the malformed annotation to be left in place, got %v", tolerated.Annotations)
+	}
+}
@@ -807,11 +807,6 @@ func autoConvert_v1_RoleBindingRestriction_To_authorization_RoleBindingRestricti
 	return nil
 }
 
-// Convert_v1_RoleBindingRestriction_To_authorization_RoleBindingRestriction is an autogenerated conversion function.
-func Convert_v1_RoleBindingRestriction_To_authorization_RoleBindingRestriction(in *v1.RoleBindingRestriction, out *authorization.RoleBindingRestriction, s conversion.Scope) error {
-	return autoConvert_v1_RoleBindingRestriction_To_authorization_RoleBindingRestriction(in, out, s)
-}
-
 func autoConvert_authorization_RoleBindingRestriction_To_v1_RoleBindingRestriction(in *authorization.RoleBindingRestriction, out *v1.RoleBindingRestriction, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_authorization_RoleBindingRestrictionSpec_To_v1_RoleBindingRestrictionSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -820,14 +815,19 @@ func autoConvert_authorization_RoleBindingRestriction_To_v1_RoleBindingRestricti
 	return nil
 }
 
-// Convert_authorization_RoleBindingRestriction_To_v1_RoleBindingRestriction is an autogenerated conversion function.
-func Convert_authorization_RoleBindingRestriction_To_v1_RoleBindingRestriction(in *authorization.RoleBindingRestriction, out *v1.RoleBindingRestriction, s conversion.Scope) error {
-	return autoConvert_authorization_RoleBindingRestriction_To_v1_RoleBindingRestriction(in, out, s)
-}
-
 func autoConvert_v1_RoleBindingRestrictionList_To_authorization_RoleBindingRestrictionList(in *v1.RoleBindingRestrictionList, out *authorization.RoleBindingRestrictionList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]authorization.RoleBindingRestriction)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]authorization.RoleBindingRestriction, len(*in))
+		for i := range *in {
+			if err := Convert_v1_RoleBindingRestriction_To_authorization_RoleBindingRestriction(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -838,7 +838,17 @@ func Convert_v1_RoleBindingRestrictionList_To_authorization_RoleBindingRestricti
 
 func autoConvert_authorization_RoleBindingRestrictionList_To_v1_RoleBindingRestrictionList(in *authorization.RoleBindingRestrictionList, out *v1.RoleBindingRestrictionList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]v1.RoleBindingRestriction)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1.RoleBindingRestriction, len(*in))
+		for i := range *in {
+			if err := Convert_authorization_RoleBindingRestriction_To_v1_RoleBindingRestriction(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -848,9 +858,25 @@ func Convert_authorization_RoleBindingRestrictionList_To_v1_RoleBindingRestricti
 }
 
 func autoConvert_v1_RoleBindingRestrictionSpec_To_authorization_RoleBindingRestrictionSpec(in *v1.RoleBindingRestrictionSpec, out *authorization.RoleBindingRestrictionSpec, s conversion.Scope) error {
-	out.UserRestriction = (*authorization.UserRestriction)(unsafe.Pointer(in.UserRestriction))
+	if in.UserRestriction != nil {
+		in, out := &in.UserRestriction, &out.UserRestriction
+		*out = new(authorization.UserRestriction)
+		if err := Convert_v1_UserRestriction_To_authorization_UserRestriction(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.UserRestriction = nil
+	}
 	out.GroupRestriction = (*authorization.GroupRestriction)(unsafe.Pointer(in.GroupRestriction))
-	out.ServiceAccountRestriction = (*authorization.ServiceAccountRestriction)(unsafe.Pointer(in.ServiceAccountRestriction))
+	if in.ServiceAccountRestriction != nil {
+		in, out := &in.ServiceAccountRestriction, &out.ServiceAccountRestriction
+		*out = new(authorization.ServiceAccountRestriction)
+		if err := Convert_v1_ServiceAccountRestriction_To_authorization_ServiceAccountRestriction(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.ServiceAccountRestriction = nil
+	}
 	return nil
 }
 
@@ -860,9 +886,25 @@ func Convert_v1_RoleBindingRestrictionSpec_To_authorization_RoleBindingRestricti
 }
 
 func autoConvert_authorization_RoleBindingRestrictionSpec_To_v1_RoleBindingRestrictionSpec(in *authorization.RoleBindingRestrictionSpec, out *v1.RoleBindingRestrictionSpec, s conversion.Scope) error {
-	out.UserRestriction = (*v1.UserRestriction)(unsafe.Pointer(in.UserRestriction))
+	if in.UserRestriction != nil {
+		in, out := &in.UserRestriction, &out.UserRestriction
+		*out = new(v1.UserRestriction)
+		if err := Convert_authorization_UserRestriction_To_v1_UserRestriction(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.UserRestriction = nil
+	}
 	out.GroupRestriction = (*v1.GroupRestriction)(unsafe.Pointer(in.GroupRestriction))
-	out.ServiceAccountRestriction = (*v1.ServiceAccountRestriction)(unsafe.Pointer(in.ServiceAccountRestriction))
+	if in.ServiceAccountRestriction != nil {
+		in, out := &in.ServiceAccountRestriction, &out.ServiceAccountRestriction
+		*out = new(v1.ServiceAccountRestriction)
+		if err := Convert_authorization_ServiceAccountRestriction_To_v1_ServiceAccountRestriction(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.ServiceAccountRestriction = nil
+	}
 	return nil
 }
 
@@ -989,14 +1031,10 @@ func Convert_v1_ServiceAccountRestriction_To_authorization_ServiceAccountRestric
 func autoConvert_authorization_ServiceAccountRestriction_To_v1_ServiceAccountRestriction(in *authorization.ServiceAccountRestriction, out *v1.ServiceAccountRestriction, s conversion.Scope) error {
 	out.ServiceAccounts = *(*[]v1.ServiceAccountReference)(unsafe.Pointer(&in.ServiceAccounts))
 	out.Namespaces = *(*[]string)(unsafe.Pointer(&in.Namespaces))
+	// WARNING: in.GroupSelectors requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_authorization_ServiceAccountRestriction_To_v1_ServiceAccountRestriction is an autogenerated conversion function.
-func Convert_authorization_ServiceAccountRestriction_To_v1_ServiceAccountRestriction(in *authorization.ServiceAccountRestriction, out *v1.ServiceAccountRestriction, s conversion.Scope) error {
-	return autoConvert_authorization_ServiceAccountRestriction_To_v1_ServiceAccountRestriction(in, out, s)
-}
-
 func autoConvert_v1_SubjectAccessReview_To_authorization_SubjectAccessReview(in *v1.SubjectAccessReview, out *authorization.SubjectAccessReview, s conversion.Scope) error {
 	if err := Convert_v1_Action_To_authorization_Action(&in.Action, &out.Action, s); err != nil {
 		return err
@@ -1155,10 +1193,6 @@ func autoConvert_authorization_UserRestriction_To_v1_UserRestriction(in *authori
 	out.Users = *(*[]string)(unsafe.Pointer(&in.Users))
 	out.Groups = *(*[]string)(unsafe.Pointer(&in.Groups))
 	out.Selectors = *(*[]metav1.LabelSelector)(unsafe.Pointer(&in.Selectors))
+	// WARNING: in.GroupSelectors requires manual conversion: does not exist in peer-type
 	return nil
 }
-
-// Convert_authorization_UserRestriction_To_v1_UserRestriction is an autogenerated conversion function.
-func Convert_authorization_UserRestriction_To_v1_UserRestriction(in *authorization.UserRestriction, out *v1.UserRestriction, s conversion.Scope) error {
-	return autoConvert_authorization_UserRestriction_To_v1_UserRestriction(in, out, s)
-}
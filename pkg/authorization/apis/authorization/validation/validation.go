@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -15,6 +16,7 @@ import (
 
 	uservalidation "github.com/openshift/apiserver-library-go/pkg/apivalidation"
 	authorizationapi "github.com/openshift/openshift-apiserver/pkg/authorization/apis/authorization"
+	authorizationapiv1 "github.com/openshift/openshift-apiserver/pkg/authorization/apis/authorization/v1"
 )
 
 func ValidateSelfSubjectRulesReview(review *authorizationapi.SelfSubjectRulesReview) field.ErrorList {
@@ -287,6 +289,25 @@ func ValidateRoleBindingRestriction(rbr *authorizationapi.RoleBindingRestriction
 	allErrs = append(allErrs,
 		ValidateRoleBindingRestrictionSpec(&rbr.Spec, field.NewPath("spec"))...)
 
+	allErrs = append(allErrs, validatePolicyAnnotations(rbr.Annotations,
+		field.NewPath("metadata", "annotations"))...)
+
+	return allErrs
+}
+
+// validatePolicyAnnotations rejects malformed values of the annotations that
+// conversion translates into internal fields.  Conversion tolerates bad
+// values so stored role binding restrictions always stay readable; a value
+// only survives to the internal object when it could not be decoded, and it
+// is rejected here on the write path instead.
+func validatePolicyAnnotations(annotations map[string]string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if value, ok := annotations[authorizationapiv1.GroupSelectorsAnnotation]; ok {
+		selectors := authorizationapiv1.GroupSelectors{}
+		if err := json.Unmarshal([]byte(value), &selectors); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(authorizationapiv1.GroupSelectorsAnnotation), value, fmt.Sprintf("must be a JSON object of group selectors: %v", err)))
+		}
+	}
 	return allErrs
 }
 
@@ -341,7 +362,8 @@ func ValidateRoleBindingRestrictionUser(user *authorizationapi.UserRestriction,
 	allErrs := field.ErrorList{}
 	const invalidMsg = `must specify at least one user, group, or label selector`
 
-	if !(len(user.Users) > 0 || len(user.Groups) > 0 || len(user.Selectors) > 0) {
+	if !(len(user.Users) > 0 || len(user.Groups) > 0 || len(user.Selectors) > 0 ||
+		len(user.GroupSelectors) > 0) {
 		allErrs = append(allErrs, field.Required(fld.Child("users"), invalidMsg))
 	}
 
@@ -351,6 +373,12 @@ func ValidateRoleBindingRestrictionUser(user *authorizationapi.UserRestriction,
 				fld.Child("selector").Index(i))...)
 	}
 
+	for i, selector := range user.GroupSelectors {
+		allErrs = append(allErrs,
+			unversionedvalidation.ValidateLabelSelector(&selector,
+				fld.Child("groupselector").Index(i))...)
+	}
+
 	return allErrs
 }
 
@@ -373,12 +401,18 @@ func ValidateRoleBindingRestrictionGroup(group *authorizationapi.GroupRestrictio
 
 func ValidateRoleBindingRestrictionServiceAccount(sa *authorizationapi.ServiceAccountRestriction, fld *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	const invalidMsg = `must specify at least one service account or namespace`
+	const invalidMsg = `must specify at least one service account, namespace, or group selector`
 
-	if !(len(sa.ServiceAccounts) > 0 || len(sa.Namespaces) > 0) {
+	if !(len(sa.ServiceAccounts) > 0 || len(sa.Namespaces) > 0 || len(sa.GroupSelectors) > 0) {
 		allErrs = append(allErrs,
 			field.Required(fld.Child("serviceaccounts"), invalidMsg))
 	}
 
+	for i, selector := range sa.GroupSelectors {
+		allErrs = append(allErrs,
+			unversionedvalidation.ValidateLabelSelector(&selector,
+				fld.Child("groupselector").Index(i))...)
+	}
+
 	return allErrs
 }
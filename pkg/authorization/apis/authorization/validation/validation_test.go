@@ -8,6 +8,7 @@ import (
 	kapi "k8s.io/kubernetes/pkg/apis/core"
 
 	authorizationapi "github.com/openshift/openshift-apiserver/pkg/authorization/apis/authorization"
+	authorizationapiv1 "github.com/openshift/openshift-apiserver/pkg/authorization/apis/authorization/v1"
 )
 
 func TestValidateRoleBinding(t *testing.T) {
@@ -374,3 +375,33 @@ func TestValidateRoleUpdate(t *testing.T) {
 		}
 	}
 }
+
+func TestValidatePolicyAnnotations(t *testing.T) {
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		expectedErrors int
+	}{
+		{
+			name:        "no policy annotations",
+			annotations: map[string]string{"other": "value"},
+		},
+		{
+			name:        "valid group selectors annotation",
+			annotations: map[string]string{authorizationapiv1.GroupSelectorsAnnotation: `{"userRestriction":[{"matchLabels":{"a":"b"}}]}`},
+		},
+		{
+			name:           "malformed group selectors annotation",
+			annotations:    map[string]string{authorizationapiv1.GroupSelectorsAnnotation: "not json"},
+			expectedErrors: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validatePolicyAnnotations(tc.annotations, field.NewPath("metadata", "annotations"))
+			if len(errs) != tc.expectedErrors {
+				t.Errorf("expected %d errors, got %v", tc.expectedErrors, errs)
+			}
+		})
+	}
+}
@@ -717,6 +717,13 @@ func (in *ServiceAccountRestriction) DeepCopyInto(out *ServiceAccountRestriction
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.GroupSelectors != nil {
+		in, out := &in.GroupSelectors, &out.GroupSelectors
+		*out = make([]v1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -911,6 +918,13 @@ func (in *UserRestriction) DeepCopyInto(out *UserRestriction) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.GroupSelectors != nil {
+		in, out := &in.GroupSelectors, &out.GroupSelectors
+		*out = make([]v1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
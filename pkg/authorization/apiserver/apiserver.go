@@ -128,10 +128,12 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 
 	v1Storage := map[string]rest.Storage{}
 	v1Storage["resourceAccessReviews"] = resourceAccessReviewStorage
+	v1Storage["resourceAccessReviews/details"] = resourceaccessreview.NewDetailsREST(c.GenericConfig.Authorization.Authorizer, c.ExtraConfig.SubjectLocator)
 	v1Storage["subjectAccessReviews"] = subjectAccessReviewStorage
 	v1Storage["localSubjectAccessReviews"] = localSubjectAccessReviewStorage
 	v1Storage["localResourceAccessReviews"] = localResourceAccessReviewStorage
 	v1Storage["selfSubjectRulesReviews"] = selfSubjectRulesReviewStorage
+	v1Storage["selfSubjectRulesReviews/details"] = selfsubjectrulesreview.NewDetailsREST(c.ExtraConfig.RuleResolver, c.ExtraConfig.KubeInformers.Rbac().V1().ClusterRoles().Lister())
 	v1Storage["subjectRulesReviews"] = subjectRulesReviewStorage
 	v1Storage["roles"] = role.NewREST(rbacClient.RESTClient())
 	v1Storage["roleBindings"] = rolebinding.NewREST(rbacClient.RESTClient())
@@ -0,0 +1,182 @@
+package resourceaccessreview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+	kauthorizer "k8s.io/apiserver/pkg/authorization/authorizer"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/kubernetes/plugin/pkg/auth/authorizer/rbac"
+
+	authorization "github.com/openshift/api/authorization"
+	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
+	authorizationapi "github.com/openshift/openshift-apiserver/pkg/authorization/apis/authorization"
+	"github.com/openshift/openshift-apiserver/pkg/authorization/apiserver/registry/util"
+)
+
+// wildcardVerbProbe is a verb no role would ever name literally.  RBAC matches
+// a request verb either because a rule lists it or because the rule's verbs
+// contain "*", so any subject the locator allows for this probe verb can only
+// hold its access through a wildcard verb grant.
+const wildcardVerbProbe = "authorization.openshift.io/wildcard-probe"
+
+// AccessDetails is the response body of the resourceaccessreviews details
+// subresource.
+type AccessDetails struct {
+	// Namespace is the namespace used for the access review
+	Namespace string `json:"namespace,omitempty"`
+	// Users is the full list of users who can perform the action, including
+	// those whose access comes through wildcard verb grants.
+	Users []string `json:"users"`
+	// Groups is the full list of groups who can perform the action.
+	Groups []string `json:"groups"`
+	// WildcardUsers is the subset of Users whose access comes through a role
+	// granting the wildcard verb.  Users not listed here hold a grant that
+	// names the reviewed verb directly.
+	WildcardUsers []string `json:"wildcardUsers,omitempty"`
+	// WildcardGroups is the subset of Groups whose access comes through a
+	// role granting the wildcard verb.
+	WildcardGroups []string `json:"wildcardGroups,omitempty"`
+	// EvaluationError can appear in combination with the subject lists.  It
+	// means some error happened during evaluation that may have prevented
+	// additional subjects from being populated.
+	EvaluationError string `json:"evaluationError,omitempty"`
+}
+
+// DetailsREST answers "who can do this, and why": it enumerates the same
+// subjects a ResourceAccessReview does — the action may name a subresource
+// such as builds/instantiate or imagestreams/layers — and additionally
+// reports which of those subjects hold their access only through wildcard
+// verb grants.  The review response is a stable API object that cannot grow
+// new fields, so the expanded answer is served as a subresource with a plain
+// JSON response.
+type DetailsREST struct {
+	authorizer     kauthorizer.Authorizer
+	subjectLocator rbac.SubjectLocator
+}
+
+var _ rest.Connecter = &DetailsREST{}
+var _ rest.Scoper = &DetailsREST{}
+
+// NewDetailsREST returns the handler for the
+// resourceaccessreviews/{name}/details subresource.  The name is a path
+// placeholder and is ignored; the action under review is taken from the
+// query string.
+func NewDetailsREST(authorizer kauthorizer.Authorizer, subjectLocator rbac.SubjectLocator) *DetailsREST {
+	return &DetailsREST{authorizer: authorizer, subjectLocator: subjectLocator}
+}
+
+// New is only implemented to make DetailsREST implement RESTStorage
+func (r *DetailsREST) New() runtime.Object {
+	return &authorizationapi.ResourceAccessReview{}
+}
+
+func (r *DetailsREST) NamespaceScoped() bool {
+	return false
+}
+
+// ConnectMethods returns GET, the only supported method
+func (r *DetailsREST) ConnectMethods() []string {
+	return []string{"GET"}
+}
+
+// NewConnectOptions returns an empty options object
+func (r *DetailsREST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that enumerates the subjects allowed to perform
+// the action described by the query string, distinguishing direct from
+// wildcard verb grants.
+func (r *DetailsREST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	callingUser, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewInternalError(errors.New("missing user on request"))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		action := authorizationapi.Action{
+			Namespace:    query.Get("namespace"),
+			Verb:         query.Get("verb"),
+			Group:        query.Get("group"),
+			Version:      query.Get("version"),
+			Resource:     query.Get("resource"),
+			ResourceName: query.Get("resourceName"),
+		}
+		if len(action.Verb) == 0 || len(action.Resource) == 0 {
+			responder.Error(kapierrors.NewBadRequest("verb and resource are required"))
+			return
+		}
+		// reviewing a namespace requires the same access a LocalResourceAccessReview
+		// in that namespace does; a cluster scoped review is covered by the RBAC
+		// check on this subresource itself
+		if len(action.Namespace) > 0 {
+			if err := r.isAllowed(req.Context(), callingUser, action.Namespace); err != nil {
+				responder.Error(err)
+				return
+			}
+		}
+
+		attributes := util.ToDefaultAuthorizationAttributes(nil, action.Namespace, action)
+		subjects, err := r.subjectLocator.AllowedSubjects(attributes)
+		users, groups := authorizationutil.RBACSubjectsToUsersAndGroups(subjects, attributes.GetNamespace())
+
+		probeAction := action
+		probeAction.Verb = wildcardVerbProbe
+		probeAttributes := util.ToDefaultAuthorizationAttributes(nil, action.Namespace, probeAction)
+		probeSubjects, probeErr := r.subjectLocator.AllowedSubjects(probeAttributes)
+		probeUsers, probeGroups := authorizationutil.RBACSubjectsToUsersAndGroups(probeSubjects, attributes.GetNamespace())
+
+		userSet := sets.NewString(users...)
+		groupSet := sets.NewString(groups...)
+		result := AccessDetails{
+			Namespace:      action.Namespace,
+			Users:          userSet.List(),
+			Groups:         groupSet.List(),
+			WildcardUsers:  userSet.Intersection(sets.NewString(probeUsers...)).List(),
+			WildcardGroups: groupSet.Intersection(sets.NewString(probeGroups...)).List(),
+		}
+		switch {
+		case err != nil:
+			result.EvaluationError = err.Error()
+		case probeErr != nil:
+			result.EvaluationError = probeErr.Error()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(&result); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+// isAllowed checks to see if the current user has rights to issue a LocalResourceAccessReview on the namespace they're attempting to review
+func (r *DetailsREST) isAllowed(ctx context.Context, user user.Info, namespace string) error {
+	localRARAttributes := kauthorizer.AttributesRecord{
+		User:            user,
+		Verb:            "create",
+		Namespace:       namespace,
+		Resource:        "localresourceaccessreviews",
+		ResourceRequest: true,
+	}
+	authorized, reason, err := r.authorizer.Authorize(ctx, localRARAttributes)
+
+	if err != nil {
+		return kapierrors.NewForbidden(authorization.Resource(localRARAttributes.GetResource()), localRARAttributes.GetName(), err)
+	}
+	if authorized != kauthorizer.DecisionAllow {
+		forbiddenError := kapierrors.NewForbidden(authorization.Resource(localRARAttributes.GetResource()), localRARAttributes.GetName(), errors.New("") /*discarded*/)
+		forbiddenError.ErrStatus.Message = reason
+		return forbiddenError
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package resourceaccessreview
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+	kauthorizer "k8s.io/apiserver/pkg/authorization/authorizer"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+type detailsTestLocator struct {
+	subjectsByVerb map[string][]rbacv1.Subject
+
+	actualAttributes []kauthorizer.Attributes
+}
+
+func (l *detailsTestLocator) AllowedSubjects(attributes kauthorizer.Attributes) ([]rbacv1.Subject, error) {
+	l.actualAttributes = append(l.actualAttributes, attributes)
+	return l.subjectsByVerb[attributes.GetVerb()], nil
+}
+
+func TestDetailsDistinguishesWildcardGrants(t *testing.T) {
+	locator := &detailsTestLocator{subjectsByVerb: map[string][]rbacv1.Subject{
+		// "create" is granted to alice directly and to bob and the builders
+		// group through wildcard verbs; only the wildcard holders answer the
+		// probe verb, which no rule names literally
+		"create": {
+			{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"},
+			{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "bob"},
+			{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "builders"},
+		},
+		wildcardVerbProbe: {
+			{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "bob"},
+			{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "builders"},
+		},
+	}}
+	storage := NewDetailsREST(&testAuthorizer{}, locator)
+
+	ctx := apirequest.WithUser(context.Background(), &user.DefaultInfo{Name: "admin"})
+	handler, err := storage.Connect(ctx, "current", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/details?verb=create&group=build.openshift.io&resource=builds/instantiate", nil))
+
+	result := AccessDetails{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("unable to decode response %q: %v", recorder.Body.String(), err)
+	}
+	if e, a := []string{"alice", "bob"}, result.Users; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected users %v, got %v", e, a)
+	}
+	if e, a := []string{"builders"}, result.Groups; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected groups %v, got %v", e, a)
+	}
+	if e, a := []string{"bob"}, result.WildcardUsers; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected wildcard users %v, got %v", e, a)
+	}
+	if e, a := []string{"builders"}, result.WildcardGroups; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected wildcard groups %v, got %v", e, a)
+	}
+	if len(result.EvaluationError) != 0 {
+		t.Errorf("unexpected evaluation error: %v", result.EvaluationError)
+	}
+
+	// both the review and the probe must split the action into resource and
+	// subresource so rules written against builds/instantiate match
+	if len(locator.actualAttributes) != 2 {
+		t.Fatalf("expected two locator calls, got %d", len(locator.actualAttributes))
+	}
+	for _, attributes := range locator.actualAttributes {
+		if attributes.GetResource() != "builds" || attributes.GetSubresource() != "instantiate" {
+			t.Errorf("expected builds/instantiate attributes, got %v/%v", attributes.GetResource(), attributes.GetSubresource())
+		}
+	}
+}
+
+func TestDetailsRequiresVerbAndResource(t *testing.T) {
+	storage := NewDetailsREST(&testAuthorizer{}, &detailsTestLocator{})
+
+	ctx := apirequest.WithUser(context.Background(), &user.DefaultInfo{Name: "admin"})
+	responder := &fakeResponder{t: t}
+	handler, err := storage.Connect(ctx, "current", nil, responder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/details?resource=builds", nil))
+	if responder.calledErr == nil {
+		t.Fatal("expected a bad request error when the verb is missing")
+	}
+}
+
+func TestDetailsDeniedNamespace(t *testing.T) {
+	storage := NewDetailsREST(&testAuthorizer{deniedNamespaces: sets.NewString("foo")}, &detailsTestLocator{})
+
+	ctx := apirequest.WithUser(context.Background(), &user.DefaultInfo{Name: "admin"})
+	responder := &fakeResponder{t: t}
+	handler, err := storage.Connect(ctx, "current", nil, responder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/details?verb=get&resource=pods&namespace=foo", nil))
+	if responder.calledErr == nil {
+		t.Fatal("expected a forbidden error for the denied namespace")
+	}
+}
+
+type fakeResponder struct {
+	t         *testing.T
+	calledErr error
+}
+
+func (r *fakeResponder) Object(statusCode int, obj runtime.Object) {
+	r.t.Fatalf("unexpected object response: %v", obj)
+}
+
+func (r *fakeResponder) Error(err error) {
+	r.calledErr = err
+}
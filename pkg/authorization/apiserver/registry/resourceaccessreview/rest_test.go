@@ -109,6 +109,35 @@ func TestNoErrors(t *testing.T) {
 	test.runTest(t)
 }
 
+func TestSubresourceAttributes(t *testing.T) {
+	authorizer := &testAuthorizer{
+		subjects: []rbacv1.Subject{
+			{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "one"},
+		},
+	}
+	test := &resourceAccessTest{
+		authorizer: authorizer,
+		reviewRequest: &authorizationapi.ResourceAccessReview{
+			Action: authorizationapi.Action{
+				Verb:     "create",
+				Group:    "build.openshift.io",
+				Resource: "builds/instantiate",
+			},
+		},
+	}
+
+	test.runTest(t)
+
+	// the locator must see the action split into resource and subresource so
+	// rules written against builds/instantiate (or a wildcard) match
+	if e, a := "builds", authorizer.actualAttributes.GetResource(); e != a {
+		t.Errorf("expected resource %q, got %q", e, a)
+	}
+	if e, a := "instantiate", authorizer.actualAttributes.GetSubresource(); e != a {
+		t.Errorf("expected subresource %q, got %q", e, a)
+	}
+}
+
 func (r *resourceAccessTest) runTest(t *testing.T) {
 	storage := REST{r.authorizer, r.authorizer}
 
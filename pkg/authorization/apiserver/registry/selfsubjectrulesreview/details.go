@@ -0,0 +1,210 @@
+package selfsubjectrulesreview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	kutilerrors "k8s.io/apimachinery/pkg/util/errors"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	rbaclisters "k8s.io/client-go/listers/rbac/v1"
+	helpersrbacvalidation "k8s.io/component-helpers/auth/rbac/validation"
+	rbacregistryvalidation "k8s.io/kubernetes/pkg/registry/rbac/validation"
+
+	authorizationv1 "github.com/openshift/api/authorization/v1"
+	"github.com/openshift/apiserver-library-go/pkg/authorization/scope"
+	authorizationapi "github.com/openshift/openshift-apiserver/pkg/authorization/apis/authorization"
+)
+
+// DetailedRule is one effective rule at resourceNames granularity, together
+// with the roles and bindings it came from.
+type DetailedRule struct {
+	Verbs           []string `json:"verbs"`
+	APIGroups       []string `json:"apiGroups,omitempty"`
+	Resources       []string `json:"resources,omitempty"`
+	ResourceNames   []string `json:"resourceNames,omitempty"`
+	NonResourceURLs []string `json:"nonResourceURLs,omitempty"`
+	// Sources describes each role binding and role that contributed this
+	// rule, so UIs can explain where the access comes from.
+	Sources []string `json:"sources"`
+}
+
+// RuleDetails is the response body of the selfsubjectrulesreviews details
+// subresource.
+type RuleDetails struct {
+	// Namespace and User identify the evaluation performed.
+	Namespace string `json:"namespace"`
+	User      string `json:"user"`
+	// Rules is the list of effective rules with their provenance.
+	Rules []DetailedRule `json:"rules"`
+	// EvaluationError can appear in combination with Rules.  It means some
+	// error happened during evaluation that may have prevented additional
+	// rules from being populated.
+	EvaluationError string `json:"evaluationError,omitempty"`
+}
+
+// DetailsREST answers "why do I have this access": it resolves the calling
+// user's rules the same way a SelfSubjectRulesReview does, but keeps them at
+// resourceNames granularity and records which role and binding contributed
+// each rule instead of compacting that information away.  The review types
+// are stable API objects that cannot grow new fields, so the expanded answer
+// is served as a subresource with a plain JSON response.
+type DetailsREST struct {
+	ruleResolver      rbacregistryvalidation.AuthorizationRuleResolver
+	clusterRoleGetter rbaclisters.ClusterRoleLister
+}
+
+var _ rest.Connecter = &DetailsREST{}
+
+// NewDetailsREST returns the handler for the
+// selfsubjectrulesreviews/{name}/details subresource.  The name is a path
+// placeholder and is ignored; the evaluation always applies to the calling
+// user.
+func NewDetailsREST(ruleResolver rbacregistryvalidation.AuthorizationRuleResolver, clusterRoleGetter rbaclisters.ClusterRoleLister) *DetailsREST {
+	return &DetailsREST{ruleResolver: ruleResolver, clusterRoleGetter: clusterRoleGetter}
+}
+
+// New is only implemented to make DetailsREST implement RESTStorage
+func (r *DetailsREST) New() runtime.Object {
+	return &authorizationapi.SelfSubjectRulesReview{}
+}
+
+// ConnectMethods returns GET, the only supported method
+func (r *DetailsREST) ConnectMethods() []string {
+	return []string{"GET"}
+}
+
+// NewConnectOptions returns an empty options object
+func (r *DetailsREST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that resolves and returns the calling user's
+// effective rules with provenance.
+func (r *DetailsREST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	namespace, ok := apirequest.NamespaceFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewBadRequest("a namespace must be specified")
+	}
+	callingUser, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewBadRequest("user missing from context")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		result := RuleDetails{Namespace: namespace, User: callingUser.GetName()}
+
+		var errs []error
+		var rules []DetailedRule
+		index := map[string]int{}
+		r.ruleResolver.VisitRulesFor(callingUser, namespace, func(source fmt.Stringer, rule *rbacv1.PolicyRule, err error) bool {
+			if err != nil {
+				errs = append(errs, err)
+				return true
+			}
+			for _, broken := range breakdownRuleByResourceName(*rule) {
+				key := detailedRuleKey(broken)
+				if i, ok := index[key]; ok {
+					rules[i].Sources = appendUniqueSource(rules[i].Sources, source.String())
+					continue
+				}
+				detailed := detailedRuleFor(broken)
+				detailed.Sources = []string{source.String()}
+				index[key] = len(rules)
+				rules = append(rules, detailed)
+			}
+			return true
+		})
+
+		// a scoped token only has the subset of its user's rules the scopes
+		// allow; filter the detailed answer the same way the plain review does
+		if scopes := callingUser.GetExtra()[authorizationv1.ScopesKey]; len(scopes) > 0 {
+			scopeRules, err := scope.ScopesToRules(scopes, namespace, r.clusterRoleGetter)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			filtered := rules[:0]
+			for _, detailed := range rules {
+				if allowed, _ := helpersrbacvalidation.Covers(scopeRules, []rbacv1.PolicyRule{policyRuleFor(detailed)}); allowed {
+					filtered = append(filtered, detailed)
+				}
+			}
+			rules = filtered
+		}
+
+		result.Rules = rules
+		if len(errs) != 0 {
+			result.EvaluationError = kutilerrors.NewAggregate(errs).Error()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(&result); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+// breakdownRuleByResourceName splits a rule into entries that name at most
+// one resource and one resourceName each, so access granted to individual
+// objects stays visible instead of being merged into its neighbours.
+func breakdownRuleByResourceName(rule rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	var rules []rbacv1.PolicyRule
+	for _, broken := range helpersrbacvalidation.BreakdownRule(rule) {
+		if len(broken.ResourceNames) <= 1 {
+			rules = append(rules, broken)
+			continue
+		}
+		for _, name := range broken.ResourceNames {
+			named := broken
+			named.ResourceNames = []string{name}
+			rules = append(rules, named)
+		}
+	}
+	return rules
+}
+
+func detailedRuleFor(rule rbacv1.PolicyRule) DetailedRule {
+	return DetailedRule{
+		Verbs:           rule.Verbs,
+		APIGroups:       rule.APIGroups,
+		Resources:       rule.Resources,
+		ResourceNames:   rule.ResourceNames,
+		NonResourceURLs: rule.NonResourceURLs,
+	}
+}
+
+func policyRuleFor(detailed DetailedRule) rbacv1.PolicyRule {
+	return rbacv1.PolicyRule{
+		Verbs:           detailed.Verbs,
+		APIGroups:       detailed.APIGroups,
+		Resources:       detailed.Resources,
+		ResourceNames:   detailed.ResourceNames,
+		NonResourceURLs: detailed.NonResourceURLs,
+	}
+}
+
+func detailedRuleKey(rule rbacv1.PolicyRule) string {
+	return strings.Join([]string{
+		strings.Join(rule.Verbs, ","),
+		strings.Join(rule.APIGroups, ","),
+		strings.Join(rule.Resources, ","),
+		strings.Join(rule.ResourceNames, ","),
+		strings.Join(rule.NonResourceURLs, ","),
+	}, ";")
+}
+
+// appendUniqueSource records a contributing role or binding once.
+func appendUniqueSource(sources []string, source string) []string {
+	for _, existing := range sources {
+		if existing == source {
+			return sources
+		}
+	}
+	return append(sources, source)
+}
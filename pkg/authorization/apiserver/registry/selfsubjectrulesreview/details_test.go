@@ -0,0 +1,94 @@
+package selfsubjectrulesreview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+type fakeSource string
+
+func (s fakeSource) String() string { return string(s) }
+
+type fakeRuleResolver struct {
+	rulesBySource map[string][]rbacv1.PolicyRule
+}
+
+func (f *fakeRuleResolver) GetRoleReferenceRules(roleRef rbacv1.RoleRef, namespace string) ([]rbacv1.PolicyRule, error) {
+	return nil, nil
+}
+
+func (f *fakeRuleResolver) RulesFor(user user.Info, namespace string) ([]rbacv1.PolicyRule, error) {
+	var rules []rbacv1.PolicyRule
+	for _, sourceRules := range f.rulesBySource {
+		rules = append(rules, sourceRules...)
+	}
+	return rules, nil
+}
+
+func (f *fakeRuleResolver) VisitRulesFor(user user.Info, namespace string, visitor func(source fmt.Stringer, rule *rbacv1.PolicyRule, err error) bool) {
+	// iterate deterministically for the test
+	for _, source := range []string{"RoleBinding/edit", "RoleBinding/secret-reader"} {
+		for i := range f.rulesBySource[source] {
+			if !visitor(fakeSource(source), &f.rulesBySource[source][i], nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestDetailsREST(t *testing.T) {
+	resolver := &fakeRuleResolver{rulesBySource: map[string][]rbacv1.PolicyRule{
+		"RoleBinding/edit": {
+			{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"a", "b"}},
+		},
+		"RoleBinding/secret-reader": {
+			{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"a"}},
+		},
+	}}
+	storage := NewDetailsREST(resolver, nil)
+
+	ctx := apirequest.WithNamespace(context.Background(), "ns")
+	ctx = apirequest.WithUser(ctx, &user.DefaultInfo{Name: "bob"})
+	handler, err := storage.Connect(ctx, "current", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/details", nil))
+
+	result := RuleDetails{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("unable to decode response %q: %v", recorder.Body.String(), err)
+	}
+	if result.Namespace != "ns" || result.User != "bob" {
+		t.Errorf("unexpected evaluation identity: %#v", result)
+	}
+	if len(result.Rules) != 2 {
+		t.Fatalf("expected one rule per resourceName, got %#v", result.Rules)
+	}
+	// access to "a" is granted by both bindings, access to "b" by one
+	for _, rule := range result.Rules {
+		switch {
+		case reflect.DeepEqual(rule.ResourceNames, []string{"a"}):
+			if !reflect.DeepEqual(rule.Sources, []string{"RoleBinding/edit", "RoleBinding/secret-reader"}) {
+				t.Errorf("expected both sources for %q, got %v", "a", rule.Sources)
+			}
+		case reflect.DeepEqual(rule.ResourceNames, []string{"b"}):
+			if !reflect.DeepEqual(rule.Sources, []string{"RoleBinding/edit"}) {
+				t.Errorf("expected a single source for %q, got %v", "b", rule.Sources)
+			}
+		default:
+			t.Errorf("unexpected rule: %#v", rule)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Package subjectchecker implements the group-selector matching semantics of
+// RoleBindingRestriction objects.  The restrictusers admission that enforces
+// the restrictions runs in the kube-apiserver; the helpers live here, next to
+// the API they interpret, so admission and any other consumer agree on how
+// selectors over user.openshift.io Group labels are resolved.
+package subjectchecker
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+
+	userv1 "github.com/openshift/api/user/v1"
+	authorizationapi "github.com/openshift/openshift-apiserver/pkg/authorization/apis/authorization"
+)
+
+// GroupsMatchingSelectors returns the names of the groups whose labels match
+// any of the selectors.
+func GroupsMatchingSelectors(groups []userv1.Group, selectors []metav1.LabelSelector) (sets.String, error) {
+	matched := sets.NewString()
+	for i := range selectors {
+		selector, err := metav1.LabelSelectorAsSelector(&selectors[i])
+		if err != nil {
+			return nil, err
+		}
+		for j := range groups {
+			if selector.Matches(labels.Set(groups[j].Labels)) {
+				matched.Insert(groups[j].Name)
+			}
+		}
+	}
+	return matched, nil
+}
+
+// ExpandMembers returns every member name reachable from the named groups.
+// LDAP group sync expresses nesting by listing one group as a member of
+// another, so members whose name is itself a group name are followed;
+// membership cycles terminate.
+func ExpandMembers(groups []userv1.Group, roots sets.String) sets.String {
+	byName := make(map[string]*userv1.Group, len(groups))
+	for i := range groups {
+		byName[groups[i].Name] = &groups[i]
+	}
+
+	members := sets.NewString()
+	visited := sets.NewString()
+	queue := roots.List()
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if visited.Has(name) {
+			continue
+		}
+		visited.Insert(name)
+		group, ok := byName[name]
+		if !ok {
+			continue
+		}
+		for _, member := range group.Users {
+			members.Insert(member)
+			if _, isGroup := byName[member]; isGroup {
+				queue = append(queue, member)
+			}
+		}
+	}
+	return members
+}
+
+// SubjectMatchesGroupSelectors reports whether subjectName is a member,
+// directly or through nested groups, of any group matched by the selectors.
+func SubjectMatchesGroupSelectors(groups []userv1.Group, selectors []metav1.LabelSelector, subjectName string) (bool, error) {
+	if len(selectors) == 0 {
+		return false, nil
+	}
+	matched, err := GroupsMatchingSelectors(groups, selectors)
+	if err != nil {
+		return false, err
+	}
+	return ExpandMembers(groups, matched).Has(subjectName), nil
+}
+
+// UserMatchesGroupSelectors reports whether the named user matches the group
+// selectors of a user restriction.
+func UserMatchesGroupSelectors(restriction *authorizationapi.UserRestriction, username string, groups []userv1.Group) (bool, error) {
+	return SubjectMatchesGroupSelectors(groups, restriction.GroupSelectors, username)
+}
+
+// ServiceAccountMatchesGroupSelectors reports whether the service account
+// matches the group selectors of a service-account restriction.  Synced
+// groups carry service accounts under their usernames.
+func ServiceAccountMatchesGroupSelectors(restriction *authorizationapi.ServiceAccountRestriction, namespace, name string, groups []userv1.Group) (bool, error) {
+	return SubjectMatchesGroupSelectors(groups, restriction.GroupSelectors, serviceaccount.MakeUsername(namespace, name))
+}
@@ -0,0 +1,120 @@
+package subjectchecker
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	userv1 "github.com/openshift/api/user/v1"
+	authorizationapi "github.com/openshift/openshift-apiserver/pkg/authorization/apis/authorization"
+)
+
+func group(name string, labels map[string]string, members ...string) userv1.Group {
+	return userv1.Group{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Users:      userv1.OptionalNames(members),
+	}
+}
+
+var ldapSelector = metav1.LabelSelector{
+	MatchLabels: map[string]string{"openshift.io/ldap.host": "ldap.example.com"},
+}
+
+func TestGroupsMatchingSelectors(t *testing.T) {
+	groups := []userv1.Group{
+		group("developers", map[string]string{"openshift.io/ldap.host": "ldap.example.com"}),
+		group("admins", map[string]string{"openshift.io/ldap.host": "other.example.com"}),
+		group("local", nil),
+	}
+
+	matched, err := GroupsMatchingSelectors(groups, []metav1.LabelSelector{ldapSelector})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !matched.Equal(sets.NewString("developers")) {
+		t.Errorf("expected only the developers group to match, got %v", matched.List())
+	}
+
+	if _, err := GroupsMatchingSelectors(groups, []metav1.LabelSelector{
+		{MatchLabels: map[string]string{"bad label": "!"}},
+	}); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
+
+func TestExpandMembersFollowsNestedGroups(t *testing.T) {
+	groups := []userv1.Group{
+		group("all-engineering", nil, "frontend", "backend", "alice"),
+		group("frontend", nil, "bob"),
+		group("backend", nil, "carol", "all-engineering"), // membership cycle
+		group("unrelated", nil, "mallory"),
+	}
+
+	members := ExpandMembers(groups, sets.NewString("all-engineering"))
+	expected := sets.NewString("frontend", "backend", "alice", "bob", "carol", "all-engineering")
+	if !members.Equal(expected) {
+		t.Errorf("expected members %v, got %v", expected.List(), members.List())
+	}
+	if members.Has("mallory") {
+		t.Error("expected members of unrelated groups to be excluded")
+	}
+}
+
+func TestUserMatchesGroupSelectors(t *testing.T) {
+	groups := []userv1.Group{
+		group("developers", map[string]string{"openshift.io/ldap.host": "ldap.example.com"}, "team-a"),
+		group("team-a", nil, "alice"),
+	}
+	restriction := &authorizationapi.UserRestriction{
+		GroupSelectors: []metav1.LabelSelector{ldapSelector},
+	}
+
+	for username, expected := range map[string]bool{
+		"alice":  true,  // nested member of a selected group
+		"team-a": true,  // the nested group itself
+		"bob":    false, // not a member
+	} {
+		matches, err := UserMatchesGroupSelectors(restriction, username, groups)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matches != expected {
+			t.Errorf("user %s: expected match %t, got %t", username, expected, matches)
+		}
+	}
+
+	matches, err := UserMatchesGroupSelectors(&authorizationapi.UserRestriction{}, "alice", groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matches {
+		t.Error("expected a restriction without group selectors not to match")
+	}
+}
+
+func TestServiceAccountMatchesGroupSelectors(t *testing.T) {
+	groups := []userv1.Group{
+		group("ci", map[string]string{"openshift.io/ldap.host": "ldap.example.com"},
+			"system:serviceaccount:build:builder"),
+	}
+	restriction := &authorizationapi.ServiceAccountRestriction{
+		GroupSelectors: []metav1.LabelSelector{ldapSelector},
+	}
+
+	matches, err := ServiceAccountMatchesGroupSelectors(restriction, "build", "builder", groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !matches {
+		t.Error("expected the synced service account to match")
+	}
+
+	matches, err = ServiceAccountMatchesGroupSelectors(restriction, "build", "deployer", groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matches {
+		t.Error("expected an unsynced service account not to match")
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/openshift/library-go/pkg/build/naming"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+
+	buildv1 "github.com/openshift/api/build/v1"
 )
 
 func BuildFieldSelector(obj runtime.Object, fieldSet fields.Set) error {
@@ -14,7 +16,38 @@ func BuildFieldSelector(obj runtime.Object, fieldSet fields.Set) error {
 		return fmt.Errorf("%T not a Build", obj)
 	}
 	fieldSet["status"] = string(build.Status.Phase)
+	fieldSet["status.phase"] = string(build.Status.Phase)
 	fieldSet["podName"] = naming.GetPodName(build.Name, "build")
+	fieldSet["spec.strategy"] = strategyTypeName(build.Spec.Strategy)
+	fieldSet["buildConfig"] = configNameForBuild(build)
 
 	return nil
 }
+
+// strategyTypeName names the strategy the way build status and the CLI do.
+func strategyTypeName(strategy BuildStrategy) string {
+	switch {
+	case strategy.DockerStrategy != nil:
+		return "Docker"
+	case strategy.CustomStrategy != nil:
+		return "Custom"
+	case strategy.SourceStrategy != nil:
+		return "Source"
+	case strategy.JenkinsPipelineStrategy != nil:
+		return "JenkinsPipeline"
+	}
+	return ""
+}
+
+// configNameForBuild returns the name of the build config that produced the
+// build, preferring the annotation over the labels the way the build
+// controller does.
+func configNameForBuild(build *Build) string {
+	if name, ok := build.Annotations[buildv1.BuildConfigAnnotation]; ok {
+		return name
+	}
+	if name, ok := build.Labels[buildv1.BuildConfigLabel]; ok {
+		return name
+	}
+	return build.Labels[buildv1.BuildConfigLabelDeprecated]
+}
@@ -0,0 +1,53 @@
+package build
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+func TestBuildFieldSelector(t *testing.T) {
+	build := &Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "frontend-3",
+			Annotations: map[string]string{buildv1.BuildConfigAnnotation: "frontend"},
+			Labels:      map[string]string{buildv1.BuildConfigLabelDeprecated: "ignored"},
+		},
+		Spec: BuildSpec{
+			CommonSpec: CommonSpec{
+				Strategy: BuildStrategy{SourceStrategy: &SourceBuildStrategy{}},
+			},
+		},
+		Status: BuildStatus{Phase: BuildPhaseFailed},
+	}
+
+	fieldSet := fields.Set{}
+	if err := BuildFieldSelector(build, fieldSet); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"status":        "Failed",
+		"status.phase":  "Failed",
+		"spec.strategy": "Source",
+		"buildConfig":   "frontend",
+	}
+	for key, value := range expected {
+		if fieldSet[key] != value {
+			t.Errorf("expected %s=%q, got %q", key, value, fieldSet[key])
+		}
+	}
+
+	// without the annotation the labels identify the build config
+	delete(build.Annotations, buildv1.BuildConfigAnnotation)
+	fieldSet = fields.Set{}
+	if err := BuildFieldSelector(build, fieldSet); err != nil {
+		t.Fatal(err)
+	}
+	if fieldSet["buildConfig"] != "ignored" {
+		t.Errorf("expected the deprecated label to be used, got %q", fieldSet["buildConfig"])
+	}
+}
@@ -88,12 +88,21 @@ type CommonSpec struct {
 	// build; value must be positive integer.
 	CompletionDeadlineSeconds *int64
 
+	// TimeoutsPolicy optionally bounds individual phases of the build instead
+	// of only the whole run. Phases without a limit are bounded only by
+	// CompletionDeadlineSeconds.
+	TimeoutsPolicy *TimeoutsPolicy
+
 	// NodeSelector is a selector which must be true for the build pod to fit on a node
 	// If nil, it can be overridden by default build nodeselector values for the cluster.
 	// If set to an empty map or a map with any values, default build nodeselector values
 	// are ignored.
 	NodeSelector map[string]string
 
+	// Tolerations lets the build pod schedule onto nodes with matching taints,
+	// for example dedicated GPU or large-memory builders.
+	Tolerations []kapi.Toleration
+
 	// MountTrustedCA bind mounts the cluster's trusted certificate authorities, as defined in
 	// the cluster's proxy configuration, into the build. This lets processes within a build trust
 	// components signed by custom PKI certificate authorities, such as private artifact
@@ -105,6 +114,22 @@ type CommonSpec struct {
 	MountTrustedCA *bool
 }
 
+// TimeoutsPolicy holds per-phase timeouts for a build. Each value is a
+// duration in seconds counted from the start of the phase; all values must be
+// positive integers.
+type TimeoutsPolicy struct {
+	// CloneTimeoutSeconds bounds fetching the build source.
+	CloneTimeoutSeconds *int64
+
+	// BuildTimeoutSeconds bounds producing the output image, counted from the
+	// end of the source clone.
+	BuildTimeoutSeconds *int64
+
+	// PushTimeoutSeconds bounds pushing the output image to the registry,
+	// counted from the end of the image build.
+	PushTimeoutSeconds *int64
+}
+
 const (
 	BuildTriggerCauseManualMsg    = "Manually triggered"
 	BuildTriggerCauseConfigMsg    = "Build configuration change"
@@ -892,40 +917,40 @@ type JenkinsPipelineBuildStrategy struct {
 //
 // 1. Shell script:
 //
-// 	BuildPostCommitSpec{
-// 		Script: "rake test --verbose",
-// 	}
+//	BuildPostCommitSpec{
+//		Script: "rake test --verbose",
+//	}
 //
 // The above is a convenient form which is equivalent to:
 //
-// 	BuildPostCommitSpec{
-// 		Command: []string{"/bin/sh", "-ic"},
-// 		Args: []string{"rake test --verbose"},
-// 	}
+//	BuildPostCommitSpec{
+//		Command: []string{"/bin/sh", "-ic"},
+//		Args: []string{"rake test --verbose"},
+//	}
 //
 // 2. Command as the image entrypoint:
 //
-// 	BuildPostCommitSpec{
-// 		Command: []string{"rake", "test", "--verbose"},
-// 	}
+//	BuildPostCommitSpec{
+//		Command: []string{"rake", "test", "--verbose"},
+//	}
 //
 // Command overrides the image entrypoint in the exec form, as documented in
 // Docker: https://docs.docker.com/engine/reference/builder/#entrypoint.
 //
 // 3. Pass arguments to the default entrypoint:
 //
-// 	BuildPostCommitSpec{
-// 		Args: []string{"rake", "test", "--verbose"},
-// 	}
+//	BuildPostCommitSpec{
+//		Args: []string{"rake", "test", "--verbose"},
+//	}
 //
 // This form is only useful if the image entrypoint can handle arguments.
 //
 // 4. Shell script with arguments:
 //
-// 	BuildPostCommitSpec{
-// 		Script: "rake test $1",
-// 		Args: []string{"--verbose"},
-// 	}
+//	BuildPostCommitSpec{
+//		Script: "rake test $1",
+//		Args: []string{"--verbose"},
+//	}
 //
 // This form is useful if you need to pass arguments that would otherwise be
 // hard to quote properly in the shell script. In the script, $0 will be
@@ -933,10 +958,10 @@ type JenkinsPipelineBuildStrategy struct {
 //
 // 5. Command with arguments:
 //
-// 	BuildPostCommitSpec{
-// 		Command: []string{"rake", "test"},
-// 		Args: []string{"--verbose"},
-// 	}
+//	BuildPostCommitSpec{
+//		Command: []string{"rake", "test"},
+//		Args: []string{"--verbose"},
+//	}
 //
 // This form is equivalent to appending the arguments to the Command slice.
 //
@@ -983,8 +1008,38 @@ type BuildOutput struct {
 	// ImageLabels define a list of labels that are applied to the resulting image. If there
 	// are multiple labels with the same name then the last one in the list is used.
 	ImageLabels []ImageLabel
+
+	// Artifacts declares supplementary OCI artifacts, such as an SBOM or
+	// provenance attestation, that the builder pushes to the output
+	// repository alongside the image.
+	Artifacts []BuildOutputArtifact
+}
+
+// BuildOutputArtifact declares one supplementary OCI artifact to push to the
+// output repository.
+type BuildOutputArtifact struct {
+	// Type identifies the kind of artifact, e.g. "sbom" or "provenance".
+	Type string
+
+	// MediaType is the OCI media type the artifact content is pushed with.
+	MediaType string
+
+	// PushSecret overrides the output push secret for this artifact. When
+	// unset, the output's push secret is used.
+	PushSecret *kapi.LocalObjectReference
 }
 
+// well known build output artifact types
+const (
+	// BuildOutputArtifactSBOM is a software bill of materials for the built
+	// image.
+	BuildOutputArtifactSBOM = "sbom"
+
+	// BuildOutputArtifactProvenance is a provenance attestation describing how
+	// the image was built.
+	BuildOutputArtifactProvenance = "provenance"
+)
+
 // ImageLabel represents a label applied to the resulting image.
 type ImageLabel struct {
 	// Name defines the name of the label. It must have non-zero length.
@@ -1085,6 +1140,13 @@ type WebHookTrigger struct {
 	// The secret being referenced must contain a key named "WebHookSecretKey", the value
 	// of which will be checked against the value supplied in the webhook invocation.
 	SecretReference *SecretLocalReference
+
+	// AllowedRefs restricts which pushed refs may instantiate a build through
+	// this webhook. Each entry is a branch name or a glob pattern (for example
+	// "release-*") matched against the pushed ref with the refs/heads/ prefix
+	// stripped. When empty, the event ref is compared against the build
+	// configuration's spec.source.git.ref as before.
+	AllowedRefs []string
 }
 
 // ImageChangeTrigger allows builds to be triggered when an ImageStream changes
@@ -1154,7 +1216,7 @@ type BuildTriggerPolicy struct {
 // BuildTriggerType refers to a specific BuildTriggerPolicy implementation.
 type BuildTriggerType string
 
-//NOTE: Adding a new trigger type requires adding the type to KnownTriggerTypes
+// NOTE: Adding a new trigger type requires adding the type to KnownTriggerTypes
 var KnownTriggerTypes = sets.NewString(
 	string(GitHubWebHookBuildTriggerType),
 	string(GenericWebHookBuildTriggerType),
@@ -1306,6 +1368,16 @@ type BuildRequest struct {
 
 	// SourceStrategyOptions contains additional source-strategy specific options for the build
 	SourceStrategyOptions *SourceStrategyOptions
+
+	// NodeSelector contains per-run node selector entries merged over the build
+	// config's node selector. Each key must be allowed by the build config's
+	// allowed node selector overrides.
+	NodeSelector map[string]string
+
+	// Tolerations contains per-run tolerations merged into the generated build.
+	// Each toleration key must be allowed by the build config's allowed
+	// toleration overrides.
+	Tolerations []kapi.Toleration
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -1424,6 +1496,9 @@ const (
 
 	// BuildVolumeSourceTypeConfigmap is the ConfigMap build source volume type
 	BuildVolumeSourceTypeConfigMap BuildVolumeSourceType = "ConfigMap"
+
+	// BuildVolumeSourceTypeProjected is the Projected build source volume type
+	BuildVolumeSourceTypeProjected BuildVolumeSourceType = "Projected"
 )
 
 // BuildVolumeSource represents the source of a volume to mount
@@ -1439,6 +1514,13 @@ type BuildVolumeSource struct {
 	// configMap represents a ConfigMap that should populate this volume
 	// +optional
 	ConfigMap *kapi.ConfigMapVolumeSource
+
+	// projected represents a projected volume that combines secrets,
+	// config maps and service account tokens in one directory, letting a
+	// build mount a short-lived token next to its other inputs.  The field
+	// has no counterpart in the v1 API yet and is dropped on conversion.
+	// +optional
+	Projected *kapi.ProjectedVolumeSource
 }
 
 // BuildVolumeMount describes the mounting of a Volume within buildah's runtime environment.
@@ -1,11 +1,16 @@
 package v1
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/url"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	apiscorev1 "k8s.io/kubernetes/pkg/apis/core/v1"
 
 	v1 "github.com/openshift/api/build/v1"
 	"github.com/openshift/library-go/pkg/image/imageutil"
@@ -13,10 +18,565 @@ import (
 	buildinternalhelpers "github.com/openshift/openshift-apiserver/pkg/build/apis/build/internal_helpers"
 )
 
+// TimeoutsPolicyAnnotation carries spec.timeoutsPolicy across the v1 API,
+// which has no field for it, as a JSON object. The annotation is translated
+// to and from the internal field during conversion and is not stored on
+// internal objects.
+const TimeoutsPolicyAnnotation = "build.openshift.io/timeouts-policy"
+
+// TimeoutsPolicy is the serialized form of the internal timeouts policy.
+type TimeoutsPolicy struct {
+	CloneTimeoutSeconds *int64 `json:"cloneTimeoutSeconds,omitempty"`
+	BuildTimeoutSeconds *int64 `json:"buildTimeoutSeconds,omitempty"`
+	PushTimeoutSeconds  *int64 `json:"pushTimeoutSeconds,omitempty"`
+}
+
+func decodeTimeoutsPolicy(meta *metav1.ObjectMeta, spec *newer.CommonSpec) {
+	value, ok := meta.Annotations[TimeoutsPolicyAnnotation]
+	if !ok {
+		return
+	}
+	decoded := &TimeoutsPolicy{}
+	if err := json.Unmarshal([]byte(value), decoded); err != nil {
+		// a stored object may carry a malformed value that predates any
+		// validation; failing conversion would make it unreadable, so the
+		// value is left in place for validation to reject on writes
+		return
+	}
+	spec.TimeoutsPolicy = &newer.TimeoutsPolicy{
+		CloneTimeoutSeconds: decoded.CloneTimeoutSeconds,
+		BuildTimeoutSeconds: decoded.BuildTimeoutSeconds,
+		PushTimeoutSeconds:  decoded.PushTimeoutSeconds,
+	}
+	// the annotation only exists in the versioned form; the object meta is
+	// shared with the input, so replace the map instead of mutating it
+	annotations := make(map[string]string, len(meta.Annotations)-1)
+	for k, v := range meta.Annotations {
+		if k != TimeoutsPolicyAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	meta.Annotations = annotations
+}
+
+func encodeTimeoutsPolicy(meta *metav1.ObjectMeta, policy *newer.TimeoutsPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	data, err := json.Marshal(&TimeoutsPolicy{
+		CloneTimeoutSeconds: policy.CloneTimeoutSeconds,
+		BuildTimeoutSeconds: policy.BuildTimeoutSeconds,
+		PushTimeoutSeconds:  policy.PushTimeoutSeconds,
+	})
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(meta.Annotations)+1)
+	for k, v := range meta.Annotations {
+		annotations[k] = v
+	}
+	annotations[TimeoutsPolicyAnnotation] = string(data)
+	meta.Annotations = annotations
+	return nil
+}
+
+// OutputArtifactsAnnotation carries spec.output.artifacts across the v1 API,
+// which has no field for it, as a JSON list. The annotation is translated to
+// and from the internal field during conversion and is not stored on internal
+// objects.
+const OutputArtifactsAnnotation = "build.openshift.io/output-artifacts"
+
+// OutputArtifact is the serialized form of one internal BuildOutputArtifact.
+type OutputArtifact struct {
+	Type       string                       `json:"type"`
+	MediaType  string                       `json:"mediaType,omitempty"`
+	PushSecret *corev1.LocalObjectReference `json:"pushSecret,omitempty"`
+}
+
+func decodeOutputArtifacts(meta *metav1.ObjectMeta, spec *newer.CommonSpec) {
+	value, ok := meta.Annotations[OutputArtifactsAnnotation]
+	if !ok {
+		return
+	}
+	var decoded []OutputArtifact
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		// a stored object may carry a malformed value that predates any
+		// validation; failing conversion would make it unreadable, so the
+		// value is left in place for validation to reject on writes
+		return
+	}
+	spec.Output.Artifacts = make([]newer.BuildOutputArtifact, len(decoded))
+	for i := range decoded {
+		spec.Output.Artifacts[i] = newer.BuildOutputArtifact{
+			Type:      decoded[i].Type,
+			MediaType: decoded[i].MediaType,
+		}
+		if decoded[i].PushSecret != nil {
+			spec.Output.Artifacts[i].PushSecret = &core.LocalObjectReference{Name: decoded[i].PushSecret.Name}
+		}
+	}
+	// the annotation only exists in the versioned form; the object meta is
+	// shared with the input, so replace the map instead of mutating it
+	annotations := make(map[string]string, len(meta.Annotations)-1)
+	for k, v := range meta.Annotations {
+		if k != OutputArtifactsAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	meta.Annotations = annotations
+}
+
+func encodeOutputArtifacts(meta *metav1.ObjectMeta, artifacts []newer.BuildOutputArtifact) error {
+	if len(artifacts) == 0 {
+		return nil
+	}
+	encoded := make([]OutputArtifact, len(artifacts))
+	for i := range artifacts {
+		encoded[i] = OutputArtifact{
+			Type:      artifacts[i].Type,
+			MediaType: artifacts[i].MediaType,
+		}
+		if artifacts[i].PushSecret != nil {
+			encoded[i].PushSecret = &corev1.LocalObjectReference{Name: artifacts[i].PushSecret.Name}
+		}
+	}
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(meta.Annotations)+1)
+	for k, v := range meta.Annotations {
+		annotations[k] = v
+	}
+	annotations[OutputArtifactsAnnotation] = string(data)
+	meta.Annotations = annotations
+	return nil
+}
+
+// OutputArtifactsFromAnnotation returns the output artifacts recorded on the
+// versioned object, or nil if it carries none. It is used by the build
+// generator, which works on versioned objects.
+func OutputArtifactsFromAnnotation(meta *metav1.ObjectMeta) ([]OutputArtifact, error) {
+	value, ok := meta.Annotations[OutputArtifactsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var decoded []OutputArtifact
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", OutputArtifactsAnnotation, err)
+	}
+	return decoded, nil
+}
+
+// SetOutputArtifactsAnnotation records the output artifacts on the versioned
+// object, replacing any recorded value.
+func SetOutputArtifactsAnnotation(meta *metav1.ObjectMeta, artifacts []OutputArtifact) error {
+	data, err := json.Marshal(artifacts)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(meta.Annotations)+1)
+	for k, v := range meta.Annotations {
+		annotations[k] = v
+	}
+	annotations[OutputArtifactsAnnotation] = string(data)
+	meta.Annotations = annotations
+	return nil
+}
+
+// TolerationsAnnotation carries spec.tolerations across the v1 API, which has
+// no field for it, as a JSON list of tolerations. The annotation is translated
+// to and from the internal field during conversion and is not stored on
+// internal objects.
+const TolerationsAnnotation = "build.openshift.io/tolerations"
+
+func decodeTolerations(meta *metav1.ObjectMeta, spec *newer.CommonSpec) error {
+	value, ok := meta.Annotations[TolerationsAnnotation]
+	if !ok {
+		return nil
+	}
+	var decoded []corev1.Toleration
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		// a stored object may carry a malformed value that predates any
+		// validation; failing conversion would make it unreadable, so the
+		// value is left in place for validation to reject on writes
+		return nil
+	}
+	spec.Tolerations = make([]core.Toleration, len(decoded))
+	for i := range decoded {
+		if err := apiscorev1.Convert_v1_Toleration_To_core_Toleration(&decoded[i], &spec.Tolerations[i], nil); err != nil {
+			return err
+		}
+	}
+	// the annotation only exists in the versioned form; the object meta is
+	// shared with the input, so replace the map instead of mutating it
+	annotations := make(map[string]string, len(meta.Annotations)-1)
+	for k, v := range meta.Annotations {
+		if k != TolerationsAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	meta.Annotations = annotations
+	return nil
+}
+
+func encodeTolerations(meta *metav1.ObjectMeta, tolerations []core.Toleration) error {
+	if len(tolerations) == 0 {
+		return nil
+	}
+	encoded := make([]corev1.Toleration, len(tolerations))
+	for i := range tolerations {
+		if err := apiscorev1.Convert_core_Toleration_To_v1_Toleration(&tolerations[i], &encoded[i], nil); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(meta.Annotations)+1)
+	for k, v := range meta.Annotations {
+		annotations[k] = v
+	}
+	annotations[TolerationsAnnotation] = string(data)
+	meta.Annotations = annotations
+	return nil
+}
+
+// AppendTolerations merges tolerations into the TolerationsAnnotation of the
+// given object, replacing recorded entries with the same key. It is used by
+// the build generator, which works on versioned objects, to apply per-run
+// toleration overrides to a generated build.
+func AppendTolerations(meta *metav1.ObjectMeta, tolerations []corev1.Toleration) error {
+	var merged []corev1.Toleration
+	if value, ok := meta.Annotations[TolerationsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), &merged); err != nil {
+			return fmt.Errorf("invalid %s annotation: %v", TolerationsAnnotation, err)
+		}
+	}
+	for _, toleration := range tolerations {
+		replaced := false
+		for i := range merged {
+			if merged[i].Key == toleration.Key {
+				merged[i] = toleration
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, toleration)
+		}
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(meta.Annotations)+1)
+	for k, v := range meta.Annotations {
+		annotations[k] = v
+	}
+	annotations[TolerationsAnnotation] = string(data)
+	meta.Annotations = annotations
+	return nil
+}
+
+// PlacementOverridesAnnotation carries a BuildRequest's nodeSelector and
+// tolerations across the v1 API, which has no fields for them, as a JSON
+// object. The annotation is translated to and from the internal fields during
+// conversion and is not stored on internal objects.
+const PlacementOverridesAnnotation = "build.openshift.io/placement-overrides"
+
+// PlacementOverrides is the serialized form of the per-run placement override
+// fields of the internal BuildRequest.
+type PlacementOverrides struct {
+	NodeSelector map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations  []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// PlacementOverridesFromRequest returns the placement overrides recorded on
+// the versioned build request, or nil if the request carries none.
+func PlacementOverridesFromRequest(request *v1.BuildRequest) (*PlacementOverrides, error) {
+	value, ok := request.Annotations[PlacementOverridesAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	decoded := &PlacementOverrides{}
+	if err := json.Unmarshal([]byte(value), decoded); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", PlacementOverridesAnnotation, err)
+	}
+	return decoded, nil
+}
+
+func decodePlacementOverrides(out *newer.BuildRequest) error {
+	value, ok := out.Annotations[PlacementOverridesAnnotation]
+	if !ok {
+		return nil
+	}
+	decoded := &PlacementOverrides{}
+	if err := json.Unmarshal([]byte(value), decoded); err != nil {
+		return fmt.Errorf("invalid %s annotation: %v", PlacementOverridesAnnotation, err)
+	}
+	out.NodeSelector = decoded.NodeSelector
+	if len(decoded.Tolerations) > 0 {
+		out.Tolerations = make([]core.Toleration, len(decoded.Tolerations))
+		for i := range decoded.Tolerations {
+			if err := apiscorev1.Convert_v1_Toleration_To_core_Toleration(&decoded.Tolerations[i], &out.Tolerations[i], nil); err != nil {
+				return err
+			}
+		}
+	}
+	annotations := make(map[string]string, len(out.Annotations)-1)
+	for k, v := range out.Annotations {
+		if k != PlacementOverridesAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	out.Annotations = annotations
+	return nil
+}
+
+func encodePlacementOverrides(in *newer.BuildRequest, out *v1.BuildRequest) error {
+	if len(in.NodeSelector) == 0 && len(in.Tolerations) == 0 {
+		return nil
+	}
+	overrides := &PlacementOverrides{NodeSelector: in.NodeSelector}
+	if len(in.Tolerations) > 0 {
+		overrides.Tolerations = make([]corev1.Toleration, len(in.Tolerations))
+		for i := range in.Tolerations {
+			if err := apiscorev1.Convert_core_Toleration_To_v1_Toleration(&in.Tolerations[i], &overrides.Tolerations[i], nil); err != nil {
+				return err
+			}
+		}
+	}
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(out.Annotations)+1)
+	for k, v := range out.Annotations {
+		annotations[k] = v
+	}
+	annotations[PlacementOverridesAnnotation] = string(data)
+	out.Annotations = annotations
+	return nil
+}
+
+func Convert_v1_BuildRequest_To_build_BuildRequest(in *v1.BuildRequest, out *newer.BuildRequest, s conversion.Scope) error {
+	if err := autoConvert_v1_BuildRequest_To_build_BuildRequest(in, out, s); err != nil {
+		return err
+	}
+	return decodePlacementOverrides(out)
+}
+
+func Convert_build_BuildRequest_To_v1_BuildRequest(in *newer.BuildRequest, out *v1.BuildRequest, s conversion.Scope) error {
+	if err := autoConvert_build_BuildRequest_To_v1_BuildRequest(in, out, s); err != nil {
+		return err
+	}
+	return encodePlacementOverrides(in, out)
+}
+
+// WebHookAllowedRefsAnnotation carries the allowedRefs of webhook triggers
+// across the v1 API, whose WebHookTrigger type has no field for them, as a
+// JSON list of per-trigger entries. The annotation is translated to and from
+// the internal fields during conversion and is not stored on internal
+// objects.
+const WebHookAllowedRefsAnnotation = "build.openshift.io/webhook-allowed-refs"
+
+// webHookAllowedRefsEntry records the allowedRefs of one webhook of the
+// trigger policy at the given index of spec.triggers. Hook names the webhook
+// field of the policy: github, generic, gitlab or bitbucket.
+type webHookAllowedRefsEntry struct {
+	Trigger     int      `json:"trigger"`
+	Hook        string   `json:"hook"`
+	AllowedRefs []string `json:"allowedRefs"`
+}
+
+// webHookHookNames lists the webhook fields of a trigger policy in their
+// declaration order.
+var webHookHookNames = []string{"github", "generic", "gitlab", "bitbucket"}
+
+// webHookOfPolicy returns the named webhook trigger of the policy, or nil.
+func webHookOfPolicy(policy *newer.BuildTriggerPolicy, hook string) *newer.WebHookTrigger {
+	switch hook {
+	case "github":
+		return policy.GitHubWebHook
+	case "generic":
+		return policy.GenericWebHook
+	case "gitlab":
+		return policy.GitLabWebHook
+	case "bitbucket":
+		return policy.BitbucketWebHook
+	}
+	return nil
+}
+
+func decodeWebHookAllowedRefs(meta *metav1.ObjectMeta, spec *newer.BuildConfigSpec) error {
+	value, ok := meta.Annotations[WebHookAllowedRefsAnnotation]
+	if !ok {
+		return nil
+	}
+	var decoded []webHookAllowedRefsEntry
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return fmt.Errorf("invalid %s annotation: %v", WebHookAllowedRefsAnnotation, err)
+	}
+	for _, entry := range decoded {
+		if entry.Trigger < 0 || entry.Trigger >= len(spec.Triggers) {
+			return fmt.Errorf("invalid %s annotation: trigger index %d out of range", WebHookAllowedRefsAnnotation, entry.Trigger)
+		}
+		trigger := webHookOfPolicy(&spec.Triggers[entry.Trigger], entry.Hook)
+		if trigger == nil {
+			return fmt.Errorf("invalid %s annotation: trigger %d has no %q webhook", WebHookAllowedRefsAnnotation, entry.Trigger, entry.Hook)
+		}
+		trigger.AllowedRefs = entry.AllowedRefs
+	}
+	annotations := make(map[string]string, len(meta.Annotations)-1)
+	for k, v := range meta.Annotations {
+		if k != WebHookAllowedRefsAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	meta.Annotations = annotations
+	return nil
+}
+
+func encodeWebHookAllowedRefs(meta *metav1.ObjectMeta, spec *newer.BuildConfigSpec) error {
+	var entries []webHookAllowedRefsEntry
+	for i := range spec.Triggers {
+		for _, hook := range webHookHookNames {
+			trigger := webHookOfPolicy(&spec.Triggers[i], hook)
+			if trigger == nil || len(trigger.AllowedRefs) == 0 {
+				continue
+			}
+			entries = append(entries, webHookAllowedRefsEntry{Trigger: i, Hook: hook, AllowedRefs: trigger.AllowedRefs})
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(meta.Annotations)+1)
+	for k, v := range meta.Annotations {
+		annotations[k] = v
+	}
+	annotations[WebHookAllowedRefsAnnotation] = string(data)
+	meta.Annotations = annotations
+	return nil
+}
+
+// webHookOfVersionedPolicy returns the named webhook trigger of the versioned
+// policy, or nil.
+func webHookOfVersionedPolicy(policy *v1.BuildTriggerPolicy, hook string) *v1.WebHookTrigger {
+	switch hook {
+	case "github":
+		return policy.GitHubWebHook
+	case "generic":
+		return policy.GenericWebHook
+	case "gitlab":
+		return policy.GitLabWebHook
+	case "bitbucket":
+		return policy.BitbucketWebHook
+	}
+	return nil
+}
+
+// WebHookAllowedRefsForTrigger returns the allowedRefs recorded on the
+// versioned build configuration for the given webhook trigger, if any. The
+// trigger must be one of the triggers of the build configuration.
+func WebHookAllowedRefsForTrigger(buildConfig *v1.BuildConfig, trigger *v1.WebHookTrigger) []string {
+	value, ok := buildConfig.Annotations[WebHookAllowedRefsAnnotation]
+	if !ok {
+		return nil
+	}
+	var decoded []webHookAllowedRefsEntry
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return nil
+	}
+	for _, entry := range decoded {
+		if entry.Trigger < 0 || entry.Trigger >= len(buildConfig.Spec.Triggers) {
+			continue
+		}
+		if webHookOfVersionedPolicy(&buildConfig.Spec.Triggers[entry.Trigger], entry.Hook) == trigger {
+			return entry.AllowedRefs
+		}
+	}
+	return nil
+}
+
+// Convert_build_WebHookTrigger_To_v1_WebHookTrigger is a manual conversion
+// because the v1 type has no allowedRefs field; the values are carried by the
+// WebHookAllowedRefsAnnotation written during BuildConfig conversion.
+func Convert_build_WebHookTrigger_To_v1_WebHookTrigger(in *newer.WebHookTrigger, out *v1.WebHookTrigger, s conversion.Scope) error {
+	return autoConvert_build_WebHookTrigger_To_v1_WebHookTrigger(in, out, s)
+}
+
+func Convert_v1_Build_To_build_Build(in *v1.Build, out *newer.Build, s conversion.Scope) error {
+	if err := autoConvert_v1_Build_To_build_Build(in, out, s); err != nil {
+		return err
+	}
+	decodeTimeoutsPolicy(&out.ObjectMeta, &out.Spec.CommonSpec)
+	decodeOutputArtifacts(&out.ObjectMeta, &out.Spec.CommonSpec)
+	return decodeTolerations(&out.ObjectMeta, &out.Spec.CommonSpec)
+}
+
+func Convert_build_Build_To_v1_Build(in *newer.Build, out *v1.Build, s conversion.Scope) error {
+	if err := autoConvert_build_Build_To_v1_Build(in, out, s); err != nil {
+		return err
+	}
+	if err := encodeTimeoutsPolicy(&out.ObjectMeta, in.Spec.TimeoutsPolicy); err != nil {
+		return err
+	}
+	if err := encodeOutputArtifacts(&out.ObjectMeta, in.Spec.Output.Artifacts); err != nil {
+		return err
+	}
+	return encodeTolerations(&out.ObjectMeta, in.Spec.Tolerations)
+}
+
+func Convert_build_BuildConfig_To_v1_BuildConfig(in *newer.BuildConfig, out *v1.BuildConfig, s conversion.Scope) error {
+	if err := autoConvert_build_BuildConfig_To_v1_BuildConfig(in, out, s); err != nil {
+		return err
+	}
+	if err := encodeWebHookAllowedRefs(&out.ObjectMeta, &in.Spec); err != nil {
+		return err
+	}
+	if err := encodeTimeoutsPolicy(&out.ObjectMeta, in.Spec.TimeoutsPolicy); err != nil {
+		return err
+	}
+	if err := encodeOutputArtifacts(&out.ObjectMeta, in.Spec.Output.Artifacts); err != nil {
+		return err
+	}
+	return encodeTolerations(&out.ObjectMeta, in.Spec.Tolerations)
+}
+
 func Convert_v1_BuildConfig_To_build_BuildConfig(in *v1.BuildConfig, out *newer.BuildConfig, s conversion.Scope) error {
 	if err := autoConvert_v1_BuildConfig_To_build_BuildConfig(in, out, s); err != nil {
 		return err
 	}
+	decodeTimeoutsPolicy(&out.ObjectMeta, &out.Spec.CommonSpec)
+	decodeOutputArtifacts(&out.ObjectMeta, &out.Spec.CommonSpec)
+	if err := decodeTolerations(&out.ObjectMeta, &out.Spec.CommonSpec); err != nil {
+		return err
+	}
+	if err := decodeWebHookAllowedRefs(&out.ObjectMeta, &out.Spec); err != nil {
+		return err
+	}
 
 	newTriggers := []newer.BuildTriggerPolicy{}
 	// Strip off any default imagechange triggers where the buildconfig's
@@ -271,7 +831,10 @@ func AddFieldSelectorKeyConversions(scheme *runtime.Scheme) error {
 func buildFieldSelectorKeyConversionFunc(label, value string) (internalLabel, internalValue string, err error) {
 	switch label {
 	case "status",
-		"podName":
+		"status.phase",
+		"podName",
+		"spec.strategy",
+		"buildConfig":
 		return label, value, nil
 	default:
 		return runtime.DefaultMetaV1FieldSelectorConversion(label, value)
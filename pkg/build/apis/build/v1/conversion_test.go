@@ -32,7 +32,7 @@ func TestFieldSelectorConversions(t *testing.T) {
 		SchemeBuilder: []func(*runtime.Scheme) error{Install},
 		Kind:          v1.GroupVersion.WithKind("Build"),
 		// Ensure previously supported labels have conversions. DO NOT REMOVE THINGS FROM THIS LIST
-		AllowedExternalFieldKeys: []string{"status", "podName"},
+		AllowedExternalFieldKeys: []string{"status", "status.phase", "podName", "spec.strategy", "buildConfig"},
 		FieldKeyEvaluatorFn:      internal.BuildFieldSelector,
 	}.Check(t)
 
@@ -433,3 +433,274 @@ func TestBuildLogOptionsOptions(t *testing.T) {
 		}
 	}
 }
+
+func TestWebHookAllowedRefsConversion(t *testing.T) {
+	internalConfig := &internal.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config-1"},
+		Spec: internal.BuildConfigSpec{
+			Triggers: []internal.BuildTriggerPolicy{
+				{
+					Type: internal.GitHubWebHookBuildTriggerType,
+					GitHubWebHook: &internal.WebHookTrigger{
+						Secret:      "secret101",
+						AllowedRefs: []string{"main", "release-*"},
+					},
+				},
+				{
+					Type: internal.GenericWebHookBuildTriggerType,
+					GenericWebHook: &internal.WebHookTrigger{
+						Secret: "secret102",
+					},
+				},
+			},
+			RunPolicy: internal.BuildRunPolicySerial,
+		},
+	}
+
+	versionedObj, err := scheme.ConvertToVersion(internalConfig, v1.GroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versioned := versionedObj.(*v1.BuildConfig)
+	if expected := `[{"trigger":0,"hook":"github","allowedRefs":["main","release-*"]}]`; versioned.Annotations[WebHookAllowedRefsAnnotation] != expected {
+		t.Fatalf("expected annotation %s, got %q", expected, versioned.Annotations[WebHookAllowedRefsAnnotation])
+	}
+
+	roundTrippedObj, err := scheme.ConvertToVersion(versioned, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := roundTrippedObj.(*internal.BuildConfig)
+	if !apiequality.Semantic.DeepEqual(internalConfig.Spec.Triggers, roundTripped.Spec.Triggers) {
+		t.Errorf("triggers did not round trip: %s", diff.ObjectReflectDiff(internalConfig.Spec.Triggers, roundTripped.Spec.Triggers))
+	}
+	if _, ok := roundTripped.Annotations[WebHookAllowedRefsAnnotation]; ok {
+		t.Errorf("expected the annotation to be dropped from the internal object, got %v", roundTripped.Annotations)
+	}
+}
+
+func TestTimeoutsPolicyConversion(t *testing.T) {
+	sixty := int64(60)
+	internalBuild := &internal.Build{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "build-1"},
+		Spec: internal.BuildSpec{
+			CommonSpec: internal.CommonSpec{
+				TimeoutsPolicy: &internal.TimeoutsPolicy{
+					CloneTimeoutSeconds: &sixty,
+				},
+			},
+		},
+	}
+
+	versionedObj, err := scheme.ConvertToVersion(internalBuild, v1.GroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versioned := versionedObj.(*v1.Build)
+	if expected := `{"cloneTimeoutSeconds":60}`; versioned.Annotations[TimeoutsPolicyAnnotation] != expected {
+		t.Fatalf("expected annotation %s, got %q", expected, versioned.Annotations[TimeoutsPolicyAnnotation])
+	}
+
+	roundTrippedObj, err := scheme.ConvertToVersion(versioned, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := roundTrippedObj.(*internal.Build)
+	if !apiequality.Semantic.DeepEqual(internalBuild.Spec.TimeoutsPolicy, roundTripped.Spec.TimeoutsPolicy) {
+		t.Errorf("timeouts policy did not round trip: %s", diff.ObjectReflectDiff(internalBuild.Spec.TimeoutsPolicy, roundTripped.Spec.TimeoutsPolicy))
+	}
+	if _, ok := roundTripped.Annotations[TimeoutsPolicyAnnotation]; ok {
+		t.Errorf("expected the annotation to be dropped from the internal object, got %v", roundTripped.Annotations)
+	}
+
+	// the annotation alone is decoded for clients that set it directly
+	fromAnnotation := &v1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "config-1",
+			Annotations: map[string]string{TimeoutsPolicyAnnotation: `{"pushTimeoutSeconds":300}`},
+		},
+	}
+	internalObj, err := scheme.ConvertToVersion(fromAnnotation, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	internalConfig := internalObj.(*internal.BuildConfig)
+	if internalConfig.Spec.TimeoutsPolicy == nil || internalConfig.Spec.TimeoutsPolicy.PushTimeoutSeconds == nil || *internalConfig.Spec.TimeoutsPolicy.PushTimeoutSeconds != 300 {
+		t.Errorf("expected a push timeout of 300 seconds, got %#v", internalConfig.Spec.TimeoutsPolicy)
+	}
+
+	// a malformed annotation on a stored object is tolerated so the object stays readable
+	invalid := &v1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "build-2",
+			Annotations: map[string]string{TimeoutsPolicyAnnotation: "not json"},
+		},
+	}
+	toleratedObj, err := scheme.ConvertToVersion(invalid, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("expected a malformed annotation to be tolerated, got %v", err)
+	}
+	tolerated := toleratedObj.(*internal.Build)
+	if tolerated.Spec.TimeoutsPolicy != nil {
+		t.Errorf("expected no timeouts policy from a malformed annotation, got %#v", tolerated.Spec.TimeoutsPolicy)
+	}
+	if tolerated.Annotations[TimeoutsPolicyAnnotation] != "not json" {
+		t.Errorf("expected the malformed annotation to be left in place, got %v", tolerated.Annotations)
+	}
+}
+
+func TestOutputArtifactsConversion(t *testing.T) {
+	internalBuild := &internal.Build{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "build-1"},
+		Spec: internal.BuildSpec{
+			CommonSpec: internal.CommonSpec{
+				Output: internal.BuildOutput{
+					Artifacts: []internal.BuildOutputArtifact{
+						{Type: internal.BuildOutputArtifactSBOM, MediaType: "application/spdx+json"},
+						{Type: internal.BuildOutputArtifactProvenance, PushSecret: &kinternal.LocalObjectReference{Name: "attestation-push"}},
+					},
+				},
+			},
+		},
+	}
+
+	versionedObj, err := scheme.ConvertToVersion(internalBuild, v1.GroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versioned := versionedObj.(*v1.Build)
+	expected := `[{"type":"sbom","mediaType":"application/spdx+json"},{"type":"provenance","pushSecret":{"name":"attestation-push"}}]`
+	if versioned.Annotations[OutputArtifactsAnnotation] != expected {
+		t.Fatalf("expected annotation %s, got %q", expected, versioned.Annotations[OutputArtifactsAnnotation])
+	}
+
+	roundTrippedObj, err := scheme.ConvertToVersion(versioned, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := roundTrippedObj.(*internal.Build)
+	if !apiequality.Semantic.DeepEqual(internalBuild.Spec.Output.Artifacts, roundTripped.Spec.Output.Artifacts) {
+		t.Errorf("output artifacts did not round trip: %s", diff.ObjectReflectDiff(internalBuild.Spec.Output.Artifacts, roundTripped.Spec.Output.Artifacts))
+	}
+	if _, ok := roundTripped.Annotations[OutputArtifactsAnnotation]; ok {
+		t.Errorf("expected the annotation to be dropped from the internal object, got %v", roundTripped.Annotations)
+	}
+
+	// a malformed annotation on a stored object is tolerated so the object stays readable
+	invalid := &v1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "build-2",
+			Annotations: map[string]string{OutputArtifactsAnnotation: "not json"},
+		},
+	}
+	toleratedObj, err := scheme.ConvertToVersion(invalid, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("expected a malformed annotation to be tolerated, got %v", err)
+	}
+	tolerated := toleratedObj.(*internal.Build)
+	if len(tolerated.Spec.Output.Artifacts) != 0 {
+		t.Errorf("expected no output artifacts from a malformed annotation, got %#v", tolerated.Spec.Output.Artifacts)
+	}
+	if tolerated.Annotations[OutputArtifactsAnnotation] != "not json" {
+		t.Errorf("expected the malformed annotation to be left in place, got %v", tolerated.Annotations)
+	}
+}
+
+func TestPlacementOverridesConversion(t *testing.T) {
+	internalRequest := &internal.BuildRequest{
+		ObjectMeta:   metav1.ObjectMeta{Namespace: "default", Name: "config-1"},
+		NodeSelector: map[string]string{"node-role/gpu": "true"},
+		Tolerations: []kinternal.Toleration{
+			{Key: "gpu", Operator: kinternal.TolerationOpExists, Effect: kinternal.TaintEffectNoSchedule},
+		},
+	}
+
+	versionedObj, err := scheme.ConvertToVersion(internalRequest, v1.GroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versioned := versionedObj.(*v1.BuildRequest)
+	value, ok := versioned.Annotations[PlacementOverridesAnnotation]
+	if !ok {
+		t.Fatalf("expected a %s annotation, got %v", PlacementOverridesAnnotation, versioned.Annotations)
+	}
+	overrides, err := PlacementOverridesFromRequest(versioned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if overrides.NodeSelector["node-role/gpu"] != "true" || len(overrides.Tolerations) != 1 || overrides.Tolerations[0].Key != "gpu" {
+		t.Errorf("unexpected overrides decoded from annotation %q: %#v", value, overrides)
+	}
+
+	roundTrippedObj, err := scheme.ConvertToVersion(versioned, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := roundTrippedObj.(*internal.BuildRequest)
+	if !apiequality.Semantic.DeepEqual(internalRequest.NodeSelector, roundTripped.NodeSelector) {
+		t.Errorf("node selector did not round trip: %s", diff.ObjectReflectDiff(internalRequest.NodeSelector, roundTripped.NodeSelector))
+	}
+	if !apiequality.Semantic.DeepEqual(internalRequest.Tolerations, roundTripped.Tolerations) {
+		t.Errorf("tolerations did not round trip: %s", diff.ObjectReflectDiff(internalRequest.Tolerations, roundTripped.Tolerations))
+	}
+	if _, ok := roundTripped.Annotations[PlacementOverridesAnnotation]; ok {
+		t.Errorf("expected the annotation to be dropped from the internal object, got %v", roundTripped.Annotations)
+	}
+}
+
+func TestTolerationsConversion(t *testing.T) {
+	internalBuild := &internal.Build{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "build-1"},
+		Spec: internal.BuildSpec{
+			CommonSpec: internal.CommonSpec{
+				Tolerations: []kinternal.Toleration{
+					{Key: "gpu", Operator: kinternal.TolerationOpExists, Effect: kinternal.TaintEffectNoSchedule},
+				},
+			},
+		},
+	}
+
+	versionedObj, err := scheme.ConvertToVersion(internalBuild, v1.GroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versioned := versionedObj.(*v1.Build)
+	if expected := `[{"key":"gpu","operator":"Exists","effect":"NoSchedule"}]`; versioned.Annotations[TolerationsAnnotation] != expected {
+		t.Fatalf("expected annotation %s, got %q", expected, versioned.Annotations[TolerationsAnnotation])
+	}
+
+	roundTrippedObj, err := scheme.ConvertToVersion(versioned, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := roundTrippedObj.(*internal.Build)
+	if !apiequality.Semantic.DeepEqual(internalBuild.Spec.Tolerations, roundTripped.Spec.Tolerations) {
+		t.Errorf("tolerations did not round trip: %s", diff.ObjectReflectDiff(internalBuild.Spec.Tolerations, roundTripped.Spec.Tolerations))
+	}
+	if _, ok := roundTripped.Annotations[TolerationsAnnotation]; ok {
+		t.Errorf("expected the annotation to be dropped from the internal object, got %v", roundTripped.Annotations)
+	}
+
+	// a malformed annotation on a stored object is tolerated so the object stays readable
+	invalid := &v1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "build-2",
+			Annotations: map[string]string{TolerationsAnnotation: "not json"},
+		},
+	}
+	toleratedObj, err := scheme.ConvertToVersion(invalid, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("expected a malformed annotation to be tolerated, got %v", err)
+	}
+	tolerated := toleratedObj.(*internal.Build)
+	if len(tolerated.Spec.Tolerations) != 0 {
+		t.Errorf("expected no tolerations from a malformed annotation, got %#v", tolerated.Spec.Tolerations)
+	}
+	if tolerated.Annotations[TolerationsAnnotation] != "not json" {
+		t.Errorf("expected the malformed annotation to be left in place, got %v", tolerated.Annotations)
+	}
+}
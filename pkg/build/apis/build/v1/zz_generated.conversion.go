@@ -723,11 +723,6 @@ func autoConvert_v1_Build_To_build_Build(in *v1.Build, out *build.Build, s conve
 	return nil
 }
 
-// Convert_v1_Build_To_build_Build is an autogenerated conversion function.
-func Convert_v1_Build_To_build_Build(in *v1.Build, out *build.Build, s conversion.Scope) error {
-	return autoConvert_v1_Build_To_build_Build(in, out, s)
-}
-
 func autoConvert_build_Build_To_v1_Build(in *build.Build, out *v1.Build, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_build_BuildSpec_To_v1_BuildSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -739,11 +734,6 @@ func autoConvert_build_Build_To_v1_Build(in *build.Build, out *v1.Build, s conve
 	return nil
 }
 
-// Convert_build_Build_To_v1_Build is an autogenerated conversion function.
-func Convert_build_Build_To_v1_Build(in *build.Build, out *v1.Build, s conversion.Scope) error {
-	return autoConvert_build_Build_To_v1_Build(in, out, s)
-}
-
 func autoConvert_v1_BuildCondition_To_build_BuildCondition(in *v1.BuildCondition, out *build.BuildCondition, s conversion.Scope) error {
 	out.Type = build.BuildConditionType(in.Type)
 	out.Status = core.ConditionStatus(in.Status)
@@ -796,11 +786,6 @@ func autoConvert_build_BuildConfig_To_v1_BuildConfig(in *build.BuildConfig, out
 	return nil
 }
 
-// Convert_build_BuildConfig_To_v1_BuildConfig is an autogenerated conversion function.
-func Convert_build_BuildConfig_To_v1_BuildConfig(in *build.BuildConfig, out *v1.BuildConfig, s conversion.Scope) error {
-	return autoConvert_build_BuildConfig_To_v1_BuildConfig(in, out, s)
-}
-
 func autoConvert_v1_BuildConfigList_To_build_BuildConfigList(in *v1.BuildConfigList, out *build.BuildConfigList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
@@ -1158,11 +1143,6 @@ func autoConvert_v1_BuildRequest_To_build_BuildRequest(in *v1.BuildRequest, out
 	return nil
 }
 
-// Convert_v1_BuildRequest_To_build_BuildRequest is an autogenerated conversion function.
-func Convert_v1_BuildRequest_To_build_BuildRequest(in *v1.BuildRequest, out *build.BuildRequest, s conversion.Scope) error {
-	return autoConvert_v1_BuildRequest_To_build_BuildRequest(in, out, s)
-}
-
 func autoConvert_build_BuildRequest_To_v1_BuildRequest(in *build.BuildRequest, out *v1.BuildRequest, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if in.Revision != nil {
@@ -1229,11 +1209,6 @@ func autoConvert_build_BuildRequest_To_v1_BuildRequest(in *build.BuildRequest, o
 	return nil
 }
 
-// Convert_build_BuildRequest_To_v1_BuildRequest is an autogenerated conversion function.
-func Convert_build_BuildRequest_To_v1_BuildRequest(in *build.BuildRequest, out *v1.BuildRequest, s conversion.Scope) error {
-	return autoConvert_build_BuildRequest_To_v1_BuildRequest(in, out, s)
-}
-
 func autoConvert_v1_BuildSource_To_build_BuildSource(in *v1.BuildSource, out *build.BuildSource, s conversion.Scope) error {
 	// INFO: in.Type opted out of conversion generation
 	out.Binary = (*build.BinaryBuildSource)(unsafe.Pointer(in.Binary))
@@ -1688,8 +1663,24 @@ func Convert_build_BuildTriggerCause_To_v1_BuildTriggerCause(in *build.BuildTrig
 
 func autoConvert_v1_BuildTriggerPolicy_To_build_BuildTriggerPolicy(in *v1.BuildTriggerPolicy, out *build.BuildTriggerPolicy, s conversion.Scope) error {
 	out.Type = build.BuildTriggerType(in.Type)
-	out.GitHubWebHook = (*build.WebHookTrigger)(unsafe.Pointer(in.GitHubWebHook))
-	out.GenericWebHook = (*build.WebHookTrigger)(unsafe.Pointer(in.GenericWebHook))
+	if in.GitHubWebHook != nil {
+		in, out := &in.GitHubWebHook, &out.GitHubWebHook
+		*out = new(build.WebHookTrigger)
+		if err := Convert_v1_WebHookTrigger_To_build_WebHookTrigger(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GitHubWebHook = nil
+	}
+	if in.GenericWebHook != nil {
+		in, out := &in.GenericWebHook, &out.GenericWebHook
+		*out = new(build.WebHookTrigger)
+		if err := Convert_v1_WebHookTrigger_To_build_WebHookTrigger(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GenericWebHook = nil
+	}
 	if in.ImageChange != nil {
 		in, out := &in.ImageChange, &out.ImageChange
 		*out = new(build.ImageChangeTrigger)
@@ -1699,15 +1690,47 @@ func autoConvert_v1_BuildTriggerPolicy_To_build_BuildTriggerPolicy(in *v1.BuildT
 	} else {
 		out.ImageChange = nil
 	}
-	out.GitLabWebHook = (*build.WebHookTrigger)(unsafe.Pointer(in.GitLabWebHook))
-	out.BitbucketWebHook = (*build.WebHookTrigger)(unsafe.Pointer(in.BitbucketWebHook))
+	if in.GitLabWebHook != nil {
+		in, out := &in.GitLabWebHook, &out.GitLabWebHook
+		*out = new(build.WebHookTrigger)
+		if err := Convert_v1_WebHookTrigger_To_build_WebHookTrigger(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GitLabWebHook = nil
+	}
+	if in.BitbucketWebHook != nil {
+		in, out := &in.BitbucketWebHook, &out.BitbucketWebHook
+		*out = new(build.WebHookTrigger)
+		if err := Convert_v1_WebHookTrigger_To_build_WebHookTrigger(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.BitbucketWebHook = nil
+	}
 	return nil
 }
 
 func autoConvert_build_BuildTriggerPolicy_To_v1_BuildTriggerPolicy(in *build.BuildTriggerPolicy, out *v1.BuildTriggerPolicy, s conversion.Scope) error {
 	out.Type = v1.BuildTriggerType(in.Type)
-	out.GitHubWebHook = (*v1.WebHookTrigger)(unsafe.Pointer(in.GitHubWebHook))
-	out.GenericWebHook = (*v1.WebHookTrigger)(unsafe.Pointer(in.GenericWebHook))
+	if in.GitHubWebHook != nil {
+		in, out := &in.GitHubWebHook, &out.GitHubWebHook
+		*out = new(v1.WebHookTrigger)
+		if err := Convert_build_WebHookTrigger_To_v1_WebHookTrigger(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GitHubWebHook = nil
+	}
+	if in.GenericWebHook != nil {
+		in, out := &in.GenericWebHook, &out.GenericWebHook
+		*out = new(v1.WebHookTrigger)
+		if err := Convert_build_WebHookTrigger_To_v1_WebHookTrigger(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GenericWebHook = nil
+	}
 	if in.ImageChange != nil {
 		in, out := &in.ImageChange, &out.ImageChange
 		*out = new(v1.ImageChangeTrigger)
@@ -1717,8 +1740,24 @@ func autoConvert_build_BuildTriggerPolicy_To_v1_BuildTriggerPolicy(in *build.Bui
 	} else {
 		out.ImageChange = nil
 	}
-	out.GitLabWebHook = (*v1.WebHookTrigger)(unsafe.Pointer(in.GitLabWebHook))
-	out.BitbucketWebHook = (*v1.WebHookTrigger)(unsafe.Pointer(in.BitbucketWebHook))
+	if in.GitLabWebHook != nil {
+		in, out := &in.GitLabWebHook, &out.GitLabWebHook
+		*out = new(v1.WebHookTrigger)
+		if err := Convert_build_WebHookTrigger_To_v1_WebHookTrigger(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GitLabWebHook = nil
+	}
+	if in.BitbucketWebHook != nil {
+		in, out := &in.BitbucketWebHook, &out.BitbucketWebHook
+		*out = new(v1.WebHookTrigger)
+		if err := Convert_build_WebHookTrigger_To_v1_WebHookTrigger(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.BitbucketWebHook = nil
+	}
 	return nil
 }
 
@@ -3075,10 +3114,6 @@ func autoConvert_build_WebHookTrigger_To_v1_WebHookTrigger(in *build.WebHookTrig
 	out.Secret = in.Secret
 	out.AllowEnv = in.AllowEnv
 	out.SecretReference = (*v1.SecretLocalReference)(unsafe.Pointer(in.SecretReference))
+	// WARNING: in.AllowedRefs requires manual conversion: does not exist in peer-type
 	return nil
 }
-
-// Convert_build_WebHookTrigger_To_v1_WebHookTrigger is an autogenerated conversion function.
-func Convert_build_WebHookTrigger_To_v1_WebHookTrigger(in *build.WebHookTrigger, out *v1.WebHookTrigger, s conversion.Scope) error {
-	return autoConvert_build_WebHookTrigger_To_v1_WebHookTrigger(in, out, s)
-}
@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"path"
@@ -9,6 +10,7 @@ import (
 
 	"k8s.io/klog/v2"
 
+	corev1 "k8s.io/api/core/v1"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	kpath "k8s.io/apimachinery/pkg/api/validation/path"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -26,6 +28,7 @@ import (
 	imageref "github.com/openshift/library-go/pkg/image/reference"
 	buildapi "github.com/openshift/openshift-apiserver/pkg/build/apis/build"
 	buildinternalhelpers "github.com/openshift/openshift-apiserver/pkg/build/apis/build/internal_helpers"
+	buildapiv1 "github.com/openshift/openshift-apiserver/pkg/build/apis/build/v1"
 	imageapivalidation "github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation"
 )
 
@@ -34,6 +37,35 @@ func ValidateBuild(build *buildapi.Build) field.ErrorList {
 	allErrs := field.ErrorList{}
 	allErrs = append(allErrs, validation.ValidateObjectMeta(&build.ObjectMeta, true, apimachineryvalidation.NameIsDNSSubdomain, field.NewPath("metadata"))...)
 	allErrs = append(allErrs, validateCommonSpec(&build.Spec.CommonSpec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validatePolicyAnnotations(build.Annotations, field.NewPath("metadata", "annotations"))...)
+	return allErrs
+}
+
+// validatePolicyAnnotations rejects malformed values of the annotations that
+// conversion translates into internal fields.  Conversion tolerates bad
+// values so stored builds and build configs always stay readable; a value
+// only survives to the internal object when it could not be decoded, and it
+// is rejected here on the write path instead.
+func validatePolicyAnnotations(annotations map[string]string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if value, ok := annotations[buildapiv1.TimeoutsPolicyAnnotation]; ok {
+		policy := buildapiv1.TimeoutsPolicy{}
+		if err := json.Unmarshal([]byte(value), &policy); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(buildapiv1.TimeoutsPolicyAnnotation), value, fmt.Sprintf("must be a JSON timeouts policy: %v", err)))
+		}
+	}
+	if value, ok := annotations[buildapiv1.OutputArtifactsAnnotation]; ok {
+		artifacts := []buildapiv1.OutputArtifact{}
+		if err := json.Unmarshal([]byte(value), &artifacts); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(buildapiv1.OutputArtifactsAnnotation), value, fmt.Sprintf("must be a JSON list of output artifacts: %v", err)))
+		}
+	}
+	if value, ok := annotations[buildapiv1.TolerationsAnnotation]; ok {
+		tolerations := []corev1.Toleration{}
+		if err := json.Unmarshal([]byte(value), &tolerations); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(buildapiv1.TolerationsAnnotation), value, fmt.Sprintf("must be a JSON list of tolerations: %v", err)))
+		}
+	}
 	return allErrs
 }
 
@@ -120,6 +152,8 @@ func ValidateBuildConfig(config *buildapi.BuildConfig) field.ErrorList {
 
 	allErrs = append(allErrs, validateCommonSpec(&config.Spec.CommonSpec, specPath)...)
 
+	allErrs = append(allErrs, validatePolicyAnnotations(config.Annotations, field.NewPath("metadata", "annotations"))...)
+
 	return allErrs
 }
 
@@ -158,6 +192,10 @@ func validateCommonSpec(spec *buildapi.CommonSpec, fldPath *field.Path) field.Er
 		}
 	}
 
+	if spec.TimeoutsPolicy != nil {
+		allErrs = append(allErrs, validateTimeoutsPolicy(spec.TimeoutsPolicy, spec.CompletionDeadlineSeconds, fldPath.Child("timeoutsPolicy"))...)
+	}
+
 	allErrs = append(allErrs, validateOutput(&spec.Output, fldPath.Child("output"))...)
 	allErrs = append(allErrs, validateStrategy(&spec.Strategy, fldPath.Child("strategy"))...)
 	allErrs = append(allErrs, validatePostCommit(spec.PostCommit, fldPath.Child("postCommit"))...)
@@ -172,6 +210,38 @@ const (
 	maxJenkinsfileLengthBytes = 100 * 1000
 )
 
+func validateTimeoutsPolicy(policy *buildapi.TimeoutsPolicy, completionDeadlineSeconds *int64, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	var total int64
+	phases := []struct {
+		name    string
+		seconds *int64
+	}{
+		{"cloneTimeoutSeconds", policy.CloneTimeoutSeconds},
+		{"buildTimeoutSeconds", policy.BuildTimeoutSeconds},
+		{"pushTimeoutSeconds", policy.PushTimeoutSeconds},
+	}
+	for _, phase := range phases {
+		if phase.seconds == nil {
+			continue
+		}
+		if *phase.seconds <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(phase.name), *phase.seconds, fmt.Sprintf("%s must be a positive integer greater than 0", phase.name)))
+			continue
+		}
+		total += *phase.seconds
+	}
+
+	// the phases run back to back, so their combined budget must still fit
+	// under the whole-build deadline
+	if completionDeadlineSeconds != nil && *completionDeadlineSeconds > 0 && total > *completionDeadlineSeconds {
+		allErrs = append(allErrs, field.Invalid(fldPath, total, fmt.Sprintf("the phase timeouts sum to %d seconds, which exceeds completionDeadlineSeconds (%d)", total, *completionDeadlineSeconds)))
+	}
+
+	return allErrs
+}
+
 func validateSource(input *buildapi.BuildSource, isCustomStrategy, isDockerStrategy, isJenkinsPipelineStrategyFromRepo bool, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -476,10 +546,32 @@ func validateOutput(output *buildapi.BuildOutput, fldPath *field.Path) field.Err
 
 	allErrs = append(allErrs, validateSecretRef(output.PushSecret, fldPath.Child("pushSecret"))...)
 	allErrs = append(allErrs, ValidateImageLabels(output.ImageLabels, fldPath.Child("imageLabels"))...)
+	allErrs = append(allErrs, validateOutputArtifacts(output.Artifacts, fldPath.Child("artifacts"))...)
 
 	return allErrs
 }
 
+func validateOutputArtifacts(artifacts []buildapi.BuildOutputArtifact, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	seen := make(map[string]bool)
+	for i, artifact := range artifacts {
+		idxPath := fldPath.Index(i)
+		if len(artifact.Type) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("type"), ""))
+		} else {
+			for _, msg := range kvalidation.IsDNS1123Label(artifact.Type) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("type"), artifact.Type, msg))
+			}
+			if seen[artifact.Type] {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("type"), artifact.Type, "duplicate type"))
+			}
+			seen[artifact.Type] = true
+		}
+		allErrs = append(allErrs, validateSecretRef(artifact.PushSecret, idxPath.Child("pushSecret"))...)
+	}
+	return allErrs
+}
+
 func validateStrategy(strategy *buildapi.BuildStrategy, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -684,6 +776,15 @@ func validateWebHook(webHook *buildapi.WebHookTrigger, fldPath *field.Path, isGe
 	if !isGeneric && webHook.AllowEnv {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("allowEnv"), webHook, "git webhooks cannot allow env vars"))
 	}
+	for i, ref := range webHook.AllowedRefs {
+		if len(ref) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("allowedRefs").Index(i), ""))
+			continue
+		}
+		if _, err := path.Match(ref, ""); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("allowedRefs").Index(i), ref, fmt.Sprintf("must be a branch name or a valid glob pattern: %v", err)))
+		}
+	}
 	return allErrs
 }
 
@@ -829,6 +930,10 @@ func validateBuildVolume(volume buildapi.BuildVolume, fldPath *field.Path) field
 		}
 		foundVolumeSources = append(foundVolumeSources, string(buildapi.BuildVolumeSourceTypeConfigMap))
 	}
+	if volume.Source.Projected != nil {
+		allErrs = append(allErrs, validateBuildVolumeProjectedSource(volume.Source.Projected, fldPath.Child("source").Child("projected"))...)
+		foundVolumeSources = append(foundVolumeSources, string(buildapi.BuildVolumeSourceTypeProjected))
+	}
 	if len(foundVolumeSources) == 0 {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("source"), volume.Source, "must specify one volume source"))
 	}
@@ -859,3 +964,56 @@ func validateBuildVolume(volume buildapi.BuildVolume, fldPath *field.Path) field
 
 	return allErrs
 }
+
+// validateBuildVolumeProjectedSource validates a projected build volume
+// source.  Builds support projecting secrets, config maps and service
+// account tokens; the downward API is not available inside buildah's runtime
+// environment.
+func validateBuildVolumeProjectedSource(projected *kapi.ProjectedVolumeSource, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(projected.Sources) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("sources"), "must supply at least one projection"))
+	}
+	for i, source := range projected.Sources {
+		srcPath := fldPath.Child("sources").Index(i)
+		numSources := 0
+		if source.Secret != nil {
+			numSources++
+			if len(source.Secret.Name) == 0 {
+				allErrs = append(allErrs, field.Required(srcPath.Child("secret").Child("name"), ""))
+			} else if reasons := validation.ValidateSecretName(source.Secret.Name, false); len(reasons) != 0 {
+				for _, r := range reasons {
+					allErrs = append(allErrs, field.Invalid(srcPath.Child("secret").Child("name"), source.Secret.Name, r))
+				}
+			}
+		}
+		if source.ConfigMap != nil {
+			numSources++
+			if len(source.ConfigMap.Name) == 0 {
+				allErrs = append(allErrs, field.Required(srcPath.Child("configMap").Child("name"), ""))
+			} else if reasons := validation.ValidateConfigMapName(source.ConfigMap.Name, false); len(reasons) != 0 {
+				for _, r := range reasons {
+					allErrs = append(allErrs, field.Invalid(srcPath.Child("configMap").Child("name"), source.ConfigMap.Name, r))
+				}
+			}
+		}
+		if source.ServiceAccountToken != nil {
+			numSources++
+			if len(source.ServiceAccountToken.Path) == 0 {
+				allErrs = append(allErrs, field.Required(srcPath.Child("serviceAccountToken").Child("path"), ""))
+			}
+		}
+		if source.DownwardAPI != nil {
+			allErrs = append(allErrs, field.Invalid(srcPath.Child("downwardAPI"), "", "downward API projections are not supported in build volumes"))
+		}
+		if numSources == 0 {
+			allErrs = append(allErrs, field.Invalid(srcPath, source, "must specify a secret, configMap or serviceAccountToken projection"))
+		}
+		if numSources > 1 {
+			allErrs = append(allErrs, field.Invalid(srcPath, source, "only one projection is allowed per source"))
+		}
+	}
+
+	return allErrs
+}
@@ -11,6 +11,7 @@ import (
 	kapi "k8s.io/kubernetes/pkg/apis/core"
 
 	buildapi "github.com/openshift/openshift-apiserver/pkg/build/apis/build"
+	buildapiv1 "github.com/openshift/openshift-apiserver/pkg/build/apis/build/v1"
 
 	_ "github.com/openshift/openshift-apiserver/pkg/build/apis/build/install"
 )
@@ -3757,6 +3758,144 @@ func TestValidateBuildVolumes(t *testing.T) {
 			},
 			errors: []string{},
 		},
+		{
+			name: "projected volume without projections should fail",
+			volume: buildapi.BuildVolume{
+				Name: "some-volume",
+				Source: buildapi.BuildVolumeSource{
+					Type:      buildapi.BuildVolumeSourceTypeProjected,
+					Projected: &kapi.ProjectedVolumeSource{},
+				},
+				Mounts: []buildapi.BuildVolumeMount{
+					{
+						DestinationPath: "/some/path",
+					},
+				},
+			},
+			errors: []string{"must supply at least one projection"},
+		},
+		{
+			name: "projected volume with empty projection should fail",
+			volume: buildapi.BuildVolume{
+				Name: "some-volume",
+				Source: buildapi.BuildVolumeSource{
+					Type: buildapi.BuildVolumeSourceTypeProjected,
+					Projected: &kapi.ProjectedVolumeSource{
+						Sources: []kapi.VolumeProjection{{}},
+					},
+				},
+				Mounts: []buildapi.BuildVolumeMount{
+					{
+						DestinationPath: "/some/path",
+					},
+				},
+			},
+			errors: []string{"must specify a secret, configMap or serviceAccountToken projection"},
+		},
+		{
+			name: "projected volume with multiple projections in one source should fail",
+			volume: buildapi.BuildVolume{
+				Name: "some-volume",
+				Source: buildapi.BuildVolumeSource{
+					Type: buildapi.BuildVolumeSourceTypeProjected,
+					Projected: &kapi.ProjectedVolumeSource{
+						Sources: []kapi.VolumeProjection{
+							{
+								Secret: &kapi.SecretProjection{
+									LocalObjectReference: kapi.LocalObjectReference{Name: "my-secret"},
+								},
+								ConfigMap: &kapi.ConfigMapProjection{
+									LocalObjectReference: kapi.LocalObjectReference{Name: "my-configmap"},
+								},
+							},
+						},
+					},
+				},
+				Mounts: []buildapi.BuildVolumeMount{
+					{
+						DestinationPath: "/some/path",
+					},
+				},
+			},
+			errors: []string{"only one projection is allowed per source"},
+		},
+		{
+			name: "projected volume with downward API should fail",
+			volume: buildapi.BuildVolume{
+				Name: "some-volume",
+				Source: buildapi.BuildVolumeSource{
+					Type: buildapi.BuildVolumeSourceTypeProjected,
+					Projected: &kapi.ProjectedVolumeSource{
+						Sources: []kapi.VolumeProjection{
+							{
+								DownwardAPI: &kapi.DownwardAPIProjection{},
+							},
+						},
+					},
+				},
+				Mounts: []buildapi.BuildVolumeMount{
+					{
+						DestinationPath: "/some/path",
+					},
+				},
+			},
+			errors: []string{
+				"downward API projections are not supported in build volumes",
+				"must specify a secret, configMap or serviceAccountToken projection",
+			},
+		},
+		{
+			name: "projected service account token without path should fail",
+			volume: buildapi.BuildVolume{
+				Name: "some-volume",
+				Source: buildapi.BuildVolumeSource{
+					Type: buildapi.BuildVolumeSourceTypeProjected,
+					Projected: &kapi.ProjectedVolumeSource{
+						Sources: []kapi.VolumeProjection{
+							{
+								ServiceAccountToken: &kapi.ServiceAccountTokenProjection{},
+							},
+						},
+					},
+				},
+				Mounts: []buildapi.BuildVolumeMount{
+					{
+						DestinationPath: "/some/path",
+					},
+				},
+			},
+			errors: []string{"Required value"},
+		},
+		{
+			name: "compliant projected volume should pass",
+			volume: buildapi.BuildVolume{
+				Name: "this-name-should-pass",
+				Source: buildapi.BuildVolumeSource{
+					Type: buildapi.BuildVolumeSourceTypeProjected,
+					Projected: &kapi.ProjectedVolumeSource{
+						Sources: []kapi.VolumeProjection{
+							{
+								Secret: &kapi.SecretProjection{
+									LocalObjectReference: kapi.LocalObjectReference{Name: "my-secret"},
+								},
+							},
+							{
+								ServiceAccountToken: &kapi.ServiceAccountTokenProjection{
+									Audience: "registry",
+									Path:     "token",
+								},
+							},
+						},
+					},
+				},
+				Mounts: []buildapi.BuildVolumeMount{
+					{
+						DestinationPath: "/some/path",
+					},
+				},
+			},
+			errors: []string{},
+		},
 	}
 
 	for _, tt := range tests {
@@ -3779,3 +3918,92 @@ func TestValidateBuildVolumes(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTimeoutsPolicy(t *testing.T) {
+	int64p := func(i int64) *int64 { return &i }
+	tests := []struct {
+		name                      string
+		policy                    *buildapi.TimeoutsPolicy
+		completionDeadlineSeconds *int64
+		expectedErrors            int
+	}{
+		{
+			name: "valid per-phase timeouts",
+			policy: &buildapi.TimeoutsPolicy{
+				CloneTimeoutSeconds: int64p(60),
+				BuildTimeoutSeconds: int64p(600),
+				PushTimeoutSeconds:  int64p(300),
+			},
+			completionDeadlineSeconds: int64p(1200),
+		},
+		{
+			name:   "empty policy",
+			policy: &buildapi.TimeoutsPolicy{},
+		},
+		{
+			name: "non-positive phase timeouts",
+			policy: &buildapi.TimeoutsPolicy{
+				CloneTimeoutSeconds: int64p(0),
+				PushTimeoutSeconds:  int64p(-1),
+			},
+			expectedErrors: 2,
+		},
+		{
+			name: "phase timeouts exceed the completion deadline",
+			policy: &buildapi.TimeoutsPolicy{
+				BuildTimeoutSeconds: int64p(600),
+				PushTimeoutSeconds:  int64p(601),
+			},
+			completionDeadlineSeconds: int64p(1200),
+			expectedErrors:            1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateTimeoutsPolicy(tc.policy, tc.completionDeadlineSeconds, field.NewPath("timeoutsPolicy"))
+			if len(errs) != tc.expectedErrors {
+				t.Errorf("expected %d errors, got %v", tc.expectedErrors, errs)
+			}
+		})
+	}
+}
+
+func TestValidatePolicyAnnotations(t *testing.T) {
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		expectedErrors int
+	}{
+		{
+			name:        "no policy annotations",
+			annotations: map[string]string{"other": "value"},
+		},
+		{
+			name:        "valid timeouts policy annotation",
+			annotations: map[string]string{buildapiv1.TimeoutsPolicyAnnotation: `{"cloneTimeoutSeconds":60}`},
+		},
+		{
+			name:           "malformed timeouts policy annotation",
+			annotations:    map[string]string{buildapiv1.TimeoutsPolicyAnnotation: "not json"},
+			expectedErrors: 1,
+		},
+		{
+			name:           "malformed output artifacts annotation",
+			annotations:    map[string]string{buildapiv1.OutputArtifactsAnnotation: "not json"},
+			expectedErrors: 1,
+		},
+		{
+			name:           "malformed tolerations annotation",
+			annotations:    map[string]string{buildapiv1.TolerationsAnnotation: "not json"},
+			expectedErrors: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validatePolicyAnnotations(tc.annotations, field.NewPath("metadata", "annotations"))
+			if len(errs) != tc.expectedErrors {
+				t.Errorf("expected %d errors, got %v", tc.expectedErrors, errs)
+			}
+		})
+	}
+}
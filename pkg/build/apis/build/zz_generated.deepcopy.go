@@ -357,6 +357,13 @@ func (in *BuildOutput) DeepCopyInto(out *BuildOutput) {
 		*out = make([]ImageLabel, len(*in))
 		copy(*out, *in)
 	}
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = make([]BuildOutputArtifact, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -370,6 +377,27 @@ func (in *BuildOutput) DeepCopy() *BuildOutput {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildOutputArtifact) DeepCopyInto(out *BuildOutputArtifact) {
+	*out = *in
+	if in.PushSecret != nil {
+		in, out := &in.PushSecret, &out.PushSecret
+		*out = new(core.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildOutputArtifact.
+func (in *BuildOutputArtifact) DeepCopy() *BuildOutputArtifact {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildOutputArtifact)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildPostCommitSpec) DeepCopyInto(out *BuildPostCommitSpec) {
 	*out = *in
@@ -450,6 +478,20 @@ func (in *BuildRequest) DeepCopyInto(out *BuildRequest) {
 		*out = new(SourceStrategyOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]core.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -798,6 +840,11 @@ func (in *BuildVolumeSource) DeepCopyInto(out *BuildVolumeSource) {
 		*out = new(core.ConfigMapVolumeSource)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Projected != nil {
+		in, out := &in.Projected, &out.Projected
+		*out = new(core.ProjectedVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -829,6 +876,11 @@ func (in *CommonSpec) DeepCopyInto(out *CommonSpec) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.TimeoutsPolicy != nil {
+		in, out := &in.TimeoutsPolicy, &out.TimeoutsPolicy
+		*out = new(TimeoutsPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
 		*out = make(map[string]string, len(*in))
@@ -836,6 +888,13 @@ func (in *CommonSpec) DeepCopyInto(out *CommonSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]core.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.MountTrustedCA != nil {
 		in, out := &in.MountTrustedCA, &out.MountTrustedCA
 		*out = new(bool)
@@ -1560,6 +1619,37 @@ func (in *StepInfo) DeepCopy() *StepInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeoutsPolicy) DeepCopyInto(out *TimeoutsPolicy) {
+	*out = *in
+	if in.CloneTimeoutSeconds != nil {
+		in, out := &in.CloneTimeoutSeconds, &out.CloneTimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.BuildTimeoutSeconds != nil {
+		in, out := &in.BuildTimeoutSeconds, &out.BuildTimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PushTimeoutSeconds != nil {
+		in, out := &in.PushTimeoutSeconds, &out.PushTimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeoutsPolicy.
+func (in *TimeoutsPolicy) DeepCopy() *TimeoutsPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeoutsPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebHookTrigger) DeepCopyInto(out *WebHookTrigger) {
 	*out = *in
@@ -1568,6 +1658,11 @@ func (in *WebHookTrigger) DeepCopyInto(out *WebHookTrigger) {
 		*out = new(SecretLocalReference)
 		**out = **in
 	}
+	if in.AllowedRefs != nil {
+		in, out := &in.AllowedRefs, &out.AllowedRefs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -3,6 +3,7 @@ package apiserver
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
@@ -14,16 +15,23 @@ import (
 	buildv1 "github.com/openshift/api/build/v1"
 	buildv1client "github.com/openshift/client-go/build/clientset/versioned"
 	imagev1client "github.com/openshift/client-go/image/clientset/versioned"
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/deprecatedusage"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/buildgenerator"
 	buildetcd "github.com/openshift/openshift-apiserver/pkg/build/apiserver/registry/build/etcd"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/registry/buildclone"
 	buildconfigregistry "github.com/openshift/openshift-apiserver/pkg/build/apiserver/registry/buildconfig"
 	buildconfigetcd "github.com/openshift/openshift-apiserver/pkg/build/apiserver/registry/buildconfig/etcd"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/registry/buildconfiginstantiate"
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/registry/buildconfigpause"
 	buildlogregistry "github.com/openshift/openshift-apiserver/pkg/build/apiserver/registry/buildlog"
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/registry/buildpromote"
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/registry/buildrun"
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/registry/buildstrategies"
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/registry/deprecatedusagereport"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook/bitbucket"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook/generic"
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook/gitea"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook/github"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook/gitlab"
 )
@@ -31,6 +39,34 @@ import (
 type ExtraConfig struct {
 	KubeAPIServerClientConfig *restclient.Config
 
+	// EnvRedactionPatterns holds the environment variable name patterns whose
+	// values are masked when builds are echoed outside regular API reads.
+	EnvRedactionPatterns []string
+
+	// LogCAFile, LogClientCertFile and LogClientKeyFile override the TLS
+	// settings of the client used to stream build logs from build pods.
+	// File based settings pick up rotated certificates without a restart.
+	// Empty values keep the defaults of KubeAPIServerClientConfig.
+	LogCAFile         string
+	LogClientCertFile string
+	LogClientKeyFile  string
+
+	// ServiceAccountWaitTimeout is how long build instantiation waits for the
+	// builder service account to appear in a freshly created namespace before
+	// failing. Zero disables waiting.
+	ServiceAccountWaitTimeout time.Duration
+
+	// ScopedPushCredentialExpiration, when positive, makes build instantiation
+	// mint an expiring push secret scoped to the build's output repository
+	// instead of relying on the long-lived builder dockercfg. Zero disables
+	// minting.
+	ScopedPushCredentialExpiration time.Duration
+
+	// InstantiateHooks names external services that may mutate a generated
+	// build before it is persisted. Hooks run in order during instantiate
+	// and each carries its own failure policy. Empty disables hooks.
+	InstantiateHooks []buildgenerator.BuildHook
+
 	// TODO these should all become local eventually
 	Scheme *runtime.Scheme
 	Codecs serializer.CodecFactory
@@ -109,6 +145,28 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 	if err != nil {
 		return nil, err
 	}
+	// log streaming gets its own client when TLS overrides are configured, so a
+	// custom CA bundle or rotated client certificate only affects that path
+	logPodClient := kubeClient.CoreV1()
+	if len(c.ExtraConfig.LogCAFile) > 0 || len(c.ExtraConfig.LogClientCertFile) > 0 {
+		logClientConfig := restclient.CopyConfig(c.ExtraConfig.KubeAPIServerClientConfig)
+		logClientConfig.TLSClientConfig.Insecure = false
+		if len(c.ExtraConfig.LogCAFile) > 0 {
+			logClientConfig.TLSClientConfig.CAFile = c.ExtraConfig.LogCAFile
+			logClientConfig.TLSClientConfig.CAData = nil
+		}
+		if len(c.ExtraConfig.LogClientCertFile) > 0 {
+			logClientConfig.TLSClientConfig.CertFile = c.ExtraConfig.LogClientCertFile
+			logClientConfig.TLSClientConfig.CertData = nil
+			logClientConfig.TLSClientConfig.KeyFile = c.ExtraConfig.LogClientKeyFile
+			logClientConfig.TLSClientConfig.KeyData = nil
+		}
+		logKubeClient, err := kubernetes.NewForConfig(logClientConfig)
+		if err != nil {
+			return nil, err
+		}
+		logPodClient = logKubeClient.CoreV1()
+	}
 	buildClient, err := buildv1client.NewForConfig(c.GenericConfig.LoopbackClientConfig)
 	if err != nil {
 		return nil, err
@@ -134,12 +192,23 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 			ImageStreamImages: imageClient.ImageV1(),
 			ImageStreamTags:   imageClient.ImageV1(),
 		},
-		ServiceAccounts: kubeClient.CoreV1(),
-		Secrets:         kubeClient.CoreV1(),
+		ServiceAccounts:           kubeClient.CoreV1(),
+		Secrets:                   kubeClient.CoreV1(),
+		EnvRedactionPatterns:      c.ExtraConfig.EnvRedactionPatterns,
+		ServiceAccountWaitTimeout: c.ExtraConfig.ServiceAccountWaitTimeout,
+	}
+	if c.ExtraConfig.ScopedPushCredentialExpiration > 0 {
+		buildGenerator.PushCredentials = buildgenerator.NewServiceAccountPushCredentialMinter(kubeClient.CoreV1(), kubeClient.CoreV1(), c.ExtraConfig.ScopedPushCredentialExpiration)
+	}
+	if len(c.ExtraConfig.InstantiateHooks) > 0 {
+		buildGenerator.Hooks = buildgenerator.NewBuildHookCaller(c.ExtraConfig.InstantiateHooks, nil)
 	}
 	buildConfigWebHooks := buildconfigregistry.NewWebHookREST(
 		buildClient.BuildV1(),
 		kubeClient.CoreV1(),
+		kubeClient.CoreV1(),
+		kubeClient.CoreV1(),
+		c.ExtraConfig.EnvRedactionPatterns,
 		// We use the buildv1 schemegroup to encode the Build that gets
 		// returned. As such, we need to make sure that the GroupVersion we use
 		// is the same API version that the storage is going to be used for.
@@ -148,6 +217,7 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 			"generic":   generic.New(),
 			"github":    github.New(),
 			"gitlab":    gitlab.New(),
+			"gitea":     gitea.New(),
 			"bitbucket": bitbucket.New(),
 		},
 	)
@@ -157,14 +227,24 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 	v1Storage["builds/clone"] = buildclone.NewStorage(buildGenerator)
 	v1Storage["builds/log"] = buildlogregistry.NewREST(
 		buildClient.BuildV1(),
-		kubeClient.CoreV1(),
+		logPodClient,
 		c.GenericConfig.SharedInformerFactory.Core().V1().Pods().Lister(),
 	)
 	v1Storage["builds/details"] = buildDetailsStorage
+	v1Storage["builds/run"] = buildrun.NewREST(buildClient.BuildV1(), kubeClient.CoreV1())
+	v1Storage["builds/promote"] = buildpromote.NewREST(
+		buildClient.BuildV1(),
+		imageClient.ImageV1(),
+		kubeClient.AuthorizationV1().SubjectAccessReviews(),
+	)
 
 	v1Storage["buildConfigs"] = buildConfigStorage
 	v1Storage["buildConfigs/webhooks"] = buildConfigWebHooks
 	v1Storage["buildConfigs/instantiate"] = buildconfiginstantiate.NewStorage(buildGenerator)
 	v1Storage["buildConfigs/instantiatebinary"] = buildconfiginstantiate.NewBinaryStorage(buildGenerator, buildClient.BuildV1(), c.ExtraConfig.KubeAPIServerClientConfig)
+	v1Storage["buildConfigs/allowedstrategies"] = buildstrategies.NewREST(kubeClient.AuthorizationV1().SubjectAccessReviews())
+	v1Storage["buildConfigs/pause"] = buildconfigpause.NewPauseREST(buildClient.BuildV1())
+	v1Storage["buildConfigs/resume"] = buildconfigpause.NewResumeREST(buildClient.BuildV1())
+	v1Storage["deprecatedApiUsageReports"] = deprecatedusagereport.NewREST(deprecatedusage.DefaultTracker)
 	return v1Storage, nil
 }
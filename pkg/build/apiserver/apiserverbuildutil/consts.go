@@ -4,6 +4,7 @@ const (
 	BuildTriggerCauseGithubMsg    = "GitHub WebHook"
 	BuildTriggerCauseGenericMsg   = "Generic WebHook"
 	BuildTriggerCauseGitLabMsg    = "GitLab WebHook"
+	BuildTriggerCauseGiteaMsg     = "Gitea WebHook"
 	BuildTriggerCauseBitbucketMsg = "Bitbucket WebHook"
 )
 
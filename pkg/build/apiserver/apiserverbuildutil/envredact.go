@@ -0,0 +1,104 @@
+package apiserverbuildutil
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	"github.com/openshift/library-go/pkg/build/buildutil"
+)
+
+// RedactedEnvValue replaces the value of environment variables whose names
+// match a redaction pattern whenever builds are echoed outside the API
+// storage, e.g. in webhook responses or log lines.
+const RedactedEnvValue = "<redacted>"
+
+// DefaultEnvRedactionPatterns is the set of environment variable name
+// patterns that are considered sensitive when no explicit configuration is
+// provided.  Matching is case-insensitive shell-style globbing.
+var DefaultEnvRedactionPatterns = []string{
+	"*TOKEN*",
+	"*SECRET*",
+	"*PASSWORD*",
+	"*PASSWD*",
+	"*APIKEY*",
+	"*API_KEY*",
+	"*ACCESS_KEY*",
+	"*CREDENTIAL*",
+}
+
+// ParseEnvRedactionPatterns parses the build-env-redaction-patterns argument.
+// Each value may hold several comma-separated shell-style patterns that are
+// matched case-insensitively against environment variable names.  An empty
+// argument selects DefaultEnvRedactionPatterns; malformed patterns are
+// rejected so that typos fail server startup instead of silently disabling
+// redaction.
+func ParseEnvRedactionPatterns(values []string) ([]string, error) {
+	var patterns []string
+	for _, value := range values {
+		for _, pattern := range strings.Split(value, ",") {
+			pattern = strings.TrimSpace(pattern)
+			if len(pattern) == 0 {
+				continue
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid build env redaction pattern %q: %v", pattern, err)
+			}
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return DefaultEnvRedactionPatterns, nil
+	}
+	return patterns, nil
+}
+
+// EnvNameIsSensitive returns true if the environment variable name matches
+// any of the given redaction patterns.
+func EnvNameIsSensitive(name string, patterns []string) bool {
+	upperName := strings.ToUpper(name)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToUpper(pattern), upperName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactEnv replaces the literal values of sensitive environment variables
+// with RedactedEnvValue and returns the result.  The input slice is not
+// modified.  Variables populated through ValueFrom carry references rather
+// than literal values and are left untouched.
+func RedactEnv(env []corev1.EnvVar, patterns []string) []corev1.EnvVar {
+	if len(env) == 0 || len(patterns) == 0 {
+		return env
+	}
+	redacted := make([]corev1.EnvVar, len(env))
+	copy(redacted, env)
+	for i := range redacted {
+		if len(redacted[i].Value) > 0 && EnvNameIsSensitive(redacted[i].Name, patterns) {
+			redacted[i].Value = RedactedEnvValue
+		}
+	}
+	return redacted
+}
+
+// RedactBuildEnv masks sensitive environment variables and docker build
+// arguments on the given build in place.  It is meant for copies of builds
+// that are echoed back to clients outside regular API reads, such as the
+// build returned in a webhook response; it must not be applied to objects
+// that are about to be persisted.
+func RedactBuildEnv(build *buildv1.Build, patterns []string) {
+	if build == nil || len(patterns) == 0 {
+		return
+	}
+	if env := buildutil.GetBuildEnv(build); len(env) > 0 {
+		buildutil.SetBuildEnv(build, RedactEnv(env, patterns))
+	}
+	if build.Spec.Strategy.DockerStrategy != nil {
+		build.Spec.Strategy.DockerStrategy.BuildArgs = RedactEnv(build.Spec.Strategy.DockerStrategy.BuildArgs, patterns)
+	}
+}
@@ -0,0 +1,140 @@
+package apiserverbuildutil
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+func TestParseEnvRedactionPatterns(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []string
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:     "empty selects defaults",
+			values:   nil,
+			expected: DefaultEnvRedactionPatterns,
+		},
+		{
+			name:     "blank values select defaults",
+			values:   []string{"", " , "},
+			expected: DefaultEnvRedactionPatterns,
+		},
+		{
+			name:     "comma separated values",
+			values:   []string{"*TOKEN*, MY_CRED"},
+			expected: []string{"*TOKEN*", "MY_CRED"},
+		},
+		{
+			name:     "repeated argument",
+			values:   []string{"*TOKEN*", "MY_CRED"},
+			expected: []string{"*TOKEN*", "MY_CRED"},
+		},
+		{
+			name:      "malformed pattern",
+			values:    []string{"[oops"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		patterns, err := ParseEnvRedactionPatterns(test.values)
+		if test.expectErr {
+			if err == nil {
+				t.Errorf("%s: expected error, got patterns %v", test.name, patterns)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(patterns, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, patterns)
+		}
+	}
+}
+
+func TestEnvNameIsSensitive(t *testing.T) {
+	patterns := []string{"*TOKEN*", "my_cred"}
+	tests := map[string]bool{
+		"GIT_TOKEN":    true,
+		"token":        true,
+		"MY_CRED":      true,
+		"GIT_REF":      false,
+		"MY_CRED_FILE": false,
+	}
+	for name, expected := range tests {
+		if actual := EnvNameIsSensitive(name, patterns); actual != expected {
+			t.Errorf("%s: expected %v, got %v", name, expected, actual)
+		}
+	}
+}
+
+func TestRedactEnv(t *testing.T) {
+	env := []corev1.EnvVar{
+		{Name: "GIT_TOKEN", Value: "s3cret"},
+		{Name: "GIT_REF", Value: "main"},
+		{Name: "OTHER_TOKEN", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{Key: "token"},
+		}},
+	}
+
+	redacted := RedactEnv(env, DefaultEnvRedactionPatterns)
+
+	expected := []corev1.EnvVar{
+		{Name: "GIT_TOKEN", Value: RedactedEnvValue},
+		{Name: "GIT_REF", Value: "main"},
+		env[2],
+	}
+	if !reflect.DeepEqual(redacted, expected) {
+		t.Errorf("expected %#v, got %#v", expected, redacted)
+	}
+	if env[0].Value != "s3cret" {
+		t.Errorf("input slice was modified: %#v", env)
+	}
+}
+
+func TestRedactBuildEnv(t *testing.T) {
+	build := &buildv1.Build{
+		Spec: buildv1.BuildSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Strategy: buildv1.BuildStrategy{
+					DockerStrategy: &buildv1.DockerBuildStrategy{
+						Env: []corev1.EnvVar{
+							{Name: "ACCESS_KEY", Value: "aki"},
+							{Name: "LOG_LEVEL", Value: "4"},
+						},
+						BuildArgs: []corev1.EnvVar{
+							{Name: "NPM_TOKEN", Value: "tok"},
+							{Name: "VERSION", Value: "1.0"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	RedactBuildEnv(build, DefaultEnvRedactionPatterns)
+
+	expectedEnv := []corev1.EnvVar{
+		{Name: "ACCESS_KEY", Value: RedactedEnvValue},
+		{Name: "LOG_LEVEL", Value: "4"},
+	}
+	if actual := build.Spec.Strategy.DockerStrategy.Env; !reflect.DeepEqual(actual, expectedEnv) {
+		t.Errorf("expected env %#v, got %#v", expectedEnv, actual)
+	}
+	expectedArgs := []corev1.EnvVar{
+		{Name: "NPM_TOKEN", Value: RedactedEnvValue},
+		{Name: "VERSION", Value: "1.0"},
+	}
+	if actual := build.Spec.Strategy.DockerStrategy.BuildArgs; !reflect.DeepEqual(actual, expectedArgs) {
+		t.Errorf("expected build args %#v, got %#v", expectedArgs, actual)
+	}
+}
@@ -2,12 +2,14 @@ package buildgenerator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	v1 "github.com/openshift/openshift-apiserver/pkg/build/apis/build/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -16,12 +18,15 @@ import (
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation"
 	kvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/audit"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/credentialprovider"
 	credentialprovidersecrets "k8s.io/kubernetes/pkg/credentialprovider/secrets"
+	utiltrace "k8s.io/utils/trace"
 
 	buildv1 "github.com/openshift/api/build/v1"
 	imagev1 "github.com/openshift/api/image/v1"
@@ -32,16 +37,73 @@ import (
 	"github.com/openshift/library-go/pkg/image/imageutil"
 	"github.com/openshift/openshift-apiserver/pkg/bootstrappolicy"
 	buildapi "github.com/openshift/openshift-apiserver/pkg/build/apis/build"
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/apiserverbuildutil"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestreammapping"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/scanpolicy"
 )
 
 const conflictRetries = 3
 
+const (
+	// AllowedNodeSelectorOverridesAnnotation is a comma-separated list of node
+	// selector keys, set on a BuildConfig, that a BuildRequest may override.
+	// "*" allows any key; absent or empty, no overrides are allowed.
+	AllowedNodeSelectorOverridesAnnotation = "build.openshift.io/allowed-node-selector-overrides"
+	// AllowedTolerationOverridesAnnotation is a comma-separated list of
+	// toleration keys, set on a BuildConfig, that a BuildRequest may supply.
+	// "*" allows any key; absent or empty, no overrides are allowed.
+	AllowedTolerationOverridesAnnotation = "build.openshift.io/allowed-toleration-overrides"
+	// imageResolutionAuditAnnotationPrefix, suffixed with the image stream
+	// reference being resolved, is set on the audit event of a request whenever
+	// build generation rewrites that reference to a pull spec.  The value is a
+	// JSON object recording the original reference, the resolved pull spec and
+	// the UID of the image stream that supplied it.
+	imageResolutionAuditAnnotationPrefix = "build.openshift.io/image-resolution."
+	// BuildCauseChainAnnotation records the ancestry of a cloned build as a
+	// JSON array of entries, oldest first, each naming an ancestor build and
+	// the trigger types that started it.  The clone annotation only names the
+	// immediate parent, so without the chain the provenance of a clone of a
+	// clone requires walking builds that may since have been pruned.
+	BuildCauseChainAnnotation = "build.openshift.io/cause-chain"
+	// PreferredPullSecretAnnotation, set to "true" on a dockercfg secret of the
+	// builder service account, marks the secret the generator should pick when
+	// several of the service account's secrets carry credentials for the same
+	// registry.
+	PreferredPullSecretAnnotation = "build.openshift.io/preferred-secret"
+	// ChosenPullSecretAnnotation is set on a generated build to record which
+	// service account secrets the generator selected for pulling images, so
+	// pull authentication failures can be traced to a concrete secret.  The
+	// value is a comma-separated list of secret names.
+	ChosenPullSecretAnnotation = "build.openshift.io/chosen-pull-secret"
+)
+
+// maxBuildCauseChainLength bounds the cause chain annotation; when exceeded,
+// the oldest entries are dropped.
+const maxBuildCauseChainLength = 10
+
 // BuildGenerator is a central place responsible for generating new Build objects
 // from BuildConfigs and other Builds.
 type BuildGenerator struct {
 	Client          GeneratorClient
 	ServiceAccounts corev1client.ServiceAccountsGetter
 	Secrets         corev1client.SecretsGetter
+	// EnvRedactionPatterns holds the environment variable name patterns whose
+	// values are masked when request details are written to the log.
+	EnvRedactionPatterns []string
+	// ServiceAccountWaitTimeout is how long to wait for the builder service
+	// account to appear when it does not exist yet, which is common in brand
+	// new namespaces where the controller creates it asynchronously. Zero
+	// disables waiting.
+	ServiceAccountWaitTimeout time.Duration
+	// PushCredentials, when set, mints a short-lived push secret scoped to
+	// the build's output repository and sets it as the build's push secret,
+	// so the long-lived builder dockercfg never reaches the build pod. Nil
+	// keeps the existing behavior.
+	PushCredentials PushCredentialMinter
+	// Hooks, when set, posts every generated build to the configured hook
+	// services before it is persisted and applies the mutations they return.
+	// Nil disables hooks.
+	Hooks *BuildHookCaller
 }
 
 // GeneratorClient is the API client used by the generator
@@ -108,21 +170,79 @@ func (c Client) GetImageStreamTag(ctx context.Context, name string, options meta
 	return c.ImageStreamTags.ImageStreamTags(apirequest.NamespaceValue(ctx)).Get(ctx, name, options)
 }
 
+// BuilderServiceAccountMissingReason is the status reason returned when a
+// build cannot be instantiated because the builder service account does not
+// exist yet. Clients can match on it and retry after the interval given in
+// the status details.
+const BuilderServiceAccountMissingReason metav1.StatusReason = "BuilderServiceAccountMissing"
+
+// serviceAccountPollInterval is how often the generator rechecks for the
+// builder service account while waiting for it to appear.
+const serviceAccountPollInterval = 500 * time.Millisecond
+
+// builderServiceAccountMissingError explains that the builder service account
+// has not been created yet. It carries a dedicated reason and retry-after
+// semantics because the account is provisioned asynchronously in new
+// namespaces and the condition resolves itself.
+func builderServiceAccountMissingError(namespace, serviceAccount string) *errors.StatusError {
+	return &errors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusServiceUnavailable,
+		Reason:  BuilderServiceAccountMissingReason,
+		Message: fmt.Sprintf("service account %s/%s does not exist yet; it is created asynchronously in new namespaces, retry shortly", namespace, serviceAccount),
+		Details: &metav1.StatusDetails{
+			RetryAfterSeconds: 5,
+		},
+	}}
+}
+
+// IsBuilderServiceAccountMissing reports whether err says the builder service
+// account has not been provisioned yet.
+func IsBuilderServiceAccountMissing(err error) bool {
+	return errors.ReasonForError(err) == BuilderServiceAccountMissingReason
+}
+
 // fetchServiceAccountSecrets retrieves the Secrets used for pushing and pulling
 // images from private container image registries.
-func fetchServiceAccountSecrets(ctx context.Context, secrets corev1client.SecretsGetter, serviceAccounts corev1client.ServiceAccountsGetter, namespace, serviceAccount string) ([]corev1.Secret, error) {
+func (g *BuildGenerator) fetchServiceAccountSecrets(ctx context.Context, namespace, serviceAccount string) ([]corev1.Secret, error) {
 	var result []corev1.Secret
-	sa, err := serviceAccounts.ServiceAccounts(namespace).Get(ctx, serviceAccount, metav1.GetOptions{})
+	sa, err := g.ServiceAccounts.ServiceAccounts(namespace).Get(ctx, serviceAccount, metav1.GetOptions{})
+	if errors.IsNotFound(err) && g.ServiceAccountWaitTimeout > 0 {
+		// give the service account controller a moment to populate a freshly
+		// created namespace before giving up
+		waitErr := wait.PollImmediate(serviceAccountPollInterval, g.ServiceAccountWaitTimeout, func() (bool, error) {
+			// stop waiting when the request that needed the secrets is gone
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return false, ctxErr
+			}
+			sa, err = g.ServiceAccounts.ServiceAccounts(namespace).Get(ctx, serviceAccount, metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return err == nil, err
+		})
+		if waitErr != nil && waitErr != wait.ErrWaitTimeout {
+			err = waitErr
+		}
+	}
 	if err != nil {
+		if errors.IsNotFound(err) {
+			return result, builderServiceAccountMissingError(namespace, serviceAccount)
+		}
 		return result, fmt.Errorf("error getting push/pull secrets for service account %s/%s: %v", namespace, serviceAccount, err)
 	}
 	for _, ref := range sa.Secrets {
-		secret, err := secrets.Secrets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		secret, err := g.Secrets.Secrets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
 		if err != nil {
 			continue
 		}
 		result = append(result, *secret)
 	}
+	if trace := utiltrace.FromContext(ctx); trace != nil {
+		trace.Step("Fetched service account secrets",
+			utiltrace.Field{Key: "serviceAccount", Value: serviceAccount},
+			utiltrace.Field{Key: "secrets", Value: len(result)})
+	}
 	return result, nil
 }
 
@@ -181,7 +301,7 @@ func findImageChangeTrigger(bc *buildv1.BuildConfig, ref *corev1.ObjectReference
 	return requestTrigger, responseTrigger
 }
 
-func describeBuildRequest(request *buildv1.BuildRequest) string {
+func (g *BuildGenerator) describeBuildRequest(request *buildv1.BuildRequest) string {
 	desc := fmt.Sprintf("BuildConfig: %s/%s", request.Namespace, request.Name)
 	if request.Revision != nil {
 		desc += fmt.Sprintf(", Revision: %#v", request.Revision.Git)
@@ -194,6 +314,15 @@ func describeBuildRequest(request *buildv1.BuildRequest) string {
 	if request.LastVersion != nil {
 		desc += fmt.Sprintf(", LastVersion: %d", *request.LastVersion)
 	}
+	if len(request.Env) > 0 {
+		// env vars commonly carry credentials, so sensitive values are masked
+		// before the request is logged
+		var envDesc []string
+		for _, env := range apiserverbuildutil.RedactEnv(request.Env, g.EnvRedactionPatterns) {
+			envDesc = append(envDesc, fmt.Sprintf("%s=%s", env.Name, env.Value))
+		}
+		desc += fmt.Sprintf(", Env: [%s]", strings.Join(envDesc, ", "))
+	}
 	return desc
 }
 
@@ -220,6 +349,64 @@ func updateBuildArgs(oldArgs *[]corev1.EnvVar, newArgs []corev1.EnvVar) []corev1
 	return result
 }
 
+// allowedOverrideKeys parses a comma-separated list of keys from a
+// buildconfig annotation into a set.
+func allowedOverrideKeys(value string) map[string]bool {
+	allowed := map[string]bool{}
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); len(key) > 0 {
+			allowed[key] = true
+		}
+	}
+	return allowed
+}
+
+// applyPlacementOverrides merges the node selector and tolerations carried by
+// the build request into the generated build. Each key must be listed in the
+// buildconfig's allowed override annotations; the request is rejected
+// otherwise so build configs stay in control of where their builds may land.
+func applyPlacementOverrides(request *buildv1.BuildRequest, bc *buildv1.BuildConfig, build *buildv1.Build) error {
+	overrides, err := v1.PlacementOverridesFromRequest(request)
+	if err != nil {
+		return errors.NewBadRequest(err.Error())
+	}
+	if overrides == nil {
+		return nil
+	}
+	// the annotation describes the request; it has no meaning on the build
+	delete(build.Annotations, v1.PlacementOverridesAnnotation)
+
+	if len(overrides.NodeSelector) > 0 {
+		allowed := allowedOverrideKeys(bc.Annotations[AllowedNodeSelectorOverridesAnnotation])
+		for key := range overrides.NodeSelector {
+			if !allowed[key] && !allowed["*"] {
+				return errors.NewBadRequest(fmt.Sprintf("BuildConfig %s/%s does not allow overriding node selector key %q", bc.Namespace, bc.Name, key))
+			}
+		}
+		if build.Spec.NodeSelector == nil {
+			build.Spec.NodeSelector = buildv1.OptionalNodeSelector{}
+		}
+		for key, value := range overrides.NodeSelector {
+			build.Spec.NodeSelector[key] = value
+		}
+	}
+
+	if len(overrides.Tolerations) > 0 {
+		allowed := allowedOverrideKeys(bc.Annotations[AllowedTolerationOverridesAnnotation])
+		for _, toleration := range overrides.Tolerations {
+			if !allowed[toleration.Key] && !allowed["*"] {
+				return errors.NewBadRequest(fmt.Sprintf("BuildConfig %s/%s does not allow overriding toleration key %q", bc.Namespace, bc.Name, toleration.Key))
+			}
+		}
+		// the v1 build spec has no tolerations field; they ride the
+		// annotation that conversion translates to the internal field
+		if err := v1.AppendTolerations(&build.ObjectMeta, overrides.Tolerations); err != nil {
+			return errors.NewBadRequest(err.Error())
+		}
+	}
+	return nil
+}
+
 // DEPRECATED: Use only by apiserver
 func (g *BuildGenerator) InstantiateInternal(ctx context.Context, request *buildapi.BuildRequest, opts metav1.CreateOptions) (*buildapi.Build, error) {
 	versionedRequest := &buildv1.BuildRequest{}
@@ -244,6 +431,10 @@ func (g *BuildGenerator) Instantiate(ctx context.Context, request *buildv1.Build
 	for i := 0; i < conflictRetries; i++ {
 		build, err = g.instantiate(ctx, request, opts)
 		if errors.IsConflict(err) {
+			// stop retrying when the request that asked for the build is gone
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
 			klog.V(2).Infof("instantiate returned conflict, try %d/%d", i+1, conflictRetries)
 			continue
 		}
@@ -258,11 +449,18 @@ func (g *BuildGenerator) Instantiate(ctx context.Context, request *buildv1.Build
 }
 
 func (g *BuildGenerator) instantiate(ctx context.Context, request *buildv1.BuildRequest, opts metav1.CreateOptions) (*buildv1.Build, error) {
-	klog.V(4).Infof("Generating Build from %s", describeBuildRequest(request))
+	klog.V(4).Infof("Generating Build from %s", g.describeBuildRequest(request))
+	trace := utiltrace.FromContext(ctx).Nest("BuildConfig instantiate",
+		utiltrace.Field{Key: "namespace", Value: request.Namespace},
+		utiltrace.Field{Key: "name", Value: request.Name})
+	defer trace.LogIfLong(5 * time.Second)
+	ctx = utiltrace.ContextWithTrace(ctx, trace)
+
 	bc, err := g.Client.GetBuildConfig(ctx, request.Name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
+	trace.Step("Fetched build config")
 	if isPaused(bc) {
 		return nil, errors.NewBadRequest(fmt.Sprintf("can't instantiate from BuildConfig %s/%s: BuildConfig is paused", bc.Namespace, bc.Name))
 	}
@@ -277,6 +475,7 @@ func (g *BuildGenerator) instantiate(ctx context.Context, request *buildv1.Build
 		}
 		return nil, errors.NewInternalError(err)
 	}
+	trace.Step("Updated image change triggers")
 
 	newBuild, err := g.generateBuildFromConfig(ctx, bc, request.Revision, request.Binary)
 	if err != nil {
@@ -285,6 +484,7 @@ func (g *BuildGenerator) instantiate(ctx context.Context, request *buildv1.Build
 		}
 		return nil, errors.NewInternalError(err)
 	}
+	trace.Step("Generated build from config")
 
 	// Add labels and annotations from the buildrequest.  Existing
 	// label/annotations will take precedence because we don't want system
@@ -293,7 +493,7 @@ func (g *BuildGenerator) instantiate(ctx context.Context, request *buildv1.Build
 	newBuild.Labels = mergeMaps(request.Labels, newBuild.Labels)
 
 	// Copy build trigger information and build arguments to the build object.
-	newBuild.Spec.TriggeredBy = request.TriggeredBy
+	setBuildTriggerCauses(newBuild, request.TriggeredBy)
 
 	if len(request.Env) > 0 {
 		updateBuildEnv(newBuild, request.Env)
@@ -332,6 +532,23 @@ func (g *BuildGenerator) instantiate(ctx context.Context, request *buildv1.Build
 			newBuild.Spec.Strategy.SourceStrategy.Incremental = sourceOpts.Incremental
 		}
 	}
+	// Apply per-run placement overrides from the request, limited to the keys
+	// the buildconfig allows.
+	if err := applyPlacementOverrides(request, bc, newBuild); err != nil {
+		return nil, err
+	}
+
+	// give the configured hooks a chance to mutate the build before anything
+	// is persisted, so a failing hook with a Fail policy rejects the request
+	// without leaving a bumped LastVersion behind
+	if g.Hooks != nil {
+		newBuild, err = g.Hooks.Mutate(ctx, request, newBuild)
+		if err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+		trace.Step("Applied build hooks")
+	}
+
 	klog.V(4).Infof("Build %s/%s has been generated from %s/%s BuildConfig", newBuild.Namespace, newBuild.ObjectMeta.Name, bc.Namespace, bc.ObjectMeta.Name)
 
 	// need to update the BuildConfig because LastVersion and possibly
@@ -340,13 +557,16 @@ func (g *BuildGenerator) instantiate(ctx context.Context, request *buildv1.Build
 		klog.V(2).Infof("Failed to update BuildConfig %s/%s so no Build will be created", bc.Namespace, bc.Name)
 		return nil, err
 	}
+	trace.Step("Persisted build config")
 
 	// Ideally we would create the build *before* updating the BC to ensure
 	// that we don't set the LastTriggeredImageID on the BC and then fail to
 	// create the corresponding build, however doing things in that order
 	// allows for a race condition in which two builds get kicked off.  Doing
 	// it in this order ensures that we catch the race while updating the BC.
-	return g.createBuild(ctx, newBuild, opts)
+	build, err := g.createBuild(ctx, newBuild, opts)
+	trace.Step("Persisted build")
+	return build, err
 }
 
 // checkLastVersion will return an error if the BuildConfig's LastVersion doesn't match the passed in lastVersion
@@ -460,6 +680,9 @@ func (g *BuildGenerator) Clone(ctx context.Context, request *buildv1.BuildReques
 		if err == nil || !errors.IsConflict(err) {
 			break
 		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		klog.V(4).Infof("clone returned conflict, try %d/%d", i+1, conflictRetries)
 	}
 
@@ -489,7 +712,7 @@ func (g *BuildGenerator) clone(ctx context.Context, request *buildv1.BuildReques
 	klog.V(4).Infof("Build %s/%s has been generated from Build %s/%s", newBuild.Namespace, newBuild.ObjectMeta.Name, build.Namespace, build.ObjectMeta.Name)
 
 	// Copy build trigger information to the build object.
-	newBuild.Spec.TriggeredBy = request.TriggeredBy
+	setBuildTriggerCauses(newBuild, request.TriggeredBy)
 
 	if len(request.Env) > 0 {
 		updateBuildEnv(newBuild, request.Env)
@@ -506,6 +729,22 @@ func (g *BuildGenerator) clone(ctx context.Context, request *buildv1.BuildReques
 		}
 	}
 
+	// Apply a source revision override. A clone normally reuses the exact
+	// revision recorded on the original build; a request may instead name a
+	// different commit, branch or tag via Revision.Git.Commit, or supply an
+	// empty git revision to unpin the clone and build the current head of the
+	// configured ref.
+	if request.Revision != nil {
+		if newBuild.Spec.Source.Git == nil {
+			return nil, errors.NewBadRequest(fmt.Sprintf("cannot override the source revision of %s/%s: not a Git based build", build.Namespace, build.Name))
+		}
+		// the recorded revision no longer describes what will be built
+		newBuild.Spec.Revision = nil
+		if git := request.Revision.Git; git != nil && len(git.Commit) > 0 {
+			newBuild.Spec.Source.Git.Ref = git.Commit
+		}
+	}
+
 	// need to update the BuildConfig because LastVersion changed
 	if buildConfig != nil {
 		if err := g.Client.UpdateBuildConfig(ctx, buildConfig, metav1.UpdateOptions{}); err != nil {
@@ -608,29 +847,96 @@ func (g *BuildGenerator) generateBuildFromConfig(ctx context.Context, bc *buildv
 
 	var builderSecrets []corev1.Secret
 	var err error
-	if builderSecrets, err = fetchServiceAccountSecrets(ctx, g.Secrets, g.ServiceAccounts, bcCopy.Namespace, serviceAccount); err != nil {
+	if builderSecrets, err = g.fetchServiceAccountSecrets(ctx, bcCopy.Namespace, serviceAccount); err != nil {
 		return nil, err
 	}
 
 	// Resolve image source if present
-	if err = g.setBuildSourceImage(ctx, builderSecrets, bcCopy, &build.Spec.Source); err != nil {
+	if err = g.setBuildSourceImage(ctx, builderSecrets, bcCopy, build); err != nil {
 		return nil, err
 	}
-	if err = g.setBaseImageAndPullSecretForBuildStrategy(ctx, builderSecrets, bcCopy, &build.Spec.Strategy); err != nil {
+	if err = g.setBaseImageAndPullSecretForBuildStrategy(ctx, builderSecrets, bcCopy, build); err != nil {
+		return nil, err
+	}
+	if err = g.setScopedPushSecret(ctx, bcCopy, serviceAccount, build); err != nil {
+		return nil, err
+	}
+	if err = setOutputArtifacts(bcCopy, build); err != nil {
 		return nil, err
 	}
 
 	return build, nil
 }
 
+// setOutputArtifacts copies the output artifacts declared on the build config
+// to the generated build and completes the push secret plumbing: an artifact
+// that does not name its own push secret pushes with the build output's push
+// secret, including a minted scoped secret.
+func setOutputArtifacts(bcCopy *buildv1.BuildConfig, build *buildv1.Build) error {
+	artifacts, err := v1.OutputArtifactsFromAnnotation(&bcCopy.ObjectMeta)
+	if err != nil {
+		return err
+	}
+	if len(artifacts) == 0 {
+		return nil
+	}
+	for i := range artifacts {
+		if artifacts[i].PushSecret == nil && build.Spec.Output.PushSecret != nil {
+			artifacts[i].PushSecret = build.Spec.Output.PushSecret
+		}
+	}
+	return v1.SetOutputArtifactsAnnotation(&build.ObjectMeta, artifacts)
+}
+
+// setScopedPushSecret replaces the implicit builder dockercfg with a minted
+// short-lived secret scoped to the build's output repository. Builds that
+// already name a push secret, or whose output does not resolve to a
+// repository of the integrated registry, are left alone.
+func (g *BuildGenerator) setScopedPushSecret(ctx context.Context, bcCopy *buildv1.BuildConfig, serviceAccount string, build *buildv1.Build) error {
+	if g.PushCredentials == nil {
+		return nil
+	}
+	output := &build.Spec.Output
+	if output.To == nil || output.To.Kind != "ImageStreamTag" || output.PushSecret != nil {
+		return nil
+	}
+	namespace := output.To.Namespace
+	if len(namespace) == 0 {
+		namespace = bcCopy.Namespace
+	}
+	name, _, err := imageutil.ParseImageStreamTagName(output.To.Name)
+	if err != nil {
+		return err
+	}
+	stream, err := g.Client.GetImageStream(apirequest.WithNamespace(ctx, namespace), name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(2).Infof("Output image stream %s/%s does not exist yet, not minting a scoped push secret for build %s", namespace, name, build.Name)
+			return nil
+		}
+		return err
+	}
+	if len(stream.Status.DockerImageRepository) == 0 {
+		klog.V(4).Infof("Image stream %s/%s is not served by the integrated registry, not minting a scoped push secret", namespace, name)
+		return nil
+	}
+	secretRef, err := g.PushCredentials.MintPushCredential(ctx, bcCopy.Namespace, serviceAccount, build.Name, stream.Status.DockerImageRepository)
+	if err != nil {
+		return fmt.Errorf("unable to mint a scoped push secret for build %s/%s: %v", bcCopy.Namespace, build.Name, err)
+	}
+	output.PushSecret = secretRef
+	return nil
+}
+
 // setBuildSourceImage set BuildSource Image item for new build
-func (g *BuildGenerator) setBuildSourceImage(ctx context.Context, builderSecrets []corev1.Secret, bcCopy *buildv1.BuildConfig, Source *buildv1.BuildSource) error {
+func (g *BuildGenerator) setBuildSourceImage(ctx context.Context, builderSecrets []corev1.Secret, bcCopy *buildv1.BuildConfig, build *buildv1.Build) error {
 	var err error
 
+	Source := &build.Spec.Source
 	strategyImageChangeTrigger := getStrategyImageChangeTrigger(bcCopy)
 	for i, sourceImage := range Source.Images {
 		if sourceImage.PullSecret == nil {
-			sourceImage.PullSecret = g.resolveImageSecret(ctx, builderSecrets, &sourceImage.From, bcCopy.Namespace)
+			sourceImage.PullSecret = g.resolveImageSecret(ctx, builderSecrets, &sourceImage.From, bcCopy.Namespace, build)
 		}
 
 		var sourceImageSpec string
@@ -664,10 +970,12 @@ func (g *BuildGenerator) setBuildSourceImage(ctx context.Context, builderSecrets
 }
 
 // setBaseImageAndPullSecretForBuildStrategy sets base image and pullSecret items used in buildStrategy for new builds
-func (g *BuildGenerator) setBaseImageAndPullSecretForBuildStrategy(ctx context.Context, builderSecrets []corev1.Secret, bcCopy *buildv1.BuildConfig, strategy *buildv1.BuildStrategy) error {
+func (g *BuildGenerator) setBaseImageAndPullSecretForBuildStrategy(ctx context.Context, builderSecrets []corev1.Secret, bcCopy *buildv1.BuildConfig, build *buildv1.Build) error {
 	var err error
 	var image string
 
+	strategy := &build.Spec.Strategy
+
 	if strategyImageChangeTrigger := getStrategyImageChangeTrigger(bcCopy); strategyImageChangeTrigger != nil {
 		image = strategyImageChangeTrigger.LastTriggeredImageID
 	}
@@ -687,7 +995,7 @@ func (g *BuildGenerator) setBaseImageAndPullSecretForBuildStrategy(ctx context.C
 			Name: image,
 		}
 		if strategy.SourceStrategy.PullSecret == nil {
-			strategy.SourceStrategy.PullSecret = g.resolveImageSecret(ctx, builderSecrets, &strategy.SourceStrategy.From, bcCopy.Namespace)
+			strategy.SourceStrategy.PullSecret = g.resolveImageSecret(ctx, builderSecrets, &strategy.SourceStrategy.From, bcCopy.Namespace, build)
 		}
 	case strategy.DockerStrategy != nil &&
 		strategy.DockerStrategy.From != nil:
@@ -702,7 +1010,7 @@ func (g *BuildGenerator) setBaseImageAndPullSecretForBuildStrategy(ctx context.C
 			Name: image,
 		}
 		if strategy.DockerStrategy.PullSecret == nil {
-			strategy.DockerStrategy.PullSecret = g.resolveImageSecret(ctx, builderSecrets, strategy.DockerStrategy.From, bcCopy.Namespace)
+			strategy.DockerStrategy.PullSecret = g.resolveImageSecret(ctx, builderSecrets, strategy.DockerStrategy.From, bcCopy.Namespace, build)
 		}
 	case strategy.CustomStrategy != nil:
 		if image == "" {
@@ -716,7 +1024,7 @@ func (g *BuildGenerator) setBaseImageAndPullSecretForBuildStrategy(ctx context.C
 			Name: image,
 		}
 		if strategy.CustomStrategy.PullSecret == nil {
-			strategy.CustomStrategy.PullSecret = g.resolveImageSecret(ctx, builderSecrets, &strategy.CustomStrategy.From, bcCopy.Namespace)
+			strategy.CustomStrategy.PullSecret = g.resolveImageSecret(ctx, builderSecrets, &strategy.CustomStrategy.From, bcCopy.Namespace, build)
 		}
 		updateCustomImageEnv(strategy.CustomStrategy, image)
 	}
@@ -749,12 +1057,26 @@ func (g *BuildGenerator) resolveImageStreamReference(ctx context.Context, from c
 			return "", err
 		}
 		reference, ok := dockerImageReferenceForImage(stream, id)
-		if !ok {
-			err = resolveError(from.Kind, namespace, from.Name, fmt.Errorf("unable to find corresponding tag for image %q", id))
-			klog.V(2).Info(err)
-			return "", err
+		if ok {
+			if tag, _ := latestImageTagEvent(stream, id); len(tag) > 0 {
+				if err := scanpolicy.VerifyTag(stream, tag); err != nil {
+					err = resolveError(from.Kind, namespace, from.Name, err)
+					klog.V(2).Info(err)
+					return "", err
+				}
+			}
+		} else {
+			// the digest may identify a platform manifest of a tagged
+			// manifest list rather than a tagged image itself
+			reference, err = g.dockerImageReferenceForSubManifest(ctx, stream, namespace, id)
+			if err != nil {
+				err = resolveError(from.Kind, namespace, from.Name, err)
+				klog.V(2).Info(err)
+				return "", err
+			}
 		}
 		klog.V(4).Infof("Resolved ImageStreamImage %s to image %q", from.Name, reference)
+		recordImageResolution(ctx, from.Kind, namespace, from.Name, reference, stream)
 		return reference, nil
 
 	case "ImageStreamTag":
@@ -770,6 +1092,11 @@ func (g *BuildGenerator) resolveImageStreamReference(ctx context.Context, from c
 			klog.V(2).Info(err)
 			return "", err
 		}
+		if err := scanpolicy.VerifyTag(stream, tag); err != nil {
+			err = resolveError(from.Kind, namespace, from.Name, err)
+			klog.V(2).Info(err)
+			return "", err
+		}
 		reference, ok := imageutil.ResolveLatestTaggedImage(stream, tag)
 		if !ok {
 			err = resolveError(from.Kind, namespace, from.Name, fmt.Errorf("unable to find latest tagged image"))
@@ -777,6 +1104,7 @@ func (g *BuildGenerator) resolveImageStreamReference(ctx context.Context, from c
 			return "", err
 		}
 		klog.V(4).Infof("Resolved ImageStreamTag %s to image %q", from.Name, reference)
+		recordImageResolution(ctx, from.Kind, namespace, from.Name, reference, stream)
 		return reference, nil
 	case "DockerImage":
 		return from.Name, nil
@@ -785,6 +1113,99 @@ func (g *BuildGenerator) resolveImageStreamReference(ctx context.Context, from c
 	}
 }
 
+// imageResolutionAudit is the value of an image resolution audit annotation.
+type imageResolutionAudit struct {
+	Kind           string `json:"kind"`
+	Original       string `json:"original"`
+	Resolved       string `json:"resolved"`
+	ImageStreamUID string `json:"imageStreamUID,omitempty"`
+}
+
+// recordImageResolution annotates the audit event of the current request with
+// the pull spec a [Kind]/namespace/name reference resolved to and the UID of
+// the image stream it was resolved from, so a workload running a given digest
+// can be traced back to the tag it was built from.
+func recordImageResolution(ctx context.Context, kind, namespace, name, resolved string, stream *imagev1.ImageStream) {
+	value := imageResolutionAudit{
+		Kind:     kind,
+		Original: namespace + "/" + name,
+		Resolved: resolved,
+	}
+	if stream != nil {
+		value.ImageStreamUID = string(stream.UID)
+	}
+	encoded, err := json.Marshal(&value)
+	if err != nil {
+		// the struct above always marshals; don't fail a build over auditing
+		klog.V(2).Infof("Unable to encode image resolution audit annotation for %s/%s: %v", namespace, name, err)
+		return
+	}
+	audit.AddAuditAnnotation(ctx, imageResolutionAuditAnnotationPrefix+value.Original, string(encoded))
+}
+
+// dockerImageReferenceForSubManifest searches the tagged images of the stream
+// for a manifest list whose platform manifests include id and derives a
+// by-digest pull spec for the platform manifest from the parent.  Tag events
+// only record the digest of the manifest list, so a reference to a child
+// digest cannot be resolved from the stream status alone.
+func (g *BuildGenerator) dockerImageReferenceForSubManifest(ctx context.Context, stream *imagev1.ImageStream, namespace, id string) (string, error) {
+	seen := map[string]struct{}{}
+	for _, events := range stream.Status.Tags {
+		for _, event := range events.Items {
+			if len(event.Image) == 0 || event.Image == id {
+				continue
+			}
+			if _, ok := seen[event.Image]; ok {
+				continue
+			}
+			seen[event.Image] = struct{}{}
+
+			isi, err := g.Client.GetImageStreamImage(apirequest.WithNamespace(ctx, namespace), imageutil.JoinImageStreamImage(stream.Name, event.Image), metav1.GetOptions{})
+			if err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return "", err
+			}
+			if !subManifestsInclude(isi.Image.Annotations[imagestreammapping.SubManifestsAnnotation], id) {
+				continue
+			}
+
+			if err := scanpolicy.VerifyTag(stream, events.Tag); err != nil {
+				return "", err
+			}
+			// derive the pull spec from the parent so the tag reference
+			// policy is honored, then address the child by its digest
+			parentSpec, ok := dockerImageReferenceForImage(stream, event.Image)
+			if !ok {
+				continue
+			}
+			ref, err := imageutil.ParseDockerImageReference(parentSpec)
+			if err != nil {
+				return "", err
+			}
+			ref.Tag = ""
+			ref.ID = id
+			return dockerImageReferenceExact(ref), nil
+		}
+	}
+	return "", fmt.Errorf("unable to find corresponding tag for image %q", id)
+}
+
+// subManifestsInclude reports whether the comma separated digest list recorded
+// on a manifest list image contains id.
+func subManifestsInclude(digests, id string) bool {
+	if len(digests) == 0 {
+		return false
+	}
+	for _, digest := range strings.Split(digests, ",") {
+		if digest == id {
+			return true
+		}
+	}
+	return false
+}
+
 // resolveImageStreamDockerRepository looks up the ImageStream[Tag/Image] and converts it to a
 // the docker repository reference with no tag information
 func (g *BuildGenerator) resolveImageStreamDockerRepository(ctx context.Context, from corev1.ObjectReference, defaultNamespace string) (string, error) {
@@ -841,8 +1262,9 @@ func dockerImageReferenceForStream(stream *imagev1.ImageStream) (imagev1.DockerI
 }
 
 // resolveImageSecret looks up the Secrets provided by the Service Account and
-// attempt to find a best match for given image.
-func (g *BuildGenerator) resolveImageSecret(ctx context.Context, secrets []corev1.Secret, imageRef *corev1.ObjectReference, buildNamespace string) *corev1.LocalObjectReference {
+// attempt to find a best match for given image. The chosen secret is recorded
+// on the build's ChosenPullSecretAnnotation.
+func (g *BuildGenerator) resolveImageSecret(ctx context.Context, secrets []corev1.Secret, imageRef *corev1.ObjectReference, buildNamespace string, build *buildv1.Build) *corev1.LocalObjectReference {
 	if len(secrets) == 0 || imageRef == nil {
 		return nil
 	}
@@ -855,15 +1277,42 @@ func (g *BuildGenerator) resolveImageSecret(ctx context.Context, secrets []corev
 	s := findDockerSecretAsReference(secrets, imageSpec)
 	if s == nil {
 		klog.V(4).Infof("No secrets found for pushing or pulling the %s  %s/%s", imageRef.Kind, buildNamespace, imageRef.Name)
+		return nil
 	}
+	recordChosenPullSecret(build, s.Name)
 	return s
 }
 
+// recordChosenPullSecret appends the named secret to the build's
+// ChosenPullSecretAnnotation unless it is already listed.
+func recordChosenPullSecret(build *buildv1.Build, name string) {
+	if build == nil {
+		return
+	}
+	if build.Annotations == nil {
+		build.Annotations = map[string]string{}
+	}
+	existing := build.Annotations[ChosenPullSecretAnnotation]
+	if len(existing) == 0 {
+		build.Annotations[ChosenPullSecretAnnotation] = name
+		return
+	}
+	for _, chosen := range strings.Split(existing, ",") {
+		if chosen == name {
+			return
+		}
+	}
+	build.Annotations[ChosenPullSecretAnnotation] = existing + "," + name
+}
+
 // findDockerSecretAsInternalReference looks through a set of k8s Secrets to find one that represents Docker credentials
 // and which contains credentials that are associated with the registry identified by the image.  It returns
-// a LocalObjectReference to the Secret, or nil if no match was found.
+// a LocalObjectReference to the Secret, or nil if no match was found.  When
+// several secrets match, secrets carrying the PreferredPullSecretAnnotation
+// win, then the most recently created, so the choice is deterministic.
 func findDockerSecretAsReference(secrets []corev1.Secret, image string) *corev1.LocalObjectReference {
 	emptyKeyring := credentialprovider.BasicDockerKeyring{}
+	var matches []corev1.Secret
 	for _, secret := range secrets {
 		secretList := []corev1.Secret{*secret.DeepCopy()}
 		keyring, err := credentialprovidersecrets.MakeDockerKeyring(secretList, &emptyKeyring)
@@ -872,10 +1321,34 @@ func findDockerSecretAsReference(secrets []corev1.Secret, image string) *corev1.
 			continue
 		}
 		if _, found := keyring.Lookup(image); found {
-			return &corev1.LocalObjectReference{Name: secret.Name}
+			matches = append(matches, secret)
 		}
 	}
-	return nil
+	if len(matches) == 0 {
+		return nil
+	}
+	chosen := matches[0]
+	for _, candidate := range matches[1:] {
+		if secretPreferredOver(candidate, chosen) {
+			chosen = candidate
+		}
+	}
+	return &corev1.LocalObjectReference{Name: chosen.Name}
+}
+
+// secretPreferredOver reports whether candidate should be picked instead of
+// current: an annotated secret beats an unannotated one, a newer secret beats
+// an older one, and names break the remaining ties.
+func secretPreferredOver(candidate, current corev1.Secret) bool {
+	candidatePreferred := candidate.Annotations[PreferredPullSecretAnnotation] == "true"
+	currentPreferred := current.Annotations[PreferredPullSecretAnnotation] == "true"
+	if candidatePreferred != currentPreferred {
+		return candidatePreferred
+	}
+	if !candidate.CreationTimestamp.Equal(&current.CreationTimestamp) {
+		return current.CreationTimestamp.Before(&candidate.CreationTimestamp)
+	}
+	return candidate.Name < current.Name
 }
 
 func resolveError(kind string, namespace string, name string, err error) error {
@@ -957,6 +1430,7 @@ func generateBuildFromBuild(build *buildv1.Build, buildConfig *buildv1.BuildConf
 		newBuild.Annotations = make(map[string]string)
 	}
 	newBuild.Annotations[buildv1.BuildCloneAnnotation] = build.Name
+	appendBuildCauseChain(newBuild, build)
 	if buildConfig != nil {
 		newBuild.Annotations[buildv1.BuildNumberAnnotation] = strconv.FormatInt(buildConfig.Status.LastVersion, 10)
 	} else {
@@ -977,6 +1451,63 @@ func generateBuildFromBuild(build *buildv1.Build, buildConfig *buildv1.BuildConf
 	return newBuild
 }
 
+// buildCauseChainEntry is one ancestor in the cause chain annotation.
+type buildCauseChainEntry struct {
+	Name     string `json:"name"`
+	Triggers string `json:"triggers,omitempty"`
+}
+
+// appendBuildCauseChain extends the cause chain inherited from the cloned
+// build with the build being cloned, dropping the oldest entries once the
+// chain exceeds maxBuildCauseChainLength.  A chain that fails to parse is
+// restarted from the immediate parent rather than failing the clone.
+func appendBuildCauseChain(newBuild, build *buildv1.Build) {
+	var chain []buildCauseChainEntry
+	if existing, ok := newBuild.Annotations[BuildCauseChainAnnotation]; ok {
+		if err := json.Unmarshal([]byte(existing), &chain); err != nil {
+			klog.V(2).Infof("Discarding unparseable build cause chain on %s/%s: %v", build.Namespace, build.Name, err)
+			chain = nil
+		}
+	}
+	chain = append(chain, buildCauseChainEntry{
+		Name:     build.Name,
+		Triggers: buildTriggerTypes(build),
+	})
+	if len(chain) > maxBuildCauseChainLength {
+		chain = chain[len(chain)-maxBuildCauseChainLength:]
+	}
+	encoded, err := json.Marshal(chain)
+	if err != nil {
+		// entries only contain strings; this cannot happen
+		return
+	}
+	newBuild.Annotations[BuildCauseChainAnnotation] = string(encoded)
+}
+
+// buildTriggerTypes summarizes what started a build as a comma-separated list
+// of trigger types, falling back to the cause message for triggers without a
+// typed cause.
+func buildTriggerTypes(build *buildv1.Build) string {
+	var types []string
+	for _, cause := range build.Spec.TriggeredBy {
+		switch {
+		case cause.GenericWebHook != nil:
+			types = append(types, "GenericWebHook")
+		case cause.GitHubWebHook != nil:
+			types = append(types, "GitHubWebHook")
+		case cause.GitLabWebHook != nil:
+			types = append(types, "GitLabWebHook")
+		case cause.BitbucketWebHook != nil:
+			types = append(types, "BitbucketWebHook")
+		case cause.ImageChangeBuild != nil:
+			types = append(types, "ImageChange")
+		case len(cause.Message) > 0:
+			types = append(types, cause.Message)
+		}
+	}
+	return strings.Join(types, ",")
+}
+
 // getNextBuildNameFromBuild returns name of the next build with random uuid added at the end
 func getNextBuildNameFromBuild(build *buildv1.Build, buildConfig *buildv1.BuildConfig) string {
 	var buildName string
@@ -1095,6 +1626,64 @@ func mergeMaps(a, b map[string]string) map[string]string {
 	return res
 }
 
+// TriggerCausesAnnotation holds a JSON summary of the deduplicated trigger
+// causes of a build: one entry per distinct cause with the number of times it
+// was reported and when it was first and last observed. The annotation is
+// only written when duplicates were collapsed.
+const TriggerCausesAnnotation = "build.openshift.io/trigger-causes"
+
+// triggerCauseSummary is one entry of the TriggerCausesAnnotation value.
+type triggerCauseSummary struct {
+	Message       string      `json:"message,omitempty"`
+	Count         int32       `json:"count"`
+	FirstObserved metav1.Time `json:"firstObserved"`
+	LastObserved  metav1.Time `json:"lastObserved"`
+}
+
+// setBuildTriggerCauses copies the trigger causes of a build request onto the
+// build. Webhook retries and multiple matching triggers can report the same
+// cause several times in one request; identical causes are collapsed to a
+// single spec.triggeredBy entry and the counts are kept in an annotation so
+// the audit trail survives without growing the build object.
+func setBuildTriggerCauses(build *buildv1.Build, causes []buildv1.BuildTriggerCause) {
+	now := metav1.Now()
+	var deduped []buildv1.BuildTriggerCause
+	var summaries []triggerCauseSummary
+	for _, cause := range causes {
+		duplicate := false
+		for i := range deduped {
+			if reflect.DeepEqual(deduped[i], cause) {
+				summaries[i].Count++
+				summaries[i].LastObserved = now
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			deduped = append(deduped, cause)
+			summaries = append(summaries, triggerCauseSummary{
+				Message:       cause.Message,
+				Count:         1,
+				FirstObserved: now,
+				LastObserved:  now,
+			})
+		}
+	}
+	build.Spec.TriggeredBy = deduped
+	if len(deduped) == len(causes) {
+		return
+	}
+	data, err := json.Marshal(summaries)
+	if err != nil {
+		// the summaries are plain structs, this cannot happen
+		return
+	}
+	if build.Annotations == nil {
+		build.Annotations = map[string]string{}
+	}
+	build.Annotations[TriggerCausesAnnotation] = string(data)
+}
+
 // isPaused returns true if the provided BuildConfig is paused and cannot be used to create a new Build
 func isPaused(bc *buildv1.BuildConfig) bool {
 	return strings.ToLower(bc.Annotations[buildv1.BuildConfigPausedAnnotation]) == "true"
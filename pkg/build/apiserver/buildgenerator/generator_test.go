@@ -2,15 +2,18 @@ package buildgenerator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/apitesting"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -19,6 +22,7 @@ import (
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/client-go/kubernetes/fake"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	clientgotesting "k8s.io/client-go/testing"
 
 	buildv1 "github.com/openshift/api/build/v1"
 	imagev1 "github.com/openshift/api/image/v1"
@@ -28,6 +32,8 @@ import (
 	buildconversionsv1 "github.com/openshift/openshift-apiserver/pkg/build/apis/build/v1"
 	"github.com/openshift/openshift-apiserver/pkg/build/apis/build/validation"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/apiserverbuildutil"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestreammapping"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/scanpolicy"
 )
 
 var (
@@ -62,6 +68,27 @@ func TestInstantiate(t *testing.T) {
 	}
 }
 
+func TestInstantiateCancelledRequest(t *testing.T) {
+	updateCalls := 0
+	generator := mockBuildGenerator(nil,
+		func(ctx context.Context, buildConfig *buildv1.BuildConfig, _ metav1.UpdateOptions) error {
+			updateCalls++
+			return errors.NewConflict(buildv1.Resource("buildconfigs"), buildConfig.Name, fmt.Errorf("concurrent update"))
+		},
+		nil, nil, nil, nil, nil)
+
+	ctx, cancel := context.WithCancel(apirequest.NewDefaultContext())
+	cancel()
+
+	_, err := generator.Instantiate(ctx, &buildv1.BuildRequest{}, metav1.CreateOptions{})
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if updateCalls != 1 {
+		t.Errorf("Expected no conflict retries after cancellation, got %d attempts", updateCalls)
+	}
+}
+
 func TestInstantiateBinary(t *testing.T) {
 	generator := mockBuildGenerator(nil, nil, nil, nil, nil, nil, nil)
 	build, err := generator.Instantiate(apirequest.NewDefaultContext(), &buildv1.BuildRequest{Binary: &buildv1.BinaryBuildSource{}}, metav1.CreateOptions{})
@@ -706,6 +733,101 @@ func TestInstantiateWithLabelsAndAnnotations(t *testing.T) {
 	}
 }
 
+func TestInstantiateWithPlacementOverrides(t *testing.T) {
+	overrides := func(value string) map[string]string {
+		return map[string]string{buildconversionsv1.PlacementOverridesAnnotation: value}
+	}
+	tests := []struct {
+		name          string
+		bcAnnotations map[string]string
+		reqAnnotation map[string]string
+		expectError   string
+		validate      func(t *testing.T, build *buildv1.Build)
+	}{
+		{
+			name: "allowed node selector and toleration",
+			bcAnnotations: map[string]string{
+				AllowedNodeSelectorOverridesAnnotation: "node-role/gpu",
+				AllowedTolerationOverridesAnnotation:   "gpu",
+			},
+			reqAnnotation: overrides(`{"nodeSelector":{"node-role/gpu":"true"},"tolerations":[{"key":"gpu","operator":"Exists","effect":"NoSchedule"}]}`),
+			validate: func(t *testing.T, build *buildv1.Build) {
+				if build.Spec.NodeSelector["node-role/gpu"] != "true" {
+					t.Errorf("node selector was not merged: %v", build.Spec.NodeSelector)
+				}
+				var tolerations []corev1.Toleration
+				if err := json.Unmarshal([]byte(build.Annotations[buildconversionsv1.TolerationsAnnotation]), &tolerations); err != nil {
+					t.Fatalf("invalid tolerations annotation %q: %v", build.Annotations[buildconversionsv1.TolerationsAnnotation], err)
+				}
+				if len(tolerations) != 1 || tolerations[0].Key != "gpu" {
+					t.Errorf("tolerations were not merged: %#v", tolerations)
+				}
+				if _, ok := build.Annotations[buildconversionsv1.PlacementOverridesAnnotation]; ok {
+					t.Errorf("expected the override annotation to be dropped from the build, got %v", build.Annotations)
+				}
+			},
+		},
+		{
+			name: "wildcard allows any key",
+			bcAnnotations: map[string]string{
+				AllowedNodeSelectorOverridesAnnotation: "*",
+			},
+			reqAnnotation: overrides(`{"nodeSelector":{"kubernetes.io/arch":"arm64"}}`),
+			validate: func(t *testing.T, build *buildv1.Build) {
+				if build.Spec.NodeSelector["kubernetes.io/arch"] != "arm64" {
+					t.Errorf("node selector was not merged: %v", build.Spec.NodeSelector)
+				}
+			},
+		},
+		{
+			name:          "node selector key not allowed",
+			reqAnnotation: overrides(`{"nodeSelector":{"node-role/gpu":"true"}}`),
+			expectError:   "does not allow overriding node selector key",
+		},
+		{
+			name: "toleration key not allowed",
+			bcAnnotations: map[string]string{
+				AllowedTolerationOverridesAnnotation: "large-memory",
+			},
+			reqAnnotation: overrides(`{"tolerations":[{"key":"gpu","operator":"Exists"}]}`),
+			expectError:   "does not allow overriding toleration key",
+		},
+		{
+			name:          "malformed annotation",
+			reqAnnotation: overrides(`{`),
+			expectError:   "invalid " + buildconversionsv1.PlacementOverridesAnnotation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := mockBuildGenerator(nil, nil, nil, nil, nil, nil, nil)
+			c := g.Client.(TestingClient)
+			c.GetBuildConfigFunc = func(ctx context.Context, name string, options metav1.GetOptions) (*buildv1.BuildConfig, error) {
+				bc := MockBuildConfig(MockSource(), MockSourceStrategyForImageRepository(), MockOutput())
+				bc.Annotations = test.bcAnnotations
+				return bc, nil
+			}
+			g.Client = c
+
+			req := &buildv1.BuildRequest{
+				ObjectMeta: metav1.ObjectMeta{Annotations: test.reqAnnotation},
+			}
+			build, err := g.Instantiate(apirequest.NewDefaultContext(), req, metav1.CreateOptions{})
+			if len(test.expectError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectError) {
+					t.Fatalf("expected error containing %q, got %v", test.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			test.validate(t, build)
+		})
+	}
+}
+
 func TestFindImageTrigger(t *testing.T) {
 	defaultTrigger := &buildv1.ImageChangeTrigger{}
 	defaultTriggerResp := buildv1.ImageChangeTriggerStatus{
@@ -931,6 +1053,80 @@ func TestClone(t *testing.T) {
 	}
 }
 
+func TestCloneRevisionOverride(t *testing.T) {
+	source := MockSource()
+	revision := &buildv1.SourceRevision{Git: &buildv1.GitSourceRevision{Commit: "originalcommit"}}
+
+	tests := []struct {
+		name          string
+		source        buildv1.BuildSource
+		request       *buildv1.BuildRequest
+		expectedRef   string
+		expectedError string
+	}{
+		{
+			name:        "commit override unpins the revision and moves the ref",
+			source:      source,
+			request:     &buildv1.BuildRequest{Revision: &buildv1.SourceRevision{Git: &buildv1.GitSourceRevision{Commit: "deadbeef"}}},
+			expectedRef: "deadbeef",
+		},
+		{
+			name:        "empty git revision unpins without changing the ref",
+			source:      source,
+			request:     &buildv1.BuildRequest{Revision: &buildv1.SourceRevision{Git: &buildv1.GitSourceRevision{}}},
+			expectedRef: source.Git.Ref,
+		},
+		{
+			name:          "revision override on a non-git build is rejected",
+			source:        buildv1.BuildSource{Binary: &buildv1.BinaryBuildSource{}},
+			request:       &buildv1.BuildRequest{Revision: &buildv1.SourceRevision{Git: &buildv1.GitSourceRevision{Commit: "deadbeef"}}},
+			expectedError: "not a Git based build",
+		},
+	}
+
+	for _, test := range tests {
+		var created *buildv1.Build
+		generator := BuildGenerator{Client: TestingClient{
+			CreateBuildFunc: func(ctx context.Context, build *buildv1.Build, _ metav1.CreateOptions) error {
+				created = build
+				return nil
+			},
+			GetBuildFunc: func(ctx context.Context, name string, options metav1.GetOptions) (*buildv1.Build, error) {
+				return &buildv1.Build{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-build-1",
+						Namespace: metav1.NamespaceDefault,
+					},
+					Spec: buildv1.BuildSpec{
+						CommonSpec: buildv1.CommonSpec{
+							Source:   test.source,
+							Revision: revision.DeepCopy(),
+						},
+					},
+				}, nil
+			},
+		}}
+
+		_, err := generator.Clone(apirequest.NewDefaultContext(), test.request)
+		if len(test.expectedError) > 0 {
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("%s: expected error containing %q, got %v", test.name, test.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if created.Spec.Revision != nil {
+			t.Errorf("%s: expected revision to be cleared, got %#v", test.name, created.Spec.Revision)
+		}
+		if created.Spec.Source.Git.Ref != test.expectedRef {
+			t.Errorf("%s: expected ref %q, got %q", test.name, test.expectedRef, created.Spec.Source.Git.Ref)
+		}
+	}
+}
+
 func TestCloneError(t *testing.T) {
 	generator := BuildGenerator{Client: TestingClient{
 		GetBuildFunc: func(ctx context.Context, name string, options metav1.GetOptions) (*buildv1.Build, error) {
@@ -1392,6 +1588,84 @@ func TestGenerateBuildFromBuild(t *testing.T) {
 
 }
 
+func TestGenerateBuildFromBuildCauseChain(t *testing.T) {
+	source := MockSource()
+	strategy := mockDockerStrategyForImageRepository()
+	output := MockOutput()
+	build := &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-build-2",
+			Annotations: map[string]string{
+				buildv1.BuildCloneAnnotation: "test-build-1",
+				BuildCauseChainAnnotation:    `[{"name":"test-build-1","triggers":"GitHubWebHook"}]`,
+			},
+		},
+		Spec: buildv1.BuildSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Source:   source,
+				Strategy: strategy,
+				Output:   output,
+			},
+			TriggeredBy: []buildv1.BuildTriggerCause{
+				{Message: "Manually triggered"},
+			},
+		},
+	}
+
+	newBuild := generateBuildFromBuild(build, nil)
+	var chain []buildCauseChainEntry
+	if err := json.Unmarshal([]byte(newBuild.Annotations[BuildCauseChainAnnotation]), &chain); err != nil {
+		t.Fatalf("unable to decode cause chain %q: %v", newBuild.Annotations[BuildCauseChainAnnotation], err)
+	}
+	expected := []buildCauseChainEntry{
+		{Name: "test-build-1", Triggers: "GitHubWebHook"},
+		{Name: "test-build-2", Triggers: "Manually triggered"},
+	}
+	if !reflect.DeepEqual(expected, chain) {
+		t.Errorf("expected cause chain %v, got %v", expected, chain)
+	}
+
+	// an unparseable inherited chain must not fail the clone; it restarts
+	// from the immediate parent
+	build.Annotations[BuildCauseChainAnnotation] = "not json"
+	newBuild = generateBuildFromBuild(build, nil)
+	if err := json.Unmarshal([]byte(newBuild.Annotations[BuildCauseChainAnnotation]), &chain); err != nil {
+		t.Fatalf("unable to decode cause chain %q: %v", newBuild.Annotations[BuildCauseChainAnnotation], err)
+	}
+	if len(chain) != 1 || chain[0].Name != "test-build-2" {
+		t.Errorf("expected a restarted chain, got %v", chain)
+	}
+}
+
+func TestGenerateBuildFromBuildCauseChainBounded(t *testing.T) {
+	entries := make([]buildCauseChainEntry, maxBuildCauseChainLength)
+	for i := range entries {
+		entries[i] = buildCauseChainEntry{Name: fmt.Sprintf("build-%d", i)}
+	}
+	encoded, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "build-full",
+			Annotations: map[string]string{BuildCauseChainAnnotation: string(encoded)},
+		},
+	}
+
+	newBuild := generateBuildFromBuild(build, nil)
+	var chain []buildCauseChainEntry
+	if err := json.Unmarshal([]byte(newBuild.Annotations[BuildCauseChainAnnotation]), &chain); err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != maxBuildCauseChainLength {
+		t.Fatalf("expected the chain to stay at %d entries, got %d", maxBuildCauseChainLength, len(chain))
+	}
+	if chain[0].Name != "build-1" || chain[len(chain)-1].Name != "build-full" {
+		t.Errorf("expected the oldest entry to be dropped, got %v", chain)
+	}
+}
+
 func TestGenerateBuildFromBuildWithBuildConfig(t *testing.T) {
 	source := MockSource()
 	strategy := mockDockerStrategyForImageRepository()
@@ -1737,6 +2011,69 @@ func TestResolveImageStreamRef(t *testing.T) {
 	}
 }
 
+func TestResolveImageStreamImageSubManifest(t *testing.T) {
+	const (
+		parentDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000001"
+		childDigest  = "sha256:0000000000000000000000000000000000000000000000000000000000000002"
+	)
+	stream := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "multiarch", Namespace: "default"},
+		Status: imagev1.ImageStreamStatus{
+			DockerImageRepository: "registry.test/default/multiarch",
+			Tags: []imagev1.NamedTagEventList{
+				{
+					Tag: "latest",
+					Items: []imagev1.TagEvent{
+						{
+							Image:                parentDigest,
+							DockerImageReference: "registry.test/default/multiarch@" + parentDigest,
+						},
+					},
+				},
+			},
+		},
+	}
+	generator := BuildGenerator{Client: TestingClient{
+		GetImageStreamFunc: func(ctx context.Context, name string, options metav1.GetOptions) (*imagev1.ImageStream, error) {
+			return stream, nil
+		},
+		GetImageStreamImageFunc: func(ctx context.Context, name string, options metav1.GetOptions) (*imagev1.ImageStreamImage, error) {
+			if name != "multiarch@"+parentDigest {
+				return nil, errors.NewNotFound(imagev1.Resource("imagestreamimages"), name)
+			}
+			return &imagev1.ImageStreamImage{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Image: imagev1.Image{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        parentDigest,
+						Annotations: map[string]string{imagestreammapping.SubManifestsAnnotation: childDigest},
+					},
+				},
+			}, nil
+		},
+	}}
+
+	// the child digest is not tagged itself, only recorded as a sub-manifest
+	// of the tagged manifest list
+	ref, err := generator.resolveImageStreamReference(apirequest.NewDefaultContext(), corev1.ObjectReference{
+		Kind: "ImageStreamImage",
+		Name: "multiarch@" + childDigest,
+	}, "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "registry.test/default/multiarch@" + childDigest; ref != expected {
+		t.Errorf("resolved reference %q, expected %q", ref, expected)
+	}
+
+	if _, err := generator.resolveImageStreamReference(apirequest.NewDefaultContext(), corev1.ObjectReference{
+		Kind: "ImageStreamImage",
+		Name: "multiarch@sha256:0000000000000000000000000000000000000000000000000000000000000003",
+	}, "default"); err == nil {
+		t.Errorf("expected an error for a digest that is not a recorded sub-manifest")
+	}
+}
+
 func mockResources() corev1.ResourceRequirements {
 	res := corev1.ResourceRequirements{}
 	res.Limits = corev1.ResourceList{}
@@ -1967,6 +2304,86 @@ func TestGenerateBuildFromConfigWithSecrets(t *testing.T) {
 	}
 }
 
+func TestFindDockerSecretAsReferenceDeterministic(t *testing.T) {
+	hubSecret := func(name string, created time.Time, preferred bool) corev1.Secret {
+		secret := corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         metav1.NamespaceDefault,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Type: corev1.SecretTypeDockercfg,
+			Data: map[string][]byte{".dockercfg": SampleDockerConfigs["hub"]},
+		}
+		if preferred {
+			secret.Annotations = map[string]string{PreferredPullSecretAnnotation: "true"}
+		}
+		return secret
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		secrets  []corev1.Secret
+		expected string
+	}{
+		{
+			name: "annotated secret wins over newer unannotated one",
+			secrets: []corev1.Secret{
+				hubSecret("newer", now, false),
+				hubSecret("preferred", now.Add(-time.Hour), true),
+			},
+			expected: "preferred",
+		},
+		{
+			name: "newest secret wins without annotations",
+			secrets: []corev1.Secret{
+				hubSecret("older", now.Add(-time.Hour), false),
+				hubSecret("newer", now, false),
+			},
+			expected: "newer",
+		},
+		{
+			name: "name breaks creation time ties",
+			secrets: []corev1.Secret{
+				hubSecret("b-secret", now, false),
+				hubSecret("a-secret", now, false),
+			},
+			expected: "a-secret",
+		},
+	}
+
+	for _, tc := range tests {
+		ref := findDockerSecretAsReference(tc.secrets, "secret1/image")
+		if ref == nil {
+			t.Errorf("%s: expected a secret reference, got nil", tc.name)
+			continue
+		}
+		if ref.Name != tc.expected {
+			t.Errorf("%s: expected secret %q to be chosen, got %q", tc.name, tc.expected, ref.Name)
+		}
+	}
+}
+
+func TestGenerateBuildFromConfigRecordsChosenPullSecret(t *testing.T) {
+	source := MockSource()
+	strategy := mockDockerStrategyForDockerImage("registry.host/secret4/image", metav1.GetOptions{})
+	output := mockOutputWithImageName("registry.host/secret4/image", metav1.GetOptions{})
+	generator := mockBuildGenerator(nil, nil, nil, nil, nil, nil, nil)
+	bc := MockBuildConfig(source, strategy, output)
+
+	build, err := generator.generateBuildFromConfig(apirequest.NewContext(), bc, nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if build.Spec.Strategy.DockerStrategy.PullSecret == nil {
+		t.Fatal("expected a pull secret to be resolved")
+	}
+	if expected, actual := build.Spec.Strategy.DockerStrategy.PullSecret.Name, build.Annotations[ChosenPullSecretAnnotation]; expected != actual {
+		t.Errorf("expected annotation to record secret %q, got %q", expected, actual)
+	}
+}
+
 func TestInstantiateBuildTriggerCauseConfigChange(t *testing.T) {
 	changeMessage := "Build configuration change"
 
@@ -2522,3 +2939,322 @@ func MockImageStream(repoName, dockerImageRepo string, tags map[string]string) *
 		},
 	}
 }
+
+func TestResolveImageStreamRefScanGated(t *testing.T) {
+	stream := MockImageStream(imageRepoName, originalImage, map[string]string{tagName: "newImageID123"})
+	stream.Annotations = map[string]string{scanpolicy.RequireScanVerificationAnnotation: "true"}
+	generator := BuildGenerator{
+		Client: TestingClient{
+			GetImageStreamFunc: func(ctx context.Context, name string, options metav1.GetOptions) (*imagev1.ImageStream, error) {
+				return stream, nil
+			},
+		},
+	}
+	streamRef := corev1.ObjectReference{Kind: "ImageStreamTag", Name: imageRepoName + ":" + tagName}
+
+	if _, err := generator.resolveImageStreamReference(apirequest.NewDefaultContext(), streamRef, ""); err == nil {
+		t.Error("expected resolution of an unverified tag to fail")
+	} else if !strings.Contains(err.Error(), scanpolicy.ScanVerifiedConditionType) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	stream.Status.Tags[0].Conditions = []imagev1.TagEventCondition{
+		{Type: scanpolicy.ScanVerifiedConditionType, Status: corev1.ConditionTrue},
+	}
+	if _, err := generator.resolveImageStreamReference(apirequest.NewDefaultContext(), streamRef, ""); err != nil {
+		t.Errorf("unexpected error after verification: %v", err)
+	}
+}
+
+func TestFetchServiceAccountSecretsMissingServiceAccount(t *testing.T) {
+	generator := BuildGenerator{
+		ServiceAccounts: fake.NewSimpleClientset().CoreV1(),
+		Secrets:         fake.NewSimpleClientset().CoreV1(),
+	}
+
+	_, err := generator.fetchServiceAccountSecrets(context.TODO(), metav1.NamespaceDefault, bootstrappolicy.BuilderServiceAccountName)
+	if err == nil {
+		t.Fatal("expected an error for a missing service account")
+	}
+	if !IsBuilderServiceAccountMissing(err) {
+		t.Errorf("expected a %s error, got: %v", BuilderServiceAccountMissingReason, err)
+	}
+	statusErr, ok := err.(*errors.StatusError)
+	if !ok {
+		t.Fatalf("expected a status error, got %T", err)
+	}
+	if statusErr.ErrStatus.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected code %d, got %d", http.StatusServiceUnavailable, statusErr.ErrStatus.Code)
+	}
+	if statusErr.ErrStatus.Details == nil || statusErr.ErrStatus.Details.RetryAfterSeconds == 0 {
+		t.Errorf("expected retry-after details, got %#v", statusErr.ErrStatus.Details)
+	}
+}
+
+func TestFetchServiceAccountSecretsWaitsForServiceAccount(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bootstrappolicy.BuilderServiceAccountName,
+			Namespace: metav1.NamespaceDefault,
+		},
+	})
+	// make the first lookups fail as if the service account controller has not
+	// caught up with the new namespace yet
+	remainingFailures := 2
+	kubeClient.PrependReactor("get", "serviceaccounts", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		if remainingFailures > 0 {
+			remainingFailures--
+			return true, nil, errors.NewNotFound(corev1.Resource("serviceaccounts"), bootstrappolicy.BuilderServiceAccountName)
+		}
+		return false, nil, nil
+	})
+
+	generator := BuildGenerator{
+		ServiceAccounts:           kubeClient.CoreV1(),
+		Secrets:                   kubeClient.CoreV1(),
+		ServiceAccountWaitTimeout: 30 * time.Second,
+	}
+
+	if _, err := generator.fetchServiceAccountSecrets(context.TODO(), metav1.NamespaceDefault, bootstrappolicy.BuilderServiceAccountName); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if remainingFailures != 0 {
+		t.Errorf("expected the generator to retry past the transient lookups, %d failures left", remainingFailures)
+	}
+}
+
+func TestSetBuildTriggerCauses(t *testing.T) {
+	webhookCause := buildv1.BuildTriggerCause{
+		Message: "GitHub WebHook",
+		GitHubWebHook: &buildv1.GitHubWebHookCause{
+			Secret: "<secret>",
+		},
+	}
+	imageCause := buildv1.BuildTriggerCause{
+		Message: "Image change",
+		ImageChangeBuild: &buildv1.ImageChangeCause{
+			ImageID: "registry/repo/image@sha256:1234",
+		},
+	}
+
+	build := &buildv1.Build{}
+	setBuildTriggerCauses(build, []buildv1.BuildTriggerCause{webhookCause, webhookCause, imageCause, webhookCause})
+	if expected := []buildv1.BuildTriggerCause{webhookCause, imageCause}; !reflect.DeepEqual(build.Spec.TriggeredBy, expected) {
+		t.Errorf("unexpected triggeredBy: %#v", build.Spec.TriggeredBy)
+	}
+	var summaries []struct {
+		Message string `json:"message"`
+		Count   int32  `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(build.Annotations[TriggerCausesAnnotation]), &summaries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].Message != "GitHub WebHook" || summaries[0].Count != 3 || summaries[1].Count != 1 {
+		t.Errorf("unexpected summaries: %#v", summaries)
+	}
+
+	// without duplicates the build is unchanged
+	build = &buildv1.Build{}
+	setBuildTriggerCauses(build, []buildv1.BuildTriggerCause{webhookCause, imageCause})
+	if !reflect.DeepEqual(build.Spec.TriggeredBy, []buildv1.BuildTriggerCause{webhookCause, imageCause}) {
+		t.Errorf("unexpected triggeredBy: %#v", build.Spec.TriggeredBy)
+	}
+	if _, ok := build.Annotations[TriggerCausesAnnotation]; ok {
+		t.Errorf("expected no summary annotation, got %q", build.Annotations[TriggerCausesAnnotation])
+	}
+}
+
+type fakePushCredentialMinter struct {
+	namespace      string
+	serviceAccount string
+	buildName      string
+	repository     string
+	minted         bool
+	ref            *corev1.LocalObjectReference
+}
+
+func (m *fakePushCredentialMinter) MintPushCredential(ctx context.Context, namespace, serviceAccount, buildName, repository string) (*corev1.LocalObjectReference, error) {
+	m.namespace = namespace
+	m.serviceAccount = serviceAccount
+	m.buildName = buildName
+	m.repository = repository
+	m.minted = true
+	return m.ref, nil
+}
+
+func TestGenerateBuildWithScopedPushSecret(t *testing.T) {
+	strategy := mockDockerStrategyForDockerImage(originalImage, metav1.GetOptions{})
+	output := buildv1.BuildOutput{
+		To: &corev1.ObjectReference{
+			Kind: "ImageStreamTag",
+			Name: imageRepoName + ":latest",
+		},
+	}
+	bc := MockBuildConfig(MockSource(), strategy, output)
+	generator := mockBuildGenerator(nil, nil, nil, nil, nil, nil, nil)
+	minter := &fakePushCredentialMinter{ref: &corev1.LocalObjectReference{Name: "test-build-config-1-push-abcde"}}
+	generator.PushCredentials = minter
+
+	build, err := generator.generateBuildFromConfig(apirequest.NewDefaultContext(), bc, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if build.Spec.Output.PushSecret == nil || build.Spec.Output.PushSecret.Name != minter.ref.Name {
+		t.Errorf("expected push secret %q, got %#v", minter.ref.Name, build.Spec.Output.PushSecret)
+	}
+	// the repository comes from the status of the output image stream
+	if minter.repository != "repo/namespace/image" {
+		t.Errorf("unexpected repository: %q", minter.repository)
+	}
+	if minter.namespace != metav1.NamespaceDefault || minter.serviceAccount != bootstrappolicy.BuilderServiceAccountName {
+		t.Errorf("unexpected scope: %q/%q", minter.namespace, minter.serviceAccount)
+	}
+	if minter.buildName != build.Name {
+		t.Errorf("expected credential for build %q, got %q", build.Name, minter.buildName)
+	}
+}
+
+func TestGenerateBuildScopedPushSecretSkipped(t *testing.T) {
+	explicitSecret := buildv1.BuildOutput{
+		To: &corev1.ObjectReference{
+			Kind: "ImageStreamTag",
+			Name: imageRepoName + ":latest",
+		},
+		PushSecret: &corev1.LocalObjectReference{Name: "my-push-secret"},
+	}
+	dockerImageOutput := buildv1.BuildOutput{
+		To: &corev1.ObjectReference{
+			Kind: "DockerImage",
+			Name: "localhost:5000/test/image-tag",
+		},
+	}
+	for name, output := range map[string]buildv1.BuildOutput{
+		"explicit push secret": explicitSecret,
+		"docker image output":  dockerImageOutput,
+	} {
+		strategy := mockDockerStrategyForDockerImage(originalImage, metav1.GetOptions{})
+		bc := MockBuildConfig(MockSource(), strategy, output)
+		generator := mockBuildGenerator(nil, nil, nil, nil, nil, nil, nil)
+		minter := &fakePushCredentialMinter{ref: &corev1.LocalObjectReference{Name: "minted"}}
+		generator.PushCredentials = minter
+
+		build, err := generator.generateBuildFromConfig(apirequest.NewDefaultContext(), bc, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if minter.minted {
+			t.Errorf("%s: expected no credential to be minted", name)
+		}
+		if !reflect.DeepEqual(build.Spec.Output.PushSecret, output.PushSecret) {
+			t.Errorf("%s: unexpected push secret: %#v", name, build.Spec.Output.PushSecret)
+		}
+	}
+}
+
+func TestServiceAccountPushCredentialMinter(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bootstrappolicy.BuilderServiceAccountName,
+			Namespace: metav1.NamespaceDefault,
+		},
+	})
+	expiry := metav1.NewTime(time.Now().Add(time.Hour).Truncate(time.Second))
+	var requestedExpiration int64
+	kubeClient.PrependReactor("create", "serviceaccounts", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		tokenRequest := action.(clientgotesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+		if tokenRequest.Spec.ExpirationSeconds != nil {
+			requestedExpiration = *tokenRequest.Spec.ExpirationSeconds
+		}
+		return true, &authenticationv1.TokenRequest{
+			Status: authenticationv1.TokenRequestStatus{
+				Token:               "short-lived-token",
+				ExpirationTimestamp: expiry,
+			},
+		}, nil
+	})
+	// the fake object tracker does not fill in generateName
+	kubeClient.PrependReactor("create", "secrets", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		secret := action.(clientgotesting.CreateAction).GetObject().(*corev1.Secret)
+		if len(secret.Name) == 0 {
+			secret.Name = secret.GenerateName + "vwxyz"
+		}
+		return false, nil, nil
+	})
+
+	minter := NewServiceAccountPushCredentialMinter(kubeClient.CoreV1(), kubeClient.CoreV1(), time.Hour)
+	ref, err := minter.MintPushCredential(context.TODO(), metav1.NamespaceDefault, bootstrappolicy.BuilderServiceAccountName, "mybuild-1", "registry.test:5000/default/out")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requestedExpiration != int64(time.Hour/time.Second) {
+		t.Errorf("expected a token expiration of %d seconds, got %d", int64(time.Hour/time.Second), requestedExpiration)
+	}
+	if ref == nil || ref.Name != "mybuild-1-push-vwxyz" {
+		t.Fatalf("unexpected secret reference: %#v", ref)
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(metav1.NamespaceDefault).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secret.Type != corev1.SecretTypeDockerConfigJson {
+		t.Errorf("unexpected secret type %q", secret.Type)
+	}
+	if secret.Annotations[ScopedPushSecretRepositoryAnnotation] != "registry.test:5000/default/out" {
+		t.Errorf("unexpected repository annotation %q", secret.Annotations[ScopedPushSecretRepositoryAnnotation])
+	}
+	if secret.Annotations[ScopedPushSecretExpirationAnnotation] != expiry.UTC().Format(time.RFC3339) {
+		t.Errorf("unexpected expiration annotation %q", secret.Annotations[ScopedPushSecretExpirationAnnotation])
+	}
+	dockerCfg := string(secret.Data[corev1.DockerConfigJsonKey])
+	if !strings.Contains(dockerCfg, `"registry.test:5000"`) {
+		t.Errorf("expected the config to only list the output registry host, got %s", dockerCfg)
+	}
+	if !strings.Contains(dockerCfg, "short-lived-token") {
+		t.Errorf("expected the config to carry the minted token, got %s", dockerCfg)
+	}
+}
+
+func TestSetOutputArtifacts(t *testing.T) {
+	bc := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceDefault,
+			Name:      "test-build-config",
+			Annotations: map[string]string{
+				buildconversionsv1.OutputArtifactsAnnotation: `[{"type":"sbom","mediaType":"application/spdx+json"},{"type":"provenance","pushSecret":{"name":"attestation-push"}}]`,
+			},
+		},
+	}
+	build := &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "test-build-1"},
+		Spec: buildv1.BuildSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Output: buildv1.BuildOutput{
+					PushSecret: &corev1.LocalObjectReference{Name: "builder-dockercfg"},
+				},
+			},
+		},
+	}
+
+	if err := setOutputArtifacts(bc, build); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	artifacts, err := buildconversionsv1.OutputArtifactsFromAnnotation(&build.ObjectMeta)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(artifacts) != 2 {
+		t.Fatalf("expected 2 artifacts, got %#v", artifacts)
+	}
+	// the artifact without its own secret pushes with the output push secret
+	if artifacts[0].PushSecret == nil || artifacts[0].PushSecret.Name != "builder-dockercfg" {
+		t.Errorf("expected the sbom artifact to default to the output push secret, got %#v", artifacts[0].PushSecret)
+	}
+	// an explicit artifact push secret is left alone
+	if artifacts[1].PushSecret == nil || artifacts[1].PushSecret.Name != "attestation-push" {
+		t.Errorf("expected the provenance artifact to keep its push secret, got %#v", artifacts[1].PushSecret)
+	}
+}
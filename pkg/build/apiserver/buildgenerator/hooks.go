@@ -0,0 +1,162 @@
+package buildgenerator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+// HookFailurePolicy decides what happens to an instantiate request when a
+// build hook cannot be reached or answers with an error.
+type HookFailurePolicy string
+
+const (
+	// HookFailurePolicyFail rejects the instantiate request when the hook
+	// fails. This is the default: a hook that pins base images or injects
+	// required proxy settings usually must not be silently skipped.
+	HookFailurePolicyFail HookFailurePolicy = "Fail"
+	// HookFailurePolicyIgnore logs the hook failure and continues with the
+	// build as generated so far.
+	HookFailurePolicyIgnore HookFailurePolicy = "Ignore"
+)
+
+// BuildHook names an external service that may mutate a generated build
+// before it is persisted.
+type BuildHook struct {
+	// URL is the endpoint the hook request is posted to.
+	URL string
+	// FailurePolicy decides whether a hook failure fails the instantiate
+	// request or is ignored.
+	FailurePolicy HookFailurePolicy
+}
+
+// ParseBuildHooks parses the build-instantiate-hook argument values. Each
+// value is a URL, optionally followed by ",failurePolicy=Fail" or
+// ",failurePolicy=Ignore"; the policy defaults to Fail. Hooks are invoked in
+// the order given.
+func ParseBuildHooks(values []string) ([]BuildHook, error) {
+	var hooks []BuildHook
+	for _, value := range values {
+		hook := BuildHook{FailurePolicy: HookFailurePolicyFail}
+		parts := strings.Split(value, ",")
+		hook.URL = strings.TrimSpace(parts[0])
+		parsed, err := url.Parse(hook.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid build hook URL %q: %v", hook.URL, err)
+		}
+		if !parsed.IsAbs() || len(parsed.Host) == 0 {
+			return nil, fmt.Errorf("build hook URL %q must be an absolute URL", hook.URL)
+		}
+		for _, option := range parts[1:] {
+			keyValue := strings.SplitN(option, "=", 2)
+			if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) != "failurePolicy" {
+				return nil, fmt.Errorf("invalid build hook option %q, expected failurePolicy=<Fail|Ignore>", option)
+			}
+			switch policy := HookFailurePolicy(strings.TrimSpace(keyValue[1])); policy {
+			case HookFailurePolicyFail, HookFailurePolicyIgnore:
+				hook.FailurePolicy = policy
+			default:
+				return nil, fmt.Errorf("invalid build hook failure policy %q, expected Fail or Ignore", keyValue[1])
+			}
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// buildHookTimeout bounds a single hook invocation so a slow hook service
+// cannot stall build instantiation indefinitely.
+const buildHookTimeout = 10 * time.Second
+
+// hookRequest is the payload posted to a build hook.
+type hookRequest struct {
+	// Request is the BuildRequest that triggered the instantiate.
+	Request *buildv1.BuildRequest `json:"request"`
+	// Build is the build generated so far, including mutations applied by
+	// earlier hooks in the chain.
+	Build *buildv1.Build `json:"build"`
+}
+
+// hookResponse is the payload a build hook answers with.
+type hookResponse struct {
+	// Build replaces the generated build when set; a nil build leaves the
+	// build unchanged.
+	Build *buildv1.Build `json:"build"`
+}
+
+// BuildHookCaller posts generated builds to the configured hooks in order and
+// folds the mutations they return into the build before it is persisted.
+type BuildHookCaller struct {
+	hooks  []BuildHook
+	client *http.Client
+}
+
+// NewBuildHookCaller returns a caller for the given hooks. A nil client
+// selects a default client with a request timeout.
+func NewBuildHookCaller(hooks []BuildHook, client *http.Client) *BuildHookCaller {
+	if client == nil {
+		client = &http.Client{Timeout: buildHookTimeout}
+	}
+	return &BuildHookCaller{hooks: hooks, client: client}
+}
+
+// Mutate runs build through every configured hook and returns the resulting
+// build. The name and namespace of the build are not negotiable: values the
+// hook returns for them are discarded. A hook failure either fails the
+// instantiate request or is logged and skipped, according to the hook's
+// failure policy.
+func (c *BuildHookCaller) Mutate(ctx context.Context, request *buildv1.BuildRequest, build *buildv1.Build) (*buildv1.Build, error) {
+	for _, hook := range c.hooks {
+		mutated, err := c.call(ctx, hook, request, build)
+		if err != nil {
+			if hook.FailurePolicy == HookFailurePolicyIgnore {
+				klog.V(2).Infof("Ignoring failed build hook %s for build %s/%s: %v", hook.URL, build.Namespace, build.Name, err)
+				continue
+			}
+			return nil, fmt.Errorf("build hook %s failed: %v", hook.URL, err)
+		}
+		if mutated == nil {
+			continue
+		}
+		mutated.Namespace = build.Namespace
+		mutated.Name = build.Name
+		build = mutated
+	}
+	return build, nil
+}
+
+func (c *BuildHookCaller) call(ctx context.Context, hook BuildHook, request *buildv1.BuildRequest, build *buildv1.Build) (*buildv1.Build, error) {
+	body, err := json.Marshal(&hookRequest{Request: request, Build: build})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("hook returned status %d", resp.StatusCode)
+	}
+
+	decoded := &hookResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(decoded); err != nil {
+		return nil, fmt.Errorf("invalid hook response: %v", err)
+	}
+	return decoded.Build, nil
+}
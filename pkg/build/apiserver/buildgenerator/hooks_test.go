@@ -0,0 +1,137 @@
+package buildgenerator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+func TestParseBuildHooks(t *testing.T) {
+	tests := map[string]struct {
+		values   []string
+		expected []BuildHook
+		errors   bool
+	}{
+		"empty": {},
+		"url only defaults to Fail": {
+			values:   []string{"https://hooks.example.com/mutate"},
+			expected: []BuildHook{{URL: "https://hooks.example.com/mutate", FailurePolicy: HookFailurePolicyFail}},
+		},
+		"explicit failure policy": {
+			values: []string{"https://hooks.example.com/mutate,failurePolicy=Ignore"},
+			expected: []BuildHook{
+				{URL: "https://hooks.example.com/mutate", FailurePolicy: HookFailurePolicyIgnore},
+			},
+		},
+		"multiple hooks keep order": {
+			values: []string{"https://first.example.com", "https://second.example.com,failurePolicy=Fail"},
+			expected: []BuildHook{
+				{URL: "https://first.example.com", FailurePolicy: HookFailurePolicyFail},
+				{URL: "https://second.example.com", FailurePolicy: HookFailurePolicyFail},
+			},
+		},
+		"relative url": {
+			values: []string{"/mutate"},
+			errors: true,
+		},
+		"unknown option": {
+			values: []string{"https://hooks.example.com,timeout=5s"},
+			errors: true,
+		},
+		"invalid failure policy": {
+			values: []string{"https://hooks.example.com,failurePolicy=Maybe"},
+			errors: true,
+		},
+	}
+	for name, test := range tests {
+		hooks, err := ParseBuildHooks(test.values)
+		if test.errors {
+			if err == nil {
+				t.Errorf("%s: expected an error, got hooks %#v", name, hooks)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if len(hooks) != len(test.expected) {
+			t.Errorf("%s: expected %d hooks, got %#v", name, len(test.expected), hooks)
+			continue
+		}
+		for i := range hooks {
+			if hooks[i] != test.expected[i] {
+				t.Errorf("%s: expected hook %#v, got %#v", name, test.expected[i], hooks[i])
+			}
+		}
+	}
+}
+
+func TestBuildHookCallerMutate(t *testing.T) {
+	build := &buildv1.Build{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "bc-1"}}
+	request := &buildv1.BuildRequest{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "bc"}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received := &hookRequest{}
+		if err := json.NewDecoder(r.Body).Decode(received); err != nil {
+			t.Errorf("unexpected hook payload: %v", err)
+		}
+		if received.Request == nil || received.Request.Name != "bc" {
+			t.Errorf("expected the build request in the hook payload, got %#v", received.Request)
+		}
+		mutated := received.Build.DeepCopy()
+		if mutated.Spec.Strategy.DockerStrategy == nil {
+			mutated.Spec.Strategy.DockerStrategy = &buildv1.DockerBuildStrategy{}
+		}
+		mutated.Spec.Strategy.DockerStrategy.Env = append(mutated.Spec.Strategy.DockerStrategy.Env,
+			corev1.EnvVar{Name: "HTTP_PROXY", Value: "http://proxy.example.com"})
+		// the caller must discard identity changes
+		mutated.Name = "renamed"
+		json.NewEncoder(w).Encode(&hookResponse{Build: mutated})
+	}))
+	defer server.Close()
+
+	caller := NewBuildHookCaller([]BuildHook{{URL: server.URL, FailurePolicy: HookFailurePolicyFail}}, nil)
+	mutated, err := caller.Mutate(context.Background(), request, build)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mutated.Name != "bc-1" || mutated.Namespace != "test" {
+		t.Errorf("expected the build identity to be preserved, got %s/%s", mutated.Namespace, mutated.Name)
+	}
+	env := mutated.Spec.Strategy.DockerStrategy.Env
+	if len(env) != 1 || env[0].Name != "HTTP_PROXY" {
+		t.Errorf("expected the hook mutation to be applied, got %#v", env)
+	}
+}
+
+func TestBuildHookCallerFailurePolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "broken", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	build := &buildv1.Build{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "bc-1"}}
+	request := &buildv1.BuildRequest{}
+
+	failing := NewBuildHookCaller([]BuildHook{{URL: server.URL, FailurePolicy: HookFailurePolicyFail}}, nil)
+	if _, err := failing.Mutate(context.Background(), request, build); err == nil {
+		t.Errorf("expected a Fail policy hook error to fail the mutation")
+	}
+
+	ignoring := NewBuildHookCaller([]BuildHook{{URL: server.URL, FailurePolicy: HookFailurePolicyIgnore}}, nil)
+	mutated, err := ignoring.Mutate(context.Background(), request, build)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mutated.Name != "bc-1" {
+		t.Errorf("expected the build to pass through unchanged, got %#v", mutated)
+	}
+}
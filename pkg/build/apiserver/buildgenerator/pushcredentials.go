@@ -0,0 +1,101 @@
+package buildgenerator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/kubernetes/pkg/credentialprovider"
+
+	"github.com/openshift/library-go/pkg/image/imageutil"
+)
+
+const (
+	// ScopedPushSecretRepositoryAnnotation records the single repository the
+	// credential held by a minted push secret is valid for.
+	ScopedPushSecretRepositoryAnnotation = "build.openshift.io/scoped-push-repository"
+	// ScopedPushSecretExpirationAnnotation records when the token held by a
+	// minted push secret expires, in RFC 3339 form. Controllers can use it to
+	// garbage collect secrets whose builds are done.
+	ScopedPushSecretExpirationAnnotation = "build.openshift.io/scoped-push-expiration"
+)
+
+// PushCredentialMinter mints registry push credentials scoped to a single
+// repository for a build that is about to be created. Implementations return
+// a reference to a secret in the build's namespace that is suitable as the
+// build's push secret.
+type PushCredentialMinter interface {
+	MintPushCredential(ctx context.Context, namespace, serviceAccount, buildName, repository string) (*corev1.LocalObjectReference, error)
+}
+
+// serviceAccountPushCredentialMinter implements PushCredentialMinter with
+// bound service account tokens. It requests a token with a short expiration
+// for the build's service account and wraps it in a dockerconfigjson secret
+// that only lists the registry host serving the output repository, so a
+// leaked build pod credential expires on its own instead of living as long
+// as the builder dockercfg.
+type serviceAccountPushCredentialMinter struct {
+	serviceAccounts corev1client.ServiceAccountsGetter
+	secrets         corev1client.SecretsGetter
+	expiration      time.Duration
+}
+
+// NewServiceAccountPushCredentialMinter returns a PushCredentialMinter backed
+// by bound service account tokens that expire after the given duration.
+func NewServiceAccountPushCredentialMinter(serviceAccounts corev1client.ServiceAccountsGetter, secrets corev1client.SecretsGetter, expiration time.Duration) PushCredentialMinter {
+	return &serviceAccountPushCredentialMinter{
+		serviceAccounts: serviceAccounts,
+		secrets:         secrets,
+		expiration:      expiration,
+	}
+}
+
+func (m *serviceAccountPushCredentialMinter) MintPushCredential(ctx context.Context, namespace, serviceAccount, buildName, repository string) (*corev1.LocalObjectReference, error) {
+	ref, err := imageutil.ParseDockerImageReference(repository)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output repository %q: %v", repository, err)
+	}
+	expirationSeconds := int64(m.expiration / time.Second)
+	token, err := m.serviceAccounts.ServiceAccounts(namespace).CreateToken(ctx, serviceAccount, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to mint a push token for build %s/%s: %v", namespace, buildName, err)
+	}
+	dockerCfg, err := json.Marshal(credentialprovider.DockerConfigJSON{
+		Auths: credentialprovider.DockerConfig{
+			ref.Registry: credentialprovider.DockerConfigEntry{
+				Username: serviceAccount,
+				Password: token.Status.Token,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	secret, err := m.secrets.Secrets(namespace).Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: buildName + "-push-",
+			Namespace:    namespace,
+			Annotations: map[string]string{
+				ScopedPushSecretRepositoryAnnotation: repository,
+				ScopedPushSecretExpirationAnnotation: token.Status.ExpirationTimestamp.UTC().Format(time.RFC3339),
+			},
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: dockerCfg,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a push secret for build %s/%s: %v", namespace, buildName, err)
+	}
+	return &corev1.LocalObjectReference{Name: secret.Name}, nil
+}
@@ -0,0 +1,90 @@
+package build
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	buildapi "github.com/openshift/openshift-apiserver/pkg/build/apis/build"
+)
+
+// BuildArtifactsAnnotation holds small structured result summaries attached
+// to a build by the builder, encoded as a JSON object mapping an artifact
+// name to its counters, e.g. {"unit-tests":{"passed":120,"failed":2}}.
+// Heavy artifact data stays external; the annotation only carries summaries
+// small enough to query through the API.  The annotation may only be written
+// through the builds/details subresource, which RBAC restricts to the
+// builder service account.
+const BuildArtifactsAnnotation = "build.openshift.io/artifacts"
+
+const (
+	// maxArtifactsAnnotationSize caps the encoded size of the artifacts
+	// annotation.
+	maxArtifactsAnnotationSize = 4096
+	// maxArtifactEntries caps the number of named artifact summaries.
+	maxArtifactEntries = 16
+)
+
+// validateArtifactsAnnotation verifies that value is a well formed, size
+// capped artifacts summary document.
+func validateArtifactsAnnotation(value string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(value) > maxArtifactsAnnotationSize {
+		allErrs = append(allErrs, field.TooLong(fldPath, value, maxArtifactsAnnotationSize))
+		return allErrs
+	}
+	artifacts := map[string]map[string]int64{}
+	if err := json.Unmarshal([]byte(value), &artifacts); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, value, "must be a JSON object mapping artifact names to integer counters"))
+		return allErrs
+	}
+	if len(artifacts) > maxArtifactEntries {
+		allErrs = append(allErrs, field.Invalid(fldPath, value, "too many artifact entries"))
+	}
+	for name, counters := range artifacts {
+		for _, msg := range validation.IsDNS1123Subdomain(name) {
+			allErrs = append(allErrs, field.Invalid(fldPath, name, msg))
+		}
+		for counter := range counters {
+			for _, msg := range validation.IsDNS1123Subdomain(counter) {
+				allErrs = append(allErrs, field.Invalid(fldPath, counter, msg))
+			}
+		}
+	}
+	return allErrs
+}
+
+// applyArtifactsAnnotation sets or removes the artifacts annotation on build
+// without mutating the annotations map it may share with the stored object.
+func applyArtifactsAnnotation(build *buildapi.Build, value string, present bool) {
+	existing, had := build.Annotations[BuildArtifactsAnnotation]
+	if present == had && existing == value {
+		return
+	}
+	annotations := make(map[string]string, len(build.Annotations)+1)
+	for k, v := range build.Annotations {
+		annotations[k] = v
+	}
+	if present {
+		annotations[BuildArtifactsAnnotation] = value
+	} else {
+		delete(annotations, BuildArtifactsAnnotation)
+	}
+	build.Annotations = annotations
+}
+
+// preserveArtifactsAnnotation reverts any change to the artifacts annotation
+// on newBuild, keeping the value recorded on oldBuild.  It is used by the
+// write paths that are not allowed to modify attached artifacts.
+func preserveArtifactsAnnotation(newBuild, oldBuild *buildapi.Build) {
+	value, ok := oldBuild.Annotations[BuildArtifactsAnnotation]
+	if !ok {
+		delete(newBuild.Annotations, BuildArtifactsAnnotation)
+		return
+	}
+	if newBuild.Annotations == nil {
+		newBuild.Annotations = make(map[string]string)
+	}
+	newBuild.Annotations[BuildArtifactsAnnotation] = value
+}
@@ -0,0 +1,108 @@
+package build
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	buildapi "github.com/openshift/openshift-apiserver/pkg/build/apis/build"
+	_ "github.com/openshift/openshift-apiserver/pkg/build/apis/build/install"
+)
+
+func TestValidateArtifactsAnnotation(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expectValid bool
+	}{
+		{
+			name:        "valid summary",
+			value:       `{"unit-tests":{"passed":120,"failed":2},"scan":{"findings":0}}`,
+			expectValid: true,
+		},
+		{
+			name:        "empty object",
+			value:       `{}`,
+			expectValid: true,
+		},
+		{
+			name:  "not json",
+			value: `not-json`,
+		},
+		{
+			name:  "non integer counter",
+			value: `{"unit-tests":{"passed":"many"}}`,
+		},
+		{
+			name:  "invalid artifact name",
+			value: `{"Unit Tests":{"passed":1}}`,
+		},
+		{
+			name:  "too large",
+			value: `{"unit-tests":{"` + strings.Repeat("a", maxArtifactsAnnotationSize) + `":1}}`,
+		},
+	}
+
+	for _, test := range tests {
+		errs := validateArtifactsAnnotation(test.value, field.NewPath("metadata", "annotations").Key(BuildArtifactsAnnotation))
+		if valid := len(errs) == 0; valid != test.expectValid {
+			t.Errorf("%s: expected valid=%t, got %v", test.name, test.expectValid, errs)
+		}
+	}
+}
+
+func TestDetailsStrategyArtifactsAnnotation(t *testing.T) {
+	ctx := apirequest.NewDefaultContext()
+	value := `{"unit-tests":{"passed":120,"failed":2}}`
+
+	oldBuild := &buildapi.Build{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-1", Namespace: "default", Annotations: map[string]string{"other": "value"}},
+		Status:     buildapi.BuildStatus{Phase: buildapi.BuildPhaseComplete},
+	}
+	newBuild := oldBuild.DeepCopy()
+	newBuild.Annotations[BuildArtifactsAnnotation] = value
+
+	DetailsStrategy.PrepareForUpdate(ctx, newBuild, oldBuild)
+	if newBuild.Annotations[BuildArtifactsAnnotation] != value {
+		t.Errorf("expected artifacts annotation to survive details update, got %v", newBuild.Annotations)
+	}
+	if _, ok := oldBuild.Annotations[BuildArtifactsAnnotation]; ok {
+		t.Errorf("expected old build to be left unmodified, got %v", oldBuild.Annotations)
+	}
+	if errs := DetailsStrategy.ValidateUpdate(ctx, newBuild, oldBuild); len(errs) != 0 {
+		t.Errorf("unexpected validation errors: %v", errs)
+	}
+
+	newBuild.Annotations[BuildArtifactsAnnotation] = `not-json`
+	if errs := DetailsStrategy.ValidateUpdate(ctx, newBuild, oldBuild); len(errs) == 0 {
+		t.Error("expected validation errors for a malformed artifacts annotation")
+	}
+}
+
+func TestStrategyPreservesArtifactsAnnotation(t *testing.T) {
+	ctx := apirequest.NewDefaultContext()
+	value := `{"unit-tests":{"passed":120}}`
+
+	oldBuild := &buildapi.Build{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-1", Namespace: "default", Annotations: map[string]string{BuildArtifactsAnnotation: value}},
+		Status:     buildapi.BuildStatus{Phase: buildapi.BuildPhaseComplete},
+	}
+	newBuild := oldBuild.DeepCopy()
+	newBuild.Annotations[BuildArtifactsAnnotation] = `{"unit-tests":{"passed":0}}`
+
+	Strategy.PrepareForUpdate(ctx, newBuild, oldBuild)
+	if newBuild.Annotations[BuildArtifactsAnnotation] != value {
+		t.Errorf("expected artifacts annotation change to be reverted, got %v", newBuild.Annotations)
+	}
+
+	created := &buildapi.Build{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-2", Namespace: "default", Annotations: map[string]string{BuildArtifactsAnnotation: value}},
+	}
+	Strategy.PrepareForCreate(ctx, created)
+	if _, ok := created.Annotations[BuildArtifactsAnnotation]; ok {
+		t.Errorf("expected artifacts annotation to be stripped on create, got %v", created.Annotations)
+	}
+}
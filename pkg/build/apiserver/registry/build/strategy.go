@@ -91,6 +91,8 @@ func (strategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
 	if len(build.Status.Phase) == 0 {
 		build.Status.Phase = buildapi.BuildPhaseNew
 	}
+	// artifacts are attached by the builder once the build has run
+	delete(build.Annotations, BuildArtifactsAnnotation)
 	manageConditions(build)
 }
 
@@ -110,6 +112,8 @@ func (strategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
 		newBuild.Status.Reason = oldBuild.Status.Reason
 		newBuild.Status.Message = oldBuild.Status.Message
 	}
+	// artifacts may only be written through the builds/details subresource
+	preserveArtifactsAnnotation(newBuild, oldBuild)
 	manageConditions(newBuild)
 
 	if !reflect.DeepEqual(oldBuild.Spec, newBuild.Spec) {
@@ -158,6 +162,7 @@ func (detailsStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Ob
 	message := newBuild.Status.Message
 	reason := newBuild.Status.Reason
 	outputTo := newBuild.Status.Output.To
+	artifacts, hasArtifacts := newBuild.Annotations[BuildArtifactsAnnotation]
 	*newBuild = *oldBuild
 	newBuild.Status.Phase = phase
 	newBuild.Status.Stages = stages
@@ -165,6 +170,7 @@ func (detailsStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Ob
 	newBuild.Status.Reason = reason
 	newBuild.Status.Message = message
 	newBuild.Status.Output.To = outputTo
+	applyArtifactsAnnotation(newBuild, artifacts, hasArtifacts)
 	manageConditions(newBuild)
 }
 
@@ -183,6 +189,9 @@ func (detailsStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Obje
 		// If there was already a revision, then return an error
 		errors = append(errors, field.Duplicate(field.NewPath("spec", "revision"), oldBuild.Spec.Revision))
 	}
+	if value, ok := newBuild.Annotations[BuildArtifactsAnnotation]; ok && value != oldBuild.Annotations[BuildArtifactsAnnotation] {
+		errors = append(errors, validateArtifactsAnnotation(value, field.NewPath("metadata", "annotations").Key(BuildArtifactsAnnotation))...)
+	}
 	return errors
 }
 
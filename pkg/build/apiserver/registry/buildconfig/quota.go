@@ -0,0 +1,87 @@
+package buildconfig
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubetypedclient "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+const (
+	// WebHookQuotaPolicyAnnotation controls how a webhook-triggered build
+	// behaves when its projected resource usage would exceed a ResourceQuota in
+	// the namespace.  "warn" (the default) instantiates the build and returns a
+	// warning to the webhook caller; "strict" rejects the webhook request with
+	// a Forbidden status so SCM users see "quota exceeded" instead of builds
+	// silently stuck Pending.
+	WebHookQuotaPolicyAnnotation = "build.openshift.io/webhook-quota-policy"
+
+	WebHookQuotaPolicyWarn   = "warn"
+	WebHookQuotaPolicyStrict = "strict"
+)
+
+// checkBuildQuota evaluates the projected usage of a build instantiated from
+// config against the ResourceQuotas in its namespace and returns a message for
+// every quota that would be exceeded.  The check is advisory: quota covered by
+// scopes or resources the build does not declare are ignored, and admission
+// remains the authoritative enforcement point.
+func checkBuildQuota(ctx context.Context, quotaClient kubetypedclient.ResourceQuotasGetter, config *buildv1.BuildConfig) ([]string, error) {
+	quotas, err := quotaClient.ResourceQuotas(config.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	projected := projectedBuildUsage(config)
+
+	var exceeded []string
+	for _, quota := range quotas.Items {
+		// quotas with scopes select pods by state or class; evaluating those
+		// server-side here would duplicate the quota controller, so skip them
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		for resourceName, requested := range projected {
+			hard, ok := quota.Status.Hard[resourceName]
+			if !ok {
+				continue
+			}
+			used := quota.Status.Used[resourceName]
+			used.Add(requested)
+			if used.Cmp(hard) > 0 {
+				exceeded = append(exceeded, fmt.Sprintf("%s: %s (limited to %s)", quota.Name, resourceName, hard.String()))
+			}
+		}
+	}
+	return exceeded, nil
+}
+
+// projectedBuildUsage returns the quota-relevant resources the build pod for
+// config is expected to consume: one pod, plus any compute resources declared
+// on the build config.
+func projectedBuildUsage(config *buildv1.BuildConfig) corev1.ResourceList {
+	projected := corev1.ResourceList{corev1.ResourcePods: *resource.NewQuantity(1, resource.DecimalSI)}
+	for name, quantity := range config.Spec.Resources.Requests {
+		switch name {
+		case corev1.ResourceCPU:
+			projected[corev1.ResourceRequestsCPU] = quantity
+			projected[corev1.ResourceCPU] = quantity
+		case corev1.ResourceMemory:
+			projected[corev1.ResourceRequestsMemory] = quantity
+			projected[corev1.ResourceMemory] = quantity
+		}
+	}
+	for name, quantity := range config.Spec.Resources.Limits {
+		switch name {
+		case corev1.ResourceCPU:
+			projected[corev1.ResourceLimitsCPU] = quantity
+		case corev1.ResourceMemory:
+			projected[corev1.ResourceLimitsMemory] = quantity
+		}
+	}
+	return projected
+}
@@ -0,0 +1,97 @@
+package buildconfig
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+func TestCheckBuildQuota(t *testing.T) {
+	tests := []struct {
+		name             string
+		quota            *corev1.ResourceQuota
+		resources        corev1.ResourceRequirements
+		expectedExceeded int
+	}{
+		{
+			name: "no quota",
+		},
+		{
+			name: "pod count available",
+			quota: &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "default"},
+				Status: corev1.ResourceQuotaStatus{
+					Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")},
+					Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("3")},
+				},
+			},
+		},
+		{
+			name: "pod count exhausted",
+			quota: &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "default"},
+				Status: corev1.ResourceQuotaStatus{
+					Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("3")},
+					Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("3")},
+				},
+			},
+			expectedExceeded: 1,
+		},
+		{
+			name: "memory request exceeds quota",
+			quota: &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "default"},
+				Status: corev1.ResourceQuotaStatus{
+					Hard: corev1.ResourceList{corev1.ResourceRequestsMemory: resource.MustParse("1Gi")},
+					Used: corev1.ResourceList{corev1.ResourceRequestsMemory: resource.MustParse("768Mi")},
+				},
+			},
+			resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+			},
+			expectedExceeded: 1,
+		},
+		{
+			name: "scoped quota is skipped",
+			quota: &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "default"},
+				Spec: corev1.ResourceQuotaSpec{
+					Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeNotTerminating},
+				},
+				Status: corev1.ResourceQuotaStatus{
+					Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("0")},
+					Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("0")},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		client := fake.NewSimpleClientset()
+		if test.quota != nil {
+			client = fake.NewSimpleClientset(test.quota)
+		}
+
+		config := &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{Resources: test.resources},
+			},
+		}
+
+		exceeded, err := checkBuildQuota(context.TODO(), client.CoreV1(), config)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if len(exceeded) != test.expectedExceeded {
+			t.Errorf("%s: expected %d exceeded quotas, got %v", test.name, test.expectedExceeded, exceeded)
+		}
+	}
+}
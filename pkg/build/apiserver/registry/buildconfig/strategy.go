@@ -3,6 +3,7 @@ package buildconfig
 import (
 	"context"
 	"reflect"
+	"strconv"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -11,10 +12,17 @@ import (
 	"k8s.io/apiserver/pkg/storage/names"
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
 
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/deprecatedusage"
 	buildapi "github.com/openshift/openshift-apiserver/pkg/build/apis/build"
 	"github.com/openshift/openshift-apiserver/pkg/build/apis/build/validation"
 )
 
+// ObservedGenerationAnnotation records the metadata.generation most recently
+// acted on by a status writer.  BuildConfigStatus has no observedGeneration
+// field and one cannot be added to the stable API, so the strategy maintains
+// the value here whenever an update changes status.
+const ObservedGenerationAnnotation = "build.openshift.io/observed-generation"
+
 var (
 	// GroupStrategy is the logic that applies when creating and updating BuildConfig objects
 	// in the Group api.
@@ -59,7 +67,11 @@ func (strategy) Canonicalize(obj runtime.Object) {
 func (s strategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
 	bc := obj.(*buildapi.BuildConfig)
 	bc.Generation = 1
+	delete(bc.Annotations, ObservedGenerationAnnotation)
 	dropUnknownTriggers(bc)
+	if specLastTriggeredImageIDsChanged(bc, nil) {
+		deprecatedusage.Record(ctx, deprecatedusage.SignalBuildConfigSpecLastTriggeredImageID)
+	}
 }
 
 // PrepareForUpdate clears fields that are not allowed to be set by end users on update.
@@ -73,9 +85,77 @@ func (strategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
 		newBC.Status.LastVersion = oldBC.Status.LastVersion
 	}
 
-	if !reflect.DeepEqual(oldBC.Spec, newBC.Spec) || newBC.Status.LastVersion != oldBC.Status.LastVersion {
+	// compare against the old spec with the same trigger filtering applied, so
+	// that a stored nil trigger list does not register as a spec change against
+	// the empty list dropUnknownTriggers just set on the new object
+	oldSpec := oldBC.Spec
+	oldSpec.Triggers = knownTriggers(oldSpec.Triggers)
+	specChanged := !reflect.DeepEqual(oldSpec, newBC.Spec)
+	if specChanged || newBC.Status.LastVersion != oldBC.Status.LastVersion {
 		newBC.Generation = oldBC.Generation + 1
 	}
+
+	updateObservedGeneration(newBC, oldBC, specChanged)
+
+	if specLastTriggeredImageIDsChanged(newBC, oldBC) {
+		deprecatedusage.Record(ctx, deprecatedusage.SignalBuildConfigSpecLastTriggeredImageID)
+	}
+}
+
+// specLastTriggeredImageIDsChanged reports whether the update writes a
+// non-empty lastTriggeredImageID into a spec image change trigger.  The field
+// is deprecated in favor of status.imageChangeTriggers, so such writes are
+// counted in the deprecated usage report.  old may be nil on create.
+func specLastTriggeredImageIDsChanged(bc, old *buildapi.BuildConfig) bool {
+	specIDs := func(bc *buildapi.BuildConfig) []string {
+		if bc == nil {
+			return nil
+		}
+		var ids []string
+		for _, trigger := range bc.Spec.Triggers {
+			if trigger.ImageChange != nil {
+				ids = append(ids, trigger.ImageChange.LastTriggeredImageID)
+			}
+		}
+		return ids
+	}
+	newIDs, oldIDs := specIDs(bc), specIDs(old)
+	hasValue := false
+	for _, id := range newIDs {
+		if len(id) > 0 {
+			hasValue = true
+			break
+		}
+	}
+	return hasValue && !reflect.DeepEqual(newIDs, oldIDs)
+}
+
+// updateObservedGeneration maintains the ObservedGenerationAnnotation.  Updates
+// that leave status untouched keep the previously recorded value; updates that
+// change status record the generation whose spec the writer acted on.
+func updateObservedGeneration(newBC, oldBC *buildapi.BuildConfig, specChanged bool) {
+	if reflect.DeepEqual(oldBC.Status, newBC.Status) {
+		if value, ok := oldBC.Annotations[ObservedGenerationAnnotation]; ok {
+			if newBC.Annotations == nil {
+				newBC.Annotations = map[string]string{}
+			}
+			newBC.Annotations[ObservedGenerationAnnotation] = value
+		} else {
+			delete(newBC.Annotations, ObservedGenerationAnnotation)
+		}
+		return
+	}
+
+	// a status writer read the old object; if this update also changed the
+	// spec the resulting generation bump was not observed by the writer
+	observed := newBC.Generation
+	if specChanged {
+		observed = oldBC.Generation
+	}
+	if newBC.Annotations == nil {
+		newBC.Annotations = map[string]string{}
+	}
+	newBC.Annotations[ObservedGenerationAnnotation] = strconv.FormatInt(observed, 10)
 }
 
 // Validate validates a new policy.
@@ -136,11 +216,16 @@ func (strategy) CheckGracefulDelete(obj runtime.Object, options *metav1.DeleteOp
 
 // dropUnknownTriggers drops any triggers that are of an unknown type
 func dropUnknownTriggers(bc *buildapi.BuildConfig) {
+	bc.Spec.Triggers = knownTriggers(bc.Spec.Triggers)
+}
+
+// knownTriggers returns the triggers whose type this server understands.
+func knownTriggers(in []buildapi.BuildTriggerPolicy) []buildapi.BuildTriggerPolicy {
 	triggers := []buildapi.BuildTriggerPolicy{}
-	for _, t := range bc.Spec.Triggers {
+	for _, t := range in {
 		if buildapi.KnownTriggerTypes.Has(string(t.Type)) {
 			triggers = append(triggers, t)
 		}
 	}
-	bc.Spec.Triggers = triggers
+	return triggers
 }
@@ -317,6 +317,20 @@ func TestUpdateGeneration(t *testing.T) {
 			},
 			expectedGeneration: 3,
 		},
+		// 2 - no spec change, no new build, generation stays put
+		{
+			input: &buildapi.BuildConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation: 2,
+				},
+			},
+			update: &buildapi.BuildConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation: 2,
+				},
+			},
+			expectedGeneration: 2,
+		},
 	}
 
 	for _, test := range tests {
@@ -328,3 +342,102 @@ func TestUpdateGeneration(t *testing.T) {
 	}
 
 }
+
+func TestObservedGeneration(t *testing.T) {
+	var tests = []struct {
+		name               string
+		old                *buildapi.BuildConfig
+		update             *buildapi.BuildConfig
+		expectedAnnotation string
+	}{
+		{
+			name: "status change records current generation",
+			old: &buildapi.BuildConfig{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Status:     buildapi.BuildConfigStatus{LastVersion: 1},
+			},
+			update: &buildapi.BuildConfig{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Status:     buildapi.BuildConfigStatus{LastVersion: 2},
+			},
+			expectedAnnotation: "4",
+		},
+		{
+			name: "combined spec and status change records the observed generation",
+			old: &buildapi.BuildConfig{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Status:     buildapi.BuildConfigStatus{LastVersion: 1},
+			},
+			update: &buildapi.BuildConfig{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec: buildapi.BuildConfigSpec{
+					CommonSpec: buildapi.CommonSpec{
+						Strategy: buildapi.BuildStrategy{
+							DockerStrategy: &buildapi.DockerBuildStrategy{NoCache: true},
+						},
+					},
+				},
+				Status: buildapi.BuildConfigStatus{LastVersion: 2},
+			},
+			expectedAnnotation: "3",
+		},
+		{
+			name: "spec only change preserves the previous value",
+			old: &buildapi.BuildConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation:  3,
+					Annotations: map[string]string{ObservedGenerationAnnotation: "3"},
+				},
+			},
+			update: &buildapi.BuildConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation:  3,
+					Annotations: map[string]string{ObservedGenerationAnnotation: "42"},
+				},
+				Spec: buildapi.BuildConfigSpec{
+					CommonSpec: buildapi.CommonSpec{
+						Strategy: buildapi.BuildStrategy{
+							DockerStrategy: &buildapi.DockerBuildStrategy{NoCache: true},
+						},
+					},
+				},
+			},
+			expectedAnnotation: "3",
+		},
+		{
+			name: "clients cannot set the annotation",
+			old: &buildapi.BuildConfig{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+			},
+			update: &buildapi.BuildConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation:  3,
+					Annotations: map[string]string{ObservedGenerationAnnotation: "42"},
+				},
+			},
+			expectedAnnotation: "",
+		},
+	}
+
+	for _, test := range tests {
+		GroupStrategy.PrepareForUpdate(apirequest.NewDefaultContext(), test.update, test.old)
+
+		if value := test.update.Annotations[ObservedGenerationAnnotation]; value != test.expectedAnnotation {
+			t.Errorf("%s: expected observed generation %q, got %q", test.name, test.expectedAnnotation, value)
+		}
+	}
+}
+
+func TestObservedGenerationStrippedOnCreate(t *testing.T) {
+	bc := &buildapi.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{ObservedGenerationAnnotation: "42"},
+		},
+	}
+
+	GroupStrategy.PrepareForCreate(apirequest.NewDefaultContext(), bc)
+
+	if value, ok := bc.Annotations[ObservedGenerationAnnotation]; ok {
+		t.Errorf("expected annotation to be stripped on create, got %q", value)
+	}
+}
@@ -23,8 +23,10 @@ import (
 	buildv1 "github.com/openshift/api/build/v1"
 	buildclienttyped "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
 
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/deprecatedusage"
 	buildapi "github.com/openshift/openshift-apiserver/pkg/build/apis/build"
 	buildv1helpers "github.com/openshift/openshift-apiserver/pkg/build/apis/build/v1"
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/apiserverbuildutil"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook"
 )
 
@@ -40,27 +42,33 @@ func init() {
 }
 
 type WebHook struct {
-	groupVersion      schema.GroupVersion
-	buildConfigClient buildclienttyped.BuildConfigsGetter
-	secretsClient     kubetypedclient.SecretsGetter
-	instantiator      buildclienttyped.BuildConfigsGetter
-	plugins           map[string]webhook.Plugin
+	groupVersion         schema.GroupVersion
+	buildConfigClient    buildclienttyped.BuildConfigsGetter
+	secretsClient        kubetypedclient.SecretsGetter
+	quotaClient          kubetypedclient.ResourceQuotasGetter
+	eventsClient         kubetypedclient.EventsGetter
+	instantiator         buildclienttyped.BuildConfigsGetter
+	envRedactionPatterns []string
+	plugins              map[string]webhook.Plugin
 }
 
 // NewWebHookREST returns the webhook handler
-func NewWebHookREST(buildConfigClient buildclienttyped.BuildV1Interface, secretsClient kubetypedclient.SecretsGetter, groupVersion schema.GroupVersion, plugins map[string]webhook.Plugin) *WebHook {
-	return newWebHookREST(buildConfigClient, secretsClient, groupVersion, plugins)
+func NewWebHookREST(buildConfigClient buildclienttyped.BuildV1Interface, secretsClient kubetypedclient.SecretsGetter, quotaClient kubetypedclient.ResourceQuotasGetter, eventsClient kubetypedclient.EventsGetter, envRedactionPatterns []string, groupVersion schema.GroupVersion, plugins map[string]webhook.Plugin) *WebHook {
+	return newWebHookREST(buildConfigClient, secretsClient, quotaClient, eventsClient, envRedactionPatterns, groupVersion, plugins)
 }
 
 // this supports simple unit testing
-func newWebHookREST(buildConfigClient buildclienttyped.BuildConfigsGetter, secretsClient kubetypedclient.SecretsGetter, groupVersion schema.GroupVersion,
+func newWebHookREST(buildConfigClient buildclienttyped.BuildConfigsGetter, secretsClient kubetypedclient.SecretsGetter, quotaClient kubetypedclient.ResourceQuotasGetter, eventsClient kubetypedclient.EventsGetter, envRedactionPatterns []string, groupVersion schema.GroupVersion,
 	plugins map[string]webhook.Plugin) *WebHook {
 	return &WebHook{
-		groupVersion:      groupVersion,
-		buildConfigClient: buildConfigClient,
-		instantiator:      buildConfigClient,
-		secretsClient:     secretsClient,
-		plugins:           plugins,
+		groupVersion:         groupVersion,
+		buildConfigClient:    buildConfigClient,
+		instantiator:         buildConfigClient,
+		secretsClient:        secretsClient,
+		quotaClient:          quotaClient,
+		eventsClient:         eventsClient,
+		envRedactionPatterns: envRedactionPatterns,
+		plugins:              plugins,
 	}
 }
 
@@ -72,15 +80,18 @@ func (h *WebHook) New() runtime.Object {
 // Connect responds to connections with a ConnectHandler
 func (h *WebHook) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
 	return &WebHookHandler{
-		ctx:               ctx,
-		name:              name,
-		options:           options.(*kapi.PodProxyOptions),
-		responder:         responder,
-		groupVersion:      h.groupVersion,
-		plugins:           h.plugins,
-		buildConfigClient: h.buildConfigClient,
-		secretsClient:     h.secretsClient,
-		instantiator:      h.instantiator,
+		ctx:                  ctx,
+		name:                 name,
+		options:              options.(*kapi.PodProxyOptions),
+		responder:            responder,
+		groupVersion:         h.groupVersion,
+		plugins:              h.plugins,
+		buildConfigClient:    h.buildConfigClient,
+		secretsClient:        h.secretsClient,
+		quotaClient:          h.quotaClient,
+		eventsClient:         h.eventsClient,
+		instantiator:         h.instantiator,
+		envRedactionPatterns: h.envRedactionPatterns,
 	}, nil
 }
 
@@ -96,15 +107,18 @@ func (h *WebHook) ConnectMethods() []string {
 
 // WebHookHandler responds to web hook requests from the master.
 type WebHookHandler struct {
-	ctx               context.Context
-	name              string
-	options           *kapi.PodProxyOptions
-	responder         rest.Responder
-	groupVersion      schema.GroupVersion
-	plugins           map[string]webhook.Plugin
-	buildConfigClient buildclienttyped.BuildConfigsGetter
-	secretsClient     kubetypedclient.SecretsGetter
-	instantiator      buildclienttyped.BuildConfigsGetter
+	ctx                  context.Context
+	name                 string
+	options              *kapi.PodProxyOptions
+	responder            rest.Responder
+	groupVersion         schema.GroupVersion
+	plugins              map[string]webhook.Plugin
+	buildConfigClient    buildclienttyped.BuildConfigsGetter
+	secretsClient        kubetypedclient.SecretsGetter
+	quotaClient          kubetypedclient.ResourceQuotasGetter
+	eventsClient         kubetypedclient.EventsGetter
+	instantiator         buildclienttyped.BuildConfigsGetter
+	envRedactionPatterns []string
 }
 
 // ServeHTTP implements the standard http.Handler
@@ -133,35 +147,72 @@ func (w *WebHookHandler) ProcessWebHook(writer http.ResponseWriter, req *http.Re
 	if err != nil {
 		// clients should not be able to find information about build configs in
 		// the system unless the config exists and the secret matches
-		return errors.NewUnauthorized(fmt.Sprintf("the webhook %q for %q did not accept your secret", hookType, name))
+		return unauthorizedSecretMismatch(hookType, name)
 	}
 
 	triggers, err := plugin.GetTriggers(config)
 	if err != nil {
-		return errors.NewUnauthorized(fmt.Sprintf("the webhook %q for %q did not accept your secret", hookType, name))
+		w.recordWebHookRejection(ctx, config, hookType, WebHookSecretRejectedReason, fmt.Sprintf("no enabled trigger matched the invocation: %v", err))
+		return unauthorizedSecretMismatch(hookType, name)
 	}
 
 	klog.V(4).Infof("checking secret for %q webhook trigger of buildconfig %s/%s", hookType, config.Namespace, config.Name)
 	trigger, err := webhook.CheckSecret(ctx, config.Namespace, secret, triggers, w.secretsClient)
 	if err != nil {
-		return errors.NewUnauthorized(fmt.Sprintf("the webhook %q for %q did not accept your secret", hookType, name))
+		w.recordWebHookRejection(ctx, config, hookType, WebHookSecretRejectedReason, fmt.Sprintf("the provided secret was not accepted: %v", err))
+		return unauthorizedSecretMismatch(hookType, name)
+	}
+	if len(trigger.Secret) > 0 && trigger.Secret == secret {
+		// the invocation authenticated with the deprecated inline trigger
+		// secret instead of a secret reference
+		deprecatedusage.Record(ctx, deprecatedusage.SignalBuildConfigWebHookLegacyURL)
 	}
 
 	revision, envvars, dockerStrategyOptions, proceed, err := plugin.Extract(config, trigger, req)
 	if !proceed {
 		switch err {
 		case webhook.ErrSecretMismatch, webhook.ErrHookNotEnabled:
-			return errors.NewUnauthorized(fmt.Sprintf("the webhook %q for %q did not accept your secret", hookType, name))
+			w.recordWebHookRejection(ctx, config, hookType, WebHookSecretRejectedReason, fmt.Sprintf("the invocation was not accepted: %v", err))
+			return unauthorizedSecretMismatch(hookType, name)
 		case webhook.MethodNotSupported:
 			return errors.NewMethodNotSupported(build.Resource("buildconfighook"), req.Method)
 		}
-		if _, ok := err.(*errors.StatusError); !ok && err != nil {
+		if err != nil {
+			w.recordWebHookRejection(ctx, config, hookType, WebHookPayloadRejectedReason, err.Error())
+		}
+		if statusErr, ok := err.(*errors.StatusError); ok {
+			// payload rejections from the plugins are plain BadRequests; give
+			// them a machine-readable cause unless the plugin set one itself
+			if statusErr.ErrStatus.Code == http.StatusBadRequest && !webhook.HasCause(statusErr, webhook.CausePayloadInvalid) {
+				webhook.WithCause(statusErr, webhook.CausePayloadInvalid, statusErr.ErrStatus.Message)
+			}
+			return statusErr
+		}
+		if err != nil {
 			return errors.NewInternalError(fmt.Errorf("hook failed: %v", err))
 		}
-		return err
+		return nil
 	}
 	warning := err
 
+	// best-effort quota projection so SCM users get immediate feedback instead
+	// of a build stuck Pending; admission remains the enforcement point
+	if w.quotaClient != nil {
+		exceeded, quotaErr := checkBuildQuota(ctx, w.quotaClient, config)
+		if quotaErr != nil {
+			utilruntime.HandleError(fmt.Errorf("error evaluating quota for %s webhook of buildconfig %s/%s: %v", hookType, config.Namespace, config.Name, quotaErr))
+		} else if len(exceeded) > 0 {
+			msg := fmt.Sprintf("build from webhook %q would exceed quota: %s", hookType, strings.Join(exceeded, "; "))
+			if config.Annotations[WebHookQuotaPolicyAnnotation] == WebHookQuotaPolicyStrict {
+				w.recordWebHookRejection(ctx, config, hookType, WebHookQuotaExceededReason, msg)
+				return errors.NewForbidden(build.Resource("buildconfighook"), name, fmt.Errorf("%s", msg))
+			}
+			if warning == nil {
+				warning = webhook.NewWarning(msg)
+			}
+		}
+	}
+
 	buildTriggerCauses := webhook.GenerateBuildTriggerInfo(revision, hookType)
 
 	request := &buildv1.BuildRequest{
@@ -174,10 +225,19 @@ func (w *WebHookHandler) ProcessWebHook(writer http.ResponseWriter, req *http.Re
 
 	newBuild, err := w.instantiator.BuildConfigs(config.Namespace).Instantiate(ctx, config.Namespace, request, metav1.CreateOptions{})
 	if err != nil {
+		// the generator rejects paused build configs with a BadRequest; that
+		// is caller misconfiguration, not a server failure
+		if errors.IsBadRequest(err) && strings.Contains(err.Error(), "BuildConfig is paused") {
+			return webhook.WithCause(errors.NewBadRequest(fmt.Sprintf("could not generate a build: %v", err)),
+				webhook.CauseBuildConfigPaused, fmt.Sprintf("the BuildConfig %s/%s is paused and does not accept new builds", config.Namespace, config.Name))
+		}
 		return errors.NewInternalError(fmt.Errorf("could not generate a build: %v", err))
 	}
 
-	// Send back the build name so that the client can alert the user.
+	// Send back the build name so that the client can alert the user.  The
+	// caller only needs the build identity, so env values that look like
+	// credentials are masked rather than echoed back over the hook URL.
+	apiserverbuildutil.RedactBuildEnv(newBuild, w.envRedactionPatterns)
 	if newBuildEncoded, err := runtime.Encode(webhookEncodingCodecFactory.LegacyCodec(w.groupVersion), newBuild); err != nil {
 		utilruntime.HandleError(err)
 	} else {
@@ -186,3 +246,12 @@ func (w *WebHookHandler) ProcessWebHook(writer http.ResponseWriter, req *http.Re
 
 	return warning
 }
+
+// unauthorizedSecretMismatch is the uniform response for every invocation
+// that fails secret verification. It deliberately does not distinguish a
+// missing build config, a disabled trigger or a wrong secret, so that the
+// hook URL cannot be used to probe for configuration details.
+func unauthorizedSecretMismatch(hookType, name string) *errors.StatusError {
+	err := errors.NewUnauthorized(fmt.Sprintf("the webhook %q for %q did not accept your secret", hookType, name))
+	return webhook.WithCause(err, webhook.CauseSecretMismatch, "no enabled webhook trigger accepted the provided secret")
+}
@@ -153,11 +153,12 @@ func newStorage() (*WebHook, *buildConfigInstantiator, *buildfake.Clientset) {
 			},
 			Proceed: true,
 		},
-		"errsecret": &plugin{Err: webhook.ErrSecretMismatch},
-		"errhook":   &plugin{Err: webhook.ErrHookNotEnabled},
-		"err":       &plugin{Err: fmt.Errorf("test error")},
+		"errsecret":  &plugin{Err: webhook.ErrSecretMismatch},
+		"errhook":    &plugin{Err: webhook.ErrHookNotEnabled},
+		"errpayload": &plugin{Err: kerrors.NewBadRequest("payload could not be parsed")},
+		"err":        &plugin{Err: fmt.Errorf("test error")},
 	}
-	hook := newWebHookREST(fakeBuildClient, nil, buildv1.SchemeGroupVersion, plugins)
+	hook := newWebHookREST(fakeBuildClient, nil, nil, nil, nil, buildv1.SchemeGroupVersion, plugins)
 
 	return hook, bci, fakeBuildClient.(*fakeBuildConfigClient).fakeclient
 }
@@ -188,14 +189,15 @@ func (r *fakeResponder) Error(err error) {
 
 func TestConnectWebHook(t *testing.T) {
 	testCases := map[string]struct {
-		Name        string
-		Path        string
-		Obj         *buildv1.BuildConfig
-		RegErr      error
-		ErrFn       func(error) bool
-		WFn         func(*httptest.ResponseRecorder) bool
-		EnvLen      int
-		Instantiate bool
+		Name           string
+		Path           string
+		Obj            *buildv1.BuildConfig
+		RegErr         error
+		InstantiateErr error
+		ErrFn          func(error) bool
+		WFn            func(*httptest.ResponseRecorder) bool
+		EnvLen         int
+		Instantiate    bool
 	}{
 		"hook returns generic error": {
 			Name: "test",
@@ -207,12 +209,33 @@ func TestConnectWebHook(t *testing.T) {
 			Instantiate: false,
 		},
 		"hook returns unauthorized for bad secret": {
-			Name:        "test",
-			Path:        "secret/errsecret",
-			Obj:         &buildv1.BuildConfig{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}},
-			ErrFn:       kerrors.IsUnauthorized,
+			Name: "test",
+			Path: "secret/errsecret",
+			Obj:  &buildv1.BuildConfig{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}},
+			ErrFn: func(err error) bool {
+				return kerrors.IsUnauthorized(err) && webhook.HasCause(err, webhook.CauseSecretMismatch)
+			},
 			Instantiate: false,
 		},
+		"hook returns payload cause for bad payload": {
+			Name: "test",
+			Path: "secret/errpayload",
+			Obj:  &buildv1.BuildConfig{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}},
+			ErrFn: func(err error) bool {
+				return kerrors.IsBadRequest(err) && webhook.HasCause(err, webhook.CausePayloadInvalid)
+			},
+			Instantiate: false,
+		},
+		"hook returns paused cause for paused build config": {
+			Name:           "test",
+			Path:           "secret/ok",
+			Obj:            &buildv1.BuildConfig{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}},
+			InstantiateErr: kerrors.NewBadRequest("can't instantiate from BuildConfig default/test: BuildConfig is paused"),
+			ErrFn: func(err error) bool {
+				return kerrors.IsBadRequest(err) && webhook.HasCause(err, webhook.CauseBuildConfigPaused)
+			},
+			Instantiate: true,
+		},
 		"hook returns unauthorized for bad hook": {
 			Name:        "test",
 			Path:        "secret/errhook",
@@ -263,6 +286,7 @@ func TestConnectWebHook(t *testing.T) {
 	}
 	for k, testCase := range testCases {
 		hook, bci, fakeBuildClient := newStorage()
+		bci.Err = testCase.InstantiateErr
 		if testCase.Obj != nil {
 			fakeBuildClient.PrependReactor("get", "buildconfigs", func(action clientesting.Action) (handled bool, ret runtime.Object, err error) {
 				return true, testCase.Obj, nil
@@ -413,7 +437,7 @@ var mockBuildStrategy = buildv1.BuildStrategy{
 func TestParseUrlError(t *testing.T) {
 	responder := &fakeResponder{}
 	client := newBuildConfigClient(&okBuildConfigInstantiator{})
-	handler, _ := newWebHookREST(client, nil, buildv1.SchemeGroupVersion,
+	handler, _ := newWebHookREST(client, nil, nil, nil, nil, buildv1.SchemeGroupVersion,
 		map[string]webhook.Plugin{"github": github.New(), "gitlab": gitlab.New(), "bitbucket": bitbucket.New()}).
 		Connect(apirequest.WithNamespace(apirequest.NewDefaultContext(), testBuildConfig.Namespace), "build100", &kapi.PodProxyOptions{Path: ""}, responder)
 	server := httptest.NewServer(handler)
@@ -432,7 +456,7 @@ func TestParseUrlError(t *testing.T) {
 func TestParseUrlOK(t *testing.T) {
 	responder := &fakeResponder{}
 	client := newBuildConfigClient(&okBuildConfigInstantiator{}, testBuildConfig)
-	handler, _ := newWebHookREST(client, nil, buildv1.SchemeGroupVersion, map[string]webhook.Plugin{"pathplugin": &pathPlugin{}}).
+	handler, _ := newWebHookREST(client, nil, nil, nil, nil, buildv1.SchemeGroupVersion, map[string]webhook.Plugin{"pathplugin": &pathPlugin{}}).
 		Connect(apirequest.WithNamespace(apirequest.NewDefaultContext(), testBuildConfig.Namespace), "build100", &kapi.PodProxyOptions{Path: "secret101/pathplugin"}, responder)
 	server := httptest.NewServer(handler)
 	defer server.Close()
@@ -450,7 +474,7 @@ func TestParseUrlLong(t *testing.T) {
 	plugin := &pathPlugin{}
 	responder := &fakeResponder{}
 	client := newBuildConfigClient(&okBuildConfigInstantiator{}, testBuildConfig)
-	handler, _ := newWebHookREST(client, nil, buildv1.SchemeGroupVersion, map[string]webhook.Plugin{"pathplugin": plugin}).
+	handler, _ := newWebHookREST(client, nil, nil, nil, nil, buildv1.SchemeGroupVersion, map[string]webhook.Plugin{"pathplugin": plugin}).
 		Connect(apirequest.WithNamespace(apirequest.NewDefaultContext(), testBuildConfig.Namespace), "build100", &kapi.PodProxyOptions{Path: "secret101/pathplugin/some/more/args"}, responder)
 	server := httptest.NewServer(handler)
 	defer server.Close()
@@ -468,7 +492,7 @@ func TestParseUrlLong(t *testing.T) {
 func TestInvokeWebhookMissingPlugin(t *testing.T) {
 	responder := &fakeResponder{}
 	client := newBuildConfigClient(&okBuildConfigInstantiator{}, testBuildConfig)
-	handler, _ := newWebHookREST(client, nil, buildv1.SchemeGroupVersion, map[string]webhook.Plugin{"pathplugin": &pathPlugin{}}).Connect(apirequest.WithNamespace(apirequest.NewDefaultContext(),
+	handler, _ := newWebHookREST(client, nil, nil, nil, nil, buildv1.SchemeGroupVersion, map[string]webhook.Plugin{"pathplugin": &pathPlugin{}}).Connect(apirequest.WithNamespace(apirequest.NewDefaultContext(),
 		testBuildConfig.Namespace), "build100", &kapi.PodProxyOptions{Path: "secret101/missingplugin"}, responder)
 	server := httptest.NewServer(handler)
 	defer server.Close()
@@ -486,7 +510,7 @@ func TestInvokeWebhookMissingPlugin(t *testing.T) {
 func TestInvokeWebhookErrorBuildConfigInstantiate(t *testing.T) {
 	responder := &fakeResponder{}
 	client := newBuildConfigClient(&errorBuildConfigInstantiator{}, testBuildConfig)
-	handler, _ := newWebHookREST(client, nil, buildv1.SchemeGroupVersion, map[string]webhook.Plugin{"pathplugin": &pathPlugin{}}).
+	handler, _ := newWebHookREST(client, nil, nil, nil, nil, buildv1.SchemeGroupVersion, map[string]webhook.Plugin{"pathplugin": &pathPlugin{}}).
 		Connect(apirequest.WithNamespace(apirequest.NewDefaultContext(), testBuildConfig.Namespace), "build100", &kapi.PodProxyOptions{Path: "secret101/pathplugin"}, responder)
 	server := httptest.NewServer(handler)
 	defer server.Close()
@@ -504,7 +528,7 @@ func TestInvokeWebhookErrorBuildConfigInstantiate(t *testing.T) {
 func TestInvokeWebhookErrorGetConfig(t *testing.T) {
 	responder := &fakeResponder{}
 	client := newBuildConfigClient(&okBuildConfigInstantiator{}, testBuildConfig)
-	handler, _ := newWebHookREST(client, nil, buildv1.SchemeGroupVersion, map[string]webhook.Plugin{"pathplugin": &pathPlugin{}}).
+	handler, _ := newWebHookREST(client, nil, nil, nil, nil, buildv1.SchemeGroupVersion, map[string]webhook.Plugin{"pathplugin": &pathPlugin{}}).
 		Connect(apirequest.WithNamespace(apirequest.NewDefaultContext(), testBuildConfig.Namespace), "badbuild100", &kapi.PodProxyOptions{Path: "secret101/pathplugin"}, responder)
 	server := httptest.NewServer(handler)
 	defer server.Close()
@@ -524,7 +548,7 @@ func TestInvokeWebhookErrorGetConfig(t *testing.T) {
 func TestInvokeWebhookErrorCreateBuild(t *testing.T) {
 	responder := &fakeResponder{}
 	client := newBuildConfigClient(&okBuildConfigInstantiator{}, testBuildConfig)
-	handler, _ := newWebHookREST(client, nil, buildv1.SchemeGroupVersion, map[string]webhook.Plugin{"errPlugin": &errPlugin{}}).
+	handler, _ := newWebHookREST(client, nil, nil, nil, nil, buildv1.SchemeGroupVersion, map[string]webhook.Plugin{"errPlugin": &errPlugin{}}).
 		Connect(apirequest.WithNamespace(apirequest.NewDefaultContext(), testBuildConfig.Namespace), "build100", &kapi.PodProxyOptions{Path: "secret101/errPlugin"}, responder)
 	server := httptest.NewServer(handler)
 	defer server.Close()
@@ -0,0 +1,64 @@
+package buildconfig
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+// Event reasons recorded against a BuildConfig when one of its webhooks
+// rejects an invocation.
+const (
+	// WebHookSecretRejectedReason is recorded when the caller's secret did
+	// not match any trigger of the requested hook type.
+	WebHookSecretRejectedReason = "WebHookSecretRejected"
+	// WebHookPayloadRejectedReason is recorded when the payload was malformed
+	// or did not match the configured trigger (for example a push to an
+	// unmatched ref).
+	WebHookPayloadRejectedReason = "WebHookPayloadRejected"
+	// WebHookQuotaExceededReason is recorded when a strict quota policy
+	// rejected the triggered build.
+	WebHookQuotaExceededReason = "WebHookQuotaExceeded"
+)
+
+// webhookEventSource identifies the component field of the recorded events.
+const webhookEventSource = "buildconfig-webhook"
+
+// recordWebHookRejection emits a namespaced warning Event referencing the
+// BuildConfig whose webhook rejected an invocation, so webhook
+// misconfiguration can be debugged from inside the cluster instead of only
+// from the caller's side of the HTTP error.  Recording is best effort.
+func (w *WebHookHandler) recordWebHookRejection(ctx context.Context, config *buildv1.BuildConfig, hookType, reason, message string) {
+	if w.eventsClient == nil {
+		return
+	}
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%v.%x", config.Name, now.UnixNano()),
+			Namespace: config.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       "BuildConfig",
+			APIVersion: buildv1.GroupVersion.String(),
+			Namespace:  config.Namespace,
+			Name:       config.Name,
+			UID:        config.UID,
+		},
+		Reason:         reason,
+		Message:        fmt.Sprintf("webhook %q: %s", hookType, message),
+		Source:         corev1.EventSource{Component: webhookEventSource},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           corev1.EventTypeWarning,
+	}
+	if _, err := w.eventsClient.Events(config.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		utilruntime.HandleError(fmt.Errorf("could not record webhook rejection event for buildconfig %s/%s: %v", config.Namespace, config.Name, err))
+	}
+}
@@ -0,0 +1,53 @@
+package buildconfig
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+func TestRecordWebHookRejection(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	handler := &WebHookHandler{eventsClient: client.CoreV1()}
+
+	config := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default", UID: "uid-1"},
+	}
+	handler.recordWebHookRejection(context.TODO(), config, "github", WebHookSecretRejectedReason, "the provided secret was not accepted")
+
+	events, err := client.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events.Items) != 1 {
+		t.Fatalf("expected one event, got %#v", events.Items)
+	}
+	event := events.Items[0]
+	if event.InvolvedObject.Kind != "BuildConfig" || event.InvolvedObject.Name != "config" || event.InvolvedObject.UID != "uid-1" {
+		t.Errorf("unexpected involved object: %#v", event.InvolvedObject)
+	}
+	if event.Reason != WebHookSecretRejectedReason {
+		t.Errorf("unexpected reason: %s", event.Reason)
+	}
+	if event.Type != corev1.EventTypeWarning {
+		t.Errorf("unexpected type: %s", event.Type)
+	}
+	if !strings.Contains(event.Message, `webhook "github"`) || !strings.Contains(event.Message, "not accepted") {
+		t.Errorf("unexpected message: %s", event.Message)
+	}
+}
+
+func TestRecordWebHookRejectionWithoutClient(t *testing.T) {
+	handler := &WebHookHandler{}
+	config := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"},
+	}
+	// must not panic when no events client is wired (unit test storage)
+	handler.recordWebHookRejection(context.TODO(), config, "github", WebHookPayloadRejectedReason, "unmatched ref")
+}
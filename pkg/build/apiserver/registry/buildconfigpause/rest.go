@@ -0,0 +1,185 @@
+package buildconfigpause
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/util/retry"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1clienttyped "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	buildapi "github.com/openshift/openshift-apiserver/pkg/build/apis/build"
+)
+
+// PausedTransitionAnnotation records when the paused state of a BuildConfig
+// last changed through the pause or resume subresource.  BuildConfigStatus
+// has no conditions field, so the transition is kept on the object metadata.
+const PausedTransitionAnnotation = "build.openshift.io/paused-last-transition"
+
+// PauseResult is the response body of the pause and resume subresources.
+type PauseResult struct {
+	// Namespace and Name identify the BuildConfig acted on.
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Paused is the state of the BuildConfig after the request.
+	Paused bool `json:"paused"`
+	// CancelledBuilds lists the queued builds that were marked cancelled
+	// because the request asked for it with ?cancelPending=true.
+	CancelledBuilds []string `json:"cancelledBuilds,omitempty"`
+}
+
+// REST atomically toggles the openshift.io/build-config.paused annotation on
+// a BuildConfig.  The instantiate path refuses to generate builds for paused
+// configs; this subresource makes the toggle a first-class action instead of
+// requiring clients to patch the annotation themselves.
+type REST struct {
+	buildConfigClient buildv1clienttyped.BuildConfigsGetter
+	buildClient       buildv1clienttyped.BuildsGetter
+	pause             bool
+}
+
+var _ rest.Connecter = &REST{}
+
+// NewPauseREST returns the handler for the buildconfigs/{name}/pause
+// subresource.
+func NewPauseREST(buildClient buildv1clienttyped.BuildV1Interface) *REST {
+	return &REST{buildConfigClient: buildClient, buildClient: buildClient, pause: true}
+}
+
+// NewResumeREST returns the handler for the buildconfigs/{name}/resume
+// subresource.
+func NewResumeREST(buildClient buildv1clienttyped.BuildV1Interface) *REST {
+	return &REST{buildConfigClient: buildClient, buildClient: buildClient, pause: false}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &buildapi.BuildConfig{}
+}
+
+// ConnectMethods returns POST, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"POST"}
+}
+
+// NewConnectOptions returns an empty options object; options are read from
+// the query string
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that toggles the paused annotation of the named
+// BuildConfig and, on pause, optionally cancels its queued builds.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	namespace, ok := apirequest.NamespaceFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewBadRequest("a namespace must be specified")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := r.setPaused(ctx, namespace, name); err != nil {
+			responder.Error(err)
+			return
+		}
+
+		result := PauseResult{Namespace: namespace, Name: name, Paused: r.pause}
+		if r.pause && req.URL.Query().Get("cancelPending") == "true" {
+			cancelled, err := r.cancelQueuedBuilds(ctx, namespace, name)
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			result.CancelledBuilds = cancelled
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(&result); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+// setPaused updates the paused annotation on the BuildConfig, retrying on
+// conflicts so concurrent pause/resume requests settle on the last writer.
+// Requests that do not change the state are a no-op.
+func (r *REST) setPaused(ctx context.Context, namespace, name string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		bc, err := r.buildConfigClient.BuildConfigs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		paused := bc.Annotations[buildv1.BuildConfigPausedAnnotation] == "true"
+		if paused == r.pause {
+			return nil
+		}
+
+		if bc.Annotations == nil {
+			bc.Annotations = map[string]string{}
+		}
+		if r.pause {
+			bc.Annotations[buildv1.BuildConfigPausedAnnotation] = "true"
+		} else {
+			delete(bc.Annotations, buildv1.BuildConfigPausedAnnotation)
+		}
+		bc.Annotations[PausedTransitionAnnotation] = time.Now().UTC().Format(time.RFC3339)
+
+		_, err = r.buildConfigClient.BuildConfigs(namespace).Update(ctx, bc, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// cancelQueuedBuilds marks the config's builds that have not started running
+// yet as cancelled and returns their names.
+func (r *REST) cancelQueuedBuilds(ctx context.Context, namespace, name string) ([]string, error) {
+	builds, err := r.buildClient.Builds(namespace).List(ctx, metav1.ListOptions{
+		// builds carry their config's name truncated to a valid label value
+		LabelSelector: fmt.Sprintf("%s=%s", buildv1.BuildConfigLabel, labelValue(name)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var cancelled []string
+	for i := range builds.Items {
+		build := &builds.Items[i]
+		switch build.Status.Phase {
+		case buildv1.BuildPhaseNew, buildv1.BuildPhasePending:
+		default:
+			continue
+		}
+		if build.Status.Cancelled {
+			continue
+		}
+		build.Status.Cancelled = true
+		if _, err := r.buildClient.Builds(namespace).Update(ctx, build, metav1.UpdateOptions{}); err != nil {
+			return nil, err
+		}
+		cancelled = append(cancelled, build.Name)
+	}
+	return cancelled, nil
+}
+
+// labelValue truncates a build config name the same way the build generator
+// does when labelling the builds it creates.
+func labelValue(name string) string {
+	end := len(name)
+	newName := name
+	for end > 0 {
+		if len(validation.IsValidLabelValue(newName)) == 0 {
+			return newName
+		}
+		end--
+		newName = newName[:end]
+	}
+	return name
+}
@@ -0,0 +1,140 @@
+package buildconfigpause
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
+)
+
+type fakeResponder struct {
+	err error
+}
+
+func (r *fakeResponder) Object(statusCode int, obj runtime.Object) {}
+
+func (r *fakeResponder) Error(err error) {
+	r.err = err
+}
+
+func TestPauseAndResume(t *testing.T) {
+	bc := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "config"},
+	}
+	queued := &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "config-1",
+			Labels:    map[string]string{buildv1.BuildConfigLabel: "config"},
+		},
+		Status: buildv1.BuildStatus{Phase: buildv1.BuildPhaseNew},
+	}
+	running := &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "config-2",
+			Labels:    map[string]string{buildv1.BuildConfigLabel: "config"},
+		},
+		Status: buildv1.BuildStatus{Phase: buildv1.BuildPhaseRunning},
+	}
+	client := buildfake.NewSimpleClientset(bc, queued, running)
+	ctx := apirequest.WithNamespace(context.Background(), "ns")
+
+	result := invoke(t, NewPauseREST(client.BuildV1()), ctx, "/pause?cancelPending=true")
+	if !result.Paused {
+		t.Error("expected the config to be paused")
+	}
+	if len(result.CancelledBuilds) != 1 || result.CancelledBuilds[0] != "config-1" {
+		t.Errorf("expected only the queued build to be cancelled, got %v", result.CancelledBuilds)
+	}
+
+	updated, err := client.BuildV1().BuildConfigs("ns").Get(ctx, "config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Annotations[buildv1.BuildConfigPausedAnnotation] != "true" {
+		t.Errorf("expected paused annotation, got %v", updated.Annotations)
+	}
+	if len(updated.Annotations[PausedTransitionAnnotation]) == 0 {
+		t.Errorf("expected a transition annotation, got %v", updated.Annotations)
+	}
+	cancelledBuild, err := client.BuildV1().Builds("ns").Get(ctx, "config-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cancelledBuild.Status.Cancelled {
+		t.Error("expected the queued build to be marked cancelled")
+	}
+	runningBuild, err := client.BuildV1().Builds("ns").Get(ctx, "config-2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runningBuild.Status.Cancelled {
+		t.Error("did not expect the running build to be cancelled")
+	}
+
+	result = invoke(t, NewResumeREST(client.BuildV1()), ctx, "/resume")
+	if result.Paused {
+		t.Error("expected the config to be resumed")
+	}
+	updated, err = client.BuildV1().BuildConfigs("ns").Get(ctx, "config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := updated.Annotations[buildv1.BuildConfigPausedAnnotation]; ok {
+		t.Errorf("expected paused annotation to be removed, got %v", updated.Annotations)
+	}
+}
+
+func TestPauseIsIdempotent(t *testing.T) {
+	bc := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "config",
+			Annotations: map[string]string{buildv1.BuildConfigPausedAnnotation: "true"},
+		},
+	}
+	client := buildfake.NewSimpleClientset(bc)
+	ctx := apirequest.WithNamespace(context.Background(), "ns")
+
+	result := invoke(t, NewPauseREST(client.BuildV1()), ctx, "/pause")
+	if !result.Paused {
+		t.Error("expected the config to stay paused")
+	}
+	updated, err := client.BuildV1().BuildConfigs("ns").Get(ctx, "config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := updated.Annotations[PausedTransitionAnnotation]; ok {
+		t.Errorf("expected no transition to be recorded for a no-op pause, got %v", updated.Annotations)
+	}
+}
+
+func invoke(t *testing.T, storage *REST, ctx context.Context, target string) PauseResult {
+	t.Helper()
+	responder := &fakeResponder{}
+	handler, err := storage.Connect(ctx, "config", nil, responder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, target, nil))
+	if responder.err != nil {
+		t.Fatalf("unexpected error: %v", responder.err)
+	}
+
+	result := PauseResult{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("unable to decode response %q: %v", recorder.Body.String(), err)
+	}
+	return result
+}
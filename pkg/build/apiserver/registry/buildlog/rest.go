@@ -11,6 +11,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	genericrest "k8s.io/apiserver/pkg/registry/generic/rest"
@@ -137,7 +138,11 @@ func (r *REST) Get(ctx context.Context, name string, opts runtime.Object) (runti
 	// and handle them w/ the old logging code.
 	if len(buildPod.Spec.InitContainers) == 0 {
 		logOpts := buildinternalhelpers.BuildToPodLogOptions(buildLogOpts)
-		return r.getSimpleLogsFn(ctx, build.Namespace, buildPodName, logOpts)
+		obj, err := r.getSimpleLogsFn(ctx, build.Namespace, buildPodName, logOpts)
+		if err != nil {
+			return nil, err
+		}
+		return withSinceLine(ctx, obj), nil
 	}
 
 	// new style builds w/ init containers from here out.
@@ -303,14 +308,20 @@ func (r *REST) Get(ctx context.Context, name string, opts runtime.Object) (runti
 				containerLogOpts.Follow = false
 			}
 
-			if err := r.pipeLogs(ctx, build.Namespace, buildPodName, containerLogOpts, pipeStreamer.In); err != nil {
-				klog.Errorf("error: failed to stream logs for build pod: %s/%s due to: %v", build.Namespace, buildPodName, err)
+			var streamErr error
+			if containerLogOpts.Follow {
+				streamErr = r.pipeLogsFollowingRestarts(ctx, build, buildPodName, containerLogOpts, pipeStreamer.In)
+			} else {
+				streamErr = r.pipeLogs(ctx, build.Namespace, buildPodName, containerLogOpts, pipeStreamer.In)
+			}
+			if streamErr != nil {
+				klog.Errorf("error: failed to stream logs for build pod: %s/%s due to: %v", build.Namespace, buildPodName, streamErr)
 				return
 			}
 		}
 	}()
 
-	return &pipeStreamer, nil
+	return withSinceLine(ctx, &pipeStreamer), nil
 }
 
 // BuildNameForConfigVersion returns the name of the version-th build
@@ -336,6 +347,62 @@ func (r *REST) New() runtime.Object {
 	return &buildapi.BuildLog{}
 }
 
+// pipeLogsFollowingRestarts streams the container logs like pipeLogs, but when
+// a followed stream ends because the build pod was deleted and recreated (for
+// example after an eviction the build controller retried), it reconnects to
+// the new pod and continues streaming after a marker line instead of
+// terminating the follow.
+func (r *REST) pipeLogsFollowingRestarts(ctx context.Context, build *buildv1.Build, buildPodName string, containerLogOpts *kapi.PodLogOptions, writer io.Writer) error {
+	for {
+		buildPod, err := r.PodLister.Pods(build.Namespace).Get(buildPodName)
+		if err != nil {
+			return err
+		}
+		streamedUID := buildPod.UID
+		streamErr := r.pipeLogs(ctx, build.Namespace, buildPodName, containerLogOpts, writer)
+		if !r.waitForRestartedBuildPod(ctx, build.Namespace, build.Name, buildPodName, streamedUID) {
+			return streamErr
+		}
+		fmt.Fprintf(writer, "\n==== build pod %s/%s restarted; continuing with logs from the new pod ====\n", build.Namespace, buildPodName)
+	}
+}
+
+// waitForRestartedBuildPod reports whether a new build pod replaced the one
+// whose logs were just streamed. It waits up to r.Timeout, but only while the
+// build itself is still in a phase that can produce more logs; once the build
+// reaches a terminal phase there is no pod to reconnect to.
+func (r *REST) waitForRestartedBuildPod(ctx context.Context, namespace, buildName, buildPodName string, streamedUID types.UID) bool {
+	restarted := false
+	_ = wait.PollImmediate(time.Second, r.Timeout, func() (bool, error) {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		default:
+		}
+		latest, err := r.BuildClient.Builds(namespace).Get(ctx, buildName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		switch latest.Status.Phase {
+		case buildv1.BuildPhaseNew, buildv1.BuildPhasePending, buildv1.BuildPhaseRunning:
+		default:
+			// the build finished; the stream ended for good
+			return true, nil
+		}
+		pod, err := r.PodLister.Pods(namespace).Get(buildPodName)
+		if err != nil {
+			// the pod has not been recreated yet
+			return false, nil
+		}
+		if pod.UID == streamedUID || pod.Status.Phase == corev1.PodPending {
+			return false, nil
+		}
+		restarted = true
+		return true, nil
+	})
+	return restarted
+}
+
 // pipeLogs retrieves the logs for a particular container and streams them into the provided writer.
 func (r *REST) pipeLogs(ctx context.Context, namespace, buildPodName string, containerLogOpts *kapi.PodLogOptions, writer io.Writer) error {
 	klog.V(4).Infof("pulling build pod logs for %s/%s, container %s", namespace, buildPodName, containerLogOpts.Container)
@@ -348,6 +415,10 @@ func (r *REST) pipeLogs(ctx context.Context, namespace, buildPodName string, con
 	readerCloser, err := logRequest.Stream(ctx)
 	if err != nil {
 		klog.Errorf("error: could not write build log for pod %q to stream due to: %v", buildPodName, err)
+		// surface verification failures to the user instead of silently truncating the stream
+		if isTLSVerificationError(err) {
+			writer.Write([]byte(translateLogStreamError(namespace, buildPodName, err).Error()))
+		}
 		return err
 	}
 
@@ -379,7 +450,7 @@ func (r *REST) getSimpleLogs(ctx context.Context, podNamespace, podName string,
 
 	readerCloser, err := logRequest.Stream(ctx)
 	if err != nil {
-		return nil, err
+		return nil, translateLogStreamError(podNamespace, podName, err)
 	}
 
 	return &apiserverrest.PassThroughStreamer{
@@ -11,6 +11,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 	"k8s.io/client-go/informers"
@@ -369,3 +370,68 @@ func TestPreviousBuildLogs(t *testing.T) {
 		t.Errorf("expected %v, actual %v", e, a)
 	}
 }
+
+func TestWaitForRestartedBuildPod(t *testing.T) {
+	informersCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	oldPod := mockPod(corev1.PodRunning, "running-build")
+	oldPod.UID = "old-uid"
+	client := fake.NewSimpleClientset(oldPod)
+	podInformer := fakeCoreV1PodInformer(client, informersCtx.Done())
+
+	build := mockBuild(buildv1.BuildPhaseRunning, "running", 1)
+	buildClient := buildfakeclient.NewSimpleClientset(build)
+
+	storage := &REST{
+		BuildClient: buildClient.BuildV1(),
+		PodClient:   client.CoreV1(),
+		PodLister:   podInformer.Lister(),
+		Timeout:     100 * time.Millisecond,
+	}
+
+	// artificially waiting for informer's cache synchronization
+	if !cache.WaitForCacheSync(informersCtx.Done(), podInformer.Informer().HasSynced) {
+		t.Error("Informer's cache is not updated!")
+	}
+
+	ctx := apirequest.NewDefaultContext()
+
+	// the streamed pod is still the current one: no restart happened
+	if storage.waitForRestartedBuildPod(ctx, "default", "running", "running-build", "old-uid") {
+		t.Error("expected no restart while the streamed pod is still current")
+	}
+
+	// replace the pod as the build controller would after an eviction
+	if err := client.CoreV1().Pods("default").Delete(informersCtx, "running-build", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	newPod := mockPod(corev1.PodRunning, "running-build")
+	newPod.UID = "new-uid"
+	if _, err := client.CoreV1().Pods("default").Create(informersCtx, newPod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		pod, err := podInformer.Lister().Pods("default").Get("running-build")
+		if err != nil {
+			return false, nil
+		}
+		return pod.UID == "new-uid", nil
+	})
+	if err != nil {
+		t.Fatalf("timed out waiting for the informer to observe the new pod: %v", err)
+	}
+
+	if !storage.waitForRestartedBuildPod(ctx, "default", "running", "running-build", "old-uid") {
+		t.Error("expected a restart to be detected once a new pod replaced the streamed one")
+	}
+
+	// once the build reaches a terminal phase there is nothing to reconnect to
+	finished := mockBuild(buildv1.BuildPhaseComplete, "running", 1)
+	if _, err := buildClient.BuildV1().Builds("default").Update(informersCtx, finished, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if storage.waitForRestartedBuildPod(ctx, "default", "running", "running-build", "old-uid") {
+		t.Error("expected no restart once the build finished")
+	}
+}
@@ -0,0 +1,85 @@
+package buildlog
+
+import (
+	"bufio"
+	"context"
+	"io"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	apiserverfilters "github.com/openshift/openshift-apiserver/pkg/apiserver/filters"
+)
+
+// withSinceLine wraps the log stream so the lines a reconnecting client has
+// already received, passed as the sinceLine query parameter, are not sent
+// again.  Because the skip is applied to the assembled stream it covers both
+// the plain and the init container log paths, and works over websocket
+// upgrades the same as over plain HTTP.
+func withSinceLine(ctx context.Context, obj runtime.Object) runtime.Object {
+	count, ok := apiserverfilters.BuildLogSinceLineFrom(ctx)
+	if !ok || count == 0 {
+		return obj
+	}
+	streamer, ok := obj.(rest.ResourceStreamer)
+	if !ok {
+		return obj
+	}
+	return &skipLinesStreamer{delegate: streamer, count: count}
+}
+
+// skipLinesStreamer suppresses the first count lines of the delegate's
+// stream.
+type skipLinesStreamer struct {
+	delegate rest.ResourceStreamer
+	count    int64
+}
+
+var _ rest.ResourceStreamer = &skipLinesStreamer{}
+
+func (s *skipLinesStreamer) GetObjectKind() schema.ObjectKind {
+	return schema.EmptyObjectKind
+}
+
+func (s *skipLinesStreamer) DeepCopyObject() runtime.Object {
+	panic("buildlog.skipLinesStreamer does not implement DeepCopyObject")
+}
+
+// InputStream returns the delegate's stream with the first count lines
+// removed.
+func (s *skipLinesStreamer) InputStream(ctx context.Context, apiVersion, acceptHeader string) (io.ReadCloser, bool, string, error) {
+	stream, flush, contentType, err := s.delegate.InputStream(ctx, apiVersion, acceptHeader)
+	if stream == nil || err != nil {
+		return stream, flush, contentType, err
+	}
+	return &skipLinesReader{reader: bufio.NewReader(stream), closer: stream, remaining: s.count}, flush, contentType, err
+}
+
+// skipLinesReader discards complete lines from the underlying reader until
+// remaining reaches zero and passes everything through afterwards.
+type skipLinesReader struct {
+	reader    *bufio.Reader
+	closer    io.Closer
+	remaining int64
+}
+
+func (s *skipLinesReader) Read(p []byte) (int, error) {
+	for s.remaining > 0 {
+		_, err := s.reader.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// a line longer than the buffer; keep discarding without
+			// counting the partial read as a complete line
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		s.remaining--
+	}
+	return s.reader.Read(p)
+}
+
+func (s *skipLinesReader) Close() error {
+	return s.closer.Close()
+}
@@ -0,0 +1,71 @@
+package buildlog
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestSkipLinesReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		skip     int64
+		expected string
+	}{
+		{
+			name:     "skip nothing",
+			input:    "one\ntwo\nthree\n",
+			skip:     0,
+			expected: "one\ntwo\nthree\n",
+		},
+		{
+			name:     "skip delivered lines",
+			input:    "one\ntwo\nthree\n",
+			skip:     2,
+			expected: "three\n",
+		},
+		{
+			name:     "skip everything",
+			input:    "one\ntwo\n",
+			skip:     2,
+			expected: "",
+		},
+		{
+			name:     "cursor beyond the stream",
+			input:    "one\ntwo\n",
+			skip:     10,
+			expected: "",
+		},
+		{
+			name:     "trailing partial line is delivered",
+			input:    "one\ntwo",
+			skip:     1,
+			expected: "two",
+		},
+		{
+			name:     "long lines count once",
+			input:    strings.Repeat("x", 3*bufio.MaxScanTokenSize) + "\nshort\n",
+			skip:     1,
+			expected: "short\n",
+		},
+	}
+
+	for _, test := range tests {
+		reader := &skipLinesReader{
+			reader:    bufio.NewReader(strings.NewReader(test.input)),
+			closer:    ioutil.NopCloser(nil),
+			remaining: test.skip,
+		}
+		out, err := ioutil.ReadAll(reader)
+		if err != nil && err != io.EOF {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, string(out))
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package buildlog
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// isTLSVerificationError returns true when the error chain indicates that the
+// TLS handshake for a log connection failed certificate verification.
+func isTLSVerificationError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameError x509.HostnameError
+	var certificateInvalid x509.CertificateInvalidError
+	if errors.As(err, &unknownAuthority) || errors.As(err, &hostnameError) || errors.As(err, &certificateInvalid) {
+		return true
+	}
+	// errors that crossed a process boundary only retain their message
+	return strings.Contains(err.Error(), "x509:")
+}
+
+// translateLogStreamError converts TLS verification failures encountered while
+// streaming logs into a clear status naming the pod and the likely fix; other
+// errors pass through untouched.
+func translateLogStreamError(namespace, podName string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if isTLSVerificationError(err) {
+		return kapierrors.NewServiceUnavailable(fmt.Sprintf("TLS verification failed streaming logs for pod %s/%s: %v; verify the configured build log CA bundle and client certificate match the serving side", namespace, podName, err))
+	}
+	return err
+}
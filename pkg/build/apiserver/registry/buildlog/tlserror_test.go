@@ -0,0 +1,69 @@
+package buildlog
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestIsTLSVerificationError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name: "nil error",
+		},
+		{
+			name: "unrelated error",
+			err:  errors.New("connection refused"),
+		},
+		{
+			name:     "unknown authority",
+			err:      x509.UnknownAuthorityError{},
+			expected: true,
+		},
+		{
+			name:     "wrapped unknown authority",
+			err:      &url.Error{Op: "Get", URL: "https://node:10250", Err: x509.UnknownAuthorityError{}},
+			expected: true,
+		},
+		{
+			name:     "hostname mismatch",
+			err:      x509.HostnameError{Host: "node"},
+			expected: true,
+		},
+		{
+			name:     "flattened message",
+			err:      fmt.Errorf("Get \"https://node:10250\": x509: certificate signed by unknown authority"),
+			expected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		if actual := isTLSVerificationError(tc.err); actual != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestTranslateLogStreamError(t *testing.T) {
+	if err := translateLogStreamError("test", "build-1-build", nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	plain := errors.New("connection refused")
+	if err := translateLogStreamError("test", "build-1-build", plain); err != plain {
+		t.Errorf("expected the original error, got %v", err)
+	}
+
+	err := translateLogStreamError("test", "build-1-build", x509.UnknownAuthorityError{})
+	if !kapierrors.IsServiceUnavailable(err) {
+		t.Errorf("expected a service unavailable status, got %v", err)
+	}
+}
@@ -0,0 +1,264 @@
+package buildpromote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	buildgroup "github.com/openshift/api/build"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagegroup "github.com/openshift/api/image"
+	imagev1 "github.com/openshift/api/image/v1"
+	buildv1clienttyped "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	imagev1clienttyped "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
+	buildapi "github.com/openshift/openshift-apiserver/pkg/build/apis/build"
+)
+
+// PromoteOnSuccessAnnotation lists additional ImageStreamTags that the output
+// image of successful builds of a BuildConfig should be tagged into, as a
+// comma-separated list of [namespace/]name:tag references.  BuildOutput has
+// no field for promotion targets, so the list is kept on the BuildConfig
+// metadata.
+const PromoteOnSuccessAnnotation = "build.openshift.io/promote-on-success"
+
+// PromotionResult is the response body of the builds/promote subresource.
+type PromotionResult struct {
+	// Namespace and Name identify the Build acted on.
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// ImageReference is the output image that was promoted.
+	ImageReference string `json:"imageReference"`
+	// PromotedTags lists the namespace/name:tag references that now point at
+	// the output image.
+	PromotedTags []string `json:"promotedTags,omitempty"`
+}
+
+// REST tags the output image of a completed build into the additional
+// ImageStreamTags listed on its BuildConfig.  Promotion is driven off the
+// recorded build status, so callers cannot race image availability the way
+// post-build scripts polling the registry do.
+type REST struct {
+	buildClient               buildv1clienttyped.BuildsGetter
+	buildConfigClient         buildv1clienttyped.BuildConfigsGetter
+	imageStreamTagClient      imagev1clienttyped.ImageStreamTagsGetter
+	subjectAccessReviewClient authorizationclient.SubjectAccessReviewInterface
+}
+
+var _ rest.Connecter = &REST{}
+
+// NewREST returns the handler for the builds/{name}/promote subresource.
+func NewREST(buildClient buildv1clienttyped.BuildV1Interface, imageStreamTagClient imagev1clienttyped.ImageStreamTagsGetter, subjectAccessReviewClient authorizationclient.SubjectAccessReviewInterface) *REST {
+	return &REST{
+		buildClient:               buildClient,
+		buildConfigClient:         buildClient,
+		imageStreamTagClient:      imageStreamTagClient,
+		subjectAccessReviewClient: subjectAccessReviewClient,
+	}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &buildapi.Build{}
+}
+
+// ConnectMethods returns POST, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"POST"}
+}
+
+// NewConnectOptions returns an empty options object
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that promotes the output image of the named
+// build into the ImageStreamTags listed on its BuildConfig.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	namespace, ok := apirequest.NamespaceFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewBadRequest("a namespace must be specified")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		result, err := r.promote(ctx, namespace, name)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+func (r *REST) promote(ctx context.Context, namespace, name string) (*PromotionResult, error) {
+	build, err := r.buildClient.Builds(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if build.Status.Phase != buildv1.BuildPhaseComplete {
+		return nil, kapierrors.NewConflict(buildgroup.Resource("builds"), name,
+			fmt.Errorf("build is in phase %s; only complete builds can be promoted", build.Status.Phase))
+	}
+	imageReference := outputImageReference(build)
+	if len(imageReference) == 0 {
+		return nil, kapierrors.NewBadRequest(fmt.Sprintf("build %s/%s did not push an output image", namespace, name))
+	}
+
+	configName := build.Annotations[buildv1.BuildConfigAnnotation]
+	if len(configName) == 0 {
+		return nil, kapierrors.NewBadRequest(fmt.Sprintf("build %s/%s was not created from a BuildConfig", namespace, name))
+	}
+	config, err := r.buildConfigClient.BuildConfigs(namespace).Get(ctx, configName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := parsePromotionTargets(config.Annotations[PromoteOnSuccessAnnotation], namespace)
+	if err != nil {
+		return nil, kapierrors.NewBadRequest(err.Error())
+	}
+	if len(targets) == 0 {
+		return nil, kapierrors.NewBadRequest(fmt.Sprintf("BuildConfig %s/%s lists no promotion targets in the %s annotation", namespace, configName, PromoteOnSuccessAnnotation))
+	}
+
+	user, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewBadRequest("a user must be specified")
+	}
+	for _, target := range targets {
+		if err := r.checkTagAccess(ctx, user, target); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &PromotionResult{Namespace: namespace, Name: name, ImageReference: imageReference}
+	for _, target := range targets {
+		if err := r.tagImage(ctx, target, imageReference); err != nil {
+			return nil, err
+		}
+		result.PromotedTags = append(result.PromotedTags, target.String())
+	}
+	return result, nil
+}
+
+// outputImageReference returns the by-digest pull reference of the image the
+// build pushed, or the plain output reference if the digest was not recorded.
+func outputImageReference(build *buildv1.Build) string {
+	ref := build.Status.OutputDockerImageReference
+	if len(ref) == 0 {
+		return ""
+	}
+	if build.Status.Output.To != nil && len(build.Status.Output.To.ImageDigest) > 0 {
+		if i := strings.LastIndex(ref, ":"); i != -1 {
+			ref = ref[:i]
+		}
+		return ref + "@" + build.Status.Output.To.ImageDigest
+	}
+	return ref
+}
+
+// promotionTarget is a single parsed entry of the promotion annotation.
+type promotionTarget struct {
+	namespace string
+	name      string
+	tag       string
+}
+
+func (t promotionTarget) String() string {
+	return t.namespace + "/" + t.name + ":" + t.tag
+}
+
+// parsePromotionTargets splits the comma-separated annotation value into
+// ImageStreamTag references, defaulting the namespace of unqualified entries
+// to the build's namespace.
+func parsePromotionTargets(value, defaultNamespace string) ([]promotionTarget, error) {
+	var targets []promotionTarget
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		target := promotionTarget{namespace: defaultNamespace}
+		if i := strings.Index(entry, "/"); i != -1 {
+			target.namespace = entry[:i]
+			entry = entry[i+1:]
+		}
+		i := strings.LastIndex(entry, ":")
+		if i <= 0 || i == len(entry)-1 {
+			return nil, fmt.Errorf("invalid promotion target %q: must be of the form [namespace/]name:tag", entry)
+		}
+		target.name = entry[:i]
+		target.tag = entry[i+1:]
+		if len(target.namespace) == 0 || strings.Contains(target.name, "/") {
+			return nil, fmt.Errorf("invalid promotion target %q: must be of the form [namespace/]name:tag", entry)
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// checkTagAccess verifies that the requesting user may update the target
+// ImageStreamTag.  Promotion can cross namespaces, so the check runs against
+// the target namespace rather than the build's.
+func (r *REST) checkTagAccess(ctx context.Context, user user.Info, target promotionTarget) error {
+	subjectAccessReview := authorizationutil.AddUserToSAR(user, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: target.namespace,
+				Verb:      "update",
+				Group:     imagegroup.GroupName,
+				Resource:  "imagestreamtags",
+				Name:      target.name + ":" + target.tag,
+			},
+		},
+	})
+	resp, err := r.subjectAccessReviewClient.Create(ctx, subjectAccessReview, metav1.CreateOptions{})
+	if err != nil {
+		return kapierrors.NewInternalError(err)
+	}
+	if !resp.Status.Allowed {
+		return kapierrors.NewForbidden(imagegroup.Resource("imagestreamtags"), target.name+":"+target.tag,
+			fmt.Errorf("%s cannot update imagestreamtags in namespace %s", user.GetName(), target.namespace))
+	}
+	return nil
+}
+
+// tagImage points the target ImageStreamTag at the given image reference,
+// creating the tag if it does not exist yet.
+func (r *REST) tagImage(ctx context.Context, target promotionTarget, imageReference string) error {
+	istag := &imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: target.namespace,
+			Name:      target.name + ":" + target.tag,
+		},
+		Tag: &imagev1.TagReference{
+			Name: target.tag,
+			From: &corev1.ObjectReference{
+				Kind: "DockerImage",
+				Name: imageReference,
+			},
+		},
+	}
+	_, err := r.imageStreamTagClient.ImageStreamTags(target.namespace).Update(ctx, istag, metav1.UpdateOptions{})
+	if kapierrors.IsNotFound(err) {
+		_, err = r.imageStreamTagClient.ImageStreamTags(target.namespace).Create(ctx, istag, metav1.CreateOptions{})
+	}
+	return err
+}
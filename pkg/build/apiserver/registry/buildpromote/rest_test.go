@@ -0,0 +1,200 @@
+package buildpromote
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
+	imagefake "github.com/openshift/client-go/image/clientset/versioned/fake"
+)
+
+type fakeResponder struct {
+	err error
+}
+
+func (r *fakeResponder) Object(statusCode int, obj runtime.Object) {}
+
+func (r *fakeResponder) Error(err error) {
+	r.err = err
+}
+
+type fakeSubjectAccessReviewClient struct {
+	allow    bool
+	requests []*authorizationv1.SubjectAccessReview
+}
+
+func (c *fakeSubjectAccessReviewClient) Create(_ context.Context, sar *authorizationv1.SubjectAccessReview, _ metav1.CreateOptions) (*authorizationv1.SubjectAccessReview, error) {
+	c.requests = append(c.requests, sar)
+	return &authorizationv1.SubjectAccessReview{Status: authorizationv1.SubjectAccessReviewStatus{Allowed: c.allow}}, nil
+}
+
+func completedBuild() *buildv1.Build {
+	return &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "config-1",
+			Annotations: map[string]string{buildv1.BuildConfigAnnotation: "config"},
+		},
+		Status: buildv1.BuildStatus{
+			Phase:                      buildv1.BuildPhaseComplete,
+			OutputDockerImageReference: "registry:5000/ns/out:latest",
+			Output: buildv1.BuildStatusOutput{
+				To: &buildv1.BuildStatusOutputTo{
+					ImageDigest: "sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4",
+				},
+			},
+		},
+	}
+}
+
+func promotionBuildConfig(targets string) *buildv1.BuildConfig {
+	return &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "config",
+			Annotations: map[string]string{PromoteOnSuccessAnnotation: targets},
+		},
+	}
+}
+
+func TestPromoteTagsOutputImage(t *testing.T) {
+	buildClient := buildfake.NewSimpleClientset(completedBuild(), promotionBuildConfig("promoted:stable, other-ns/shared:prod"))
+	imageClient := imagefake.NewSimpleClientset()
+	sarClient := &fakeSubjectAccessReviewClient{allow: true}
+	storage := NewREST(buildClient.BuildV1(), imageClient.ImageV1(), sarClient)
+
+	ctx := apirequest.WithUser(apirequest.WithNamespace(context.Background(), "ns"), &user.DefaultInfo{Name: "promoter"})
+	result, err := invoke(storage, ctx, "config-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedRef := "registry:5000/ns/out@sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4"
+	if result.ImageReference != expectedRef {
+		t.Errorf("expected image reference %s, got %s", expectedRef, result.ImageReference)
+	}
+	if expected := []string{"ns/promoted:stable", "other-ns/shared:prod"}; !reflect.DeepEqual(result.PromotedTags, expected) {
+		t.Errorf("expected promoted tags %v, got %v", expected, result.PromotedTags)
+	}
+
+	if len(sarClient.requests) != 2 {
+		t.Fatalf("expected 2 access reviews, got %d", len(sarClient.requests))
+	}
+	attrs := sarClient.requests[1].Spec.ResourceAttributes
+	if attrs.Namespace != "other-ns" || attrs.Verb != "update" || attrs.Resource != "imagestreamtags" || attrs.Name != "shared:prod" {
+		t.Errorf("unexpected access review attributes: %#v", attrs)
+	}
+	if sarClient.requests[0].Spec.User != "promoter" {
+		t.Errorf("expected access review for promoter, got %q", sarClient.requests[0].Spec.User)
+	}
+
+	istag, err := imageClient.ImageV1().ImageStreamTags("other-ns").Get(ctx, "shared:prod", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if istag.Tag == nil || istag.Tag.From == nil || istag.Tag.From.Name != expectedRef {
+		t.Errorf("expected tag to point at %s, got %#v", expectedRef, istag.Tag)
+	}
+}
+
+func TestPromoteRequiresCompleteBuild(t *testing.T) {
+	build := completedBuild()
+	build.Status.Phase = buildv1.BuildPhaseRunning
+	buildClient := buildfake.NewSimpleClientset(build, promotionBuildConfig("promoted:stable"))
+	storage := NewREST(buildClient.BuildV1(), imagefake.NewSimpleClientset().ImageV1(), &fakeSubjectAccessReviewClient{allow: true})
+
+	ctx := apirequest.WithUser(apirequest.WithNamespace(context.Background(), "ns"), &user.DefaultInfo{Name: "promoter"})
+	if _, err := invoke(storage, ctx, "config-1"); !kapierrors.IsConflict(err) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestPromoteDenied(t *testing.T) {
+	buildClient := buildfake.NewSimpleClientset(completedBuild(), promotionBuildConfig("promoted:stable"))
+	imageClient := imagefake.NewSimpleClientset()
+	storage := NewREST(buildClient.BuildV1(), imageClient.ImageV1(), &fakeSubjectAccessReviewClient{allow: false})
+
+	ctx := apirequest.WithUser(apirequest.WithNamespace(context.Background(), "ns"), &user.DefaultInfo{Name: "promoter"})
+	if _, err := invoke(storage, ctx, "config-1"); !kapierrors.IsForbidden(err) {
+		t.Errorf("expected a forbidden error, got %v", err)
+	}
+	if actions := imageClient.Actions(); len(actions) != 0 {
+		t.Errorf("expected no image actions after denial, got %v", actions)
+	}
+}
+
+func TestParsePromotionTargets(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  []promotionTarget
+		expectErr bool
+	}{
+		{
+			value: "promoted:stable",
+			expected: []promotionTarget{
+				{namespace: "ns", name: "promoted", tag: "stable"},
+			},
+		},
+		{
+			value: "promoted:stable, other-ns/shared:prod,",
+			expected: []promotionTarget{
+				{namespace: "ns", name: "promoted", tag: "stable"},
+				{namespace: "other-ns", name: "shared", tag: "prod"},
+			},
+		},
+		{value: ""},
+		{value: "promoted", expectErr: true},
+		{value: "promoted:", expectErr: true},
+		{value: ":stable", expectErr: true},
+		{value: "a/b/c:stable", expectErr: true},
+	}
+
+	for _, test := range tests {
+		targets, err := parsePromotionTargets(test.value, "ns")
+		if test.expectErr {
+			if err == nil {
+				t.Errorf("%q: expected error, got %v", test.value, targets)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(targets, test.expected) {
+			t.Errorf("%q: expected %v, got %v", test.value, test.expected, targets)
+		}
+	}
+}
+
+func invoke(storage *REST, ctx context.Context, name string) (*PromotionResult, error) {
+	responder := &fakeResponder{}
+	handler, err := storage.Connect(ctx, name, nil, responder)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/promote", nil))
+	if responder.err != nil {
+		return nil, responder.err
+	}
+
+	result := &PromotionResult{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
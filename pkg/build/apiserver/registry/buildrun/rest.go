@@ -0,0 +1,208 @@
+package buildrun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	kubetypedclient "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildtypedclient "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	"github.com/openshift/library-go/pkg/build/naming"
+	buildapi "github.com/openshift/openshift-apiserver/pkg/build/apis/build"
+)
+
+// RunCondition mirrors the Succeeded condition of a Tekton run.
+type RunCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// RunStep is the condensed state of one container of the build pod.
+type RunStep struct {
+	Name       string       `json:"name"`
+	ImageID    string       `json:"imageID,omitempty"`
+	StartedAt  *metav1.Time `json:"startedAt,omitempty"`
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+	ExitCode   *int32       `json:"exitCode,omitempty"`
+	Reason     string       `json:"reason,omitempty"`
+}
+
+// RunResult is a named output of the build, such as the pushed image
+// reference.
+type RunResult struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// RunStatus is the response body of the run subresource: a Build projected
+// into the shape of a Tekton run status.
+type RunStatus struct {
+	Namespace      string         `json:"namespace"`
+	Name           string         `json:"name"`
+	StartTime      *metav1.Time   `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time   `json:"completionTime,omitempty"`
+	Conditions     []RunCondition `json:"conditions"`
+	Steps          []RunStep      `json:"steps,omitempty"`
+	Results        []RunResult    `json:"results,omitempty"`
+}
+
+// REST projects a Build into a condensed, Tekton-run-like status document.
+// Dashboards that present Tekton pipelines and OpenShift builds side by side
+// can consume the projection instead of joining builds against their pods
+// themselves.
+type REST struct {
+	buildClient buildtypedclient.BuildsGetter
+	podClient   kubetypedclient.PodsGetter
+}
+
+var _ rest.Connecter = &REST{}
+
+// NewREST returns the handler for the builds/{name}/run subresource.
+func NewREST(buildClient buildtypedclient.BuildsGetter, podClient kubetypedclient.PodsGetter) *REST {
+	return &REST{buildClient: buildClient, podClient: podClient}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &buildapi.Build{}
+}
+
+// ConnectMethods returns GET, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"GET"}
+}
+
+// NewConnectOptions returns an empty options object
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that serves the run projection of the named
+// build.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	namespace, ok := apirequest.NamespaceFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewBadRequest("a namespace must be specified")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		status, err := r.runStatus(ctx, namespace, name)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+func (r *REST) runStatus(ctx context.Context, namespace, name string) (*RunStatus, error) {
+	build, err := r.buildClient.Builds(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	status := &RunStatus{
+		Namespace:      build.Namespace,
+		Name:           build.Name,
+		StartTime:      build.Status.StartTimestamp,
+		CompletionTime: build.Status.CompletionTimestamp,
+		Conditions:     []RunCondition{succeededCondition(build)},
+		Results:        resultsForBuild(build),
+	}
+
+	// The build pod is deleted when the build is pruned or its pod policy
+	// reaps it; the projection simply omits steps then.
+	pod, err := r.podClient.Pods(namespace).Get(ctx, naming.GetPodName(build.Name, "build"), metav1.GetOptions{})
+	if err == nil {
+		status.Steps = stepsForPod(pod)
+	} else if !kapierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// succeededCondition maps the build phase onto a single Tekton-style
+// Succeeded condition: True once complete, False for failed, errored, or
+// cancelled builds, and Unknown while the build has not finished.
+func succeededCondition(build *buildv1.Build) RunCondition {
+	condition := RunCondition{Type: "Succeeded", Message: build.Status.Message}
+	switch build.Status.Phase {
+	case buildv1.BuildPhaseComplete:
+		condition.Status = string(corev1.ConditionTrue)
+		condition.Reason = "Succeeded"
+	case buildv1.BuildPhaseFailed, buildv1.BuildPhaseError, buildv1.BuildPhaseCancelled:
+		condition.Status = string(corev1.ConditionFalse)
+		condition.Reason = string(build.Status.Reason)
+		if len(condition.Reason) == 0 {
+			condition.Reason = string(build.Status.Phase)
+		}
+	default:
+		condition.Status = string(corev1.ConditionUnknown)
+		condition.Reason = string(build.Status.Phase)
+	}
+	return condition
+}
+
+// stepsForPod flattens the build pod's container statuses, init containers
+// first, into run steps in execution order.
+func stepsForPod(pod *corev1.Pod) []RunStep {
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+
+	steps := make([]RunStep, 0, len(statuses))
+	for _, containerStatus := range statuses {
+		step := RunStep{Name: containerStatus.Name, ImageID: containerStatus.ImageID}
+		switch {
+		case containerStatus.State.Terminated != nil:
+			terminated := containerStatus.State.Terminated
+			startedAt := terminated.StartedAt
+			finishedAt := terminated.FinishedAt
+			exitCode := terminated.ExitCode
+			step.StartedAt = &startedAt
+			step.FinishedAt = &finishedAt
+			step.ExitCode = &exitCode
+			step.Reason = terminated.Reason
+		case containerStatus.State.Running != nil:
+			startedAt := containerStatus.State.Running.StartedAt
+			step.StartedAt = &startedAt
+			step.Reason = "Running"
+		case containerStatus.State.Waiting != nil:
+			step.Reason = containerStatus.State.Waiting.Reason
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// resultsForBuild surfaces the build outputs a pipeline result would carry:
+// the pushed image reference and its digest, when known.
+func resultsForBuild(build *buildv1.Build) []RunResult {
+	var results []RunResult
+	if len(build.Status.OutputDockerImageReference) > 0 {
+		results = append(results, RunResult{Name: "outputImageReference", Value: build.Status.OutputDockerImageReference})
+	}
+	if to := build.Status.Output.To; to != nil && len(to.ImageDigest) > 0 {
+		results = append(results, RunResult{Name: "outputImageDigest", Value: to.ImageDigest})
+	}
+	if build.Status.Config != nil {
+		results = append(results, RunResult{Name: "buildConfig", Value: fmt.Sprintf("%s/%s", build.Namespace, build.Status.Config.Name)})
+	}
+	return results
+}
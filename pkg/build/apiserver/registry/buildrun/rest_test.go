@@ -0,0 +1,172 @@
+package buildrun
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
+)
+
+type fakeResponder struct {
+	err error
+}
+
+func (r *fakeResponder) Object(statusCode int, obj runtime.Object) {}
+
+func (r *fakeResponder) Error(err error) {
+	r.err = err
+}
+
+func TestRunStatusForCompletedBuild(t *testing.T) {
+	start := metav1.NewTime(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(start.Add(2 * time.Minute))
+	build := &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "app-1"},
+		Status: buildv1.BuildStatus{
+			Phase:                      buildv1.BuildPhaseComplete,
+			StartTimestamp:             &start,
+			CompletionTimestamp:        &end,
+			OutputDockerImageReference: "registry.example.com/ns/app:latest",
+			Output: buildv1.BuildStatusOutput{
+				To: &buildv1.BuildStatusOutputTo{ImageDigest: "sha256:abc"},
+			},
+			Config: &corev1.ObjectReference{Name: "app"},
+		},
+	}
+	exitCode := int32(0)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "app-1-build"},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: "git-clone",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Reason: "Completed", StartedAt: start, FinishedAt: end},
+					},
+				},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: "sti-build",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Reason: "Completed", StartedAt: start, FinishedAt: end},
+					},
+				},
+			},
+		},
+	}
+
+	status := invoke(t, NewREST(buildfake.NewSimpleClientset(build).BuildV1(), kubefake.NewSimpleClientset(pod).CoreV1()))
+
+	if len(status.Conditions) != 1 || status.Conditions[0].Type != "Succeeded" || status.Conditions[0].Status != "True" {
+		t.Errorf("expected a True Succeeded condition, got %#v", status.Conditions)
+	}
+	if status.StartTime == nil || !status.StartTime.Equal(&start) {
+		t.Errorf("expected start time %v, got %v", start, status.StartTime)
+	}
+	if len(status.Steps) != 2 || status.Steps[0].Name != "git-clone" || status.Steps[1].Name != "sti-build" {
+		t.Errorf("expected init containers ordered before containers, got %#v", status.Steps)
+	}
+	if status.Steps[0].ExitCode == nil || *status.Steps[0].ExitCode != 0 {
+		t.Errorf("expected exit code 0, got %#v", status.Steps[0])
+	}
+	expectedResults := map[string]string{
+		"outputImageReference": "registry.example.com/ns/app:latest",
+		"outputImageDigest":    "sha256:abc",
+		"buildConfig":          "ns/app",
+	}
+	if len(status.Results) != len(expectedResults) {
+		t.Fatalf("expected %d results, got %#v", len(expectedResults), status.Results)
+	}
+	for _, result := range status.Results {
+		if expectedResults[result.Name] != result.Value {
+			t.Errorf("unexpected result %#v", result)
+		}
+	}
+}
+
+func TestRunStatusForFailedBuildWithoutPod(t *testing.T) {
+	build := &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "app-1"},
+		Status: buildv1.BuildStatus{
+			Phase:   buildv1.BuildPhaseFailed,
+			Reason:  buildv1.StatusReasonGenericBuildFailed,
+			Message: "build failed",
+		},
+	}
+
+	status := invoke(t, NewREST(buildfake.NewSimpleClientset(build).BuildV1(), kubefake.NewSimpleClientset().CoreV1()))
+
+	condition := status.Conditions[0]
+	if condition.Status != "False" || condition.Reason != string(buildv1.StatusReasonGenericBuildFailed) || condition.Message != "build failed" {
+		t.Errorf("expected a False condition carrying the build failure, got %#v", condition)
+	}
+	if len(status.Steps) != 0 {
+		t.Errorf("expected no steps when the build pod is gone, got %#v", status.Steps)
+	}
+	if len(status.Results) != 0 {
+		t.Errorf("expected no results for a failed build, got %#v", status.Results)
+	}
+}
+
+func TestRunStatusForRunningBuild(t *testing.T) {
+	start := metav1.Now()
+	build := &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "app-1"},
+		Status:     buildv1.BuildStatus{Phase: buildv1.BuildPhaseRunning},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "app-1-build"},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "sti-build",
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: start}},
+				},
+			},
+		},
+	}
+
+	status := invoke(t, NewREST(buildfake.NewSimpleClientset(build).BuildV1(), kubefake.NewSimpleClientset(pod).CoreV1()))
+
+	condition := status.Conditions[0]
+	if condition.Status != "Unknown" || condition.Reason != string(buildv1.BuildPhaseRunning) {
+		t.Errorf("expected an Unknown condition while running, got %#v", condition)
+	}
+	if len(status.Steps) != 1 || status.Steps[0].Reason != "Running" || status.Steps[0].StartedAt == nil {
+		t.Errorf("expected a running step, got %#v", status.Steps)
+	}
+}
+
+func invoke(t *testing.T, storage *REST) RunStatus {
+	t.Helper()
+	ctx := apirequest.WithNamespace(context.Background(), "ns")
+	responder := &fakeResponder{}
+	handler, err := storage.Connect(ctx, "app-1", nil, responder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/run", nil))
+	if responder.err != nil {
+		t.Fatalf("unexpected error: %v", responder.err)
+	}
+
+	status := RunStatus{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+		t.Fatalf("unable to decode response %q: %v", recorder.Body.String(), err)
+	}
+	return status
+}
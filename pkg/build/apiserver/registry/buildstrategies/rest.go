@@ -0,0 +1,133 @@
+package buildstrategies
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
+
+	"github.com/openshift/openshift-apiserver/pkg/bootstrappolicy"
+	buildapi "github.com/openshift/openshift-apiserver/pkg/build/apis/build"
+)
+
+// strategyResources maps each build strategy to the authorization subresource
+// that the build.openshift.io/BuildByStrategy admission plugin checks on
+// create.  The discovery endpoint evaluates the same subresources so the
+// answer matches what admission will enforce.
+var strategyResources = []struct {
+	strategy buildv1.BuildStrategyType
+	resource string
+}{
+	{buildv1.DockerBuildStrategyType, bootstrappolicy.DockerBuildResource},
+	{buildv1.DockerBuildStrategyType, bootstrappolicy.OptimizedDockerBuildResource},
+	{buildv1.SourceBuildStrategyType, bootstrappolicy.SourceBuildResource},
+	{buildv1.CustomBuildStrategyType, bootstrappolicy.CustomBuildResource},
+	{buildv1.JenkinsPipelineBuildStrategyType, bootstrappolicy.JenkinsPipelineBuildResource},
+}
+
+// AllowedBuildStrategy reports whether the requesting user may create builds
+// using one strategy subresource in the namespace.
+type AllowedBuildStrategy struct {
+	// Strategy is the build strategy type gated by Resource.
+	Strategy buildv1.BuildStrategyType `json:"strategy"`
+	// Resource is the authorization subresource checked, e.g. builds/docker.
+	Resource string `json:"resource"`
+	// Allowed is true if the user may create builds via this subresource.
+	Allowed bool `json:"allowed"`
+}
+
+// AllowedBuildStrategies is the response body of the allowedstrategies
+// endpoint.
+type AllowedBuildStrategies struct {
+	// Namespace the strategies were evaluated in.
+	Namespace string `json:"namespace"`
+	// Strategies holds one entry per strategy authorization subresource.
+	Strategies []AllowedBuildStrategy `json:"strategies"`
+}
+
+// REST implements a computed, machine-readable discovery endpoint reporting
+// which build strategies the requesting user may use in a namespace.
+// Admission denies disallowed strategies only at create time; this endpoint
+// lets UIs and CLIs evaluate the same policy up front.
+type REST struct {
+	sarClient authorizationclient.SubjectAccessReviewInterface
+}
+
+var _ rest.Connecter = &REST{}
+
+// NewREST returns the allowed strategies discovery handler.
+func NewREST(sarClient authorizationclient.SubjectAccessReviewInterface) *REST {
+	return &REST{sarClient: sarClient}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &buildapi.BuildConfig{}
+}
+
+// ConnectMethods returns GET, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"GET"}
+}
+
+// NewConnectOptions returns an empty options object; there are no options
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that evaluates, for each build strategy, whether
+// the requesting user may create builds using it in the request namespace.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	namespace, ok := apirequest.NamespaceFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewBadRequest("a namespace must be specified")
+	}
+	user, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewBadRequest("unable to get user from context")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		result := AllowedBuildStrategies{Namespace: namespace}
+		for _, entry := range strategyResources {
+			parts := strings.SplitN(entry.resource, "/", 2)
+			sar := authorizationutil.AddUserToSAR(user, &authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace:   namespace,
+						Verb:        "create",
+						Group:       buildv1.GroupName,
+						Resource:    parts[0],
+						Subresource: parts[1],
+					},
+				},
+			})
+			resp, err := r.sarClient.Create(ctx, sar, metav1.CreateOptions{})
+			if err != nil {
+				responder.Error(kapierrors.NewInternalError(err))
+				return
+			}
+			result.Strategies = append(result.Strategies, AllowedBuildStrategy{
+				Strategy: entry.strategy,
+				Resource: entry.resource,
+				Allowed:  resp.Status.Allowed,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(&result); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
@@ -0,0 +1,78 @@
+package deprecatedusagereport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/deprecatedusage"
+	buildapi "github.com/openshift/openshift-apiserver/pkg/build/apis/build"
+)
+
+// reportName is the only accepted resource name; the report is a cluster
+// singleton, matching the convention used by cluster configuration resources.
+const reportName = "cluster"
+
+// DeprecatedUsageReport is the response body of the deprecated usage report
+// endpoint.
+type DeprecatedUsageReport struct {
+	// Usages aggregates the observed calls to deprecated OpenShift API
+	// endpoints and fields by signal, user agent and namespace, most
+	// frequent consumers first. The counters cover this apiserver process
+	// and reset on restart.
+	Usages []deprecatedusage.Usage `json:"usages"`
+}
+
+// REST implements a computed, cluster-scoped endpoint reporting the observed
+// usage of deprecated OpenShift API endpoints and fields. It lets platform
+// teams find the remaining consumers of a deprecated surface before removing
+// it, without turning on audit logging.
+type REST struct {
+	tracker *deprecatedusage.Tracker
+}
+
+var _ rest.Connecter = &REST{}
+var _ rest.Scoper = &REST{}
+
+// NewREST returns the deprecated usage report handler backed by tracker.
+func NewREST(tracker *deprecatedusage.Tracker) *REST {
+	return &REST{tracker: tracker}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &buildapi.BuildConfig{}
+}
+
+func (r *REST) NamespaceScoped() bool {
+	return false
+}
+
+// ConnectMethods returns GET, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"GET"}
+}
+
+// NewConnectOptions returns an empty options object; there are no options
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that serializes the tracked usage.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	if name != reportName {
+		return nil, kapierrors.NewNotFound(buildapi.Resource("deprecatedapiusagereports"), name)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := &DeprecatedUsageReport{Usages: r.tracker.Snapshot()}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
@@ -0,0 +1,55 @@
+package deprecatedusagereport
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/deprecatedusage"
+)
+
+func TestConnect(t *testing.T) {
+	ctx := apirequest.NewContext()
+	tracker := deprecatedusage.NewTracker()
+	tracker.Record(deprecatedusage.SignalBuildConfigWebHookLegacyURL, "jenkins/2.0", "ci")
+	storage := NewREST(tracker)
+
+	if _, err := storage.Connect(ctx, "other", nil, nil); !kapierrors.IsNotFound(err) {
+		t.Errorf("expected not found for names other than cluster, got %v", err)
+	}
+
+	handler, err := storage.Connect(ctx, "cluster", nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+	report := &DeprecatedUsageReport{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Usages) != 1 {
+		t.Fatalf("expected one usage, got %#v", report.Usages)
+	}
+	usage := report.Usages[0]
+	if usage.Signal != deprecatedusage.SignalBuildConfigWebHookLegacyURL || usage.UserAgent != "jenkins/2.0" || usage.Namespace != "ci" || usage.Count != 1 {
+		t.Errorf("unexpected usage: %#v", usage)
+	}
+}
+
+func TestConnectEmptyReport(t *testing.T) {
+	storage := NewREST(deprecatedusage.NewTracker())
+	handler, err := storage.Connect(apirequest.NewContext(), "cluster", nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	if body := recorder.Body.String(); body != "{\"usages\":[]}\n" {
+		t.Errorf("unexpected body: %q", body)
+	}
+}
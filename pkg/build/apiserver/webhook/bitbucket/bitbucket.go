@@ -98,9 +98,9 @@ func (p *WebHookPlugin) Extract(buildCfg *buildv1.BuildConfig, trigger *buildv1.
 		return revision, envvars, dockerStrategyOptions, false, errors.NewBadRequest(fmt.Sprintf("Unknown Bitbucket X-Event-Key %s", method))
 	}
 
-	if !webhook.GitRefMatches(branch, webhook.DefaultConfigRef, &buildCfg.Spec.Source) {
+	if !webhook.TriggerRefMatches(branch, trigger, buildCfg) {
 		klog.V(2).Infof("Skipping build for BuildConfig %s/%s.  Branch reference '%s' does not match configuration", buildCfg.Namespace, buildCfg.Name, branch)
-		return revision, envvars, dockerStrategyOptions, false, err
+		return revision, envvars, dockerStrategyOptions, false, webhook.NewRefMismatchWarning(fmt.Sprintf("skipping build: branch reference %q does not match configuration", branch))
 	}
 
 	return revision, envvars, dockerStrategyOptions, true, err
@@ -10,6 +10,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	buildv1 "github.com/openshift/api/build/v1"
+
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook"
 )
 
 var mockBuildStrategy = buildv1.BuildStrategy{
@@ -334,8 +336,8 @@ func TestExtractSkipsBuildForUnmatchedBranches(t *testing.T) {
 
 	//execute
 	_, _, _, proceed, err := context.plugin.Extract(context.buildCfg, buildConfig.Spec.Triggers[0].BitbucketWebHook, context.req)
-	if err != nil {
-		t.Errorf("unexpected error %v", err)
+	if !webhook.HasCause(err, webhook.CauseRefMismatch) {
+		t.Errorf("Expected a RefMismatch warning, got: %v", err)
 	}
 	if proceed {
 		t.Errorf("Expecting to not continue from this event because the branch is not for this buildConfig '%s'", context.buildCfg.Spec.Source.Git.Ref)
@@ -348,8 +350,8 @@ func TestExtractSkipsBuildForUnmatchedBranches54(t *testing.T) {
 
 	//execute
 	_, _, _, proceed, err := context.plugin.Extract(context.buildCfg, buildConfig.Spec.Triggers[0].BitbucketWebHook, context.req)
-	if err != nil {
-		t.Errorf("unexpected error %v", err)
+	if !webhook.HasCause(err, webhook.CauseRefMismatch) {
+		t.Errorf("Expected a RefMismatch warning, got: %v", err)
 	}
 	if proceed {
 		t.Errorf("Expecting to not continue from this event because the branch is not for this buildConfig '%s'", context.buildCfg.Spec.Source.Git.Ref)
@@ -95,18 +95,18 @@ func (p *WebHookPlugin) Extract(buildCfg *buildv1.BuildConfig, trigger *buildv1.
 
 	if internalData.Git.Refs != nil {
 		for _, ref := range versionedData.Git.Refs {
-			if webhook.GitRefMatches(ref.Ref, webhook.DefaultConfigRef, &buildCfg.Spec.Source) {
+			if webhook.TriggerRefMatches(ref.Ref, trigger, buildCfg) {
 				revision = &buildv1.SourceRevision{
 					Git: &ref.GitSourceRevision,
 				}
 				return revision, envvars, dockerStrategyOptions, true, nil
 			}
 		}
-		warning := webhook.NewWarning(fmt.Sprintf("skipping build. None of the supplied refs matched %q", buildCfg.Spec.Source.Git.Ref))
+		warning := webhook.NewRefMismatchWarning(fmt.Sprintf("skipping build. None of the supplied refs matched %q", buildCfg.Spec.Source.Git.Ref))
 		return revision, envvars, dockerStrategyOptions, false, warning
 	}
-	if !webhook.GitRefMatches(internalData.Git.Ref, webhook.DefaultConfigRef, &buildCfg.Spec.Source) {
-		warning := webhook.NewWarning(fmt.Sprintf("skipping build. Branch reference from %q does not match configuration", internalData.Git.Ref))
+	if !webhook.TriggerRefMatches(internalData.Git.Ref, trigger, buildCfg) {
+		warning := webhook.NewRefMismatchWarning(fmt.Sprintf("skipping build. Branch reference from %q does not match configuration", internalData.Git.Ref))
 		return revision, envvars, dockerStrategyOptions, false, warning
 	}
 	revision = &buildv1.SourceRevision{
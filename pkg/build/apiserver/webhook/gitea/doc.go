@@ -0,0 +1,10 @@
+// Package gitea contains webhook.Plugin implementation of gitea webhooks
+// according to https://docs.gitea.io/en-us/webhooks/
+//
+// Gitea emits a Gogs-derived payload whose commit layout differs from both
+// the GitHub and generic hooks (the head commit is carried in head_commit
+// and the commits array, with string timestamps), so the generic hook cannot
+// reliably extract revisions or filter branches.  Gitea webhooks are
+// configured on a build config with a GitHub webhook trigger, mirroring how
+// Gogs payloads are accepted.
+package gitea
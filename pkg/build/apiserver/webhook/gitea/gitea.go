@@ -0,0 +1,125 @@
+package gitea
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	"github.com/openshift/library-go/pkg/build/buildutil"
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook"
+)
+
+// WebHookPlugin used for processing gitea webhook requests.
+type WebHookPlugin struct{}
+
+// New returns gitea webhook plugin.
+func New() *WebHookPlugin {
+	return &WebHookPlugin{}
+}
+
+type commit struct {
+	ID        string                    `json:"id,omitempty"`
+	Author    buildv1.SourceControlUser `json:"author,omitempty"`
+	Committer buildv1.SourceControlUser `json:"committer,omitempty"`
+	Message   string                    `json:"message,omitempty"`
+}
+
+// NOTE - older gitea releases do not set head_commit; the commits array is
+// always present on push events, with the latest commit last
+type pushEvent struct {
+	Ref        string   `json:"ref,omitempty"`
+	After      string   `json:"after,omitempty"`
+	HeadCommit *commit  `json:"head_commit,omitempty"`
+	Commits    []commit `json:"commits,omitempty"`
+}
+
+// Extract services webhooks from a Gitea server
+func (p *WebHookPlugin) Extract(buildCfg *buildv1.BuildConfig, trigger *buildv1.WebHookTrigger, req *http.Request) (revision *buildv1.SourceRevision, envvars []corev1.EnvVar, dockerStrategyOptions *buildv1.DockerStrategyOptions, proceed bool, err error) {
+	klog.V(4).Infof("Verifying build request for BuildConfig %s/%s", buildCfg.Namespace, buildCfg.Name)
+	if err = verifyRequest(req); err != nil {
+		return revision, envvars, dockerStrategyOptions, proceed, err
+	}
+	method := getEvent(req.Header)
+	if method != "ping" && method != "push" {
+		return revision, envvars, dockerStrategyOptions, proceed, errors.NewBadRequest(fmt.Sprintf("Unknown X-Gitea-Event %s", method))
+	}
+	if method == "ping" {
+		return revision, envvars, dockerStrategyOptions, proceed, err
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return revision, envvars, dockerStrategyOptions, proceed, errors.NewBadRequest(err.Error())
+	}
+	var event pushEvent
+	if err = json.Unmarshal(body, &event); err != nil {
+		return revision, envvars, dockerStrategyOptions, proceed, errors.NewBadRequest(err.Error())
+	}
+	if !webhook.TriggerRefMatches(event.Ref, trigger, buildCfg) {
+		klog.V(2).Infof("Skipping build for BuildConfig %s/%s.  Branch reference from '%s' does not match configuration", buildCfg.Namespace, buildCfg.Name, event.Ref)
+		return revision, envvars, dockerStrategyOptions, proceed, webhook.NewRefMismatchWarning(fmt.Sprintf("skipping build: branch reference %q does not match configuration", event.Ref))
+	}
+
+	head := event.HeadCommit
+	if head == nil {
+		if len(event.Commits) == 0 {
+			return revision, envvars, dockerStrategyOptions, proceed, errors.NewBadRequest("push event contains no commits")
+		}
+		head = &event.Commits[len(event.Commits)-1]
+	}
+
+	revision = &buildv1.SourceRevision{
+		Git: &buildv1.GitSourceRevision{
+			Commit:    head.ID,
+			Author:    head.Author,
+			Committer: head.Committer,
+			Message:   head.Message,
+		},
+	}
+	return revision, envvars, dockerStrategyOptions, true, err
+}
+
+// GetTriggers retrieves the WebHookTriggers for this webhook type (if any).
+// Gitea payloads are accepted by GitHub webhook triggers, the same way Gogs
+// payloads are.
+func (p *WebHookPlugin) GetTriggers(buildConfig *buildv1.BuildConfig) ([]*buildv1.WebHookTrigger, error) {
+	triggers := buildutil.FindTriggerPolicy(buildv1.GitHubWebHookBuildTriggerType, buildConfig)
+	webhookTriggers := []*buildv1.WebHookTrigger{}
+	for _, trigger := range triggers {
+		if trigger.GitHubWebHook != nil {
+			webhookTriggers = append(webhookTriggers, trigger.GitHubWebHook)
+		}
+	}
+	if len(webhookTriggers) == 0 {
+		return nil, webhook.ErrHookNotEnabled
+	}
+	return webhookTriggers, nil
+}
+
+func verifyRequest(req *http.Request) error {
+	if method := req.Method; method != "POST" {
+		return webhook.MethodNotSupported
+	}
+	contentType := req.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return errors.NewBadRequest(fmt.Sprintf("non-parseable Content-Type %s (%s)", contentType, err))
+	}
+	if mediaType != "application/json" {
+		return errors.NewBadRequest(fmt.Sprintf("unsupported Content-Type %s", contentType))
+	}
+	if len(getEvent(req.Header)) == 0 {
+		return errors.NewBadRequest("missing X-Gitea-Event")
+	}
+	return nil
+}
+
+func getEvent(header http.Header) string {
+	return header.Get("X-Gitea-Event")
+}
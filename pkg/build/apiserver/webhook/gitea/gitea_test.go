@@ -0,0 +1,199 @@
+package gitea
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	buildv1 "github.com/openshift/api/build/v1"
+
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook"
+)
+
+var buildConfig = &buildv1.BuildConfig{
+	Spec: buildv1.BuildConfigSpec{
+		Triggers: []buildv1.BuildTriggerPolicy{
+			{
+				Type: buildv1.GitHubWebHookBuildTriggerType,
+				GitHubWebHook: &buildv1.WebHookTrigger{
+					Secret: "secret100",
+				},
+			},
+		},
+		CommonSpec: buildv1.CommonSpec{
+			Source: buildv1.BuildSource{
+				Git: &buildv1.GitBuildSource{},
+			},
+		},
+	},
+}
+
+func GivenRequest(method string) *http.Request {
+	req, _ := http.NewRequest(method, "http://someurl.com", nil)
+	return req
+}
+
+func TestVerifyRequestForMethod(t *testing.T) {
+	req := GivenRequest("GET")
+	plugin := New()
+	revision, _, _, proceed, err := plugin.Extract(buildConfig, buildConfig.Spec.Triggers[0].GitHubWebHook, req)
+
+	if err == nil || !strings.Contains(err.Error(), "unsupported HTTP method") {
+		t.Errorf("Expected unsupported HTTP method, got %v", err)
+	}
+	if proceed {
+		t.Error("Expected 'proceed' return value to be 'false'")
+	}
+	if revision != nil {
+		t.Error("Expected the 'revision' return value to be nil")
+	}
+}
+
+func TestMissingEvent(t *testing.T) {
+	req := GivenRequest("POST")
+	req.Header.Add("Content-Type", "application/json")
+	plugin := New()
+	revision, _, _, proceed, err := plugin.Extract(buildConfig, buildConfig.Spec.Triggers[0].GitHubWebHook, req)
+
+	if err == nil || !strings.Contains(err.Error(), "missing X-Gitea-Event") {
+		t.Errorf("Expected missing X-Gitea-Event, got %v", err)
+	}
+	if proceed {
+		t.Error("Expected 'proceed' return value to be 'false'")
+	}
+	if revision != nil {
+		t.Error("Expected the 'revision' return value to be nil")
+	}
+}
+
+func TestWrongGiteaEvent(t *testing.T) {
+	req := GivenRequest("POST")
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("X-Gitea-Event", "wrong")
+	plugin := New()
+	revision, _, _, proceed, err := plugin.Extract(buildConfig, buildConfig.Spec.Triggers[0].GitHubWebHook, req)
+
+	if err == nil || !strings.Contains(err.Error(), "Unknown X-Gitea-Event") {
+		t.Errorf("Expected Unknown X-Gitea-Event, got %v", err)
+	}
+	if proceed {
+		t.Error("Expected 'proceed' return value to be 'false'")
+	}
+	if revision != nil {
+		t.Error("Expected the 'revision' return value to be nil")
+	}
+}
+
+func TestPingEvent(t *testing.T) {
+	req := GivenRequest("POST")
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("X-Gitea-Event", "ping")
+	plugin := New()
+	revision, _, _, proceed, err := plugin.Extract(buildConfig, buildConfig.Spec.Triggers[0].GitHubWebHook, req)
+
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if proceed {
+		t.Error("Expected 'proceed' return value to be 'false'")
+	}
+	if revision != nil {
+		t.Error("Expected the 'revision' return value to be nil")
+	}
+}
+
+func TestJsonPushEventError(t *testing.T) {
+	req := post("push", []byte{}, "http://some.url", t)
+	plugin := New()
+	revision, _, _, proceed, err := plugin.Extract(buildConfig, buildConfig.Spec.Triggers[0].GitHubWebHook, req)
+
+	if err == nil || !strings.Contains(err.Error(), "unexpected end of JSON input") {
+		t.Errorf("Expected unexpected end of JSON input, got %v", err)
+	}
+	if proceed {
+		t.Error("Expected 'proceed' return value to be 'false'")
+	}
+	if revision != nil {
+		t.Error("Expected the 'revision' return value to be nil")
+	}
+}
+
+func TestJsonGiteaPushEvent(t *testing.T) {
+	req := postFile("push", "pushevent.json", "http://some.url", t)
+	plugin := New()
+	revision, _, _, proceed, err := plugin.Extract(buildConfig, buildConfig.Spec.Triggers[0].GitHubWebHook, req)
+
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !proceed {
+		t.Error("Expected 'proceed' return value to be 'true'")
+	}
+	if revision == nil || revision.Git == nil {
+		t.Fatal("Expected a git source revision")
+	}
+	if revision.Git.Commit != "bffeb74224043ba2feb48d137756c8971f04bce8" {
+		t.Errorf("Unexpected revision commit %q", revision.Git.Commit)
+	}
+	if revision.Git.Author.Name != "Gitea" {
+		t.Errorf("Unexpected revision author %q", revision.Git.Author.Name)
+	}
+}
+
+func TestJsonGiteaPushEventNotMasterBranch(t *testing.T) {
+	req := postFile("push", "pushevent-not-master-branch.json", "http://some.url", t)
+	plugin := New()
+	revision, _, _, proceed, err := plugin.Extract(buildConfig, buildConfig.Spec.Triggers[0].GitHubWebHook, req)
+
+	if !webhook.HasCause(err, webhook.CauseRefMismatch) {
+		t.Errorf("Expected a RefMismatch warning, got: %v", err)
+	}
+	if proceed {
+		t.Error("Expected 'proceed' return value to be 'false' for non-matching branch")
+	}
+	if revision != nil {
+		t.Error("Expected the 'revision' return value to be nil")
+	}
+}
+
+func TestGetTriggersNotEnabled(t *testing.T) {
+	config := &buildv1.BuildConfig{
+		Spec: buildv1.BuildConfigSpec{
+			Triggers: []buildv1.BuildTriggerPolicy{
+				{
+					Type: buildv1.GitLabWebHookBuildTriggerType,
+					GitLabWebHook: &buildv1.WebHookTrigger{
+						Secret: "secret100",
+					},
+				},
+			},
+		},
+	}
+	plugin := New()
+	if _, err := plugin.GetTriggers(config); err == nil {
+		t.Error("Expected an error when no github webhook trigger is defined")
+	}
+}
+
+func postFile(eventName, filename, url string, t *testing.T) *http.Request {
+	data, err := ioutil.ReadFile("testdata/" + filename)
+	if err != nil {
+		t.Errorf("Failed to open %s: %v", filename, err)
+	}
+
+	return post(eventName, data, url, t)
+}
+
+func post(eventName string, data []byte, url string, t *testing.T) *http.Request {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		t.Errorf("Error creating POST request: %v", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("X-Gitea-Event", eventName)
+
+	return req
+}
@@ -6,9 +6,11 @@ import (
 	"io/ioutil"
 	"mime"
 	"net/http"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
 	buildv1 "github.com/openshift/api/build/v1"
@@ -16,6 +18,13 @@ import (
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook"
 )
 
+// AllowedEventsAnnotation lists the GitHub event types the webhook accepts
+// for a build configuration, comma separated (for example
+// "push,pull_request,check_run"). Without the annotation only push events
+// trigger builds, preserving the historical behavior; pull_request and
+// check_run events must be opted into explicitly.
+const AllowedEventsAnnotation = "build.openshift.io/github-webhook-events"
+
 // WebHookPlugin used for processing github webhook requests.
 type WebHookPlugin struct{}
 
@@ -37,6 +46,35 @@ type pushEvent struct {
 	HeadCommit commit `json:"head_commit,omitempty"`
 }
 
+type pullRequestHead struct {
+	SHA string `json:"sha,omitempty"`
+	Ref string `json:"ref,omitempty"`
+}
+
+type pullRequestDetail struct {
+	Head pullRequestHead `json:"head,omitempty"`
+}
+
+type pullRequestEvent struct {
+	Action      string            `json:"action,omitempty"`
+	Number      int               `json:"number,omitempty"`
+	PullRequest pullRequestDetail `json:"pull_request,omitempty"`
+}
+
+type checkSuite struct {
+	HeadBranch string `json:"head_branch,omitempty"`
+}
+
+type checkRun struct {
+	HeadSHA    string     `json:"head_sha,omitempty"`
+	CheckSuite checkSuite `json:"check_suite,omitempty"`
+}
+
+type checkRunEvent struct {
+	Action   string   `json:"action,omitempty"`
+	CheckRun checkRun `json:"check_run,omitempty"`
+}
+
 // Extract services webhooks from github.com
 func (p *WebHookPlugin) Extract(buildCfg *buildv1.BuildConfig, trigger *buildv1.WebHookTrigger, req *http.Request) (revision *buildv1.SourceRevision, envvars []corev1.EnvVar, dockerStrategyOptions *buildv1.DockerStrategyOptions, proceed bool, err error) {
 	klog.V(4).Infof("Verifying build request for BuildConfig %s/%s", buildCfg.Namespace, buildCfg.Name)
@@ -44,23 +82,34 @@ func (p *WebHookPlugin) Extract(buildCfg *buildv1.BuildConfig, trigger *buildv1.
 		return revision, envvars, dockerStrategyOptions, proceed, err
 	}
 	method := getEvent(req.Header)
-	if method != "ping" && method != "push" {
+	if method != "ping" && method != "push" && method != "pull_request" && method != "check_run" {
 		return revision, envvars, dockerStrategyOptions, proceed, errors.NewBadRequest(fmt.Sprintf("Unknown X-GitHub-Event or X-Gogs-Event %s", method))
 	}
 	if method == "ping" {
 		return revision, envvars, dockerStrategyOptions, proceed, err
 	}
+	if !allowedEvents(buildCfg).Has(method) {
+		return revision, envvars, dockerStrategyOptions, proceed, errors.NewBadRequest(fmt.Sprintf("the %s event is not enabled for this build configuration; list it in the %s annotation to enable it", method, AllowedEventsAnnotation))
+	}
 	body, err := ioutil.ReadAll(req.Body)
 	if err != nil {
 		return revision, envvars, dockerStrategyOptions, proceed, errors.NewBadRequest(err.Error())
 	}
+
+	switch method {
+	case "pull_request":
+		return p.extractPullRequest(buildCfg, trigger, body)
+	case "check_run":
+		return p.extractCheckRun(buildCfg, trigger, body)
+	}
+
 	var event pushEvent
 	if err = json.Unmarshal(body, &event); err != nil {
 		return revision, envvars, dockerStrategyOptions, proceed, errors.NewBadRequest(err.Error())
 	}
-	if !webhook.GitRefMatches(event.Ref, webhook.DefaultConfigRef, &buildCfg.Spec.Source) {
+	if !webhook.TriggerRefMatches(event.Ref, trigger, buildCfg) {
 		klog.V(2).Infof("Skipping build for BuildConfig %s/%s.  Branch reference from '%s' does not match configuration", buildCfg.Namespace, buildCfg.Name, event)
-		return revision, envvars, dockerStrategyOptions, proceed, err
+		return revision, envvars, dockerStrategyOptions, proceed, webhook.NewRefMismatchWarning(fmt.Sprintf("skipping build: branch reference %q does not match configuration", event.Ref))
 	}
 
 	revision = &buildv1.SourceRevision{
@@ -74,6 +123,80 @@ func (p *WebHookPlugin) Extract(buildCfg *buildv1.BuildConfig, trigger *buildv1.
 	return revision, envvars, dockerStrategyOptions, true, err
 }
 
+// extractPullRequest handles a pull_request event. Only opened, reopened and
+// synchronize actions trigger builds; everything else (labels, reviews,
+// closing) is acknowledged without a build. The event ref is constructed as
+// refs/pull/N/head, and because that ref never matches a branch configured as
+// the source ref, it is only filtered when the trigger restricts refs.
+func (p *WebHookPlugin) extractPullRequest(buildCfg *buildv1.BuildConfig, trigger *buildv1.WebHookTrigger, body []byte) (*buildv1.SourceRevision, []corev1.EnvVar, *buildv1.DockerStrategyOptions, bool, error) {
+	var event pullRequestEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, nil, nil, false, errors.NewBadRequest(err.Error())
+	}
+	switch event.Action {
+	case "opened", "reopened", "synchronize":
+	default:
+		klog.V(4).Infof("Skipping build for BuildConfig %s/%s: pull_request action %q does not trigger builds", buildCfg.Namespace, buildCfg.Name, event.Action)
+		return nil, nil, nil, false, nil
+	}
+
+	ref := fmt.Sprintf("refs/pull/%d/head", event.Number)
+	if webhook.TriggerRestrictsRefs(trigger, buildCfg) && !webhook.TriggerRefMatches(ref, trigger, buildCfg) {
+		klog.V(2).Infof("Skipping build for BuildConfig %s/%s.  Pull request reference '%s' does not match configuration", buildCfg.Namespace, buildCfg.Name, ref)
+		return nil, nil, nil, false, webhook.NewRefMismatchWarning(fmt.Sprintf("skipping build: pull request reference %q does not match configuration", ref))
+	}
+
+	revision := &buildv1.SourceRevision{
+		Git: &buildv1.GitSourceRevision{
+			Commit: event.PullRequest.Head.SHA,
+		},
+	}
+	return revision, nil, nil, true, nil
+}
+
+// extractCheckRun handles a check_run event. Only the rerequested action
+// triggers a build, letting a failed check be rerun from the GitHub UI. The
+// check suite's head branch is matched against the trigger like a push.
+func (p *WebHookPlugin) extractCheckRun(buildCfg *buildv1.BuildConfig, trigger *buildv1.WebHookTrigger, body []byte) (*buildv1.SourceRevision, []corev1.EnvVar, *buildv1.DockerStrategyOptions, bool, error) {
+	var event checkRunEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, nil, nil, false, errors.NewBadRequest(err.Error())
+	}
+	if event.Action != "rerequested" {
+		klog.V(4).Infof("Skipping build for BuildConfig %s/%s: check_run action %q does not trigger builds", buildCfg.Namespace, buildCfg.Name, event.Action)
+		return nil, nil, nil, false, nil
+	}
+
+	ref := "refs/heads/" + event.CheckRun.CheckSuite.HeadBranch
+	if !webhook.TriggerRefMatches(ref, trigger, buildCfg) {
+		klog.V(2).Infof("Skipping build for BuildConfig %s/%s.  Branch reference from '%s' does not match configuration", buildCfg.Namespace, buildCfg.Name, ref)
+		return nil, nil, nil, false, webhook.NewRefMismatchWarning(fmt.Sprintf("skipping build: branch reference %q does not match configuration", ref))
+	}
+
+	revision := &buildv1.SourceRevision{
+		Git: &buildv1.GitSourceRevision{
+			Commit: event.CheckRun.HeadSHA,
+		},
+	}
+	return revision, nil, nil, true, nil
+}
+
+// allowedEvents returns the GitHub event types enabled for the build
+// configuration. Pings are always answered and are not listed here.
+func allowedEvents(buildCfg *buildv1.BuildConfig) sets.String {
+	value, ok := buildCfg.Annotations[AllowedEventsAnnotation]
+	if !ok {
+		return sets.NewString("push")
+	}
+	events := sets.NewString()
+	for _, event := range strings.Split(value, ",") {
+		if event = strings.TrimSpace(event); len(event) > 0 {
+			events.Insert(event)
+		}
+	}
+	return events
+}
+
 // GetTriggers retrieves the WebHookTriggers for this webhook type (if any)
 func (p *WebHookPlugin) GetTriggers(buildConfig *buildv1.BuildConfig) ([]*buildv1.WebHookTrigger, error) {
 	triggers := buildutil.FindTriggerPolicy(buildv1.GitHubWebHookBuildTriggerType, buildConfig)
@@ -10,6 +10,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	buildv1 "github.com/openshift/api/build/v1"
+
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook"
 )
 
 var mockBuildStrategy = buildv1.BuildStrategy{
@@ -322,3 +324,73 @@ func TestExtractSkipsBuildForUnmatchedBranches(t *testing.T) {
 		t.Errorf("Expecting to not continue from this event because the branch is not for this buildConfig '%s'", context.buildCfg.Spec.Source.Git.Ref)
 	}
 }
+
+func TestExtractRejectsPullRequestEventWithoutOptIn(t *testing.T) {
+	context := setup(t, "pullrequestevent.json", "pull_request", "")
+
+	_, _, _, proceed, err := context.plugin.Extract(context.buildCfg, context.buildCfg.Spec.Triggers[0].GitHubWebHook, context.req)
+	if err == nil || !strings.Contains(err.Error(), "not enabled") {
+		t.Errorf("Expected a not enabled error without the %s annotation, got %v", AllowedEventsAnnotation, err)
+	}
+	if proceed {
+		t.Error("Expected 'proceed' return value to be 'false'")
+	}
+}
+
+func TestExtractProvidesValidBuildForAPullRequestEvent(t *testing.T) {
+	context := setup(t, "pullrequestevent.json", "pull_request", "")
+	context.buildCfg.Annotations = map[string]string{AllowedEventsAnnotation: "push, pull_request"}
+
+	revision, _, _, proceed, err := context.plugin.Extract(context.buildCfg, context.buildCfg.Spec.Triggers[0].GitHubWebHook, context.req)
+	if err != nil {
+		t.Errorf("Error while extracting build info: %v", err)
+	}
+	if !proceed {
+		t.Error("Expected 'proceed' return value to be 'true'")
+	}
+	if revision == nil || revision.Git == nil || revision.Git.Commit != "9bdc3c5c25e1a6e44b1f5771e4e0c1e7a4d6e43c" {
+		t.Errorf("Expected the pull request head sha as the revision commit, got %#v", revision)
+	}
+}
+
+func TestExtractSkipsBuildForIgnoredPullRequestActions(t *testing.T) {
+	context := setup(t, "pullrequestevent-closed.json", "pull_request", "")
+	context.buildCfg.Annotations = map[string]string{AllowedEventsAnnotation: "push,pull_request"}
+
+	_, _, _, proceed, err := context.plugin.Extract(context.buildCfg, context.buildCfg.Spec.Triggers[0].GitHubWebHook, context.req)
+	if err != nil {
+		t.Errorf("Error while extracting build info: %v", err)
+	}
+	if proceed {
+		t.Error("Expected 'proceed' return value to be 'false' for a closed pull request")
+	}
+}
+
+func TestExtractProvidesValidBuildForACheckRunRerequest(t *testing.T) {
+	context := setup(t, "checkrunevent.json", "check_run", "master")
+	context.buildCfg.Annotations = map[string]string{AllowedEventsAnnotation: "push,check_run"}
+
+	revision, _, _, proceed, err := context.plugin.Extract(context.buildCfg, context.buildCfg.Spec.Triggers[0].GitHubWebHook, context.req)
+	if err != nil {
+		t.Errorf("Error while extracting build info: %v", err)
+	}
+	if !proceed {
+		t.Error("Expected 'proceed' return value to be 'true'")
+	}
+	if revision == nil || revision.Git == nil || revision.Git.Commit != "9bdc3c5c25e1a6e44b1f5771e4e0c1e7a4d6e43c" {
+		t.Errorf("Expected the check run head sha as the revision commit, got %#v", revision)
+	}
+}
+
+func TestExtractSkipsCheckRunForUnmatchedBranches(t *testing.T) {
+	context := setup(t, "checkrunevent.json", "check_run", "wrongref")
+	context.buildCfg.Annotations = map[string]string{AllowedEventsAnnotation: "check_run"}
+
+	_, _, _, proceed, err := context.plugin.Extract(context.buildCfg, context.buildCfg.Spec.Triggers[0].GitHubWebHook, context.req)
+	if !webhook.HasCause(err, webhook.CauseRefMismatch) {
+		t.Errorf("Expected a RefMismatch warning, got: %v", err)
+	}
+	if proceed {
+		t.Error("Expected 'proceed' return value to be 'false' for a check run on another branch")
+	}
+}
@@ -57,9 +57,9 @@ func (p *WebHookPlugin) Extract(buildCfg *buildv1.BuildConfig, trigger *buildv1.
 	if err = json.Unmarshal(body, &event); err != nil {
 		return revision, envvars, dockerStrategyOptions, proceed, errors.NewBadRequest(err.Error())
 	}
-	if !webhook.GitRefMatches(event.Ref, webhook.DefaultConfigRef, &buildCfg.Spec.Source) {
+	if !webhook.TriggerRefMatches(event.Ref, trigger, buildCfg) {
 		klog.V(2).Infof("Skipping build for BuildConfig %s/%s.  Branch reference from '%s' does not match configuration", buildCfg.Namespace, buildCfg.Name, event)
-		return revision, envvars, dockerStrategyOptions, proceed, err
+		return revision, envvars, dockerStrategyOptions, proceed, webhook.NewRefMismatchWarning(fmt.Sprintf("skipping build: branch reference %q does not match configuration", event.Ref))
 	}
 
 	lastCommit := event.Commits[len(event.Commits)-1]
@@ -12,6 +12,7 @@ import (
 	kcoreclient "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	buildv1 "github.com/openshift/api/build/v1"
+	buildapiv1 "github.com/openshift/openshift-apiserver/pkg/build/apis/build/v1"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook/bitbucket"
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/webhook/generic"
@@ -176,6 +177,36 @@ func TestWebHookEventNoRef(t *testing.T) {
 	}
 }
 
+func TestTriggerRefMatchesAllowedRefs(t *testing.T) {
+	buildConfig := newBuildConfig()
+	buildConfig.Spec.Source = *newBuildSource("master")
+	trigger := buildConfig.Spec.Triggers[3].GitHubWebHook
+	buildConfig.Annotations = map[string]string{
+		buildapiv1.WebHookAllowedRefsAnnotation: `[{"trigger":3,"hook":"github","allowedRefs":["main","release-*"]}]`,
+	}
+
+	for eventRef, expected := range map[string]bool{
+		"refs/heads/main":        true,
+		"release-4.16":           true,
+		"refs/heads/release-1.0": true,
+		"master":                 false,
+		"feature/main":           false,
+	} {
+		if matched := webhook.TriggerRefMatches(eventRef, trigger, buildConfig); matched != expected {
+			t.Errorf("%s: expected match=%t, got %t", eventRef, expected, matched)
+		}
+	}
+
+	// a trigger without allowedRefs keeps the single-ref source comparison
+	unrestricted := buildConfig.Spec.Triggers[0].GenericWebHook
+	if !webhook.TriggerRefMatches("refs/heads/master", unrestricted, buildConfig) {
+		t.Errorf("Expected an unrestricted trigger to match the source ref")
+	}
+	if webhook.TriggerRefMatches("refs/heads/main", unrestricted, buildConfig) {
+		t.Errorf("Expected an unrestricted trigger to reject other refs")
+	}
+}
+
 func TestFindTriggerPolicyWebHookError(t *testing.T) {
 	buildConfig := &buildv1.BuildConfig{}
 	plugins := []webhook.Plugin{
@@ -5,6 +5,7 @@ import (
 	"crypto/hmac"
 	"errors"
 	"net/http"
+	"path"
 	"strings"
 
 	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/apiserverbuildutil"
@@ -16,6 +17,7 @@ import (
 	"k8s.io/klog/v2"
 
 	buildv1 "github.com/openshift/api/build/v1"
+	buildapiv1 "github.com/openshift/openshift-apiserver/pkg/build/apis/build/v1"
 )
 
 const (
@@ -53,6 +55,53 @@ func GitRefMatches(eventRef, configRef string, buildSource *buildv1.BuildSource)
 	return configRef == eventRef
 }
 
+// TriggerRefMatches determines if the ref from a webhook event is allowed to
+// instantiate a build through the given trigger. Triggers that restrict refs
+// admit the event when it matches one of their branch names or glob patterns;
+// triggers without restrictions fall back to the single-ref comparison
+// against the build configuration's source.
+func TriggerRefMatches(eventRef string, trigger *buildv1.WebHookTrigger, buildConfig *buildv1.BuildConfig) bool {
+	allowedRefs := buildapiv1.WebHookAllowedRefsForTrigger(buildConfig, trigger)
+	if len(allowedRefs) == 0 {
+		return GitRefMatches(eventRef, DefaultConfigRef, &buildConfig.Spec.Source)
+	}
+
+	eventRef = strings.TrimPrefix(eventRef, refPrefix)
+	for _, allowed := range allowedRefs {
+		allowed = strings.TrimPrefix(allowed, refPrefix)
+		if matched, err := path.Match(allowed, eventRef); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// TriggerRestrictsRefs reports whether the trigger carries an allowedRefs
+// restriction. Events whose refs cannot meaningfully be compared against the
+// build configuration's source ref, such as pull request refs, are only
+// filtered when a restriction is configured.
+func TriggerRestrictsRefs(trigger *buildv1.WebHookTrigger, buildConfig *buildv1.BuildConfig) bool {
+	return len(buildapiv1.WebHookAllowedRefsForTrigger(buildConfig, trigger)) > 0
+}
+
+// Machine-readable causes attached to Status.Details.Causes of webhook
+// responses, so that callers can tell a misconfigured hook apart from a
+// transient failure without parsing the message text.
+const (
+	// CauseSecretMismatch indicates that no enabled webhook trigger accepted
+	// the secret in the invocation URL.
+	CauseSecretMismatch metav1.CauseType = "SecretMismatch"
+	// CauseRefMismatch indicates that the event referenced a ref the build
+	// configuration does not build, so no build was started.
+	CauseRefMismatch metav1.CauseType = "RefMismatch"
+	// CausePayloadInvalid indicates that the event payload could not be
+	// parsed or described an event the webhook does not handle.
+	CausePayloadInvalid metav1.CauseType = "PayloadInvalid"
+	// CauseBuildConfigPaused indicates that the build configuration is paused
+	// and does not accept new builds.
+	CauseBuildConfigPaused metav1.CauseType = "BuildConfigPaused"
+)
+
 // NewWarning returns an StatusError object with a http.StatusOK (200) code.
 func NewWarning(message string) *kerrors.StatusError {
 	return &kerrors.StatusError{ErrStatus: metav1.Status{
@@ -62,6 +111,38 @@ func NewWarning(message string) *kerrors.StatusError {
 	}}
 }
 
+// NewRefMismatchWarning returns a success response explaining that no build
+// was started because the event ref did not match the build configuration,
+// with a RefMismatch cause attached for programmatic consumers.
+func NewRefMismatchWarning(message string) *kerrors.StatusError {
+	return WithCause(NewWarning(message), CauseRefMismatch, message)
+}
+
+// WithCause attaches a machine-readable cause to a webhook status response
+// and returns the response.
+func WithCause(err *kerrors.StatusError, cause metav1.CauseType, message string) *kerrors.StatusError {
+	if err.ErrStatus.Details == nil {
+		err.ErrStatus.Details = &metav1.StatusDetails{}
+	}
+	err.ErrStatus.Details.Causes = append(err.ErrStatus.Details.Causes, metav1.StatusCause{Type: cause, Message: message})
+	return err
+}
+
+// HasCause reports whether the error is a webhook status response carrying
+// the given machine-readable cause.
+func HasCause(err error, cause metav1.CauseType) bool {
+	statusErr, ok := err.(*kerrors.StatusError)
+	if !ok || statusErr.ErrStatus.Details == nil {
+		return false
+	}
+	for _, c := range statusErr.ErrStatus.Details.Causes {
+		if c.Type == cause {
+			return true
+		}
+	}
+	return false
+}
+
 // CheckSecret tests the user provided secret against the secrets for the webhook triggers, if a match is found
 // then the corresponding webhook trigger is returned.
 func CheckSecret(ctx context.Context, namespace, userSecret string, triggers []*buildv1.WebHookTrigger, secretsClient kubernetes.SecretsGetter) (*buildv1.WebHookTrigger, error) {
@@ -122,6 +203,17 @@ func GenerateBuildTriggerInfo(revision *buildv1.SourceRevision, hookType string)
 					},
 				},
 			})
+	case hookType == "gitea":
+		// gitea hooks are authenticated by github webhook triggers, so the
+		// cause is recorded the same way
+		buildTriggerCauses = append(buildTriggerCauses,
+			buildv1.BuildTriggerCause{
+				Message: apiserverbuildutil.BuildTriggerCauseGiteaMsg,
+				GitHubWebHook: &buildv1.GitHubWebHookCause{
+					Revision: revision,
+					Secret:   hiddenSecret,
+				},
+			})
 	case hookType == "bitbucket":
 		buildTriggerCauses = append(buildTriggerCauses,
 			buildv1.BuildTriggerCause{
@@ -128,5 +128,5 @@ func (o *OpenShiftAPIServer) RunAPIServer(stopCh <-chan struct{}) error {
 	}
 	setRecommendedOpenShiftAPIServerConfigDefaults(config)
 
-	return RunOpenShiftAPIServer(config, o.Authentication, o.Authorization, stopCh)
+	return RunOpenShiftAPIServer(config, absoluteConfigFile, o.Authentication, o.Authorization, stopCh)
 }
@@ -0,0 +1,67 @@
+package openshiftadmission
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission"
+)
+
+type fakeValidatingPlugin struct {
+	*admission.Handler
+	err    error
+	called bool
+}
+
+func (p *fakeValidatingPlugin) Validate(ctx context.Context, a admission.Attributes, o admission.ObjectInterfaces) error {
+	p.called = true
+	return p.err
+}
+
+func fakeAttributes() admission.Attributes {
+	return admission.NewAttributesRecord(
+		nil, nil,
+		schema.GroupVersionKind{Group: "build.openshift.io", Version: "v1", Kind: "Build"},
+		"ns", "name",
+		schema.GroupVersionResource{Group: "build.openshift.io", Version: "v1", Resource: "builds"},
+		"", admission.Create, nil, false, nil,
+	)
+}
+
+func TestWithOpenShiftPluginMetricsSkipsKubePlugins(t *testing.T) {
+	plugin := &fakeValidatingPlugin{Handler: admission.NewHandler(admission.Create)}
+	if decorated := WithOpenShiftPluginMetrics(plugin, "ResourceQuota"); decorated != admission.Interface(plugin) {
+		t.Errorf("expected kube plugins to pass through undecorated, got %T", decorated)
+	}
+	if decorated := WithOpenShiftPluginMetrics(plugin, "build.openshift.io/BuildByStrategy"); decorated == admission.Interface(plugin) {
+		t.Error("expected openshift plugins to be decorated")
+	}
+}
+
+func TestWithOpenShiftPluginMetricsDelegates(t *testing.T) {
+	denial := fmt.Errorf("denied")
+	plugin := &fakeValidatingPlugin{Handler: admission.NewHandler(admission.Create), err: denial}
+	decorated := WithOpenShiftPluginMetrics(plugin, "build.openshift.io/BuildByStrategy")
+
+	validator, ok := decorated.(admission.ValidationInterface)
+	if !ok {
+		t.Fatal("expected the decorated plugin to validate")
+	}
+	if err := validator.Validate(context.Background(), fakeAttributes(), nil); err != denial {
+		t.Errorf("expected the plugin error to pass through, got %v", err)
+	}
+	if !plugin.called {
+		t.Error("expected the wrapped plugin to be called")
+	}
+
+	// a validate-only plugin must not fail when driven through Admit
+	mutator, ok := decorated.(admission.MutationInterface)
+	if !ok {
+		t.Fatal("expected the decorated plugin to implement Admit")
+	}
+	if err := mutator.Admit(context.Background(), fakeAttributes(), nil); err != nil {
+		t.Errorf("expected Admit on a validate-only plugin to be a no-op, got %v", err)
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/openshift/library-go/pkg/quota/clusterquotamapping"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/admission/imagepolicy/originimagereferencemutators"
 	"github.com/openshift/openshift-apiserver/pkg/quota/image"
+	"github.com/openshift/openshift-apiserver/pkg/quota/objectcount"
 )
 
 type InformerAccess interface {
@@ -65,11 +66,16 @@ func NewPluginInitializer(
 	imageEvaluators := image.NewReplenishmentEvaluators(
 		nil, // for admission, we never have to list everything, so we can pass nil.
 		informers.GetOpenshiftImageInformers().Image().V1().ImageStreams(),
+		informers.GetOpenshiftImageInformers().Image().V1().Images(),
 		imageClient.ImageV1(),
 	)
 	for i := range imageEvaluators {
 		quotaRegistry.Add(imageEvaluators[i])
 	}
+	// for admission, usage is never enumerated, so no lister func is needed.
+	for _, evaluator := range objectcount.NewEvaluators(nil) {
+		quotaRegistry.Add(evaluator)
+	}
 
 	var cloudConfig []byte
 	if len(cloudConfigFile) != 0 {
@@ -12,6 +12,8 @@ import (
 	buildstrategyrestrictions "github.com/openshift/openshift-apiserver/pkg/build/apiserver/admission/strategyrestrictions"
 	imageadmission "github.com/openshift/openshift-apiserver/pkg/image/apiserver/admission/limitrange"
 	projectrequestlimit "github.com/openshift/openshift-apiserver/pkg/project/apiserver/admission/requestlimit"
+	routednsvalidation "github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/dnsvalidation"
+	routewildcardpolicy "github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/wildcardpolicy"
 )
 
 // TODO register this per apiserver or at least per process
@@ -37,6 +39,8 @@ func RegisterOpenshiftAdmissionPlugins(plugins *admission.Plugins) {
 	buildstrategyrestrictions.Register(plugins)
 	imageadmission.Register(plugins)
 	imagepolicy.Register(plugins)
+	routewildcardpolicy.Register(plugins)
+	routednsvalidation.Register(plugins)
 	quotaclusterresourcequota.Register(plugins)
 }
 
@@ -53,6 +57,8 @@ var (
 		"build.openshift.io/BuildByStrategy",
 		"image.openshift.io/ImageLimitRange",
 		"image.openshift.io/ImagePolicy",
+		"route.openshift.io/WildcardPolicy",
+		"route.openshift.io/DNSValidation",
 		"quota.openshift.io/ClusterResourceQuota",
 
 		// the rest of the kube chain goes here
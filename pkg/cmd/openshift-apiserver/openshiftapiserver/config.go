@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -28,13 +31,25 @@ import (
 	"github.com/openshift/library-go/pkg/apiserver/apiserverconfig"
 	"github.com/openshift/library-go/pkg/config/helpers"
 	"github.com/openshift/library-go/pkg/config/serving"
+	apiserverfilters "github.com/openshift/openshift-apiserver/pkg/apiserver/filters"
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/selfdiagnostics"
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/apiserverbuildutil"
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/buildgenerator"
 	"github.com/openshift/openshift-apiserver/pkg/cmd/openshift-apiserver/openshiftadmission"
 	"github.com/openshift/openshift-apiserver/pkg/cmd/openshift-apiserver/openshiftapiserver/configprocessing"
+	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/whitelist"
+	imageimporter "github.com/openshift/openshift-apiserver/pkg/image/apiserver/importer"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/labelpolicy"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registryhostname"
+	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/hostclaimpolicy"
 	"github.com/openshift/openshift-apiserver/pkg/version"
 )
 
-func NewOpenshiftAPIConfig(config *openshiftcontrolplanev1.OpenShiftAPIServerConfig, authenticationOptions *genericapiserveroptions.DelegatingAuthenticationOptions, authorizationOptions *genericapiserveroptions.DelegatingAuthorizationOptions) (*OpenshiftAPIConfig, error) {
+// NewOpenshiftAPIConfig builds the runtime config of the server from its
+// parsed configuration. configFile, when non-empty, is the path the
+// configuration was read from and enables live reload of the image import
+// policy.
+func NewOpenshiftAPIConfig(config *openshiftcontrolplanev1.OpenShiftAPIServerConfig, configFile string, authenticationOptions *genericapiserveroptions.DelegatingAuthenticationOptions, authorizationOptions *genericapiserveroptions.DelegatingAuthorizationOptions) (*OpenshiftAPIConfig, error) {
 	kubeClientConfig, err := helpers.GetKubeClientConfig(config.KubeClientConfig)
 	if err != nil {
 		return nil, err
@@ -137,6 +152,10 @@ func NewOpenshiftAPIConfig(config *openshiftcontrolplanev1.OpenShiftAPIServerCon
 	if err != nil {
 		return nil, err
 	}
+	selfdiagnostics.RegisterSyncStatusProvider(informers.SyncStatus)
+	// the namespace informer backs the project cache and authorization cache;
+	// its event times show whether their watches are still delivering
+	informers.kubernetesInformers.Core().V1().Namespaces().Informer().AddEventHandler(selfdiagnostics.EventHandler("kubernetes/*v1.Namespace"))
 
 	auditFlags := configflags.AuditFlags(&config.AuditConfig, configflags.ArgsWithPrefix(config.APIServerArguments, "audit-"))
 	auditOpt := genericapiserveroptions.NewAuditOptions()
@@ -176,6 +195,7 @@ func NewOpenshiftAPIConfig(config *openshiftcontrolplanev1.OpenShiftAPIServerCon
 	admissionOptions := genericapiserveroptions.NewAdmissionOptions()
 	admissionOptions.Decorators = admission.Decorators{
 		admission.DecoratorFunc(admissionmetrics.WithControllerMetrics),
+		admission.DecoratorFunc(openshiftadmission.WithOpenShiftPluginMetrics),
 		admission.DecoratorFunc(admissiontimeout.AdmissionTimeout{Timeout: 13 * time.Second}.WithTimeout),
 	}
 	admissionOptions.DefaultOffPlugins = sets.String{}
@@ -189,14 +209,18 @@ func NewOpenshiftAPIConfig(config *openshiftcontrolplanev1.OpenShiftAPIServerCon
 		return nil, err
 	}
 
-	var externalRegistryHostname string
-	if len(config.ImagePolicyConfig.ExternalRegistryHostnames) > 0 {
-		externalRegistryHostname = config.ImagePolicyConfig.ExternalRegistryHostnames[0]
+	registryHostnameRetriever, err := registryhostname.DefaultRegistryHostnameRetriever(kubeClientConfig, config.ImagePolicyConfig.ExternalRegistryHostnames, config.ImagePolicyConfig.InternalRegistryHostname)
+	if err != nil {
+		return nil, err
 	}
-	registryHostnameRetriever, err := registryhostname.DefaultRegistryHostnameRetriever(kubeClientConfig, externalRegistryHostname, config.ImagePolicyConfig.InternalRegistryHostname)
+	// the retriever and the whitelister are wrapped so a config reload can
+	// swap their delegates without rebuilding the image REST storage
+	mutableRegistryHostnameRetriever := registryhostname.NewMutableRegistryHostnameRetriever(registryHostnameRetriever)
+	registryWhitelister, err := newRegistryWhitelister(config.ImagePolicyConfig.AllowedRegistriesForImport, mutableRegistryHostnameRetriever)
 	if err != nil {
 		return nil, err
 	}
+	mutableRegistryWhitelister := whitelist.NewMutableRegistryWhitelister(registryWhitelister)
 
 	var caData []byte
 	if len(config.ImagePolicyConfig.AdditionalTrustedCA) != 0 {
@@ -208,6 +232,144 @@ func NewOpenshiftAPIConfig(config *openshiftcontrolplanev1.OpenShiftAPIServerCon
 		}
 	}
 
+	importRegistryRateLimits, err := imageimporter.ParseRegistryLimits(config.APIServerArguments["image-import-registry-rate-limit"])
+	if err != nil {
+		return nil, err
+	}
+
+	buildInstantiateHooks, err := buildgenerator.ParseBuildHooks(config.APIServerArguments["build-instantiate-hook"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid build-instantiate-hook: %v", err)
+	}
+
+	buildEnvRedactionPatterns, err := apiserverbuildutil.ParseEnvRedactionPatterns(config.APIServerArguments["build-env-redaction-patterns"])
+	if err != nil {
+		return nil, err
+	}
+
+	// the build log TLS overrides must reference readable files so a typo fails
+	// startup instead of breaking log streaming at request time
+	buildLogCAFile, err := singleFileArgument(config.APIServerArguments, "build-log-ca-file")
+	if err != nil {
+		return nil, err
+	}
+	buildLogClientCertFile, err := singleFileArgument(config.APIServerArguments, "build-log-client-cert-file")
+	if err != nil {
+		return nil, err
+	}
+	buildLogClientKeyFile, err := singleFileArgument(config.APIServerArguments, "build-log-client-key-file")
+	if err != nil {
+		return nil, err
+	}
+	if (len(buildLogClientCertFile) == 0) != (len(buildLogClientKeyFile) == 0) {
+		return nil, fmt.Errorf("build-log-client-cert-file and build-log-client-key-file must be specified together")
+	}
+
+	var buildServiceAccountWaitTimeout time.Duration
+	if waitTimeoutSlice := config.APIServerArguments["build-service-account-wait-timeout"]; len(waitTimeoutSlice) == 1 {
+		buildServiceAccountWaitTimeout, err = time.ParseDuration(waitTimeoutSlice[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid build-service-account-wait-timeout: %v", err)
+		}
+		if buildServiceAccountWaitTimeout < 0 {
+			return nil, fmt.Errorf("build-service-account-wait-timeout must not be negative")
+		}
+	} else if len(waitTimeoutSlice) > 1 {
+		return nil, fmt.Errorf("build-service-account-wait-timeout may only be specified once")
+	}
+
+	var buildScopedPushCredentialExpiration time.Duration
+	if expirationSlice := config.APIServerArguments["build-scoped-push-credential-expiration"]; len(expirationSlice) == 1 {
+		buildScopedPushCredentialExpiration, err = time.ParseDuration(expirationSlice[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid build-scoped-push-credential-expiration: %v", err)
+		}
+		if buildScopedPushCredentialExpiration < 0 {
+			return nil, fmt.Errorf("build-scoped-push-credential-expiration must not be negative")
+		}
+	} else if len(expirationSlice) > 1 {
+		return nil, fmt.Errorf("build-scoped-push-credential-expiration may only be specified once")
+	}
+
+	var maxImageStreamTagHistory int
+	if maxTagHistorySlice := config.APIServerArguments["imagestream-max-tag-history"]; len(maxTagHistorySlice) == 1 {
+		maxImageStreamTagHistory, err = strconv.Atoi(maxTagHistorySlice[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid imagestream-max-tag-history: %v", err)
+		}
+		if maxImageStreamTagHistory < 0 {
+			return nil, fmt.Errorf("imagestream-max-tag-history must not be negative")
+		}
+	} else if len(maxTagHistorySlice) > 1 {
+		return nil, fmt.Errorf("imagestream-max-tag-history may only be specified once")
+	}
+
+	var importLabelPolicy labelpolicy.Policy
+	for _, value := range config.APIServerArguments["image-import-required-labels"] {
+		for _, label := range strings.Split(value, ",") {
+			label = strings.TrimSpace(label)
+			if len(label) == 0 {
+				return nil, fmt.Errorf("image-import-required-labels must not contain empty label names")
+			}
+			importLabelPolicy.RequiredLabels = append(importLabelPolicy.RequiredLabels, label)
+		}
+	}
+	importLabelPolicy.Mode = labelpolicy.ModeWarn
+	if modeSlice := config.APIServerArguments["image-import-required-labels-policy"]; len(modeSlice) == 1 {
+		importLabelPolicy.Mode, err = labelpolicy.ParseMode(modeSlice[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid image-import-required-labels-policy: %v", err)
+		}
+	} else if len(modeSlice) > 1 {
+		return nil, fmt.Errorf("image-import-required-labels-policy may only be specified once")
+	}
+
+	var imageImportScannerWebhookURL string
+	if scannerURLSlice := config.APIServerArguments["image-import-scanner-webhook-url"]; len(scannerURLSlice) == 1 {
+		scannerURL, err := url.Parse(scannerURLSlice[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid image-import-scanner-webhook-url: %v", err)
+		}
+		if !scannerURL.IsAbs() || len(scannerURL.Host) == 0 {
+			return nil, fmt.Errorf("image-import-scanner-webhook-url must be an absolute URL")
+		}
+		imageImportScannerWebhookURL = scannerURLSlice[0]
+	} else if len(scannerURLSlice) > 1 {
+		return nil, fmt.Errorf("image-import-scanner-webhook-url may only be specified once")
+	}
+
+	var imageImportCredentialProviderConfigFile string
+	if providerConfigSlice := config.APIServerArguments["image-import-credential-provider-config"]; len(providerConfigSlice) == 1 {
+		if _, err := imageimporter.ReadCredentialProviderConfig(providerConfigSlice[0]); err != nil {
+			return nil, fmt.Errorf("invalid image-import-credential-provider-config: %v", err)
+		}
+		imageImportCredentialProviderConfigFile = providerConfigSlice[0]
+	} else if len(providerConfigSlice) > 1 {
+		return nil, fmt.Errorf("image-import-credential-provider-config may only be specified once")
+	}
+
+	var serveStaleReadsOnStorageError bool
+	if staleReadsSlice := config.APIServerArguments["serve-stale-reads-on-storage-error"]; len(staleReadsSlice) == 1 {
+		serveStaleReadsOnStorageError, err = strconv.ParseBool(staleReadsSlice[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid serve-stale-reads-on-storage-error: %v", err)
+		}
+	} else if len(staleReadsSlice) > 1 {
+		return nil, fmt.Errorf("serve-stale-reads-on-storage-error may only be specified once")
+	}
+
+	routeHostClaimPolicyFile, err := singleFileArgument(config.APIServerArguments, "route-host-claim-policy-file")
+	if err != nil {
+		return nil, err
+	}
+	var routeHostClaimPolicy *hostclaimpolicy.Evaluator
+	if len(routeHostClaimPolicyFile) > 0 {
+		routeHostClaimPolicy, err = hostclaimpolicy.ReadPolicyFile(routeHostClaimPolicyFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid route-host-claim-policy-file: %v", err)
+		}
+	}
+
 	subjectLocator := NewSubjectLocator(informers.GetKubernetesInformers().Rbac().V1())
 	projectAuthorizationCache := NewProjectAuthorizationCache(
 		subjectLocator,
@@ -225,34 +387,78 @@ func NewOpenshiftAPIConfig(config *openshiftcontrolplanev1.OpenShiftAPIServerCon
 	ret := &OpenshiftAPIConfig{
 		GenericConfig: genericConfig,
 		ExtraConfig: OpenshiftAPIExtraConfig{
-			InformerStart:                      informers.Start,
-			KubeAPIServerClientConfig:          kubeClientConfig,
-			KubeInformers:                      kubeInformers, // TODO remove this and use the one from the genericconfig
-			QuotaInformers:                     informers.quotaInformers,
-			SecurityInformers:                  informers.securityInformers,
-			OperatorInformers:                  informers.operatorInformers,
-			RuleResolver:                       ruleResolver,
-			SubjectLocator:                     subjectLocator,
-			RegistryHostnameRetriever:          registryHostnameRetriever,
-			AllowedRegistriesForImport:         config.ImagePolicyConfig.AllowedRegistriesForImport,
-			MaxImagesBulkImportedPerRepository: config.ImagePolicyConfig.MaxImagesBulkImportedPerRepository,
-			AdditionalTrustedCA:                caData,
-			RouteAllocator:                     routeAllocator,
-			ProjectAuthorizationCache:          projectAuthorizationCache,
-			ProjectCache:                       projectCache,
-			ProjectRequestTemplate:             config.ProjectConfig.ProjectRequestTemplate,
-			ProjectRequestMessage:              config.ProjectConfig.ProjectRequestMessage,
-			ClusterQuotaMappingController:      clusterQuotaMappingController,
-			RESTMapper:                         restMapper,
+			InformerStart:                           informers.Start,
+			KubeAPIServerClientConfig:               kubeClientConfig,
+			KubeInformers:                           kubeInformers, // TODO remove this and use the one from the genericconfig
+			QuotaInformers:                          informers.quotaInformers,
+			SecurityInformers:                       informers.securityInformers,
+			OperatorInformers:                       informers.operatorInformers,
+			RuleResolver:                            ruleResolver,
+			SubjectLocator:                          subjectLocator,
+			RegistryHostnameRetriever:               mutableRegistryHostnameRetriever,
+			RegistryWhitelister:                     mutableRegistryWhitelister,
+			ConfigFile:                              configFile,
+			AllowedRegistriesForImport:              config.ImagePolicyConfig.AllowedRegistriesForImport,
+			MaxImagesBulkImportedPerRepository:      config.ImagePolicyConfig.MaxImagesBulkImportedPerRepository,
+			MaxImageStreamTagHistory:                maxImageStreamTagHistory,
+			ImageImportLabelPolicy:                  importLabelPolicy,
+			ImportRegistryRateLimits:                importRegistryRateLimits,
+			ImageImportScannerWebhookURL:            imageImportScannerWebhookURL,
+			ImageImportCredentialProviderConfigFile: imageImportCredentialProviderConfigFile,
+			ServeStaleReadsOnStorageError:           serveStaleReadsOnStorageError,
+			TemplateInstanceAllowedKinds:            config.APIServerArguments["template-instance-allowed-kinds"],
+			BuildEnvRedactionPatterns:               buildEnvRedactionPatterns,
+			BuildLogCAFile:                          buildLogCAFile,
+			BuildLogClientCertFile:                  buildLogClientCertFile,
+			BuildLogClientKeyFile:                   buildLogClientKeyFile,
+			BuildServiceAccountWaitTimeout:          buildServiceAccountWaitTimeout,
+			BuildScopedPushCredentialExpiration:     buildScopedPushCredentialExpiration,
+			BuildInstantiateHooks:                   buildInstantiateHooks,
+			AdditionalTrustedCA:                     caData,
+			RouteAllocator:                          routeAllocator,
+			RouteHostClaimPolicy:                    routeHostClaimPolicy,
+			ProjectAuthorizationCache:               projectAuthorizationCache,
+			ProjectCache:                            projectCache,
+			ProjectRequestTemplate:                  config.ProjectConfig.ProjectRequestTemplate,
+			ProjectRequestMessage:                   config.ProjectConfig.ProjectRequestMessage,
+			ClusterQuotaMappingController:           clusterQuotaMappingController,
+			RESTMapper:                              restMapper,
 		},
 	}
 
 	return ret, ret.ExtraConfig.Validate()
 }
 
+// singleFileArgument returns the value of a file-valued API server argument,
+// verifying the file is readable.
+func singleFileArgument(args map[string][]string, name string) (string, error) {
+	values := args[name]
+	switch len(values) {
+	case 0:
+		return "", nil
+	case 1:
+		if _, err := ioutil.ReadFile(values[0]); err != nil {
+			return "", fmt.Errorf("failed to read %s %q: %v", name, values[0], err)
+		}
+		return values[0], nil
+	default:
+		return "", fmt.Errorf("%s may only be specified once", name)
+	}
+}
+
 func OpenshiftHandlerChain(apiHandler http.Handler, genericConfig *genericapiserver.Config) http.Handler {
+	// enforce the fieldValidation query parameter for resources whose
+	// handlers decode loosely
+	handler := apiserverfilters.WithFieldValidation(apiHandler, genericConfig.MaxRequestBodyBytes)
+
+	// let build log clients resume an interrupted stream from a line cursor
+	handler = apiserverfilters.WithBuildLogSinceLine(handler)
+
+	// attribute deprecated API usage to the client that caused it
+	handler = apiserverfilters.WithRequestUserAgent(handler)
+
 	// this is the normal kube handler chain
-	handler := genericapiserver.DefaultBuildHandlerChain(apiHandler, genericConfig)
+	handler = genericapiserver.DefaultBuildHandlerChain(handler, genericConfig)
 
 	handler = apiserverconfig.WithCacheControl(handler, "no-store") // protected endpoints should not be cached
 
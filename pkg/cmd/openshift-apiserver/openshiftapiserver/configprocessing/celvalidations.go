@@ -0,0 +1,68 @@
+package configprocessing
+
+import (
+	"github.com/go-openapi/spec"
+
+	openapicommon "k8s.io/kube-openapi/pkg/common"
+
+	openapigenerated "github.com/openshift/openshift-apiserver/pkg/openapi"
+)
+
+// celValidationsExtension is the OpenAPI extension that carries CEL rules for
+// a schema. Clients such as kubectl --validate and IDE schema checkers
+// evaluate the rules locally before a request is submitted.
+const celValidationsExtension = "x-kubernetes-validations"
+
+// celValidationRule mirrors the structure of one x-kubernetes-validations
+// entry.
+type celValidationRule struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message,omitempty"`
+}
+
+// celValidations holds CEL rules for cheap invariants that server-side
+// validation enforces anyway, keyed by OpenAPI definition name. The rules are
+// advisory for clients; server-side validation in the respective apis
+// packages remains authoritative, so a rule here must never be stricter than
+// its server-side counterpart.
+var celValidations = map[string][]celValidationRule{
+	"github.com/openshift/api/route/v1.TLSConfig": {
+		{
+			Rule:    "self.termination != 'passthrough' || (!has(self.certificate) && !has(self.key) && !has(self.caCertificate) && !has(self.destinationCACertificate))",
+			Message: "passthrough termination does not support certificates",
+		},
+		{
+			Rule:    "self.termination != 'edge' || !has(self.destinationCACertificate)",
+			Message: "edge termination does not support destination certificates",
+		},
+		{
+			Rule:    "self.termination != 'passthrough' || !has(self.insecureEdgeTerminationPolicy) || self.insecureEdgeTerminationPolicy in ['None', 'Redirect']",
+			Message: "acceptable insecureEdgeTerminationPolicy values for passthrough termination are None, Redirect, or empty",
+		},
+	},
+	"github.com/openshift/api/build/v1.BuildConfigSpec": {
+		{
+			Rule:    "!has(self.triggers) || self.triggers.filter(t, t.type == 'ImageChange' && (!has(t.imageChange) || !has(t.imageChange.from))).size() <= 1",
+			Message: "only one ImageChange trigger may omit imageChange.from",
+		},
+	},
+}
+
+// getOpenAPIDefinitionsWithCELValidations returns the generated OpenAPI
+// definitions with the x-kubernetes-validations extension attached to the
+// schemas listed in celValidations.
+func getOpenAPIDefinitionsWithCELValidations(ref openapicommon.ReferenceCallback) map[string]openapicommon.OpenAPIDefinition {
+	definitions := openapigenerated.GetOpenAPIDefinitions(ref)
+	for name, rules := range celValidations {
+		definition, ok := definitions[name]
+		if !ok {
+			continue
+		}
+		if definition.Schema.Extensions == nil {
+			definition.Schema.Extensions = spec.Extensions{}
+		}
+		definition.Schema.Extensions[celValidationsExtension] = rules
+		definitions[name] = definition
+	}
+	return definitions
+}
@@ -0,0 +1,44 @@
+package configprocessing
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-openapi/spec"
+)
+
+func TestCELValidationsAttachToKnownDefinitions(t *testing.T) {
+	dummyRef := func(name string) spec.Ref { return spec.MustCreateRef("#/definitions/dummy") }
+	definitions := getOpenAPIDefinitionsWithCELValidations(dummyRef)
+
+	for name, rules := range celValidations {
+		definition, ok := definitions[name]
+		if !ok {
+			t.Errorf("celValidations references definition %s which does not exist in the generated schemas", name)
+			continue
+		}
+		attached, ok := definition.Schema.Extensions[celValidationsExtension]
+		if !ok {
+			t.Errorf("definition %s is missing the %s extension", name, celValidationsExtension)
+			continue
+		}
+		data, err := json.Marshal(attached)
+		if err != nil {
+			t.Errorf("definition %s: the %s extension does not marshal: %v", name, celValidationsExtension, err)
+			continue
+		}
+		var roundTripped []celValidationRule
+		if err := json.Unmarshal(data, &roundTripped); err != nil {
+			t.Errorf("definition %s: the %s extension does not round trip: %v", name, celValidationsExtension, err)
+			continue
+		}
+		if len(roundTripped) != len(rules) {
+			t.Errorf("definition %s: expected %d rules, got %d", name, len(rules), len(roundTripped))
+		}
+		for _, rule := range roundTripped {
+			if len(rule.Rule) == 0 {
+				t.Errorf("definition %s: rule with empty expression", name)
+			}
+		}
+	}
+}
@@ -13,7 +13,6 @@ import (
 	openapicommon "k8s.io/kube-openapi/pkg/common"
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
 
-	openapigenerated "github.com/openshift/openshift-apiserver/pkg/openapi"
 	"github.com/openshift/openshift-apiserver/pkg/version"
 )
 
@@ -30,7 +29,7 @@ func DefaultOpenAPIConfig() *openapicommon.Config {
 	defNamer := apiserverendpointsopenapi.NewDefinitionNamer(legacyscheme.Scheme, extensionsapiserver.Scheme, aggregatorscheme.Scheme)
 	return &openapicommon.Config{
 		ProtocolList:      []string{"https"},
-		GetDefinitions:    openapigenerated.GetOpenAPIDefinitions,
+		GetDefinitions:    getOpenAPIDefinitionsWithCELValidations,
 		IgnorePrefixes:    []string{"/swaggerapi", "/healthz", "/controllers", "/metrics", "/version/openshift", "/brokers"},
 		GetDefinitionName: defNamer.GetDefinitionName,
 		GetOperationIDAndTags: func(r *restful.Route) (string, []string, error) {
@@ -0,0 +1,175 @@
+package openshiftapiserver
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+
+	configv1 "github.com/openshift/api/config/v1"
+	openshiftcontrolplanev1 "github.com/openshift/api/openshiftcontrolplane/v1"
+	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/whitelist"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registryhostname"
+)
+
+var imagePolicyConfigGeneration = metrics.NewGauge(
+	&metrics.GaugeOpts{
+		Name: "openshift_apiserver_image_policy_config_generation",
+		Help: "Generation of the image import policy configuration currently in effect. Starts at 1 and increases by one for every successful live reload.",
+	},
+)
+
+func init() {
+	legacyregistry.MustRegister(imagePolicyConfigGeneration)
+}
+
+// imagePolicyReloadInterval is how often the configuration file is reread for
+// image import policy changes.
+const imagePolicyReloadInterval = 30 * time.Second
+
+// newRegistryWhitelister mirrors the whitelister the image API server would
+// build itself for the given import policy.
+func newRegistryWhitelister(allowedRegistries openshiftcontrolplanev1.AllowedRegistries, registryHostnameRetriever registryhostname.RegistryHostnameRetriever) (whitelist.RegistryWhitelister, error) {
+	if len(allowedRegistries) == 0 {
+		return whitelist.WhitelistAllRegistries(context.TODO()), nil
+	}
+	whitelister, err := whitelist.NewRegistryWhitelister(allowedRegistries, registryHostnameRetriever)
+	if err != nil {
+		return nil, fmt.Errorf("error building registry whitelister: %v", err)
+	}
+	return whitelister, nil
+}
+
+// imagePolicyState holds the configuration fields the reloader watches.
+type imagePolicyState struct {
+	AllowedRegistriesForImport openshiftcontrolplanev1.AllowedRegistries
+	InternalRegistryHostname   string
+	ExternalRegistryHostnames  []string
+}
+
+func imagePolicyStateFromConfig(config *openshiftcontrolplanev1.OpenShiftAPIServerConfig) imagePolicyState {
+	return imagePolicyState{
+		AllowedRegistriesForImport: config.ImagePolicyConfig.AllowedRegistriesForImport,
+		InternalRegistryHostname:   config.ImagePolicyConfig.InternalRegistryHostname,
+		ExternalRegistryHostnames:  config.ImagePolicyConfig.ExternalRegistryHostnames,
+	}
+}
+
+// imagePolicyReloader rereads the server configuration file and swaps the
+// delegates of the mutable whitelister and hostname retriever when the image
+// import policy changes, so policy updates take effect without a restart.
+type imagePolicyReloader struct {
+	configFile       string
+	kubeClientConfig *restclient.Config
+
+	whitelister       *whitelist.MutableRegistryWhitelister
+	hostnameRetriever *registryhostname.MutableRegistryHostnameRetriever
+
+	decoder    runtime.Decoder
+	current    imagePolicyState
+	generation int64
+}
+
+// newImagePolicyReloader returns a reloader, or nil if live reload is not
+// possible because the config file path is unknown or the whitelister and
+// retriever in use are not swappable.
+func newImagePolicyReloader(c *OpenshiftAPIExtraConfig) (*imagePolicyReloader, error) {
+	if len(c.ConfigFile) == 0 {
+		return nil, nil
+	}
+	whitelister, ok := c.RegistryWhitelister.(*whitelist.MutableRegistryWhitelister)
+	if !ok {
+		return nil, nil
+	}
+	hostnameRetriever, ok := c.RegistryHostnameRetriever.(*registryhostname.MutableRegistryHostnameRetriever)
+	if !ok {
+		return nil, nil
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(openshiftcontrolplanev1.Install(scheme))
+	codecs := serializer.NewCodecFactory(scheme)
+
+	reloader := &imagePolicyReloader{
+		configFile:        c.ConfigFile,
+		kubeClientConfig:  c.KubeAPIServerClientConfig,
+		whitelister:       whitelister,
+		hostnameRetriever: hostnameRetriever,
+		decoder:           codecs.UniversalDecoder(openshiftcontrolplanev1.GroupVersion, configv1.GroupVersion),
+		generation:        1,
+	}
+	// record the state in effect so only a real change triggers a swap
+	config, err := reloader.readConfig()
+	if err != nil {
+		return nil, err
+	}
+	reloader.current = imagePolicyStateFromConfig(config)
+	return reloader, nil
+}
+
+// Run periodically rereads the configuration until stopCh closes.
+func (r *imagePolicyReloader) Run(stopCh <-chan struct{}) {
+	imagePolicyConfigGeneration.Set(float64(r.generation))
+	go wait.Until(func() {
+		if err := r.reload(); err != nil {
+			utilruntime.HandleError(fmt.Errorf("unable to reload image import policy from %s: %v", r.configFile, err))
+		}
+	}, imagePolicyReloadInterval, stopCh)
+}
+
+func (r *imagePolicyReloader) readConfig() (*openshiftcontrolplanev1.OpenShiftAPIServerConfig, error) {
+	configContent, err := ioutil.ReadFile(r.configFile)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := runtime.Decode(r.decoder, configContent)
+	if err != nil {
+		return nil, err
+	}
+	config, ok := obj.(*openshiftcontrolplanev1.OpenShiftAPIServerConfig)
+	if !ok {
+		return nil, fmt.Errorf("unexpected config type %T", obj)
+	}
+	return config, nil
+}
+
+func (r *imagePolicyReloader) reload() error {
+	config, err := r.readConfig()
+	if err != nil {
+		return err
+	}
+
+	desired := imagePolicyStateFromConfig(config)
+	if reflect.DeepEqual(desired, r.current) {
+		return nil
+	}
+
+	registryHostnameRetriever, err := registryhostname.DefaultRegistryHostnameRetriever(r.kubeClientConfig, desired.ExternalRegistryHostnames, desired.InternalRegistryHostname)
+	if err != nil {
+		return err
+	}
+	registryWhitelister, err := newRegistryWhitelister(desired.AllowedRegistriesForImport, r.hostnameRetriever)
+	if err != nil {
+		return err
+	}
+
+	// swap the retriever first so a whitelister admitting against the
+	// integrated registry hostname sees the new value
+	r.hostnameRetriever.Swap(registryHostnameRetriever)
+	r.whitelister.Swap(registryWhitelister)
+	r.current = desired
+	r.generation++
+	imagePolicyConfigGeneration.Set(float64(r.generation))
+	klog.V(2).Infof("Reloaded image import policy from %s (generation %d)", r.configFile, r.generation)
+	return nil
+}
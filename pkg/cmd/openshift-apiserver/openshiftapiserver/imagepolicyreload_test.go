@@ -0,0 +1,97 @@
+package openshiftapiserver
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	restclient "k8s.io/client-go/rest"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/whitelist"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registryhostname"
+)
+
+const reloadTestConfigOpen = `apiVersion: openshiftcontrolplane.config.openshift.io/v1
+kind: OpenShiftAPIServerConfig
+imagePolicyConfig:
+  internalRegistryHostname: registry.internal.test
+`
+
+const reloadTestConfigRestricted = `apiVersion: openshiftcontrolplane.config.openshift.io/v1
+kind: OpenShiftAPIServerConfig
+imagePolicyConfig:
+  internalRegistryHostname: registry.internal.test
+  allowedRegistriesForImport:
+  - domainName: docker.io
+`
+
+func TestImagePolicyReload(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "imagepolicyreload")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(configFile, []byte(reloadTestConfigOpen), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	extraConfig := &OpenshiftAPIExtraConfig{
+		ConfigFile:                configFile,
+		KubeAPIServerClientConfig: &restclient.Config{Host: "https://localhost:8443"},
+		RegistryWhitelister:       whitelist.NewMutableRegistryWhitelister(whitelist.WhitelistAllRegistries(ctx)),
+		RegistryHostnameRetriever: registryhostname.NewMutableRegistryHostnameRetriever(registryhostname.TestingRegistryHostnameRetriever(nil, "", "registry.internal.test")),
+	}
+	reloader, err := newImagePolicyReloader(extraConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reloader == nil {
+		t.Fatal("expected a reloader to be constructed")
+	}
+
+	ref := imageapi.DockerImageReference{Registry: "example.com", Name: "repo"}
+
+	// unchanged config must not swap anything
+	if err := reloader.reload(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reloader.generation != 1 {
+		t.Errorf("expected generation 1 after a no-op reload, got %d", reloader.generation)
+	}
+	if err := extraConfig.RegistryWhitelister.AdmitDockerImageReference(ctx, ref, whitelist.WhitelistTransportSecure); err != nil {
+		t.Errorf("expected example.com to still be admitted: %v", err)
+	}
+
+	// restricting the import policy must take effect without a restart
+	if err := ioutil.WriteFile(configFile, []byte(reloadTestConfigRestricted), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reloader.reload(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reloader.generation != 2 {
+		t.Errorf("expected generation 2 after the policy changed, got %d", reloader.generation)
+	}
+	if err := extraConfig.RegistryWhitelister.AdmitDockerImageReference(ctx, ref, whitelist.WhitelistTransportSecure); err == nil {
+		t.Error("expected example.com to be rejected after the reload")
+	}
+}
+
+func TestImagePolicyReloaderRequiresMutableDelegates(t *testing.T) {
+	reloader, err := newImagePolicyReloader(&OpenshiftAPIExtraConfig{
+		ConfigFile:                "unused",
+		RegistryWhitelister:       whitelist.WhitelistAllRegistries(context.Background()),
+		RegistryHostnameRetriever: registryhostname.TestingRegistryHostnameRetriever(nil, "", ""),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reloader != nil {
+		t.Error("expected no reloader for non swappable delegates")
+	}
+}
@@ -1,6 +1,8 @@
 package openshiftapiserver
 
 import (
+	"fmt"
+	"reflect"
 	"time"
 
 	authorizationv1client "github.com/openshift/client-go/authorization/clientset/versioned"
@@ -126,6 +128,30 @@ func (i *InformerHolder) GetOpenshiftUserInformers() userv1informer.SharedInform
 	return i.userInformers
 }
 
+// SyncStatus reports, for every informer requested from this holder, whether
+// its cache has synced.  Informer names are "<factory>/<type>".  It returns
+// the current state immediately rather than waiting for the caches.
+func (i *InformerHolder) SyncStatus() map[string]bool {
+	closed := make(chan struct{})
+	close(closed)
+	status := make(map[string]bool)
+	record := func(factory string, synced map[reflect.Type]bool) {
+		for informerType, ok := range synced {
+			status[fmt.Sprintf("%s/%s", factory, informerType)] = ok
+		}
+	}
+	record("kubernetes", i.kubernetesInformers.WaitForCacheSync(closed))
+	record("authorization.openshift.io", i.authorizationInformers.WaitForCacheSync(closed))
+	record("image.openshift.io", i.imageInformers.WaitForCacheSync(closed))
+	record("oauth.openshift.io", i.oauthInformers.WaitForCacheSync(closed))
+	record("quota.openshift.io", i.quotaInformers.WaitForCacheSync(closed))
+	record("route.openshift.io", i.routeInformers.WaitForCacheSync(closed))
+	record("security.openshift.io", i.securityInformers.WaitForCacheSync(closed))
+	record("user.openshift.io", i.userInformers.WaitForCacheSync(closed))
+	record("operator.openshift.io", i.operatorInformers.WaitForCacheSync(closed))
+	return status
+}
+
 // Start initializes all requested informers.
 func (i *InformerHolder) Start(stopCh <-chan struct{}) {
 	i.kubernetesInformers.Start(stopCh)
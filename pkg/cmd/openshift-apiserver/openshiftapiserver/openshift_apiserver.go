@@ -21,6 +21,7 @@ import (
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
+	"k8s.io/component-base/metrics/legacyregistry"
 	"k8s.io/klog/v2"
 	openapicontroller "k8s.io/kube-aggregator/pkg/controllers/openapi"
 	"k8s.io/kube-aggregator/pkg/controllers/openapi/aggregator"
@@ -34,18 +35,24 @@ import (
 	quotainformer "github.com/openshift/client-go/quota/informers/externalversions"
 	securityv1informer "github.com/openshift/client-go/security/informers/externalversions"
 	"github.com/openshift/library-go/pkg/quota/clusterquotamapping"
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/selfdiagnostics"
 	oappsapiserver "github.com/openshift/openshift-apiserver/pkg/apps/apiserver"
 	authorizationapiserver "github.com/openshift/openshift-apiserver/pkg/authorization/apiserver"
 	"github.com/openshift/openshift-apiserver/pkg/bootstrappolicy"
 	buildapiserver "github.com/openshift/openshift-apiserver/pkg/build/apiserver"
+	"github.com/openshift/openshift-apiserver/pkg/build/apiserver/buildgenerator"
 	"github.com/openshift/openshift-apiserver/pkg/cmd/openshift-apiserver/openshiftapiserver/configprocessing"
+	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/whitelist"
 	imageapiserver "github.com/openshift/openshift-apiserver/pkg/image/apiserver"
+	imageimporter "github.com/openshift/openshift-apiserver/pkg/image/apiserver/importer"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/labelpolicy"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registryhostname"
 	projectapiserver "github.com/openshift/openshift-apiserver/pkg/project/apiserver"
 	projectauth "github.com/openshift/openshift-apiserver/pkg/project/auth"
 	projectcache "github.com/openshift/openshift-apiserver/pkg/project/cache"
 	quotaapiserver "github.com/openshift/openshift-apiserver/pkg/quota/apiserver"
 	routeapiserver "github.com/openshift/openshift-apiserver/pkg/route/apiserver"
+	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/hostclaimpolicy"
 	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/routeallocationcontroller"
 	securityapiserver "github.com/openshift/openshift-apiserver/pkg/security/apiserver"
 	templateapiserver "github.com/openshift/openshift-apiserver/pkg/template/apiserver"
@@ -73,12 +80,70 @@ type OpenshiftAPIExtraConfig struct {
 	// for Images
 	// RegistryHostnameRetriever retrieves the internal and external hostname of
 	// the integrated registry, or false if no such registry is available.
-	RegistryHostnameRetriever          registryhostname.RegistryHostnameRetriever
+	RegistryHostnameRetriever registryhostname.RegistryHostnameRetriever
+	// RegistryWhitelister admits image references against the configured
+	// import policy.
+	RegistryWhitelister whitelist.RegistryWhitelister
+	// ConfigFile is the path the server configuration was read from; when set,
+	// the image import policy is reloaded from it without a restart.
+	ConfigFile                         string
 	AllowedRegistriesForImport         openshiftcontrolplanev1.AllowedRegistries
 	MaxImagesBulkImportedPerRepository int
-	AdditionalTrustedCA                []byte
+	// MaxImageStreamTagHistory caps the number of history items kept per
+	// image stream status tag; zero keeps the full history.
+	MaxImageStreamTagHistory int
+	// ImageImportLabelPolicy lists labels every imported image must carry
+	// and whether violations warn or reject.
+	ImageImportLabelPolicy   labelpolicy.Policy
+	ImportRegistryRateLimits map[string]imageimporter.RegistryLimit
+	// ImageImportScannerWebhookURL, when set, names an external scanning
+	// service whose vulnerability metadata is attached to imported images.
+	ImageImportScannerWebhookURL string
+	// ImageImportCredentialProviderConfigFile, when set, names a file of
+	// exec credential provider plugins that mint short-lived registry
+	// credentials for image imports.
+	ImageImportCredentialProviderConfigFile string
+	// ServeStaleReadsOnStorageError enables the graceful degradation mode for
+	// etcd outages: image stream and project reads are answered from local
+	// caches with a Warning header when storage is unavailable.
+	ServeStaleReadsOnStorageError bool
+	AdditionalTrustedCA           []byte
+
+	// TemplateInstanceAllowedKinds is the cluster-wide allowlist of object
+	// kinds templates may create; empty means unrestricted.
+	TemplateInstanceAllowedKinds []string
+
+	// BuildEnvRedactionPatterns holds the environment variable name patterns
+	// whose values are masked when builds are echoed outside regular API
+	// reads, e.g. in webhook responses.
+	BuildEnvRedactionPatterns []string
+
+	// BuildLogCAFile, BuildLogClientCertFile and BuildLogClientKeyFile override
+	// the TLS settings of the client used to stream build logs; empty values
+	// keep the kube client defaults.
+	BuildLogCAFile         string
+	BuildLogClientCertFile string
+	BuildLogClientKeyFile  string
+
+	// BuildServiceAccountWaitTimeout is how long build instantiation waits
+	// for the builder service account in a freshly created namespace before
+	// failing; zero disables waiting.
+	BuildServiceAccountWaitTimeout time.Duration
+
+	// BuildInstantiateHooks names external services that may mutate generated
+	// builds before they are persisted; empty disables hooks.
+	BuildInstantiateHooks []buildgenerator.BuildHook
+
+	// BuildScopedPushCredentialExpiration, when positive, makes build
+	// instantiation mint an expiring push secret scoped to the build's output
+	// repository instead of relying on the builder dockercfg; zero disables
+	// minting.
+	BuildScopedPushCredentialExpiration time.Duration
 
 	RouteAllocator *routeallocationcontroller.RouteAllocationController
+	// RouteHostClaimPolicy, when set, is consulted before the custom-host
+	// subject access review for route host and certificate changes.
+	RouteHostClaimPolicy *hostclaimpolicy.Evaluator
 
 	ProjectAuthorizationCache *projectauth.AuthorizationCache
 	ProjectCache              *projectcache.ProjectCache
@@ -207,9 +272,16 @@ func (c *completedConfig) withBuildAPIServer(delegateAPIServer genericapiserver.
 	cfg := &buildapiserver.BuildServerConfig{
 		GenericConfig: &genericapiserver.RecommendedConfig{Config: *c.GenericConfig.Config, SharedInformerFactory: c.GenericConfig.SharedInformerFactory},
 		ExtraConfig: buildapiserver.ExtraConfig{
-			KubeAPIServerClientConfig: c.ExtraConfig.KubeAPIServerClientConfig,
-			Codecs:                    legacyscheme.Codecs,
-			Scheme:                    legacyscheme.Scheme,
+			KubeAPIServerClientConfig:      c.ExtraConfig.KubeAPIServerClientConfig,
+			EnvRedactionPatterns:           c.ExtraConfig.BuildEnvRedactionPatterns,
+			LogCAFile:                      c.ExtraConfig.BuildLogCAFile,
+			LogClientCertFile:              c.ExtraConfig.BuildLogClientCertFile,
+			LogClientKeyFile:               c.ExtraConfig.BuildLogClientKeyFile,
+			ServiceAccountWaitTimeout:      c.ExtraConfig.BuildServiceAccountWaitTimeout,
+			ScopedPushCredentialExpiration: c.ExtraConfig.BuildScopedPushCredentialExpiration,
+			InstantiateHooks:               c.ExtraConfig.BuildInstantiateHooks,
+			Codecs:                         legacyscheme.Codecs,
+			Scheme:                         legacyscheme.Scheme,
 		},
 	}
 	config := cfg.Complete()
@@ -227,8 +299,15 @@ func (c *completedConfig) withImageAPIServer(delegateAPIServer genericapiserver.
 		ExtraConfig: imageapiserver.ExtraConfig{
 			KubeAPIServerClientConfig:          c.ExtraConfig.KubeAPIServerClientConfig,
 			RegistryHostnameRetriever:          c.ExtraConfig.RegistryHostnameRetriever,
+			RegistryWhitelister:                c.ExtraConfig.RegistryWhitelister,
 			AllowedRegistriesForImport:         c.ExtraConfig.AllowedRegistriesForImport,
 			MaxImagesBulkImportedPerRepository: c.ExtraConfig.MaxImagesBulkImportedPerRepository,
+			MaxImageStreamTagHistory:           c.ExtraConfig.MaxImageStreamTagHistory,
+			ImportLabelPolicy:                  c.ExtraConfig.ImageImportLabelPolicy,
+			ImportRegistryRateLimits:           c.ExtraConfig.ImportRegistryRateLimits,
+			ImportScannerWebhookURL:            c.ExtraConfig.ImageImportScannerWebhookURL,
+			ImportCredentialProviderConfigFile: c.ExtraConfig.ImageImportCredentialProviderConfigFile,
+			ServeStaleReadsOnStorageError:      c.ExtraConfig.ServeStaleReadsOnStorageError,
 			Codecs:                             legacyscheme.Codecs,
 			Scheme:                             legacyscheme.Scheme,
 			AdditionalTrustedCA:                c.ExtraConfig.AdditionalTrustedCA,
@@ -248,14 +327,15 @@ func (c *completedConfig) withProjectAPIServer(delegateAPIServer genericapiserve
 	cfg := &projectapiserver.ProjectAPIServerConfig{
 		GenericConfig: &genericapiserver.RecommendedConfig{Config: *c.GenericConfig.Config, SharedInformerFactory: c.GenericConfig.SharedInformerFactory},
 		ExtraConfig: projectapiserver.ExtraConfig{
-			KubeAPIServerClientConfig: c.ExtraConfig.KubeAPIServerClientConfig,
-			ProjectAuthorizationCache: c.ExtraConfig.ProjectAuthorizationCache,
-			ProjectCache:              c.ExtraConfig.ProjectCache,
-			ProjectRequestTemplate:    c.ExtraConfig.ProjectRequestTemplate,
-			ProjectRequestMessage:     c.ExtraConfig.ProjectRequestMessage,
-			RESTMapper:                c.ExtraConfig.RESTMapper,
-			Codecs:                    legacyscheme.Codecs,
-			Scheme:                    legacyscheme.Scheme,
+			KubeAPIServerClientConfig:     c.ExtraConfig.KubeAPIServerClientConfig,
+			ProjectAuthorizationCache:     c.ExtraConfig.ProjectAuthorizationCache,
+			ProjectCache:                  c.ExtraConfig.ProjectCache,
+			ProjectRequestTemplate:        c.ExtraConfig.ProjectRequestTemplate,
+			ProjectRequestMessage:         c.ExtraConfig.ProjectRequestMessage,
+			RESTMapper:                    c.ExtraConfig.RESTMapper,
+			ServeStaleReadsOnStorageError: c.ExtraConfig.ServeStaleReadsOnStorageError,
+			Codecs:                        legacyscheme.Codecs,
+			Scheme:                        legacyscheme.Scheme,
 		},
 	}
 	config := cfg.Complete()
@@ -271,6 +351,7 @@ func (c *completedConfig) withQuotaAPIServer(delegateAPIServer genericapiserver.
 	cfg := &quotaapiserver.QuotaAPIServerConfig{
 		GenericConfig: &genericapiserver.RecommendedConfig{Config: *c.GenericConfig.Config, SharedInformerFactory: c.GenericConfig.SharedInformerFactory},
 		ExtraConfig: quotaapiserver.ExtraConfig{
+			KubeAPIServerClientConfig:     c.ExtraConfig.KubeAPIServerClientConfig,
 			ClusterQuotaMappingController: c.ExtraConfig.ClusterQuotaMappingController,
 			QuotaInformers:                c.ExtraConfig.QuotaInformers,
 			Codecs:                        legacyscheme.Codecs,
@@ -292,6 +373,7 @@ func (c *completedConfig) withRouteAPIServer(delegateAPIServer genericapiserver.
 		ExtraConfig: routeapiserver.ExtraConfig{
 			KubeAPIServerClientConfig: c.ExtraConfig.KubeAPIServerClientConfig,
 			RouteAllocator:            c.ExtraConfig.RouteAllocator,
+			HostClaimPolicy:           c.ExtraConfig.RouteHostClaimPolicy,
 			Codecs:                    legacyscheme.Codecs,
 			Scheme:                    legacyscheme.Scheme,
 		},
@@ -330,9 +412,10 @@ func (c *completedConfig) withTemplateAPIServer(delegateAPIServer genericapiserv
 	cfg := &templateapiserver.TemplateConfig{
 		GenericConfig: &genericapiserver.RecommendedConfig{Config: *c.GenericConfig.Config, SharedInformerFactory: c.GenericConfig.SharedInformerFactory},
 		ExtraConfig: templateapiserver.ExtraConfig{
-			KubeAPIServerClientConfig: c.ExtraConfig.KubeAPIServerClientConfig,
-			Codecs:                    legacyscheme.Codecs,
-			Scheme:                    legacyscheme.Scheme,
+			KubeAPIServerClientConfig:    c.ExtraConfig.KubeAPIServerClientConfig,
+			TemplateInstanceAllowedKinds: c.ExtraConfig.TemplateInstanceAllowedKinds,
+			Codecs:                       legacyscheme.Codecs,
+			Scheme:                       legacyscheme.Scheme,
 		},
 	}
 	config := cfg.Complete()
@@ -407,6 +490,12 @@ func (c completedConfig) New(delegationTarget genericapiserver.DelegationTarget)
 	// this remains a non-healthz endpoint so that you can be healthy without being ready.
 	addReadinessCheckRoute(s.GenericAPIServer.Handler.NonGoRestfulMux, "/healthz/ready", c.ExtraConfig.ProjectAuthorizationCache.ReadyForAccess)
 
+	// a structured companion to /healthz/ready: which caches are behind and
+	// how slow storage has been, for diagnosing why this apiserver is not ready
+	// or is slow while kube is fine
+	selfdiagnostics.RegisterCheck("project.openshift.io/authorization-cache", c.ExtraConfig.ProjectAuthorizationCache.ReadyForAccess)
+	s.GenericAPIServer.Handler.NonGoRestfulMux.Handle("/healthz/diagnostics", selfdiagnostics.Handler(legacyregistry.DefaultGatherer))
+
 	// this remains here and separate so that you can check both kube and openshift levels
 	AddOpenshiftVersionRoute(s.GenericAPIServer.Handler.GoRestfulContainer, "/version/openshift")
 
@@ -440,6 +529,14 @@ func (c completedConfig) New(delegationTarget genericapiserver.DelegationTarget)
 		go c.ExtraConfig.ClusterQuotaMappingController.Run(5, context.StopCh)
 		return nil
 	})
+	if imagePolicyReloader, err := newImagePolicyReloader(c.ExtraConfig); err != nil {
+		return nil, err
+	} else if imagePolicyReloader != nil {
+		s.GenericAPIServer.AddPostStartHookOrDie("image.openshift.io-imagepolicyreload", func(context genericapiserver.PostStartHookContext) error {
+			imagePolicyReloader.Run(context.StopCh)
+			return nil
+		})
+	}
 
 	return s, nil
 }
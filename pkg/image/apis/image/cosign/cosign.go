@@ -0,0 +1,147 @@
+// Package cosign contains helpers for parsing cosign (sigstore) signatures
+// stored in ImageSignature objects.
+package cosign
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+)
+
+// PayloadType is the type string a cosign simple signing payload must carry
+// in its critical section.
+const PayloadType = "cosign container image signature"
+
+// Signature is the stored form of a cosign signature. It mirrors the envelope
+// cosign attaches to OCI signature layers.
+type Signature struct {
+	// Payload is the base64 encoded simple signing payload that was signed.
+	Payload string `json:"payload"`
+	// Signature is the base64 encoded signature over the payload.
+	Signature string `json:"signature"`
+	// Certificate is the PEM encoded signing certificate when keyless
+	// signing was used.
+	Certificate string `json:"certificate,omitempty"`
+	// Chain is the PEM encoded chain of intermediates for the signing
+	// certificate.
+	Chain string `json:"chain,omitempty"`
+	// RekorBundle is an offline proof of inclusion in the Rekor transparency
+	// log.
+	RekorBundle *RekorBundle `json:"rekorBundle,omitempty"`
+}
+
+// RekorBundle identifies an entry in the Rekor transparency log.
+type RekorBundle struct {
+	// LogID is the identifier of the log the entry was appended to.
+	LogID string `json:"logID"`
+	// LogIndex is the index of the entry within the log.
+	LogIndex int64 `json:"logIndex"`
+	// IntegratedTime is the UNIX time the entry was appended to the log.
+	IntegratedTime int64 `json:"integratedTime"`
+}
+
+// Payload is the simple signing payload carried by a cosign signature.
+type Payload struct {
+	Critical Critical `json:"critical"`
+	// Optional holds free-form claims made by the signer.
+	Optional map[string]interface{} `json:"optional,omitempty"`
+}
+
+// Critical is the portion of the payload that verifiers must interpret.
+type Critical struct {
+	Identity Identity      `json:"identity"`
+	Image    CriticalImage `json:"image"`
+	Type     string        `json:"type"`
+}
+
+// Identity names the image the signature applies to.
+type Identity struct {
+	DockerReference string `json:"docker-reference"`
+}
+
+// CriticalImage pins the signature to a manifest digest.
+type CriticalImage struct {
+	DockerManifestDigest string `json:"docker-manifest-digest"`
+}
+
+// ParseSignature parses and structurally validates the content of a cosign
+// typed ImageSignature. It does not verify the signature cryptographically.
+func ParseSignature(content []byte) (*Signature, *Payload, error) {
+	signature := &Signature{}
+	if err := json.Unmarshal(content, signature); err != nil {
+		return nil, nil, fmt.Errorf("content is not a cosign signature envelope: %v", err)
+	}
+	if len(signature.Payload) == 0 {
+		return nil, nil, fmt.Errorf("cosign signature has no payload")
+	}
+	if len(signature.Signature) == 0 {
+		return nil, nil, fmt.Errorf("cosign signature has no signature")
+	}
+	if _, err := base64.StdEncoding.DecodeString(signature.Signature); err != nil {
+		return nil, nil, fmt.Errorf("cosign signature is not base64 encoded: %v", err)
+	}
+	rawPayload, err := base64.StdEncoding.DecodeString(signature.Payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cosign payload is not base64 encoded: %v", err)
+	}
+	payload := &Payload{}
+	if err := json.Unmarshal(rawPayload, payload); err != nil {
+		return nil, nil, fmt.Errorf("cosign payload is not a simple signing document: %v", err)
+	}
+	if payload.Critical.Type != PayloadType {
+		return nil, nil, fmt.Errorf("cosign payload has type %q, expected %q", payload.Critical.Type, PayloadType)
+	}
+	if len(payload.Critical.Image.DockerManifestDigest) == 0 {
+		return nil, nil, fmt.Errorf("cosign payload does not name a manifest digest")
+	}
+	if len(signature.Certificate) > 0 {
+		if _, err := parseCertificates(signature.Certificate); err != nil {
+			return nil, nil, fmt.Errorf("cosign signature carries an invalid certificate: %v", err)
+		}
+	}
+	if len(signature.Chain) > 0 {
+		if _, err := parseCertificates(signature.Chain); err != nil {
+			return nil, nil, fmt.Errorf("cosign signature carries an invalid certificate chain: %v", err)
+		}
+	}
+	return signature, payload, nil
+}
+
+// LeafCertificate returns the signing certificate of a keyless signature, or
+// nil when the signature was made with a plain key pair.
+func (s *Signature) LeafCertificate() (*x509.Certificate, error) {
+	if len(s.Certificate) == 0 {
+		return nil, nil
+	}
+	certs, err := parseCertificates(s.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+func parseCertificates(pemData string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(pemData)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+	return certs, nil
+}
@@ -0,0 +1,128 @@
+package cosign
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func envelope(t *testing.T, payload map[string]interface{}, mutate func(map[string]interface{})) []byte {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	env := map[string]interface{}{
+		"payload":   base64.StdEncoding.EncodeToString(raw),
+		"signature": base64.StdEncoding.EncodeToString([]byte("opaque")),
+	}
+	if mutate != nil {
+		mutate(env)
+	}
+	content, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return content
+}
+
+func validPayload() map[string]interface{} {
+	return map[string]interface{}{
+		"critical": map[string]interface{}{
+			"identity": map[string]string{"docker-reference": "registry.example.com/app"},
+			"image":    map[string]string{"docker-manifest-digest": "sha256:0123"},
+			"type":     PayloadType,
+		},
+		"optional": map[string]interface{}{"creator": "ci"},
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	content := envelope(t, validPayload(), func(env map[string]interface{}) {
+		env["rekorBundle"] = map[string]interface{}{"logID": "abcd", "logIndex": 42, "integratedTime": 1700000000}
+	})
+	signature, payload, err := ParseSignature(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.Critical.Image.DockerManifestDigest != "sha256:0123" {
+		t.Errorf("unexpected digest %q", payload.Critical.Image.DockerManifestDigest)
+	}
+	if payload.Critical.Identity.DockerReference != "registry.example.com/app" {
+		t.Errorf("unexpected identity %q", payload.Critical.Identity.DockerReference)
+	}
+	if signature.RekorBundle == nil || signature.RekorBundle.LogIndex != 42 {
+		t.Errorf("unexpected rekor bundle %#v", signature.RekorBundle)
+	}
+}
+
+func TestParseSignatureErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     []byte
+		expectedErr string
+	}{
+		{
+			name:        "not json",
+			content:     []byte("binary blob"),
+			expectedErr: "not a cosign signature envelope",
+		},
+		{
+			name: "missing payload",
+			content: envelope(t, validPayload(), func(env map[string]interface{}) {
+				delete(env, "payload")
+			}),
+			expectedErr: "no payload",
+		},
+		{
+			name: "missing signature",
+			content: envelope(t, validPayload(), func(env map[string]interface{}) {
+				delete(env, "signature")
+			}),
+			expectedErr: "no signature",
+		},
+		{
+			name: "payload not base64",
+			content: envelope(t, validPayload(), func(env map[string]interface{}) {
+				env["payload"] = "not*base64"
+			}),
+			expectedErr: "not base64",
+		},
+		{
+			name: "wrong payload type",
+			content: envelope(t, func() map[string]interface{} {
+				payload := validPayload()
+				payload["critical"].(map[string]interface{})["type"] = "something else"
+				return payload
+			}(), nil),
+			expectedErr: "expected",
+		},
+		{
+			name: "missing digest",
+			content: envelope(t, func() map[string]interface{} {
+				payload := validPayload()
+				payload["critical"].(map[string]interface{})["image"] = map[string]string{}
+				return payload
+			}(), nil),
+			expectedErr: "manifest digest",
+		},
+		{
+			name: "bad certificate",
+			content: envelope(t, validPayload(), func(env map[string]interface{}) {
+				env["certificate"] = "not a pem"
+			}),
+			expectedErr: "invalid certificate",
+		},
+	}
+
+	for _, tc := range tests {
+		_, _, err := ParseSignature(tc.content)
+		if err == nil {
+			t.Errorf("%s: expected error, got none", tc.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.expectedErr) {
+			t.Errorf("%s: expected error containing %q, got %v", tc.name, tc.expectedErr, err)
+		}
+	}
+}
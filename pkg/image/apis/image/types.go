@@ -77,6 +77,9 @@ type ImageLayer struct {
 const (
 	// The supported type of image signature.
 	ImageSignatureTypeAtomicImageV1 string = "AtomicImageV1"
+	// ImageSignatureTypeCosign means the signature content is a cosign
+	// (sigstore) signature envelope.
+	ImageSignatureTypeCosign string = "Cosign"
 )
 
 // +genclient
@@ -249,6 +252,20 @@ type TagReference struct {
 	ImportPolicy TagImportPolicy
 	// ReferencePolicy defines how other components should consume the image.
 	ReferencePolicy TagReferencePolicy
+	// Pinned prevents imports from moving the tag. Once the tag has resolved to an
+	// image, later imports record the newest upstream image as the candidate of the
+	// status tag instead of prepending a tag event, and the tag only moves when the
+	// candidate is explicitly promoted.
+	Pinned bool
+	// MaxHistoryItems optionally bounds the number of history items kept for
+	// this tag in the stream status; older items are pruned during status
+	// updates. Unset or zero leaves the stream-level history limit in effect.
+	MaxHistoryItems *int32
+	// MaxAgeSeconds optionally bounds how long a history item of this tag is
+	// kept, counted from the item's creation; older items are pruned during
+	// status updates. The newest item is always kept so the tag keeps
+	// resolving. Unset or zero means no age limit.
+	MaxAgeSeconds *int64
 }
 
 type TagImportPolicy struct {
@@ -256,6 +273,9 @@ type TagImportPolicy struct {
 	Insecure bool
 	// Scheduled indicates to the server that this tag should be periodically checked to ensure it is up to date, and imported
 	Scheduled bool
+	// Platforms restricts import to images whose config declares one of the
+	// listed os/arch platforms; empty allows any platform.
+	Platforms []string
 }
 
 // TagReferencePolicyType describes how pull-specs for images in an image stream tag are generated when
@@ -306,6 +326,10 @@ type TagEventList struct {
 	Items []TagEvent
 	// Conditions is an array of conditions that apply to the tag event list.
 	Conditions []TagEventCondition
+	// Candidate is the most recently imported image of a pinned tag. It shadows
+	// Items[0] until it is promoted, at which point it becomes the newest tag
+	// event; see TagReference.Pinned.
+	Candidate *TagEvent
 }
 
 // NamedTagEventList relates a tag to its image history.
@@ -336,6 +360,18 @@ type TagEventConditionType string
 const (
 	// ImportSuccess with status False means the import of the specific tag failed
 	ImportSuccess TagEventConditionType = "ImportSuccess"
+	// ScanVerified with status True means an external scanner verified the
+	// image the tag pointed at for the condition's generation
+	ScanVerified TagEventConditionType = "ScanVerified"
+	// ImportLastAttempt reflects the outcome of the most recent import
+	// attempt for the tag; status True means it succeeded
+	ImportLastAttempt TagEventConditionType = "LastImportAttempt"
+	// ImportConsecutiveFailures counts import failures since the last
+	// success in its reason; absent once an import succeeds
+	ImportConsecutiveFailures TagEventConditionType = "ConsecutiveFailures"
+	// ImportNextScheduled carries an advisory RFC3339 estimate in its
+	// message of when a scheduled import will next be attempted
+	ImportNextScheduled TagEventConditionType = "NextScheduledImport"
 )
 
 // TagEventCondition contains condition information for a tag event.
@@ -516,6 +552,16 @@ type ImageLayerData struct {
 	LayerSize *int64
 	// MediaType of the referenced object.
 	MediaType string
+	// UncompressedSize of the layer in bytes after decompression, when known. The
+	// image config records layer digests rather than sizes, so this is only set
+	// when the stored blob is itself an uncompressed tar.
+	// +optional
+	UncompressedSize *int64
+	// Manifests lists the digests of the platform manifests of a tagged manifest
+	// list whose images reference this blob. It is empty for blobs referenced only
+	// by single-manifest images.
+	// +optional
+	Manifests []string
 }
 
 // +genclient
@@ -1,10 +1,13 @@
 package v1
 
 import (
+	"encoding/json"
+	"fmt"
 	"sort"
 	"strings"
 
 	apicorev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -325,6 +328,369 @@ func Convert_v1_SecretList_To_image_v1_SecretList(in *core.SecretList, out *v1.S
 	return nil
 }
 
+// LayerMetadataAnnotation carries the per-blob metadata of an
+// ImageStreamLayers object that the versioned API has no fields for: the
+// uncompressed size of a layer and the digests of the platform manifests of a
+// manifest list that reference it. The value is a JSON object keyed by blob
+// name.
+const LayerMetadataAnnotation = "image.openshift.io/layer-metadata"
+
+// layerMetadataAnnotationValue is the JSON representation of the extra
+// per-blob fields of ImageLayerData carried by the LayerMetadataAnnotation.
+type layerMetadataAnnotationValue struct {
+	UncompressedSize *int64   `json:"uncompressedSize,omitempty"`
+	Manifests        []string `json:"manifests,omitempty"`
+}
+
+// Convert_image_ImageLayerData_To_v1_ImageLayerData drops the fields the
+// versioned type has no equivalent for; ImageStreamLayers conversion carries
+// them in the LayerMetadataAnnotation instead.
+func Convert_image_ImageLayerData_To_v1_ImageLayerData(in *newer.ImageLayerData, out *v1.ImageLayerData, s conversion.Scope) error {
+	return autoConvert_image_ImageLayerData_To_v1_ImageLayerData(in, out, s)
+}
+
+func Convert_v1_ImageStreamLayers_To_image_ImageStreamLayers(in *v1.ImageStreamLayers, out *newer.ImageStreamLayers, s conversion.Scope) error {
+	if err := autoConvert_v1_ImageStreamLayers_To_image_ImageStreamLayers(in, out, s); err != nil {
+		return err
+	}
+	value, ok := out.Annotations[LayerMetadataAnnotation]
+	if !ok {
+		return nil
+	}
+	metadata := make(map[string]layerMetadataAnnotationValue)
+	if err := json.Unmarshal([]byte(value), &metadata); err != nil {
+		return fmt.Errorf("invalid %s annotation: %v", LayerMetadataAnnotation, err)
+	}
+	for name, extra := range metadata {
+		blob, ok := out.Blobs[name]
+		if !ok {
+			continue
+		}
+		blob.UncompressedSize = extra.UncompressedSize
+		blob.Manifests = extra.Manifests
+		out.Blobs[name] = blob
+	}
+	annotations := make(map[string]string, len(out.Annotations))
+	for k, v := range out.Annotations {
+		if k != LayerMetadataAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	out.Annotations = annotations
+	return nil
+}
+
+func Convert_image_ImageStreamLayers_To_v1_ImageStreamLayers(in *newer.ImageStreamLayers, out *v1.ImageStreamLayers, s conversion.Scope) error {
+	if err := autoConvert_image_ImageStreamLayers_To_v1_ImageStreamLayers(in, out, s); err != nil {
+		return err
+	}
+	metadata := make(map[string]layerMetadataAnnotationValue)
+	for name, blob := range in.Blobs {
+		if blob.UncompressedSize == nil && len(blob.Manifests) == 0 {
+			continue
+		}
+		metadata[name] = layerMetadataAnnotationValue{
+			UncompressedSize: blob.UncompressedSize,
+			Manifests:        blob.Manifests,
+		}
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+	value, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(out.Annotations)+1)
+	for k, v := range out.Annotations {
+		annotations[k] = v
+	}
+	annotations[LayerMetadataAnnotation] = string(value)
+	out.Annotations = annotations
+	return nil
+}
+
+// PinnedTagsAnnotation carries the names of the spec tags whose pinned flag
+// is set, comma separated. The versioned TagReference has no field for the
+// flag, so it is translated to and from the annotation during ImageStream
+// conversion and is not stored on internal objects.
+const PinnedTagsAnnotation = "image.openshift.io/pinned-tags"
+
+// TagCandidatesAnnotation carries the candidate tag events recorded for
+// pinned tags as a JSON object keyed by tag name. Like the pinned flag, the
+// candidates have no versioned field and ride the annotation across the v1
+// API.
+const TagCandidatesAnnotation = "image.openshift.io/tag-candidates"
+
+func decodeTagPins(meta *metav1.ObjectMeta, stream *newer.ImageStream) {
+	if value, ok := meta.Annotations[PinnedTagsAnnotation]; ok {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			ref, ok := stream.Spec.Tags[tag]
+			if !ok {
+				continue
+			}
+			ref.Pinned = true
+			stream.Spec.Tags[tag] = ref
+		}
+	}
+	candidatesDecoded := false
+	if value, ok := meta.Annotations[TagCandidatesAnnotation]; ok {
+		candidates := make(map[string]v1.TagEvent)
+		// a stored stream may carry a malformed value that predates any
+		// validation; failing conversion would make it unreadable, so the
+		// value is left in place for validation to reject on writes
+		if err := json.Unmarshal([]byte(value), &candidates); err == nil {
+			candidatesDecoded = true
+			for tag, event := range candidates {
+				tagEvents, ok := stream.Status.Tags[tag]
+				if !ok {
+					continue
+				}
+				tagEvents.Candidate = &newer.TagEvent{
+					Created:              event.Created,
+					DockerImageReference: event.DockerImageReference,
+					Image:                event.Image,
+					Generation:           event.Generation,
+				}
+				stream.Status.Tags[tag] = tagEvents
+			}
+		}
+	}
+	if len(meta.Annotations) == 0 {
+		return
+	}
+	annotations := make(map[string]string, len(meta.Annotations))
+	for k, v := range meta.Annotations {
+		if k == PinnedTagsAnnotation || (k == TagCandidatesAnnotation && candidatesDecoded) {
+			continue
+		}
+		annotations[k] = v
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	meta.Annotations = annotations
+}
+
+func encodeTagPins(meta *metav1.ObjectMeta, stream *newer.ImageStream) error {
+	var pinned []string
+	for tag, ref := range stream.Spec.Tags {
+		if ref.Pinned {
+			pinned = append(pinned, tag)
+		}
+	}
+	candidates := make(map[string]v1.TagEvent)
+	for tag, tagEvents := range stream.Status.Tags {
+		if tagEvents.Candidate == nil {
+			continue
+		}
+		candidates[tag] = v1.TagEvent{
+			Created:              tagEvents.Candidate.Created,
+			DockerImageReference: tagEvents.Candidate.DockerImageReference,
+			Image:                tagEvents.Candidate.Image,
+			Generation:           tagEvents.Candidate.Generation,
+		}
+	}
+	if len(pinned) == 0 && len(candidates) == 0 {
+		return nil
+	}
+	annotations := make(map[string]string, len(meta.Annotations)+2)
+	for k, v := range meta.Annotations {
+		annotations[k] = v
+	}
+	if len(pinned) > 0 {
+		sort.Strings(pinned)
+		annotations[PinnedTagsAnnotation] = strings.Join(pinned, ",")
+	}
+	if len(candidates) > 0 {
+		value, err := json.Marshal(candidates)
+		if err != nil {
+			return err
+		}
+		annotations[TagCandidatesAnnotation] = string(value)
+	}
+	meta.Annotations = annotations
+	return nil
+}
+
+// TagGCPolicyAnnotation carries the per-tag history retention policy of spec
+// tags as a JSON object keyed by tag name. The versioned TagReference has no
+// fields for the policy, so it is translated to and from the annotation
+// during ImageStream conversion and is not stored on internal objects.
+const TagGCPolicyAnnotation = "image.openshift.io/tag-gc-policy"
+
+// TagGCPolicy is the serialized retention policy of one tag.
+type TagGCPolicy struct {
+	MaxHistoryItems *int32 `json:"maxHistoryItems,omitempty"`
+	MaxAgeSeconds   *int64 `json:"maxAgeSeconds,omitempty"`
+}
+
+func decodeTagGCPolicies(meta *metav1.ObjectMeta, stream *newer.ImageStream) {
+	value, ok := meta.Annotations[TagGCPolicyAnnotation]
+	if !ok {
+		return
+	}
+	policies := make(map[string]TagGCPolicy)
+	if err := json.Unmarshal([]byte(value), &policies); err != nil {
+		// a stored stream may carry a malformed value that predates any
+		// validation; failing conversion would make it unreadable, so the
+		// value is left in place for validation to reject on writes
+		return
+	}
+	for tag, policy := range policies {
+		ref, ok := stream.Spec.Tags[tag]
+		if !ok {
+			continue
+		}
+		ref.MaxHistoryItems = policy.MaxHistoryItems
+		ref.MaxAgeSeconds = policy.MaxAgeSeconds
+		stream.Spec.Tags[tag] = ref
+	}
+	annotations := make(map[string]string, len(meta.Annotations)-1)
+	for k, v := range meta.Annotations {
+		if k != TagGCPolicyAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	meta.Annotations = annotations
+}
+
+func encodeTagGCPolicies(meta *metav1.ObjectMeta, stream *newer.ImageStream) error {
+	policies := make(map[string]TagGCPolicy)
+	for tag, ref := range stream.Spec.Tags {
+		if ref.MaxHistoryItems == nil && ref.MaxAgeSeconds == nil {
+			continue
+		}
+		policies[tag] = TagGCPolicy{
+			MaxHistoryItems: ref.MaxHistoryItems,
+			MaxAgeSeconds:   ref.MaxAgeSeconds,
+		}
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	value, err := json.Marshal(policies)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(meta.Annotations)+1)
+	for k, v := range meta.Annotations {
+		annotations[k] = v
+	}
+	annotations[TagGCPolicyAnnotation] = string(value)
+	meta.Annotations = annotations
+	return nil
+}
+
+// Convert_v1_ImageStream_To_image_ImageStream is a manual conversion because
+// the pinned flags and retention policies of spec tags and the candidates of
+// status tags are carried by annotations in v1.
+func Convert_v1_ImageStream_To_image_ImageStream(in *v1.ImageStream, out *newer.ImageStream, s conversion.Scope) error {
+	if err := autoConvert_v1_ImageStream_To_image_ImageStream(in, out, s); err != nil {
+		return err
+	}
+	decodeTagPins(&out.ObjectMeta, out)
+	decodeTagGCPolicies(&out.ObjectMeta, out)
+	return nil
+}
+
+func Convert_image_ImageStream_To_v1_ImageStream(in *newer.ImageStream, out *v1.ImageStream, s conversion.Scope) error {
+	if err := autoConvert_image_ImageStream_To_v1_ImageStream(in, out, s); err != nil {
+		return err
+	}
+	if err := encodeTagPins(&out.ObjectMeta, in); err != nil {
+		return err
+	}
+	return encodeTagGCPolicies(&out.ObjectMeta, in)
+}
+
+// ImportPlatformsAnnotation carries the platform allowlist of the import
+// policies on an ImageStreamImport as a comma-separated list of os/arch
+// entries. The versioned TagImportPolicy has no field for the allowlist, so
+// it is translated to and from the annotation during ImageStreamImport
+// conversion and is not stored on internal objects.
+const ImportPlatformsAnnotation = "image.openshift.io/import-platforms"
+
+func decodeImportPlatforms(meta *metav1.ObjectMeta, isi *newer.ImageStreamImport) error {
+	value, ok := meta.Annotations[ImportPlatformsAnnotation]
+	if !ok {
+		return nil
+	}
+	var platforms []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); len(entry) > 0 {
+			platforms = append(platforms, entry)
+		}
+	}
+	if len(platforms) == 0 {
+		return fmt.Errorf("invalid %s annotation: must list at least one os/arch entry", ImportPlatformsAnnotation)
+	}
+	for i := range isi.Spec.Images {
+		isi.Spec.Images[i].ImportPolicy.Platforms = append([]string(nil), platforms...)
+	}
+	if isi.Spec.Repository != nil {
+		isi.Spec.Repository.ImportPolicy.Platforms = append([]string(nil), platforms...)
+	}
+	annotations := make(map[string]string, len(meta.Annotations)-1)
+	for k, v := range meta.Annotations {
+		if k != ImportPlatformsAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	meta.Annotations = annotations
+	return nil
+}
+
+func encodeImportPlatforms(meta *metav1.ObjectMeta, isi *newer.ImageStreamImport) {
+	var platforms []string
+	for i := range isi.Spec.Images {
+		if len(isi.Spec.Images[i].ImportPolicy.Platforms) > 0 {
+			platforms = isi.Spec.Images[i].ImportPolicy.Platforms
+			break
+		}
+	}
+	if len(platforms) == 0 && isi.Spec.Repository != nil {
+		platforms = isi.Spec.Repository.ImportPolicy.Platforms
+	}
+	if len(platforms) == 0 {
+		return
+	}
+	annotations := make(map[string]string, len(meta.Annotations)+1)
+	for k, v := range meta.Annotations {
+		annotations[k] = v
+	}
+	annotations[ImportPlatformsAnnotation] = strings.Join(platforms, ",")
+	meta.Annotations = annotations
+}
+
+// Convert_v1_ImageStreamImport_To_image_ImageStreamImport is a manual
+// conversion because the platform allowlist of the import policies is carried
+// by an annotation in v1.
+func Convert_v1_ImageStreamImport_To_image_ImageStreamImport(in *v1.ImageStreamImport, out *newer.ImageStreamImport, s conversion.Scope) error {
+	if err := autoConvert_v1_ImageStreamImport_To_image_ImageStreamImport(in, out, s); err != nil {
+		return err
+	}
+	return decodeImportPlatforms(&out.ObjectMeta, out)
+}
+
+func Convert_image_ImageStreamImport_To_v1_ImageStreamImport(in *newer.ImageStreamImport, out *v1.ImageStreamImport, s conversion.Scope) error {
+	if err := autoConvert_image_ImageStreamImport_To_v1_ImageStreamImport(in, out, s); err != nil {
+		return err
+	}
+	encodeImportPlatforms(&out.ObjectMeta, in)
+	return nil
+}
+
 func AddConversionFuncs(s *runtime.Scheme) error {
 	if err := s.AddConversionFunc((*[]newer.TagEventCondition)(nil), (*[]v1.TagEventCondition)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_image_TagEventConditionArray_to_v1_TagEventConditionArray(a.(*[]newer.TagEventCondition), b.(*[]v1.TagEventCondition), scope)
@@ -87,3 +87,246 @@ func TestImageImportSpecDefaulting(t *testing.T) {
 		t.Errorf("unexpected round trip: %#v", isi)
 	}
 }
+
+func TestImageStreamLayersConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	Install(scheme)
+
+	uncompressed := int64(2048)
+	size := int64(1024)
+	internalLayers := &internal.ImageStreamLayers{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Blobs: map[string]internal.ImageLayerData{
+			"sha256:layer": {
+				LayerSize:        &size,
+				MediaType:        "application/vnd.docker.image.rootfs.diff.tar.gzip",
+				UncompressedSize: &uncompressed,
+				Manifests:        []string{"sha256:amd64", "sha256:arm64"},
+			},
+			"sha256:plain": {
+				LayerSize: &size,
+				MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip",
+			},
+		},
+		Images: map[string]internal.ImageBlobReferences{
+			"sha256:manifest": {Layers: []string{"sha256:layer", "sha256:plain"}},
+		},
+	}
+
+	versionedObj, err := scheme.ConvertToVersion(internalLayers, v1.GroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versioned := versionedObj.(*v1.ImageStreamLayers)
+	expected := `{"sha256:layer":{"uncompressedSize":2048,"manifests":["sha256:amd64","sha256:arm64"]}}`
+	if versioned.Annotations[LayerMetadataAnnotation] != expected {
+		t.Fatalf("expected annotation %s, got %q", expected, versioned.Annotations[LayerMetadataAnnotation])
+	}
+
+	roundTrippedObj, err := scheme.ConvertToVersion(versioned, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := roundTrippedObj.(*internal.ImageStreamLayers)
+	if _, ok := roundTripped.Annotations[LayerMetadataAnnotation]; ok {
+		t.Errorf("expected the annotation to be dropped from the internal object, got %v", roundTripped.Annotations)
+	}
+	if !reflect.DeepEqual(internalLayers.Blobs, roundTripped.Blobs) {
+		t.Errorf("blobs did not round trip: %s", diff.ObjectDiff(internalLayers.Blobs, roundTripped.Blobs))
+	}
+}
+
+func TestImageStreamTagPinConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	Install(scheme)
+
+	internalStream := &internal.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: internal.ImageStreamSpec{
+			Tags: map[string]internal.TagReference{
+				"stable": {
+					Name:   "stable",
+					From:   &kapi.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/repo:stable"},
+					Pinned: true,
+				},
+				"latest": {
+					Name: "latest",
+					From: &kapi.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/repo:latest"},
+				},
+			},
+		},
+		Status: internal.ImageStreamStatus{
+			Tags: map[string]internal.TagEventList{
+				"stable": {
+					Items: []internal.TagEvent{
+						{DockerImageReference: "registry.example.com/repo@sha256:old", Image: "sha256:old", Generation: 1},
+					},
+					Candidate: &internal.TagEvent{DockerImageReference: "registry.example.com/repo@sha256:new", Image: "sha256:new", Generation: 2},
+				},
+			},
+		},
+	}
+
+	versionedObj, err := scheme.ConvertToVersion(internalStream, v1.GroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versioned := versionedObj.(*v1.ImageStream)
+	if versioned.Annotations[PinnedTagsAnnotation] != "stable" {
+		t.Errorf("expected pinned tags annotation %q, got %q", "stable", versioned.Annotations[PinnedTagsAnnotation])
+	}
+	if _, ok := versioned.Annotations[TagCandidatesAnnotation]; !ok {
+		t.Errorf("expected a candidates annotation, got %v", versioned.Annotations)
+	}
+
+	roundTrippedObj, err := scheme.ConvertToVersion(versioned, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := roundTrippedObj.(*internal.ImageStream)
+	if _, ok := roundTripped.Annotations[PinnedTagsAnnotation]; ok {
+		t.Errorf("expected the annotations to be dropped from the internal object, got %v", roundTripped.Annotations)
+	}
+	if !roundTripped.Spec.Tags["stable"].Pinned {
+		t.Errorf("expected the stable tag to round trip as pinned: %#v", roundTripped.Spec.Tags["stable"])
+	}
+	if roundTripped.Spec.Tags["latest"].Pinned {
+		t.Errorf("did not expect the latest tag to be pinned: %#v", roundTripped.Spec.Tags["latest"])
+	}
+	if !reflect.DeepEqual(internalStream.Status.Tags["stable"].Candidate, roundTripped.Status.Tags["stable"].Candidate) {
+		t.Errorf("candidate did not round trip: %s", diff.ObjectDiff(internalStream.Status.Tags["stable"].Candidate, roundTripped.Status.Tags["stable"].Candidate))
+	}
+
+	// a stored stream may carry a malformed annotation from before validation
+	// covered it; conversion must keep such streams readable
+	invalid := &v1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test",
+			Annotations: map[string]string{
+				TagCandidatesAnnotation: "not json",
+				TagGCPolicyAnnotation:   "not json",
+			},
+		},
+	}
+	toleratedObj, err := scheme.ConvertToVersion(invalid, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("expected an unparseable annotation to be tolerated, got: %v", err)
+	}
+	tolerated := toleratedObj.(*internal.ImageStream)
+	if tolerated.Annotations[TagCandidatesAnnotation] != "not json" {
+		t.Errorf("expected an unparseable annotation to be passed through, got %v", tolerated.Annotations)
+	}
+	if tolerated.Annotations[TagGCPolicyAnnotation] != "not json" {
+		t.Errorf("expected an unparseable annotation to be passed through, got %v", tolerated.Annotations)
+	}
+}
+
+func TestImageStreamTagGCPolicyConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	Install(scheme)
+
+	maxItems := int32(3)
+	maxAge := int64(86400)
+	internalStream := &internal.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: internal.ImageStreamSpec{
+			Tags: map[string]internal.TagReference{
+				"latest": {
+					Name:            "latest",
+					From:            &kapi.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/repo:latest"},
+					MaxHistoryItems: &maxItems,
+					MaxAgeSeconds:   &maxAge,
+				},
+				"stable": {
+					Name: "stable",
+					From: &kapi.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/repo:stable"},
+				},
+			},
+		},
+	}
+
+	versionedObj, err := scheme.ConvertToVersion(internalStream, v1.GroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versioned := versionedObj.(*v1.ImageStream)
+	expected := `{"latest":{"maxHistoryItems":3,"maxAgeSeconds":86400}}`
+	if versioned.Annotations[TagGCPolicyAnnotation] != expected {
+		t.Errorf("expected gc policy annotation %s, got %q", expected, versioned.Annotations[TagGCPolicyAnnotation])
+	}
+
+	roundTrippedObj, err := scheme.ConvertToVersion(versioned, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := roundTrippedObj.(*internal.ImageStream)
+	if _, ok := roundTripped.Annotations[TagGCPolicyAnnotation]; ok {
+		t.Errorf("expected the annotation to be dropped from the internal object, got %v", roundTripped.Annotations)
+	}
+	if !reflect.DeepEqual(internalStream.Spec.Tags["latest"], roundTripped.Spec.Tags["latest"]) {
+		t.Errorf("gc policy did not round trip: %s", diff.ObjectDiff(internalStream.Spec.Tags["latest"], roundTripped.Spec.Tags["latest"]))
+	}
+	if ref := roundTripped.Spec.Tags["stable"]; ref.MaxHistoryItems != nil || ref.MaxAgeSeconds != nil {
+		t.Errorf("did not expect a gc policy on the stable tag: %#v", ref)
+	}
+}
+
+func TestImageStreamImportPlatformsConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	Install(scheme)
+
+	platforms := []string{"linux/amd64", "linux/arm64"}
+	internalImport := &internal.ImageStreamImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: internal.ImageStreamImportSpec{
+			Import: true,
+			Repository: &internal.RepositoryImportSpec{
+				From:         kapi.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/repo"},
+				ImportPolicy: internal.TagImportPolicy{Platforms: platforms},
+			},
+			Images: []internal.ImageImportSpec{
+				{
+					From:         kapi.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/repo:latest"},
+					ImportPolicy: internal.TagImportPolicy{Platforms: platforms},
+				},
+			},
+		},
+	}
+
+	versionedObj, err := scheme.ConvertToVersion(internalImport, v1.GroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versioned := versionedObj.(*v1.ImageStreamImport)
+	expected := "linux/amd64,linux/arm64"
+	if versioned.Annotations[ImportPlatformsAnnotation] != expected {
+		t.Errorf("expected platforms annotation %q, got %q", expected, versioned.Annotations[ImportPlatformsAnnotation])
+	}
+
+	roundTrippedObj, err := scheme.ConvertToVersion(versioned, internal.SchemeGroupVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := roundTrippedObj.(*internal.ImageStreamImport)
+	if _, ok := roundTripped.Annotations[ImportPlatformsAnnotation]; ok {
+		t.Errorf("expected the annotation to be dropped from the internal object, got %v", roundTripped.Annotations)
+	}
+	if !reflect.DeepEqual(roundTripped.Spec.Repository.ImportPolicy.Platforms, platforms) {
+		t.Errorf("repository platforms did not round trip: %#v", roundTripped.Spec.Repository.ImportPolicy)
+	}
+	if !reflect.DeepEqual(roundTripped.Spec.Images[0].ImportPolicy.Platforms, platforms) {
+		t.Errorf("image platforms did not round trip: %#v", roundTripped.Spec.Images[0].ImportPolicy)
+	}
+
+	invalid := &v1.ImageStreamImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "test",
+			Annotations: map[string]string{ImportPlatformsAnnotation: " , "},
+		},
+	}
+	if _, err := scheme.ConvertToVersion(invalid, internal.SchemeGroupVersion); err == nil {
+		t.Errorf("expected an error for an empty platform list")
+	}
+}
@@ -587,14 +587,11 @@ func Convert_v1_ImageLayerData_To_image_ImageLayerData(in *v1.ImageLayerData, ou
 func autoConvert_image_ImageLayerData_To_v1_ImageLayerData(in *image.ImageLayerData, out *v1.ImageLayerData, s conversion.Scope) error {
 	out.LayerSize = (*int64)(unsafe.Pointer(in.LayerSize))
 	out.MediaType = in.MediaType
+	// WARNING: in.UncompressedSize requires manual conversion: does not exist in peer-type
+	// WARNING: in.Manifests requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_image_ImageLayerData_To_v1_ImageLayerData is an autogenerated conversion function.
-func Convert_image_ImageLayerData_To_v1_ImageLayerData(in *image.ImageLayerData, out *v1.ImageLayerData, s conversion.Scope) error {
-	return autoConvert_image_ImageLayerData_To_v1_ImageLayerData(in, out, s)
-}
-
 func autoConvert_v1_ImageList_To_image_ImageList(in *v1.ImageList, out *image.ImageList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
@@ -704,11 +701,6 @@ func autoConvert_v1_ImageStream_To_image_ImageStream(in *v1.ImageStream, out *im
 	return nil
 }
 
-// Convert_v1_ImageStream_To_image_ImageStream is an autogenerated conversion function.
-func Convert_v1_ImageStream_To_image_ImageStream(in *v1.ImageStream, out *image.ImageStream, s conversion.Scope) error {
-	return autoConvert_v1_ImageStream_To_image_ImageStream(in, out, s)
-}
-
 func autoConvert_image_ImageStream_To_v1_ImageStream(in *image.ImageStream, out *v1.ImageStream, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_image_ImageStreamSpec_To_v1_ImageStreamSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -720,11 +712,6 @@ func autoConvert_image_ImageStream_To_v1_ImageStream(in *image.ImageStream, out
 	return nil
 }
 
-// Convert_image_ImageStream_To_v1_ImageStream is an autogenerated conversion function.
-func Convert_image_ImageStream_To_v1_ImageStream(in *image.ImageStream, out *v1.ImageStream, s conversion.Scope) error {
-	return autoConvert_image_ImageStream_To_v1_ImageStream(in, out, s)
-}
-
 func autoConvert_v1_ImageStreamImage_To_image_ImageStreamImage(in *v1.ImageStreamImage, out *image.ImageStreamImage, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1_Image_To_image_Image(&in.Image, &out.Image, s); err != nil {
@@ -762,11 +749,6 @@ func autoConvert_v1_ImageStreamImport_To_image_ImageStreamImport(in *v1.ImageStr
 	return nil
 }
 
-// Convert_v1_ImageStreamImport_To_image_ImageStreamImport is an autogenerated conversion function.
-func Convert_v1_ImageStreamImport_To_image_ImageStreamImport(in *v1.ImageStreamImport, out *image.ImageStreamImport, s conversion.Scope) error {
-	return autoConvert_v1_ImageStreamImport_To_image_ImageStreamImport(in, out, s)
-}
-
 func autoConvert_image_ImageStreamImport_To_v1_ImageStreamImport(in *image.ImageStreamImport, out *v1.ImageStreamImport, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_image_ImageStreamImportSpec_To_v1_ImageStreamImportSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -778,11 +760,6 @@ func autoConvert_image_ImageStreamImport_To_v1_ImageStreamImport(in *image.Image
 	return nil
 }
 
-// Convert_image_ImageStreamImport_To_v1_ImageStreamImport is an autogenerated conversion function.
-func Convert_image_ImageStreamImport_To_v1_ImageStreamImport(in *image.ImageStreamImport, out *v1.ImageStreamImport, s conversion.Scope) error {
-	return autoConvert_image_ImageStreamImport_To_v1_ImageStreamImport(in, out, s)
-}
-
 func autoConvert_v1_ImageStreamImportSpec_To_image_ImageStreamImportSpec(in *v1.ImageStreamImportSpec, out *image.ImageStreamImportSpec, s conversion.Scope) error {
 	out.Import = in.Import
 	if in.Repository != nil {
@@ -921,28 +898,42 @@ func Convert_image_ImageStreamImportStatus_To_v1_ImageStreamImportStatus(in *ima
 
 func autoConvert_v1_ImageStreamLayers_To_image_ImageStreamLayers(in *v1.ImageStreamLayers, out *image.ImageStreamLayers, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
-	out.Blobs = *(*map[string]image.ImageLayerData)(unsafe.Pointer(&in.Blobs))
+	if in.Blobs != nil {
+		in, out := &in.Blobs, &out.Blobs
+		*out = make(map[string]image.ImageLayerData, len(*in))
+		for key, val := range *in {
+			newVal := new(image.ImageLayerData)
+			if err := Convert_v1_ImageLayerData_To_image_ImageLayerData(&val, newVal, s); err != nil {
+				return err
+			}
+			(*out)[key] = *newVal
+		}
+	} else {
+		out.Blobs = nil
+	}
 	out.Images = *(*map[string]image.ImageBlobReferences)(unsafe.Pointer(&in.Images))
 	return nil
 }
 
-// Convert_v1_ImageStreamLayers_To_image_ImageStreamLayers is an autogenerated conversion function.
-func Convert_v1_ImageStreamLayers_To_image_ImageStreamLayers(in *v1.ImageStreamLayers, out *image.ImageStreamLayers, s conversion.Scope) error {
-	return autoConvert_v1_ImageStreamLayers_To_image_ImageStreamLayers(in, out, s)
-}
-
 func autoConvert_image_ImageStreamLayers_To_v1_ImageStreamLayers(in *image.ImageStreamLayers, out *v1.ImageStreamLayers, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
-	out.Blobs = *(*map[string]v1.ImageLayerData)(unsafe.Pointer(&in.Blobs))
+	if in.Blobs != nil {
+		in, out := &in.Blobs, &out.Blobs
+		*out = make(map[string]v1.ImageLayerData, len(*in))
+		for key, val := range *in {
+			newVal := new(v1.ImageLayerData)
+			if err := Convert_image_ImageLayerData_To_v1_ImageLayerData(&val, newVal, s); err != nil {
+				return err
+			}
+			(*out)[key] = *newVal
+		}
+	} else {
+		out.Blobs = nil
+	}
 	out.Images = *(*map[string]v1.ImageBlobReferences)(unsafe.Pointer(&in.Images))
 	return nil
 }
 
-// Convert_image_ImageStreamLayers_To_v1_ImageStreamLayers is an autogenerated conversion function.
-func Convert_image_ImageStreamLayers_To_v1_ImageStreamLayers(in *image.ImageStreamLayers, out *v1.ImageStreamLayers, s conversion.Scope) error {
-	return autoConvert_image_ImageStreamLayers_To_v1_ImageStreamLayers(in, out, s)
-}
-
 func autoConvert_v1_ImageStreamList_To_image_ImageStreamList(in *v1.ImageStreamList, out *image.ImageStreamList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
@@ -1562,6 +1553,7 @@ func Convert_v1_TagImportPolicy_To_image_TagImportPolicy(in *v1.TagImportPolicy,
 func autoConvert_image_TagImportPolicy_To_v1_TagImportPolicy(in *image.TagImportPolicy, out *v1.TagImportPolicy, s conversion.Scope) error {
 	out.Insecure = in.Insecure
 	out.Scheduled = in.Scheduled
+	// WARNING: in.Platforms requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -3,6 +3,7 @@ package validation
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"regexp"
@@ -24,6 +25,8 @@ import (
 	"github.com/openshift/library-go/pkg/image/imageutil"
 	imageref "github.com/openshift/library-go/pkg/image/reference"
 	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/cosign"
+	imageapiv1 "github.com/openshift/openshift-apiserver/pkg/image/apis/image/v1"
 	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/whitelist"
 )
 
@@ -122,6 +125,10 @@ func validateImageSignature(signature *imageapi.ImageSignature, fldPath *field.P
 	}
 	if len(signature.Content) == 0 {
 		allErrs = append(allErrs, field.Required(fldPath.Child("content"), ""))
+	} else if signature.Type == imageapi.ImageSignatureTypeCosign {
+		if _, _, err := cosign.ParseSignature(signature.Content); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("content"), "<content>", err.Error()))
+		}
 	}
 
 	var trustedCondition, forImageCondition *imageapi.SignatureCondition
@@ -249,9 +256,33 @@ func ValidateImageStreamWithWhitelister(
 		}
 	}
 
+	result = append(result, validatePolicyAnnotations(stream, field.NewPath("metadata", "annotations"))...)
+
 	return result
 }
 
+// validatePolicyAnnotations rejects malformed values of the annotations that
+// conversion translates into internal fields.  Conversion tolerates bad
+// values so stored image streams always stay readable; a value only survives
+// to the internal object when it could not be decoded, and it is rejected
+// here on the write path instead.
+func validatePolicyAnnotations(stream *imageapi.ImageStream, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if value, ok := stream.Annotations[imageapiv1.TagCandidatesAnnotation]; ok {
+		candidates := map[string]imagev1.TagEvent{}
+		if err := json.Unmarshal([]byte(value), &candidates); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(imageapiv1.TagCandidatesAnnotation), value, fmt.Sprintf("must be a JSON object mapping tag names to tag events: %v", err)))
+		}
+	}
+	if value, ok := stream.Annotations[imageapiv1.TagGCPolicyAnnotation]; ok {
+		policies := map[string]imageapiv1.TagGCPolicy{}
+		if err := json.Unmarshal([]byte(value), &policies); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(imageapiv1.TagGCPolicyAnnotation), value, fmt.Sprintf("must be a JSON object mapping tag names to retention policies: %v", err)))
+		}
+	}
+	return allErrs
+}
+
 // ValidateImageStreamTagReference ensures that a given tag reference is valid.
 func ValidateImageStreamTagReference(
 	ctx context.Context,
@@ -290,6 +321,12 @@ func ValidateImageStreamTagReference(
 	default:
 		errs = append(errs, field.Invalid(fldPath.Child("referencePolicy", "type"), tagRef.ReferencePolicy.Type, fmt.Sprintf("valid values are %q, %q", imageapi.SourceTagReferencePolicy, imageapi.LocalTagReferencePolicy)))
 	}
+	if tagRef.MaxHistoryItems != nil && *tagRef.MaxHistoryItems < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("maxHistoryItems"), *tagRef.MaxHistoryItems, "may not be negative"))
+	}
+	if tagRef.MaxAgeSeconds != nil && *tagRef.MaxAgeSeconds < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("maxAgeSeconds"), *tagRef.MaxAgeSeconds, "may not be negative"))
+	}
 
 	return errs
 }
@@ -626,6 +663,9 @@ func ValidateImageStreamImport(isi *imageapi.ImageStreamImport) field.ErrorList
 	repoPath := specPath.Child("repository")
 
 	errs := field.ErrorList{}
+	// remember which spec entry claimed each destination tag so a bulk import
+	// renaming tags cannot have two entries silently overwrite one another
+	destinationTags := map[string]int{}
 	for i, spec := range isi.Spec.Images {
 		from := spec.From
 		switch from.Kind {
@@ -633,6 +673,9 @@ func ValidateImageStreamImport(isi *imageapi.ImageStreamImport) field.ErrorList
 			if spec.To != nil && len(spec.To.Name) == 0 {
 				errs = append(errs, field.Invalid(imagesPath.Index(i).Child("to", "name"), spec.To.Name, "the name of the target tag must be specified"))
 			}
+			if spec.To != nil && len(spec.To.Name) > 0 && !tagNameAnchoredRegexp.MatchString(spec.To.Name) {
+				errs = append(errs, field.Invalid(imagesPath.Index(i).Child("to", "name"), spec.To.Name, "must be a valid container image tag"))
+			}
 			if len(spec.From.Name) == 0 {
 				errs = append(errs, field.Required(imagesPath.Index(i).Child("from", "name"), ""))
 			} else {
@@ -647,6 +690,15 @@ func ValidateImageStreamImport(isi *imageapi.ImageStreamImport) field.ErrorList
 					errs = append(errs, field.Invalid(imagesPath.Index(i).Child("from", "name"), spec.From.Name, err.Error()))
 				}
 			}
+			errs = append(errs, validateImportPlatforms(spec.ImportPolicy.Platforms, imagesPath.Index(i).Child("importPolicy", "platforms"))...)
+			destination, destinationPath := importDestinationTag(spec, imagesPath.Index(i))
+			if len(destination) > 0 {
+				if previous, exists := destinationTags[destination]; exists {
+					errs = append(errs, field.Invalid(destinationPath, destination, fmt.Sprintf("tag %q is already the destination of images[%d]", destination, previous)))
+				} else {
+					destinationTags[destination] = i
+				}
+			}
 		default:
 			errs = append(errs, field.Invalid(imagesPath.Index(i).Child("from", "kind"), from.Kind, "only DockerImage is supported"))
 		}
@@ -670,6 +722,7 @@ func ValidateImageStreamImport(isi *imageapi.ImageStreamImport) field.ErrorList
 		default:
 			errs = append(errs, field.Invalid(repoPath.Child("from", "kind"), from.Kind, "only DockerImage is supported"))
 		}
+		errs = append(errs, validateImportPlatforms(spec.ImportPolicy.Platforms, repoPath.Child("importPolicy", "platforms"))...)
 	}
 	if len(isi.Spec.Images) == 0 && isi.Spec.Repository == nil {
 		errs = append(errs, field.Invalid(imagesPath, nil, "you must specify at least one image or a repository import"))
@@ -679,6 +732,38 @@ func ValidateImageStreamImport(isi *imageapi.ImageStreamImport) field.ErrorList
 	return errs
 }
 
+// validateImportPlatforms ensures every platform allowlist entry is an
+// os/arch pair, so a malformed filter fails the request instead of silently
+// admitting every platform.
+func validateImportPlatforms(platforms []string, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	for i, platform := range platforms {
+		parts := strings.Split(platform, "/")
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			errs = append(errs, field.Invalid(fldPath.Index(i), platform, "must be an os/arch pair such as linux/amd64"))
+		}
+	}
+	return errs
+}
+
+// tagNameAnchoredRegexp matches complete, valid container image tag names.
+var tagNameAnchoredRegexp = regexp.MustCompile(`^` + reference.TagRegexp.String() + `$`)
+
+// importDestinationTag returns the tag an image import spec entry will
+// populate in the stream and the field path responsible for the choice. The
+// explicit to.name wins; without it the tag of the source reference is used,
+// mirroring the v1 defaulting. Entries that only reference a digest have no
+// destination.
+func importDestinationTag(spec imageapi.ImageImportSpec, path *field.Path) (string, *field.Path) {
+	if spec.To != nil {
+		return spec.To.Name, path.Child("to", "name")
+	}
+	if ref, err := imageref.Parse(spec.From.Name); err == nil {
+		return ref.Tag, path.Child("from", "name")
+	}
+	return "", nil
+}
+
 func isRepositoryInsecure(obj runtime.Object) bool {
 	accessor, err := kmeta.Accessor(obj)
 	if err != nil {
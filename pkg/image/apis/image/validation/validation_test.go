@@ -16,6 +16,7 @@ import (
 	imagev1 "github.com/openshift/api/image/v1"
 	openshiftcontrolplanev1 "github.com/openshift/api/openshiftcontrolplane/v1"
 	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	imageapiv1 "github.com/openshift/openshift-apiserver/pkg/image/apis/image/v1"
 	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/whitelist"
 )
 
@@ -607,6 +608,30 @@ func TestValidateImageStream(t *testing.T) {
 	}
 }
 
+func TestValidatePolicyAnnotations(t *testing.T) {
+	for name, test := range map[string]struct {
+		annotations    map[string]string
+		expectedErrors int
+	}{
+		"no policy annotations": {},
+		"malformed tag candidates annotation": {
+			annotations:    map[string]string{imageapiv1.TagCandidatesAnnotation: "not json"},
+			expectedErrors: 1,
+		},
+		"malformed tag gc policy annotation": {
+			annotations:    map[string]string{imageapiv1.TagGCPolicyAnnotation: "not json"},
+			expectedErrors: 1,
+		},
+	} {
+		stream := &imageapi.ImageStream{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+		errs := validatePolicyAnnotations(stream, field.NewPath("metadata", "annotations"))
+
+		if len(errs) != test.expectedErrors {
+			t.Errorf("%s: expected %d error(s), got %d. %v", name, test.expectedErrors, len(errs), errs)
+		}
+	}
+}
+
 func TestValidateImageStreamWithWhitelister(t *testing.T) {
 	for name, test := range map[string]struct {
 		namespace             string
@@ -1623,6 +1648,103 @@ func TestValidateImageStreamImport(t *testing.T) {
 				field.Invalid(field.NewPath("spec", "repository", "from", "name"), "a/b@sha256:something", "invalid reference format"),
 			},
 		},
+		"invalid destination tag": {
+			isi: &imageapi.ImageStreamImport{
+				ObjectMeta: validMeta, Spec: imageapi.ImageStreamImportSpec{
+					Images: []imageapi.ImageImportSpec{
+						{
+							From: kapi.ObjectReference{Kind: "DockerImage", Name: "a/b:v1.2.3"},
+							To:   &kapi.LocalObjectReference{Name: "-bad"},
+						},
+					},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "images").Index(0).Child("to", "name"), "-bad", "must be a valid container image tag"),
+			},
+		},
+		"invalid image import platform": {
+			isi: &imageapi.ImageStreamImport{
+				ObjectMeta: validMeta, Spec: imageapi.ImageStreamImportSpec{
+					Images: []imageapi.ImageImportSpec{
+						{
+							From:         kapi.ObjectReference{Kind: "DockerImage", Name: "a/b:v1"},
+							ImportPolicy: imageapi.TagImportPolicy{Platforms: []string{"linux/amd64", "windows"}},
+						},
+					},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "images").Index(0).Child("importPolicy", "platforms").Index(1), "windows", "must be an os/arch pair such as linux/amd64"),
+			},
+		},
+		"invalid repository import platform": {
+			isi: &imageapi.ImageStreamImport{
+				ObjectMeta: validMeta, Spec: imageapi.ImageStreamImportSpec{
+					Repository: &imageapi.RepositoryImportSpec{
+						From:         kapi.ObjectReference{Kind: "DockerImage", Name: "redis"},
+						ImportPolicy: imageapi.TagImportPolicy{Platforms: []string{"/amd64"}},
+					},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "repository", "importPolicy", "platforms").Index(0), "/amd64", "must be an os/arch pair such as linux/amd64"),
+			},
+		},
+		"duplicate destination tags": {
+			isi: &imageapi.ImageStreamImport{
+				ObjectMeta: validMeta, Spec: imageapi.ImageStreamImportSpec{
+					Images: []imageapi.ImageImportSpec{
+						{
+							From: kapi.ObjectReference{Kind: "DockerImage", Name: "a/b:v1.2.3"},
+							To:   &kapi.LocalObjectReference{Name: "stable"},
+						},
+						{
+							From: kapi.ObjectReference{Kind: "DockerImage", Name: "a/b:v2.0.0"},
+							To:   &kapi.LocalObjectReference{Name: "stable"},
+						},
+					},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "images").Index(1).Child("to", "name"), "stable", `tag "stable" is already the destination of images[0]`),
+			},
+		},
+		"duplicate destination via source tag": {
+			isi: &imageapi.ImageStreamImport{
+				ObjectMeta: validMeta, Spec: imageapi.ImageStreamImportSpec{
+					Images: []imageapi.ImageImportSpec{
+						{
+							From: kapi.ObjectReference{Kind: "DockerImage", Name: "a/b:v1"},
+						},
+						{
+							From: kapi.ObjectReference{Kind: "DockerImage", Name: "c/d:latest"},
+							To:   &kapi.LocalObjectReference{Name: "v1"},
+						},
+					},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "images").Index(1).Child("to", "name"), "v1", `tag "v1" is already the destination of images[0]`),
+			},
+		},
+		"renamed destination tags do not collide": {
+			isi: &imageapi.ImageStreamImport{
+				ObjectMeta: validMeta, Spec: imageapi.ImageStreamImportSpec{
+					Images: []imageapi.ImageImportSpec{
+						{
+							From: kapi.ObjectReference{Kind: "DockerImage", Name: "a/b:v1.2.3"},
+							To:   &kapi.LocalObjectReference{Name: "stable"},
+						},
+						{
+							From: kapi.ObjectReference{Kind: "DockerImage", Name: "a/b:v1.2.3"},
+							To:   &kapi.LocalObjectReference{Name: "v1.2.3"},
+						},
+					},
+				},
+			},
+			expected: field.ErrorList{},
+		},
 		"only DockerImage tags can be scheduled": {
 			isi: &imageapi.ImageStreamImport{
 				ObjectMeta: validMeta, Spec: imageapi.ImageStreamImportSpec{
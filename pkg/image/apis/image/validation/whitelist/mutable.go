@@ -0,0 +1,66 @@
+package whitelist
+
+import (
+	"context"
+	"sync"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+// MutableRegistryWhitelister forwards to a delegate whitelister that can be
+// swapped atomically while requests are being served. It allows registry
+// import policy to be reloaded without rebuilding the storage that holds a
+// reference to the whitelister.
+type MutableRegistryWhitelister struct {
+	lock     sync.RWMutex
+	delegate RegistryWhitelister
+}
+
+var _ RegistryWhitelister = &MutableRegistryWhitelister{}
+
+// NewMutableRegistryWhitelister returns a whitelister forwarding to the given
+// initial delegate until Swap replaces it.
+func NewMutableRegistryWhitelister(delegate RegistryWhitelister) *MutableRegistryWhitelister {
+	return &MutableRegistryWhitelister{delegate: delegate}
+}
+
+// Swap replaces the delegate whitelister. Admissions started before the swap
+// finish against the delegate they began with.
+func (w *MutableRegistryWhitelister) Swap(delegate RegistryWhitelister) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.delegate = delegate
+}
+
+func (w *MutableRegistryWhitelister) current() RegistryWhitelister {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	return w.delegate
+}
+
+func (w *MutableRegistryWhitelister) AdmitHostname(ctx context.Context, host string, transport WhitelistTransport) error {
+	return w.current().AdmitHostname(ctx, host, transport)
+}
+
+func (w *MutableRegistryWhitelister) AdmitPullSpec(ctx context.Context, pullSpec string, transport WhitelistTransport) error {
+	return w.current().AdmitPullSpec(ctx, pullSpec, transport)
+}
+
+func (w *MutableRegistryWhitelister) AdmitDockerImageReference(ctx context.Context, ref imageapi.DockerImageReference, transport WhitelistTransport) error {
+	return w.current().AdmitDockerImageReference(ctx, ref, transport)
+}
+
+func (w *MutableRegistryWhitelister) WhitelistRegistry(hostPortGlob string, transport WhitelistTransport) error {
+	return w.current().WhitelistRegistry(hostPortGlob, transport)
+}
+
+func (w *MutableRegistryWhitelister) WhitelistRepository(pullSpec string) error {
+	return w.current().WhitelistRepository(pullSpec)
+}
+
+// Copy returns a deep copy of the current delegate. The copy is detached from
+// future swaps, which is what validation wants: a consistent view for the
+// duration of a single request.
+func (w *MutableRegistryWhitelister) Copy() RegistryWhitelister {
+	return w.current().Copy()
+}
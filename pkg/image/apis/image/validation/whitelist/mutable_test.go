@@ -0,0 +1,45 @@
+package whitelist
+
+import (
+	"context"
+	"testing"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+func TestMutableRegistryWhitelisterSwap(t *testing.T) {
+	ctx := context.Background()
+	restricted, err := NewRegistryWhitelister(mkAllowed(false, "docker.io"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mutable := NewMutableRegistryWhitelister(restricted)
+
+	ref := imageapi.DockerImageReference{Registry: "example.com", Name: "repo"}
+	if err := mutable.AdmitDockerImageReference(ctx, ref, WhitelistTransportSecure); err == nil {
+		t.Error("expected example.com to be rejected before the swap")
+	}
+
+	mutable.Swap(WhitelistAllRegistries(ctx))
+	if err := mutable.AdmitDockerImageReference(ctx, ref, WhitelistTransportSecure); err != nil {
+		t.Errorf("expected example.com to be admitted after the swap: %v", err)
+	}
+}
+
+func TestMutableRegistryWhitelisterCopyIsDetached(t *testing.T) {
+	ctx := context.Background()
+	restricted, err := NewRegistryWhitelister(mkAllowed(false, "docker.io"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mutable := NewMutableRegistryWhitelister(restricted)
+	copied := mutable.Copy()
+	mutable.Swap(WhitelistAllRegistries(ctx))
+
+	ref := imageapi.DockerImageReference{Registry: "example.com", Name: "repo"}
+	if err := copied.AdmitDockerImageReference(ctx, ref, WhitelistTransportSecure); err == nil {
+		t.Error("expected the copy to keep rejecting example.com after the swap")
+	}
+}
@@ -198,7 +198,7 @@ func (in *ImageImportSpec) DeepCopyInto(out *ImageImportSpec) {
 		*out = new(core.LocalObjectReference)
 		**out = **in
 	}
-	out.ImportPolicy = in.ImportPolicy
+	in.ImportPolicy.DeepCopyInto(&out.ImportPolicy)
 	out.ReferencePolicy = in.ReferencePolicy
 	return
 }
@@ -259,6 +259,16 @@ func (in *ImageLayerData) DeepCopyInto(out *ImageLayerData) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.UncompressedSize != nil {
+		in, out := &in.UncompressedSize, &out.UncompressedSize
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Manifests != nil {
+		in, out := &in.Manifests, &out.Manifests
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -852,7 +862,7 @@ func (in *NamedTagEventList) DeepCopy() *NamedTagEventList {
 func (in *RepositoryImportSpec) DeepCopyInto(out *RepositoryImportSpec) {
 	*out = *in
 	out.From = in.From
-	out.ImportPolicy = in.ImportPolicy
+	in.ImportPolicy.DeepCopyInto(&out.ImportPolicy)
 	out.ReferencePolicy = in.ReferencePolicy
 	return
 }
@@ -1015,6 +1025,11 @@ func (in *TagEventList) DeepCopyInto(out *TagEventList) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Candidate != nil {
+		in, out := &in.Candidate, &out.Candidate
+		*out = new(TagEvent)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1031,6 +1046,11 @@ func (in *TagEventList) DeepCopy() *TagEventList {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TagImportPolicy) DeepCopyInto(out *TagImportPolicy) {
 	*out = *in
+	if in.Platforms != nil {
+		in, out := &in.Platforms, &out.Platforms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1064,8 +1084,18 @@ func (in *TagReference) DeepCopyInto(out *TagReference) {
 		*out = new(int64)
 		**out = **in
 	}
-	out.ImportPolicy = in.ImportPolicy
+	in.ImportPolicy.DeepCopyInto(&out.ImportPolicy)
 	out.ReferencePolicy = in.ReferencePolicy
+	if in.MaxHistoryItems != nil {
+		in, out := &in.MaxHistoryItems, &out.MaxHistoryItems
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxAgeSeconds != nil {
+		in, out := &in.MaxAgeSeconds, &out.MaxAgeSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
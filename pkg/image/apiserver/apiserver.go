@@ -33,27 +33,55 @@ import (
 	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/whitelist"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/importer"
 	imageimporter "github.com/openshift/openshift-apiserver/pkg/image/apiserver/importer"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/labelpolicy"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/garbagecollect"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/image"
 	imageetcd "github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/image/etcd"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagesecret"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagesignature"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream"
 	imagestreametcd "github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream/etcd"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestreamfreeze"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestreamimage"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestreamimport"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestreammapping"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestreampromote"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestreamtag"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagetag"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/importsoverview"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/limitverification"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registryhostname"
 )
 
 type ExtraConfig struct {
-	KubeAPIServerClientConfig          *restclient.Config
-	RegistryHostnameRetriever          registryhostname.RegistryHostnameRetriever
+	KubeAPIServerClientConfig *restclient.Config
+	RegistryHostnameRetriever registryhostname.RegistryHostnameRetriever
+	// RegistryWhitelister, when set, is used instead of building one from
+	// AllowedRegistriesForImport. Callers pass a mutable whitelister here so
+	// import policy can be reloaded while the server runs.
+	RegistryWhitelister                whitelist.RegistryWhitelister
 	AllowedRegistriesForImport         openshiftcontrolplanev1.AllowedRegistries
 	MaxImagesBulkImportedPerRepository int
-	AdditionalTrustedCA                []byte
-	OperatorInformers                  operatorinformers.SharedInformerFactory
+	// MaxImageStreamTagHistory bounds the number of history items kept per
+	// status tag; zero keeps the full history. Individual streams may
+	// override it with the image.openshift.io/max-tag-history annotation.
+	MaxImageStreamTagHistory int
+	// ImportLabelPolicy lists labels every imported image must carry and
+	// whether violations warn or reject.
+	ImportLabelPolicy        labelpolicy.Policy
+	ImportRegistryRateLimits map[string]imageimporter.RegistryLimit
+	// ImportScannerWebhookURL, when set, selects a webhook-backed scanner
+	// that attaches vulnerability metadata to every imported image.
+	ImportScannerWebhookURL string
+	// ImportCredentialProviderConfigFile, when set, names a file of exec
+	// credential provider plugins the importer consults for registries not
+	// covered by pull secrets.
+	ImportCredentialProviderConfigFile string
+	// ServeStaleReadsOnStorageError lets image stream GETs and LISTs fall
+	// back to a watch cache with a Warning header when etcd is unavailable.
+	ServeStaleReadsOnStorageError bool
+	AdditionalTrustedCA           []byte
+	OperatorInformers             operatorinformers.SharedInformerFactory
 
 	// TODO these should all become local eventually
 	Scheme *runtime.Scheme
@@ -221,24 +249,28 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 		return nil, fmt.Errorf("error building REST storage: %v", err)
 	}
 
-	var whitelister whitelist.RegistryWhitelister
-	if len(c.ExtraConfig.AllowedRegistriesForImport) > 0 {
-		whitelister, err = whitelist.NewRegistryWhitelister(
-			c.ExtraConfig.AllowedRegistriesForImport,
-			c.ExtraConfig.RegistryHostnameRetriever)
-		if err != nil {
-			return nil, fmt.Errorf("error building registry whitelister: %v", err)
+	whitelister := c.ExtraConfig.RegistryWhitelister
+	if whitelister == nil {
+		if len(c.ExtraConfig.AllowedRegistriesForImport) > 0 {
+			whitelister, err = whitelist.NewRegistryWhitelister(
+				c.ExtraConfig.AllowedRegistriesForImport,
+				c.ExtraConfig.RegistryHostnameRetriever)
+			if err != nil {
+				return nil, fmt.Errorf("error building registry whitelister: %v", err)
+			}
+		} else {
+			whitelister = whitelist.WhitelistAllRegistries(context.TODO())
 		}
-	} else {
-		whitelister = whitelist.WhitelistAllRegistries(context.TODO())
 	}
 
 	imageLayerIndex := imagestreametcd.NewImageLayerIndex(imageV1Client.ImageV1().Images())
 	c.ExtraConfig.startFns = append(c.ExtraConfig.startFns, imageLayerIndex.Run)
 
+	importHealthIndex := importsoverview.NewImportHealthIndex(imageV1Client.ImageV1().ImageStreams(metav1.NamespaceAll))
+	c.ExtraConfig.startFns = append(c.ExtraConfig.startFns, importHealthIndex.Run)
+
 	imageRegistry := image.NewRegistry(imageStorage)
 	imageSignatureStorage := imagesignature.NewREST(imageClient.ImageV1())
-	imageStreamSecretsStorage := imagesecret.NewREST(kubeClient.CoreV1())
 	imageStreamStorage, imageStreamLayersStorage, imageStreamStatusStorage, internalImageStreamStorage, err := imagestreametcd.NewREST(
 		c.GenericConfig.RESTOptionsGetter,
 		c.ExtraConfig.RegistryHostnameRetriever,
@@ -246,20 +278,43 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 		c.GenericConfig.SharedInformerFactory.Core().V1().LimitRanges(),
 		whitelister,
 		imageLayerIndex,
+		c.ExtraConfig.MaxImageStreamTagHistory,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error building REST storage: %v", err)
 	}
 	imageStreamRegistry := imagestream.NewRegistry(imageStreamStorage, imageStreamStatusStorage, internalImageStreamStorage)
-	imageStreamMappingStorage := imagestreammapping.NewREST(imageRegistry, imageStreamRegistry, c.ExtraConfig.RegistryHostnameRetriever)
-	imageStreamTagStorage := imagestreamtag.NewREST(imageRegistry, imageStreamRegistry, whitelister)
-	imageTagStorage := imagetag.NewREST(imageRegistry, imageStreamRegistry, whitelister)
+	var servedImageStreamStorage rest.Storage = imageStreamStorage
+	if c.ExtraConfig.ServeStaleReadsOnStorageError {
+		fallback := imagestreametcd.NewFallbackREST(imageStreamStorage, imageV1Client.ImageV1())
+		c.ExtraConfig.startFns = append(c.ExtraConfig.startFns, fallback.Run)
+		servedImageStreamStorage = fallback
+	}
+	imageStreamSecretsStorage := imagesecret.NewREST(kubeClient.CoreV1(), imageStreamRegistry, authorizationClient.SubjectAccessReviews())
+	imageStreamMappingStorage := imagestreammapping.NewREST(imageRegistry, imageStreamRegistry, authorizationClient.SubjectAccessReviews(), c.ExtraConfig.RegistryHostnameRetriever, c.ExtraConfig.ImportLabelPolicy)
+	imageStreamTagStorage := imagestreamtag.NewREST(imageRegistry, imageStreamRegistry, whitelister, c.ExtraConfig.RegistryHostnameRetriever)
+	imageTagStorage := imagetag.NewREST(imageRegistry, imageStreamRegistry, whitelister, c.ExtraConfig.RegistryHostnameRetriever)
 	importerCache, err := imageimporter.NewImageStreamLayerCache(imageimporter.DefaultImageStreamLayerCacheSize)
 	if err != nil {
 		return nil, fmt.Errorf("error building REST storage: %v", err)
 	}
+	// the registry limiter is shared by all importer instances so that the
+	// configured per-registry limits hold across concurrent import requests
+	registryLimiter := imageimporter.NewImportRateLimiter(c.ExtraConfig.ImportRegistryRateLimits)
+	importScanner := imageimporter.NewNoopScanner()
+	if len(c.ExtraConfig.ImportScannerWebhookURL) > 0 {
+		importScanner = imageimporter.NewWebhookScanner(c.ExtraConfig.ImportScannerWebhookURL, nil)
+	}
+	var credentialProviders *imageimporter.CredentialProviders
+	if len(c.ExtraConfig.ImportCredentialProviderConfigFile) > 0 {
+		providerConfig, err := imageimporter.ReadCredentialProviderConfig(c.ExtraConfig.ImportCredentialProviderConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("error building REST storage: %v", err)
+		}
+		credentialProviders = imageimporter.NewCredentialProviders(providerConfig)
+	}
 	importerFn := func(r importer.RepositoryRetriever, regConf *sysregistriesv2.V2RegistriesConf) imageimporter.Interface {
-		return imageimporter.NewImageStreamImporter(r, regConf, c.ExtraConfig.MaxImagesBulkImportedPerRepository, flowcontrol.NewTokenBucketRateLimiter(2.0, 3), &importerCache)
+		return imageimporter.NewImageStreamImporter(r, regConf, c.ExtraConfig.MaxImagesBulkImportedPerRepository, flowcontrol.NewTokenBucketRateLimiter(2.0, 3), registryLimiter, &importerCache, importScanner)
 	}
 	imageStreamImportStorage := imagestreamimport.NewREST(
 		importerFn,
@@ -273,6 +328,8 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 		authorizationClient.SubjectAccessReviews(),
 		c.ExtraConfig.OperatorInformers.Operator().V1alpha1().ImageContentSourcePolicies().Lister(),
 		configV1Client.ConfigV1(),
+		c.ExtraConfig.ImportLabelPolicy,
+		credentialProviders,
 	)
 	imageStreamImageStorage := imagestreamimage.NewREST(imageRegistry, imageStreamRegistry)
 
@@ -280,13 +337,20 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 	v1Storage["images"] = imageStorage
 	v1Storage["imagesignatures"] = imageSignatureStorage
 	v1Storage["imageStreams/secrets"] = imageStreamSecretsStorage
-	v1Storage["imageStreams"] = imageStreamStorage
+	v1Storage["imageStreams"] = servedImageStreamStorage
 	v1Storage["imageStreams/layers"] = imageStreamLayersStorage
 	v1Storage["imageStreams/status"] = imageStreamStatusStorage
+	v1Storage["imageStreams/garbage-collect"] = garbagecollect.NewREST(imageStreamRegistry, imageLayerIndex)
+	v1Storage["imageStreams/limit-verification"] = limitverification.NewREST(c.GenericConfig.SharedInformerFactory.Core().V1().LimitRanges())
+	v1Storage["imageStreams/promote"] = imagestreampromote.NewREST(imageStreamRegistry)
+	v1Storage["imageStreams/freeze"] = imagestreamfreeze.NewFreezeREST(imageClient.ImageV1())
+	v1Storage["imageStreams/unfreeze"] = imagestreamfreeze.NewUnfreezeREST(imageClient.ImageV1())
 	v1Storage["imageStreamImports"] = imageStreamImportStorage
+	v1Storage["imageStreamImports/layout"] = imagestreamimport.NewLayoutREST(imageStreamImportStorage)
 	v1Storage["imageStreamImages"] = imageStreamImageStorage
 	v1Storage["imageStreamMappings"] = imageStreamMappingStorage
 	v1Storage["imageStreamTags"] = imageStreamTagStorage
 	v1Storage["imageTags"] = imageTagStorage
+	v1Storage["imageImportsOverviews"] = importsoverview.NewREST(importHealthIndex)
 	return v1Storage, nil
 }
@@ -0,0 +1,223 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// credentialProviderTimeout bounds a single plugin invocation so a hung
+// plugin cannot stall an import request indefinitely.
+const credentialProviderTimeout = 30 * time.Second
+
+// defaultCredentialCacheDuration is used when neither the plugin response
+// nor the provider configuration names a cache duration.
+const defaultCredentialCacheDuration = 5 * time.Minute
+
+// CredentialProviderConfig configures exec-based credential provider
+// plugins for image import, in the spirit of kubelet credential providers.
+// Plugins mint short-lived registry credentials at import time, so importing
+// from registries such as ECR, ACR or GAR does not require long-lived
+// dockercfg secrets in every namespace.
+type CredentialProviderConfig struct {
+	Providers []CredentialProvider `json:"providers"`
+}
+
+// CredentialProvider describes one plugin and the registries it serves.
+type CredentialProvider struct {
+	// Name identifies the provider in logs and cache keys.
+	Name string `json:"name"`
+	// MatchImages lists glob patterns matched against the registry host of
+	// the image being imported, such as "*.dkr.ecr.*.amazonaws.com".
+	MatchImages []string `json:"matchImages"`
+	// Command is the plugin executable to run.
+	Command string `json:"command"`
+	// Args are passed to the command on every invocation.
+	Args []string `json:"args,omitempty"`
+	// Env entries of the form KEY=VALUE are appended to the apiserver's
+	// environment for the invocation.
+	Env []string `json:"env,omitempty"`
+	// DefaultCacheDuration is how long minted credentials are reused when
+	// the plugin response does not carry its own duration.
+	DefaultCacheDuration metav1.Duration `json:"defaultCacheDuration,omitempty"`
+}
+
+// credentialProviderRequest is written to the plugin's stdin.
+type credentialProviderRequest struct {
+	// Image is the image being imported, without tag or digest.
+	Image string `json:"image"`
+}
+
+// credentialProviderResponse is read from the plugin's stdout.
+type credentialProviderResponse struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// CacheDuration, when set, overrides the provider's default.
+	CacheDuration *metav1.Duration `json:"cacheDuration,omitempty"`
+}
+
+// ReadCredentialProviderConfig loads and validates a provider configuration
+// from the given JSON file.
+func ReadCredentialProviderConfig(filename string) (*CredentialProviderConfig, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	config := &CredentialProviderConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid credential provider config: %v", err)
+	}
+	if len(config.Providers) == 0 {
+		return nil, fmt.Errorf("invalid credential provider config: must list at least one provider")
+	}
+	seen := map[string]bool{}
+	for i, provider := range config.Providers {
+		if len(provider.Name) == 0 {
+			return nil, fmt.Errorf("invalid credential provider config: providers[%d] must have a name", i)
+		}
+		if seen[provider.Name] {
+			return nil, fmt.Errorf("invalid credential provider config: provider %q is listed twice", provider.Name)
+		}
+		seen[provider.Name] = true
+		if len(provider.Command) == 0 {
+			return nil, fmt.Errorf("invalid credential provider config: provider %q must have a command", provider.Name)
+		}
+		if len(provider.MatchImages) == 0 {
+			return nil, fmt.Errorf("invalid credential provider config: provider %q must match at least one image pattern", provider.Name)
+		}
+		for _, pattern := range provider.MatchImages {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid credential provider config: provider %q has a malformed pattern %q: %v", provider.Name, pattern, err)
+			}
+		}
+	}
+	return config, nil
+}
+
+// CredentialProviders invokes configured plugins to mint registry
+// credentials and caches the results across import requests.
+type CredentialProviders struct {
+	providers []CredentialProvider
+
+	lock  sync.Mutex
+	cache map[string]cachedCredential
+}
+
+type cachedCredential struct {
+	username string
+	password string
+	expires  time.Time
+}
+
+// NewCredentialProviders returns a provider set backed by the given
+// configuration.
+func NewCredentialProviders(config *CredentialProviderConfig) *CredentialProviders {
+	return &CredentialProviders{
+		providers: config.Providers,
+		cache:     map[string]cachedCredential{},
+	}
+}
+
+// Credentials returns the username and password a configured plugin minted
+// for the registry host, or false when no provider matches the host or the
+// matching plugin fails. Plugin failures are logged and treated as a miss so
+// the import proceeds with whatever other credentials are available.
+func (c *CredentialProviders) Credentials(ctx context.Context, host, image string) (string, string, bool) {
+	if c == nil {
+		return "", "", false
+	}
+	for i := range c.providers {
+		provider := &c.providers[i]
+		if !providerMatchesHost(provider, host) {
+			continue
+		}
+		key := provider.Name + "/" + host
+		if username, password, ok := c.cachedCredentials(key); ok {
+			return username, password, true
+		}
+		response, err := invokeCredentialProvider(ctx, provider, image)
+		if err != nil {
+			klog.Warningf("credential provider %q failed for registry %s: %v", provider.Name, host, err)
+			return "", "", false
+		}
+		c.storeCredentials(key, provider, response)
+		return response.Username, response.Password, true
+	}
+	return "", "", false
+}
+
+func (c *CredentialProviders) cachedCredentials(key string) (string, string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cached, ok := c.cache[key]
+	if !ok || time.Now().After(cached.expires) {
+		return "", "", false
+	}
+	return cached.username, cached.password, true
+}
+
+func (c *CredentialProviders) storeCredentials(key string, provider *CredentialProvider, response *credentialProviderResponse) {
+	duration := defaultCredentialCacheDuration
+	if provider.DefaultCacheDuration.Duration > 0 {
+		duration = provider.DefaultCacheDuration.Duration
+	}
+	if response.CacheDuration != nil {
+		duration = response.CacheDuration.Duration
+	}
+	if duration <= 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache[key] = cachedCredential{
+		username: response.Username,
+		password: response.Password,
+		expires:  time.Now().Add(duration),
+	}
+}
+
+func providerMatchesHost(provider *CredentialProvider, host string) bool {
+	for _, pattern := range provider.MatchImages {
+		if matched, err := path.Match(pattern, host); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func invokeCredentialProvider(ctx context.Context, provider *CredentialProvider, image string) (*credentialProviderResponse, error) {
+	request, err := json.Marshal(credentialProviderRequest{Image: image})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, credentialProviderTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, provider.Command, provider.Args...)
+	cmd.Env = append(os.Environ(), provider.Env...)
+	cmd.Stdin = bytes.NewReader(request)
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, stderr.String())
+	}
+	response := &credentialProviderResponse{}
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return nil, fmt.Errorf("invalid plugin response: %v", err)
+	}
+	if len(response.Username) == 0 && len(response.Password) == 0 {
+		return nil, fmt.Errorf("plugin response carries no credentials")
+	}
+	return response, nil
+}
@@ -0,0 +1,204 @@
+package importer
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func writeProviderConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadCredentialProviderConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      string
+		expectedErr string
+	}{
+		{
+			name:   "valid",
+			config: `{"providers":[{"name":"ecr","matchImages":["*.dkr.ecr.*.amazonaws.com"],"command":"ecr-helper"}]}`,
+		},
+		{
+			name:        "not json",
+			config:      `not json`,
+			expectedErr: "invalid credential provider config",
+		},
+		{
+			name:        "no providers",
+			config:      `{"providers":[]}`,
+			expectedErr: "must list at least one provider",
+		},
+		{
+			name:        "missing name",
+			config:      `{"providers":[{"matchImages":["*"],"command":"helper"}]}`,
+			expectedErr: "providers[0] must have a name",
+		},
+		{
+			name:        "duplicate name",
+			config:      `{"providers":[{"name":"a","matchImages":["*"],"command":"helper"},{"name":"a","matchImages":["*"],"command":"helper"}]}`,
+			expectedErr: `provider "a" is listed twice`,
+		},
+		{
+			name:        "missing command",
+			config:      `{"providers":[{"name":"a","matchImages":["*"]}]}`,
+			expectedErr: `provider "a" must have a command`,
+		},
+		{
+			name:        "missing patterns",
+			config:      `{"providers":[{"name":"a","command":"helper"}]}`,
+			expectedErr: `provider "a" must match at least one image pattern`,
+		},
+		{
+			name:        "malformed pattern",
+			config:      `{"providers":[{"name":"a","matchImages":["[invalid"],"command":"helper"}]}`,
+			expectedErr: `provider "a" has a malformed pattern`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := ReadCredentialProviderConfig(writeProviderConfig(t, testCase.config))
+			if len(testCase.expectedErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), testCase.expectedErr) {
+				t.Fatalf("expected error containing %q, got: %v", testCase.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestProviderMatchesHost(t *testing.T) {
+	provider := &CredentialProvider{
+		MatchImages: []string{"*.dkr.ecr.*.amazonaws.com", "registry.example.com"},
+	}
+	testCases := []struct {
+		host    string
+		matches bool
+	}{
+		{host: "123456789.dkr.ecr.us-east-1.amazonaws.com", matches: true},
+		{host: "registry.example.com", matches: true},
+		{host: "quay.io", matches: false},
+		{host: "registry.example.com.evil.io", matches: false},
+	}
+	for _, testCase := range testCases {
+		if matches := providerMatchesHost(provider, testCase.host); matches != testCase.matches {
+			t.Errorf("%s: expected match=%t, got %t", testCase.host, testCase.matches, matches)
+		}
+	}
+}
+
+func writeProviderPlugin(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plugin.sh")
+	if err := ioutil.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCredentialProvidersExec(t *testing.T) {
+	countFile := filepath.Join(t.TempDir(), "count")
+	plugin := writeProviderPlugin(t, `#!/bin/sh
+echo x >> `+countFile+`
+echo '{"username":"token","password":"secret"}'
+`)
+	providers := NewCredentialProviders(&CredentialProviderConfig{
+		Providers: []CredentialProvider{{
+			Name:        "test",
+			MatchImages: []string{"registry.example.com"},
+			Command:     plugin,
+		}},
+	})
+
+	username, password, found := providers.Credentials(context.TODO(), "registry.example.com", "registry.example.com/ns/app")
+	if !found || username != "token" || password != "secret" {
+		t.Fatalf("expected minted credentials, got found=%t username=%q password=%q", found, username, password)
+	}
+	if _, _, found := providers.Credentials(context.TODO(), "quay.io", "quay.io/ns/app"); found {
+		t.Fatal("expected no credentials for an unmatched registry")
+	}
+
+	// the second matching lookup must be served from the cache
+	if _, _, found := providers.Credentials(context.TODO(), "registry.example.com", "registry.example.com/ns/app"); !found {
+		t.Fatal("expected cached credentials")
+	}
+	invocations, err := ioutil.ReadFile(countFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := strings.Count(string(invocations), "x"); count != 1 {
+		t.Errorf("expected 1 plugin invocation, got %d", count)
+	}
+}
+
+func TestCredentialProvidersExecFailure(t *testing.T) {
+	plugin := writeProviderPlugin(t, `#!/bin/sh
+echo "no token for you" >&2
+exit 1
+`)
+	providers := NewCredentialProviders(&CredentialProviderConfig{
+		Providers: []CredentialProvider{{
+			Name:        "test",
+			MatchImages: []string{"*"},
+			Command:     plugin,
+		}},
+	})
+	if _, _, found := providers.Credentials(context.TODO(), "registry.example.com", "registry.example.com/ns/app"); found {
+		t.Fatal("expected no credentials from a failing plugin")
+	}
+}
+
+func TestCredentialProvidersCacheExpiry(t *testing.T) {
+	plugin := writeProviderPlugin(t, `#!/bin/sh
+echo '{"username":"token","password":"secret","cacheDuration":"1ns"}'
+`)
+	providers := NewCredentialProviders(&CredentialProviderConfig{
+		Providers: []CredentialProvider{{
+			Name:                 "test",
+			MatchImages:          []string{"*"},
+			Command:              plugin,
+			DefaultCacheDuration: metav1.Duration{Duration: time.Hour},
+		}},
+	})
+	if _, _, found := providers.Credentials(context.TODO(), "registry.example.com", "registry.example.com/ns/app"); !found {
+		t.Fatal("expected minted credentials")
+	}
+	if _, _, ok := providers.cachedCredentials("test/registry.example.com"); ok {
+		t.Fatal("expected the 1ns response duration to override the provider default and expire immediately")
+	}
+}
+
+func TestCredentialProvidersEnv(t *testing.T) {
+	plugin := writeProviderPlugin(t, `#!/bin/sh
+echo "{\"username\":\"$PROVIDER_USER\",\"password\":\"secret\"}"
+`)
+	os.Unsetenv("PROVIDER_USER")
+	providers := NewCredentialProviders(&CredentialProviderConfig{
+		Providers: []CredentialProvider{{
+			Name:        "test",
+			MatchImages: []string{"*"},
+			Command:     plugin,
+			Env:         []string{"PROVIDER_USER=fromenv"},
+		}},
+	})
+	username, _, found := providers.Credentials(context.TODO(), "registry.example.com", "registry.example.com/ns/app")
+	if !found || username != "fromenv" {
+		t.Fatalf("expected the configured environment to reach the plugin, got found=%t username=%q", found, username)
+	}
+}
@@ -103,7 +103,7 @@ func TestImageStreamImportQuayIO(t *testing.T) {
 	}
 
 	err := retryWhenUnreachable(t, func() error {
-		i := importer.NewImageStreamImporter(importCtx, nil, 3, nil, nil)
+		i := importer.NewImageStreamImporter(importCtx, nil, 3, nil, nil, nil, nil)
 		if err := i.Import(context.Background(), imports, &imageapi.ImageStream{}); err != nil {
 			return err
 		}
@@ -156,7 +156,7 @@ func TestImageStreamImportRedHatRegistry(t *testing.T) {
 		},
 	}
 
-	i := importer.NewImageStreamImporter(importCtx, nil, 3, nil, nil)
+	i := importer.NewImageStreamImporter(importCtx, nil, 3, nil, nil, nil, nil)
 	if err := i.Import(context.Background(), imports, &imageapi.ImageStream{}); err != nil {
 		t.Fatal(err)
 	}
@@ -183,7 +183,7 @@ func TestImageStreamImportRedHatRegistry(t *testing.T) {
 	context := context.Background()
 	importCtx = importer.NewStaticCredentialsContext(rt, nil, nil)
 	err := retryWhenUnreachable(t, func() error {
-		i = importer.NewImageStreamImporter(importCtx, nil, 3, nil, nil)
+		i = importer.NewImageStreamImporter(importCtx, nil, 3, nil, nil, nil, nil)
 		if err := i.Import(context, imports, &imageapi.ImageStream{}); err != nil {
 			return err
 		}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/containers/image/pkg/sysregistriesv2"
 	"github.com/docker/distribution"
@@ -26,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/klog/v2"
+	utiltrace "k8s.io/utils/trace"
 
 	"github.com/openshift/api/image"
 	imagev1 "github.com/openshift/api/image/v1"
@@ -35,6 +37,13 @@ import (
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/internalimageutil"
 )
 
+// ManifestSourceAnnotation is set on an imported image when its manifest was
+// served by a configured mirror rather than the repository the import asked
+// for, recording the repository that actually answered.  The annotation rides
+// along on the image in the import status, so disconnected clusters can
+// verify which source satisfied an import.
+const ManifestSourceAnnotation = "image.openshift.io/manifest-source"
+
 // Interface loads images into an image stream import request.
 type Interface interface {
 	Import(ctx context.Context, isi *imageapi.ImageStreamImport, stream *imageapi.ImageStream) error
@@ -52,9 +61,11 @@ type RepositoryRetriever interface {
 type ImageStreamImporter struct {
 	maximumTagsPerRepo int
 
-	retriever RepositoryRetriever
-	limiter   flowcontrol.RateLimiter
-	regConf   *sysregistriesv2.V2RegistriesConf
+	retriever       RepositoryRetriever
+	limiter         flowcontrol.RateLimiter
+	registryLimiter *ImportRateLimiter
+	regConf         *sysregistriesv2.V2RegistriesConf
+	scanner         Scanner
 
 	digestToRepositoryCache map[context.Context]map[manifestKey]*imageapi.Image
 
@@ -63,26 +74,35 @@ type ImageStreamImporter struct {
 }
 
 // NewImageStreamImporter creates an importer that will load images from a remote container image
-// registry into an ImageStreamImport object. Limiter may be nil.
+// registry into an ImageStreamImport object. Limiter, registryLimiter and scanner may be nil; the
+// registryLimiter must be shared between importer instances for its per-registry limits to be
+// effective.
 func NewImageStreamImporter(
 	retriever RepositoryRetriever,
 	regConf *sysregistriesv2.V2RegistriesConf,
 	maximumTagsPerRepo int,
 	limiter flowcontrol.RateLimiter,
+	registryLimiter *ImportRateLimiter,
 	cache *ImageStreamLayerCache,
+	scanner Scanner,
 ) *ImageStreamImporter {
 	if limiter == nil {
 		limiter = flowcontrol.NewFakeAlwaysRateLimiter()
 	}
+	if scanner == nil {
+		scanner = NewNoopScanner()
+	}
 	if cache == nil {
 		klog.V(5).Infof("the global layer cache is disabled")
 	}
 	return &ImageStreamImporter{
 		maximumTagsPerRepo: maximumTagsPerRepo,
 
-		retriever: retriever,
-		limiter:   limiter,
-		regConf:   regConf,
+		retriever:       retriever,
+		limiter:         limiter,
+		registryLimiter: registryLimiter,
+		regConf:         regConf,
+		scanner:         scanner,
 
 		// XXX The context used to index here is the client request's context. We might
 		// be able to get rid of this index at all as ImageStreamImporter is instantiated
@@ -159,6 +179,7 @@ func (imp *ImageStreamImporter) importImages(ctx context.Context, isi *imageapi.
 		if from.Kind != "DockerImage" {
 			continue
 		}
+		countImportRequest(spec.ImportPolicy.Scheduled)
 		// TODO: This should be removed in 1.6
 		// See for more info: https://github.com/openshift/origin/pull/11774#issuecomment-258905994
 		var (
@@ -176,6 +197,7 @@ func (imp *ImageStreamImporter) importImages(ctx context.Context, isi *imageapi.
 		}
 
 		if imp.blockedRegistry(ref) {
+			recordBlockedImport(ref.DockerClientDefaults().Registry)
 			isi.Status.Images[i].Status = forbiddenStatus(
 				fmt.Errorf("registry %s blocked", ref.Registry),
 			)
@@ -248,6 +270,11 @@ func (imp *ImageStreamImporter) importImages(ctx context.Context, isi *imageapi.
 					setImageImportStatus(isi, index, tag.Name, tag.Err)
 					continue
 				}
+				if platforms := isi.Spec.Images[index].ImportPolicy.Platforms; !platformAllowed(tag.Image, platforms) {
+					isi.Status.Images[index].Tag = tag.Name
+					isi.Status.Images[index].Status = forbiddenStatus(platformMismatchError(tag.Image, platforms))
+					continue
+				}
 				copied := *tag.Image
 				image := &isi.Status.Images[index]
 				ref := repo.Ref
@@ -269,6 +296,10 @@ func (imp *ImageStreamImporter) importImages(ctx context.Context, isi *imageapi.
 					setImageImportStatus(isi, index, "", digest.Err)
 					continue
 				}
+				if platforms := isi.Spec.Images[index].ImportPolicy.Platforms; !platformAllowed(digest.Image, platforms) {
+					isi.Status.Images[index].Status = forbiddenStatus(platformMismatchError(digest.Image, platforms))
+					continue
+				}
 				image := &isi.Status.Images[index]
 				copied := *digest.Image
 				ref := repo.Ref
@@ -298,6 +329,7 @@ func (imp *ImageStreamImporter) importFromRepository(ctx context.Context, isi *i
 	if from.Kind != "DockerImage" {
 		return
 	}
+	countImportRequest(spec.ImportPolicy.Scheduled)
 	// TODO: This should be removed in 1.6
 	// See for more info: https://github.com/openshift/origin/pull/11774#issuecomment-258905994
 	var (
@@ -315,6 +347,7 @@ func (imp *ImageStreamImporter) importFromRepository(ctx context.Context, isi *i
 	}
 
 	if imp.blockedRegistry(ref) {
+		recordBlockedImport(ref.DockerClientDefaults().Registry)
 		status.Status = forbiddenStatus(fmt.Errorf("registry %s blocked", ref.Registry))
 		return
 	}
@@ -323,6 +356,12 @@ func (imp *ImageStreamImporter) importFromRepository(ctx context.Context, isi *i
 	repoName := defaultRef.RepositoryName()
 	registryURL := defaultRef.RegistryURL()
 
+	selection, err := ParseRepositoryTagSelection(isi.Annotations)
+	if err != nil {
+		status.Status = invalidStatus("", field.Invalid(field.NewPath("metadata", "annotations"), "", err.Error()))
+		return
+	}
+
 	key := repositoryKey{url: *registryURL, name: repoName}
 	repo := &importRepository{
 		Ref:         ref,
@@ -330,6 +369,13 @@ func (imp *ImageStreamImporter) importFromRepository(ctx context.Context, isi *i
 		Name:        key.name,
 		Insecure:    imp.allowRegistryInsecureAccess(spec.ImportPolicy, ref),
 		MaximumTags: imp.maximumTagsPerRepo,
+		Selection:   selection,
+	}
+	// the annotation can only narrow the server-wide tag cap, never raise it
+	if selection != nil && selection.Limit > 0 {
+		if repo.MaximumTags == -1 || selection.Limit < repo.MaximumTags {
+			repo.MaximumTags = selection.Limit
+		}
 	}
 	imp.importRepositoryFromDocker(ctx, repo)
 
@@ -363,6 +409,11 @@ func (imp *ImageStreamImporter) importFromRepository(ctx context.Context, isi *i
 			status.Images[i].Status = imageImportStatus(tag.Err, "", "repository")
 			continue
 		}
+		if platforms := spec.ImportPolicy.Platforms; !platformAllowed(tag.Image, platforms) {
+			failures++
+			status.Images[i].Status = forbiddenStatus(platformMismatchError(tag.Image, platforms))
+			continue
+		}
 		status.Images[i].Status.Status = metav1.StatusSuccess
 
 		copied := *tag.Image
@@ -392,6 +443,23 @@ func applyErrorToRepository(repository *importRepository, err error) {
 	// accessed from a mirror.
 }
 
+// applyContextErrorToRepository marks every tag and digest that has not been
+// resolved yet with the context error, so a cancelled request reports why the
+// remaining imports were not attempted.
+func applyContextErrorToRepository(repository *importRepository, err error) {
+	repository.Err = err
+	for i := range repository.Digests {
+		if repository.Digests[i].Err == nil && repository.Digests[i].Image == nil {
+			repository.Digests[i].Err = err
+		}
+	}
+	for i := range repository.Tags {
+		if repository.Tags[i].Err == nil && repository.Tags[i].Image == nil {
+			repository.Tags[i].Err = err
+		}
+	}
+}
+
 func formatPingError(imageRef imageref.DockerImageReference, insecure bool, err error) error {
 	switch {
 	case err == reference.ErrReferenceInvalidFormat:
@@ -552,11 +620,15 @@ func (imp *ImageStreamImporter) getManifestFromSource(
 	return manifest, ms, repo.Blobs(ctx), nil
 }
 
-// getManifestFromSource pulls a manifest from the source respecting
-// V2RegistriesConf.
+// getManifest pulls a manifest from the source respecting V2RegistriesConf,
+// trying configured mirrors before the canonical repository.  On success it
+// also returns the reference of the pull source that served the manifest.
 func (imp *ImageStreamImporter) getManifest(
 	ctx context.Context, ref reference.Named, insecure bool,
-) (distribution.Manifest, distribution.ManifestService, distribution.BlobStore, error) {
+) (distribution.Manifest, distribution.ManifestService, distribution.BlobStore, reference.Named, error) {
+	trace := utiltrace.FromContext(ctx).Nest("Fetch manifest", utiltrace.Field{Key: "ref", Value: ref.String()})
+	defer trace.Log()
+
 	var errs []error
 
 	pullSources, err := imp.getPullSources(ref)
@@ -584,7 +656,7 @@ func (imp *ImageStreamImporter) getManifest(
 
 		klog.V(5).Infof("importing %s: got manifest from %s", ref.String(), pullSource.Reference)
 
-		return manifest, ms, bs, nil
+		return manifest, ms, bs, pullSource.Reference, nil
 	}
 
 	if len(errs) == 1 {
@@ -592,9 +664,23 @@ func (imp *ImageStreamImporter) getManifest(
 		// Unauthorized) that provides a meaningful Reason. NewAggregate will
 		// hide this Reason, so it's better to not aggregate a single error.
 		// Eventually we need a better way to aggregate errors.
-		return nil, nil, nil, errs[0]
+		return nil, nil, nil, nil, errs[0]
 	}
-	return nil, nil, nil, utilerrors.NewAggregate(errs)
+	return nil, nil, nil, nil, utilerrors.NewAggregate(errs)
+}
+
+// recordManifestSource annotates image with the repository that served its
+// manifest when it differs from the repository the import asked for.  The
+// image's docker reference always names the canonical repository, so without
+// the annotation there is no record that a mirror answered.
+func recordManifestSource(image *imageapi.Image, requested, served reference.Named) {
+	if image == nil || served == nil || served.Name() == requested.Name() {
+		return
+	}
+	if image.Annotations == nil {
+		image.Annotations = map[string]string{}
+	}
+	image.Annotations[ManifestSourceAnnotation] = served.Name()
 }
 
 func manifestFromManifestList(
@@ -698,16 +784,69 @@ func (imp *ImageStreamImporter) importManifest(
 			return image, err
 		}
 	}
+
+	imp.attachScanMetadata(ctx, image)
 	return
 }
 
+// attachScanMetadata asks the configured scanner for metadata about an imported image and merges
+// the returned annotations into it. Scanning is best effort: a failure leaves the image without
+// the metadata but does not fail the import.
+func (imp *ImageStreamImporter) attachScanMetadata(ctx context.Context, image *imageapi.Image) {
+	annotations, err := imp.scanner.ScanImage(ctx, image)
+	if err != nil {
+		klog.V(2).Infof("unable to scan image %s: %v", image.Name, err)
+		return
+	}
+	if len(annotations) == 0 {
+		return
+	}
+	if image.Annotations == nil {
+		image.Annotations = make(map[string]string, len(annotations))
+	}
+	for k, v := range annotations {
+		image.Annotations[k] = v
+	}
+}
+
 // importRepositoryFromDocker loads the tags and images requested in the passed importRepository, obeying the
 // optional rate limiter.  Errors are set onto the individual tags and digest objects.
 func (imp *ImageStreamImporter) importRepositoryFromDocker(ctx context.Context, repository *importRepository) {
 	klog.V(5).Infof("importing remote Docker repository registry=%s repository=%s insecure=%t", repository.Registry, repository.Name, repository.Insecure)
+	start := time.Now()
+	defer func() {
+		recordRepositoryImport(repository, time.Since(start))
+	}()
+
+	trace := utiltrace.FromContext(ctx).Nest("Import repository",
+		utiltrace.Field{Key: "registry", Value: repository.Registry.Host},
+		utiltrace.Field{Key: "repository", Value: repository.Name})
+	defer trace.Log()
+	ctx = utiltrace.ContextWithTrace(ctx, trace)
+
+	done, err := imp.registryLimiter.Begin(ctx, repository.Registry.Host)
+	if err != nil {
+		applyContextErrorToRepository(repository, err)
+		return
+	}
+	defer done()
+	trace.Step("Acquired registry rate limit slot")
+
+	// the request may have been cancelled while waiting for the registry slot
+	if err := ctx.Err(); err != nil {
+		applyContextErrorToRepository(repository, err)
+		return
+	}
 
 	// load digests
 	for i := range repository.Digests {
+		// give up on the remaining work when the request that started the
+		// import has gone away
+		if err := ctx.Err(); err != nil {
+			applyContextErrorToRepository(repository, err)
+			return
+		}
+
 		importDigest := &repository.Digests[i]
 		if importDigest.Err != nil || importDigest.Image != nil {
 			continue
@@ -729,9 +868,16 @@ func (imp *ImageStreamImporter) importRepositoryFromDocker(ctx context.Context,
 			continue
 		}
 
-		imp.limiter.Accept()
+		if err := imp.limiter.Wait(ctx); err != nil {
+			applyContextErrorToRepository(repository, err)
+			return
+		}
+		if err := imp.registryLimiter.Accept(ctx, repository.Registry.Host); err != nil {
+			applyContextErrorToRepository(repository, err)
+			return
+		}
 
-		manifest, ms, bs, err := imp.getManifest(ctx, dockerRef, repository.Insecure)
+		manifest, ms, bs, source, err := imp.getManifest(ctx, dockerRef, repository.Insecure)
 		if err != nil {
 			klog.V(5).Infof("unable to get manifest by digest %s for image %s: %v", d, ref.Exact(), err)
 			importDigest.Err = err
@@ -739,6 +885,12 @@ func (imp *ImageStreamImporter) importRepositoryFromDocker(ctx context.Context,
 		}
 
 		importDigest.Image, importDigest.Err = imp.importManifest(ctx, manifest, dockerRef, d, ms, bs, "", "")
+		if importDigest.Err == nil {
+			recordManifestSource(importDigest.Image, dockerRef, source)
+		}
+	}
+	if len(repository.Digests) > 0 {
+		trace.Step("Fetched manifests by digest", utiltrace.Field{Key: "digests", Value: len(repository.Digests)})
 	}
 
 	// if repository import is requested (MaximumTags), attempt to load the tags, sort them, and request the first N
@@ -770,6 +922,7 @@ func (imp *ImageStreamImporter) importRepositoryFromDocker(ctx context.Context,
 			repository.Err = err
 			return
 		}
+		trace.Step("Listed repository tags", utiltrace.Field{Key: "tags", Value: len(tags)})
 		// some images on the Hub have empty tags - treat those as "latest"
 		set := sets.NewString(tags...)
 		if set.Has("") {
@@ -777,8 +930,15 @@ func (imp *ImageStreamImporter) importRepositoryFromDocker(ctx context.Context,
 			set.Insert(imagev1.DefaultImageTag)
 		}
 		tags = set.List()
-		// include only the top N tags in the result, put the rest in AdditionalTags
-		imageutil.PrioritizeTags(tags)
+		// drop tags the requested selection filters out before the limit applies
+		if repository.Selection != nil {
+			tags = repository.Selection.FilterTags(tags)
+		}
+		// include only the top N tags in the result, put the rest in AdditionalTags;
+		// set.List is already alphabetical, so only the default order needs a sort
+		if repository.Selection == nil || repository.Selection.Sort != RepositoryImportSortAlphabetical {
+			imageutil.PrioritizeTags(tags)
+		}
 		for _, s := range tags {
 			if count <= 0 && repository.MaximumTags != -1 {
 				repository.AdditionalTags = append(repository.AdditionalTags, s)
@@ -792,6 +952,11 @@ func (imp *ImageStreamImporter) importRepositoryFromDocker(ctx context.Context,
 	}
 
 	for i := range repository.Tags {
+		if err := ctx.Err(); err != nil {
+			applyContextErrorToRepository(repository, err)
+			return
+		}
+
 		importTag := &repository.Tags[i]
 		if importTag.Err != nil || importTag.Image != nil {
 			continue
@@ -807,9 +972,16 @@ func (imp *ImageStreamImporter) importRepositoryFromDocker(ctx context.Context,
 			continue
 		}
 
-		imp.limiter.Accept()
+		if err := imp.limiter.Wait(ctx); err != nil {
+			applyContextErrorToRepository(repository, err)
+			return
+		}
+		if err := imp.registryLimiter.Accept(ctx, repository.Registry.Host); err != nil {
+			applyContextErrorToRepository(repository, err)
+			return
+		}
 
-		manifest, ms, bs, err := imp.getManifest(ctx, dockerRef, repository.Insecure)
+		manifest, ms, bs, source, err := imp.getManifest(ctx, dockerRef, repository.Insecure)
 		if err != nil {
 			klog.V(5).Infof("unable to get manifest by tag %q for image %s: %#v", importTag.Name, ref.Exact(), err)
 			importTag.Err = err
@@ -817,6 +989,12 @@ func (imp *ImageStreamImporter) importRepositoryFromDocker(ctx context.Context,
 		}
 
 		importTag.Image, importTag.Err = imp.importManifest(ctx, manifest, dockerRef, "", ms, bs, importTag.PreferArch, importTag.PreferOS)
+		if importTag.Err == nil {
+			recordManifestSource(importTag.Image, dockerRef, source)
+		}
+	}
+	if len(repository.Tags) > 0 {
+		trace.Step("Fetched manifests by tag", utiltrace.Field{Key: "tags", Value: len(repository.Tags)})
 	}
 }
 
@@ -844,6 +1022,7 @@ type importRepository struct {
 	Digests []importDigest
 
 	MaximumTags    int
+	Selection      *RepositoryTagSelection
 	AdditionalTags []string
 	Err            error
 }
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/containers/image/pkg/sysregistriesv2"
@@ -185,8 +186,8 @@ func TestImportNothing(t *testing.T) {
 		http.DefaultTransport, http.DefaultTransport, nil,
 	)
 	isi := &imageapi.ImageStreamImport{}
-	i := NewImageStreamImporter(ctx, nil, 5, nil, nil)
-	if err := i.Import(nil, isi, nil); err != nil {
+	i := NewImageStreamImporter(ctx, nil, 5, nil, nil, nil, nil)
+	if err := i.Import(context.Background(), isi, nil); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -426,8 +427,8 @@ func TestImportManifestList(t *testing.T) {
 		},
 	}
 
-	im := NewImageStreamImporter(retriever, nil, 5, nil, nil)
-	if err := im.Import(nil, &isi, &imageapi.ImageStream{}); err != nil {
+	im := NewImageStreamImporter(retriever, nil, 5, nil, nil, nil, nil)
+	if err := im.Import(context.Background(), &isi, &imageapi.ImageStream{}); err != nil {
 		t.Errorf("importing manifest list returned: %v", err)
 	}
 
@@ -667,8 +668,8 @@ func TestImport(t *testing.T) {
 		},
 	}
 	for i, test := range testCases {
-		im := NewImageStreamImporter(test.retriever, nil, 5, nil, nil)
-		if err := im.Import(nil, &test.isi, &imageapi.ImageStream{}); err != nil {
+		im := NewImageStreamImporter(test.retriever, nil, 5, nil, nil, nil, nil)
+		if err := im.Import(context.Background(), &test.isi, &imageapi.ImageStream{}); err != nil {
 			t.Errorf("%d: %v", i, err)
 		}
 		if test.expect != nil {
@@ -677,6 +678,42 @@ func TestImport(t *testing.T) {
 	}
 }
 
+func TestImportCancelled(t *testing.T) {
+	calls := 0
+	retriever := mockRetrieverFunc(func(registry *url.URL, repoName string, insecure bool) (distribution.Repository, error) {
+		calls++
+		return nil, fmt.Errorf("the registry should not be contacted for a cancelled request")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	isi := &imageapi.ImageStreamImport{
+		Spec: imageapi.ImageStreamImportSpec{
+			Images: []imageapi.ImageImportSpec{
+				{From: kapi.ObjectReference{Kind: "DockerImage", Name: "test:tag"}},
+			},
+			Repository: &imageapi.RepositoryImportSpec{
+				From: kapi.ObjectReference{Kind: "DockerImage", Name: "test"},
+			},
+		},
+	}
+	im := NewImageStreamImporter(retriever, nil, 5, nil, nil, nil, nil)
+	if err := im.Import(ctx, isi, &imageapi.ImageStream{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 0 {
+		t.Errorf("expected no registry requests after cancellation, got %d", calls)
+	}
+	if status := isi.Status.Images[0].Status; status.Status != metav1.StatusFailure || !strings.Contains(status.Message, "context canceled") {
+		t.Errorf("unexpected status: %#v", status)
+	}
+	if status := isi.Status.Repository.Status; status.Status != metav1.StatusFailure || !strings.Contains(status.Message, "context canceled") {
+		t.Errorf("unexpected repository status: %#v", status)
+	}
+}
+
 func TestImportFromMirror(t *testing.T) {
 	busyboxManifestSchema2 := &schema2.DeserializedManifest{}
 	if err := busyboxManifestSchema2.UnmarshalJSON([]byte(busyboxManifest)); err != nil {
@@ -734,8 +771,8 @@ func TestImportFromMirror(t *testing.T) {
 			},
 		}
 
-		im := NewImageStreamImporter(testRetriever, regConf, 5, nil, nil)
-		if err := im.Import(nil, &isi, &imageapi.ImageStream{}); err != nil {
+		im := NewImageStreamImporter(testRetriever, regConf, 5, nil, nil, nil, nil)
+		if err := im.Import(context.Background(), &isi, &imageapi.ImageStream{}); err != nil {
 			t.Fatalf("%v", err)
 		}
 		if len(isi.Status.Images) != 1 {
@@ -754,6 +791,9 @@ func TestImportFromMirror(t *testing.T) {
 		if image.Image.DockerImageReference != "quay.io/openshift/test@"+busyboxDigest {
 			t.Errorf("unexpected ref: %#v", image.Image.DockerImageReference)
 		}
+		if source := image.Image.Annotations[ManifestSourceAnnotation]; source != "mirror.example.com/openshift4/test" {
+			t.Errorf("expected the serving mirror to be recorded, got %q", source)
+		}
 	})
 
 	t.Run("by digest from another repo", func(t *testing.T) {
@@ -783,8 +823,8 @@ func TestImportFromMirror(t *testing.T) {
 			},
 		}
 
-		im := NewImageStreamImporter(testRetriever, regConf, 5, nil, nil)
-		if err := im.Import(nil, &isi, &imageapi.ImageStream{}); err != nil {
+		im := NewImageStreamImporter(testRetriever, regConf, 5, nil, nil, nil, nil)
+		if err := im.Import(context.Background(), &isi, &imageapi.ImageStream{}); err != nil {
 			t.Fatalf("%v", err)
 		}
 		if len(isi.Status.Images) != 1 {
@@ -803,6 +843,9 @@ func TestImportFromMirror(t *testing.T) {
 		if image.Image.DockerImageReference != "docker.io/openshift/test@"+busyboxDigest {
 			t.Errorf("unexpected ref: %#v", image.Image.DockerImageReference)
 		}
+		if source, ok := image.Image.Annotations[ManifestSourceAnnotation]; ok {
+			t.Errorf("expected no manifest source annotation for an unmirrored import, got %q", source)
+		}
 	})
 }
 
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/docker/distribution/registry/api/errcode"
+	v2 "github.com/docker/distribution/registry/api/v2"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	importDurationSeconds = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Name:    "openshift_apiserver_image_import_duration_seconds",
+			Help:    "Time taken to import one remote repository, labeled by registry host and result.",
+			Buckets: []float64{0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		},
+		[]string{"registry", "result"},
+	)
+	importFailuresTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name: "openshift_apiserver_image_import_failures_total",
+			Help: "Number of failed image imports, labeled by registry host and failure reason.",
+		},
+		[]string{"registry", "reason"},
+	)
+	importsTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name: "openshift_apiserver_image_imports_total",
+			Help: "Number of requested image imports, labeled by import mode (scheduled or on-demand).",
+		},
+		[]string{"mode"},
+	)
+)
+
+func init() {
+	legacyregistry.MustRegister(importDurationSeconds)
+	legacyregistry.MustRegister(importFailuresTotal)
+	legacyregistry.MustRegister(importsTotal)
+}
+
+// countImportRequest counts one requested image or repository import.
+func countImportRequest(scheduled bool) {
+	mode := "on-demand"
+	if scheduled {
+		mode = "scheduled"
+	}
+	importsTotal.WithLabelValues(mode).Inc()
+}
+
+// recordBlockedImport counts an import that was denied because the registry
+// hosting the image is blocked by cluster policy.
+func recordBlockedImport(registry string) {
+	importFailuresTotal.WithLabelValues(registry, "whitelist-denied").Inc()
+}
+
+// recordRepositoryImport observes the duration of one repository import and
+// counts the failures recorded on the repository, its tags and its digests.
+func recordRepositoryImport(repository *importRepository, duration time.Duration) {
+	registry := repository.Registry.Host
+	result := "success"
+	for _, err := range repositoryImportErrors(repository) {
+		result = "error"
+		importFailuresTotal.WithLabelValues(registry, importFailureReason(err)).Inc()
+	}
+	importDurationSeconds.WithLabelValues(registry, result).Observe(duration.Seconds())
+}
+
+// repositoryImportErrors collects the errors recorded on repository after an
+// import attempt.
+func repositoryImportErrors(repository *importRepository) []error {
+	var errs []error
+	if repository.Err != nil {
+		errs = append(errs, repository.Err)
+	}
+	for i := range repository.Tags {
+		if err := repository.Tags[i].Err; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i := range repository.Digests {
+		if err := repository.Digests[i].Err; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// importFailureReason buckets an import error into a small set of reasons
+// suitable as a metric label.
+func importFailureReason(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case isTimeoutError(err):
+		return "timeout"
+	case kapierrors.IsUnauthorized(err), isDockerError(err, errcode.ErrorCodeUnauthorized), isDockerError(err, errcode.ErrorCodeDenied):
+		return "auth"
+	case kapierrors.IsNotFound(err), isDockerError(err, v2.ErrorCodeNameUnknown), isDockerError(err, v2.ErrorCodeManifestUnknown):
+		return "not-found"
+	case kapierrors.IsForbidden(err):
+		return "forbidden"
+	default:
+		return "other"
+	}
+}
+
+func isTimeoutError(err error) bool {
+	if kapierrors.IsTimeout(err) || kapierrors.IsServerTimeout(err) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/docker/distribution/registry/api/errcode"
+	v2 "github.com/docker/distribution/registry/api/v2"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/openshift/api/image"
+)
+
+func TestImportFailureReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "context deadline",
+			err:      fmt.Errorf("import wrapped: %w", context.DeadlineExceeded),
+			expected: "timeout",
+		},
+		{
+			name:     "api timeout",
+			err:      kapierrors.NewTimeoutError("too slow", 1),
+			expected: "timeout",
+		},
+		{
+			name:     "unauthorized status",
+			err:      kapierrors.NewUnauthorized("no access"),
+			expected: "auth",
+		},
+		{
+			name:     "docker unauthorized",
+			err:      errcode.ErrorCodeUnauthorized.WithDetail("nope"),
+			expected: "auth",
+		},
+		{
+			name:     "not found status",
+			err:      kapierrors.NewNotFound(image.Resource("dockerimage"), "test"),
+			expected: "not-found",
+		},
+		{
+			name:     "docker manifest unknown",
+			err:      v2.ErrorCodeManifestUnknown.WithDetail("gone"),
+			expected: "not-found",
+		},
+		{
+			name:     "forbidden status",
+			err:      kapierrors.NewForbidden(image.Resource("dockerimage"), "test", fmt.Errorf("blocked")),
+			expected: "forbidden",
+		},
+		{
+			name:     "anything else",
+			err:      fmt.Errorf("connection reset"),
+			expected: "other",
+		},
+	}
+
+	for _, test := range tests {
+		if reason := importFailureReason(test.err); reason != test.expected {
+			t.Errorf("%s: expected reason %q, got %q", test.name, test.expected, reason)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package ocilayout
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/internalimageutil"
+)
+
+// maxMetadataBlobSize bounds the size of manifest and config blobs read into
+// memory from an uploaded layout.  Layer blobs are never buffered; only their
+// digests and sizes are consulted.
+const maxMetadataBlobSize = 4 * 1024 * 1024
+
+// TaggedImage is an image parsed from an OCI image layout together with the
+// tag it was referenced by in the layout index.
+type TaggedImage struct {
+	Tag   string
+	Image *imageapi.Image
+}
+
+// Parse reads an OCI image layout (https://github.com/opencontainers/image-spec/blob/master/image-layout.md)
+// from a tar stream and returns an Image object per index entry, tagged by the
+// org.opencontainers.image.ref.name annotation.  repository is the pull spec
+// of the repository the images will be served from; it is used to construct
+// each image's dockerImageReference.  Nested image indices (manifest lists)
+// are rejected.
+func Parse(r io.Reader, repository string) ([]TaggedImage, error) {
+	blobs, index, err := readLayout(r)
+	if err != nil {
+		return nil, err
+	}
+	if index == nil {
+		return nil, fmt.Errorf("uploaded layout does not contain an index.json")
+	}
+
+	var images []TaggedImage
+	for i, desc := range index.Manifests {
+		tag := desc.Annotations[imgspecv1.AnnotationRefName]
+		if len(tag) == 0 {
+			if len(index.Manifests) > 1 {
+				return nil, fmt.Errorf("index entry %d has no %s annotation; all entries must be tagged", i, imgspecv1.AnnotationRefName)
+			}
+			tag = "latest"
+		}
+		// the reference may be a full tag reference, e.g. registry/repo:v1
+		if i := strings.LastIndex(tag, ":"); i != -1 {
+			tag = tag[i+1:]
+		}
+
+		image, err := imageForDescriptor(blobs, desc, repository)
+		if err != nil {
+			return nil, fmt.Errorf("index entry %q: %v", tag, err)
+		}
+		images = append(images, TaggedImage{Tag: tag, Image: image})
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("uploaded layout index does not reference any manifests")
+	}
+	return images, nil
+}
+
+// readLayout consumes the tar stream, returning the metadata blobs keyed by
+// digest and the parsed index.json.
+func readLayout(r io.Reader) (map[digest.Digest][]byte, *imgspecv1.Index, error) {
+	blobs := map[digest.Digest][]byte{}
+	var index *imgspecv1.Index
+	sawLayoutFile := false
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid tar stream: %v", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		switch {
+		case name == imgspecv1.ImageLayoutFile:
+			data, err := ioutil.ReadAll(io.LimitReader(tr, maxMetadataBlobSize))
+			if err != nil {
+				return nil, nil, err
+			}
+			layout := imgspecv1.ImageLayout{}
+			if err := json.Unmarshal(data, &layout); err != nil {
+				return nil, nil, fmt.Errorf("invalid %s file: %v", imgspecv1.ImageLayoutFile, err)
+			}
+			if layout.Version != imgspecv1.ImageLayoutVersion {
+				return nil, nil, fmt.Errorf("unsupported image layout version %q", layout.Version)
+			}
+			sawLayoutFile = true
+
+		case name == "index.json":
+			data, err := ioutil.ReadAll(io.LimitReader(tr, maxMetadataBlobSize))
+			if err != nil {
+				return nil, nil, err
+			}
+			index = &imgspecv1.Index{}
+			if err := json.Unmarshal(data, index); err != nil {
+				return nil, nil, fmt.Errorf("invalid index.json: %v", err)
+			}
+
+		case strings.HasPrefix(name, "blobs/"):
+			parts := strings.Split(name, "/")
+			if len(parts) != 3 {
+				continue
+			}
+			// layer blobs can be arbitrarily large; only manifests and configs
+			// need to be buffered, and both are small
+			if hdr.Size > maxMetadataBlobSize {
+				continue
+			}
+			data, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return nil, nil, err
+			}
+			blobs[digest.Digest(parts[1]+":"+parts[2])] = data
+		}
+	}
+
+	if !sawLayoutFile {
+		return nil, nil, fmt.Errorf("uploaded tar is not an OCI image layout: missing %s file", imgspecv1.ImageLayoutFile)
+	}
+	return blobs, index, nil
+}
+
+// imageForDescriptor builds an Image for a single manifest descriptor,
+// verifying that the blob content matches its digest.
+func imageForDescriptor(blobs map[digest.Digest][]byte, desc imgspecv1.Descriptor, repository string) (*imageapi.Image, error) {
+	switch desc.MediaType {
+	case imgspecv1.MediaTypeImageIndex, "application/vnd.docker.distribution.manifest.list.v2+json":
+		return nil, fmt.Errorf("nested image indices (manifest lists) are not supported")
+	case imgspecv1.MediaTypeImageManifest, schema2.MediaTypeManifest, "":
+	default:
+		return nil, fmt.Errorf("unsupported manifest media type %q", desc.MediaType)
+	}
+
+	manifestBytes, ok := blobs[desc.Digest]
+	if !ok {
+		return nil, fmt.Errorf("manifest blob %s is not present in the layout", desc.Digest)
+	}
+	if err := verifyBlob(desc.Digest, manifestBytes); err != nil {
+		return nil, err
+	}
+
+	manifest := imgspecv1.Manifest{}
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %v", desc.Digest, err)
+	}
+
+	configBytes, ok := blobs[manifest.Config.Digest]
+	if !ok {
+		return nil, fmt.Errorf("config blob %s is not present in the layout", manifest.Config.Digest)
+	}
+	if err := verifyBlob(manifest.Config.Digest, configBytes); err != nil {
+		return nil, err
+	}
+
+	mediaType := desc.MediaType
+	if len(mediaType) == 0 {
+		mediaType = imgspecv1.MediaTypeImageManifest
+	}
+
+	image := &imageapi.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: desc.Digest.String(),
+		},
+		DockerImageReference:         repository + "@" + desc.Digest.String(),
+		DockerImageManifest:          string(manifestBytes),
+		DockerImageManifestMediaType: mediaType,
+		DockerImageConfig:            string(configBytes),
+	}
+	if err := internalimageutil.InternalImageWithMetadata(image); err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+func verifyBlob(dgst digest.Digest, data []byte) error {
+	if err := dgst.Validate(); err != nil {
+		return fmt.Errorf("invalid digest %q: %v", dgst, err)
+	}
+	if computed := dgst.Algorithm().FromBytes(data); computed != dgst {
+		return fmt.Errorf("blob %s content does not match its digest", dgst)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package ocilayout
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	imgspec "github.com/opencontainers/image-spec/specs-go"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type layoutEntry struct {
+	name string
+	data []byte
+}
+
+func buildLayout(t *testing.T, entries []layoutEntry) *bytes.Reader {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, entry := range entries {
+		if err := tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(entry.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func blobPath(dgst digest.Digest) string {
+	return "blobs/" + dgst.Algorithm().String() + "/" + dgst.Hex()
+}
+
+func validLayoutEntries(t *testing.T, refName string) ([]layoutEntry, digest.Digest) {
+	config := []byte(`{"architecture":"amd64","os":"linux","config":{},"rootfs":{"type":"layers","diff_ids":["sha256:0000000000000000000000000000000000000000000000000000000000000000"]}}`)
+	configDigest := digest.FromBytes(config)
+
+	manifest, err := json.Marshal(imgspecv1.Manifest{
+		Versioned: imgspec.Versioned{SchemaVersion: 2},
+		Config: imgspecv1.Descriptor{
+			MediaType: imgspecv1.MediaTypeImageConfig,
+			Digest:    configDigest,
+			Size:      int64(len(config)),
+		},
+		Layers: []imgspecv1.Descriptor{
+			{
+				MediaType: imgspecv1.MediaTypeImageLayerGzip,
+				Digest:    "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+				Size:      1024,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDigest := digest.FromBytes(manifest)
+
+	manifestDesc := imgspecv1.Descriptor{
+		MediaType: imgspecv1.MediaTypeImageManifest,
+		Digest:    manifestDigest,
+		Size:      int64(len(manifest)),
+	}
+	if len(refName) > 0 {
+		manifestDesc.Annotations = map[string]string{imgspecv1.AnnotationRefName: refName}
+	}
+	index, err := json.Marshal(imgspecv1.Index{Manifests: []imgspecv1.Descriptor{manifestDesc}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []layoutEntry{
+		{name: imgspecv1.ImageLayoutFile, data: []byte(`{"imageLayoutVersion":"1.0.0"}`)},
+		{name: "index.json", data: index},
+		{name: blobPath(manifestDigest), data: manifest},
+		{name: blobPath(configDigest), data: config},
+	}, manifestDigest
+}
+
+func TestParse(t *testing.T) {
+	entries, manifestDigest := validLayoutEntries(t, "v1")
+
+	images, err := Parse(buildLayout(t, entries), "registry.example.com/test/stream")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(images) != 1 {
+		t.Fatalf("expected 1 image, got %d", len(images))
+	}
+	if images[0].Tag != "v1" {
+		t.Errorf("expected tag v1, got %q", images[0].Tag)
+	}
+	image := images[0].Image
+	if image.Name != manifestDigest.String() {
+		t.Errorf("expected image name %s, got %s", manifestDigest, image.Name)
+	}
+	if expected := "registry.example.com/test/stream@" + manifestDigest.String(); image.DockerImageReference != expected {
+		t.Errorf("expected reference %s, got %s", expected, image.DockerImageReference)
+	}
+	if image.DockerImageManifestMediaType != imgspecv1.MediaTypeImageManifest {
+		t.Errorf("unexpected media type %q", image.DockerImageManifestMediaType)
+	}
+	if image.DockerImageMetadata.Architecture != "amd64" {
+		t.Errorf("expected image metadata to be filled, got %#v", image.DockerImageMetadata)
+	}
+	if len(image.DockerImageLayers) != 1 {
+		t.Errorf("expected 1 layer, got %d", len(image.DockerImageLayers))
+	}
+}
+
+func TestParseDefaultsTag(t *testing.T) {
+	entries, _ := validLayoutEntries(t, "")
+	images, err := Parse(buildLayout(t, entries), "registry.example.com/test/stream")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(images) != 1 || images[0].Tag != "latest" {
+		t.Errorf("expected a single image tagged latest, got %#v", images)
+	}
+}
+
+func TestParseStripsRepositoryFromRefName(t *testing.T) {
+	entries, _ := validLayoutEntries(t, "registry.example.com/test/stream:v2")
+	images, err := Parse(buildLayout(t, entries), "registry.example.com/test/stream")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(images) != 1 || images[0].Tag != "v2" {
+		t.Errorf("expected a single image tagged v2, got %#v", images)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	valid, _ := validLayoutEntries(t, "v1")
+
+	tests := []struct {
+		name          string
+		entries       []layoutEntry
+		expectedError string
+	}{
+		{
+			name:          "empty tar",
+			entries:       nil,
+			expectedError: "missing oci-layout file",
+		},
+		{
+			name:          "missing index",
+			entries:       valid[:1],
+			expectedError: "does not contain an index.json",
+		},
+		{
+			name: "unsupported layout version",
+			entries: append([]layoutEntry{
+				{name: imgspecv1.ImageLayoutFile, data: []byte(`{"imageLayoutVersion":"2.0.0"}`)},
+			}, valid[1:]...),
+			expectedError: "unsupported image layout version",
+		},
+		{
+			name:          "missing manifest blob",
+			entries:       []layoutEntry{valid[0], valid[1], valid[3]},
+			expectedError: "is not present in the layout",
+		},
+		{
+			name: "corrupted manifest blob",
+			entries: []layoutEntry{
+				valid[0], valid[1],
+				{name: valid[2].name, data: append([]byte(nil), append(valid[2].data, ' ')...)},
+				valid[3],
+			},
+			expectedError: "does not match its digest",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(buildLayout(t, test.entries), "registry.example.com/test/stream")
+		if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.expectedError, err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+// imagePlatform returns the os and architecture the imported image's config
+// declares. The config blob is authoritative; schema1 manifests only record
+// an architecture in the image metadata and no os.
+func imagePlatform(image *imageapi.Image) (string, string) {
+	os := ""
+	arch := image.DockerImageMetadata.Architecture
+	if len(image.DockerImageConfig) > 0 {
+		var config struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+		}
+		if err := json.Unmarshal([]byte(image.DockerImageConfig), &config); err == nil {
+			if len(config.OS) > 0 {
+				os = config.OS
+			}
+			if len(config.Architecture) > 0 {
+				arch = config.Architecture
+			}
+		}
+	}
+	return os, arch
+}
+
+// platformAllowed reports whether the image's platform matches one of the
+// os/arch allowlist entries of the import policy. Images that declare no
+// platform at all are allowed: the filter exists to reject known mismatches,
+// not to break imports of images without config metadata. A missing os or
+// architecture matches any entry for the unknown half.
+func platformAllowed(image *imageapi.Image, platforms []string) bool {
+	if len(platforms) == 0 {
+		return true
+	}
+	os, arch := imagePlatform(image)
+	if len(os) == 0 && len(arch) == 0 {
+		return true
+	}
+	for _, platform := range platforms {
+		parts := strings.SplitN(platform, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if (len(os) == 0 || parts[0] == os) && (len(arch) == 0 || parts[1] == arch) {
+			return true
+		}
+	}
+	return false
+}
+
+// platformMismatchError describes an image rejected by the platform allowlist.
+func platformMismatchError(image *imageapi.Image, platforms []string) error {
+	os, arch := imagePlatform(image)
+	return fmt.Errorf("image platform %s/%s does not match the importPolicy platforms %s", os, arch, strings.Join(platforms, ", "))
+}
@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"testing"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+func TestPlatformAllowed(t *testing.T) {
+	tests := map[string]struct {
+		image     imageapi.Image
+		platforms []string
+		allowed   bool
+	}{
+		"empty allowlist admits everything": {
+			image:   imageapi.Image{DockerImageConfig: `{"os":"linux","architecture":"s390x"}`},
+			allowed: true,
+		},
+		"matching os and arch": {
+			image:     imageapi.Image{DockerImageConfig: `{"os":"linux","architecture":"amd64"}`},
+			platforms: []string{"linux/amd64"},
+			allowed:   true,
+		},
+		"matching one of several entries": {
+			image:     imageapi.Image{DockerImageConfig: `{"os":"linux","architecture":"arm64"}`},
+			platforms: []string{"linux/amd64", "linux/arm64"},
+			allowed:   true,
+		},
+		"architecture mismatch": {
+			image:     imageapi.Image{DockerImageConfig: `{"os":"linux","architecture":"s390x"}`},
+			platforms: []string{"linux/amd64"},
+			allowed:   false,
+		},
+		"os mismatch": {
+			image:     imageapi.Image{DockerImageConfig: `{"os":"windows","architecture":"amd64"}`},
+			platforms: []string{"linux/amd64"},
+			allowed:   false,
+		},
+		"no declared platform is admitted": {
+			image:     imageapi.Image{},
+			platforms: []string{"linux/amd64"},
+			allowed:   true,
+		},
+		"schema1 architecture only matches on arch": {
+			image: imageapi.Image{
+				DockerImageMetadata: imageapi.DockerImage{Architecture: "amd64"},
+			},
+			platforms: []string{"linux/amd64"},
+			allowed:   true,
+		},
+		"schema1 architecture only mismatch": {
+			image: imageapi.Image{
+				DockerImageMetadata: imageapi.DockerImage{Architecture: "arm64"},
+			},
+			platforms: []string{"linux/amd64"},
+			allowed:   false,
+		},
+		"config overrides metadata architecture": {
+			image: imageapi.Image{
+				DockerImageMetadata: imageapi.DockerImage{Architecture: "amd64"},
+				DockerImageConfig:   `{"os":"linux","architecture":"arm64"}`,
+			},
+			platforms: []string{"linux/amd64"},
+			allowed:   false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if allowed := platformAllowed(&test.image, test.platforms); allowed != test.allowed {
+				t.Errorf("expected allowed=%t, got %t", test.allowed, allowed)
+			}
+		})
+	}
+}
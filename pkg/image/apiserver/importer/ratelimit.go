@@ -0,0 +1,143 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// RegistryLimit describes the import limits applied to a single registry
+// host.  A zero QPS or MaxConcurrent disables the respective limit.
+type RegistryLimit struct {
+	// QPS is the sustained rate of manifest requests allowed against the
+	// registry, with Burst extra requests allowed in short spikes.
+	QPS   float32
+	Burst int
+	// MaxConcurrent caps the number of repository imports running against
+	// the registry at the same time.
+	MaxConcurrent int
+}
+
+// ImportRateLimiter dispenses per-registry-host rate limiters and concurrency
+// slots for image imports.  A single instance is shared by all importers so
+// limits apply across concurrent import requests.  A nil limiter allows
+// everything.
+type ImportRateLimiter struct {
+	limits map[string]RegistryLimit
+
+	lock       sync.Mutex
+	limiters   map[string]flowcontrol.RateLimiter
+	semaphores map[string]chan struct{}
+}
+
+// NewImportRateLimiter returns a limiter enforcing the given limits, keyed by
+// registry host (hostname or hostname:port).
+func NewImportRateLimiter(limits map[string]RegistryLimit) *ImportRateLimiter {
+	if len(limits) == 0 {
+		return nil
+	}
+	return &ImportRateLimiter{
+		limits:     limits,
+		limiters:   make(map[string]flowcontrol.RateLimiter),
+		semaphores: make(map[string]chan struct{}),
+	}
+}
+
+// Begin claims a concurrency slot for a repository import against the given
+// registry host, blocking while the registry is at its cap.  It returns the
+// context error when the request is cancelled before a slot opens up, so
+// abandoned imports do not keep waiting for a busy registry.  The returned
+// function releases the slot and must always be called.
+func (l *ImportRateLimiter) Begin(ctx context.Context, host string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	limit, ok := l.limits[host]
+	if !ok || limit.MaxConcurrent <= 0 {
+		return func() {}, nil
+	}
+
+	l.lock.Lock()
+	semaphore, ok := l.semaphores[host]
+	if !ok {
+		semaphore = make(chan struct{}, limit.MaxConcurrent)
+		l.semaphores[host] = semaphore
+	}
+	l.lock.Unlock()
+
+	select {
+	case semaphore <- struct{}{}:
+		return func() { <-semaphore }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Accept blocks until a manifest request against the given registry host is
+// within the configured QPS limit or the request is cancelled, in which case
+// the context error is returned.
+func (l *ImportRateLimiter) Accept(ctx context.Context, host string) error {
+	if l == nil {
+		return nil
+	}
+	limit, ok := l.limits[host]
+	if !ok || limit.QPS <= 0 {
+		return nil
+	}
+
+	l.lock.Lock()
+	limiter, ok := l.limiters[host]
+	if !ok {
+		burst := limit.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		limiter = flowcontrol.NewTokenBucketRateLimiter(limit.QPS, burst)
+		l.limiters[host] = limiter
+	}
+	l.lock.Unlock()
+
+	return limiter.Wait(ctx)
+}
+
+// ParseRegistryLimits parses limit declarations of the form
+// <host>=<qps>,<burst>,<maxConcurrent>, one registry per entry, as provided
+// through the image-import-registry-rate-limit apiserver argument.
+func ParseRegistryLimits(values []string) (map[string]RegistryLimit, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	limits := make(map[string]RegistryLimit)
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, fmt.Errorf("invalid registry rate limit %q: expected <host>=<qps>,<burst>,<maxConcurrent>", value)
+		}
+		host := parts[0]
+		fields := strings.Split(parts[1], ",")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid registry rate limit %q: expected <host>=<qps>,<burst>,<maxConcurrent>", value)
+		}
+		qps, err := strconv.ParseFloat(fields[0], 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid qps in registry rate limit %q: %v", value, err)
+		}
+		burst, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid burst in registry rate limit %q: %v", value, err)
+		}
+		maxConcurrent, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxConcurrent in registry rate limit %q: %v", value, err)
+		}
+		if qps < 0 || burst < 0 || maxConcurrent < 0 {
+			return nil, fmt.Errorf("invalid registry rate limit %q: limits may not be negative", value)
+		}
+		limits[host] = RegistryLimit{QPS: float32(qps), Burst: burst, MaxConcurrent: maxConcurrent}
+	}
+	return limits, nil
+}
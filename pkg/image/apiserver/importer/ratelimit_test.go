@@ -0,0 +1,145 @@
+package importer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseRegistryLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      []string
+		expected    map[string]RegistryLimit
+		expectError bool
+	}{
+		{
+			name: "no configuration",
+		},
+		{
+			name:   "single registry",
+			values: []string{"docker.io=2,3,4"},
+			expected: map[string]RegistryLimit{
+				"docker.io": {QPS: 2, Burst: 3, MaxConcurrent: 4},
+			},
+		},
+		{
+			name:   "registry with port and fractional qps",
+			values: []string{"registry.example.com:5000=0.5,1,2", "quay.io=10,20,0"},
+			expected: map[string]RegistryLimit{
+				"registry.example.com:5000": {QPS: 0.5, Burst: 1, MaxConcurrent: 2},
+				"quay.io":                   {QPS: 10, Burst: 20, MaxConcurrent: 0},
+			},
+		},
+		{
+			name:        "missing host",
+			values:      []string{"=1,2,3"},
+			expectError: true,
+		},
+		{
+			name:        "missing fields",
+			values:      []string{"docker.io=1,2"},
+			expectError: true,
+		},
+		{
+			name:        "negative limit",
+			values:      []string{"docker.io=-1,2,3"},
+			expectError: true,
+		},
+		{
+			name:        "non numeric qps",
+			values:      []string{"docker.io=fast,2,3"},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		limits, err := ParseRegistryLimits(test.values)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %v", test.name, limits)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(limits, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, limits)
+		}
+	}
+}
+
+func TestImportRateLimiterConcurrency(t *testing.T) {
+	limiter := NewImportRateLimiter(map[string]RegistryLimit{
+		"docker.io": {MaxConcurrent: 1},
+	})
+
+	ctx := context.Background()
+
+	// unknown hosts are not limited
+	unlimited, err := limiter.Begin(ctx, "quay.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlimited()
+
+	first, err := limiter.Begin(ctx, "docker.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		second, err := limiter.Begin(ctx, "docker.io")
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second import was admitted while the first still held the only slot")
+	default:
+	}
+
+	first()
+	second := <-acquired
+	second()
+}
+
+func TestImportRateLimiterCancelledBegin(t *testing.T) {
+	limiter := NewImportRateLimiter(map[string]RegistryLimit{
+		"docker.io": {MaxConcurrent: 1},
+	})
+
+	first, err := limiter.Begin(context.Background(), "docker.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := limiter.Begin(ctx, "docker.io"); err != context.Canceled {
+		t.Errorf("expected a cancelled wait to return context.Canceled, got %v", err)
+	}
+}
+
+func TestImportRateLimiterNil(t *testing.T) {
+	var limiter *ImportRateLimiter
+	if err := limiter.Accept(context.Background(), "docker.io"); err != nil {
+		t.Fatal(err)
+	}
+	release, err := limiter.Begin(context.Background(), "docker.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	if NewImportRateLimiter(nil) != nil {
+		t.Error("expected no limiter when no limits are configured")
+	}
+}
@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+// Scanner supplies vulnerability or attestation metadata for imported images.
+// It is invoked after the manifest of an image has been loaded, so scanning
+// integrations receive the image as part of the import instead of racing a
+// watch on created images.
+type Scanner interface {
+	// ScanImage returns metadata describing the image, keyed by annotation
+	// name, to be attached to the image before it is persisted.  Returning
+	// an error leaves the image without metadata but does not fail the
+	// import.
+	ScanImage(ctx context.Context, image *imageapi.Image) (map[string]string, error)
+}
+
+// NewNoopScanner returns the default Scanner, which attaches no metadata.
+func NewNoopScanner() Scanner {
+	return noopScanner{}
+}
+
+type noopScanner struct{}
+
+func (noopScanner) ScanImage(ctx context.Context, image *imageapi.Image) (map[string]string, error) {
+	return nil, nil
+}
+
+// webhookScannerTimeout bounds a single webhook invocation so a slow scanning
+// service cannot stall imports.
+const webhookScannerTimeout = 10 * time.Second
+
+// scanRequest is the payload posted to a scanner webhook.
+type scanRequest struct {
+	// Name is the image name, which for imported images is the manifest
+	// digest.
+	Name string `json:"name"`
+	// DockerImageReference is the pull spec the image was imported from.
+	DockerImageReference string `json:"dockerImageReference"`
+}
+
+// scanResponse is the payload a scanner webhook answers with.
+type scanResponse struct {
+	// Annotations are attached to the imported image as-is.
+	Annotations map[string]string `json:"annotations"`
+}
+
+// webhookScanner posts every imported image to an external scanning service
+// and attaches the annotations the service returns.
+type webhookScanner struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookScanner returns a Scanner backed by the scanning service at the
+// given URL.  A nil client selects a default client with a request timeout.
+func NewWebhookScanner(url string, client *http.Client) Scanner {
+	if client == nil {
+		client = &http.Client{Timeout: webhookScannerTimeout}
+	}
+	return &webhookScanner{url: url, client: client}
+}
+
+func (s *webhookScanner) ScanImage(ctx context.Context, image *imageapi.Image) (map[string]string, error) {
+	body, err := json.Marshal(&scanRequest{
+		Name:                 image.Name,
+		DockerImageReference: image.DockerImageReference,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("scanner webhook returned status %d", resp.StatusCode)
+	}
+
+	decoded := &scanResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(decoded); err != nil {
+		return nil, fmt.Errorf("invalid scanner webhook response: %v", err)
+	}
+	return decoded.Annotations, nil
+}
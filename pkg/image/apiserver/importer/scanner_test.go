@@ -0,0 +1,126 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/distribution/manifest/schema1"
+	godigest "github.com/opencontainers/go-digest"
+
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+type scannerFunc func(ctx context.Context, image *imageapi.Image) (map[string]string, error)
+
+func (fn scannerFunc) ScanImage(ctx context.Context, image *imageapi.Image) (map[string]string, error) {
+	return fn(ctx, image)
+}
+
+func TestWebhookScanner(t *testing.T) {
+	var received scanRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("invalid scan request: %v", err)
+		}
+		json.NewEncoder(w).Encode(&scanResponse{Annotations: map[string]string{
+			"vulnerabilities.example.com/summary": "0 critical",
+		}})
+	}))
+	defer server.Close()
+
+	image := &imageapi.Image{}
+	image.Name = "sha256:958608f8ecc1dc62c93b6c610f3a834dae4220c9642e6e8b4e0f2b3ad7cbd238"
+	image.DockerImageReference = "test@" + image.Name
+
+	annotations, err := NewWebhookScanner(server.URL, nil).ScanImage(context.Background(), image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received.Name != image.Name || received.DockerImageReference != image.DockerImageReference {
+		t.Errorf("unexpected scan request: %#v", received)
+	}
+	if annotations["vulnerabilities.example.com/summary"] != "0 critical" {
+		t.Errorf("unexpected annotations: %#v", annotations)
+	}
+}
+
+func TestWebhookScannerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if _, err := NewWebhookScanner(server.URL, nil).ScanImage(context.Background(), &imageapi.Image{}); err == nil {
+		t.Error("expected an error for a failing webhook")
+	}
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "not json")
+	})
+	if _, err := NewWebhookScanner(server.URL, nil).ScanImage(context.Background(), &imageapi.Image{}); err == nil {
+		t.Error("expected an error for a malformed response")
+	}
+}
+
+func TestImportAttachesScanMetadata(t *testing.T) {
+	etcdManifestSchema1 := &schema1.SignedManifest{}
+	if err := json.Unmarshal([]byte(etcdManifest), etcdManifestSchema1); err != nil {
+		t.Fatal(err)
+	}
+	retriever := &mockRetriever{repo: &mockRepository{manifest: etcdManifestSchema1}}
+
+	isi := func() *imageapi.ImageStreamImport {
+		return &imageapi.ImageStreamImport{
+			Spec: imageapi.ImageStreamImportSpec{
+				Images: []imageapi.ImageImportSpec{
+					{From: kapi.ObjectReference{Kind: "DockerImage", Name: "test@sha256:958608f8ecc1dc62c93b6c610f3a834dae4220c9642e6e8b4e0f2b3ad7cbd238"}},
+				},
+			},
+		}
+	}
+
+	scanned := 0
+	scanner := scannerFunc(func(ctx context.Context, image *imageapi.Image) (map[string]string, error) {
+		scanned++
+		if _, err := godigest.Parse(image.Name); err != nil {
+			t.Errorf("scanner invoked with a non-digest image name %q: %v", image.Name, err)
+		}
+		return map[string]string{"vulnerabilities.example.com/summary": "0 critical"}, nil
+	})
+
+	im := NewImageStreamImporter(retriever, nil, 5, nil, nil, nil, scanner)
+	status := isi()
+	if err := im.Import(context.Background(), status, &imageapi.ImageStream{}); err != nil {
+		t.Fatal(err)
+	}
+	if scanned != 1 {
+		t.Errorf("expected one scan, got %d", scanned)
+	}
+	image := status.Status.Images[0].Image
+	if image == nil || image.Annotations["vulnerabilities.example.com/summary"] != "0 critical" {
+		t.Errorf("scan metadata not attached: %#v", image)
+	}
+
+	// a failing scanner must not fail the import
+	im = NewImageStreamImporter(retriever, nil, 5, nil, nil, nil, scannerFunc(func(ctx context.Context, image *imageapi.Image) (map[string]string, error) {
+		return nil, fmt.Errorf("scanner unavailable")
+	}))
+	status = isi()
+	if err := im.Import(context.Background(), status, &imageapi.ImageStream{}); err != nil {
+		t.Fatal(err)
+	}
+	if status.Status.Images[0].Status.Status != "Success" {
+		t.Errorf("import failed because of the scanner: %#v", status.Status.Images[0].Status)
+	}
+	if image := status.Status.Images[0].Image; image == nil {
+		t.Error("image not imported")
+	} else if _, ok := image.Annotations["vulnerabilities.example.com/summary"]; ok {
+		t.Errorf("unexpected scan metadata: %#v", image.Annotations)
+	}
+}
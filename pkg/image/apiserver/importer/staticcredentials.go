@@ -28,11 +28,24 @@ func NewStaticCredentialsContext(
 	transport http.RoundTripper,
 	insecureTransport http.RoundTripper,
 	secrets []corev1.Secret,
+) *StaticCredentialsContext {
+	return NewStaticCredentialsContextWithProviders(transport, insecureTransport, secrets, nil)
+}
+
+// NewStaticCredentialsContextWithProviders is NewStaticCredentialsContext
+// with credential provider plugins consulted when neither kubernetes secrets
+// nor node pull credentials cover a registry.
+func NewStaticCredentialsContextWithProviders(
+	transport http.RoundTripper,
+	insecureTransport http.RoundTripper,
+	secrets []corev1.Secret,
+	providers *CredentialProviders,
 ) *StaticCredentialsContext {
 	return &StaticCredentialsContext{
 		transport:         transport,
 		insecureTransport: insecureTransport,
 		secrets:           secrets,
+		providers:         providers,
 	}
 }
 
@@ -40,6 +53,7 @@ type StaticCredentialsContext struct {
 	transport         http.RoundTripper
 	insecureTransport http.RoundTripper
 	secrets           []corev1.Secret
+	providers         *CredentialProviders
 	contexts          sync.Map
 }
 
@@ -48,6 +62,8 @@ type StaticCredentialsContext struct {
 // Kubernetes Secrets and node pull credentials are merged, the first has
 // higher priority. In case of failure reading node pull credentials only
 // kubernetes secrets are taken into account and a log entry is created.
+// When neither names the registry, configured credential provider plugins
+// are consulted before falling back to anonymous access.
 func (s *StaticCredentialsContext) Repository(
 	ctx context.Context,
 	ref reference.DockerImageReference,
@@ -82,6 +98,11 @@ func (s *StaticCredentialsContext) Repository(
 			Username: auths[0].Username,
 			Password: auths[0].Password,
 		})
+	} else if username, password, found := s.providers.Credentials(ctx, defRef.Registry, repo); found {
+		cred = dockerregistry.NewStaticCredentialStore(&types.AuthConfig{
+			Username: username,
+			Password: password,
+		})
 	}
 
 	importCtx := registryclient.NewContext(
@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Annotations on an ImageStreamImport that narrow a repository import down
+// from the default "first N prioritized tags".  Large upstream repositories
+// can carry hundreds of tags; these let a caller import only the ones it
+// cares about.  The annotations are read from the request object and are
+// never persisted.
+const (
+	// RepositoryImportIncludeAnnotation is a comma-separated list of glob
+	// patterns; when present, only tags matching at least one pattern are
+	// considered for import.
+	RepositoryImportIncludeAnnotation = "image.openshift.io/repository-import-include"
+
+	// RepositoryImportExcludeAnnotation is a comma-separated list of glob
+	// patterns; tags matching any pattern are never imported.
+	RepositoryImportExcludeAnnotation = "image.openshift.io/repository-import-exclude"
+
+	// RepositoryImportSortAnnotation selects the order in which the filtered
+	// tags are considered before the limit is applied: "prioritized" (the
+	// default, semver-aware with latest first) or "alphabetical".
+	RepositoryImportSortAnnotation = "image.openshift.io/repository-import-sort"
+
+	// RepositoryImportLimitAnnotation is the number of tags to import after
+	// filtering and sorting.  It can only lower the server-wide maximum, never
+	// raise it.
+	RepositoryImportLimitAnnotation = "image.openshift.io/repository-import-tag-limit"
+)
+
+// sort orders accepted by RepositoryImportSortAnnotation
+const (
+	RepositoryImportSortPrioritized  = "prioritized"
+	RepositoryImportSortAlphabetical = "alphabetical"
+)
+
+// RepositoryTagSelection captures the tag filtering and ordering requested by
+// the repository import annotations on an ImageStreamImport.
+type RepositoryTagSelection struct {
+	// Include holds the glob patterns a tag must match to be imported; empty
+	// means all tags are eligible.
+	Include []string
+
+	// Exclude holds the glob patterns that remove tags from consideration.
+	Exclude []string
+
+	// Sort is the order tags are considered in before the limit applies.
+	Sort string
+
+	// Limit is the requested number of tags to import; zero means the server
+	// default.
+	Limit int
+}
+
+// ParseRepositoryTagSelection reads the repository import annotations and
+// returns the selection they describe, or nil when none are set.  Malformed
+// patterns, unknown sort orders and non-positive limits are rejected so that
+// a typo does not silently import every tag.
+func ParseRepositoryTagSelection(annotations map[string]string) (*RepositoryTagSelection, error) {
+	selection := &RepositoryTagSelection{}
+	found := false
+
+	if value, ok := annotations[RepositoryImportIncludeAnnotation]; ok {
+		patterns, err := parseTagPatterns(RepositoryImportIncludeAnnotation, value)
+		if err != nil {
+			return nil, err
+		}
+		selection.Include = patterns
+		found = true
+	}
+	if value, ok := annotations[RepositoryImportExcludeAnnotation]; ok {
+		patterns, err := parseTagPatterns(RepositoryImportExcludeAnnotation, value)
+		if err != nil {
+			return nil, err
+		}
+		selection.Exclude = patterns
+		found = true
+	}
+	if value, ok := annotations[RepositoryImportSortAnnotation]; ok {
+		switch value {
+		case RepositoryImportSortPrioritized, RepositoryImportSortAlphabetical:
+			selection.Sort = value
+		default:
+			return nil, fmt.Errorf("annotation %s must be %q or %q, not %q", RepositoryImportSortAnnotation, RepositoryImportSortPrioritized, RepositoryImportSortAlphabetical, value)
+		}
+		found = true
+	}
+	if value, ok := annotations[RepositoryImportLimitAnnotation]; ok {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("annotation %s must be a positive integer, not %q", RepositoryImportLimitAnnotation, value)
+		}
+		selection.Limit = limit
+		found = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+	return selection, nil
+}
+
+func parseTagPatterns(annotation, value string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("annotation %s contains an invalid pattern %q: %v", annotation, pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// FilterTags returns the tags that pass the include and exclude patterns, in
+// their original order.
+func (s *RepositoryTagSelection) FilterTags(tags []string) []string {
+	filtered := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !matchesAny(s.Include, tag, true) {
+			continue
+		}
+		if matchesAny(s.Exclude, tag, false) {
+			continue
+		}
+		filtered = append(filtered, tag)
+	}
+	return filtered
+}
+
+// matchesAny reports whether tag matches any of the patterns; an empty
+// pattern list yields emptyResult.  Patterns were validated at parse time, so
+// match errors cannot occur here.
+func matchesAny(patterns []string, tag string, emptyResult bool) bool {
+	if len(patterns) == 0 {
+		return emptyResult
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, tag); ok {
+			return true
+		}
+	}
+	return false
+}
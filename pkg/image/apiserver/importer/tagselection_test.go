@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRepositoryTagSelection(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		expected    *RepositoryTagSelection
+		expectErr   bool
+	}{
+		"no annotations": {
+			annotations: map[string]string{"unrelated": "value"},
+		},
+		"include and exclude patterns": {
+			annotations: map[string]string{
+				RepositoryImportIncludeAnnotation: "v4.*, v5.*",
+				RepositoryImportExcludeAnnotation: "*-beta*",
+			},
+			expected: &RepositoryTagSelection{
+				Include: []string{"v4.*", "v5.*"},
+				Exclude: []string{"*-beta*"},
+			},
+		},
+		"sort and limit": {
+			annotations: map[string]string{
+				RepositoryImportSortAnnotation:  RepositoryImportSortAlphabetical,
+				RepositoryImportLimitAnnotation: "10",
+			},
+			expected: &RepositoryTagSelection{
+				Sort:  RepositoryImportSortAlphabetical,
+				Limit: 10,
+			},
+		},
+		"invalid pattern": {
+			annotations: map[string]string{RepositoryImportIncludeAnnotation: "v4.[*"},
+			expectErr:   true,
+		},
+		"unknown sort order": {
+			annotations: map[string]string{RepositoryImportSortAnnotation: "newest"},
+			expectErr:   true,
+		},
+		"non-numeric limit": {
+			annotations: map[string]string{RepositoryImportLimitAnnotation: "ten"},
+			expectErr:   true,
+		},
+		"non-positive limit": {
+			annotations: map[string]string{RepositoryImportLimitAnnotation: "0"},
+			expectErr:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			selection, err := ParseRepositoryTagSelection(test.annotations)
+			if test.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got selection %#v", selection)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(selection, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, selection)
+			}
+		})
+	}
+}
+
+func TestFilterTags(t *testing.T) {
+	tags := []string{"latest", "v4.1", "v4.2", "v4.2-beta1", "v5.0", "candidate"}
+
+	tests := map[string]struct {
+		selection *RepositoryTagSelection
+		expected  []string
+	}{
+		"no patterns keeps everything": {
+			selection: &RepositoryTagSelection{},
+			expected:  tags,
+		},
+		"include narrows": {
+			selection: &RepositoryTagSelection{Include: []string{"v4.*"}},
+			expected:  []string{"v4.1", "v4.2", "v4.2-beta1"},
+		},
+		"exclude removes": {
+			selection: &RepositoryTagSelection{Exclude: []string{"*-beta*", "candidate"}},
+			expected:  []string{"latest", "v4.1", "v4.2", "v5.0"},
+		},
+		"include and exclude combine": {
+			selection: &RepositoryTagSelection{Include: []string{"v4.*"}, Exclude: []string{"*-beta*"}},
+			expected:  []string{"v4.1", "v4.2"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if filtered := test.selection.FilterTags(tags); !reflect.DeepEqual(filtered, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, filtered)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package internalimageutil
+
+import (
+	"fmt"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Components of the compound names of the virtual image resources, recorded
+// in the Field of a NotFound cause to say what exactly was absent.
+const (
+	// MissingImageStreamCause marks a NotFound caused by the backing image
+	// stream not existing.
+	MissingImageStreamCause = "imageStream"
+	// MissingTagCause marks a NotFound caused by the stream existing but not
+	// carrying the requested tag.
+	MissingTagCause = "tag"
+	// MissingImageCause marks a NotFound caused by the requested image not
+	// being recorded in or resolvable from the stream.
+	MissingImageCause = "image"
+)
+
+// NewNotFound returns a NotFound error for one of the virtual image resources
+// (imagestreamimages, imagestreamtags, imagetags) with a cause recording
+// which component of the compound name was missing.  The three resources are
+// materialized from image streams, so "not found" can mean the backing
+// stream, the tag, or the image was absent; clients that react differently to
+// each case rely on the cause to tell them apart.
+func NewNotFound(resource schema.GroupResource, name, missingComponent string) *kapierrors.StatusError {
+	err := kapierrors.NewNotFound(resource, name)
+	err.ErrStatus.Details.Causes = []metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldValueNotFound,
+		Field:   missingComponent,
+		Message: fmt.Sprintf("the %s referenced by %s %q does not exist", missingComponent, resource.Resource, name),
+	}}
+	return err
+}
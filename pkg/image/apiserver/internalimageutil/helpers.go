@@ -350,6 +350,39 @@ func ResolveReferenceForTagEvent(stream *imageapi.ImageStream, tag string, lates
 	}
 }
 
+// ResolveReferenceForTagEventWithAliases applies the tag reference rules for a
+// stream, tag, and tag event, and additionally recognizes registryAliases as
+// hostnames of the integrated registry. A resolved reference that still points
+// at an alias, for example an external registry hostname the registry was
+// served under before a route rename, is rewritten to a pullthrough spec on
+// the stream's current local repository.
+func ResolveReferenceForTagEventWithAliases(stream *imageapi.ImageStream, tag string, latest *imageapi.TagEvent, registryAliases []string) string {
+	resolved := ResolveReferenceForTagEvent(stream, tag, latest)
+	if len(registryAliases) == 0 || len(latest.Image) == 0 || len(stream.Status.DockerImageRepository) == 0 {
+		return resolved
+	}
+	resolvedRef, err := reference.Parse(resolved)
+	if err != nil {
+		return resolved
+	}
+	localRef, err := reference.Parse(stream.Status.DockerImageRepository)
+	if err != nil {
+		return resolved
+	}
+	if resolvedRef.Registry == localRef.Registry {
+		// already points at the current local repository
+		return resolved
+	}
+	for _, alias := range registryAliases {
+		if resolvedRef.Registry == alias {
+			localRef.Tag = ""
+			localRef.ID = latest.Image
+			return localRef.Exact()
+		}
+	}
+	return resolved
+}
+
 // AddTagEventToImageStream attempts to update the given image stream with a tag event. It will
 // collapse duplicate entries - returning true if a change was made or false if no change
 // occurred. Any successful tag resets the status field.
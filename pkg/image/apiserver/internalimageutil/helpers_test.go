@@ -1138,3 +1138,49 @@ func TestResolveImageID(t *testing.T) {
 		}
 	}
 }
+
+func TestResolveReferenceForTagEventWithAliases(t *testing.T) {
+	const imageID = "sha256:0000000000000000000000000000000000000000000000000000000000000005"
+	stream := &imageapi.ImageStream{
+		Spec: imageapi.ImageStreamSpec{
+			Tags: map[string]imageapi.TagReference{
+				"latest": {ReferencePolicy: imageapi.TagReferencePolicy{Type: imageapi.SourceTagReferencePolicy}},
+			},
+		},
+		Status: imageapi.ImageStreamStatus{
+			DockerImageRepository: "registry.internal:5000/test/stream",
+		},
+	}
+
+	tests := map[string]struct {
+		reference string
+		aliases   []string
+		expected  string
+	}{
+		"old external hostname is rewritten to the local repository": {
+			reference: "old-route.apps.example.com/test/stream@" + imageID,
+			aliases:   []string{"registry.internal:5000", "new-route.apps.example.com", "old-route.apps.example.com"},
+			expected:  "registry.internal:5000/test/stream@" + imageID,
+		},
+		"current local hostname is left alone": {
+			reference: "registry.internal:5000/test/stream@" + imageID,
+			aliases:   []string{"registry.internal:5000", "old-route.apps.example.com"},
+			expected:  "registry.internal:5000/test/stream@" + imageID,
+		},
+		"unrelated registry is left alone": {
+			reference: "quay.io/test/stream@" + imageID,
+			aliases:   []string{"registry.internal:5000", "old-route.apps.example.com"},
+			expected:  "quay.io/test/stream@" + imageID,
+		},
+		"no aliases disables rewriting": {
+			reference: "old-route.apps.example.com/test/stream@" + imageID,
+			expected:  "old-route.apps.example.com/test/stream@" + imageID,
+		},
+	}
+	for name, test := range tests {
+		event := &imageapi.TagEvent{DockerImageReference: test.reference, Image: imageID}
+		if got := ResolveReferenceForTagEventWithAliases(stream, "latest", event, test.aliases); got != test.expected {
+			t.Errorf("%s: expected %s, got %s", name, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Package labelpolicy evaluates cluster policy about labels that imported
+// image manifests must carry, such as the org.opencontainers.image.source
+// and vendor provenance labels some organizations require on every image.
+package labelpolicy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+// Mode decides what happens when an imported image is missing a required
+// label.
+type Mode string
+
+const (
+	// ModeWarn records the violation but lets the import proceed.
+	ModeWarn Mode = "Warn"
+	// ModeReject fails the import of the offending image.
+	ModeReject Mode = "Reject"
+)
+
+// ParseMode returns the Mode named by value, case-insensitively.
+func ParseMode(value string) (Mode, error) {
+	switch {
+	case strings.EqualFold(value, string(ModeWarn)):
+		return ModeWarn, nil
+	case strings.EqualFold(value, string(ModeReject)):
+		return ModeReject, nil
+	default:
+		return "", fmt.Errorf("unrecognized mode %q, must be %s or %s", value, ModeWarn, ModeReject)
+	}
+}
+
+// Policy lists the labels every imported image must carry and what to do
+// when one is missing. The zero value permits everything.
+type Policy struct {
+	// RequiredLabels are label names the image config must define with a
+	// non-empty value.
+	RequiredLabels []string
+	// Mode selects between warning and rejecting on a violation.
+	Mode Mode
+}
+
+// Enabled is true when the policy requires at least one label.
+func (p Policy) Enabled() bool {
+	return len(p.RequiredLabels) > 0
+}
+
+// MissingLabels returns the required labels the image does not carry, sorted
+// for stable messages. Labels are read from the image config; images whose
+// metadata could not be parsed are treated as carrying none.
+func (p Policy) MissingLabels(image *imageapi.Image) []string {
+	if !p.Enabled() {
+		return nil
+	}
+	labels := image.DockerImageMetadata.ContainerConfig.Labels
+	if config := image.DockerImageMetadata.Config; config != nil {
+		labels = config.Labels
+	}
+	var missing []string
+	for _, name := range p.RequiredLabels {
+		if len(labels[name]) == 0 {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// ViolationMessage describes a missing-labels violation for statuses, error
+// messages, and logs.
+func ViolationMessage(image *imageapi.Image, missing []string) string {
+	name := image.DockerImageReference
+	if len(name) == 0 {
+		name = image.Name
+	}
+	return fmt.Sprintf("image %s is missing required labels: %s", name, strings.Join(missing, ", "))
+}
@@ -0,0 +1,102 @@
+package labelpolicy
+
+import (
+	"reflect"
+	"testing"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+func imageWithLabels(labels map[string]string) *imageapi.Image {
+	return &imageapi.Image{
+		DockerImageReference: "registry.example.com/ns/app@sha256:abc",
+		DockerImageMetadata: imageapi.DockerImage{
+			Config: &imageapi.DockerConfig{Labels: labels},
+		},
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for value, expected := range map[string]Mode{
+		"Warn":   ModeWarn,
+		"warn":   ModeWarn,
+		"Reject": ModeReject,
+		"reject": ModeReject,
+	} {
+		mode, err := ParseMode(value)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", value, err)
+		}
+		if mode != expected {
+			t.Errorf("%s: expected %s, got %s", value, expected, mode)
+		}
+	}
+	if _, err := ParseMode("Enforce"); err == nil {
+		t.Error("expected an error for an unrecognized mode")
+	}
+}
+
+func TestMissingLabels(t *testing.T) {
+	policy := Policy{
+		RequiredLabels: []string{"vendor", "org.opencontainers.image.source"},
+		Mode:           ModeReject,
+	}
+
+	testCases := []struct {
+		name    string
+		image   *imageapi.Image
+		missing []string
+	}{
+		{
+			name: "all labels present",
+			image: imageWithLabels(map[string]string{
+				"vendor":                          "Example Corp",
+				"org.opencontainers.image.source": "https://git.example.com/app",
+			}),
+		},
+		{
+			name:    "one label missing",
+			image:   imageWithLabels(map[string]string{"vendor": "Example Corp"}),
+			missing: []string{"org.opencontainers.image.source"},
+		},
+		{
+			name:    "empty value counts as missing",
+			image:   imageWithLabels(map[string]string{"vendor": "", "org.opencontainers.image.source": "https://git.example.com/app"}),
+			missing: []string{"vendor"},
+		},
+		{
+			name:    "no config at all",
+			image:   &imageapi.Image{},
+			missing: []string{"org.opencontainers.image.source", "vendor"},
+		},
+	}
+	for _, tc := range testCases {
+		if missing := policy.MissingLabels(tc.image); !reflect.DeepEqual(missing, tc.missing) {
+			t.Errorf("%s: expected missing %v, got %v", tc.name, tc.missing, missing)
+		}
+	}
+
+	if missing := (Policy{}).MissingLabels(imageWithLabels(nil)); missing != nil {
+		t.Errorf("expected a disabled policy to report nothing, got %v", missing)
+	}
+}
+
+func TestMissingLabelsFallsBackToContainerConfig(t *testing.T) {
+	policy := Policy{RequiredLabels: []string{"vendor"}}
+	image := &imageapi.Image{
+		DockerImageMetadata: imageapi.DockerImage{
+			ContainerConfig: imageapi.DockerConfig{Labels: map[string]string{"vendor": "Example Corp"}},
+		},
+	}
+	if missing := policy.MissingLabels(image); len(missing) != 0 {
+		t.Errorf("expected labels from the container config to satisfy the policy, got %v", missing)
+	}
+}
+
+func TestViolationMessage(t *testing.T) {
+	message := ViolationMessage(imageWithLabels(nil), []string{"vendor"})
+	expected := "image registry.example.com/ns/app@sha256:abc is missing required labels: vendor"
+	if message != expected {
+		t.Errorf("expected %q, got %q", expected, message)
+	}
+}
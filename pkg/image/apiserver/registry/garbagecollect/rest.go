@@ -0,0 +1,270 @@
+package garbagecollect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	imagestreametcd "github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream/etcd"
+)
+
+// defaultKeepTagRevisions is how many most recent history items of every tag
+// are retained when the request does not say otherwise.  It matches the
+// client-side pruner default.
+const defaultKeepTagRevisions = 3
+
+// defaultKeepYoungerThan protects recently pushed revisions from showing up
+// as prune candidates regardless of their position in the tag history.
+const defaultKeepYoungerThan = 60 * time.Minute
+
+// streamGetter is the subset of the image stream registry the garbage-collect
+// subresource needs.
+type streamGetter interface {
+	GetImageStream(ctx context.Context, id string, options *metav1.GetOptions) (*imageapi.ImageStream, error)
+}
+
+// PrunableRevision identifies one tag history item that falls outside the
+// retention policy.
+type PrunableRevision struct {
+	// Tag is the status tag the revision belongs to.
+	Tag string `json:"tag"`
+	// Image is the digest of the image the revision points at.
+	Image string `json:"image"`
+	// Created is when the revision was recorded.
+	Created metav1.Time `json:"created"`
+}
+
+// PrunableBlob describes one blob referenced only by prunable revisions of
+// this stream.
+type PrunableBlob struct {
+	// Name is the blob digest.
+	Name string `json:"name"`
+	// LayerSize is the size of the blob in bytes, when known.
+	LayerSize *int64 `json:"layerSize,omitempty"`
+	// MediaType is the media type of the blob, when known.
+	MediaType string `json:"mediaType,omitempty"`
+}
+
+// GarbageCollectPlan is the response body of the garbage-collect subresource.
+// It reports what a pruner honoring the given retention policy would remove
+// from the stream; nothing is deleted by this call.
+type GarbageCollectPlan struct {
+	// Namespace and Name identify the ImageStream the plan was computed for.
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// KeepTagRevisions and KeepYoungerThan echo the retention policy the
+	// plan was computed against.
+	KeepTagRevisions int    `json:"keepTagRevisions"`
+	KeepYoungerThan  string `json:"keepYoungerThan"`
+	// Revisions lists the tag history items outside the retention policy.
+	Revisions []PrunableRevision `json:"revisions"`
+	// Blobs lists the blobs that no retained revision of this stream
+	// references.  Other streams or images may still reference them; the
+	// plan is scoped to this stream only.
+	Blobs []PrunableBlob `json:"blobs"`
+	// MissingImages lists prunable image digests that were not found in the
+	// layer index; their blob contribution to the plan is unknown.
+	MissingImages []string `json:"missingImages,omitempty"`
+}
+
+// REST computes prune candidates for a single image stream server-side, where
+// the image layer index already exists.  Clients previously had to list every
+// image on the cluster to make the same decision.
+type REST struct {
+	streams streamGetter
+	index   imagestreametcd.ImageLayerIndex
+	now     func() time.Time
+}
+
+var _ rest.Connecter = &REST{}
+var _ rest.Scoper = &REST{}
+
+// NewREST returns the handler for the imagestreams/{name}/garbage-collect
+// subresource.
+func NewREST(streams streamGetter, index imagestreametcd.ImageLayerIndex) *REST {
+	return &REST{streams: streams, index: index, now: time.Now}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &imageapi.ImageStream{}
+}
+
+func (r *REST) NamespaceScoped() bool {
+	return true
+}
+
+// ConnectMethods returns GET, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"GET"}
+}
+
+// NewConnectOptions returns an empty options object; the retention policy is
+// passed through query parameters
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that computes the prune plan for the named
+// ImageStream.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	if !r.index.HasSynced() {
+		return nil, kapierrors.NewServerTimeout(imageapi.Resource("imagestreams"), "get", 2)
+	}
+	stream, err := r.streams.GetImageStream(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		keepTagRevisions, keepYoungerThan, err := retentionFromQuery(req)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+
+		plan := planForStream(stream, r.index, keepTagRevisions, keepYoungerThan, r.now())
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(plan); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+// retentionFromQuery reads the retention policy from the request, falling
+// back to the pruner defaults.
+func retentionFromQuery(req *http.Request) (int, time.Duration, error) {
+	keepTagRevisions := defaultKeepTagRevisions
+	if value := req.URL.Query().Get("keepTagRevisions"); len(value) > 0 {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, kapierrors.NewBadRequest(fmt.Sprintf("keepTagRevisions must be a non-negative integer: %q", value))
+		}
+		keepTagRevisions = parsed
+	}
+	keepYoungerThan := defaultKeepYoungerThan
+	if value := req.URL.Query().Get("keepYoungerThan"); len(value) > 0 {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, kapierrors.NewBadRequest(fmt.Sprintf("keepYoungerThan must be a non-negative duration: %q", value))
+		}
+		keepYoungerThan = parsed
+	}
+	return keepTagRevisions, keepYoungerThan, nil
+}
+
+// planForStream applies the retention policy to the status tags of stream and
+// resolves the blobs of prunable images through the layer index.
+func planForStream(stream *imageapi.ImageStream, index imagestreametcd.ImageLayerIndex, keepTagRevisions int, keepYoungerThan time.Duration, now time.Time) *GarbageCollectPlan {
+	plan := &GarbageCollectPlan{
+		Namespace:        stream.Namespace,
+		Name:             stream.Name,
+		KeepTagRevisions: keepTagRevisions,
+		KeepYoungerThan:  keepYoungerThan.String(),
+		Revisions:        []PrunableRevision{},
+		Blobs:            []PrunableBlob{},
+	}
+	cutoff := now.Add(-keepYoungerThan)
+
+	retained := make(map[string]struct{})
+	prunable := make(map[string]struct{})
+	for tag, history := range stream.Status.Tags {
+		for i, item := range history.Items {
+			if len(item.Image) == 0 {
+				continue
+			}
+			// a revision is prunable only when it is both beyond the
+			// revision count and old enough
+			if i < keepTagRevisions || item.Created.Time.After(cutoff) {
+				retained[item.Image] = struct{}{}
+				continue
+			}
+			prunable[item.Image] = struct{}{}
+			plan.Revisions = append(plan.Revisions, PrunableRevision{Tag: tag, Image: item.Image, Created: item.Created})
+		}
+	}
+	sort.Slice(plan.Revisions, func(i, j int) bool {
+		a, b := plan.Revisions[i], plan.Revisions[j]
+		if a.Tag != b.Tag {
+			return a.Tag < b.Tag
+		}
+		return a.Image < b.Image
+	})
+
+	// a digest tagged both inside and outside the retention window stays
+	for image := range prunable {
+		if _, ok := retained[image]; ok {
+			delete(prunable, image)
+		}
+	}
+
+	referenced := make(map[string]struct{})
+	for image := range retained {
+		entry, ok := layersForImage(index, image)
+		if !ok {
+			continue
+		}
+		for _, blob := range blobsForEntry(image, entry) {
+			referenced[blob.Name] = struct{}{}
+		}
+	}
+
+	blobs := make(map[string]PrunableBlob)
+	for image := range prunable {
+		entry, ok := layersForImage(index, image)
+		if !ok {
+			plan.MissingImages = append(plan.MissingImages, image)
+			continue
+		}
+		for _, blob := range blobsForEntry(image, entry) {
+			if _, ok := referenced[blob.Name]; ok {
+				continue
+			}
+			blobs[blob.Name] = blob
+		}
+	}
+	for _, blob := range blobs {
+		plan.Blobs = append(plan.Blobs, blob)
+	}
+	sort.Slice(plan.Blobs, func(i, j int) bool { return plan.Blobs[i].Name < plan.Blobs[j].Name })
+	sort.Strings(plan.MissingImages)
+
+	return plan
+}
+
+// layersForImage looks up one image in the layer index.
+func layersForImage(index imagestreametcd.ImageLayerIndex, image string) (*imagestreametcd.ImageLayers, bool) {
+	obj, _, _ := index.GetByKey(image)
+	entry, ok := obj.(*imagestreametcd.ImageLayers)
+	return entry, ok
+}
+
+// blobsForEntry lists every blob an image consists of: its layers, its config
+// blob when it has one, and the manifest itself.
+func blobsForEntry(image string, entry *imagestreametcd.ImageLayers) []PrunableBlob {
+	blobs := make([]PrunableBlob, 0, len(entry.Layers)+2)
+	for i := range entry.Layers {
+		layer := entry.Layers[i]
+		blobs = append(blobs, PrunableBlob{Name: layer.Name, LayerSize: &layer.LayerSize, MediaType: layer.MediaType})
+	}
+	if config := entry.Config; config != nil {
+		blob := PrunableBlob{Name: config.Name, MediaType: config.MediaType}
+		if config.LayerSize > 0 {
+			size := config.LayerSize
+			blob.LayerSize = &size
+		}
+		blobs = append(blobs, blob)
+	}
+	blobs = append(blobs, PrunableBlob{Name: image, MediaType: entry.MediaType})
+	return blobs
+}
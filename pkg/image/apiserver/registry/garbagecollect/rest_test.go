@@ -0,0 +1,182 @@
+package garbagecollect
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	imagestreametcd "github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream/etcd"
+)
+
+type fakeLayerIndex struct {
+	entries map[string]*imagestreametcd.ImageLayers
+}
+
+func (f fakeLayerIndex) HasSynced() bool { return true }
+func (f fakeLayerIndex) GetByKey(key string) (interface{}, bool, error) {
+	entry, ok := f.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	return entry, true, nil
+}
+func (f fakeLayerIndex) Run(stopCh <-chan struct{}) {}
+
+func layerSize(size int64) *int64 { return &size }
+
+func TestPlanForStream(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := metav1.NewTime(now.Add(-24 * time.Hour))
+	recent := metav1.NewTime(now.Add(-10 * time.Minute))
+
+	stream := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Status: imageapi.ImageStreamStatus{
+			Tags: map[string]imageapi.TagEventList{
+				"latest": {
+					Items: []imageapi.TagEvent{
+						{Image: "sha256:keep1", Created: old},
+						{Image: "sha256:prune1", Created: old},
+						{Image: "sha256:young", Created: recent},
+						{Image: "sha256:prune2", Created: old},
+					},
+				},
+				"stable": {
+					Items: []imageapi.TagEvent{
+						{Image: "sha256:prune1", Created: old},
+					},
+				},
+			},
+		},
+	}
+
+	index := fakeLayerIndex{entries: map[string]*imagestreametcd.ImageLayers{
+		"sha256:keep1": {
+			Name:      "sha256:keep1",
+			MediaType: "application/vnd.docker.distribution.manifest.v2+json",
+			Layers:    []imagev1.ImageLayer{{Name: "sha256:shared", LayerSize: 100}},
+		},
+		"sha256:young": {
+			Name:      "sha256:young",
+			MediaType: "application/vnd.docker.distribution.manifest.v2+json",
+			Layers:    []imagev1.ImageLayer{{Name: "sha256:shared", LayerSize: 100}},
+		},
+		"sha256:prune1": {
+			Name:      "sha256:prune1",
+			MediaType: "application/vnd.docker.distribution.manifest.v2+json",
+			Config:    &imagev1.ImageLayer{Name: "sha256:prune1config", MediaType: "application/vnd.docker.container.image.v1+json"},
+			Layers: []imagev1.ImageLayer{
+				{Name: "sha256:shared", LayerSize: 100},
+				{Name: "sha256:orphan", LayerSize: 200},
+			},
+		},
+	}}
+
+	plan := planForStream(stream, index, 1, time.Hour, now)
+
+	// the latest history entries for prune1 and prune2 are beyond the
+	// retention policy; the young revision is protected by keepYoungerThan
+	// even though it is beyond the revision count.
+	expectedRevisions := []PrunableRevision{
+		{Tag: "latest", Image: "sha256:prune1", Created: old},
+		{Tag: "latest", Image: "sha256:prune2", Created: old},
+	}
+	if !reflect.DeepEqual(plan.Revisions, expectedRevisions) {
+		t.Errorf("unexpected revisions: %#v", plan.Revisions)
+	}
+	if expected := []string{"sha256:prune2"}; !reflect.DeepEqual(plan.MissingImages, expected) {
+		t.Errorf("unexpected missing images: %v", plan.MissingImages)
+	}
+	if len(plan.Blobs) != 0 {
+		t.Errorf("expected no prunable blobs while prune1 is retained, got %#v", plan.Blobs)
+	}
+}
+
+func TestPlanForStreamOrphanedBlobs(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := metav1.NewTime(now.Add(-24 * time.Hour))
+
+	stream := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Status: imageapi.ImageStreamStatus{
+			Tags: map[string]imageapi.TagEventList{
+				"latest": {
+					Items: []imageapi.TagEvent{
+						{Image: "sha256:keep1", Created: old},
+						{Image: "sha256:prune1", Created: old},
+					},
+				},
+			},
+		},
+	}
+
+	index := fakeLayerIndex{entries: map[string]*imagestreametcd.ImageLayers{
+		"sha256:keep1": {
+			Name:      "sha256:keep1",
+			MediaType: "application/vnd.docker.distribution.manifest.v2+json",
+			Layers:    []imagev1.ImageLayer{{Name: "sha256:shared", LayerSize: 100}},
+		},
+		"sha256:prune1": {
+			Name:      "sha256:prune1",
+			MediaType: "application/vnd.docker.distribution.manifest.v2+json",
+			Layers: []imagev1.ImageLayer{
+				{Name: "sha256:shared", LayerSize: 100},
+				{Name: "sha256:orphan", LayerSize: 200},
+			},
+		},
+	}}
+
+	plan := planForStream(stream, index, 1, time.Hour, now)
+
+	expectedBlobs := []PrunableBlob{
+		{Name: "sha256:orphan", LayerSize: layerSize(200)},
+		{Name: "sha256:prune1", MediaType: "application/vnd.docker.distribution.manifest.v2+json"},
+	}
+	if !reflect.DeepEqual(plan.Blobs, expectedBlobs) {
+		t.Errorf("unexpected blobs: %#v", plan.Blobs)
+	}
+	if len(plan.MissingImages) != 0 {
+		t.Errorf("unexpected missing images: %v", plan.MissingImages)
+	}
+}
+
+func TestRetentionFromQuery(t *testing.T) {
+	testCases := []struct {
+		query            string
+		expectErr        bool
+		keepTagRevisions int
+		keepYoungerThan  time.Duration
+	}{
+		{query: "", keepTagRevisions: 3, keepYoungerThan: 60 * time.Minute},
+		{query: "keepTagRevisions=0&keepYoungerThan=0s", keepTagRevisions: 0, keepYoungerThan: 0},
+		{query: "keepTagRevisions=5", keepTagRevisions: 5, keepYoungerThan: 60 * time.Minute},
+		{query: "keepYoungerThan=30m", keepTagRevisions: 3, keepYoungerThan: 30 * time.Minute},
+		{query: "keepTagRevisions=-1", expectErr: true},
+		{query: "keepTagRevisions=many", expectErr: true},
+		{query: "keepYoungerThan=-1h", expectErr: true},
+		{query: "keepYoungerThan=soon", expectErr: true},
+	}
+	for _, tc := range testCases {
+		req := &http.Request{URL: &url.URL{RawQuery: tc.query}}
+		keepTagRevisions, keepYoungerThan, err := retentionFromQuery(req)
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("%q: expected an error", tc.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.query, err)
+			continue
+		}
+		if keepTagRevisions != tc.keepTagRevisions || keepYoungerThan != tc.keepYoungerThan {
+			t.Errorf("%q: expected (%d, %s), got (%d, %s)", tc.query, tc.keepTagRevisions, tc.keepYoungerThan, keepTagRevisions, keepYoungerThan)
+		}
+	}
+}
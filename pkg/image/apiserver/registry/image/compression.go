@@ -0,0 +1,143 @@
+package image
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+// manifestCompressionAnnotation marks images whose DockerImageManifest and
+// DockerImageConfig fields are stored gzip compressed and base64 encoded.
+// The annotation is internal to the storage layer; reads decompress the
+// fields and strip it, so clients always observe plain manifests.
+const manifestCompressionAnnotation = "image.openshift.io/manifest-compression"
+
+// manifestCompressionGzip is the only compression scheme currently written.
+// Reads reject unknown schemes so a future scheme cannot be silently
+// misinterpreted as plain text.
+const manifestCompressionGzip = "gzip"
+
+// manifestCompressionThreshold is the combined size of the manifest and
+// config fields above which they are compressed before being persisted.
+// Manifest lists covering many platforms plus attestations can approach the
+// etcd value limit; everything smaller is stored as-is and stays readable by
+// servers that predate compression.
+const manifestCompressionThreshold = 256 * 1024
+
+// compressManifestFields rewrites the manifest and config fields of image
+// into their compressed stored form when they are large enough to warrant
+// it. Failures are logged and leave the image uncompressed, which is always
+// a valid stored form.
+func compressManifestFields(image *imageapi.Image) {
+	if _, ok := image.Annotations[manifestCompressionAnnotation]; ok {
+		return
+	}
+	if len(image.DockerImageManifest)+len(image.DockerImageConfig) < manifestCompressionThreshold {
+		return
+	}
+	manifest, err := compressField(image.DockerImageManifest)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("unable to compress manifest of image %q, storing it uncompressed: %v", image.Name, err))
+		return
+	}
+	config, err := compressField(image.DockerImageConfig)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("unable to compress config of image %q, storing it uncompressed: %v", image.Name, err))
+		return
+	}
+	image.DockerImageManifest = manifest
+	image.DockerImageConfig = config
+	if image.Annotations == nil {
+		image.Annotations = map[string]string{}
+	}
+	image.Annotations[manifestCompressionAnnotation] = manifestCompressionGzip
+}
+
+// decompressManifestFields restores the plain manifest and config fields of
+// an image read from storage and removes the compression annotation. Images
+// stored before compression existed carry no annotation and pass through
+// untouched.
+func decompressManifestFields(image *imageapi.Image) error {
+	scheme, ok := image.Annotations[manifestCompressionAnnotation]
+	if !ok {
+		return nil
+	}
+	if scheme != manifestCompressionGzip {
+		return fmt.Errorf("image %q is stored with unsupported manifest compression %q", image.Name, scheme)
+	}
+	manifest, err := decompressField(image.DockerImageManifest)
+	if err != nil {
+		return fmt.Errorf("unable to decompress manifest of image %q: %v", image.Name, err)
+	}
+	config, err := decompressField(image.DockerImageConfig)
+	if err != nil {
+		return fmt.Errorf("unable to decompress config of image %q: %v", image.Name, err)
+	}
+	image.DockerImageManifest = manifest
+	image.DockerImageConfig = config
+	delete(image.Annotations, manifestCompressionAnnotation)
+	return nil
+}
+
+// DecompressManifestFields restores compressed manifest fields on an image
+// or image list returned from storage. It is installed as the registry store
+// decorator so that every read path sees plain manifests regardless of the
+// stored form.
+func DecompressManifestFields(obj runtime.Object) {
+	switch t := obj.(type) {
+	case *imageapi.Image:
+		if err := decompressManifestFields(t); err != nil {
+			utilruntime.HandleError(err)
+		}
+	case *imageapi.ImageList:
+		for i := range t.Items {
+			if err := decompressManifestFields(&t.Items[i]); err != nil {
+				utilruntime.HandleError(err)
+			}
+		}
+	}
+}
+
+func compressField(value string) (string, error) {
+	if len(value) == 0 {
+		return "", nil
+	}
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write([]byte(value)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func decompressField(value string) (string, error) {
+	if len(value) == 0 {
+		return "", nil
+	}
+	compressed, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", err
+	}
+	plain, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	if err := r.Close(); err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,110 @@
+package image
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+func TestCompressManifestFieldsRoundTrip(t *testing.T) {
+	manifest := strings.Repeat(`{"schemaVersion":2,"layers":[]}`, 3*manifestCompressionThreshold/30)
+	config := strings.Repeat(`{"architecture":"amd64"}`, 100)
+
+	image := &imageapi.Image{
+		ObjectMeta:          metav1.ObjectMeta{Name: "sha256:0000"},
+		DockerImageManifest: manifest,
+		DockerImageConfig:   config,
+	}
+
+	compressManifestFields(image)
+	if image.Annotations[manifestCompressionAnnotation] != manifestCompressionGzip {
+		t.Fatalf("expected compression annotation, got %v", image.Annotations)
+	}
+	if image.DockerImageManifest == manifest {
+		t.Fatalf("expected the manifest to be rewritten")
+	}
+	if len(image.DockerImageManifest) >= len(manifest) {
+		t.Errorf("expected the stored manifest to shrink, got %d >= %d", len(image.DockerImageManifest), len(manifest))
+	}
+
+	if err := decompressManifestFields(image); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if image.DockerImageManifest != manifest || image.DockerImageConfig != config {
+		t.Errorf("round trip did not restore the original fields")
+	}
+	if _, ok := image.Annotations[manifestCompressionAnnotation]; ok {
+		t.Errorf("expected the compression annotation to be removed")
+	}
+}
+
+func TestCompressManifestFieldsSkipsSmallImages(t *testing.T) {
+	image := &imageapi.Image{
+		ObjectMeta:          metav1.ObjectMeta{Name: "sha256:0000"},
+		DockerImageManifest: `{"schemaVersion":2}`,
+	}
+
+	compressManifestFields(image)
+	if image.Annotations != nil {
+		t.Errorf("expected a small image to be left alone, got %v", image.Annotations)
+	}
+	if image.DockerImageManifest != `{"schemaVersion":2}` {
+		t.Errorf("expected the manifest to be untouched, got %q", image.DockerImageManifest)
+	}
+
+	// images stored before compression existed carry no annotation and must
+	// pass through reads unchanged
+	if err := decompressManifestFields(image); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if image.DockerImageManifest != `{"schemaVersion":2}` {
+		t.Errorf("expected the manifest to be untouched, got %q", image.DockerImageManifest)
+	}
+}
+
+func TestDecompressManifestFieldsUnknownScheme(t *testing.T) {
+	image := &imageapi.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "sha256:0000",
+			Annotations: map[string]string{manifestCompressionAnnotation: "zstd"},
+		},
+		DockerImageManifest: "opaque",
+	}
+
+	if err := decompressManifestFields(image); err == nil {
+		t.Fatalf("expected an error for an unknown compression scheme")
+	}
+	if image.DockerImageManifest != "opaque" {
+		t.Errorf("expected the stored manifest to be left untouched, got %q", image.DockerImageManifest)
+	}
+}
+
+func TestDecompressManifestFieldsList(t *testing.T) {
+	manifest := strings.Repeat("a", manifestCompressionThreshold)
+	compressed := &imageapi.Image{
+		ObjectMeta:          metav1.ObjectMeta{Name: "sha256:0001"},
+		DockerImageManifest: manifest,
+	}
+	compressManifestFields(compressed)
+
+	list := &imageapi.ImageList{
+		Items: []imageapi.Image{
+			*compressed,
+			{
+				ObjectMeta:          metav1.ObjectMeta{Name: "sha256:0002"},
+				DockerImageManifest: "plain",
+			},
+		},
+	}
+
+	DecompressManifestFields(list)
+	if list.Items[0].DockerImageManifest != manifest {
+		t.Errorf("expected the compressed item to be restored")
+	}
+	if list.Items[1].DockerImageManifest != "plain" {
+		t.Errorf("expected the plain item to be untouched, got %q", list.Items[1].DockerImageManifest)
+	}
+}
@@ -31,6 +31,10 @@ func NewREST(optsGetter generic.RESTOptionsGetter) (*REST, error) {
 
 		TableConvertor: printerstorage.TableConvertor{TableGenerator: printers.NewTableGenerator().With(imageprinters.AddImageOpenShiftHandlers)},
 
+		// manifests of very large images are stored compressed; hand clients
+		// the plain form on every read path
+		Decorator: imageregistry.DecompressManifestFields,
+
 		CreateStrategy: imageregistry.Strategy,
 		UpdateStrategy: imageregistry.Strategy,
 		DeleteStrategy: imageregistry.Strategy,
@@ -46,6 +46,9 @@ func (imageStrategy) NamespaceScoped() bool {
 // It extracts the latest information from the manifest (if available) and sets that onto the object.
 func (s imageStrategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
 	newImage := obj.(*imageapi.Image)
+	// the compression annotation never reaches clients, so an incoming object
+	// carrying one is treated as plain text
+	delete(newImage.Annotations, manifestCompressionAnnotation)
 	// ignore errors, change in place
 	if err := internalimageutil.InternalImageWithMetadata(newImage); err != nil {
 		utilruntime.HandleError(fmt.Errorf("Unable to update image metadata for %q: %v", newImage.Name, err))
@@ -73,8 +76,11 @@ func (imageStrategy) AllowUnconditionalUpdate() bool {
 	return false
 }
 
-// Canonicalize normalizes the object after validation.
+// Canonicalize normalizes the object after validation. Large manifest and
+// config fields are rewritten into their compressed stored form here, after
+// validation has run against the plain text.
 func (imageStrategy) Canonicalize(obj runtime.Object) {
+	compressManifestFields(obj.(*imageapi.Image))
 }
 
 // PrepareForUpdate clears fields that are not allowed to be set by end users on update.
@@ -85,6 +91,16 @@ func (s imageStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Ob
 	newImage := obj.(*imageapi.Image)
 	oldImage := old.(*imageapi.Image)
 
+	// the stored form of the old image may be compressed; restore the plain
+	// fields so the comparisons below and any values copied forward are plain
+	// text
+	if err := decompressManifestFields(oldImage); err != nil {
+		utilruntime.HandleError(err)
+	}
+	// the compression annotation never reaches clients, so an incoming object
+	// carrying one is treated as plain text
+	delete(newImage.Annotations, manifestCompressionAnnotation)
+
 	// image metadata cannot be altered
 	newImage.DockerImageMetadata = oldImage.DockerImageMetadata
 	newImage.DockerImageMetadataVersion = oldImage.DockerImageMetadataVersion
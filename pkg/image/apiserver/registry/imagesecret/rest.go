@@ -4,28 +4,39 @@ import (
 	"context"
 	"fmt"
 
+	authorizationapi "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
 	coreapi "k8s.io/kubernetes/pkg/apis/core"
 	corev1conversion "k8s.io/kubernetes/pkg/apis/core/v1"
+	"k8s.io/kubernetes/pkg/credentialprovider"
+	credentialprovidersecrets "k8s.io/kubernetes/pkg/credentialprovider/secrets"
 
 	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream"
 )
 
 // REST implements the RESTStorage interface for ImageStreamImport
 type REST struct {
-	secrets corev1client.SecretsGetter
+	secrets             corev1client.SecretsGetter
+	imageStreamRegistry imagestream.Registry
+	sarClient           authorizationclient.SubjectAccessReviewInterface
 }
 
 var _ rest.GetterWithOptions = &REST{}
 
 // NewREST returns a new REST.
-func NewREST(secrets corev1client.SecretsGetter) *REST {
-	return &REST{secrets: secrets}
+func NewREST(secrets corev1client.SecretsGetter, imageStreamRegistry imagestream.Registry, sarClient authorizationclient.SubjectAccessReviewInterface) *REST {
+	return &REST{secrets: secrets, imageStreamRegistry: imageStreamRegistry, sarClient: sarClient}
 }
 
 func (r *REST) New() runtime.Object {
@@ -36,9 +47,12 @@ func (r *REST) NewGetOptions() (runtime.Object, bool, string) {
 	return &metav1.ListOptions{}, false, ""
 }
 
-// Get retrieves all pull type secrets in the current namespace. Name is currently ignored and
-// reserved for future use.
-func (r *REST) Get(ctx context.Context, _ string, options runtime.Object) (runtime.Object, error) {
+// Get retrieves the pull type secrets in the current namespace that carry
+// credentials usable for the named image stream. Because the response exposes
+// secret contents, the caller must be able to read secrets in the namespace.
+// When the name is empty, or the stream's status does not record any
+// repository yet, every pull type secret is returned.
+func (r *REST) Get(ctx context.Context, name string, options runtime.Object) (runtime.Object, error) {
 	listOptions, ok := options.(*metav1.ListOptions)
 	if !ok {
 		return nil, fmt.Errorf("unexpected options: %T", options)
@@ -51,6 +65,17 @@ func (r *REST) Get(ctx context.Context, _ string, options runtime.Object) (runti
 	if !ok {
 		ns = metav1.NamespaceAll
 	}
+	if err := r.checkSecretReadAccess(ctx, ns); err != nil {
+		return nil, err
+	}
+	var pullSpecs []string
+	if len(name) > 0 && r.imageStreamRegistry != nil {
+		stream, err := r.imageStreamRegistry.GetImageStream(ctx, name, &metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		pullSpecs = streamPullSpecs(stream)
+	}
 	secrets, err := r.secrets.Secrets(ns).List(ctx, opts)
 	if err != nil {
 		return nil, err
@@ -62,6 +87,9 @@ func (r *REST) Get(ctx context.Context, _ string, options runtime.Object) (runti
 		}
 		switch secrets.Items[i].Type {
 		case corev1.SecretTypeDockercfg, corev1.SecretTypeDockerConfigJson:
+			if !secretMatchesPullSpecs(&secrets.Items[i], pullSpecs) {
+				continue
+			}
 			internalSecret := &coreapi.Secret{}
 			if err := corev1conversion.Convert_v1_Secret_To_core_Secret(&secrets.Items[i], internalSecret, nil); err != nil {
 				return nil, err
@@ -78,3 +106,80 @@ func (r *REST) Get(ctx context.Context, _ string, options runtime.Object) (runti
 	internalSecretList.Items = filtered
 	return internalSecretList, nil
 }
+
+// checkSecretReadAccess requires the requesting user to be able to read
+// secrets in the namespace, so that the subresource does not become a way
+// around the secret read permission.
+func (r *REST) checkSecretReadAccess(ctx context.Context, ns string) error {
+	if r.sarClient == nil {
+		return nil
+	}
+	user, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return kapierrors.NewBadRequest("unable to get user from context")
+	}
+	sar := authorizationutil.AddUserToSAR(user, &authorizationapi.SubjectAccessReview{
+		Spec: authorizationapi.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationapi.ResourceAttributes{
+				Namespace: ns,
+				Verb:      "get",
+				Resource:  "secrets",
+			},
+		},
+	})
+	resp, err := r.sarClient.Create(ctx, sar, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	if !resp.Status.Allowed {
+		return kapierrors.NewForbidden(imageapi.Resource("imagestreams/secrets"), "",
+			fmt.Errorf("the secrets subresource requires permission to read secrets in namespace %s", ns))
+	}
+	return nil
+}
+
+// streamPullSpecs collects the repositories the stream's status reports it is
+// served from, including the references of previously imported tag events.
+func streamPullSpecs(stream *imageapi.ImageStream) []string {
+	var specs []string
+	seen := map[string]struct{}{}
+	add := func(spec string) {
+		if len(spec) == 0 {
+			return
+		}
+		if _, ok := seen[spec]; ok {
+			return
+		}
+		seen[spec] = struct{}{}
+		specs = append(specs, spec)
+	}
+	add(stream.Status.DockerImageRepository)
+	add(stream.Status.PublicDockerImageRepository)
+	for _, events := range stream.Status.Tags {
+		for i := range events.Items {
+			add(events.Items[i].DockerImageReference)
+		}
+	}
+	return specs
+}
+
+// secretMatchesPullSpecs reports whether the secret's credentials apply to at
+// least one of the pull specs. An empty list matches everything, so streams
+// without status repositories still return all pull secrets.
+func secretMatchesPullSpecs(secret *corev1.Secret, pullSpecs []string) bool {
+	if len(pullSpecs) == 0 {
+		return true
+	}
+	emptyKeyring := credentialprovider.BasicDockerKeyring{}
+	keyring, err := credentialprovidersecrets.MakeDockerKeyring([]corev1.Secret{*secret.DeepCopy()}, &emptyKeyring)
+	if err != nil {
+		klog.V(2).Infof("Unable to make the Docker keyring for %s/%s secret: %v", secret.Namespace, secret.Name, err)
+		return false
+	}
+	for _, spec := range pullSpecs {
+		if _, found := keyring.Lookup(spec); found {
+			return true
+		}
+	}
+	return false
+}
@@ -1,16 +1,42 @@
 package imagesecret
 
 import (
+	"context"
 	"testing"
 
 	imagev1 "github.com/openshift/api/image/v1"
+	authorizationapi "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/client-go/kubernetes/fake"
 	coreapi "k8s.io/kubernetes/pkg/apis/core"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream"
 )
 
+type fakeSubjectAccessReviewRegistry struct {
+	allow   bool
+	request *authorizationapi.SubjectAccessReview
+}
+
+func (f *fakeSubjectAccessReviewRegistry) Create(_ context.Context, subjectAccessReview *authorizationapi.SubjectAccessReview, _ metav1.CreateOptions) (*authorizationapi.SubjectAccessReview, error) {
+	f.request = subjectAccessReview
+	return &authorizationapi.SubjectAccessReview{Status: authorizationapi.SubjectAccessReviewStatus{Allowed: f.allow}}, nil
+}
+
+type fakeImageStreamRegistry struct {
+	imagestream.Registry
+	stream *imageapi.ImageStream
+}
+
+func (f *fakeImageStreamRegistry) GetImageStream(ctx context.Context, id string, options *metav1.GetOptions) (*imageapi.ImageStream, error) {
+	return f.stream, nil
+}
+
 func TestGetSecrets(t *testing.T) {
 	fake := fake.NewSimpleClientset(&corev1.SecretList{
 		Items: []corev1.Secret{
@@ -36,9 +62,9 @@ func TestGetSecrets(t *testing.T) {
 			},
 		},
 	})
-	rest := NewREST(fake.CoreV1())
+	rest := NewREST(fake.CoreV1(), nil, &fakeSubjectAccessReviewRegistry{allow: true})
 	opts, _, _ := rest.NewGetOptions()
-	obj, err := rest.Get(apirequest.NewDefaultContext(), "", opts)
+	obj, err := rest.Get(testContext(), "", opts)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -50,3 +76,57 @@ func TestGetSecrets(t *testing.T) {
 		t.Fatal(list)
 	}
 }
+
+func TestGetSecretsMatchesStreamRegistries(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(&corev1.SecretList{
+		Items: []corev1.Secret{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "matching", Namespace: "default"},
+				Type:       corev1.SecretTypeDockercfg,
+				Data:       map[string][]byte{".dockercfg": []byte(`{"https://registry.host/v1/":{"auth": "Zm9vOmJhcgo=", "email": ""}}`)},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "other-registry", Namespace: "default"},
+				Type:       corev1.SecretTypeDockercfg,
+				Data:       map[string][]byte{".dockercfg": []byte(`{"https://index.docker.io/v1/":{"auth": "Zm9vOmJhcgo=", "email": ""}}`)},
+			},
+		},
+	})
+	streams := &fakeImageStreamRegistry{stream: &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Status: imageapi.ImageStreamStatus{
+			DockerImageRepository: "registry.host/default/test",
+		},
+	}}
+	sar := &fakeSubjectAccessReviewRegistry{allow: true}
+	rest := NewREST(fakeClient.CoreV1(), streams, sar)
+	opts, _, _ := rest.NewGetOptions()
+	obj, err := rest.Get(testContext(), "test", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := obj.(*coreapi.SecretList)
+	if len(list.Items) != 1 || list.Items[0].Name != "matching" {
+		t.Fatalf("expected only the matching secret, got %#v", list.Items)
+	}
+	if sar.request == nil {
+		t.Fatal("expected a subject access review to be performed")
+	}
+	if attrs := sar.request.Spec.ResourceAttributes; attrs == nil || attrs.Resource != "secrets" || attrs.Verb != "get" || attrs.Namespace != "default" {
+		t.Errorf("unexpected review attributes: %#v", sar.request.Spec.ResourceAttributes)
+	}
+}
+
+func TestGetSecretsForbiddenWithoutSecretAccess(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	rest := NewREST(fakeClient.CoreV1(), nil, &fakeSubjectAccessReviewRegistry{allow: false})
+	opts, _, _ := rest.NewGetOptions()
+	_, err := rest.Get(testContext(), "test", opts)
+	if err == nil || !kapierrors.IsForbidden(err) {
+		t.Fatalf("expected a forbidden error, got %v", err)
+	}
+}
+
+func testContext() context.Context {
+	return apirequest.WithUser(apirequest.NewDefaultContext(), &user.DefaultInfo{Name: "alice"})
+}
@@ -0,0 +1,149 @@
+package imagesignature
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/cosign"
+)
+
+// Claims under which the certificate chain and Rekor entry of a cosign
+// signature are surfaced. The external ImageSignature type has no structured
+// fields for them, so they travel in SignedClaims.
+const (
+	cosignCertificateClaim   = "cosign/certificate"
+	cosignChainClaim         = "cosign/chain"
+	rekorLogIDClaim          = "rekor/logID"
+	rekorLogIndexClaim       = "rekor/logIndex"
+	rekorIntegratedTimeClaim = "rekor/integratedTime"
+)
+
+// CosignVerifier evaluates a parsed cosign signature and reports conditions
+// describing the verification outcome, such as SignatureTrusted after the
+// certificate chain and Rekor entry have been checked against configured
+// roots of trust.
+type CosignVerifier func(signature *imageapi.ImageSignature, parsed *cosign.Signature, payload *cosign.Payload) []imageapi.SignatureCondition
+
+// RegisterCosignVerifier adds a verifier consulted whenever a cosign typed
+// signature is created. Without a verifier reporting a conclusive Trusted
+// condition the server stores the signature without derived metadata.
+func (s *strategy) RegisterCosignVerifier(verifier CosignVerifier) {
+	s.cosignVerifiers = append(s.cosignVerifiers, verifier)
+}
+
+// prepareCosignSignature fills the server managed metadata of a cosign
+// signature from its payload. Validation forbids derived metadata unless a
+// conclusive Trusted condition is present, so everything stays empty until a
+// registered verifier makes a trust decision.
+func (s *strategy) prepareCosignSignature(signature *imageapi.ImageSignature) {
+	parsed, payload, err := cosign.ParseSignature(signature.Content)
+	if err != nil {
+		// validation reports the parse error to the client
+		return
+	}
+
+	var conditions []imageapi.SignatureCondition
+	for _, verifier := range s.cosignVerifiers {
+		conditions = append(conditions, verifier(signature, parsed, payload)...)
+	}
+	if !hasConclusiveTrust(conditions) {
+		return
+	}
+
+	now := metav1.Now()
+	conditions = append(conditions, imageapi.SignatureCondition{
+		Type:               imageapi.SignatureForImage,
+		Status:             forImageStatus(signature.Name, payload),
+		LastProbeTime:      now,
+		LastTransitionTime: now,
+	})
+	signature.Conditions = conditions
+	signature.ImageIdentity = payload.Critical.Identity.DockerReference
+	signature.SignedClaims = cosignClaims(parsed, payload)
+	if bundle := parsed.RekorBundle; bundle != nil {
+		created := metav1.Unix(bundle.IntegratedTime, 0)
+		signature.Created = &created
+	}
+
+	cert, err := parsed.LeafCertificate()
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("unable to parse signing certificate of signature %q: %v", signature.Name, err))
+		return
+	}
+	if cert == nil {
+		return
+	}
+	signature.IssuedBy = &imageapi.SignatureIssuer{
+		SignatureGenericEntity: imageapi.SignatureGenericEntity{
+			Organization: firstString(cert.Issuer.Organization),
+			CommonName:   cert.Issuer.CommonName,
+		},
+	}
+	fingerprint := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	signature.IssuedTo = &imageapi.SignatureSubject{
+		SignatureGenericEntity: imageapi.SignatureGenericEntity{
+			Organization: firstString(cert.Subject.Organization),
+			CommonName:   cert.Subject.CommonName,
+		},
+		// the lowest 64 bits of the public key fingerprint, as the field doc
+		// asks for
+		PublicKeyID: fmt.Sprintf("0x%x", fingerprint[len(fingerprint)-8:]),
+	}
+}
+
+// hasConclusiveTrust reports whether the conditions carry a Trusted condition
+// with a definite answer.
+func hasConclusiveTrust(conditions []imageapi.SignatureCondition) bool {
+	for i := range conditions {
+		if conditions[i].Type == imageapi.SignatureTrusted && conditions[i].Status != kapi.ConditionUnknown {
+			return true
+		}
+	}
+	return false
+}
+
+// forImageStatus reports whether the payload digest matches the image the
+// signature is being attached to.
+func forImageStatus(signatureName string, payload *cosign.Payload) kapi.ConditionStatus {
+	imageName, _, err := splitImageSignatureName(signatureName)
+	if err != nil {
+		return kapi.ConditionFalse
+	}
+	if payload.Critical.Image.DockerManifestDigest == imageName {
+		return kapi.ConditionTrue
+	}
+	return kapi.ConditionFalse
+}
+
+// cosignClaims flattens the optional payload claims and adds the certificate
+// chain and Rekor entry of the signature.
+func cosignClaims(parsed *cosign.Signature, payload *cosign.Payload) map[string]string {
+	claims := map[string]string{}
+	for key, value := range payload.Optional {
+		claims[key] = fmt.Sprintf("%v", value)
+	}
+	if len(parsed.Certificate) > 0 {
+		claims[cosignCertificateClaim] = parsed.Certificate
+	}
+	if len(parsed.Chain) > 0 {
+		claims[cosignChainClaim] = parsed.Chain
+	}
+	if bundle := parsed.RekorBundle; bundle != nil {
+		claims[rekorLogIDClaim] = bundle.LogID
+		claims[rekorLogIndexClaim] = fmt.Sprintf("%d", bundle.LogIndex)
+		claims[rekorIntegratedTimeClaim] = fmt.Sprintf("%d", bundle.IntegratedTime)
+	}
+	return claims
+}
+
+func firstString(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
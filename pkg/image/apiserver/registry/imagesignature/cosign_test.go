@@ -0,0 +1,178 @@
+package imagesignature
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/cosign"
+	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation"
+)
+
+const testImageDigest = "sha256:0123456789abcdef"
+
+func cosignContent(t *testing.T, digest, certificate string) []byte {
+	payload := map[string]interface{}{
+		"critical": map[string]interface{}{
+			"identity": map[string]string{"docker-reference": "registry.example.com/app"},
+			"image":    map[string]string{"docker-manifest-digest": digest},
+			"type":     cosign.PayloadType,
+		},
+		"optional": map[string]interface{}{"creator": "ci"},
+	}
+	rawPayload, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	envelope := map[string]interface{}{
+		"payload":     base64.StdEncoding.EncodeToString(rawPayload),
+		"signature":   base64.StdEncoding.EncodeToString([]byte("opaque")),
+		"rekorBundle": map[string]interface{}{"logID": "abcd", "logIndex": 42, "integratedTime": 1700000000},
+	}
+	if len(certificate) > 0 {
+		envelope["certificate"] = certificate
+	}
+	content, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return content
+}
+
+func testCertificate(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName:   "builder@example.com",
+			Organization: []string{"Example"},
+		},
+		NotBefore: time.Now().Add(-time.Hour),
+		NotAfter:  time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(cryptorand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func cosignSignature(content []byte) *imageapi.ImageSignature {
+	return &imageapi.ImageSignature{
+		ObjectMeta: metav1.ObjectMeta{Name: testImageDigest + "@cosignsig"},
+		Type:       imageapi.ImageSignatureTypeCosign,
+		Content:    content,
+	}
+}
+
+func trustingVerifier(status kapi.ConditionStatus) CosignVerifier {
+	return func(signature *imageapi.ImageSignature, parsed *cosign.Signature, payload *cosign.Payload) []imageapi.SignatureCondition {
+		now := metav1.Now()
+		return []imageapi.SignatureCondition{{
+			Type:               imageapi.SignatureTrusted,
+			Status:             status,
+			LastProbeTime:      now,
+			LastTransitionTime: now,
+		}}
+	}
+}
+
+func conditionStatus(signature *imageapi.ImageSignature, conditionType imageapi.SignatureConditionType) (kapi.ConditionStatus, bool) {
+	for i := range signature.Conditions {
+		if signature.Conditions[i].Type == conditionType {
+			return signature.Conditions[i].Status, true
+		}
+	}
+	return "", false
+}
+
+func TestPrepareCosignSignatureWithoutVerifier(t *testing.T) {
+	s := &strategy{ObjectTyper: legacyscheme.Scheme}
+
+	signature := cosignSignature(cosignContent(t, testImageDigest, ""))
+	s.PrepareForCreate(context.TODO(), signature)
+
+	if len(signature.Conditions) != 0 || len(signature.SignedClaims) != 0 || len(signature.ImageIdentity) != 0 {
+		t.Errorf("expected no derived metadata without a trust decision, got %#v", signature)
+	}
+	if errs := validation.ValidateImageSignature(signature); len(errs) != 0 {
+		t.Errorf("unexpected validation errors: %v", errs)
+	}
+}
+
+func TestPrepareCosignSignatureTrusted(t *testing.T) {
+	s := &strategy{ObjectTyper: legacyscheme.Scheme}
+	s.RegisterCosignVerifier(trustingVerifier(kapi.ConditionTrue))
+
+	signature := cosignSignature(cosignContent(t, testImageDigest, testCertificate(t)))
+	s.PrepareForCreate(context.TODO(), signature)
+
+	if status, ok := conditionStatus(signature, imageapi.SignatureTrusted); !ok || status != kapi.ConditionTrue {
+		t.Errorf("expected a true Trusted condition, got %#v", signature.Conditions)
+	}
+	if status, ok := conditionStatus(signature, imageapi.SignatureForImage); !ok || status != kapi.ConditionTrue {
+		t.Errorf("expected a true ForImage condition, got %#v", signature.Conditions)
+	}
+	if signature.ImageIdentity != "registry.example.com/app" {
+		t.Errorf("unexpected image identity %q", signature.ImageIdentity)
+	}
+	for _, claim := range []string{"creator", cosignCertificateClaim, rekorLogIDClaim, rekorLogIndexClaim, rekorIntegratedTimeClaim} {
+		if _, ok := signature.SignedClaims[claim]; !ok {
+			t.Errorf("expected claim %q, got %v", claim, signature.SignedClaims)
+		}
+	}
+	if signature.Created == nil || signature.Created.Unix() != 1700000000 {
+		t.Errorf("expected creation time from the rekor bundle, got %v", signature.Created)
+	}
+	if signature.IssuedTo == nil || signature.IssuedTo.CommonName != "builder@example.com" || len(signature.IssuedTo.PublicKeyID) == 0 {
+		t.Errorf("unexpected subject %#v", signature.IssuedTo)
+	}
+	if signature.IssuedBy == nil || signature.IssuedBy.CommonName != "builder@example.com" {
+		t.Errorf("unexpected issuer %#v", signature.IssuedBy)
+	}
+	if errs := validation.ValidateImageSignature(signature); len(errs) != 0 {
+		t.Errorf("unexpected validation errors: %v", errs)
+	}
+}
+
+func TestPrepareCosignSignatureDigestMismatch(t *testing.T) {
+	s := &strategy{ObjectTyper: legacyscheme.Scheme}
+	s.RegisterCosignVerifier(trustingVerifier(kapi.ConditionTrue))
+
+	signature := cosignSignature(cosignContent(t, "sha256:anotherimage", ""))
+	s.PrepareForCreate(context.TODO(), signature)
+
+	if status, ok := conditionStatus(signature, imageapi.SignatureForImage); !ok || status != kapi.ConditionFalse {
+		t.Errorf("expected a false ForImage condition, got %#v", signature.Conditions)
+	}
+}
+
+func TestValidateCosignSignatureContent(t *testing.T) {
+	signature := cosignSignature([]byte("opaque blob"))
+	if errs := validation.ValidateImageSignature(signature); len(errs) == 0 {
+		t.Errorf("expected validation to reject a cosign signature with unparseable content")
+	}
+
+	// the atomic type keeps treating content as opaque
+	signature.Type = imageapi.ImageSignatureTypeAtomicImageV1
+	if errs := validation.ValidateImageSignature(signature); len(errs) != 0 {
+		t.Errorf("unexpected validation errors: %v", errs)
+	}
+}
@@ -14,6 +14,10 @@ import (
 // strategy implements behavior for ImageStreamTags.
 type strategy struct {
 	runtime.ObjectTyper
+
+	// cosignVerifiers are consulted for cosign typed signatures; see
+	// RegisterCosignVerifier
+	cosignVerifiers []CosignVerifier
 }
 
 var Strategy = &strategy{
@@ -33,6 +37,10 @@ func (s *strategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
 	signature.Created = nil
 	signature.IssuedBy = nil
 	signature.IssuedTo = nil
+
+	if signature.Type == imageapi.ImageSignatureTypeCosign {
+		s.prepareCosignSignature(signature)
+	}
 }
 
 func (s *strategy) GenerateName(base string) string {
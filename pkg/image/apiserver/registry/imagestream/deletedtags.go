@@ -0,0 +1,118 @@
+package imagestream
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+// TagDeleteIntentAnnotation records tags deleted from an image stream along
+// with the stream generation observed when the delete was applied, encoded as
+// "tag=generation[,tag=generation...]".  Status writers that raced with the
+// delete (typically scheduled imports that read the stream before the delete
+// committed) are prevented from resurrecting those tags: a status update may
+// only re-add an intent-listed tag with events from a later generation.  The
+// intent for a tag is cleared when the tag is deliberately re-created in the
+// spec or when a newer import supersedes it.
+const TagDeleteIntentAnnotation = "image.openshift.io/tag-delete-intent"
+
+// SetTagDeleteIntent records on stream that tag was deleted at the given
+// generation.
+func SetTagDeleteIntent(stream *imageapi.ImageStream, tag string, generation int64) {
+	intents := parseTagDeleteIntents(stream)
+	intents[tag] = generation
+	setTagDeleteIntents(stream, intents)
+}
+
+// parseTagDeleteIntents returns the delete intents recorded on stream.
+// Malformed entries are dropped.
+func parseTagDeleteIntents(stream *imageapi.ImageStream) map[string]int64 {
+	intents := make(map[string]int64)
+	for _, entry := range strings.Split(stream.Annotations[TagDeleteIntentAnnotation], ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		generation, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		intents[parts[0]] = generation
+	}
+	return intents
+}
+
+// setTagDeleteIntents replaces the delete intents recorded on stream,
+// removing the annotation entirely when none remain.
+func setTagDeleteIntents(stream *imageapi.ImageStream, intents map[string]int64) {
+	if len(intents) == 0 {
+		delete(stream.Annotations, TagDeleteIntentAnnotation)
+		return
+	}
+	entries := make([]string, 0, len(intents))
+	for tag, generation := range intents {
+		entries = append(entries, fmt.Sprintf("%s=%d", tag, generation))
+	}
+	sort.Strings(entries)
+	if stream.Annotations == nil {
+		stream.Annotations = make(map[string]string)
+	}
+	stream.Annotations[TagDeleteIntentAnnotation] = strings.Join(entries, ",")
+}
+
+// enforceTagDeleteIntents applies the delete intents recorded on oldStream to
+// an incoming update of stream.  Status tags re-added with history no newer
+// than the recorded generation were written by a client that read the stream
+// before the delete committed, and are removed again.  An intent is released
+// once a tag has events from a later generation (a genuinely newer import).
+// The annotation is carried over from oldStream so status writers cannot drop
+// it accidentally.
+func enforceTagDeleteIntents(stream, oldStream *imageapi.ImageStream) {
+	intents := parseTagDeleteIntents(oldStream)
+	if len(intents) == 0 {
+		return
+	}
+	remaining := make(map[string]int64)
+	for tag, generation := range intents {
+		// an explicit spec entry means the tag was deliberately re-created
+		if _, ok := stream.Spec.Tags[tag]; ok {
+			continue
+		}
+		if latestTagGeneration(stream, tag) > generation {
+			continue
+		}
+		delete(stream.Status.Tags, tag)
+		remaining[tag] = generation
+	}
+	setTagDeleteIntents(stream, remaining)
+}
+
+// releaseTagDeleteIntentsForSpecTags clears delete intents for tags the user
+// has deliberately re-created in the stream spec.
+func releaseTagDeleteIntentsForSpecTags(stream *imageapi.ImageStream) {
+	intents := parseTagDeleteIntents(stream)
+	if len(intents) == 0 {
+		return
+	}
+	for tag := range intents {
+		if _, ok := stream.Spec.Tags[tag]; ok {
+			delete(intents, tag)
+		}
+	}
+	setTagDeleteIntents(stream, intents)
+}
+
+// latestTagGeneration returns the newest event generation recorded for tag in
+// stream's status, or 0 if the tag has no events.
+func latestTagGeneration(stream *imageapi.ImageStream, tag string) int64 {
+	var latest int64
+	for _, event := range stream.Status.Tags[tag].Items {
+		if event.Generation > latest {
+			latest = event.Generation
+		}
+	}
+	return latest
+}
@@ -0,0 +1,97 @@
+package imagestream
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+func streamWithIntent(intent string) *imageapi.ImageStream {
+	stream := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+	}
+	if len(intent) > 0 {
+		stream.Annotations = map[string]string{TagDeleteIntentAnnotation: intent}
+	}
+	return stream
+}
+
+func withStatusTag(stream *imageapi.ImageStream, tag string, generations ...int64) *imageapi.ImageStream {
+	if stream.Status.Tags == nil {
+		stream.Status.Tags = make(map[string]imageapi.TagEventList)
+	}
+	events := imageapi.TagEventList{}
+	for _, generation := range generations {
+		events.Items = append(events.Items, imageapi.TagEvent{Image: "sha256:123", Generation: generation})
+	}
+	stream.Status.Tags[tag] = events
+	return stream
+}
+
+func TestSetTagDeleteIntent(t *testing.T) {
+	stream := streamWithIntent("")
+	SetTagDeleteIntent(stream, "latest", 3)
+	SetTagDeleteIntent(stream, "v1", 5)
+	if expected := "latest=3,v1=5"; stream.Annotations[TagDeleteIntentAnnotation] != expected {
+		t.Errorf("expected annotation %q, got %q", expected, stream.Annotations[TagDeleteIntentAnnotation])
+	}
+}
+
+func TestEnforceTagDeleteIntents(t *testing.T) {
+	tests := []struct {
+		name             string
+		old              *imageapi.ImageStream
+		stream           *imageapi.ImageStream
+		expectTagPresent bool
+		expectedIntent   string
+	}{
+		{
+			name:             "no intent leaves tags alone",
+			old:              streamWithIntent(""),
+			stream:           withStatusTag(streamWithIntent(""), "latest", 2),
+			expectTagPresent: true,
+		},
+		{
+			name:           "stale resurrect is removed",
+			old:            streamWithIntent("latest=3"),
+			stream:         withStatusTag(streamWithIntent(""), "latest", 3),
+			expectedIntent: "latest=3",
+		},
+		{
+			name:             "newer import releases intent",
+			old:              streamWithIntent("latest=3"),
+			stream:           withStatusTag(streamWithIntent(""), "latest", 4),
+			expectTagPresent: true,
+		},
+		{
+			name: "explicit spec tag releases intent",
+			old:  streamWithIntent("latest=3"),
+			stream: func() *imageapi.ImageStream {
+				stream := withStatusTag(streamWithIntent(""), "latest", 3)
+				stream.Spec.Tags = map[string]imageapi.TagReference{"latest": {}}
+				return stream
+			}(),
+			expectTagPresent: true,
+		},
+		{
+			name:           "intent is carried over while tag stays deleted",
+			old:            streamWithIntent("latest=3"),
+			stream:         streamWithIntent(""),
+			expectedIntent: "latest=3",
+		},
+	}
+
+	for _, test := range tests {
+		enforceTagDeleteIntents(test.stream, test.old)
+
+		_, present := test.stream.Status.Tags["latest"]
+		if present != test.expectTagPresent {
+			t.Errorf("%s: expected tag present=%t, got %t", test.name, test.expectTagPresent, present)
+		}
+		if intent := test.stream.Annotations[TagDeleteIntentAnnotation]; intent != test.expectedIntent {
+			t.Errorf("%s: expected intent %q, got %q", test.name, test.expectedIntent, intent)
+		}
+	}
+}
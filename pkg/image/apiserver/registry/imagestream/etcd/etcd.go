@@ -2,13 +2,17 @@ package etcd
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/docker/distribution/manifest/schema2"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"k8s.io/klog/v2"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/generic"
 	"k8s.io/apiserver/pkg/registry/generic/registry"
 	"k8s.io/apiserver/pkg/registry/rest"
@@ -19,6 +23,7 @@ import (
 	printerstorage "k8s.io/kubernetes/pkg/printers/storage"
 
 	"github.com/openshift/api/image"
+	imagev1 "github.com/openshift/api/image/v1"
 	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
 	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/whitelist"
 	imageadmission "github.com/openshift/openshift-apiserver/pkg/image/apiserver/admission/limitrange"
@@ -30,6 +35,10 @@ import (
 // REST implements a RESTStorage for image streams against etcd.
 type REST struct {
 	*registry.Store
+	// internalStore writes both spec and status; renames go through it so the
+	// copied stream keeps its tag history.
+	internalStore *registry.Store
+	sarClient     authorizationclient.SubjectAccessReviewInterface
 }
 
 var _ rest.StandardStorage = &REST{}
@@ -53,6 +62,7 @@ func NewREST(
 	limitRangeInformer corev1informers.LimitRangeInformer,
 	registryWhitelister whitelist.RegistryWhitelister,
 	imageLayerIndex ImageLayerIndex,
+	maxTagHistory int,
 ) (*REST, *LayersREST, *StatusREST, *InternalREST, error) {
 	return NewRESTWithLimitVerifier(
 		optsGetter,
@@ -61,6 +71,7 @@ func NewREST(
 		ImageLimitVerifier(limitRangeInformer),
 		registryWhitelister,
 		imageLayerIndex,
+		maxTagHistory,
 	)
 }
 
@@ -72,6 +83,7 @@ func NewRESTWithLimitVerifier(
 	limitVerifier imageadmission.LimitVerifier,
 	registryWhitelister whitelist.RegistryWhitelister,
 	imageLayerIndex ImageLayerIndex,
+	maxTagHistory int,
 ) (*REST, *LayersREST, *StatusREST, *InternalREST, error) {
 	store := registry.Store{
 		NewFunc:                  func() runtime.Object { return &imageapi.ImageStream{} },
@@ -82,10 +94,11 @@ func NewRESTWithLimitVerifier(
 	}
 
 	rest := &REST{
-		Store: &store,
+		Store:     &store,
+		sarClient: subjectAccessReviewRegistry,
 	}
 	// strategy must be able to load image streams across namespaces during tag verification
-	strategy := imagestream.NewStrategy(registryHostname, subjectAccessReviewRegistry, limitVerifier, registryWhitelister, rest)
+	strategy := imagestream.NewStrategy(registryHostname, subjectAccessReviewRegistry, limitVerifier, registryWhitelister, rest, maxTagHistory)
 
 	store.CreateStrategy = strategy
 	store.UpdateStrategy = strategy
@@ -116,9 +129,62 @@ func NewRESTWithLimitVerifier(
 	internalStore.UpdateStrategy = internalStrategy
 
 	internalREST := &InternalREST{store: &internalStore}
+	rest.internalStore = &internalStore
 	return rest, layersREST, statusREST, internalREST, nil
 }
 
+// Update diverts an update carrying the rename annotation into a rename and
+// applies every other update unchanged.
+func (r *REST) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	oldObj, err := r.Store.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return r.Store.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+	}
+	newObj, err := objInfo.UpdatedObject(ctx, oldObj)
+	if err != nil {
+		return nil, false, err
+	}
+	stream, ok := newObj.(*imageapi.ImageStream)
+	if !ok {
+		return r.Store.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+	}
+	target := imagestream.RenameTarget(stream)
+	if len(target) == 0 {
+		return r.Store.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+	}
+	return r.rename(ctx, stream, target, createValidation, options)
+}
+
+// rename copies stream under the target name with its history and
+// self-references rewritten, then turns the old stream into a tombstone alias
+// so references that still use the old name keep resolving during the grace
+// period.  The caller must be allowed to both delete the old stream and
+// create the new one.
+func (r *REST) rename(ctx context.Context, stream *imageapi.ImageStream, target string, createValidation rest.ValidateObjectFunc, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	user, ok := apirequest.UserFrom(ctx)
+	if !ok || !imagestream.CanRename(ctx, r.sarClient, user, stream.Namespace, stream.Name, target) {
+		return nil, false, errors.NewForbidden(image.Resource("imagestreams"), stream.Name,
+			fmt.Errorf("renaming an image stream requires permission to delete %q and create %q", stream.Name, target))
+	}
+	if imagestream.IsRenameTombstone(stream) {
+		return nil, false, errors.NewBadRequest(fmt.Sprintf("image stream %q is a tombstone alias for %q and cannot be renamed", stream.Name, imagestream.RenameTombstoneTarget(stream)))
+	}
+
+	renamed := imagestream.RenamedImageStream(stream, target)
+	createdObj, err := r.internalStore.Create(ctx, renamed, createValidation, &metav1.CreateOptions{DryRun: options.DryRun})
+	if err != nil {
+		return nil, false, err
+	}
+
+	tombstone := stream.DeepCopy()
+	imagestream.MakeRenameTombstone(tombstone, target, time.Now())
+	if _, _, err := r.internalStore.Update(ctx, stream.Name, rest.DefaultUpdatedObjectInfo(tombstone), nil, nil, false, options); err != nil {
+		klog.Errorf("Image stream %s/%s was copied to %q but the old name could not be turned into a tombstone alias: %v", stream.Namespace, stream.Name, target, err)
+		return nil, false, err
+	}
+	return createdObj, true, nil
+}
+
 // StatusREST implements the REST endpoint for changing the status of an image stream.
 type StatusREST struct {
 	store *registry.Store
@@ -211,61 +277,124 @@ func (r *LayersREST) Get(ctx context.Context, name string, options *metav1.GetOp
 }
 
 // addImageStreamLayersFromCache looks up tagged images from the provided image stream in the cache and then adds
-// metadata about those images and their referenced blobs to isl. It returns the names of missing images from the
-// cache.
+// metadata about those images and their referenced blobs to isl. The platform manifests of a tagged manifest list
+// are resolved as well so each of their blobs records which manifest contributed it. It returns the names of
+// missing images from the cache.
 func addImageStreamLayersFromCache(isl *imageapi.ImageStreamLayers, is *imageapi.ImageStream, index ImageLayerIndex) []string {
 	var missing []string
-	for _, status := range is.Status.Tags {
-		for _, item := range status.Items {
-			if len(item.Image) == 0 {
-				continue
+	// manifest is empty for directly tagged images and the digest of the platform
+	// manifest when the image is a member of a tagged manifest list
+	var addImage func(name, manifest string)
+	addImage = func(name, manifest string) {
+		obj, _, _ := index.GetByKey(name)
+		entry, ok := obj.(*ImageLayers)
+		if !ok {
+			if _, ok := isl.Images[name]; !ok {
+				isl.Images[name] = imageapi.ImageBlobReferences{ImageMissing: true}
 			}
+			missing = append(missing, name)
+			return
+		}
+
+		// we have already added this image once
+		if _, ok := isl.Images[name]; ok {
+			return
+		}
 
-			obj, _, _ := index.GetByKey(item.Image)
-			entry, ok := obj.(*ImageLayers)
-			if !ok {
-				if _, ok := isl.Images[item.Image]; !ok {
-					isl.Images[item.Image] = imageapi.ImageBlobReferences{ImageMissing: true}
+		var reference imageapi.ImageBlobReferences
+		for i, layer := range entry.Layers {
+			reference.Layers = append(reference.Layers, layer.Name)
+			if _, ok := isl.Blobs[layer.Name]; !ok {
+				size := layer.LayerSize
+				isl.Blobs[layer.Name] = imageapi.ImageLayerData{
+					LayerSize:        &size,
+					MediaType:        layer.MediaType,
+					UncompressedSize: uncompressedLayerSize(layer, diffIDForLayer(entry, i)),
 				}
-				missing = append(missing, item.Image)
-				continue
 			}
-
-			// we have already added this image once
-			if _, ok := isl.Images[item.Image]; ok {
-				continue
+			if len(manifest) > 0 {
+				addBlobManifest(isl, layer.Name, manifest)
 			}
+		}
 
-			var reference imageapi.ImageBlobReferences
-			for _, layer := range entry.Layers {
-				reference.Layers = append(reference.Layers, layer.Name)
-				if _, ok := isl.Blobs[layer.Name]; !ok {
-					isl.Blobs[layer.Name] = imageapi.ImageLayerData{LayerSize: &layer.LayerSize, MediaType: layer.MediaType}
+		if blob := entry.Config; blob != nil {
+			reference.Config = &blob.Name
+			if _, ok := isl.Blobs[blob.Name]; !ok {
+				if blob.LayerSize == 0 {
+					// only send media type since we don't the size of the manifest
+					isl.Blobs[blob.Name] = imageapi.ImageLayerData{MediaType: blob.MediaType}
+				} else {
+					isl.Blobs[blob.Name] = imageapi.ImageLayerData{LayerSize: &blob.LayerSize, MediaType: blob.MediaType}
 				}
 			}
-
-			if blob := entry.Config; blob != nil {
-				reference.Config = &blob.Name
-				if _, ok := isl.Blobs[blob.Name]; !ok {
-					if blob.LayerSize == 0 {
-						// only send media type since we don't the size of the manifest
-						isl.Blobs[blob.Name] = imageapi.ImageLayerData{MediaType: blob.MediaType}
-					} else {
-						isl.Blobs[blob.Name] = imageapi.ImageLayerData{LayerSize: &blob.LayerSize, MediaType: blob.MediaType}
-					}
-				}
+			if len(manifest) > 0 {
+				addBlobManifest(isl, blob.Name, manifest)
 			}
+		}
+
+		// the image manifest is always a blob - schema2 images also have a config blob referenced from the manifest
+		if _, ok := isl.Blobs[name]; !ok {
+			isl.Blobs[name] = imageapi.ImageLayerData{MediaType: entry.MediaType}
+		}
+		isl.Images[name] = reference
 
-			// the image manifest is always a blob - schema2 images also have a config blob referenced from the manifest
-			if _, ok := isl.Blobs[item.Image]; !ok {
-				isl.Blobs[item.Image] = imageapi.ImageLayerData{MediaType: entry.MediaType}
+		for _, digest := range entry.SubManifests {
+			addImage(digest, digest)
+		}
+	}
+
+	for _, status := range is.Status.Tags {
+		for _, item := range status.Items {
+			if len(item.Image) == 0 {
+				continue
 			}
-			isl.Images[item.Image] = reference
+			addImage(item.Image, "")
 		}
 	}
 	return missing
 }
 
+// addBlobManifest records manifest as a contributor of the named blob.
+func addBlobManifest(isl *imageapi.ImageStreamLayers, name, manifest string) {
+	blob := isl.Blobs[name]
+	for _, existing := range blob.Manifests {
+		if existing == manifest {
+			return
+		}
+	}
+	blob.Manifests = append(blob.Manifests, manifest)
+	isl.Blobs[name] = blob
+}
+
+// diffIDForLayer returns the config diff_id matching the i-th layer of entry,
+// or the empty string when the config does not describe it.
+func diffIDForLayer(entry *ImageLayers, i int) string {
+	if i < len(entry.DiffIDs) {
+		return entry.DiffIDs[i]
+	}
+	return ""
+}
+
+// uncompressedLayerSize returns the size of layer after decompression when it
+// can be determined. The image config records the digests of the uncompressed
+// layers rather than their sizes, so the size is only known when the stored
+// blob is the uncompressed tar itself: either its media type carries no
+// compression or its digest matches the config diff_id.
+func uncompressedLayerSize(layer imagev1.ImageLayer, diffID string) *int64 {
+	uncompressed := false
+	switch layer.MediaType {
+	case imgspecv1.MediaTypeImageLayer, imgspecv1.MediaTypeImageLayerNonDistributable, schema2.MediaTypeUncompressedLayer:
+		uncompressed = true
+	default:
+		uncompressed = len(diffID) > 0 && diffID == layer.Name
+	}
+	if !uncompressed {
+		return nil
+	}
+	size := layer.LayerSize
+	return &size
+}
+
 // LegacyREST allows us to wrap and alter some behavior
 type LegacyREST struct {
 	*REST
@@ -69,6 +69,7 @@ func newStorage(t *testing.T) (*REST, *StatusREST, *InternalREST, *etcdtesting.E
 		&admfake.ImageStreamLimitVerifier{},
 		&fake.RegistryWhitelister{},
 		NewEmptyLayerIndex(),
+		0,
 	)
 	if err != nil {
 		t.Fatal(err)
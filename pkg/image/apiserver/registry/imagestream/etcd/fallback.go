@@ -0,0 +1,140 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imagev1typedclient "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/readfallback"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+// streamReader is the subset of the store the fallback delegates reads to,
+// narrowed so tests can supply a fake.
+type streamReader interface {
+	Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error)
+	List(ctx context.Context, options *metainternal.ListOptions) (runtime.Object, error)
+}
+
+// FallbackREST wraps the image stream store so GETs and LISTs survive an etcd
+// outage: when the store reports the backend unavailable, the answer is
+// served from a watch cache instead, with a Warning header flagging it as
+// possibly stale. Image pulls through the integrated registry resolve
+// pullthrough metadata from image streams, so keeping stream reads alive
+// matters more than strict freshness. Writes and watches pass through
+// untouched.
+type FallbackREST struct {
+	*REST
+	reader   streamReader
+	informer cache.SharedIndexInformer
+}
+
+// NewFallbackREST wraps the store with a cache fed from the given client.
+// Run must be started for the cache to populate.
+func NewFallbackREST(store *REST, client imagev1typedclient.ImageStreamsGetter) *FallbackREST {
+	informer := cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.ImageStreams(metav1.NamespaceAll).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.ImageStreams(metav1.NamespaceAll).Watch(context.TODO(), options)
+			},
+		},
+		&imagev1.ImageStream{},
+		0,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+	return &FallbackREST{REST: store, reader: store, informer: informer}
+}
+
+// Run starts the backing cache and blocks until the stop channel closes.
+func (r *FallbackREST) Run(stopCh <-chan struct{}) {
+	r.informer.Run(stopCh)
+}
+
+func (r *FallbackREST) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	obj, err := r.reader.Get(ctx, name, options)
+	if err == nil || !readfallback.ShouldFallback(err) {
+		return obj, err
+	}
+	namespace := apirequest.NamespaceValue(ctx)
+	item, exists, cacheErr := r.informer.GetStore().GetByKey(namespace + "/" + name)
+	if cacheErr != nil || !exists {
+		return nil, err
+	}
+	external, ok := item.(*imagev1.ImageStream)
+	if !ok {
+		return nil, err
+	}
+	internal, convErr := internalImageStream(external)
+	if convErr != nil {
+		return nil, err
+	}
+	readfallback.AddStaleReadWarning(ctx, "imagestreams")
+	return internal, nil
+}
+
+func (r *FallbackREST) List(ctx context.Context, options *metainternal.ListOptions) (runtime.Object, error) {
+	obj, err := r.reader.List(ctx, options)
+	if err == nil || !readfallback.ShouldFallback(err) {
+		return obj, err
+	}
+
+	labelSelector := labels.Everything()
+	if options != nil && options.LabelSelector != nil {
+		labelSelector = options.LabelSelector
+	}
+	var items []interface{}
+	if namespace := apirequest.NamespaceValue(ctx); len(namespace) > 0 {
+		byNamespace, indexErr := r.informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+		if indexErr != nil {
+			return nil, err
+		}
+		items = byNamespace
+	} else {
+		items = r.informer.GetStore().List()
+	}
+
+	list := &imageapi.ImageStreamList{}
+	for _, item := range items {
+		external, ok := item.(*imagev1.ImageStream)
+		if !ok {
+			continue
+		}
+		if !labelSelector.Matches(labels.Set(external.Labels)) {
+			continue
+		}
+		internal, convErr := internalImageStream(external)
+		if convErr != nil {
+			return nil, err
+		}
+		list.Items = append(list.Items, *internal)
+	}
+	readfallback.AddStaleReadWarning(ctx, "imagestreams")
+	return list, nil
+}
+
+// internalImageStream converts a cached external image stream to the internal
+// form. The input is copied first so the response cannot alias cache state.
+func internalImageStream(external *imagev1.ImageStream) (*imageapi.ImageStream, error) {
+	converted, err := legacyscheme.Scheme.ConvertToVersion(external.DeepCopy(), imageapi.SchemeGroupVersion)
+	if err != nil {
+		return nil, err
+	}
+	internal, ok := converted.(*imageapi.ImageStream)
+	if !ok {
+		return nil, fmt.Errorf("unexpected conversion result %T", converted)
+	}
+	return internal, nil
+}
@@ -0,0 +1,116 @@
+package etcd
+
+import (
+	"context"
+	"testing"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imageclientfake "github.com/openshift/client-go/image/clientset/versioned/fake"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+
+	_ "github.com/openshift/openshift-apiserver/pkg/api/install"
+)
+
+type fakeStreamReader struct {
+	err error
+}
+
+func (f *fakeStreamReader) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	return nil, f.err
+}
+
+func (f *fakeStreamReader) List(ctx context.Context, options *metainternal.ListOptions) (runtime.Object, error) {
+	return nil, f.err
+}
+
+func newTestFallbackREST(t *testing.T, err error, streams ...*imagev1.ImageStream) *FallbackREST {
+	fallback := NewFallbackREST(nil, imageclientfake.NewSimpleClientset().ImageV1())
+	fallback.reader = &fakeStreamReader{err: err}
+	for _, stream := range streams {
+		if addErr := fallback.informer.GetStore().Add(stream); addErr != nil {
+			t.Fatalf("unexpected error priming the cache: %v", addErr)
+		}
+	}
+	return fallback
+}
+
+func unavailableError() error {
+	return kapierrors.NewServerTimeout(imageapi.Resource("imagestreams"), "get", 1)
+}
+
+func TestFallbackGetServesFromCacheOnStorageError(t *testing.T) {
+	fallback := newTestFallbackREST(t, unavailableError(), &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+	})
+
+	obj, err := fallback.Get(apirequest.NewDefaultContext(), "test", &metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stream, ok := obj.(*imageapi.ImageStream)
+	if !ok {
+		t.Fatalf("expected an internal image stream, got %T", obj)
+	}
+	if stream.Name != "test" || stream.Namespace != "default" {
+		t.Errorf("unexpected stream: %#v", stream.ObjectMeta)
+	}
+}
+
+func TestFallbackGetCacheMissReturnsStorageError(t *testing.T) {
+	fallback := newTestFallbackREST(t, unavailableError())
+
+	if _, err := fallback.Get(apirequest.NewDefaultContext(), "test", &metav1.GetOptions{}); !kapierrors.IsServerTimeout(err) {
+		t.Errorf("expected the storage error, got %v", err)
+	}
+}
+
+func TestFallbackGetDoesNotMaskDefinitiveErrors(t *testing.T) {
+	notFound := kapierrors.NewNotFound(imageapi.Resource("imagestreams"), "test")
+	fallback := newTestFallbackREST(t, notFound, &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+	})
+
+	if _, err := fallback.Get(apirequest.NewDefaultContext(), "test", &metav1.GetOptions{}); !kapierrors.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestFallbackListServesFromCacheOnStorageError(t *testing.T) {
+	fallback := newTestFallbackREST(t, unavailableError(),
+		&imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "one", Labels: map[string]string{"app": "a"}}},
+		&imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "two", Labels: map[string]string{"app": "b"}}},
+		&imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "three"}},
+	)
+
+	obj, err := fallback.List(apirequest.NewDefaultContext(), &metainternal.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list, ok := obj.(*imageapi.ImageStreamList)
+	if !ok {
+		t.Fatalf("expected an internal image stream list, got %T", obj)
+	}
+	if len(list.Items) != 2 {
+		t.Fatalf("expected the two streams in the request namespace, got %#v", list.Items)
+	}
+
+	selector, err := labels.Parse("app=a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj, err = fallback.List(apirequest.NewDefaultContext(), &metainternal.ListOptions{LabelSelector: selector})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list = obj.(*imageapi.ImageStreamList)
+	if len(list.Items) != 1 || list.Items[0].Name != "one" {
+		t.Errorf("expected only the labeled stream, got %#v", list.Items)
+	}
+}
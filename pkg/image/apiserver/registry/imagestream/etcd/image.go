@@ -2,7 +2,9 @@ package etcd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/docker/distribution/manifest/schema2"
 	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
@@ -148,6 +150,12 @@ func configFromImage(image *imagev1.Image) *imagev1.ImageLayer {
 	}
 }
 
+// subManifestsAnnotation mirrors the annotation written by the image stream
+// mapping registry onto a manifest list image, recording the digests of its
+// platform manifests separated by commas. The constant is duplicated here
+// because the mapping registry imports this package's consumers.
+const subManifestsAnnotation = "image.openshift.io/sub-manifests"
+
 // ImageLayers is the minimal set of data we need to retain to provide the cache.
 // Unlike a more general informer cache, we do not retain the full object because of
 // the potential size of the objects being stored. Even a small cluster may have 20k
@@ -158,6 +166,13 @@ type ImageLayers struct {
 	MediaType       string
 	Config          *imagev1.ImageLayer
 	Layers          []imagev1.ImageLayer
+	// DiffIDs are the digests of the uncompressed layers from the image config,
+	// in the same order as Layers. Empty when the image has no config blob.
+	DiffIDs []string
+	// SubManifests are the digests of the platform manifests of a manifest
+	// list, recorded so the layers subresource can attribute blobs to the
+	// manifests that contributed them.
+	SubManifests []string
 }
 
 func imageLayersForImage(image *imagev1.Image) *ImageLayers {
@@ -165,15 +180,40 @@ func imageLayersForImage(image *imagev1.Image) *ImageLayers {
 	if len(mediaType) == 0 {
 		mediaType = schema2.MediaTypeManifest
 	}
+	var subManifests []string
+	if value := image.Annotations[subManifestsAnnotation]; len(value) > 0 {
+		subManifests = strings.Split(value, ",")
+	}
 	return &ImageLayers{
 		Name:            image.Name,
 		ResourceVersion: image.ResourceVersion,
 		MediaType:       mediaType,
 		Config:          configFromImage(image),
 		Layers:          image.DockerImageLayers,
+		DiffIDs:         diffIDsFromImage(image),
+		SubManifests:    subManifests,
 	}
 }
 
+// diffIDsFromImage returns the digests of the uncompressed layers recorded in
+// the image config, ordered base layer first to match DockerImageLayers.
+// Images without a config blob have no diff_ids.
+func diffIDsFromImage(image *imagev1.Image) []string {
+	if len(image.DockerImageConfig) == 0 {
+		return nil
+	}
+	var config struct {
+		RootFS struct {
+			DiffIDs []string `json:"diff_ids"`
+		} `json:"rootfs"`
+	}
+	if err := json.Unmarshal([]byte(image.DockerImageConfig), &config); err != nil {
+		utilruntime.HandleError(fmt.Errorf("Unable to decode image config for layer cache: %v", err))
+		return nil
+	}
+	return config.RootFS.DiffIDs
+}
+
 var (
 	_ runtime.Object = &ImageLayers{}
 	_ metav1.Object  = &ImageLayers{}
@@ -191,12 +231,24 @@ func (l *ImageLayers) DeepCopyObject() runtime.Object {
 		copied := *l.Config
 		config = &copied
 	}
+	var diffIDs []string
+	if l.DiffIDs != nil {
+		diffIDs = make([]string, len(l.DiffIDs))
+		copy(diffIDs, l.DiffIDs)
+	}
+	var subManifests []string
+	if l.SubManifests != nil {
+		subManifests = make([]string, len(l.SubManifests))
+		copy(subManifests, l.SubManifests)
+	}
 	return &ImageLayers{
 		Name:            l.Name,
 		ResourceVersion: l.ResourceVersion,
 		MediaType:       l.MediaType,
 		Config:          config,
 		Layers:          layers,
+		DiffIDs:         diffIDs,
+		SubManifests:    subManifests,
 	}
 }
 
@@ -0,0 +1,125 @@
+package imagestream
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+func frozenStream(generation int64) *imageapi.ImageStream {
+	one := generation
+	return &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "release",
+			Annotations: map[string]string{FrozenAnnotation: "true"},
+		},
+		Spec: imageapi.ImageStreamSpec{
+			Tags: map[string]imageapi.TagReference{
+				"latest": {
+					From:       &kapi.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/release:latest"},
+					Generation: &one,
+				},
+			},
+		},
+	}
+}
+
+func TestValidateFrozen(t *testing.T) {
+	tests := []struct {
+		name          string
+		frozen        bool
+		changeTags    bool
+		allowUnfreeze bool
+		expectError   bool
+		expectSARMade bool
+	}{
+		{
+			name:       "unfrozen streams are unaffected",
+			changeTags: true,
+		},
+		{
+			name:   "frozen stream allows updates that leave tags alone",
+			frozen: true,
+		},
+		{
+			name:          "frozen stream rejects tag changes",
+			frozen:        true,
+			changeTags:    true,
+			expectError:   true,
+			expectSARMade: true,
+		},
+		{
+			name:          "unfreeze permission writes through the freeze",
+			frozen:        true,
+			changeTags:    true,
+			allowUnfreeze: true,
+			expectSARMade: true,
+		},
+	}
+	for _, test := range tests {
+		sar := &fakeSubjectAccessReviewRegistry{}
+		sar.allow = test.allowUnfreeze
+		strategy := NewStrategy(nil, sar, nil, nil, nil, 0)
+
+		oldStream := frozenStream(1)
+		if !test.frozen {
+			delete(oldStream.Annotations, FrozenAnnotation)
+		}
+		stream := oldStream.DeepCopy()
+		if test.changeTags {
+			tag := stream.Spec.Tags["latest"]
+			tag.From.Name = "registry.example.com/release:retagged"
+			stream.Spec.Tags["latest"] = tag
+		}
+
+		ctx := apirequest.WithUser(context.Background(), &user.DefaultInfo{Name: "bob"})
+		errs := strategy.validateFrozen(ctx, oldStream, stream)
+		if test.expectError && len(errs) == 0 {
+			t.Errorf("%s: expected a forbidden error", test.name)
+		}
+		if !test.expectError && len(errs) != 0 {
+			t.Errorf("%s: unexpected errors: %v", test.name, errs)
+		}
+		if test.expectSARMade != (sar.request != nil) {
+			t.Errorf("%s: expected SAR made=%v, got request %#v", test.name, test.expectSARMade, sar.request)
+		}
+		if sar.request != nil {
+			attrs := sar.request.Spec.ResourceAttributes
+			if attrs.Subresource != "unfreeze" || attrs.Verb != "create" || attrs.Name != "release" || attrs.Namespace != "ns" {
+				t.Errorf("%s: unexpected SAR attributes: %#v", test.name, attrs)
+			}
+		}
+	}
+}
+
+func TestCheckFrozenStreamWrite(t *testing.T) {
+	sar := &fakeSubjectAccessReviewRegistry{}
+	ctx := apirequest.WithUser(context.Background(), &user.DefaultInfo{Name: "registry"})
+
+	stream := frozenStream(1)
+	if err := CheckFrozenStreamWrite(ctx, sar, stream); err == nil {
+		t.Error("expected a forbidden error for a frozen stream")
+	}
+
+	sar.allow = true
+	if err := CheckFrozenStreamWrite(ctx, sar, stream); err != nil {
+		t.Errorf("expected unfreeze permission to allow the write, got %v", err)
+	}
+
+	delete(stream.Annotations, FrozenAnnotation)
+	sar.allow = false
+	sar.request = nil
+	if err := CheckFrozenStreamWrite(ctx, sar, stream); err != nil {
+		t.Errorf("unexpected error for an unfrozen stream: %v", err)
+	}
+	if sar.request != nil {
+		t.Error("expected no SAR for an unfrozen stream")
+	}
+}
@@ -0,0 +1,169 @@
+package imagestream
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	authorizationapi "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
+	"github.com/openshift/library-go/pkg/image/reference"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+// RenameToAnnotation, when written to an image stream, requests renaming the
+// stream to the given name in the same namespace instead of applying the
+// update.  The stream is copied under the new name with its history and
+// self-references rewritten, and the old name is kept as a tombstone alias for
+// a grace period so existing references keep resolving while they are moved.
+const RenameToAnnotation = "image.openshift.io/rename-to"
+
+// RenamedFromAnnotation records the previous name on a renamed image stream.
+const RenamedFromAnnotation = "image.openshift.io/renamed-from"
+
+// RenamedToAnnotation marks a tombstone alias left behind by a rename and
+// names the stream that replaced it.
+const RenamedToAnnotation = "image.openshift.io/renamed-to"
+
+// RenameTombstoneExpiryAnnotation records, in RFC 3339 form, when the
+// tombstone alias left behind by a rename stops being needed.  The apiserver
+// does not delete expired tombstones itself; the annotation lets cleanup
+// tooling find them.
+const RenameTombstoneExpiryAnnotation = "image.openshift.io/rename-tombstone-expiry"
+
+// renameTombstoneGracePeriod is how long a tombstone alias is expected to
+// cover references that still use the old name.
+const renameTombstoneGracePeriod = 24 * time.Hour
+
+// RenameTarget returns the new name requested by the rename annotation on
+// stream, or the empty string when the update is not a rename request.
+func RenameTarget(stream *imageapi.ImageStream) string {
+	target := stream.Annotations[RenameToAnnotation]
+	if target == stream.Name {
+		return ""
+	}
+	return target
+}
+
+// CanRename returns true if user may rename the stream oldName to newName in
+// namespace.  A rename is a create of the new name and a delete of the old
+// one, so both permissions are required.
+func CanRename(ctx context.Context, sarClient authorizationclient.SubjectAccessReviewInterface, user user.Info, namespace, oldName, newName string) bool {
+	checks := []struct {
+		verb string
+		name string
+	}{
+		{verb: "delete", name: oldName},
+		{verb: "create", name: newName},
+	}
+	for _, check := range checks {
+		subjectAccessReview := authorizationutil.AddUserToSAR(user, &authorizationapi.SubjectAccessReview{
+			Spec: authorizationapi.SubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationapi.ResourceAttributes{
+					Namespace: namespace,
+					Verb:      check.verb,
+					Group:     imageapi.GroupName,
+					Resource:  "imagestreams",
+					Name:      check.name,
+				},
+			},
+		})
+		resp, err := sarClient.Create(ctx, subjectAccessReview, metav1.CreateOptions{})
+		if err != nil {
+			klog.V(2).Infof("Error checking %s access for user=%s to image stream %s/%s: %v", check.verb, user.GetName(), namespace, check.name, err)
+			return false
+		}
+		if !resp.Status.Allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// RenamedImageStream returns a copy of stream carrying newName, with object
+// metadata reset for creation and references to the old name rewritten: spec
+// tags pointing back into the same stream follow the new name, and status
+// items whose pull spec names the old repository are moved to the new one.
+// References held by other objects are not rewritten; the tombstone alias
+// keeps them resolving until they are updated.
+func RenamedImageStream(stream *imageapi.ImageStream, newName string) *imageapi.ImageStream {
+	oldName := stream.Name
+	renamed := stream.DeepCopy()
+	renamed.ObjectMeta = metav1.ObjectMeta{
+		Namespace:   stream.Namespace,
+		Name:        newName,
+		Labels:      renamed.Labels,
+		Annotations: renamed.Annotations,
+	}
+	delete(renamed.Annotations, RenameToAnnotation)
+	if renamed.Annotations == nil {
+		renamed.Annotations = make(map[string]string)
+	}
+	renamed.Annotations[RenamedFromAnnotation] = oldName
+
+	for tag, ref := range renamed.Spec.Tags {
+		if ref.From == nil || (len(ref.From.Namespace) > 0 && ref.From.Namespace != stream.Namespace) {
+			continue
+		}
+		switch ref.From.Kind {
+		case "ImageStreamTag":
+			if strings.HasPrefix(ref.From.Name, oldName+":") {
+				ref.From.Name = newName + strings.TrimPrefix(ref.From.Name, oldName)
+				renamed.Spec.Tags[tag] = ref
+			}
+		case "ImageStreamImage":
+			if strings.HasPrefix(ref.From.Name, oldName+"@") {
+				ref.From.Name = newName + strings.TrimPrefix(ref.From.Name, oldName)
+				renamed.Spec.Tags[tag] = ref
+			}
+		}
+	}
+
+	for tag, events := range renamed.Status.Tags {
+		for i, item := range events.Items {
+			ref, err := reference.Parse(item.DockerImageReference)
+			if err != nil || ref.Namespace != stream.Namespace || ref.Name != oldName {
+				continue
+			}
+			ref.Name = newName
+			events.Items[i].DockerImageReference = ref.Exact()
+		}
+		renamed.Status.Tags[tag] = events
+	}
+	// the repository pull specs are recomputed for the new name on create
+	renamed.Status.DockerImageRepository = ""
+	renamed.Status.PublicDockerImageRepository = ""
+	return renamed
+}
+
+// MakeRenameTombstone turns stream into the tombstone alias left behind by a
+// rename to newName.  The status history is kept so pulls by the old name
+// keep working through the grace period, but the spec tags are dropped so the
+// alias stops importing or tracking new content.
+func MakeRenameTombstone(stream *imageapi.ImageStream, newName string, now time.Time) {
+	if stream.Annotations == nil {
+		stream.Annotations = make(map[string]string)
+	}
+	delete(stream.Annotations, RenameToAnnotation)
+	stream.Annotations[RenamedToAnnotation] = newName
+	stream.Annotations[RenameTombstoneExpiryAnnotation] = now.Add(renameTombstoneGracePeriod).UTC().Format(time.RFC3339)
+	stream.Spec.Tags = nil
+}
+
+// IsRenameTombstone returns true if stream is a tombstone alias left behind
+// by a rename.
+func IsRenameTombstone(stream *imageapi.ImageStream) bool {
+	return len(stream.Annotations[RenamedToAnnotation]) > 0
+}
+
+// RenameTombstoneTarget returns the stream a tombstone alias points at,
+// formatted as a same-namespace reference.
+func RenameTombstoneTarget(stream *imageapi.ImageStream) string {
+	return fmt.Sprintf("%s/%s", stream.Namespace, stream.Annotations[RenamedToAnnotation])
+}
@@ -0,0 +1,149 @@
+package imagestream
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+func TestRenameTarget(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    string
+	}{
+		{
+			name: "no annotation is not a rename",
+		},
+		{
+			name:        "annotation names the target",
+			annotations: map[string]string{RenameToAnnotation: "renamed"},
+			expected:    "renamed",
+		},
+		{
+			name:        "renaming to the current name is a no-op",
+			annotations: map[string]string{RenameToAnnotation: "test"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if target := RenameTarget(streamWithAnnotations(tc.annotations)); target != tc.expected {
+				t.Errorf("expected target %q, got %q", tc.expected, target)
+			}
+		})
+	}
+}
+
+func TestRenamedImageStream(t *testing.T) {
+	stream := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "old",
+			UID:             "uid",
+			ResourceVersion: "10",
+			Labels:          map[string]string{"app": "test"},
+			Annotations:     map[string]string{RenameToAnnotation: "new", "other": "kept"},
+		},
+		Spec: imageapi.ImageStreamSpec{
+			Tags: map[string]imageapi.TagReference{
+				"latest": {
+					Name: "latest",
+					From: &kapi.ObjectReference{Kind: "ImageStreamTag", Name: "old:stable"},
+				},
+				"pinned": {
+					Name: "pinned",
+					From: &kapi.ObjectReference{Kind: "ImageStreamImage", Name: "old@sha256:123"},
+				},
+				"external": {
+					Name: "external",
+					From: &kapi.ObjectReference{Kind: "ImageStreamTag", Namespace: "other", Name: "old:stable"},
+				},
+			},
+		},
+		Status: imageapi.ImageStreamStatus{
+			DockerImageRepository: "registry.local:5000/default/old",
+			Tags: map[string]imageapi.TagEventList{
+				"latest": {
+					Items: []imageapi.TagEvent{
+						{DockerImageReference: "registry.local:5000/default/old@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", Image: "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", Generation: 2},
+						{DockerImageReference: "registry.example.com/repo@sha256:abc", Image: "sha256:abc", Generation: 1},
+					},
+				},
+			},
+		},
+	}
+
+	renamed := RenamedImageStream(stream, "new")
+
+	if renamed.Name != "new" || renamed.Namespace != "default" {
+		t.Errorf("unexpected object meta: %#v", renamed.ObjectMeta)
+	}
+	if len(renamed.UID) > 0 || len(renamed.ResourceVersion) > 0 {
+		t.Errorf("expected object meta to be reset for creation: %#v", renamed.ObjectMeta)
+	}
+	if renamed.Labels["app"] != "test" || renamed.Annotations["other"] != "kept" {
+		t.Errorf("expected labels and annotations to be kept: %#v", renamed.ObjectMeta)
+	}
+	if _, ok := renamed.Annotations[RenameToAnnotation]; ok {
+		t.Errorf("expected the rename annotation to be dropped, got %v", renamed.Annotations)
+	}
+	if renamed.Annotations[RenamedFromAnnotation] != "old" {
+		t.Errorf("expected the old name to be recorded, got %v", renamed.Annotations)
+	}
+	if from := renamed.Spec.Tags["latest"].From.Name; from != "new:stable" {
+		t.Errorf("expected the tag self-reference to follow the rename, got %q", from)
+	}
+	if from := renamed.Spec.Tags["pinned"].From.Name; from != "new@sha256:123" {
+		t.Errorf("expected the image self-reference to follow the rename, got %q", from)
+	}
+	if from := renamed.Spec.Tags["external"].From.Name; from != "old:stable" {
+		t.Errorf("expected the cross-namespace reference to be untouched, got %q", from)
+	}
+	items := renamed.Status.Tags["latest"].Items
+	if items[0].DockerImageReference != "registry.local:5000/default/new@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" {
+		t.Errorf("expected the local pull spec to be rewritten, got %q", items[0].DockerImageReference)
+	}
+	if items[1].DockerImageReference != "registry.example.com/repo@sha256:abc" {
+		t.Errorf("expected the external pull spec to be untouched, got %q", items[1].DockerImageReference)
+	}
+	if len(renamed.Status.DockerImageRepository) > 0 {
+		t.Errorf("expected the repository pull spec to be cleared, got %q", renamed.Status.DockerImageRepository)
+	}
+	if stream.Spec.Tags["latest"].From.Name != "old:stable" || stream.Status.Tags["latest"].Items[0].Generation != 2 {
+		t.Errorf("expected the input stream to be unmodified: %#v", stream)
+	}
+}
+
+func TestMakeRenameTombstone(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	stream := streamWithAnnotations(map[string]string{RenameToAnnotation: "new"})
+	stream.Spec.Tags = map[string]imageapi.TagReference{"latest": {Name: "latest"}}
+	stream.Status.Tags = map[string]imageapi.TagEventList{
+		"latest": {Items: []imageapi.TagEvent{{Image: "sha256:123", Generation: 1}}},
+	}
+
+	MakeRenameTombstone(stream, "new", now)
+
+	if !IsRenameTombstone(stream) {
+		t.Fatalf("expected the stream to become a tombstone: %v", stream.Annotations)
+	}
+	if target := RenameTombstoneTarget(stream); target != "default/new" {
+		t.Errorf("expected tombstone target %q, got %q", "default/new", target)
+	}
+	if _, ok := stream.Annotations[RenameToAnnotation]; ok {
+		t.Errorf("expected the rename annotation to be dropped, got %v", stream.Annotations)
+	}
+	if expiry := stream.Annotations[RenameTombstoneExpiryAnnotation]; expiry != "2024-06-02T12:00:00Z" {
+		t.Errorf("expected expiry %q, got %q", "2024-06-02T12:00:00Z", expiry)
+	}
+	if stream.Spec.Tags != nil {
+		t.Errorf("expected spec tags to be dropped, got %v", stream.Spec.Tags)
+	}
+	if len(stream.Status.Tags["latest"].Items) != 1 {
+		t.Errorf("expected the status history to be kept, got %v", stream.Status.Tags)
+	}
+}
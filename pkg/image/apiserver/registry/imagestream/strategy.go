@@ -24,6 +24,7 @@ import (
 
 	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
 	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/deprecatedusage"
 	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
 	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation"
 	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/whitelist"
@@ -44,6 +45,10 @@ type Strategy struct {
 	limitVerifier             imageadmission.LimitVerifier
 	registryWhitelister       whitelist.RegistryWhitelister
 	imageStreamGetter         ResourceGetter
+	// maxTagHistory bounds the number of history items kept per status tag;
+	// zero means unlimited. Streams may override it with the
+	// MaxTagHistoryAnnotation.
+	maxTagHistory int
 }
 
 // NewStrategy is the default logic that applies when creating and updating
@@ -54,6 +59,7 @@ func NewStrategy(
 	limitVerifier imageadmission.LimitVerifier,
 	registryWhitelister whitelist.RegistryWhitelister,
 	imageStreamGetter ResourceGetter,
+	maxTagHistory int,
 ) Strategy {
 	return Strategy{
 		ObjectTyper:               legacyscheme.Scheme,
@@ -63,6 +69,7 @@ func NewStrategy(
 		limitVerifier:             limitVerifier,
 		registryWhitelister:       registryWhitelister,
 		imageStreamGetter:         imageStreamGetter,
+		maxTagHistory:             maxTagHistory,
 	}
 }
 
@@ -94,6 +101,9 @@ func (s Strategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
 		stream.Spec.Tags[tag] = ref
 	}
 	collapseEmptyStatusTags(stream)
+	if len(stream.Spec.DockerImageRepository) > 0 {
+		deprecatedusage.Record(ctx, deprecatedusage.SignalImageStreamSpecDockerImageRepository)
+	}
 }
 
 // Validate validates a new image stream and verifies the current user is
@@ -206,6 +216,7 @@ func parseFromReference(stream *imageapi.ImageStream, from *kapi.ObjectReference
 // if the old stream is nil, all tags are considered additions.
 func (s Strategy) tagsChanged(ctx context.Context, old, stream *imageapi.ImageStream) field.ErrorList {
 	internalRegistry, hasInternalRegistry := s.registryHostnameRetriever.InternalRegistryHostname(ctx)
+	externalRegistryHostnames := s.registryHostnameRetriever.ExternalRegistryHostnames()
 
 	var errs field.ErrorList
 
@@ -277,11 +288,20 @@ func (s Strategy) tagsChanged(ctx context.Context, old, stream *imageapi.ImageSt
 			continue
 		}
 
-		// if this is not a reference tag, and the tag points to the internal registry for the other namespace, alter it to
-		// point to this stream so that pulls happen from this stream in the future.
+		// if this is not a reference tag, and the tag points to the integrated
+		// registry - under its internal hostname or any of its recognized
+		// external hostnames - for the other namespace, alter it to point to
+		// this stream so that pulls happen from this stream in the future.
 		if !tagRef.Reference {
 			if ref, err := reference.Parse(event.DockerImageReference); err == nil {
-				if hasInternalRegistry && ref.Registry == internalRegistry && ref.Namespace == streamRef.Namespace && ref.Name == streamRef.Name {
+				localRegistry := hasInternalRegistry && ref.Registry == internalRegistry
+				for _, hostname := range externalRegistryHostnames {
+					if ref.Registry == hostname {
+						localRegistry = true
+						break
+					}
+				}
+				if localRegistry && ref.Namespace == streamRef.Namespace && ref.Name == streamRef.Name {
 					ref.Namespace = stream.Namespace
 					ref.Name = stream.Name
 					event.DockerImageReference = ref.Exact()
@@ -598,17 +618,39 @@ func (s Strategy) prepareForUpdate(ctx context.Context, obj, old runtime.Object,
 
 	// default spec tag generations afterwards (to avoid updating the generation for legacy objects)
 	ensureSpecTagGenerationsAreSet(stream, oldStream)
+
+	// a tag the user re-creates in the spec is no longer pending delete
+	releaseTagDeleteIntentsForSpecTags(stream)
+
+	if len(stream.Spec.DockerImageRepository) > 0 && stream.Spec.DockerImageRepository != oldStream.Spec.DockerImageRepository {
+		deprecatedusage.Record(ctx, deprecatedusage.SignalImageStreamSpecDockerImageRepository)
+	}
 }
 
 func (s Strategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
 	s.prepareForUpdate(ctx, obj, old, true)
 }
 
+// validateFrozen rejects spec tag changes to a frozen stream unless the user
+// holds unfreeze permission for it.
+func (s Strategy) validateFrozen(ctx context.Context, oldStream, stream *imageapi.ImageStream) field.ErrorList {
+	if !IsFrozen(oldStream) || kapihelper.Semantic.DeepEqual(oldStream.Spec.Tags, stream.Spec.Tags) {
+		return nil
+	}
+	user, ok := apirequest.UserFrom(ctx)
+	if ok && canBypassFreeze(ctx, s.tagVerifier.subjectAccessReviewClient, user, stream.Namespace, stream.Name) {
+		return nil
+	}
+	recordFrozenWriteRejected(ctx, user, stream.Namespace, stream.Name)
+	return field.ErrorList{field.Forbidden(field.NewPath("spec", "tags"), fmt.Sprintf("image stream is frozen by the %s annotation; tags may only be changed by users permitted to unfreeze it", FrozenAnnotation))}
+}
+
 // ValidateUpdate is the default update validation for an end user.
 func (s Strategy) ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList {
 	stream := obj.(*imageapi.ImageStream)
 	oldStream := old.(*imageapi.ImageStream)
 	var errs field.ErrorList
+	errs = append(errs, s.validateFrozen(ctx, oldStream, stream)...)
 	if err := s.validateTagsAndLimits(ctx, oldStream, stream); err != nil {
 		errs = append(errs, field.InternalError(field.NewPath(""), err))
 	}
@@ -649,13 +691,23 @@ func NewStatusStrategy(strategy Strategy) StatusStrategy {
 func (StatusStrategy) Canonicalize(obj runtime.Object) {
 }
 
-func (StatusStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
+func (s StatusStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
 	oldStream := old.(*imageapi.ImageStream)
 	stream := obj.(*imageapi.ImageStream)
 
 	stream.Spec.Tags = oldStream.Spec.Tags
 	stream.Spec.DockerImageRepository = oldStream.Spec.DockerImageRepository
 
+	// only spec changes move the generation; a status writer must not be able
+	// to make a stream look newer (or older) than the spec it acted on
+	stream.Generation = oldStream.Generation
+
+	// a status writer that read the stream before a tag delete committed must
+	// not resurrect the tag with stale history
+	enforceTagDeleteIntents(stream, oldStream)
+
+	limitTagHistory(stream, oldStream, s.maxTagHistory)
+
 	updateObservedGenerationForStatusUpdate(stream, oldStream)
 }
 
@@ -707,4 +759,6 @@ func (s InternalStrategy) PrepareForCreate(ctx context.Context, obj runtime.Obje
 
 func (s InternalStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
 	s.prepareForUpdate(ctx, obj, old, false)
+	enforceTagDeleteIntents(obj.(*imageapi.ImageStream), old.(*imageapi.ImageStream))
+	limitTagHistory(obj.(*imageapi.ImageStream), old.(*imageapi.ImageStream), s.maxTagHistory)
 }
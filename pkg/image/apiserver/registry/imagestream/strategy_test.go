@@ -149,7 +149,7 @@ func TestPublicDockerImageRepository(t *testing.T) {
 	}
 
 	for testName, test := range tests {
-		strategy := NewStrategy(registryhostname.TestingRegistryHostnameRetriever(nil, test.publicRegistry, ""), &fakeSubjectAccessReviewRegistry{}, &admfake.ImageStreamLimitVerifier{}, nil, nil)
+		strategy := NewStrategy(registryhostname.TestingRegistryHostnameRetriever(nil, test.publicRegistry, ""), &fakeSubjectAccessReviewRegistry{}, &admfake.ImageStreamLimitVerifier{}, nil, nil, 0)
 		value := strategy.publicDockerImageRepository(test.stream)
 		if e, a := test.expected, value; e != a {
 			t.Errorf("%s: expected %q, got %q", testName, e, a)
@@ -220,7 +220,7 @@ func TestDockerImageRepository(t *testing.T) {
 
 	for testName, test := range tests {
 		fakeRegistry := &fakeDefaultRegistry{test.defaultRegistry}
-		strategy := NewStrategy(registryhostname.TestingRegistryHostnameRetriever(fakeRegistry.DefaultRegistry, "", ""), &fakeSubjectAccessReviewRegistry{}, &admfake.ImageStreamLimitVerifier{}, nil, nil)
+		strategy := NewStrategy(registryhostname.TestingRegistryHostnameRetriever(fakeRegistry.DefaultRegistry, "", ""), &fakeSubjectAccessReviewRegistry{}, &admfake.ImageStreamLimitVerifier{}, nil, nil, 0)
 		value := strategy.dockerImageRepository(context.TODO(), test.stream, true)
 		if e, a := test.expected, value; e != a {
 			t.Errorf("%s: expected %q, got %q", testName, e, a)
@@ -1517,3 +1517,29 @@ func TestTagsChangedHelper(t *testing.T) {
 		}
 	}
 }
+
+func TestStatusUpdatePreservesGeneration(t *testing.T) {
+	strategy := NewStrategy(registryhostname.TestingRegistryHostnameRetriever(nil, "", ""), &fakeSubjectAccessReviewRegistry{}, &admfake.ImageStreamLimitVerifier{}, &fake.RegistryWhitelister{}, nil, 0)
+	statusStrategy := NewStatusStrategy(strategy)
+
+	old := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "stream", Generation: 5},
+	}
+	stream := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "stream", Generation: 9},
+		Status: imageapi.ImageStreamStatus{
+			Tags: map[string]imageapi.TagEventList{
+				"latest": {Items: []imageapi.TagEvent{{DockerImageReference: "registry.example.com/ns/stream:latest"}}},
+			},
+		},
+	}
+
+	statusStrategy.PrepareForUpdate(apirequest.NewDefaultContext(), stream, old)
+
+	if stream.Generation != 5 {
+		t.Errorf("expected status update to preserve generation 5, got %d", stream.Generation)
+	}
+	if e, a := int64(5), stream.Status.Tags["latest"].Items[0].Generation; e != a {
+		t.Errorf("expected status tag generation %d, got %d", e, a)
+	}
+}
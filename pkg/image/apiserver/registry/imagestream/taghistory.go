@@ -0,0 +1,121 @@
+package imagestream
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+// MaxTagHistoryAnnotation overrides the cluster-wide tag history limit for a
+// single image stream.  The value is the maximum number of history items kept
+// per status tag; "0" disables the limit for the stream.  The override read
+// during a status write is the one already persisted on the stream, so a
+// status writer cannot raise its own cap in the same request.
+const MaxTagHistoryAnnotation = "image.openshift.io/max-tag-history"
+
+// TagHistoryTrimmedAnnotation records how many history items have been
+// trimmed from each status tag over the stream's lifetime, encoded as
+// "tag=count[,tag=count...]".  It tells consumers that older entries existed
+// but were dropped by the history limit rather than deleted by a user.
+const TagHistoryTrimmedAnnotation = "image.openshift.io/tag-history-trimmed"
+
+// maxTagHistory returns the tag history limit in effect for stream: the
+// per-stream annotation override when it parses as a non-negative integer,
+// otherwise clusterDefault.  Zero means unlimited.
+func maxTagHistory(stream *imageapi.ImageStream, clusterDefault int) int {
+	value, ok := stream.Annotations[MaxTagHistoryAnnotation]
+	if !ok {
+		return clusterDefault
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		klog.V(2).Infof("Ignoring invalid %s annotation %q on image stream %s/%s", MaxTagHistoryAnnotation, value, stream.Namespace, stream.Name)
+		return clusterDefault
+	}
+	return limit
+}
+
+// limitTagHistory drops the oldest history items from any status tag of
+// stream that exceeds the limits in effect, and accumulates the number of
+// dropped items per tag in the TagHistoryTrimmedAnnotation.  Beyond the
+// stream-level count limit, each spec tag may carry its own retention policy
+// (maxHistoryItems, maxAgeSeconds); the tightest limit wins.  All limits are
+// resolved against oldStream so neither the override annotation nor a tag
+// policy can be changed and applied by the same status write.
+func limitTagHistory(stream, oldStream *imageapi.ImageStream, clusterDefault int) {
+	limitTagHistoryAt(stream, oldStream, clusterDefault, time.Now())
+}
+
+func limitTagHistoryAt(stream, oldStream *imageapi.ImageStream, clusterDefault int, now time.Time) {
+	streamLimit := maxTagHistory(oldStream, clusterDefault)
+	trimmed := parseTagHistoryTrimmed(stream)
+	changed := false
+	for tag, events := range stream.Status.Tags {
+		limit := streamLimit
+		policy := oldStream.Spec.Tags[tag]
+		if policy.MaxHistoryItems != nil && *policy.MaxHistoryItems > 0 {
+			if tagLimit := int(*policy.MaxHistoryItems); limit <= 0 || tagLimit < limit {
+				limit = tagLimit
+			}
+		}
+		// items are ordered newest first, so truncating keeps the most recent
+		kept := len(events.Items)
+		if limit > 0 && kept > limit {
+			kept = limit
+		}
+		if policy.MaxAgeSeconds != nil && *policy.MaxAgeSeconds > 0 {
+			maxAge := time.Duration(*policy.MaxAgeSeconds) * time.Second
+			// the newest item is always kept so the tag keeps resolving
+			for kept > 1 && now.Sub(events.Items[kept-1].Created.Time) > maxAge {
+				kept--
+			}
+		}
+		if kept == len(events.Items) {
+			continue
+		}
+		trimmed[tag] += len(events.Items) - kept
+		events.Items = events.Items[:kept:kept]
+		stream.Status.Tags[tag] = events
+		changed = true
+	}
+	if changed {
+		setTagHistoryTrimmed(stream, trimmed)
+	}
+}
+
+// parseTagHistoryTrimmed returns the per-tag trim counts recorded on stream.
+// Malformed entries are dropped.
+func parseTagHistoryTrimmed(stream *imageapi.ImageStream) map[string]int {
+	trimmed := make(map[string]int)
+	for _, entry := range strings.Split(stream.Annotations[TagHistoryTrimmedAnnotation], ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		count, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		trimmed[parts[0]] = count
+	}
+	return trimmed
+}
+
+// setTagHistoryTrimmed replaces the per-tag trim counts recorded on stream.
+func setTagHistoryTrimmed(stream *imageapi.ImageStream, trimmed map[string]int) {
+	entries := make([]string, 0, len(trimmed))
+	for tag, count := range trimmed {
+		entries = append(entries, fmt.Sprintf("%s=%d", tag, count))
+	}
+	sort.Strings(entries)
+	if stream.Annotations == nil {
+		stream.Annotations = make(map[string]string)
+	}
+	stream.Annotations[TagHistoryTrimmedAnnotation] = strings.Join(entries, ",")
+}
@@ -0,0 +1,251 @@
+package imagestream
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+func streamWithAnnotations(annotations map[string]string) *imageapi.ImageStream {
+	return &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", Annotations: annotations},
+	}
+}
+
+func TestMaxTagHistory(t *testing.T) {
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		clusterDefault int
+		expected       int
+	}{
+		{
+			name:           "no annotation uses the cluster default",
+			clusterDefault: 10,
+			expected:       10,
+		},
+		{
+			name:           "annotation overrides the cluster default",
+			annotations:    map[string]string{MaxTagHistoryAnnotation: "3"},
+			clusterDefault: 10,
+			expected:       3,
+		},
+		{
+			name:           "zero annotation disables the limit",
+			annotations:    map[string]string{MaxTagHistoryAnnotation: "0"},
+			clusterDefault: 10,
+			expected:       0,
+		},
+		{
+			name:           "invalid annotation falls back to the cluster default",
+			annotations:    map[string]string{MaxTagHistoryAnnotation: "lots"},
+			clusterDefault: 10,
+			expected:       10,
+		},
+		{
+			name:           "negative annotation falls back to the cluster default",
+			annotations:    map[string]string{MaxTagHistoryAnnotation: "-1"},
+			clusterDefault: 10,
+			expected:       10,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if limit := maxTagHistory(streamWithAnnotations(tc.annotations), tc.clusterDefault); limit != tc.expected {
+				t.Errorf("expected limit %d, got %d", tc.expected, limit)
+			}
+		})
+	}
+}
+
+func TestLimitTagHistory(t *testing.T) {
+	tests := []struct {
+		name            string
+		oldAnnotations  map[string]string
+		annotations     map[string]string
+		generations     []int64
+		clusterDefault  int
+		expectedItems   []int64
+		expectedTrimmed string
+	}{
+		{
+			name:           "no limit keeps the full history",
+			generations:    []int64{5, 4, 3, 2, 1},
+			clusterDefault: 0,
+			expectedItems:  []int64{5, 4, 3, 2, 1},
+		},
+		{
+			name:           "history within the limit is untouched",
+			generations:    []int64{5, 4, 3},
+			clusterDefault: 3,
+			expectedItems:  []int64{5, 4, 3},
+		},
+		{
+			name:            "overflowing history drops the oldest items",
+			generations:     []int64{5, 4, 3, 2, 1},
+			clusterDefault:  2,
+			expectedItems:   []int64{5, 4},
+			expectedTrimmed: "latest=3",
+		},
+		{
+			name:            "stream annotation overrides the cluster default",
+			oldAnnotations:  map[string]string{MaxTagHistoryAnnotation: "4"},
+			generations:     []int64{5, 4, 3, 2, 1},
+			clusterDefault:  2,
+			expectedItems:   []int64{5, 4, 3, 2},
+			expectedTrimmed: "latest=1",
+		},
+		{
+			name:           "zero override on the stream disables the limit",
+			oldAnnotations: map[string]string{MaxTagHistoryAnnotation: "0"},
+			generations:    []int64{5, 4, 3, 2, 1},
+			clusterDefault: 2,
+			expectedItems:  []int64{5, 4, 3, 2, 1},
+		},
+		{
+			name:            "override set by the same write is not honored",
+			annotations:     map[string]string{MaxTagHistoryAnnotation: "0"},
+			generations:     []int64{5, 4, 3},
+			clusterDefault:  2,
+			expectedItems:   []int64{5, 4},
+			expectedTrimmed: "latest=1",
+		},
+		{
+			name:            "trim counts accumulate",
+			annotations:     map[string]string{TagHistoryTrimmedAnnotation: "latest=7,other=2"},
+			generations:     []int64{5, 4, 3},
+			clusterDefault:  2,
+			expectedItems:   []int64{5, 4},
+			expectedTrimmed: "latest=8,other=2",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			old := streamWithAnnotations(tc.oldAnnotations)
+			stream := withStatusTag(streamWithAnnotations(tc.annotations), "latest", tc.generations...)
+
+			limitTagHistory(stream, old, tc.clusterDefault)
+
+			items := stream.Status.Tags["latest"].Items
+			if len(items) != len(tc.expectedItems) {
+				t.Fatalf("expected %d history items, got %d", len(tc.expectedItems), len(items))
+			}
+			for i, generation := range tc.expectedItems {
+				if items[i].Generation != generation {
+					t.Errorf("item %d: expected generation %d, got %d", i, generation, items[i].Generation)
+				}
+			}
+			if trimmed := stream.Annotations[TagHistoryTrimmedAnnotation]; trimmed != tc.expectedTrimmed {
+				t.Errorf("expected trimmed annotation %q, got %q", tc.expectedTrimmed, trimmed)
+			}
+		})
+	}
+}
+
+func TestLimitTagHistoryTagPolicy(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	int32p := func(i int32) *int32 { return &i }
+	int64p := func(i int64) *int64 { return &i }
+
+	tests := []struct {
+		name            string
+		oldPolicy       imageapi.TagReference
+		newPolicy       *imageapi.TagReference
+		ages            []time.Duration
+		clusterDefault  int
+		expectedItems   int
+		expectedTrimmed string
+	}{
+		{
+			name:            "tag limit tightens the stream limit",
+			oldPolicy:       imageapi.TagReference{MaxHistoryItems: int32p(2)},
+			ages:            []time.Duration{0, time.Hour, 2 * time.Hour, 3 * time.Hour},
+			clusterDefault:  10,
+			expectedItems:   2,
+			expectedTrimmed: "latest=2",
+		},
+		{
+			name:           "tag limit looser than the stream limit is ignored",
+			oldPolicy:      imageapi.TagReference{MaxHistoryItems: int32p(10)},
+			ages:           []time.Duration{0, time.Hour},
+			clusterDefault: 3,
+			expectedItems:  2,
+		},
+		{
+			name:            "tag limit applies when the stream is unlimited",
+			oldPolicy:       imageapi.TagReference{MaxHistoryItems: int32p(1)},
+			ages:            []time.Duration{0, time.Hour, 2 * time.Hour},
+			clusterDefault:  0,
+			expectedItems:   1,
+			expectedTrimmed: "latest=2",
+		},
+		{
+			name:            "age limit drops stale items",
+			oldPolicy:       imageapi.TagReference{MaxAgeSeconds: int64p(3600)},
+			ages:            []time.Duration{10 * time.Minute, 30 * time.Minute, 2 * time.Hour, 3 * time.Hour},
+			clusterDefault:  0,
+			expectedItems:   2,
+			expectedTrimmed: "latest=2",
+		},
+		{
+			name:            "age limit keeps the newest item",
+			oldPolicy:       imageapi.TagReference{MaxAgeSeconds: int64p(60)},
+			ages:            []time.Duration{2 * time.Hour, 3 * time.Hour},
+			clusterDefault:  0,
+			expectedItems:   1,
+			expectedTrimmed: "latest=1",
+		},
+		{
+			name:            "count and age limits combine",
+			oldPolicy:       imageapi.TagReference{MaxHistoryItems: int32p(3), MaxAgeSeconds: int64p(3600)},
+			ages:            []time.Duration{10 * time.Minute, 2 * time.Hour, 3 * time.Hour, 4 * time.Hour},
+			clusterDefault:  0,
+			expectedItems:   1,
+			expectedTrimmed: "latest=3",
+		},
+		{
+			name:           "policy set by the same write is not honored",
+			newPolicy:      &imageapi.TagReference{MaxHistoryItems: int32p(1)},
+			ages:           []time.Duration{0, time.Hour},
+			clusterDefault: 0,
+			expectedItems:  2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			old := streamWithAnnotations(nil)
+			old.Spec.Tags = map[string]imageapi.TagReference{"latest": tc.oldPolicy}
+			stream := streamWithAnnotations(nil)
+			if tc.newPolicy != nil {
+				stream.Spec.Tags = map[string]imageapi.TagReference{"latest": *tc.newPolicy}
+			}
+			events := imageapi.TagEventList{}
+			for i, age := range tc.ages {
+				events.Items = append(events.Items, imageapi.TagEvent{
+					Image:      "sha256:123",
+					Generation: int64(len(tc.ages) - i),
+					Created:    metav1.Time{Time: now.Add(-age)},
+				})
+			}
+			stream.Status.Tags = map[string]imageapi.TagEventList{"latest": events}
+
+			limitTagHistoryAt(stream, old, tc.clusterDefault, now)
+
+			items := stream.Status.Tags["latest"].Items
+			if len(items) != tc.expectedItems {
+				t.Fatalf("expected %d history items, got %d", tc.expectedItems, len(items))
+			}
+			for i, item := range items {
+				if expected := int64(len(tc.ages) - i); item.Generation != expected {
+					t.Errorf("item %d: expected generation %d, got %d", i, expected, item.Generation)
+				}
+			}
+			if trimmed := stream.Annotations[TagHistoryTrimmedAnnotation]; trimmed != tc.expectedTrimmed {
+				t.Errorf("expected trimmed annotation %q, got %q", tc.expectedTrimmed, trimmed)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package imagestreamfreeze
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/util/retry"
+
+	imagev1clienttyped "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream"
+)
+
+// FrozenTransitionAnnotation records when the frozen state of an image
+// stream last changed through the freeze or unfreeze subresource.
+const FrozenTransitionAnnotation = "image.openshift.io/frozen-last-transition"
+
+// FrozenByAnnotation records the user who froze the stream.  It is removed
+// when the stream is unfrozen.
+const FrozenByAnnotation = "image.openshift.io/frozen-by"
+
+// FreezeResult is the response body of the freeze and unfreeze subresources.
+type FreezeResult struct {
+	// Namespace and Name identify the ImageStream acted on.
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Frozen is the state of the ImageStream after the request.
+	Frozen bool `json:"frozen"`
+}
+
+// REST atomically toggles the image.openshift.io/frozen annotation on an
+// ImageStream.  The image stream update strategy and the mapping path refuse
+// tag mutations on frozen streams; this subresource makes the toggle an
+// RBAC-guarded action instead of requiring clients to patch the annotation
+// themselves.
+type REST struct {
+	imageStreamClient imagev1clienttyped.ImageStreamsGetter
+	freeze            bool
+}
+
+var _ rest.Connecter = &REST{}
+
+// NewFreezeREST returns the handler for the imagestreams/{name}/freeze
+// subresource.
+func NewFreezeREST(imageStreamClient imagev1clienttyped.ImageStreamsGetter) *REST {
+	return &REST{imageStreamClient: imageStreamClient, freeze: true}
+}
+
+// NewUnfreezeREST returns the handler for the imagestreams/{name}/unfreeze
+// subresource.
+func NewUnfreezeREST(imageStreamClient imagev1clienttyped.ImageStreamsGetter) *REST {
+	return &REST{imageStreamClient: imageStreamClient, freeze: false}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &imageapi.ImageStream{}
+}
+
+// ConnectMethods returns POST, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"POST"}
+}
+
+// NewConnectOptions returns an empty options object
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that toggles the frozen annotation of the named
+// ImageStream.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	namespace, ok := apirequest.NamespaceFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewBadRequest("a namespace must be specified")
+	}
+
+	username := ""
+	if user, ok := apirequest.UserFrom(ctx); ok {
+		username = user.GetName()
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := r.setFrozen(ctx, namespace, name, username); err != nil {
+			responder.Error(err)
+			return
+		}
+
+		result := FreezeResult{Namespace: namespace, Name: name, Frozen: r.freeze}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(&result); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+// setFrozen updates the frozen annotations on the ImageStream, retrying on
+// conflicts so concurrent freeze/unfreeze requests settle on the last
+// writer.  Requests that do not change the state are a no-op.
+func (r *REST) setFrozen(ctx context.Context, namespace, name, username string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		stream, err := r.imageStreamClient.ImageStreams(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		frozen := stream.Annotations[imagestream.FrozenAnnotation] == "true"
+		if frozen == r.freeze {
+			return nil
+		}
+
+		if stream.Annotations == nil {
+			stream.Annotations = map[string]string{}
+		}
+		if r.freeze {
+			stream.Annotations[imagestream.FrozenAnnotation] = "true"
+			stream.Annotations[FrozenByAnnotation] = username
+		} else {
+			delete(stream.Annotations, imagestream.FrozenAnnotation)
+			delete(stream.Annotations, FrozenByAnnotation)
+		}
+		stream.Annotations[FrozenTransitionAnnotation] = time.Now().UTC().Format(time.RFC3339)
+
+		_, err = r.imageStreamClient.ImageStreams(namespace).Update(ctx, stream, metav1.UpdateOptions{})
+		return err
+	})
+}
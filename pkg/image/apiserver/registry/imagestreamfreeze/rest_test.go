@@ -0,0 +1,116 @@
+package imagestreamfreeze
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imagefake "github.com/openshift/client-go/image/clientset/versioned/fake"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream"
+)
+
+type fakeResponder struct {
+	err error
+}
+
+func (r *fakeResponder) Object(statusCode int, obj runtime.Object) {}
+
+func (r *fakeResponder) Error(err error) {
+	r.err = err
+}
+
+func TestFreezeAndUnfreeze(t *testing.T) {
+	stream := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "release"},
+	}
+	client := imagefake.NewSimpleClientset(stream)
+	ctx := apirequest.WithNamespace(context.Background(), "ns")
+	ctx = apirequest.WithUser(ctx, &user.DefaultInfo{Name: "admin"})
+
+	result := invoke(t, NewFreezeREST(client.ImageV1()), ctx, "/freeze")
+	if !result.Frozen {
+		t.Error("expected the stream to be frozen")
+	}
+
+	updated, err := client.ImageV1().ImageStreams("ns").Get(ctx, "release", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Annotations[imagestream.FrozenAnnotation] != "true" {
+		t.Errorf("expected frozen annotation, got %v", updated.Annotations)
+	}
+	if updated.Annotations[FrozenByAnnotation] != "admin" {
+		t.Errorf("expected the freezing user to be recorded, got %v", updated.Annotations)
+	}
+	if len(updated.Annotations[FrozenTransitionAnnotation]) == 0 {
+		t.Errorf("expected a transition annotation, got %v", updated.Annotations)
+	}
+
+	result = invoke(t, NewUnfreezeREST(client.ImageV1()), ctx, "/unfreeze")
+	if result.Frozen {
+		t.Error("expected the stream to be unfrozen")
+	}
+	updated, err = client.ImageV1().ImageStreams("ns").Get(ctx, "release", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := updated.Annotations[imagestream.FrozenAnnotation]; ok {
+		t.Errorf("expected frozen annotation to be removed, got %v", updated.Annotations)
+	}
+	if _, ok := updated.Annotations[FrozenByAnnotation]; ok {
+		t.Errorf("expected frozen-by annotation to be removed, got %v", updated.Annotations)
+	}
+}
+
+func TestFreezeIsIdempotent(t *testing.T) {
+	stream := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "release",
+			Annotations: map[string]string{imagestream.FrozenAnnotation: "true"},
+		},
+	}
+	client := imagefake.NewSimpleClientset(stream)
+	ctx := apirequest.WithNamespace(context.Background(), "ns")
+
+	result := invoke(t, NewFreezeREST(client.ImageV1()), ctx, "/freeze")
+	if !result.Frozen {
+		t.Error("expected the stream to stay frozen")
+	}
+	updated, err := client.ImageV1().ImageStreams("ns").Get(ctx, "release", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := updated.Annotations[FrozenTransitionAnnotation]; ok {
+		t.Errorf("expected no transition to be recorded for a no-op freeze, got %v", updated.Annotations)
+	}
+}
+
+func invoke(t *testing.T, storage *REST, ctx context.Context, target string) FreezeResult {
+	t.Helper()
+	responder := &fakeResponder{}
+	handler, err := storage.Connect(ctx, "release", nil, responder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, target, nil))
+	if responder.err != nil {
+		t.Fatalf("unexpected error: %v", responder.err)
+	}
+
+	result := FreezeResult{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("unable to decode response %q: %v", recorder.Body.String(), err)
+	}
+	return result
+}
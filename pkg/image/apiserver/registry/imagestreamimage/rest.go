@@ -78,21 +78,30 @@ func (r *REST) Get(ctx context.Context, id string, options *metav1.GetOptions) (
 
 	repo, err := r.imageStreamRegistry.GetImageStream(ctx, name, &metav1.GetOptions{})
 	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, internalimageutil.NewNotFound(imagegroup.Resource("imagestreamimages"), id, internalimageutil.MissingImageStreamCause)
+		}
 		return nil, err
 	}
 
 	if repo.Status.Tags == nil {
-		return nil, errors.NewNotFound(imagegroup.Resource("imagestreamimage"), id)
+		return nil, internalimageutil.NewNotFound(imagegroup.Resource("imagestreamimages"), id, internalimageutil.MissingImageCause)
 	}
 
 	event, err := internalimageutil.ResolveImageID(repo, imageID)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, internalimageutil.NewNotFound(imagegroup.Resource("imagestreamimages"), id, internalimageutil.MissingImageCause)
+		}
 		return nil, err
 	}
 
 	imageName := event.Image
 	image, err := r.imageRegistry.GetImage(ctx, imageName, &metav1.GetOptions{})
 	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, internalimageutil.NewNotFound(imagegroup.Resource("imagestreamimages"), id, internalimageutil.MissingImageCause)
+		}
 		return nil, err
 	}
 	if err := internalimageutil.InternalImageWithMetadata(image); err != nil {
@@ -6,6 +6,7 @@ import (
 
 	etcd "go.etcd.io/etcd/clientv3"
 	authorizationapi "k8s.io/api/authorization/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -18,6 +19,7 @@ import (
 	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
 	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/fake"
 	admfake "github.com/openshift/openshift-apiserver/pkg/image/apiserver/admission/fake"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/internalimageutil"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/image"
 	imageetcd "github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/image/etcd"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream"
@@ -52,7 +54,7 @@ func setup(t *testing.T) (etcd.KV, *etcdtesting.EtcdTestServer, *REST) {
 	}
 	defaultRegistry := registryhostname.TestingRegistryHostnameRetriever(testDefaultRegistry, "", "")
 	imagestreamRESTOptions := generic.RESTOptions{StorageConfig: etcdStorage, Decorator: generic.UndecoratedStorage, DeleteCollectionWorkers: 1, ResourcePrefix: "imagestreams"}
-	imageStreamStorage, _, imageStreamStatus, internalStorage, err := imagestreametcd.NewRESTWithLimitVerifier(imagestreamRESTOptions, defaultRegistry, &fakeSubjectAccessReviewRegistry{}, &admfake.ImageStreamLimitVerifier{}, &fake.RegistryWhitelister{}, imagestreametcd.NewEmptyLayerIndex())
+	imageStreamStorage, _, imageStreamStatus, internalStorage, err := imagestreametcd.NewRESTWithLimitVerifier(imagestreamRESTOptions, defaultRegistry, &fakeSubjectAccessReviewRegistry{}, &admfake.ImageStreamLimitVerifier{}, &fake.RegistryWhitelister{}, imagestreametcd.NewEmptyLayerIndex(), 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -71,6 +73,7 @@ func TestGet(t *testing.T) {
 		repo        *imageapi.ImageStream
 		image       *imageapi.Image
 		expectError bool
+		errorCause  string
 	}{
 		"empty string": {
 			input:       "",
@@ -96,15 +99,26 @@ func TestGet(t *testing.T) {
 			input:       "repo@id",
 			repo:        nil,
 			expectError: true,
+			errorCause:  internalimageutil.MissingImageStreamCause,
 		},
 		"nil tags": {
-			input:       "repo@id",
-			repo:        &imageapi.ImageStream{},
+			input: "repo@id",
+			repo: &imageapi.ImageStream{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "ns",
+					Name:      "repo",
+				},
+			},
 			expectError: true,
+			errorCause:  internalimageutil.MissingImageCause,
 		},
 		"image not found": {
 			input: "repo@id",
 			repo: &imageapi.ImageStream{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "ns",
+					Name:      "repo",
+				},
 				Status: imageapi.ImageStreamStatus{
 					Tags: map[string]imageapi.TagEventList{
 						"latest": {
@@ -116,6 +130,7 @@ func TestGet(t *testing.T) {
 				},
 			},
 			expectError: true,
+			errorCause:  internalimageutil.MissingImageCause,
 		},
 		"happy path": {
 			input: "repo@id",
@@ -238,6 +253,21 @@ func TestGet(t *testing.T) {
 				return
 			}
 			if test.expectError {
+				if len(test.errorCause) > 0 {
+					if !kapierrors.IsNotFound(err) {
+						t.Errorf("%s: expected a NotFound error, got %v", name, err)
+						return
+					}
+					status := err.(kapierrors.APIStatus).Status()
+					if status.Details.Kind != "imagestreamimages" || status.Details.Name != test.input {
+						t.Errorf("%s: unexpected status: %#v", name, status.Details)
+						return
+					}
+					if len(status.Details.Causes) != 1 || status.Details.Causes[0].Field != test.errorCause {
+						t.Errorf("%s: unexpected causes: %#v", name, status.Details.Causes)
+						return
+					}
+				}
 				return
 			}
 
@@ -0,0 +1,146 @@
+package imagestreamimport
+
+import (
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+const (
+	// defaultScheduledImportInterval mirrors the scheduled import
+	// controller's default polling interval and seeds the advisory
+	// next-attempt estimate.
+	defaultScheduledImportInterval = 15 * time.Minute
+	// maxScheduledImportBackoff caps the estimate for tags whose imports
+	// keep failing.
+	maxScheduledImportBackoff = 24 * time.Hour
+)
+
+// recordImportAttempt maintains the structured per-tag import attempt
+// conditions.  LastImportAttempt reflects the outcome of the most recent
+// attempt, ConsecutiveFailures counts failures since the last success in its
+// reason, and for tags with scheduled imports NextScheduledImport carries an
+// advisory estimate of the next retry, backing off exponentially with the
+// failure count.  Together they let users see when a scheduled import last
+// succeeded and when the next retry is due without scraping controller logs.
+func recordImportAttempt(stream *imageapi.ImageStream, tag string, succeeded bool, generation int64, now metav1.Time) {
+	if len(tag) == 0 {
+		return
+	}
+
+	failures := int64(0)
+	if !succeeded {
+		failures = consecutiveFailures(stream, tag) + 1
+	}
+
+	status := kapi.ConditionTrue
+	reason := "Succeeded"
+	if !succeeded {
+		status = kapi.ConditionFalse
+		reason = "Failed"
+	}
+	replaceTagCondition(stream, tag, imageapi.TagEventCondition{
+		Type:               imageapi.ImportLastAttempt,
+		Status:             status,
+		Reason:             reason,
+		Generation:         generation,
+		LastTransitionTime: now,
+	})
+
+	if succeeded {
+		removeTagCondition(stream, tag, imageapi.ImportConsecutiveFailures)
+	} else {
+		replaceTagCondition(stream, tag, imageapi.TagEventCondition{
+			Type:               imageapi.ImportConsecutiveFailures,
+			Status:             kapi.ConditionTrue,
+			Reason:             strconv.FormatInt(failures, 10),
+			Generation:         generation,
+			LastTransitionTime: now,
+		})
+	}
+
+	if tagRef, ok := stream.Spec.Tags[tag]; ok && tagRef.ImportPolicy.Scheduled {
+		next := now.Add(scheduledImportBackoff(failures))
+		replaceTagCondition(stream, tag, imageapi.TagEventCondition{
+			Type:               imageapi.ImportNextScheduled,
+			Status:             kapi.ConditionTrue,
+			Message:            next.UTC().Format(time.RFC3339),
+			Generation:         generation,
+			LastTransitionTime: now,
+		})
+	} else {
+		removeTagCondition(stream, tag, imageapi.ImportNextScheduled)
+	}
+}
+
+// consecutiveFailures returns the failure count recorded on the tag, or zero
+// if none is recorded or the recorded value does not parse.
+func consecutiveFailures(stream *imageapi.ImageStream, tag string) int64 {
+	for _, condition := range stream.Status.Tags[tag].Conditions {
+		if condition.Type != imageapi.ImportConsecutiveFailures {
+			continue
+		}
+		if failures, err := strconv.ParseInt(condition.Reason, 10, 64); err == nil && failures >= 0 {
+			return failures
+		}
+	}
+	return 0
+}
+
+// scheduledImportBackoff returns the advisory interval until the next
+// scheduled attempt, doubling per consecutive failure up to a cap.
+func scheduledImportBackoff(failures int64) time.Duration {
+	interval := defaultScheduledImportInterval
+	for i := int64(0); i < failures && interval < maxScheduledImportBackoff; i++ {
+		interval *= 2
+	}
+	if interval > maxScheduledImportBackoff {
+		interval = maxScheduledImportBackoff
+	}
+	return interval
+}
+
+// replaceTagCondition replaces the condition of the same type on the tag, or
+// appends it.
+func replaceTagCondition(stream *imageapi.ImageStream, tag string, condition imageapi.TagEventCondition) {
+	tagEvents := stream.Status.Tags[tag]
+	replaced := false
+	for i := range tagEvents.Conditions {
+		if tagEvents.Conditions[i].Type == condition.Type {
+			tagEvents.Conditions[i] = condition
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		tagEvents.Conditions = append(tagEvents.Conditions, condition)
+	}
+	if stream.Status.Tags == nil {
+		stream.Status.Tags = make(map[string]imageapi.TagEventList)
+	}
+	stream.Status.Tags[tag] = tagEvents
+}
+
+// removeTagCondition drops the condition of the given type from the tag, if
+// present.
+func removeTagCondition(stream *imageapi.ImageStream, tag string, conditionType imageapi.TagEventConditionType) {
+	tagEvents, ok := stream.Status.Tags[tag]
+	if !ok {
+		return
+	}
+	conditions := tagEvents.Conditions[:0]
+	for _, condition := range tagEvents.Conditions {
+		if condition.Type != conditionType {
+			conditions = append(conditions, condition)
+		}
+	}
+	if len(conditions) == len(tagEvents.Conditions) {
+		return
+	}
+	tagEvents.Conditions = conditions
+	stream.Status.Tags[tag] = tagEvents
+}
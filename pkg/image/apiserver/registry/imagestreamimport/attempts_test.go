@@ -0,0 +1,103 @@
+package imagestreamimport
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+func tagCondition(stream *imageapi.ImageStream, tag string, conditionType imageapi.TagEventConditionType) *imageapi.TagEventCondition {
+	for i, condition := range stream.Status.Tags[tag].Conditions {
+		if condition.Type == conditionType {
+			return &stream.Status.Tags[tag].Conditions[i]
+		}
+	}
+	return nil
+}
+
+func TestRecordImportAttempt(t *testing.T) {
+	now := metav1.Now()
+	stream := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "stream"},
+		Spec: imageapi.ImageStreamSpec{
+			Tags: map[string]imageapi.TagReference{
+				"latest": {ImportPolicy: imageapi.TagImportPolicy{Scheduled: true}},
+			},
+		},
+	}
+
+	// two failures in a row increment the counter and push the estimate out
+	recordImportAttempt(stream, "latest", false, 2, now)
+	recordImportAttempt(stream, "latest", false, 3, now)
+
+	last := tagCondition(stream, "latest", imageapi.ImportLastAttempt)
+	if last == nil || last.Status != kapi.ConditionFalse || last.Generation != 3 {
+		t.Fatalf("unexpected last attempt condition: %#v", last)
+	}
+	failures := tagCondition(stream, "latest", imageapi.ImportConsecutiveFailures)
+	if failures == nil || failures.Reason != "2" {
+		t.Fatalf("expected 2 consecutive failures, got %#v", failures)
+	}
+	next := tagCondition(stream, "latest", imageapi.ImportNextScheduled)
+	if next == nil {
+		t.Fatal("expected a next scheduled import condition")
+	}
+	expected := now.Add(4 * defaultScheduledImportInterval).UTC().Format(time.RFC3339)
+	if next.Message != expected {
+		t.Errorf("expected next import at %s, got %s", expected, next.Message)
+	}
+
+	// a success resets the counter and returns to the base interval
+	recordImportAttempt(stream, "latest", true, 4, now)
+	last = tagCondition(stream, "latest", imageapi.ImportLastAttempt)
+	if last == nil || last.Status != kapi.ConditionTrue || last.Generation != 4 {
+		t.Fatalf("unexpected last attempt condition after success: %#v", last)
+	}
+	if failures = tagCondition(stream, "latest", imageapi.ImportConsecutiveFailures); failures != nil {
+		t.Errorf("expected failure counter to be cleared, got %#v", failures)
+	}
+	next = tagCondition(stream, "latest", imageapi.ImportNextScheduled)
+	expected = now.Add(defaultScheduledImportInterval).UTC().Format(time.RFC3339)
+	if next == nil || next.Message != expected {
+		t.Errorf("expected next import at %s, got %#v", expected, next)
+	}
+
+	// only one condition of each type accumulates
+	if conditions := stream.Status.Tags["latest"].Conditions; len(conditions) != 2 {
+		t.Errorf("expected exactly two conditions, got %#v", conditions)
+	}
+}
+
+func TestRecordImportAttemptUnscheduledTag(t *testing.T) {
+	now := metav1.Now()
+	stream := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "stream"},
+		Spec: imageapi.ImageStreamSpec{
+			Tags: map[string]imageapi.TagReference{"latest": {}},
+		},
+	}
+
+	recordImportAttempt(stream, "latest", false, 2, now)
+	if next := tagCondition(stream, "latest", imageapi.ImportNextScheduled); next != nil {
+		t.Errorf("expected no scheduled import estimate for an unscheduled tag, got %#v", next)
+	}
+	if last := tagCondition(stream, "latest", imageapi.ImportLastAttempt); last == nil || last.Status != kapi.ConditionFalse {
+		t.Errorf("unexpected last attempt condition: %#v", last)
+	}
+}
+
+func TestScheduledImportBackoff(t *testing.T) {
+	if interval := scheduledImportBackoff(0); interval != defaultScheduledImportInterval {
+		t.Errorf("expected base interval with no failures, got %s", interval)
+	}
+	if interval := scheduledImportBackoff(3); interval != 8*defaultScheduledImportInterval {
+		t.Errorf("expected three doublings, got %s", interval)
+	}
+	if interval := scheduledImportBackoff(100); interval != maxScheduledImportBackoff {
+		t.Errorf("expected the cap, got %s", interval)
+	}
+}
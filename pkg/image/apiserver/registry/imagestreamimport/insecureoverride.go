@@ -0,0 +1,69 @@
+package imagestreamimport
+
+import (
+	"context"
+
+	authorizationapi "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+// InsecureImportOverrideAnnotation requests that the insecure flags carried on
+// the image specs of an ImageStreamImport apply to this import only.  The
+// registry is contacted according to the request's import policies, but the
+// policies stored on the stream's spec tags are left untouched, so a one-off
+// import from a registry with a broken certificate does not permanently mark
+// the tag insecure.  The override is permission checked: bypassing TLS
+// verification on a tag that was configured secure is not something every
+// importer should be able to do.
+const InsecureImportOverrideAnnotation = "image.openshift.io/insecure-import-override"
+
+// insecureOverrideRequested returns true if the import request asks for the
+// one-shot insecure override.
+func insecureOverrideRequested(isi *imageapi.ImageStreamImport) bool {
+	return isi.Annotations[InsecureImportOverrideAnnotation] == "true"
+}
+
+// canOverrideInsecureImport returns true if user may perform an import whose
+// insecure flags bypass the policies recorded on the stream.  The permission
+// checked is create on the insecure-import subresource of the stream.
+func canOverrideInsecureImport(ctx context.Context, sarClient authorizationclient.SubjectAccessReviewInterface, user user.Info, namespace, name string) bool {
+	subjectAccessReview := authorizationutil.AddUserToSAR(user, &authorizationapi.SubjectAccessReview{
+		Spec: authorizationapi.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationapi.ResourceAttributes{
+				Namespace:   namespace,
+				Verb:        "create",
+				Group:       imageapi.GroupName,
+				Resource:    "imagestreams",
+				Subresource: "insecure-import",
+				Name:        name,
+			},
+		},
+	})
+	resp, err := sarClient.Create(ctx, subjectAccessReview, metav1.CreateOptions{})
+	if err != nil {
+		klog.V(2).Infof("Error checking insecure-import access for user=%s to %s/%s: %v", user.GetName(), namespace, name, err)
+		return false
+	}
+	return resp.Status.Allowed
+}
+
+// restoreImportPolicies copies the import policy of every spec tag that
+// already existed on original back onto stream, undoing the policy reset a
+// successful import performs.  Tags created by the import keep the policy the
+// request carried.
+func restoreImportPolicies(stream, original *imageapi.ImageStream) {
+	for tag, specTag := range stream.Spec.Tags {
+		originalTag, ok := original.Spec.Tags[tag]
+		if !ok {
+			continue
+		}
+		specTag.ImportPolicy = originalTag.ImportPolicy
+		stream.Spec.Tags[tag] = specTag
+	}
+}
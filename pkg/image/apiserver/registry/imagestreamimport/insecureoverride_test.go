@@ -0,0 +1,61 @@
+package imagestreamimport
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+func TestInsecureOverrideRequested(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		expected    bool
+	}{
+		"no annotations": {
+			expected: false,
+		},
+		"annotation true": {
+			annotations: map[string]string{InsecureImportOverrideAnnotation: "true"},
+			expected:    true,
+		},
+		"annotation other value": {
+			annotations: map[string]string{InsecureImportOverrideAnnotation: "yes"},
+			expected:    false,
+		},
+	}
+	for name, test := range tests {
+		isi := &imageapi.ImageStreamImport{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+		if got := insecureOverrideRequested(isi); got != test.expected {
+			t.Errorf("%s: expected %t, got %t", name, test.expected, got)
+		}
+	}
+}
+
+func TestRestoreImportPolicies(t *testing.T) {
+	original := &imageapi.ImageStream{
+		Spec: imageapi.ImageStreamSpec{
+			Tags: map[string]imageapi.TagReference{
+				"secure": {ImportPolicy: imageapi.TagImportPolicy{Insecure: false, Scheduled: true}},
+			},
+		},
+	}
+	stream := &imageapi.ImageStream{
+		Spec: imageapi.ImageStreamSpec{
+			Tags: map[string]imageapi.TagReference{
+				"secure": {ImportPolicy: imageapi.TagImportPolicy{Insecure: true}},
+				"new":    {ImportPolicy: imageapi.TagImportPolicy{Insecure: true}},
+			},
+		},
+	}
+
+	restoreImportPolicies(stream, original)
+
+	if policy := stream.Spec.Tags["secure"].ImportPolicy; policy.Insecure || !policy.Scheduled {
+		t.Errorf("expected the pre-existing tag to keep its original import policy, got %#v", policy)
+	}
+	if policy := stream.Spec.Tags["new"].ImportPolicy; !policy.Insecure {
+		t.Errorf("expected the new tag to keep the requested import policy, got %#v", policy)
+	}
+}
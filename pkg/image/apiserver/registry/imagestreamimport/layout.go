@@ -0,0 +1,139 @@
+package imagestreamimport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/importer/ocilayout"
+)
+
+// maxLayoutUploadSize bounds the size of an uploaded OCI image layout
+// tarball.  Only manifests and configs are persisted, so this mostly guards
+// against unbounded request bodies.
+const maxLayoutUploadSize = 1 * 1024 * 1024 * 1024
+
+// LayoutREST implements uploading an OCI image layout tarball as an
+// alternative source for image stream import.  It accepts a tar stream on
+// POST, creates Image objects for each manifest referenced by the layout
+// index, and tags them into the image stream the same way a remote import
+// does.  This allows air-gapped users to seed image streams without running a
+// registry mirror.
+type LayoutREST struct {
+	r *REST
+}
+
+var _ rest.Connecter = &LayoutREST{}
+
+// NewLayoutREST returns the layout upload handler for an import REST storage.
+func NewLayoutREST(r *REST) *LayoutREST {
+	return &LayoutREST{r: r}
+}
+
+// New is only implemented to make LayoutREST implement RESTStorage
+func (r *LayoutREST) New() runtime.Object {
+	return &imageapi.ImageStreamImport{}
+}
+
+// ConnectMethods returns POST, the only supported method
+func (r *LayoutREST) ConnectMethods() []string {
+	return []string{"POST"}
+}
+
+// NewConnectOptions prepares a layout upload; there are no options
+func (r *LayoutREST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that accepts an OCI image layout tarball for the
+// named image stream.
+func (r *LayoutREST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		stream, err := r.importLayout(ctx, name, http.MaxBytesReader(w, req.Body, maxLayoutUploadSize))
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+		isi := &imageapi.ImageStreamImport{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: stream.Namespace},
+			Status:     imageapi.ImageStreamImportStatus{Import: stream},
+		}
+		responder.Object(http.StatusCreated, isi)
+	}), nil
+}
+
+func (r *LayoutREST) importLayout(ctx context.Context, name string, body io.Reader) (*imageapi.ImageStream, error) {
+	namespace, ok := apirequest.NamespaceFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewBadRequest("a namespace must be specified to import images")
+	}
+
+	create := false
+	stream, err := r.r.streams.GetImageStream(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		if !kapierrors.IsNotFound(err) {
+			return nil, err
+		}
+		create = true
+		stream = &imageapi.ImageStream{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+	}
+
+	// uploaded images carry no source registry; their pull spec is the
+	// repository this stream is served from
+	repository := stream.Status.DockerImageRepository
+	if len(repository) == 0 {
+		repository = fmt.Sprintf("%s/%s", namespace, name)
+	}
+
+	images, err := ocilayout.Parse(body, repository)
+	if err != nil {
+		return nil, kapierrors.NewBadRequest(fmt.Sprintf("unable to parse OCI image layout: %v", err))
+	}
+
+	now := metav1.Now()
+	nextGeneration := stream.Generation + 1
+	importedImages := make(map[string]error)
+	updatedImages := make(map[string]*imageapi.Image)
+
+	for _, tagged := range images {
+		r.r.importSuccessful(ctx, tagged.Image, stream, tagged.Tag, tagged.Image.DockerImageReference, nextGeneration,
+			now, imageapi.TagImportPolicy{}, imageapi.TagReferencePolicy{Type: imageapi.SourceTagReferencePolicy}, importedImages, updatedImages)
+	}
+	for _, err := range importedImages {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if stream.Annotations == nil {
+		stream.Annotations = make(map[string]string)
+	}
+	stream.Annotations[imagev1.DockerImageRepositoryCheckAnnotation] = now.UTC().Format(time.RFC3339)
+
+	var obj runtime.Object
+	if create {
+		obj, err = r.r.internalStreams.Create(ctx, stream, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+	} else {
+		obj, _, err = r.r.internalStreams.Update(ctx, stream.Name, rest.DefaultUpdatedObjectInfo(stream), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, false, &metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*imageapi.ImageStream), nil
+}
@@ -16,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/diff"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
@@ -23,6 +24,7 @@ import (
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
 	kapi "k8s.io/kubernetes/pkg/apis/core"
 	kapihelper "k8s.io/kubernetes/pkg/apis/core/helper"
+	utiltrace "k8s.io/utils/trace"
 
 	"github.com/openshift/api/image"
 	imagev1 "github.com/openshift/api/image/v1"
@@ -37,6 +39,7 @@ import (
 	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/whitelist"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/importer"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/internalimageutil"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/labelpolicy"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registries"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream"
 )
@@ -46,17 +49,19 @@ type ImporterFunc func(r importer.RepositoryRetriever, regConf *sysregistriesv2.
 
 // REST implements the RESTStorage interface for ImageStreamImport
 type REST struct {
-	importFn          ImporterFunc
-	streams           imagestream.Registry
-	internalStreams   rest.CreaterUpdater
-	images            rest.Creater
-	isV1Client        imageclientv1.ImageStreamsGetter
-	transport         http.RoundTripper
-	insecureTransport http.RoundTripper
-	strategy          *strategy
-	sarClient         authorizationclient.SubjectAccessReviewInterface
-	icspLister        operatorv1lister.ImageContentSourcePolicyLister
-	imageCfgV1Client  configclientv1.ImagesGetter
+	importFn            ImporterFunc
+	streams             imagestream.Registry
+	internalStreams     rest.CreaterUpdater
+	images              rest.Creater
+	isV1Client          imageclientv1.ImageStreamsGetter
+	transport           http.RoundTripper
+	insecureTransport   http.RoundTripper
+	strategy            *strategy
+	sarClient           authorizationclient.SubjectAccessReviewInterface
+	icspLister          operatorv1lister.ImageContentSourcePolicyLister
+	imageCfgV1Client    configclientv1.ImagesGetter
+	labelPolicy         labelpolicy.Policy
+	credentialProviders *importer.CredentialProviders
 }
 
 var _ rest.Creater = &REST{}
@@ -74,19 +79,23 @@ func NewREST(importFn ImporterFunc, streams imagestream.Registry, internalStream
 	sarClient authorizationclient.SubjectAccessReviewInterface,
 	icspLister operatorv1lister.ImageContentSourcePolicyLister,
 	imageCfgV1Client configclientv1.ImagesGetter,
+	labelPolicy labelpolicy.Policy,
+	credentialProviders *importer.CredentialProviders,
 ) *REST {
 	return &REST{
-		importFn:          importFn,
-		streams:           streams,
-		internalStreams:   internalStreams,
-		images:            images,
-		isV1Client:        isV1Client,
-		transport:         transport,
-		insecureTransport: insecureTransport,
-		strategy:          NewStrategy(registryWhitelister),
-		sarClient:         sarClient,
-		icspLister:        icspLister,
-		imageCfgV1Client:  imageCfgV1Client,
+		importFn:            importFn,
+		streams:             streams,
+		internalStreams:     internalStreams,
+		images:              images,
+		isV1Client:          isV1Client,
+		transport:           transport,
+		insecureTransport:   insecureTransport,
+		strategy:            NewStrategy(registryWhitelister),
+		sarClient:           sarClient,
+		icspLister:          icspLister,
+		imageCfgV1Client:    imageCfgV1Client,
+		labelPolicy:         labelPolicy,
+		credentialProviders: credentialProviders,
 	}
 }
 
@@ -114,6 +123,12 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		return nil, err
 	}
 
+	trace := utiltrace.New("ImageStreamImport",
+		utiltrace.Field{Key: "namespace", Value: apirequest.NamespaceValue(ctx)},
+		utiltrace.Field{Key: "name", Value: isi.Name})
+	defer trace.LogIfLong(10 * time.Second)
+	ctx = utiltrace.ContextWithTrace(ctx, trace)
+
 	// Check if the user is allowed to create Images or ImageStreamMappings.
 	// In case the user is allowed to create them, do not validate the ImageStreamImport
 	// registry location against the registry whitelist, but instead allow to create any
@@ -135,6 +150,7 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 	if err != nil {
 		return nil, err
 	}
+	trace.Step("Checked image create access")
 
 	createImageStreamMappingSAR := authorizationutil.AddUserToSAR(user, &authorizationapi.SubjectAccessReview{
 		Spec: authorizationapi.SubjectAccessReviewSpec{
@@ -161,6 +177,20 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		return nil, kapierrors.NewBadRequest("a namespace must be specified to import images")
 	}
 
+	insecureOverride := insecureOverrideRequested(isi)
+	if insecureOverride && !canOverrideInsecureImport(ctx, r.sarClient, user, namespace, isi.Name) {
+		return nil, kapierrors.NewForbidden(image.Resource("imagestreamimports"), isi.Name,
+			fmt.Errorf("the %s annotation requires permission to create the insecure-import subresource of the image stream", InsecureImportOverrideAnnotation))
+	}
+
+	// reject malformed tag selection annotations up front so a typo fails the
+	// request instead of silently importing every tag
+	if _, err := importer.ParseRepositoryTagSelection(isi.Annotations); err != nil {
+		return nil, kapierrors.NewInvalid(image.Kind("ImageStreamImport"), isi.Name, field.ErrorList{
+			field.Invalid(field.NewPath("metadata", "annotations"), "", err.Error()),
+		})
+	}
+
 	create := false
 	stream, err := r.streams.GetImageStream(ctx, isi.Name, &metav1.GetOptions{})
 	if err != nil {
@@ -217,14 +247,16 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 	if err != nil {
 		return nil, kapierrors.NewInternalError(err)
 	}
+	trace.Step("Resolved pull secrets", utiltrace.Field{Key: "secrets", Value: len(secretsList.Items)})
 
-	importCtx := importer.NewStaticCredentialsContext(
-		r.transport, r.insecureTransport, secretsList.Items,
+	importCtx := importer.NewStaticCredentialsContextWithProviders(
+		r.transport, r.insecureTransport, secretsList.Items, r.credentialProviders,
 	)
 	imports := r.importFn(importCtx, v2regConf)
 	if err := imports.Import(ctx, isi, stream); err != nil {
 		return nil, kapierrors.NewInternalError(err)
 	}
+	trace.Step("Completed registry import")
 
 	// check imported images status. If we get authentication error (401), try import same image without authentication.
 	// container image registry gives 401 on public images if you have wrong secret in your secret list.
@@ -248,6 +280,7 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		if err := imports.Import(ctx, isi, stream); err != nil {
 			return nil, kapierrors.NewInternalError(err)
 		}
+		trace.Step("Retried registry import without credentials")
 	}
 	//cycle through status and set old messages so not to confuse users
 	for key, image := range isi.Status.Images {
@@ -256,6 +289,10 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		}
 	}
 
+	// enforce the required-labels policy before the statuses are applied to
+	// the stream, so rejected images surface as ordinary import failures
+	r.applyRequiredLabelsPolicy(isi)
+
 	// TODO: perform the transformation of the image stream and return it with the ISI if import is false
 	//   so that clients can see what the resulting object would look like.
 	if !isi.Spec.Import {
@@ -337,6 +374,10 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		}
 	}
 
+	if insecureOverride {
+		restoreImportPolicies(stream, original)
+	}
+
 	clearManifests(isi)
 
 	// ensure defaulting is applied by round trip converting
@@ -371,6 +412,7 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 			obj, _, err = r.internalStreams.Update(ctx, stream.Name, rest.DefaultUpdatedObjectInfo(stream), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, false, &metav1.UpdateOptions{})
 		}
 	}
+	trace.Step("Persisted image stream and images")
 
 	if err != nil {
 		// if we have am admission limit error then record the conditions on the original stream.  Quota errors
@@ -400,6 +442,44 @@ func recordLimitExceededStatus(originalStream *imageapi.ImageStream, newStream *
 	}
 }
 
+// applyRequiredLabelsPolicy checks every imported image against the cluster's
+// required-labels policy. Violations are recorded in the import status: in
+// Warn mode the image is kept and the status message notes the missing
+// labels, in Reject mode the image's status becomes a failure and the image
+// is not tagged into the stream.
+func (r *REST) applyRequiredLabelsPolicy(isi *imageapi.ImageStreamImport) {
+	if !r.labelPolicy.Enabled() {
+		return
+	}
+	apply := func(statuses []imageapi.ImageImportStatus) {
+		for i := range statuses {
+			status := &statuses[i]
+			if status.Image == nil || status.Status.Status != metav1.StatusSuccess {
+				continue
+			}
+			missing := r.labelPolicy.MissingLabels(status.Image)
+			if len(missing) == 0 {
+				continue
+			}
+			message := labelpolicy.ViolationMessage(status.Image, missing)
+			if r.labelPolicy.Mode == labelpolicy.ModeReject {
+				status.Image = nil
+				status.Status = metav1.Status{
+					Status:  metav1.StatusFailure,
+					Reason:  metav1.StatusReasonForbidden,
+					Message: message,
+				}
+				continue
+			}
+			status.Status.Message = message
+		}
+	}
+	apply(isi.Status.Images)
+	if isi.Status.Repository != nil {
+		apply(isi.Status.Repository.Images)
+	}
+}
+
 func checkImportFailure(status imageapi.ImageImportStatus, stream *imageapi.ImageStream, tag string, nextGeneration int64, now metav1.Time) bool {
 	if status.Image != nil && status.Status.Status == metav1.StatusSuccess {
 		return false
@@ -436,6 +516,7 @@ func checkImportFailure(status imageapi.ImageImportStatus, stream *imageapi.Imag
 			stream.Spec.Tags[tag] = tagRef
 		}
 	}
+	recordImportAttempt(stream, tag, false, nextGeneration, now)
 	return true
 }
 
@@ -455,6 +536,29 @@ func hasTagCondition(stream *imageapi.ImageStream, tag string, condition imageap
 	return false
 }
 
+// hasTagEvent returns true if the status of the given tag records at least one event.
+func hasTagEvent(stream *imageapi.ImageStream, tag string) bool {
+	return len(stream.Status.Tags[tag].Items) > 0
+}
+
+// setTagCandidate records the imported event as the candidate of the given pinned
+// tag. An event matching what the tag currently points to clears the candidate
+// instead: the upstream moved back to the pinned image.
+func setTagCandidate(stream *imageapi.ImageStream, tag string, next imageapi.TagEvent) {
+	if stream.Status.Tags == nil {
+		stream.Status.Tags = make(map[string]imageapi.TagEventList)
+	}
+	tagEvents := stream.Status.Tags[tag]
+	if len(tagEvents.Items) > 0 &&
+		tagEvents.Items[0].DockerImageReference == next.DockerImageReference &&
+		tagEvents.Items[0].Image == next.Image {
+		tagEvents.Candidate = nil
+	} else {
+		tagEvents.Candidate = &next
+	}
+	stream.Status.Tags[tag] = tagEvents
+}
+
 // SetTagConditions applies the specified conditions to the status of the given tag.
 func setTagConditions(stream *imageapi.ImageStream, tag string, conditions ...imageapi.TagEventCondition) {
 	tagEvents := stream.Status.Tags[tag]
@@ -522,7 +626,15 @@ func (r *REST) importSuccessful(
 	// ensure the spec and status tag match the imported image
 	changed := differentTagEvent(stream, tag, tagEvent) || differentTagGeneration(stream, tag)
 	specTag, ok := stream.Spec.Tags[tag]
-	if changed || !ok {
+	if ok && specTag.Pinned && hasTagEvent(stream, tag) {
+		// a pinned tag that has already resolved never moves on import; the
+		// newest upstream image is recorded as the candidate of the status tag
+		// and the spec tag keeps its upstream reference until the candidate is
+		// promoted
+		if changed {
+			setTagCandidate(stream, tag, tagEvent)
+		}
+	} else if changed || !ok {
 		specTag = ensureSpecTag(stream, tag, from, importPolicy, referencePolicy, true)
 		internalimageutil.AddTagEventToImageStream(stream, tag, tagEvent)
 	}
@@ -537,6 +649,7 @@ func (r *REST) importSuccessful(
 	} else {
 		setTagConditions(stream, tag)
 	}
+	recordImportAttempt(stream, tag, importErr == nil, nextGeneration, now)
 
 	// create the image if it does not exist, otherwise cache the updated status from the store for use by other tags
 	if alreadyImported {
@@ -234,3 +234,73 @@ func TestImportSuccessful(t *testing.T) {
 		}
 	}
 }
+
+func TestImportSuccessfulPinnedTag(t *testing.T) {
+	const (
+		tag            = "stable"
+		oldDigest      = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		oldReference   = "registry.com/namespace/image@" + oldDigest
+		newDigest      = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		newReference   = "registry.com/namespace/image@" + newDigest
+		upstreamSource = "registry.com/namespace/image:stable"
+	)
+
+	one := int64(1)
+	two := int64(2)
+	now := metav1.Now()
+	image := &imageapi.Image{
+		ObjectMeta:           metav1.ObjectMeta{Name: newDigest},
+		DockerImageReference: newReference,
+	}
+	stream := &imageapi.ImageStream{
+		Spec: imageapi.ImageStreamSpec{
+			Tags: map[string]imageapi.TagReference{
+				tag: {
+					Name: tag,
+					From: &kapi.ObjectReference{
+						Kind: "DockerImage",
+						Name: upstreamSource,
+					},
+					Generation: &one,
+					Pinned:     true,
+				},
+			},
+		},
+		Status: imageapi.ImageStreamStatus{
+			Tags: map[string]imageapi.TagEventList{
+				tag: {
+					Items: []imageapi.TagEvent{{
+						DockerImageReference: oldReference,
+						Image:                oldDigest,
+						Generation:           one,
+					}},
+				},
+			},
+		},
+	}
+
+	ref, err := reference.Parse(image.DockerImageReference)
+	if err != nil {
+		t.Fatalf("error parsing image ref: %v", err)
+	}
+
+	storage := REST{images: fakeImageCreater{}}
+	_, ok := storage.importSuccessful(apirequest.NewDefaultContext(), image, stream,
+		tag, ref.Exact(), two, now, imageapi.TagImportPolicy{Scheduled: true},
+		imageapi.TagReferencePolicy{Type: imageapi.SourceTagReferencePolicy},
+		make(map[string]error), make(map[string]*imageapi.Image))
+	if !ok {
+		t.Fatal("expected success, didn't get one")
+	}
+
+	tagEvents := stream.Status.Tags[tag]
+	if len(tagEvents.Items) != 1 || tagEvents.Items[0].Image != oldDigest {
+		t.Errorf("expected the pinned tag to keep its event, got %#v", tagEvents.Items)
+	}
+	if tagEvents.Candidate == nil || tagEvents.Candidate.Image != newDigest {
+		t.Errorf("expected the imported image to be recorded as candidate, got %#v", tagEvents.Candidate)
+	}
+	if stream.Spec.Tags[tag].From.Name != upstreamSource {
+		t.Errorf("expected the upstream reference to be preserved, got %#v", stream.Spec.Tags[tag].From)
+	}
+}
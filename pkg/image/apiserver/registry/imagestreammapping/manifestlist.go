@@ -0,0 +1,100 @@
+package imagestreammapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/docker/distribution/manifest/manifestlist"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	imagereference "github.com/openshift/library-go/pkg/image/reference"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+const (
+	// SubManifestsAnnotation records the digests of the platform manifests of
+	// a manifest list, separated by commas.  It stands in for the
+	// DockerImageManifests field of newer API versions of Image.
+	SubManifestsAnnotation = "image.openshift.io/sub-manifests"
+
+	// ImagePlatformAnnotation records the os/architecture[/variant] platform
+	// string of a platform manifest that was created for an entry of a
+	// manifest list.
+	ImagePlatformAnnotation = "image.openshift.io/platform"
+)
+
+// isManifestList returns true if the pushed image carries a manifest list or
+// OCI image index rather than a single image manifest.
+func isManifestList(image *imageapi.Image) bool {
+	mediaType := image.DockerImageManifestMediaType
+	if len(mediaType) == 0 && len(image.DockerImageManifest) > 0 {
+		var envelope struct {
+			MediaType string `json:"mediaType"`
+		}
+		if err := json.Unmarshal([]byte(image.DockerImageManifest), &envelope); err == nil {
+			mediaType = envelope.MediaType
+		}
+	}
+	switch mediaType {
+	case manifestlist.MediaTypeManifestList, imgspecv1.MediaTypeImageIndex:
+		return true
+	}
+	return false
+}
+
+// childImagesForManifestList parses the manifest list carried by the parent
+// image and builds one Image per platform manifest.  The children carry no
+// manifest payload of their own - the registry serves those by digest - but
+// record their media type, platform and a by-digest pull reference derived
+// from the parent.  The digests of all children are recorded on the parent
+// in the SubManifestsAnnotation.
+func childImagesForManifestList(parent *imageapi.Image) ([]imageapi.Image, error) {
+	var list manifestlist.ManifestList
+	if err := json.Unmarshal([]byte(parent.DockerImageManifest), &list); err != nil {
+		return nil, fmt.Errorf("invalid manifest list: %v", err)
+	}
+	if len(list.Manifests) == 0 {
+		return nil, fmt.Errorf("manifest list contains no manifests")
+	}
+
+	ref, err := imagereference.Parse(parent.DockerImageReference)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker image reference %q: %v", parent.DockerImageReference, err)
+	}
+	ref.Tag = ""
+
+	var children []imageapi.Image
+	var digests []string
+	for _, descriptor := range list.Manifests {
+		switch descriptor.MediaType {
+		case manifestlist.MediaTypeManifestList, imgspecv1.MediaTypeImageIndex:
+			return nil, fmt.Errorf("nested manifest lists are not supported")
+		}
+		if err := descriptor.Digest.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid manifest digest %q: %v", descriptor.Digest, err)
+		}
+		ref.ID = descriptor.Digest.String()
+
+		child := imageapi.Image{}
+		child.Name = descriptor.Digest.String()
+		child.DockerImageReference = ref.Exact()
+		child.DockerImageManifestMediaType = descriptor.MediaType
+		if len(descriptor.Platform.OS) > 0 || len(descriptor.Platform.Architecture) > 0 {
+			platform := descriptor.Platform.OS + "/" + descriptor.Platform.Architecture
+			if len(descriptor.Platform.Variant) > 0 {
+				platform += "/" + descriptor.Platform.Variant
+			}
+			child.Annotations = map[string]string{ImagePlatformAnnotation: platform}
+		}
+		children = append(children, child)
+		digests = append(digests, descriptor.Digest.String())
+	}
+
+	if parent.Annotations == nil {
+		parent.Annotations = map[string]string{}
+	}
+	parent.Annotations[SubManifestsAnnotation] = strings.Join(digests, ",")
+
+	return children, nil
+}
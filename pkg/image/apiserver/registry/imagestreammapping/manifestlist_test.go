@@ -0,0 +1,204 @@
+package imagestreammapping
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/docker/distribution/manifest/manifestlist"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	etcdtesting "k8s.io/apiserver/pkg/storage/etcd3/testing"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+const (
+	testAmd64ManifestID = "sha256:958608f8ecc1dc62c93b6c610f3a834dae4220c9642e6e8b4e0f2b3ad7cbd238"
+	testArm64ManifestID = "sha256:4fd30fc9fd10f0d7691dcec267f1dd13cc31a7acd1a3008e9a9753b73a4b9a1f"
+	testManifestListID  = "sha256:d003da0b8a25f13fb756b26dd8c0b57ddd27d7f1a3ce2a1fc376f548478219c5"
+)
+
+func validManifestList() string {
+	return fmt.Sprintf(`{
+		"schemaVersion": 2,
+		"mediaType": %q,
+		"manifests": [
+			{
+				"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+				"digest": %q,
+				"size": 527,
+				"platform": {"architecture": "amd64", "os": "linux"}
+			},
+			{
+				"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+				"digest": %q,
+				"size": 527,
+				"platform": {"architecture": "arm64", "os": "linux", "variant": "v8"}
+			}
+		]
+	}`, manifestlist.MediaTypeManifestList, testAmd64ManifestID, testArm64ManifestID)
+}
+
+func validManifestListImage() *imageapi.Image {
+	return &imageapi.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testManifestListID,
+		},
+		DockerImageReference:         "localhost:5000/default/somerepo@" + testManifestListID,
+		DockerImageManifest:          validManifestList(),
+		DockerImageManifestMediaType: manifestlist.MediaTypeManifestList,
+	}
+}
+
+func TestIsManifestList(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    *imageapi.Image
+		expected bool
+	}{
+		{
+			name:     "explicit media type",
+			image:    validManifestListImage(),
+			expected: true,
+		},
+		{
+			name: "media type sniffed from manifest",
+			image: &imageapi.Image{
+				DockerImageManifest: validManifestList(),
+			},
+			expected: true,
+		},
+		{
+			name: "single image manifest",
+			image: &imageapi.Image{
+				DockerImageManifest:          `{"schemaVersion": 2, "mediaType": "application/vnd.docker.distribution.manifest.v2+json"}`,
+				DockerImageManifestMediaType: "application/vnd.docker.distribution.manifest.v2+json",
+			},
+			expected: false,
+		},
+		{
+			name:     "no manifest",
+			image:    &imageapi.Image{},
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		if actual := isManifestList(test.image); actual != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestChildImagesForManifestList(t *testing.T) {
+	parent := validManifestListImage()
+
+	children, err := childImagesForManifestList(parent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(children) != 2 {
+		t.Fatalf("expected 2 children, got %#v", children)
+	}
+	if e, a := testAmd64ManifestID, children[0].Name; e != a {
+		t.Errorf("child name: expected %s, got %s", e, a)
+	}
+	if e, a := "localhost:5000/default/somerepo@"+testAmd64ManifestID, children[0].DockerImageReference; e != a {
+		t.Errorf("child reference: expected %s, got %s", e, a)
+	}
+	if e, a := "linux/amd64", children[0].Annotations[ImagePlatformAnnotation]; e != a {
+		t.Errorf("child platform: expected %s, got %s", e, a)
+	}
+	if e, a := "linux/arm64/v8", children[1].Annotations[ImagePlatformAnnotation]; e != a {
+		t.Errorf("child platform: expected %s, got %s", e, a)
+	}
+	if e, a := testAmd64ManifestID+","+testArm64ManifestID, parent.Annotations[SubManifestsAnnotation]; e != a {
+		t.Errorf("parent sub-manifests annotation: expected %s, got %s", e, a)
+	}
+}
+
+func TestChildImagesForManifestListErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+	}{
+		{
+			name:     "empty list",
+			manifest: fmt.Sprintf(`{"schemaVersion": 2, "mediaType": %q, "manifests": []}`, manifestlist.MediaTypeManifestList),
+		},
+		{
+			name: "nested list",
+			manifest: fmt.Sprintf(`{"schemaVersion": 2, "mediaType": %q, "manifests": [
+				{"mediaType": %q, "digest": %q, "size": 527}
+			]}`, manifestlist.MediaTypeManifestList, manifestlist.MediaTypeManifestList, testAmd64ManifestID),
+		},
+		{
+			name:     "not json",
+			manifest: "not a manifest list",
+		},
+	}
+	for _, test := range tests {
+		parent := validManifestListImage()
+		parent.DockerImageManifest = test.manifest
+		if _, err := childImagesForManifestList(parent); err == nil {
+			t.Errorf("%s: expected error", test.name)
+		}
+	}
+}
+
+func TestCreateManifestListCreatesChildImages(t *testing.T) {
+	client, server, storage := setup(t)
+	defer server.Terminate(t)
+
+	initialRepo := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "somerepo"},
+	}
+
+	_, err := client.Put(
+		context.TODO(),
+		etcdtesting.AddPrefix("/imagestreams/default/somerepo"),
+		runtime.EncodeOrDie(legacyscheme.Codecs.LegacyCodec(imagev1.SchemeGroupVersion), initialRepo),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx := apirequest.WithUser(apirequest.NewDefaultContext(), &user.DefaultInfo{})
+
+	mapping := &imageapi.ImageStreamMapping{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "somerepo",
+		},
+		Image: *validManifestListImage(),
+		Tag:   "latest",
+	}
+	if _, err := storage.Create(ctx, mapping, rest.ValidateAllObjectFunc, &metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	parent, err := storage.imageRegistry.GetImage(ctx, testManifestListID, &metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error retrieving parent image: %v", err)
+	}
+	if e, a := testAmd64ManifestID+","+testArm64ManifestID, parent.Annotations[SubManifestsAnnotation]; e != a {
+		t.Errorf("parent sub-manifests annotation: expected %s, got %s", e, a)
+	}
+
+	for _, childID := range []string{testAmd64ManifestID, testArm64ManifestID} {
+		child, err := storage.imageRegistry.GetImage(ctx, childID, &metav1.GetOptions{})
+		if err != nil {
+			t.Errorf("Unexpected error retrieving child image %s: %v", childID, err)
+			continue
+		}
+		if e, a := "localhost:5000/default/somerepo@"+childID, child.DockerImageReference; e != a {
+			t.Errorf("child %s reference: expected %s, got %s", childID, e, a)
+		}
+	}
+}
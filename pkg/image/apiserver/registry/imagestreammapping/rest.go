@@ -13,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apiserver/pkg/registry/rest"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	kapihelper "k8s.io/kubernetes/pkg/apis/core/helper"
 
 	imagegroup "github.com/openshift/api/image"
@@ -20,6 +21,7 @@ import (
 	imagereference "github.com/openshift/library-go/pkg/image/reference"
 	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/internalimageutil"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/labelpolicy"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/image"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registryhostname"
@@ -33,20 +35,24 @@ const maxRetriesOnConflict = 10
 // image stream registry. It only supports the Create method and is used
 // to simplify adding a new Image and tag to an ImageStream.
 type REST struct {
-	imageRegistry       image.Registry
-	imageStreamRegistry imagestream.Registry
-	strategy            Strategy
+	imageRegistry             image.Registry
+	imageStreamRegistry       imagestream.Registry
+	subjectAccessReviewClient authorizationclient.SubjectAccessReviewInterface
+	strategy                  Strategy
+	labelPolicy               labelpolicy.Policy
 }
 
 var _ rest.Creater = &REST{}
 var _ rest.Scoper = &REST{}
 
 // NewREST returns a new REST.
-func NewREST(imageRegistry image.Registry, imageStreamRegistry imagestream.Registry, registry registryhostname.RegistryHostnameRetriever) *REST {
+func NewREST(imageRegistry image.Registry, imageStreamRegistry imagestream.Registry, subjectAccessReviewClient authorizationclient.SubjectAccessReviewInterface, registry registryhostname.RegistryHostnameRetriever, labelPolicy labelpolicy.Policy) *REST {
 	return &REST{
-		imageRegistry:       imageRegistry,
-		imageStreamRegistry: imageStreamRegistry,
-		strategy:            NewStrategy(registry),
+		imageRegistry:             imageRegistry,
+		imageStreamRegistry:       imageStreamRegistry,
+		subjectAccessReviewClient: subjectAccessReviewClient,
+		strategy:                  NewStrategy(registry),
+		labelPolicy:               labelPolicy,
 	}
 }
 
@@ -79,12 +85,39 @@ func (s *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		return nil, err
 	}
 
+	if err := imagestream.CheckFrozenStreamWrite(ctx, s.subjectAccessReviewClient, stream); err != nil {
+		return nil, err
+	}
+
 	image := mapping.Image
 	tag := mapping.Tag
 	if len(tag) == 0 {
 		tag = imagev1.DefaultImageTag
 	}
 
+	if missing := s.labelPolicy.MissingLabels(&image); len(missing) > 0 {
+		message := labelpolicy.ViolationMessage(&image, missing)
+		if s.labelPolicy.Mode == labelpolicy.ModeReject {
+			return nil, errors.NewForbidden(imagegroup.Resource("imagestreammappings"), image.Name, fmt.Errorf("%s", message))
+		}
+		klog.V(2).Infof("ImageStreamMapping %s/%s: %s", mapping.Namespace, mapping.Name, message)
+	}
+
+	// A manifest list points at per-platform manifests. Register those as
+	// Image objects in their own right so clients can resolve the child
+	// digests, and record them on the parent.
+	if isManifestList(&image) {
+		children, err := childImagesForManifestList(&image)
+		if err != nil {
+			return nil, errors.NewBadRequest(err.Error())
+		}
+		for i := range children {
+			if err := s.imageRegistry.CreateImage(ctx, &children[i]); err != nil && !errors.IsAlreadyExists(err) {
+				return nil, err
+			}
+		}
+	}
+
 	imageCreateErr := s.imageRegistry.CreateImage(ctx, &image)
 	if imageCreateErr != nil && !errors.IsAlreadyExists(imageCreateErr) {
 		return nil, imageCreateErr
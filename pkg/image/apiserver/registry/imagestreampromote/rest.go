@@ -0,0 +1,138 @@
+package imagestreampromote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/util/retry"
+
+	imagegroup "github.com/openshift/api/image"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/internalimageutil"
+)
+
+// PromoteResult is the response body of the promote subresource.
+type PromoteResult struct {
+	// Namespace and Name identify the ImageStream acted on.
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Tag is the tag the candidate was promoted on.
+	Tag string `json:"tag"`
+	// DockerImageReference is the pull spec the tag points to after promotion.
+	DockerImageReference string `json:"dockerImageReference"`
+	// Image is the name of the promoted image.
+	Image string `json:"image"`
+}
+
+// streamUpdater is the subset of the image stream registry the subresource
+// needs, narrowed so tests can supply a fake.
+type streamUpdater interface {
+	GetImageStream(ctx context.Context, id string, options *metav1.GetOptions) (*imageapi.ImageStream, error)
+	UpdateImageStreamStatus(ctx context.Context, repo *imageapi.ImageStream, forceAllowCreate bool, options *metav1.UpdateOptions) (*imageapi.ImageStream, error)
+}
+
+// REST implements the imagestreams/{name}/promote subresource. Posting to it
+// with a tag query parameter adopts the candidate recorded for a pinned tag:
+// the candidate becomes the newest tag event and is cleared. It is the second
+// half of the hold-and-promote workflow of pinned tags; imports record
+// candidates, this subresource moves the tag.
+type REST struct {
+	streams streamUpdater
+}
+
+var _ rest.Connecter = &REST{}
+
+// NewREST returns the handler for the imagestreams/{name}/promote
+// subresource.
+func NewREST(streams streamUpdater) *REST {
+	return &REST{streams: streams}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &imageapi.ImageStream{}
+}
+
+// ConnectMethods returns POST, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"POST"}
+}
+
+// NewConnectOptions returns an empty options object
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that promotes the candidate of the tag named by
+// the tag query parameter.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	namespace, ok := apirequest.NamespaceFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewBadRequest("a namespace must be specified")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tag := req.URL.Query().Get("tag")
+		if len(tag) == 0 {
+			responder.Error(kapierrors.NewBadRequest("a tag query parameter must be specified"))
+			return
+		}
+
+		result, err := r.promote(ctx, namespace, name, tag)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+// promote adopts the candidate of the given tag as its newest tag event,
+// retrying on conflicts so a concurrent import does not fail the request.
+func (r *REST) promote(ctx context.Context, namespace, name, tag string) (*PromoteResult, error) {
+	var result *PromoteResult
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		stream, err := r.streams.GetImageStream(ctx, name, &metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		tagEvents, ok := stream.Status.Tags[tag]
+		if !ok {
+			return kapierrors.NewNotFound(imagegroup.Resource("imagestreamtags"), imageutil.JoinImageStreamTag(name, tag))
+		}
+		if tagEvents.Candidate == nil {
+			return kapierrors.NewBadRequest(fmt.Sprintf("tag %q of image stream %s/%s has no candidate to promote", tag, namespace, name))
+		}
+
+		event := *tagEvents.Candidate
+		tagEvents.Candidate = nil
+		stream.Status.Tags[tag] = tagEvents
+		internalimageutil.AddTagEventToImageStream(stream, tag, event)
+
+		if _, err := r.streams.UpdateImageStreamStatus(ctx, stream, false, &metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		result = &PromoteResult{
+			Namespace:            namespace,
+			Name:                 name,
+			Tag:                  tag,
+			DockerImageReference: event.DockerImageReference,
+			Image:                event.Image,
+		}
+		return nil
+	})
+	return result, err
+}
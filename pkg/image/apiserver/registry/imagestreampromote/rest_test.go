@@ -0,0 +1,137 @@
+package imagestreampromote
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	imagegroup "github.com/openshift/api/image"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+type fakeStreamUpdater struct {
+	stream  *imageapi.ImageStream
+	updated *imageapi.ImageStream
+}
+
+func (f *fakeStreamUpdater) GetImageStream(ctx context.Context, id string, options *metav1.GetOptions) (*imageapi.ImageStream, error) {
+	if f.stream == nil || f.stream.Name != id {
+		return nil, kapierrors.NewNotFound(imagegroup.Resource("imagestreams"), id)
+	}
+	return f.stream.DeepCopy(), nil
+}
+
+func (f *fakeStreamUpdater) UpdateImageStreamStatus(ctx context.Context, repo *imageapi.ImageStream, forceAllowCreate bool, options *metav1.UpdateOptions) (*imageapi.ImageStream, error) {
+	f.updated = repo
+	return repo, nil
+}
+
+type fakeResponder struct {
+	err error
+}
+
+func (r *fakeResponder) Object(statusCode int, obj runtime.Object) {}
+
+func (r *fakeResponder) Error(err error) {
+	r.err = err
+}
+
+func pinnedStream() *imageapi.ImageStream {
+	return &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "release"},
+		Spec: imageapi.ImageStreamSpec{
+			Tags: map[string]imageapi.TagReference{
+				"stable": {Name: "stable", Pinned: true},
+			},
+		},
+		Status: imageapi.ImageStreamStatus{
+			Tags: map[string]imageapi.TagEventList{
+				"stable": {
+					Items: []imageapi.TagEvent{
+						{DockerImageReference: "registry.example.com/repo@sha256:old", Image: "sha256:old", Generation: 1},
+					},
+					Candidate: &imageapi.TagEvent{DockerImageReference: "registry.example.com/repo@sha256:new", Image: "sha256:new", Generation: 2},
+				},
+			},
+		},
+	}
+}
+
+func TestPromoteAdoptsTheCandidate(t *testing.T) {
+	streams := &fakeStreamUpdater{stream: pinnedStream()}
+	ctx := apirequest.WithNamespace(context.Background(), "ns")
+
+	responder := &fakeResponder{}
+	handler, err := NewREST(streams).Connect(ctx, "release", nil, responder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/promote?tag=stable", nil))
+	if responder.err != nil {
+		t.Fatalf("unexpected error: %v", responder.err)
+	}
+
+	result := PromoteResult{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("unable to decode response %q: %v", recorder.Body.String(), err)
+	}
+	if result.Tag != "stable" || result.Image != "sha256:new" {
+		t.Errorf("unexpected result: %#v", result)
+	}
+
+	if streams.updated == nil {
+		t.Fatal("expected the stream status to be updated")
+	}
+	tagEvents := streams.updated.Status.Tags["stable"]
+	if tagEvents.Candidate != nil {
+		t.Errorf("expected the candidate to be cleared, got %#v", tagEvents.Candidate)
+	}
+	if len(tagEvents.Items) != 2 || tagEvents.Items[0].Image != "sha256:new" {
+		t.Errorf("expected the candidate to become the newest tag event, got %#v", tagEvents.Items)
+	}
+}
+
+func TestPromoteWithoutCandidateFails(t *testing.T) {
+	stream := pinnedStream()
+	stream.Status.Tags["stable"] = imageapi.TagEventList{Items: stream.Status.Tags["stable"].Items}
+	streams := &fakeStreamUpdater{stream: stream}
+	ctx := apirequest.WithNamespace(context.Background(), "ns")
+
+	responder := &fakeResponder{}
+	handler, err := NewREST(streams).Connect(ctx, "release", nil, responder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/promote?tag=stable", nil))
+	if responder.err == nil || !kapierrors.IsBadRequest(responder.err) {
+		t.Fatalf("expected a bad request error, got %v", responder.err)
+	}
+	if streams.updated != nil {
+		t.Errorf("did not expect a status update, got %#v", streams.updated)
+	}
+}
+
+func TestPromoteRequiresTheTagParameter(t *testing.T) {
+	streams := &fakeStreamUpdater{stream: pinnedStream()}
+	ctx := apirequest.WithNamespace(context.Background(), "ns")
+
+	responder := &fakeResponder{}
+	handler, err := NewREST(streams).Connect(ctx, "release", nil, responder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/promote", nil))
+	if responder.err == nil || !kapierrors.IsBadRequest(responder.err) {
+		t.Fatalf("expected a bad request error, got %v", responder.err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 	"k8s.io/kubernetes/pkg/printers"
@@ -24,6 +25,7 @@ import (
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/internalimageutil"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/image"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registryhostname"
 	imageprinters "github.com/openshift/openshift-apiserver/pkg/image/printers/internalversion"
 )
 
@@ -32,24 +34,41 @@ import (
 type REST struct {
 	imageRegistry       image.Registry
 	imageStreamRegistry imagestream.Registry
+	hostnames           registryhostname.RegistryHostnameRetriever
 	strategy            Strategy
 	rest.TableConvertor
 }
 
 // NewREST returns a new REST.
-func NewREST(imageRegistry image.Registry, imageStreamRegistry imagestream.Registry, registryWhitelister whitelist.RegistryWhitelister) *REST {
+func NewREST(imageRegistry image.Registry, imageStreamRegistry imagestream.Registry, registryWhitelister whitelist.RegistryWhitelister, hostnames registryhostname.RegistryHostnameRetriever) *REST {
 	return &REST{
 		imageRegistry:       imageRegistry,
 		imageStreamRegistry: imageStreamRegistry,
+		hostnames:           hostnames,
 		strategy:            NewStrategy(registryWhitelister),
 		TableConvertor:      printerstorage.TableConvertor{TableGenerator: printers.NewTableGenerator().With(imageprinters.AddImageOpenShiftHandlers)},
 	}
 }
 
+// registryAliases returns every hostname the integrated registry is known
+// under, so references recorded under a previous registry hostname are still
+// resolved to local pullthrough specs. A nil retriever disables rewriting.
+func (r *REST) registryAliases(ctx context.Context) []string {
+	if r.hostnames == nil {
+		return nil
+	}
+	var aliases []string
+	if internal, ok := r.hostnames.InternalRegistryHostname(ctx); ok {
+		aliases = append(aliases, internal)
+	}
+	return append(aliases, r.hostnames.ExternalRegistryHostnames()...)
+}
+
 var _ rest.Getter = &REST{}
 var _ rest.Lister = &REST{}
 var _ rest.CreaterUpdater = &REST{}
 var _ rest.GracefulDeleter = &REST{}
+var _ rest.CollectionDeleter = &REST{}
 var _ rest.ShortNamesProvider = &REST{}
 var _ rest.Scoper = &REST{}
 
@@ -83,7 +102,11 @@ func nameAndTag(id string) (name string, tag string, err error) {
 }
 
 func (r *REST) List(ctx context.Context, options *metainternal.ListOptions) (runtime.Object, error) {
-	imageStreams, err := r.imageStreamRegistry.ListImageStreams(ctx, options)
+	limit, continueKey, streamOptions, err := paginationFromListOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	imageStreams, err := r.imageStreamRegistry.ListImageStreams(ctx, streamOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -91,9 +114,15 @@ func (r *REST) List(ctx context.Context, options *metainternal.ListOptions) (run
 	matcher := MatchImageStreamTag(apihelpers.InternalListOptionsToSelectors(options))
 
 	list := &imageapi.ImageStreamTagList{}
+	list.ResourceVersion = imageStreams.ResourceVersion
+	lastKey := ""
 	for _, currIS := range imageStreams.Items {
-		for currTag := range currIS.Status.Tags {
-			istag, err := newISTag(currTag, &currIS, nil, false)
+		for _, currTag := range sets.StringKeySet(currIS.Status.Tags).List() {
+			key := apihelpers.ContinueTokenKey(currIS.Namespace, currIS.Name, currTag)
+			if key <= continueKey {
+				continue
+			}
+			istag, err := newISTag(currTag, &currIS, nil, false, r.registryAliases(ctx))
 			if err != nil {
 				if kapierrors.IsNotFound(err) {
 					continue
@@ -106,7 +135,14 @@ func (r *REST) List(ctx context.Context, options *metainternal.ListOptions) (run
 			}
 
 			if matches {
+				if limit > 0 && int64(len(list.Items)) >= limit {
+					// another item exists beyond the page, so the client must
+					// continue from the last item returned
+					list.Continue = apihelpers.EncodeContinueToken(lastKey)
+					return list, nil
+				}
 				list.Items = append(list.Items, *istag)
+				lastKey = key
 			}
 		}
 	}
@@ -114,6 +150,33 @@ func (r *REST) List(ctx context.Context, options *metainternal.ListOptions) (run
 	return list, nil
 }
 
+// paginationFromListOptions extracts the limit and decoded continue position
+// from options and returns the options to list the backing image streams
+// with.  Tags are materialized from whole streams, so the stream list itself
+// must not be chunked; limit and continue are applied to the emitted tags
+// instead.
+func paginationFromListOptions(options *metainternal.ListOptions) (int64, string, *metainternal.ListOptions, error) {
+	if options == nil {
+		return 0, "", nil, nil
+	}
+	continueKey := ""
+	if len(options.Continue) > 0 {
+		key, err := apihelpers.DecodeContinueToken(options.Continue)
+		if err != nil {
+			return 0, "", nil, kapierrors.NewBadRequest(err.Error())
+		}
+		continueKey = key
+	}
+	streamOptions := options
+	if options.Limit > 0 || len(options.Continue) > 0 {
+		copied := *options
+		copied.Limit = 0
+		copied.Continue = ""
+		streamOptions = &copied
+	}
+	return options.Limit, continueKey, streamOptions, nil
+}
+
 // Get retrieves an image that has been tagged by stream and tag. `id` is of the format <stream name>:<tag>.
 func (r *REST) Get(ctx context.Context, id string, options *metav1.GetOptions) (runtime.Object, error) {
 	name, tag, err := nameAndTag(id)
@@ -123,6 +186,9 @@ func (r *REST) Get(ctx context.Context, id string, options *metav1.GetOptions) (
 
 	imageStream, err := r.imageStreamRegistry.GetImageStream(ctx, name, options)
 	if err != nil {
+		if kapierrors.IsNotFound(err) {
+			return nil, internalimageutil.NewNotFound(imagegroup.Resource("imagestreamtags"), id, internalimageutil.MissingImageStreamCause)
+		}
 		return nil, err
 	}
 
@@ -131,7 +197,7 @@ func (r *REST) Get(ctx context.Context, id string, options *metav1.GetOptions) (
 		return nil, err
 	}
 
-	return newISTag(tag, imageStream, image, false)
+	return newISTag(tag, imageStream, image, false, r.registryAliases(ctx))
 }
 
 func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
@@ -180,8 +246,8 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		if exists {
 			return nil, kapierrors.NewAlreadyExists(imagegroup.Resource("imagestreamtag"), istag.Name)
 		}
-		if istag.Tag != nil {
-			target.Spec.Tags[imageTag] = *istag.Tag
+		if tagRef := createTagReference(istag, imageTag); tagRef != nil {
+			target.Spec.Tags[imageTag] = *tagRef
 		}
 
 		// Check the stream creation timestamp and make sure we will not
@@ -198,13 +264,35 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 			return nil, err
 		}
 		image, _ := r.imageFor(ctx, imageTag, target)
-		return newISTag(imageTag, target, image, true)
+		return newISTag(imageTag, target, image, true, r.registryAliases(ctx))
 	}
 	// We tried to update resource, but we kept conflicting. Inform the client that we couldn't complete
 	// the operation but that they may try again.
 	return nil, kapierrors.NewServerTimeout(imagegroup.Resource("imagestreamtags"), "create", 2)
 }
 
+// createTagReference builds the spec tag a Create request records on the
+// image stream: the full tag reference of the request, including its import
+// and reference policies, with the object's annotations applied on top. This
+// lets a single create carry everything `oc tag` needs instead of requiring a
+// follow-up image stream update that frequently conflicts in automation. A
+// request carrying neither a tag nor annotations records nothing, preserving
+// the historical behavior.
+func createTagReference(istag *imageapi.ImageStreamTag, tag string) *imageapi.TagReference {
+	if istag.Tag == nil && len(istag.Annotations) == 0 {
+		return nil
+	}
+	tagRef := imageapi.TagReference{}
+	if istag.Tag != nil {
+		tagRef = *istag.Tag
+	}
+	tagRef.Name = tag
+	if len(istag.Annotations) > 0 {
+		tagRef.Annotations = istag.Annotations
+	}
+	return &tagRef
+}
+
 func (r *REST) Update(ctx context.Context, tagName string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
 	name, tag, err := nameAndTag(tagName)
 	if err != nil {
@@ -232,7 +320,7 @@ func (r *REST) Update(ctx context.Context, tagName string, objInfo rest.UpdatedO
 	}
 
 	// create the synthetic old istag
-	old, err := newISTag(tag, imageStream, nil, true)
+	old, err := newISTag(tag, imageStream, nil, true, r.registryAliases(ctx))
 	if err != nil {
 		return nil, false, err
 	}
@@ -318,7 +406,7 @@ func (r *REST) Update(ctx context.Context, tagName string, objInfo rest.UpdatedO
 		}
 	}
 
-	newISTag, err := newISTag(tag, newImageStream, image, true)
+	newISTag, err := newISTag(tag, newImageStream, image, true, r.registryAliases(ctx))
 	return newISTag, !exists, err
 }
 
@@ -331,6 +419,11 @@ func (r *REST) Delete(ctx context.Context, id string, objectFunc rest.ValidateOb
 		return nil, false, err
 	}
 
+	// observedGeneration tracks the newest event generation seen for the tag so
+	// a conflicting concurrent import can be reported as such instead of being
+	// silently retried over
+	observedGeneration := int64(-1)
+
 	for i := 10; i > 0; i-- {
 		stream, err := r.imageStreamRegistry.GetImageStream(ctx, name, &metav1.GetOptions{})
 		if err != nil {
@@ -344,6 +437,20 @@ func (r *REST) Delete(ctx context.Context, id string, objectFunc rest.ValidateOb
 			}
 		}
 
+		latestGeneration := int64(0)
+		for _, event := range stream.Status.Tags[tag].Items {
+			if event.Generation > latestGeneration {
+				latestGeneration = event.Generation
+			}
+		}
+		if observedGeneration >= 0 && latestGeneration > observedGeneration {
+			// a concurrent import moved the tag while we were retrying; make the
+			// caller decide whether the newer image should be removed as well
+			return nil, false, kapierrors.NewConflict(imagegroup.Resource("imagestreamtags"), id,
+				fmt.Errorf("the tag was updated by a concurrent import (generation %d is newer than %d); retry the delete if the newer image should be removed as well", latestGeneration, observedGeneration))
+		}
+		observedGeneration = latestGeneration
+
 		notFound := true
 
 		// Try to delete the status tag
@@ -362,6 +469,10 @@ func (r *REST) Delete(ctx context.Context, id string, objectFunc rest.ValidateOb
 			return nil, false, kapierrors.NewNotFound(imagegroup.Resource("imagestreamtags"), id)
 		}
 
+		// record delete intent so in-flight status writes that read the stream
+		// before this delete committed cannot resurrect the tag
+		imagestream.SetTagDeleteIntent(stream, tag, stream.Generation+1)
+
 		_, err = r.imageStreamRegistry.UpdateImageStream(ctx, stream, false, &metav1.UpdateOptions{})
 		if kapierrors.IsConflict(err) {
 			continue
@@ -376,26 +487,144 @@ func (r *REST) Delete(ctx context.Context, id string, objectFunc rest.ValidateOb
 	return nil, false, kapierrors.NewServerTimeout(imagegroup.Resource("imagestreamtags"), "delete", 2)
 }
 
+// DeleteCollection removes every tag matching the list selectors and returns
+// the removed tags.  Matching tags are grouped by image stream so that
+// clearing many stale tags from one stream is a single read-modify-write
+// instead of one conflicting update per tag.  The images the tags point to
+// are *not* deleted.
+func (r *REST) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions, listOptions *metainternal.ListOptions) (runtime.Object, error) {
+	if listOptions == nil {
+		listOptions = &metainternal.ListOptions{}
+	}
+	matcher := MatchImageStreamTag(apihelpers.InternalListOptionsToSelectors(listOptions))
+
+	imageStreams, err := r.imageStreamRegistry.ListImageStreams(ctx, &metainternal.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := &imageapi.ImageStreamTagList{}
+	for i := range imageStreams.Items {
+		stream := &imageStreams.Items[i]
+		var tags []string
+		for _, tag := range sets.StringKeySet(stream.Status.Tags).List() {
+			istag, err := newISTag(tag, stream, nil, false, r.registryAliases(ctx))
+			if err != nil {
+				if kapierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			matches, err := matcher.Matches(istag)
+			if err != nil {
+				return nil, err
+			}
+			if !matches {
+				continue
+			}
+			if deleteValidation != nil {
+				if err := deleteValidation(ctx, istag); err != nil {
+					return nil, err
+				}
+			}
+			tags = append(tags, tag)
+			deleted.Items = append(deleted.Items, *istag)
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		if err := r.deleteTagsFromStream(ctx, stream.Name, tags, options); err != nil {
+			return nil, err
+		}
+	}
+	return deleted, nil
+}
+
+// deleteTagsFromStream removes the given tags from the named stream in one
+// update, retrying on conflicts.  Tags or streams removed concurrently by
+// another caller are treated as already deleted.
+func (r *REST) deleteTagsFromStream(ctx context.Context, name string, tags []string, options *metav1.DeleteOptions) error {
+	for i := 10; i > 0; i-- {
+		stream, err := r.imageStreamRegistry.GetImageStream(ctx, name, &metav1.GetOptions{})
+		if err != nil {
+			if kapierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if options != nil {
+			if pre := options.Preconditions; pre != nil {
+				if pre.UID != nil && *pre.UID != stream.UID {
+					return kapierrors.NewConflict(imagegroup.Resource("imagestreamtags"), name, fmt.Errorf("the UID precondition was not met"))
+				}
+			}
+		}
+
+		changed := false
+		for _, tag := range tags {
+			removed := false
+			if _, ok := stream.Status.Tags[tag]; ok {
+				delete(stream.Status.Tags, tag)
+				removed = true
+			}
+			if _, ok := stream.Spec.Tags[tag]; ok {
+				delete(stream.Spec.Tags, tag)
+				removed = true
+			}
+			if !removed {
+				continue
+			}
+			changed = true
+			// record delete intent so in-flight status writes that read the
+			// stream before this delete committed cannot resurrect the tag
+			imagestream.SetTagDeleteIntent(stream, tag, stream.Generation+1)
+		}
+		if !changed {
+			return nil
+		}
+
+		_, err = r.imageStreamRegistry.UpdateImageStream(ctx, stream, false, &metav1.UpdateOptions{})
+		if kapierrors.IsConflict(err) {
+			continue
+		}
+		if err != nil && !kapierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	// We tried to update resource, but we kept conflicting. Inform the client that we couldn't complete
+	// the operation but that they may try again.
+	return kapierrors.NewServerTimeout(imagegroup.Resource("imagestreamtags"), "deletecollection", 2)
+}
+
 // imageFor retrieves the most recent image for a tag in a given imageStreem.
 func (r *REST) imageFor(ctx context.Context, tag string, imageStream *imageapi.ImageStream) (*imageapi.Image, error) {
+	istagName := imageutil.JoinImageStreamTag(imageStream.Name, tag)
 	event := internalimageutil.LatestTaggedImage(imageStream, tag)
 	if event == nil || len(event.Image) == 0 {
-		return nil, kapierrors.NewNotFound(imagegroup.Resource("imagestreamtags"), imageutil.JoinImageStreamTag(imageStream.Name, tag))
+		return nil, internalimageutil.NewNotFound(imagegroup.Resource("imagestreamtags"), istagName, internalimageutil.MissingTagCause)
 	}
 
-	return r.imageRegistry.GetImage(ctx, event.Image, &metav1.GetOptions{})
+	image, err := r.imageRegistry.GetImage(ctx, event.Image, &metav1.GetOptions{})
+	if err != nil {
+		if kapierrors.IsNotFound(err) {
+			return nil, internalimageutil.NewNotFound(imagegroup.Resource("imagestreamtags"), istagName, internalimageutil.MissingImageCause)
+		}
+		return nil, err
+	}
+	return image, nil
 }
 
 // newISTag initializes an image stream tag from an image stream and image. The allowEmptyEvent will create a tag even
 // in the event that the status tag does does not exist yet (no image has successfully been tagged) or the image is nil.
-func newISTag(tag string, imageStream *imageapi.ImageStream, image *imageapi.Image, allowEmptyEvent bool) (*imageapi.ImageStreamTag, error) {
+func newISTag(tag string, imageStream *imageapi.ImageStream, image *imageapi.Image, allowEmptyEvent bool, registryAliases []string) (*imageapi.ImageStreamTag, error) {
 	istagName := imageutil.JoinImageStreamTag(imageStream.Name, tag)
 
 	event := internalimageutil.LatestTaggedImage(imageStream, tag)
 	if event == nil || len(event.Image) == 0 {
 		if !allowEmptyEvent {
 			klog.V(4).Infof("did not find tag %s in image stream status tags: %#v", tag, imageStream.Status.Tags)
-			return nil, kapierrors.NewNotFound(imagegroup.Resource("imagestreamtags"), istagName)
+			return nil, internalimageutil.NewNotFound(imagegroup.Resource("imagestreamtags"), istagName, internalimageutil.MissingTagCause)
 		}
 		event = &imageapi.TagEvent{
 			Created: imageStream.CreationTimestamp,
@@ -457,6 +686,6 @@ func newISTag(tag string, imageStream *imageapi.ImageStream, image *imageapi.Ima
 		ist.Image.Name = event.Image
 	}
 
-	ist.Image.DockerImageReference = internalimageutil.ResolveReferenceForTagEvent(imageStream, tag, event)
+	ist.Image.DockerImageReference = internalimageutil.ResolveReferenceForTagEventWithAliases(imageStream, tag, event, registryAliases)
 	return ist, nil
 }
@@ -9,7 +9,9 @@ import (
 	etcd "go.etcd.io/etcd/clientv3"
 	authorizationapi "k8s.io/api/authorization/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apiserver/pkg/authentication/user"
@@ -24,6 +26,7 @@ import (
 	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
 	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/fake"
 	admfake "github.com/openshift/openshift-apiserver/pkg/image/apiserver/admission/fake"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/internalimageutil"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/image"
 	imageetcd "github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/image/etcd"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream"
@@ -87,6 +90,7 @@ func setup(t *testing.T) (etcd.KV, *etcdtesting.EtcdTestServer, *REST) {
 		&admfake.ImageStreamLimitVerifier{},
 		rw,
 		imagestreametcd.NewEmptyLayerIndex(),
+		0,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -95,7 +99,7 @@ func setup(t *testing.T) (etcd.KV, *etcdtesting.EtcdTestServer, *REST) {
 	imageRegistry := image.NewRegistry(imageStorage)
 	imageStreamRegistry := imagestream.NewRegistry(imageStreamStorage, imageStreamStatus, internalStorage)
 
-	storage := NewREST(imageRegistry, imageStreamRegistry, rw)
+	storage := NewREST(imageRegistry, imageStreamRegistry, rw, registry)
 
 	return etcdClient, server, storage
 }
@@ -111,6 +115,7 @@ func TestGetImageStreamTag(t *testing.T) {
 		expectError     bool
 		errorTargetKind string
 		errorTargetID   string
+		errorCause      string
 	}{
 		"happy path": {
 			image: &imageapi.Image{ObjectMeta: metav1.ObjectMeta{Name: "10"}, DockerImageReference: "foo/bar/baz"},
@@ -155,21 +160,26 @@ func TestGetImageStreamTag(t *testing.T) {
 			expectError:     true,
 			errorTargetKind: "imagestreamtags",
 			errorTargetID:   "test:latest",
+			errorCause:      internalimageutil.MissingTagCause,
 		},
 		"missing image": {
-			repo: &imageapi.ImageStream{Status: imageapi.ImageStreamStatus{
-				Tags: map[string]imageapi.TagEventList{
-					"latest": {Items: []imageapi.TagEvent{{DockerImageReference: "test", Image: "10"}}},
-				},
-			}},
+			repo: &imageapi.ImageStream{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Status: imageapi.ImageStreamStatus{
+					Tags: map[string]imageapi.TagEventList{
+						"latest": {Items: []imageapi.TagEvent{{DockerImageReference: "test", Image: "10"}}},
+					},
+				}},
 			expectError:     true,
-			errorTargetKind: "images",
-			errorTargetID:   "10",
+			errorTargetKind: "imagestreamtags",
+			errorTargetID:   "test:latest",
+			errorCause:      internalimageutil.MissingImageCause,
 		},
 		"missing repo": {
 			expectError:     true,
-			errorTargetKind: "imagestreams",
-			errorTargetID:   "test",
+			errorTargetKind: "imagestreamtags",
+			errorTargetID:   "test:latest",
+			errorCause:      internalimageutil.MissingImageStreamCause,
 		},
 		"missing tag": {
 			image: &imageapi.Image{ObjectMeta: metav1.ObjectMeta{Name: "10"}, DockerImageReference: "foo/bar/baz"},
@@ -183,6 +193,7 @@ func TestGetImageStreamTag(t *testing.T) {
 			expectError:     true,
 			errorTargetKind: "imagestreamtags",
 			errorTargetID:   "test:latest",
+			errorCause:      internalimageutil.MissingTagCause,
 		},
 	}
 
@@ -222,6 +233,12 @@ func TestGetImageStreamTag(t *testing.T) {
 					t.Errorf("%s: unexpected status: %#v", name, status.Details)
 					return
 				}
+				if len(testCase.errorCause) > 0 {
+					if len(status.Details.Causes) != 1 || status.Details.Causes[0].Field != testCase.errorCause {
+						t.Errorf("%s: unexpected causes: %#v", name, status.Details.Causes)
+						return
+					}
+				}
 			} else {
 				actual := obj.(*imageapi.ImageStreamTag)
 				if e, a := "default", actual.Namespace; e != a {
@@ -286,6 +303,86 @@ func TestGetImageStreamTagDIR(t *testing.T) {
 	}
 }
 
+func TestListImageStreamTagPagination(t *testing.T) {
+	client, server, storage := setup(t)
+	defer server.Terminate(t)
+
+	// "app" is a prefix of "app-db" and "app" exists in two namespaces, so a
+	// continue token must resume on the full (namespace, name, tag) position
+	// rather than on a flat "name:tag" comparison
+	repos := []imageapi.ImageStream{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+			Status: imageapi.ImageStreamStatus{
+				Tags: map[string]imageapi.TagEventList{
+					"latest": {Items: []imageapi.TagEvent{{DockerImageReference: "test", Image: "10"}}},
+					"v1":     {Items: []imageapi.TagEvent{{DockerImageReference: "test", Image: "10"}}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app-db"},
+			Status: imageapi.ImageStreamStatus{
+				Tags: map[string]imageapi.TagEventList{
+					"latest": {Items: []imageapi.TagEvent{{DockerImageReference: "test", Image: "10"}}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "app"},
+			Status: imageapi.ImageStreamStatus{
+				Tags: map[string]imageapi.TagEventList{
+					"latest": {Items: []imageapi.TagEvent{{DockerImageReference: "test", Image: "10"}}},
+				},
+			},
+		},
+	}
+	for _, repo := range repos {
+		client.Put(
+			context.TODO(),
+			etcdtesting.AddPrefix("/imagestreams/"+repo.Namespace+"/"+repo.Name),
+			runtime.EncodeOrDie(legacyscheme.Codecs.LegacyCodec(imagev1.SchemeGroupVersion), &repo),
+		)
+	}
+
+	paginate := func(ctx context.Context, limit int64) []string {
+		var names []string
+		options := &metainternalversion.ListOptions{Limit: limit}
+		for page := 0; ; page++ {
+			obj, err := storage.List(ctx, options)
+			if err != nil {
+				t.Fatalf("unexpected error on page %d: %v", page, err)
+			}
+			list := obj.(*imageapi.ImageStreamTagList)
+			if int64(len(list.Items)) > options.Limit {
+				t.Fatalf("page %d exceeds limit: %d items", page, len(list.Items))
+			}
+			for _, item := range list.Items {
+				names = append(names, item.Namespace+"/"+item.Name)
+			}
+			if len(list.Continue) == 0 {
+				break
+			}
+			options.Continue = list.Continue
+		}
+		return names
+	}
+
+	expected := []string{"default/app:latest", "default/app:v1", "default/app-db:latest"}
+	if names := paginate(apirequest.NewDefaultContext(), 1); !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	expectedAll := []string{"default/app:latest", "default/app:v1", "default/app-db:latest", "other/app:latest"}
+	if names := paginate(apirequest.NewContext(), 1); !reflect.DeepEqual(expectedAll, names) {
+		t.Errorf("expected %v, got %v", expectedAll, names)
+	}
+
+	if _, err := storage.List(apirequest.NewDefaultContext(), &metainternalversion.ListOptions{Continue: "not-a-token!"}); !errors.IsBadRequest(err) {
+		t.Errorf("expected bad request for malformed continue token, got %v", err)
+	}
+}
+
 func TestDeleteImageStreamTag(t *testing.T) {
 	tests := map[string]struct {
 		repo        *imageapi.ImageStream
@@ -482,6 +579,83 @@ func TestDeleteImageStreamTag(t *testing.T) {
 	}
 }
 
+func TestDeleteCollectionImageStreamTags(t *testing.T) {
+	client, server, storage := setup(t)
+	defer server.Terminate(t)
+
+	event := imageapi.TagEvent{
+		DockerImageReference: "registry.default.local/default/test@sha256:381151ac5b7f775e8371e489f3479b84a4c004c90ceddb2ad80b6877215a892f",
+		Image:                "sha256:381151ac5b7f775e8371e489f3479b84a4c004c90ceddb2ad80b6877215a892f",
+		Generation:           2,
+	}
+	repos := []imageapi.ImageStream{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "stale", Labels: map[string]string{"lifecycle": "temporary"}},
+			Spec: imageapi.ImageStreamSpec{
+				Tags: map[string]imageapi.TagReference{
+					"latest": {From: &kapi.ObjectReference{Kind: "ImageStreamTag", Name: "other:latest"}},
+				},
+			},
+			Status: imageapi.ImageStreamStatus{
+				Tags: map[string]imageapi.TagEventList{
+					"latest": {Items: []imageapi.TagEvent{event}},
+					"v1":     {Items: []imageapi.TagEvent{event}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kept"},
+			Status: imageapi.ImageStreamStatus{
+				Tags: map[string]imageapi.TagEventList{
+					"latest": {Items: []imageapi.TagEvent{event}},
+				},
+			},
+		},
+	}
+	for _, repo := range repos {
+		client.Put(
+			context.TODO(),
+			etcdtesting.AddPrefix("/imagestreams/default/"+repo.Name),
+			runtime.EncodeOrDie(legacyscheme.Codecs.LegacyCodec(imagev1.SchemeGroupVersion), &repo),
+		)
+	}
+
+	selector, err := labels.Parse("lifecycle=temporary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := apirequest.WithUser(apirequest.NewDefaultContext(), &fakeUser{})
+	obj, err := storage.DeleteCollection(ctx, nil, nil, &metainternalversion.ListOptions{LabelSelector: selector})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := obj.(*imageapi.ImageStreamTagList)
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	if expected := []string{"stale:latest", "stale:v1"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected deleted tags %v, got %v", expected, names)
+	}
+
+	updatedRepo, err := storage.imageStreamRegistry.GetImageStream(apirequest.NewDefaultContext(), "stale", &metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updatedRepo.Status.Tags) != 0 || len(updatedRepo.Spec.Tags) != 0 {
+		t.Errorf("expected all tags removed, got spec=%#v status=%#v", updatedRepo.Spec.Tags, updatedRepo.Status.Tags)
+	}
+
+	keptRepo, err := storage.imageStreamRegistry.GetImageStream(apirequest.NewDefaultContext(), "kept", &metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keptRepo.Status.Tags) != 1 {
+		t.Errorf("expected unmatched stream to keep its tags, got %#v", keptRepo.Status.Tags)
+	}
+}
+
 func TestCreateImageStreamTag(t *testing.T) {
 	tests := map[string]struct {
 		istag           runtime.Object
@@ -565,3 +739,60 @@ func TestCreateImageStreamTag(t *testing.T) {
 		}()
 	}
 }
+
+func TestCreateImageStreamTagAppliesTagReference(t *testing.T) {
+	client, server, storage := setup(t)
+	defer server.Terminate(t)
+
+	client.Put(
+		context.TODO(),
+		etcdtesting.AddPrefix("/imagestreams/default/test"),
+		runtime.EncodeOrDie(legacyscheme.Codecs.LegacyCodec(imagev1.SchemeGroupVersion),
+			&imageapi.ImageStream{
+				ObjectMeta: metav1.ObjectMeta{
+					CreationTimestamp: metav1.Date(2015, 3, 24, 9, 38, 0, 0, time.UTC),
+					Namespace:         "default",
+					Name:              "test",
+				},
+				Spec: imageapi.ImageStreamSpec{
+					Tags: map[string]imageapi.TagReference{},
+				},
+			},
+		))
+
+	istag := &imageapi.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "test:tag",
+			Annotations: map[string]string{"description": "tracking tag"},
+		},
+		Tag: &imageapi.TagReference{
+			Name:            "latest",
+			From:            &kapi.ObjectReference{Kind: "DockerImage", Name: "foo/bar/baz"},
+			ImportPolicy:    imageapi.TagImportPolicy{Scheduled: true},
+			ReferencePolicy: imageapi.TagReferencePolicy{Type: imageapi.LocalTagReferencePolicy},
+		},
+	}
+
+	ctx := apirequest.WithUser(apirequest.NewDefaultContext(), &fakeUser{})
+	obj, err := storage.Create(ctx, istag, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	created := obj.(*imageapi.ImageStreamTag)
+	if created.Tag == nil {
+		t.Fatalf("expected a spec tag, got %#v", created)
+	}
+	if created.Tag.Name != "tag" {
+		t.Errorf("expected the tag name to be normalized to %q, got %q", "tag", created.Tag.Name)
+	}
+	if !created.Tag.ImportPolicy.Scheduled {
+		t.Errorf("expected the import policy to be applied, got %#v", created.Tag.ImportPolicy)
+	}
+	if created.Tag.ReferencePolicy.Type != imageapi.LocalTagReferencePolicy {
+		t.Errorf("expected the reference policy to be applied, got %#v", created.Tag.ReferencePolicy)
+	}
+	if created.Tag.Annotations["description"] != "tracking tag" {
+		t.Errorf("expected the annotations to be applied to the spec tag, got %#v", created.Tag.Annotations)
+	}
+}
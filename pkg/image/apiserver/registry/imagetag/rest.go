@@ -23,6 +23,7 @@ import (
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/internalimageutil"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/image"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registryhostname"
 	imageprinters "github.com/openshift/openshift-apiserver/pkg/image/printers/internalversion"
 )
 
@@ -31,24 +32,41 @@ import (
 type REST struct {
 	imageRegistry       image.Registry
 	imageStreamRegistry imagestream.Registry
+	hostnames           registryhostname.RegistryHostnameRetriever
 	strategy            Strategy
 	rest.TableConvertor
 }
 
 // NewREST returns a new REST.
-func NewREST(imageRegistry image.Registry, imageStreamRegistry imagestream.Registry, registryWhitelister whitelist.RegistryWhitelister) *REST {
+func NewREST(imageRegistry image.Registry, imageStreamRegistry imagestream.Registry, registryWhitelister whitelist.RegistryWhitelister, hostnames registryhostname.RegistryHostnameRetriever) *REST {
 	return &REST{
 		imageRegistry:       imageRegistry,
 		imageStreamRegistry: imageStreamRegistry,
+		hostnames:           hostnames,
 		strategy:            NewStrategy(registryWhitelister),
 		TableConvertor:      printerstorage.TableConvertor{TableGenerator: printers.NewTableGenerator().With(imageprinters.AddImageOpenShiftHandlers)},
 	}
 }
 
+// registryAliases returns every hostname the integrated registry is known
+// under, so references recorded under a previous registry hostname are still
+// resolved to local pullthrough specs. A nil retriever disables rewriting.
+func (r *REST) registryAliases(ctx context.Context) []string {
+	if r.hostnames == nil {
+		return nil
+	}
+	var aliases []string
+	if internal, ok := r.hostnames.InternalRegistryHostname(ctx); ok {
+		aliases = append(aliases, internal)
+	}
+	return append(aliases, r.hostnames.ExternalRegistryHostnames()...)
+}
+
 var _ rest.Getter = &REST{}
 var _ rest.Lister = &REST{}
 var _ rest.CreaterUpdater = &REST{}
 var _ rest.GracefulDeleter = &REST{}
+var _ rest.CollectionDeleter = &REST{}
 var _ rest.ShortNamesProvider = &REST{}
 var _ rest.Scoper = &REST{}
 
@@ -82,7 +100,11 @@ func nameAndTag(id string) (name string, tag string, err error) {
 }
 
 func (r *REST) List(ctx context.Context, options *metainternal.ListOptions) (runtime.Object, error) {
-	imageStreams, err := r.imageStreamRegistry.ListImageStreams(ctx, options)
+	limit, continueKey, streamOptions, err := paginationFromListOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	imageStreams, err := r.imageStreamRegistry.ListImageStreams(ctx, streamOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -91,6 +113,8 @@ func (r *REST) List(ctx context.Context, options *metainternal.ListOptions) (run
 	tagNames := sets.NewString()
 
 	list := &imageapi.ImageTagList{}
+	list.ResourceVersion = imageStreams.ResourceVersion
+	lastKey := ""
 	for _, currIS := range imageStreams.Items {
 		// prepare a list of all possible tags, then add each one in order
 		for tag := range tagNames {
@@ -103,7 +127,11 @@ func (r *REST) List(ctx context.Context, options *metainternal.ListOptions) (run
 			tagNames.Insert(tag)
 		}
 		for _, currTag := range tagNames.List() {
-			itag, err := newITag(currTag, &currIS, nil, false)
+			key := apihelpers.ContinueTokenKey(currIS.Namespace, currIS.Name, currTag)
+			if key <= continueKey {
+				continue
+			}
+			itag, err := newITag(currTag, &currIS, nil, false, r.registryAliases(ctx))
 			if err != nil {
 				if kapierrors.IsNotFound(err) {
 					continue
@@ -116,7 +144,14 @@ func (r *REST) List(ctx context.Context, options *metainternal.ListOptions) (run
 			}
 
 			if matches {
+				if limit > 0 && int64(len(list.Items)) >= limit {
+					// another item exists beyond the page, so the client must
+					// continue from the last item returned
+					list.Continue = apihelpers.EncodeContinueToken(lastKey)
+					return list, nil
+				}
 				list.Items = append(list.Items, *itag)
+				lastKey = key
 			}
 		}
 	}
@@ -124,6 +159,33 @@ func (r *REST) List(ctx context.Context, options *metainternal.ListOptions) (run
 	return list, nil
 }
 
+// paginationFromListOptions extracts the limit and decoded continue position
+// from options and returns the options to list the backing image streams
+// with.  Tags are materialized from whole streams, so the stream list itself
+// must not be chunked; limit and continue are applied to the emitted tags
+// instead.
+func paginationFromListOptions(options *metainternal.ListOptions) (int64, string, *metainternal.ListOptions, error) {
+	if options == nil {
+		return 0, "", nil, nil
+	}
+	continueKey := ""
+	if len(options.Continue) > 0 {
+		key, err := apihelpers.DecodeContinueToken(options.Continue)
+		if err != nil {
+			return 0, "", nil, kapierrors.NewBadRequest(err.Error())
+		}
+		continueKey = key
+	}
+	streamOptions := options
+	if options.Limit > 0 || len(options.Continue) > 0 {
+		copied := *options
+		copied.Limit = 0
+		copied.Continue = ""
+		streamOptions = &copied
+	}
+	return options.Limit, continueKey, streamOptions, nil
+}
+
 // Get retrieves an image that has been tagged by stream and tag. `id` is of the format <stream name>:<tag>.
 func (r *REST) Get(ctx context.Context, id string, options *metav1.GetOptions) (runtime.Object, error) {
 	name, tag, err := nameAndTag(id)
@@ -133,6 +195,9 @@ func (r *REST) Get(ctx context.Context, id string, options *metav1.GetOptions) (
 
 	imageStream, err := r.imageStreamRegistry.GetImageStream(ctx, name, options)
 	if err != nil {
+		if kapierrors.IsNotFound(err) {
+			return nil, internalimageutil.NewNotFound(imagegroup.Resource("imagetags"), id, internalimageutil.MissingImageStreamCause)
+		}
 		return nil, err
 	}
 
@@ -144,7 +209,7 @@ func (r *REST) Get(ctx context.Context, id string, options *metav1.GetOptions) (
 		image = nil
 	}
 
-	return newITag(tag, imageStream, image, false)
+	return newITag(tag, imageStream, image, false, r.registryAliases(ctx))
 }
 
 func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
@@ -208,7 +273,7 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 			return nil, err
 		}
 		image, _ := r.imageFor(ctx, imageTag, target)
-		return newITag(imageTag, target, image, true)
+		return newITag(imageTag, target, image, true, r.registryAliases(ctx))
 	}
 	// We tried to update resource, but we kept conflicting. Inform the client that we couldn't complete
 	// the operation but that they may try again.
@@ -243,7 +308,7 @@ func (r *REST) Update(ctx context.Context, tagName string, objInfo rest.UpdatedO
 	}
 
 	// create the synthetic old itag
-	old, err := newITag(tag, imageStream, nil, true)
+	old, err := newITag(tag, imageStream, nil, true, r.registryAliases(ctx))
 	if err != nil {
 		return nil, false, err
 	}
@@ -323,7 +388,7 @@ func (r *REST) Update(ctx context.Context, tagName string, objInfo rest.UpdatedO
 		}
 	}
 
-	newITag, err := newITag(tag, newImageStream, image, true)
+	newITag, err := newITag(tag, newImageStream, image, true, r.registryAliases(ctx))
 	return newITag, !exists, err
 }
 
@@ -381,18 +446,135 @@ func (r *REST) Delete(ctx context.Context, id string, objectFunc rest.ValidateOb
 	return nil, false, kapierrors.NewServerTimeout(imagegroup.Resource("imagetags"), "delete", 2)
 }
 
+// DeleteCollection removes every tag matching the list selectors and returns
+// the removed tags.  Matching tags are grouped by image stream so that
+// clearing many stale tags from one stream is a single read-modify-write
+// instead of one conflicting update per tag.  The images the tags point to
+// are *not* deleted.
+func (r *REST) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions, listOptions *metainternal.ListOptions) (runtime.Object, error) {
+	if listOptions == nil {
+		listOptions = &metainternal.ListOptions{}
+	}
+	matcher := MatchImageTag(apihelpers.InternalListOptionsToSelectors(listOptions))
+
+	imageStreams, err := r.imageStreamRegistry.ListImageStreams(ctx, &metainternal.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := &imageapi.ImageTagList{}
+	for i := range imageStreams.Items {
+		stream := &imageStreams.Items[i]
+		tagNames := sets.NewString()
+		for tag := range stream.Spec.Tags {
+			tagNames.Insert(tag)
+		}
+		for tag := range stream.Status.Tags {
+			tagNames.Insert(tag)
+		}
+		var tags []string
+		for _, tag := range tagNames.List() {
+			itag, err := newITag(tag, stream, nil, false, r.registryAliases(ctx))
+			if err != nil {
+				if kapierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			matches, err := matcher.Matches(itag)
+			if err != nil {
+				return nil, err
+			}
+			if !matches {
+				continue
+			}
+			if deleteValidation != nil {
+				if err := deleteValidation(ctx, itag); err != nil {
+					return nil, err
+				}
+			}
+			tags = append(tags, tag)
+			deleted.Items = append(deleted.Items, *itag)
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		if err := r.deleteTagsFromStream(ctx, stream.Name, tags, options); err != nil {
+			return nil, err
+		}
+	}
+	return deleted, nil
+}
+
+// deleteTagsFromStream removes the given tags from the named stream in one
+// update, retrying on conflicts.  Tags or streams removed concurrently by
+// another caller are treated as already deleted.
+func (r *REST) deleteTagsFromStream(ctx context.Context, name string, tags []string, options *metav1.DeleteOptions) error {
+	for i := 10; i > 0; i-- {
+		stream, err := r.imageStreamRegistry.GetImageStream(ctx, name, &metav1.GetOptions{})
+		if err != nil {
+			if kapierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if options != nil {
+			if pre := options.Preconditions; pre != nil {
+				if pre.UID != nil && *pre.UID != stream.UID {
+					return kapierrors.NewConflict(imagegroup.Resource("imagetags"), name, fmt.Errorf("the UID precondition was not met"))
+				}
+			}
+		}
+
+		changed := false
+		for _, tag := range tags {
+			if _, ok := stream.Status.Tags[tag]; ok {
+				delete(stream.Status.Tags, tag)
+				changed = true
+			}
+			if _, ok := stream.Spec.Tags[tag]; ok {
+				delete(stream.Spec.Tags, tag)
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+
+		_, err = r.imageStreamRegistry.UpdateImageStream(ctx, stream, false, &metav1.UpdateOptions{})
+		if kapierrors.IsConflict(err) {
+			continue
+		}
+		if err != nil && !kapierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	// We tried to update resource, but we kept conflicting. Inform the client that we couldn't complete
+	// the operation but that they may try again.
+	return kapierrors.NewServerTimeout(imagegroup.Resource("imagetags"), "deletecollection", 2)
+}
+
 // imageFor retrieves the most recent image for a tag in a given imageStreem.
 func (r *REST) imageFor(ctx context.Context, tag string, imageStream *imageapi.ImageStream) (*imageapi.Image, error) {
+	itagName := imageutil.JoinImageStreamTag(imageStream.Name, tag)
 	event := internalimageutil.LatestTaggedImage(imageStream, tag)
 	if event == nil || len(event.Image) == 0 {
-		return nil, kapierrors.NewNotFound(imagegroup.Resource("imagetags"), imageutil.JoinImageStreamTag(imageStream.Name, tag))
+		return nil, internalimageutil.NewNotFound(imagegroup.Resource("imagetags"), itagName, internalimageutil.MissingTagCause)
 	}
 
-	return r.imageRegistry.GetImage(ctx, event.Image, &metav1.GetOptions{})
+	image, err := r.imageRegistry.GetImage(ctx, event.Image, &metav1.GetOptions{})
+	if err != nil {
+		if kapierrors.IsNotFound(err) {
+			return nil, internalimageutil.NewNotFound(imagegroup.Resource("imagetags"), itagName, internalimageutil.MissingImageCause)
+		}
+		return nil, err
+	}
+	return image, nil
 }
 
 // newITag initializes an image tag from an image stream and image.
-func newITag(tag string, imageStream *imageapi.ImageStream, image *imageapi.Image, allowEmpty bool) (*imageapi.ImageTag, error) {
+func newITag(tag string, imageStream *imageapi.ImageStream, image *imageapi.Image, allowEmpty bool, registryAliases []string) (*imageapi.ImageTag, error) {
 	itagName := imageutil.JoinImageStreamTag(imageStream.Name, tag)
 
 	itag := &imageapi.ImageTag{
@@ -428,11 +610,11 @@ func newITag(tag string, imageStream *imageapi.ImageStream, image *imageapi.Imag
 		image.DockerImageManifest = ""
 		image.DockerImageConfig = ""
 		itag.Image = image
-		itag.Image.DockerImageReference = internalimageutil.ResolveReferenceForTagEvent(imageStream, tag, event)
+		itag.Image.DockerImageReference = internalimageutil.ResolveReferenceForTagEventWithAliases(imageStream, tag, event, registryAliases)
 	}
 
 	if !allowEmpty && itag.Spec == nil && itag.Status == nil {
-		return nil, kapierrors.NewNotFound(imagegroup.Resource("imagetags"), itagName)
+		return nil, internalimageutil.NewNotFound(imagegroup.Resource("imagetags"), itagName, internalimageutil.MissingTagCause)
 	}
 
 	return itag, nil
@@ -11,6 +11,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/diff"
@@ -26,6 +27,7 @@ import (
 	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
 	"github.com/openshift/openshift-apiserver/pkg/image/apis/image/validation/fake"
 	admfake "github.com/openshift/openshift-apiserver/pkg/image/apiserver/admission/fake"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/internalimageutil"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/image"
 	imageetcd "github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/image/etcd"
 	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/registry/imagestream"
@@ -89,6 +91,7 @@ func setup(t *testing.T) (etcd.KV, *etcdtesting.EtcdTestServer, *REST) {
 		&admfake.ImageStreamLimitVerifier{},
 		rw,
 		imagestreametcd.NewEmptyLayerIndex(),
+		0,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -97,7 +100,7 @@ func setup(t *testing.T) (etcd.KV, *etcdtesting.EtcdTestServer, *REST) {
 	imageRegistry := image.NewRegistry(imageStorage)
 	imageStreamRegistry := imagestream.NewRegistry(imageStreamStorage, imageStreamStatus, internalStorage)
 
-	storage := NewREST(imageRegistry, imageStreamRegistry, rw)
+	storage := NewREST(imageRegistry, imageStreamRegistry, rw, registry)
 
 	return etcdClient, server, storage
 }
@@ -114,6 +117,7 @@ func TestGetImageTag(t *testing.T) {
 		expectError     bool
 		errorTargetKind string
 		errorTargetID   string
+		errorCause      string
 	}{
 		"happy path": {
 			image: &imageapi.Image{ObjectMeta: metav1.ObjectMeta{Name: "10"}, DockerImageReference: "foo/bar/baz"},
@@ -336,8 +340,9 @@ func TestGetImageTag(t *testing.T) {
 		},
 		"missing repo": {
 			expectError:     true,
-			errorTargetKind: "imagestreams",
-			errorTargetID:   "test",
+			errorTargetKind: "imagetags",
+			errorTargetID:   "test:latest",
+			errorCause:      internalimageutil.MissingImageStreamCause,
 		},
 	}
 
@@ -378,6 +383,12 @@ func TestGetImageTag(t *testing.T) {
 					t.Errorf("%s: unexpected status: %#v", name, status.Details)
 					return
 				}
+				if len(testCase.errorCause) > 0 {
+					if len(status.Details.Causes) != 1 || status.Details.Causes[0].Field != testCase.errorCause {
+						t.Errorf("%s: unexpected causes: %#v", name, status.Details.Causes)
+					}
+					return
+				}
 				return
 			}
 
@@ -716,6 +727,86 @@ func TestListImageTag(t *testing.T) {
 	}
 }
 
+func TestListImageTagPagination(t *testing.T) {
+	client, server, storage := setup(t)
+	defer server.Terminate(t)
+
+	// "app" is a prefix of "app-db" and "app" exists in two namespaces, so a
+	// continue token must resume on the full (namespace, name, tag) position
+	// rather than on a flat "name:tag" comparison
+	repos := []imageapi.ImageStream{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+			Status: imageapi.ImageStreamStatus{
+				Tags: map[string]imageapi.TagEventList{
+					"latest": {Items: []imageapi.TagEvent{{DockerImageReference: "test", Image: "10"}}},
+					"v1":     {Items: []imageapi.TagEvent{{DockerImageReference: "test", Image: "10"}}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app-db"},
+			Status: imageapi.ImageStreamStatus{
+				Tags: map[string]imageapi.TagEventList{
+					"latest": {Items: []imageapi.TagEvent{{DockerImageReference: "test", Image: "10"}}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "app"},
+			Status: imageapi.ImageStreamStatus{
+				Tags: map[string]imageapi.TagEventList{
+					"latest": {Items: []imageapi.TagEvent{{DockerImageReference: "test", Image: "10"}}},
+				},
+			},
+		},
+	}
+	for _, repo := range repos {
+		client.Put(
+			context.TODO(),
+			etcdtesting.AddPrefix("/imagestreams/"+repo.Namespace+"/"+repo.Name),
+			runtime.EncodeOrDie(legacyscheme.Codecs.LegacyCodec(imagev1.SchemeGroupVersion), &repo),
+		)
+	}
+
+	paginate := func(ctx context.Context, limit int64) []string {
+		var names []string
+		options := &metainternalversion.ListOptions{Limit: limit}
+		for page := 0; ; page++ {
+			obj, err := storage.List(ctx, options)
+			if err != nil {
+				t.Fatalf("unexpected error on page %d: %v", page, err)
+			}
+			list := obj.(*imageapi.ImageTagList)
+			if int64(len(list.Items)) > options.Limit {
+				t.Fatalf("page %d exceeds limit: %d items", page, len(list.Items))
+			}
+			for _, item := range list.Items {
+				names = append(names, item.Namespace+"/"+item.Name)
+			}
+			if len(list.Continue) == 0 {
+				break
+			}
+			options.Continue = list.Continue
+		}
+		return names
+	}
+
+	expected := []string{"default/app:latest", "default/app:v1", "default/app-db:latest"}
+	if names := paginate(apirequest.NewDefaultContext(), 1); !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	expectedAll := []string{"default/app:latest", "default/app:v1", "default/app-db:latest", "other/app:latest"}
+	if names := paginate(apirequest.NewContext(), 1); !reflect.DeepEqual(expectedAll, names) {
+		t.Errorf("expected %v, got %v", expectedAll, names)
+	}
+
+	if _, err := storage.List(apirequest.NewDefaultContext(), &metainternalversion.ListOptions{Continue: "not-a-token!"}); !errors.IsBadRequest(err) {
+		t.Errorf("expected bad request for malformed continue token, got %v", err)
+	}
+}
+
 func TestGetImageTagDIR(t *testing.T) {
 	expDockerImageReference := "foo/bar/baz:latest"
 	image := &imageapi.Image{ObjectMeta: metav1.ObjectMeta{Name: "10"}, DockerImageReference: "foo/bar/baz:different"}
@@ -957,6 +1048,82 @@ func TestDeleteImageTag(t *testing.T) {
 	}
 }
 
+func TestDeleteCollectionImageTags(t *testing.T) {
+	client, server, storage := setup(t)
+	defer server.Terminate(t)
+
+	event := imageapi.TagEvent{
+		DockerImageReference: "registry.default.local/default/test@sha256:381151ac5b7f775e8371e489f3479b84a4c004c90ceddb2ad80b6877215a892f",
+		Image:                "sha256:381151ac5b7f775e8371e489f3479b84a4c004c90ceddb2ad80b6877215a892f",
+		Generation:           2,
+	}
+	repos := []imageapi.ImageStream{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "stale", Labels: map[string]string{"lifecycle": "temporary"}},
+			Spec: imageapi.ImageStreamSpec{
+				Tags: map[string]imageapi.TagReference{
+					"spec-only": {From: &kapi.ObjectReference{Kind: "ImageStreamTag", Name: "other:latest"}},
+				},
+			},
+			Status: imageapi.ImageStreamStatus{
+				Tags: map[string]imageapi.TagEventList{
+					"latest": {Items: []imageapi.TagEvent{event}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kept"},
+			Status: imageapi.ImageStreamStatus{
+				Tags: map[string]imageapi.TagEventList{
+					"latest": {Items: []imageapi.TagEvent{event}},
+				},
+			},
+		},
+	}
+	for _, repo := range repos {
+		client.Put(
+			context.TODO(),
+			etcdtesting.AddPrefix("/imagestreams/default/"+repo.Name),
+			runtime.EncodeOrDie(legacyscheme.Codecs.LegacyCodec(imagev1.SchemeGroupVersion), &repo),
+		)
+	}
+
+	selector, err := labels.Parse("lifecycle=temporary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := apirequest.WithUser(apirequest.NewDefaultContext(), &fakeUser{})
+	obj, err := storage.DeleteCollection(ctx, nil, nil, &metainternalversion.ListOptions{LabelSelector: selector})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := obj.(*imageapi.ImageTagList)
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	if expected := []string{"stale:latest", "stale:spec-only"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected deleted tags %v, got %v", expected, names)
+	}
+
+	updatedRepo, err := storage.imageStreamRegistry.GetImageStream(apirequest.NewDefaultContext(), "stale", &metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updatedRepo.Status.Tags) != 0 || len(updatedRepo.Spec.Tags) != 0 {
+		t.Errorf("expected all tags removed, got spec=%#v status=%#v", updatedRepo.Spec.Tags, updatedRepo.Status.Tags)
+	}
+
+	keptRepo, err := storage.imageStreamRegistry.GetImageStream(apirequest.NewDefaultContext(), "kept", &metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keptRepo.Status.Tags) != 1 {
+		t.Errorf("expected unmatched stream to keep its tags, got %#v", keptRepo.Status.Tags)
+	}
+}
+
 func int64p(i int64) *int64 {
 	return &i
 }
@@ -0,0 +1,196 @@
+package imagetag
+
+import (
+	"context"
+	"sync"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apiserver/pkg/registry/rest"
+	kstorage "k8s.io/apiserver/pkg/storage"
+
+	"github.com/openshift/openshift-apiserver/pkg/api/apihelpers"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+var _ rest.Watcher = &REST{}
+
+// Watch begins watching for new, changed, or deleted image tags. The
+// underlying image stream watch is translated into one event per affected
+// tag, and every emitted ImageTag carries the resource version of the stream
+// revision that produced it, so clients can resume from the version they
+// last observed just like with any other resource.
+func (r *REST) Watch(ctx context.Context, options *metainternal.ListOptions) (watch.Interface, error) {
+	source, err := r.imageStreamRegistry.WatchImageStreams(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	w := newTagWatcher(source, MatchImageTag(apihelpers.InternalListOptionsToSelectors(options)), r.registryAliases(ctx))
+	go w.loop()
+	return w, nil
+}
+
+// tagWatcher translates image stream events into per-tag events. It keeps
+// the tags it last emitted for every stream so a stream modification can be
+// diffed into added, modified and deleted tags.
+type tagWatcher struct {
+	source  watch.Interface
+	matcher kstorage.SelectionPredicate
+
+	// registryAliases holds every hostname the integrated registry is known
+	// under, used when resolving the references of emitted tags.
+	registryAliases []string
+
+	result   chan watch.Event
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	// known maps namespace/name of a stream to the tags last emitted for it.
+	known map[string]map[string]*imageapi.ImageTag
+}
+
+func newTagWatcher(source watch.Interface, matcher kstorage.SelectionPredicate, registryAliases []string) *tagWatcher {
+	return &tagWatcher{
+		source:          source,
+		matcher:         matcher,
+		registryAliases: registryAliases,
+		result:          make(chan watch.Event),
+		stopCh:          make(chan struct{}),
+		known:           map[string]map[string]*imageapi.ImageTag{},
+	}
+}
+
+func (w *tagWatcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *tagWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		w.source.Stop()
+		close(w.stopCh)
+	})
+}
+
+func (w *tagWatcher) loop() {
+	defer close(w.result)
+	for event := range w.source.ResultChan() {
+		switch event.Type {
+		case watch.Added, watch.Modified, watch.Deleted:
+			stream, ok := event.Object.(*imageapi.ImageStream)
+			if !ok {
+				// let the client decide what to do with objects we do not
+				// understand, e.g. an embedded status carried by an error
+				if !w.emit(event) {
+					return
+				}
+				continue
+			}
+			if !w.emitTagEvents(event.Type, stream) {
+				return
+			}
+		case watch.Bookmark:
+			// rewrap the resource version so the object type matches the watch
+			itag := &imageapi.ImageTag{}
+			if stream, ok := event.Object.(*imageapi.ImageStream); ok {
+				itag.ResourceVersion = stream.ResourceVersion
+			}
+			if !w.emit(watch.Event{Type: watch.Bookmark, Object: itag}) {
+				return
+			}
+		default:
+			if !w.emit(event) {
+				return
+			}
+		}
+	}
+}
+
+// emitTagEvents diffs the tags of the stream revision against the tags last
+// emitted for the stream and sends one event per difference. It returns
+// false when the watcher was stopped.
+func (w *tagWatcher) emitTagEvents(eventType watch.EventType, stream *imageapi.ImageStream) bool {
+	key := stream.Namespace + "/" + stream.Name
+
+	current := map[string]*imageapi.ImageTag{}
+	if eventType != watch.Deleted {
+		tagNames := sets.NewString()
+		for tag := range stream.Spec.Tags {
+			tagNames.Insert(tag)
+		}
+		for tag := range stream.Status.Tags {
+			tagNames.Insert(tag)
+		}
+		for _, tag := range tagNames.List() {
+			itag, err := newITag(tag, stream, nil, false, w.registryAliases)
+			if err != nil {
+				// only tags with neither spec nor status report errors here
+				continue
+			}
+			if matches, err := w.matcher.Matches(itag); err != nil || !matches {
+				continue
+			}
+			current[tag] = itag
+		}
+	}
+
+	previous := w.known[key]
+	for _, tag := range sets.StringKeySet(current).List() {
+		itag := current[tag]
+		old, ok := previous[tag]
+		switch {
+		case !ok:
+			if !w.emit(watch.Event{Type: watch.Added, Object: itag}) {
+				return false
+			}
+		case tagChanged(old, itag):
+			if !w.emit(watch.Event{Type: watch.Modified, Object: itag}) {
+				return false
+			}
+		}
+	}
+	for _, tag := range sets.StringKeySet(previous).List() {
+		if _, ok := current[tag]; ok {
+			continue
+		}
+		// the final state of the tag is reported at the resource version of
+		// the stream revision that removed it, so a restarted watch does not
+		// replay the deletion
+		last := previous[tag].DeepCopy()
+		last.ResourceVersion = stream.ResourceVersion
+		if !w.emit(watch.Event{Type: watch.Deleted, Object: last}) {
+			return false
+		}
+	}
+
+	if len(current) == 0 {
+		delete(w.known, key)
+	} else {
+		w.known[key] = current
+	}
+	return true
+}
+
+// tagChanged reports whether two revisions of a tag differ in anything other
+// than the versioning metadata every stream write touches. Without this,
+// writing one tag of a stream would produce spurious Modified events for all
+// of its other tags.
+func tagChanged(old, current *imageapi.ImageTag) bool {
+	a := old.DeepCopy()
+	b := current.DeepCopy()
+	a.ResourceVersion = ""
+	b.ResourceVersion = ""
+	a.Generation = 0
+	b.Generation = 0
+	return !apiequality.Semantic.DeepEqual(a, b)
+}
+
+func (w *tagWatcher) emit(event watch.Event) bool {
+	select {
+	case w.result <- event:
+		return true
+	case <-w.stopCh:
+		return false
+	}
+}
@@ -0,0 +1,121 @@
+package imagetag
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+func expectTagEvent(t *testing.T, w *tagWatcher, eventType watch.EventType, name, resourceVersion string) {
+	t.Helper()
+	event := <-w.ResultChan()
+	if event.Type != eventType {
+		t.Fatalf("expected a %s event, got %#v", eventType, event)
+	}
+	itag, ok := event.Object.(*imageapi.ImageTag)
+	if !ok {
+		t.Fatalf("expected an ImageTag, got %#v", event.Object)
+	}
+	if itag.Name != name {
+		t.Errorf("expected tag %q, got %q", name, itag.Name)
+	}
+	if itag.ResourceVersion != resourceVersion {
+		t.Errorf("expected resource version %q, got %q", resourceVersion, itag.ResourceVersion)
+	}
+}
+
+func TestWatchTranslatesStreamEvents(t *testing.T) {
+	source := watch.NewFake()
+	w := newTagWatcher(source, MatchImageTag(labels.Everything(), fields.Everything()), nil)
+	go w.loop()
+	defer w.Stop()
+
+	stream := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "test",
+			ResourceVersion: "10",
+		},
+		Status: imageapi.ImageStreamStatus{
+			Tags: map[string]imageapi.TagEventList{
+				"latest": {
+					Items: []imageapi.TagEvent{
+						{DockerImageReference: "registry:5000/default/test@sha256:01", Image: "sha256:01"},
+					},
+				},
+			},
+		},
+	}
+	go source.Add(stream.DeepCopy())
+	expectTagEvent(t, w, watch.Added, "test:latest", "10")
+
+	// adding a second tag must not replay the unchanged one
+	updated := stream.DeepCopy()
+	updated.ResourceVersion = "11"
+	updated.Spec.Tags = map[string]imageapi.TagReference{
+		"stable": {Name: "stable"},
+	}
+	go source.Modify(updated.DeepCopy())
+	expectTagEvent(t, w, watch.Added, "test:stable", "11")
+
+	// a new tag event modifies only the tag it lands on
+	retagged := updated.DeepCopy()
+	retagged.ResourceVersion = "12"
+	retagged.Status.Tags["latest"] = imageapi.TagEventList{
+		Items: []imageapi.TagEvent{
+			{DockerImageReference: "registry:5000/default/test@sha256:02", Image: "sha256:02"},
+		},
+	}
+	go source.Modify(retagged.DeepCopy())
+	expectTagEvent(t, w, watch.Modified, "test:latest", "12")
+
+	// removing a tag reports its deletion at the new stream revision
+	removed := retagged.DeepCopy()
+	removed.ResourceVersion = "13"
+	delete(removed.Spec.Tags, "stable")
+	go source.Modify(removed.DeepCopy())
+	expectTagEvent(t, w, watch.Deleted, "test:stable", "13")
+
+	// deleting the stream deletes the remaining tags
+	go source.Delete(removed.DeepCopy())
+	expectTagEvent(t, w, watch.Deleted, "test:latest", "13")
+}
+
+func TestWatchAppliesSelectors(t *testing.T) {
+	source := watch.NewFake()
+	w := newTagWatcher(source, MatchImageTag(labels.SelectorFromSet(labels.Set{"color": "blue"}), fields.Everything()), nil)
+	go w.loop()
+	defer w.Stop()
+
+	unlabeled := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "plain",
+			ResourceVersion: "20",
+		},
+		Spec: imageapi.ImageStreamSpec{
+			Tags: map[string]imageapi.TagReference{"latest": {Name: "latest"}},
+		},
+	}
+	labeled := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "colored",
+			ResourceVersion: "21",
+			Labels:          map[string]string{"color": "blue"},
+		},
+		Spec: imageapi.ImageStreamSpec{
+			Tags: map[string]imageapi.TagReference{"latest": {Name: "latest"}},
+		},
+	}
+	go func() {
+		source.Add(unlabeled)
+		source.Add(labeled)
+	}()
+	expectTagEvent(t, w, watch.Added, "colored:latest", "21")
+}
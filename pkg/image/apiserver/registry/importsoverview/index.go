@@ -0,0 +1,156 @@
+package importsoverview
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imageref "github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/reducedinformer"
+)
+
+// ImportHealthIndex is a cache of per-stream import health. Because a large
+// number of image streams can exist on a cluster, only the small subset of
+// each stream needed to aggregate import health is held in memory.
+type ImportHealthIndex interface {
+	HasSynced() bool
+	List() []*StreamImportHealth
+	Run(stopCh <-chan struct{})
+}
+
+type ImageStreamListWatch interface {
+	List(context.Context, metav1.ListOptions) (*imagev1.ImageStreamList, error)
+	Watch(context.Context, metav1.ListOptions) (watch.Interface, error)
+}
+
+type importHealthIndex struct {
+	informer cache.SharedIndexInformer
+}
+
+func (i importHealthIndex) HasSynced() bool {
+	return i.informer.HasSynced()
+}
+
+func (i importHealthIndex) List() []*StreamImportHealth {
+	items := i.informer.GetStore().List()
+	entries := make([]*StreamImportHealth, 0, len(items))
+	for _, item := range items {
+		if entry, ok := item.(*StreamImportHealth); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (i importHealthIndex) Run(stopCh <-chan struct{}) {
+	i.informer.Run(stopCh)
+}
+
+// NewImportHealthIndex creates an index over a store that must return image
+// streams.
+func NewImportHealthIndex(lw ImageStreamListWatch) ImportHealthIndex {
+	informer := reducedinformer.New(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return lw.List(context.TODO(), metav1.ListOptions{
+				ResourceVersion: options.ResourceVersion,
+				Limit:           options.Limit,
+				Continue:        options.Continue,
+			})
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return lw.Watch(context.TODO(), metav1.ListOptions{
+				ResourceVersion: options.ResourceVersion,
+			})
+		},
+	}, func(obj runtime.Object) runtime.Object {
+		if stream, ok := obj.(*imagev1.ImageStream); ok {
+			return importHealthForStream(stream)
+		}
+		return obj
+	}, &StreamImportHealth{})
+	return importHealthIndex{informer: informer}
+}
+
+// FailedTag records one tag of a stream whose most recent import attempt
+// failed.
+type FailedTag struct {
+	// Tag is the name of the tag within the stream.
+	Tag string `json:"tag"`
+	// Reason is the machine readable reason recorded on the import condition.
+	Reason string `json:"reason,omitempty"`
+	// Message is the human readable message recorded on the import condition.
+	Message string `json:"message,omitempty"`
+}
+
+// StreamImportHealth is the minimal subset of an image stream retained to
+// aggregate import health across the cluster.
+type StreamImportHealth struct {
+	reducedinformer.ReducedObjectMeta
+	// ScheduledTags counts the spec tags imported on a schedule.
+	ScheduledTags int
+	// FailedTags lists the tags whose most recent import attempt failed.
+	FailedTags []FailedTag
+	// Registries lists the registry hosts the stream imports from.
+	Registries []string
+}
+
+// importHealthForStream reduces a stream to its import health subset.
+func importHealthForStream(stream *imagev1.ImageStream) *StreamImportHealth {
+	entry := &StreamImportHealth{
+		ReducedObjectMeta: reducedinformer.ReducedObjectMeta{
+			Namespace:       stream.Namespace,
+			Name:            stream.Name,
+			ResourceVersion: stream.ResourceVersion,
+		},
+	}
+	registries := sets.NewString()
+	for _, tag := range stream.Spec.Tags {
+		if tag.ImportPolicy.Scheduled {
+			entry.ScheduledTags++
+		}
+		if tag.From == nil || tag.From.Kind != "DockerImage" {
+			continue
+		}
+		if ref, err := imageref.Parse(tag.From.Name); err == nil {
+			registries.Insert(ref.DockerClientDefaults().Registry)
+		}
+	}
+	entry.Registries = registries.List()
+	for _, tag := range stream.Status.Tags {
+		for _, condition := range tag.Conditions {
+			if condition.Type != imagev1.ImportSuccess || condition.Status != "False" {
+				continue
+			}
+			entry.FailedTags = append(entry.FailedTags, FailedTag{
+				Tag:     tag.Tag,
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			})
+			break
+		}
+	}
+	return entry
+}
+
+var (
+	_ runtime.Object = &StreamImportHealth{}
+	_ metav1.Object  = &StreamImportHealth{}
+)
+
+func (h *StreamImportHealth) DeepCopyObject() runtime.Object {
+	copied := *h
+	if h.FailedTags != nil {
+		copied.FailedTags = make([]FailedTag, len(h.FailedTags))
+		copy(copied.FailedTags, h.FailedTags)
+	}
+	if h.Registries != nil {
+		copied.Registries = make([]string, len(h.Registries))
+		copy(copied.Registries, h.Registries)
+	}
+	return &copied
+}
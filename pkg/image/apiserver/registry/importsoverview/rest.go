@@ -0,0 +1,170 @@
+package importsoverview
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+// overviewName is the only accepted resource name; the overview is a cluster
+// singleton, matching the convention used by cluster configuration resources.
+const overviewName = "cluster"
+
+// FailingStream identifies one image stream with at least one failing import.
+type FailingStream struct {
+	// Namespace and Name identify the stream.
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Tags lists the failing tags with their recorded reason and message.
+	Tags []FailedTag `json:"tags"`
+	// Registries lists the registry hosts the stream imports from.
+	Registries []string `json:"registries,omitempty"`
+}
+
+// ReasonCount counts the failing tags recorded with one failure reason.
+type ReasonCount struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+// RegistryCount counts the failing streams importing from one registry host.
+type RegistryCount struct {
+	Registry       string `json:"registry"`
+	FailingStreams int    `json:"failingStreams"`
+}
+
+// ImportsOverview is the response body of the imports overview endpoint.
+type ImportsOverview struct {
+	// ScheduledStreams counts the streams with at least one scheduled spec
+	// tag.
+	ScheduledStreams int `json:"scheduledStreams"`
+	// FailingStreams lists the streams whose most recent import attempts
+	// failed for at least one tag.
+	FailingStreams []FailingStream `json:"failingStreams"`
+	// Reasons aggregates the recorded failure reasons, most frequent first.
+	Reasons []ReasonCount `json:"reasons,omitempty"`
+	// Registries aggregates the registries failing streams import from,
+	// most affected first.
+	Registries []RegistryCount `json:"registries,omitempty"`
+}
+
+// REST implements a computed, cluster-scoped endpoint aggregating scheduled
+// import health across all image streams.  It lets an administrator diagnose
+// a registry outage in one call instead of scanning every namespace, and is
+// backed by informer data so the call is cheap.
+type REST struct {
+	index ImportHealthIndex
+}
+
+var _ rest.Connecter = &REST{}
+var _ rest.Scoper = &REST{}
+
+// NewREST returns the imports overview handler backed by index.
+func NewREST(index ImportHealthIndex) *REST {
+	return &REST{index: index}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &imageapi.ImageStreamImport{}
+}
+
+func (r *REST) NamespaceScoped() bool {
+	return false
+}
+
+// ConnectMethods returns GET, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"GET"}
+}
+
+// NewConnectOptions returns an empty options object; there are no options
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that aggregates the indexed import health.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	if name != overviewName {
+		return nil, kapierrors.NewNotFound(imageapi.Resource("imageimportsoverviews"), name)
+	}
+	if !r.index.HasSynced() {
+		return nil, kapierrors.NewServiceUnavailable("imports overview cache has not finished an initial synchronization")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		overview := overviewFromIndex(r.index)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(overview); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+// overviewFromIndex aggregates the per-stream entries in index.
+func overviewFromIndex(index ImportHealthIndex) *ImportsOverview {
+	overview := &ImportsOverview{FailingStreams: []FailingStream{}}
+	reasons := make(map[string]int)
+	registries := make(map[string]int)
+
+	for _, entry := range index.List() {
+		if entry.ScheduledTags > 0 {
+			overview.ScheduledStreams++
+		}
+		if len(entry.FailedTags) == 0 {
+			continue
+		}
+		overview.FailingStreams = append(overview.FailingStreams, FailingStream{
+			Namespace:  entry.Namespace,
+			Name:       entry.Name,
+			Tags:       entry.FailedTags,
+			Registries: entry.Registries,
+		})
+		for _, tag := range entry.FailedTags {
+			reason := tag.Reason
+			if len(reason) == 0 {
+				reason = "Unknown"
+			}
+			reasons[reason]++
+		}
+		for _, registry := range entry.Registries {
+			registries[registry]++
+		}
+	}
+
+	sort.Slice(overview.FailingStreams, func(i, j int) bool {
+		a, b := overview.FailingStreams[i], overview.FailingStreams[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	for reason, count := range reasons {
+		overview.Reasons = append(overview.Reasons, ReasonCount{Reason: reason, Count: count})
+	}
+	sort.Slice(overview.Reasons, func(i, j int) bool {
+		a, b := overview.Reasons[i], overview.Reasons[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Reason < b.Reason
+	})
+	for registry, count := range registries {
+		overview.Registries = append(overview.Registries, RegistryCount{Registry: registry, FailingStreams: count})
+	}
+	sort.Slice(overview.Registries, func(i, j int) bool {
+		a, b := overview.Registries[i], overview.Registries[j]
+		if a.FailingStreams != b.FailingStreams {
+			return a.FailingStreams > b.FailingStreams
+		}
+		return a.Registry < b.Registry
+	})
+	return overview
+}
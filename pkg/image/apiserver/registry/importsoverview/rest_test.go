@@ -0,0 +1,118 @@
+package importsoverview
+
+import (
+	"reflect"
+	"testing"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/reducedinformer"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type fakeIndex struct {
+	synced  bool
+	entries []*StreamImportHealth
+}
+
+func (f *fakeIndex) HasSynced() bool             { return f.synced }
+func (f *fakeIndex) List() []*StreamImportHealth { return f.entries }
+func (f *fakeIndex) Run(stopCh <-chan struct{})  {}
+
+func TestImportHealthForStream(t *testing.T) {
+	stream := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{
+				{
+					Name:         "latest",
+					From:         &corev1.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/repo/app:latest"},
+					ImportPolicy: imagev1.TagImportPolicy{Scheduled: true},
+				},
+				{
+					Name: "internal",
+					From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "other:latest"},
+				},
+			},
+		},
+		Status: imagev1.ImageStreamStatus{
+			Tags: []imagev1.NamedTagEventList{
+				{
+					Tag: "latest",
+					Conditions: []imagev1.TagEventCondition{
+						{Type: imagev1.ImportSuccess, Status: "False", Reason: "Unauthorized", Message: "no access"},
+					},
+				},
+				{Tag: "internal"},
+			},
+		},
+	}
+
+	entry := importHealthForStream(stream)
+	if entry.ScheduledTags != 1 {
+		t.Errorf("expected 1 scheduled tag, got %d", entry.ScheduledTags)
+	}
+	if expected := []string{"registry.example.com"}; !reflect.DeepEqual(entry.Registries, expected) {
+		t.Errorf("expected registries %v, got %v", expected, entry.Registries)
+	}
+	if expected := []FailedTag{{Tag: "latest", Reason: "Unauthorized", Message: "no access"}}; !reflect.DeepEqual(entry.FailedTags, expected) {
+		t.Errorf("expected failed tags %v, got %v", expected, entry.FailedTags)
+	}
+}
+
+func TestOverviewFromIndex(t *testing.T) {
+	index := &fakeIndex{entries: []*StreamImportHealth{
+		{
+			ReducedObjectMeta: reducedinformer.ReducedObjectMeta{Namespace: "b", Name: "broken"},
+			ScheduledTags:     2,
+			FailedTags:        []FailedTag{{Tag: "latest", Reason: "Unauthorized"}},
+			Registries:        []string{"registry.example.com"},
+		},
+		{
+			ReducedObjectMeta: reducedinformer.ReducedObjectMeta{Namespace: "a", Name: "also-broken"},
+			FailedTags:        []FailedTag{{Tag: "v1", Reason: "Unauthorized"}, {Tag: "v2", Reason: "InternalError"}},
+			Registries:        []string{"registry.example.com", "quay.io"},
+		},
+		{
+			ReducedObjectMeta: reducedinformer.ReducedObjectMeta{Namespace: "c", Name: "healthy"},
+			ScheduledTags:     1,
+			Registries:        []string{"docker.io"},
+		},
+	}}
+
+	overview := overviewFromIndex(index)
+	if overview.ScheduledStreams != 2 {
+		t.Errorf("expected 2 scheduled streams, got %d", overview.ScheduledStreams)
+	}
+	if len(overview.FailingStreams) != 2 || overview.FailingStreams[0].Name != "also-broken" {
+		t.Errorf("unexpected failing streams: %#v", overview.FailingStreams)
+	}
+	expectedReasons := []ReasonCount{{Reason: "Unauthorized", Count: 2}, {Reason: "InternalError", Count: 1}}
+	if !reflect.DeepEqual(overview.Reasons, expectedReasons) {
+		t.Errorf("expected reasons %v, got %v", expectedReasons, overview.Reasons)
+	}
+	expectedRegistries := []RegistryCount{{Registry: "registry.example.com", FailingStreams: 2}, {Registry: "quay.io", FailingStreams: 1}}
+	if !reflect.DeepEqual(overview.Registries, expectedRegistries) {
+		t.Errorf("expected registries %v, got %v", expectedRegistries, overview.Registries)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	ctx := apirequest.NewContext()
+
+	storage := NewREST(&fakeIndex{synced: false})
+	if _, err := storage.Connect(ctx, "cluster", nil, nil); !kapierrors.IsServiceUnavailable(err) {
+		t.Errorf("expected service unavailable before sync, got %v", err)
+	}
+
+	storage = NewREST(&fakeIndex{synced: true})
+	if _, err := storage.Connect(ctx, "other", nil, nil); !kapierrors.IsNotFound(err) {
+		t.Errorf("expected not found for names other than cluster, got %v", err)
+	}
+	if _, err := storage.Connect(ctx, "cluster", nil, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package limitverification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	imageadmission "github.com/openshift/openshift-apiserver/pkg/image/apiserver/admission/limitrange"
+	"github.com/openshift/openshift-apiserver/pkg/image/apiserver/internalimageutil"
+)
+
+// maxVerificationBodySize bounds the size of a proposed object; an
+// ImageStreamMapping carries a full manifest, so allow a few megabytes.
+const maxVerificationBodySize = 4 * 1024 * 1024
+
+// LimitViolation describes one limit the proposed object would exceed.
+type LimitViolation struct {
+	// LimitType is the LimitRange item type that would reject the object.
+	LimitType string `json:"limitType"`
+	// Resource is the limited resource.
+	Resource string `json:"resource"`
+	// Requested and Limit are the proposed usage and the configured maximum.
+	Requested string `json:"requested"`
+	Limit     string `json:"limit"`
+	// Message is the error the admission path would have returned.
+	Message string `json:"message"`
+}
+
+// LimitVerification is the response body of the limit-verification
+// subresource.
+type LimitVerification struct {
+	// Namespace and Name identify the stream the proposal was checked
+	// against.
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Kind is the kind of the proposed object.
+	Kind string `json:"kind"`
+	// Allowed is true when no limit would reject the proposal.
+	Allowed bool `json:"allowed"`
+	// Violations lists the limits the proposal exceeds.
+	Violations []LimitViolation `json:"violations"`
+}
+
+// REST evaluates a proposed ImageStream or ImageStreamMapping against the
+// LimitRanges of its namespace without persisting anything.  The admission
+// path reports limit failures as 422s after the push already happened; this
+// endpoint lets pipelines run the same checks up front.
+type REST struct {
+	rangesForNamespace func(namespace string) ([]*corev1.LimitRange, error)
+	decoder            runtime.Decoder
+}
+
+var _ rest.Connecter = &REST{}
+var _ rest.Scoper = &REST{}
+
+// NewREST returns the handler for the imagestreams/{name}/limit-verification
+// subresource.
+func NewREST(limitRangeInformer corev1informers.LimitRangeInformer) *REST {
+	// this call just forces the informer to be registered
+	limitRangeInformer.Informer()
+	return newREST(func(namespace string) ([]*corev1.LimitRange, error) {
+		return limitRangeInformer.Lister().LimitRanges(namespace).List(labels.Everything())
+	})
+}
+
+func newREST(rangesForNamespace func(namespace string) ([]*corev1.LimitRange, error)) *REST {
+	return &REST{
+		rangesForNamespace: rangesForNamespace,
+		decoder:            legacyscheme.Codecs.UniversalDecoder(),
+	}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &imageapi.ImageStream{}
+}
+
+func (r *REST) NamespaceScoped() bool {
+	return true
+}
+
+// ConnectMethods returns POST, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"POST"}
+}
+
+// NewConnectOptions returns an empty options object; there are no options
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that checks the posted object against the limits
+// of the request namespace.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	namespace, ok := apirequest.NamespaceFrom(ctx)
+	if !ok {
+		return nil, kapierrors.NewBadRequest("a namespace must be specified")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxVerificationBodySize))
+		if err != nil {
+			responder.Error(kapierrors.NewBadRequest(fmt.Sprintf("unable to read proposed object: %v", err)))
+			return
+		}
+		obj, _, err := r.decoder.Decode(body, nil, nil)
+		if err != nil {
+			responder.Error(kapierrors.NewBadRequest(fmt.Sprintf("unable to decode proposed object: %v", err)))
+			return
+		}
+
+		verification, err := r.verify(namespace, name, obj)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(verification); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+// verify dispatches on the kind of the proposed object.
+func (r *REST) verify(namespace, name string, obj runtime.Object) (*LimitVerification, error) {
+	verification := &LimitVerification{
+		Namespace:  namespace,
+		Name:       name,
+		Allowed:    true,
+		Violations: []LimitViolation{},
+	}
+
+	switch t := obj.(type) {
+	case *imageapi.ImageStream:
+		if len(t.Name) > 0 && t.Name != name {
+			return nil, kapierrors.NewBadRequest(fmt.Sprintf("the name of the proposed ImageStream %q does not match the subresource name %q", t.Name, name))
+		}
+		verification.Kind = "ImageStream"
+		violations, err := r.verifyStream(namespace, t)
+		if err != nil {
+			return nil, err
+		}
+		verification.Violations = violations
+	case *imageapi.ImageStreamMapping:
+		if len(t.Name) > 0 && t.Name != name {
+			return nil, kapierrors.NewBadRequest(fmt.Sprintf("the name of the proposed ImageStreamMapping %q does not match the subresource name %q", t.Name, name))
+		}
+		verification.Kind = "ImageStreamMapping"
+		violations, err := r.verifyMapping(namespace, t)
+		if err != nil {
+			return nil, err
+		}
+		verification.Violations = violations
+	default:
+		return nil, kapierrors.NewBadRequest(fmt.Sprintf("only ImageStream and ImageStreamMapping objects may be verified, not %T", obj))
+	}
+
+	verification.Allowed = len(verification.Violations) == 0
+	return verification, nil
+}
+
+// verifyStream applies the image stream count limits the update strategy
+// enforces through the limit verifier.
+func (r *REST) verifyStream(namespace string, stream *imageapi.ImageStream) ([]LimitViolation, error) {
+	limits, err := imageadmission.LimitRangesForNamespaceFunc(r.rangesForNamespace).LimitsForNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []LimitViolation{}
+	usage := imageadmission.GetImageStreamUsage(stream)
+	for resourceName, limit := range limits {
+		requested, ok := usage[resourceName]
+		if !ok || requested.Cmp(limit) <= 0 {
+			continue
+		}
+		violations = append(violations, newLimitViolation(imagev1.LimitTypeImageStream, resourceName, &requested, &limit))
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Resource < violations[j].Resource })
+	return violations, nil
+}
+
+// verifyMapping applies the per-image storage limits the admission plugin
+// enforces on image stream mappings.
+func (r *REST) verifyMapping(namespace string, mapping *imageapi.ImageStreamMapping) ([]LimitViolation, error) {
+	ranges, err := r.rangesForNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	image := &mapping.Image
+	if err := internalimageutil.InternalImageWithMetadata(image); err != nil {
+		return nil, kapierrors.NewBadRequest(fmt.Sprintf("unable to parse the manifest of the proposed image: %v", err))
+	}
+	requested := resource.NewQuantity(image.DockerImageMetadata.Size, resource.BinarySI)
+
+	violations := []LimitViolation{}
+	for _, limitRange := range ranges {
+		for _, item := range limitRange.Spec.Limits {
+			if item.Type != imagev1.LimitTypeImage {
+				continue
+			}
+			limit, ok := item.Max[corev1.ResourceStorage]
+			if !ok || limit.Cmp(*requested) >= 0 {
+				continue
+			}
+			violations = append(violations, newLimitViolation(imagev1.LimitTypeImage, corev1.ResourceStorage, requested, &limit))
+		}
+	}
+	return violations, nil
+}
+
+// newLimitViolation mirrors the error message of the admission path so
+// pipelines see the same text either way.
+func newLimitViolation(limitType corev1.LimitType, resourceName corev1.ResourceName, requested, limit *resource.Quantity) LimitViolation {
+	return LimitViolation{
+		LimitType: string(limitType),
+		Resource:  string(resourceName),
+		Requested: requested.String(),
+		Limit:     limit.String(),
+		Message:   fmt.Sprintf("requested usage of %s exceeds the maximum limit per %s (%s > %s)", resourceName, limitType, requested.String(), limit.String()),
+	}
+}
@@ -0,0 +1,148 @@
+package limitverification
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+)
+
+func rangesFixture(ranges ...*corev1.LimitRange) func(namespace string) ([]*corev1.LimitRange, error) {
+	return func(namespace string) ([]*corev1.LimitRange, error) {
+		return ranges, nil
+	}
+}
+
+func imageStreamLimitRange(tags, images string) *corev1.LimitRange {
+	return &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "stream-limits"},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type: imagev1.LimitTypeImageStream,
+					Max: corev1.ResourceList{
+						imagev1.ResourceImageStreamTags:   resource.MustParse(tags),
+						imagev1.ResourceImageStreamImages: resource.MustParse(images),
+					},
+				},
+			},
+		},
+	}
+}
+
+func imageSizeLimitRange(max string) *corev1.LimitRange {
+	return &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "image-limits"},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type: imagev1.LimitTypeImage,
+					Max:  corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(max)},
+				},
+			},
+		},
+	}
+}
+
+func proposedStream(tags int) *imageapi.ImageStream {
+	stream := &imageapi.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Spec:       imageapi.ImageStreamSpec{Tags: map[string]imageapi.TagReference{}},
+	}
+	for i := 0; i < tags; i++ {
+		name := string(rune('a' + i))
+		stream.Spec.Tags[name] = imageapi.TagReference{
+			Name: name,
+			From: &kapi.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/repo/app:" + name},
+		}
+	}
+	return stream
+}
+
+func proposedMapping(size int64) *imageapi.ImageStreamMapping {
+	mapping := &imageapi.ImageStreamMapping{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Image: imageapi.Image{
+			ObjectMeta: metav1.ObjectMeta{Name: "sha256:0000000000000000000000000000000000000000000000000000000000000001"},
+		},
+		Tag: "latest",
+	}
+	mapping.Image.DockerImageMetadata.Size = size
+	return mapping
+}
+
+func TestVerifyStream(t *testing.T) {
+	storage := newREST(rangesFixture(imageStreamLimitRange("2", "10")))
+
+	verification, err := storage.verify("default", "app", proposedStream(3))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verification.Allowed {
+		t.Error("expected the proposal to be rejected")
+	}
+	if len(verification.Violations) != 1 {
+		t.Fatalf("expected 1 violation, got %#v", verification.Violations)
+	}
+	violation := verification.Violations[0]
+	if violation.Resource != string(imagev1.ResourceImageStreamTags) || violation.Requested != "3" || violation.Limit != "2" {
+		t.Errorf("unexpected violation: %#v", violation)
+	}
+
+	verification, err = storage.verify("default", "app", proposedStream(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !verification.Allowed || len(verification.Violations) != 0 {
+		t.Errorf("expected the proposal to be allowed, got %#v", verification.Violations)
+	}
+}
+
+func TestVerifyMapping(t *testing.T) {
+	storage := newREST(rangesFixture(imageSizeLimitRange("1Ki")))
+
+	verification, err := storage.verify("default", "app", proposedMapping(2048))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verification.Allowed {
+		t.Error("expected the proposal to be rejected")
+	}
+	if len(verification.Violations) != 1 {
+		t.Fatalf("expected 1 violation, got %#v", verification.Violations)
+	}
+	violation := verification.Violations[0]
+	if violation.LimitType != string(imagev1.LimitTypeImage) || violation.Resource != string(corev1.ResourceStorage) {
+		t.Errorf("unexpected violation: %#v", violation)
+	}
+
+	verification, err = storage.verify("default", "app", proposedMapping(512))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !verification.Allowed || len(verification.Violations) != 0 {
+		t.Errorf("expected the proposal to be allowed, got %#v", verification.Violations)
+	}
+}
+
+func TestVerifyNameMismatch(t *testing.T) {
+	storage := newREST(rangesFixture())
+
+	if _, err := storage.verify("default", "other", proposedStream(1)); err == nil {
+		t.Error("expected a name mismatch error")
+	}
+}
+
+func TestVerifyUnsupportedObject(t *testing.T) {
+	storage := newREST(rangesFixture())
+
+	if _, err := storage.verify("default", "app", &imageapi.Image{}); err == nil {
+		t.Error("expected an error for an unsupported object")
+	}
+}
@@ -0,0 +1,47 @@
+package registryhostname
+
+import (
+	"context"
+	"sync"
+)
+
+// MutableRegistryHostnameRetriever forwards to a delegate retriever that can
+// be swapped atomically, so registry hostname configuration can be reloaded
+// without restarting the storage holding a reference to the retriever.
+type MutableRegistryHostnameRetriever struct {
+	lock     sync.RWMutex
+	delegate RegistryHostnameRetriever
+}
+
+var _ RegistryHostnameRetriever = &MutableRegistryHostnameRetriever{}
+
+// NewMutableRegistryHostnameRetriever returns a retriever forwarding to the
+// given initial delegate until Swap replaces it.
+func NewMutableRegistryHostnameRetriever(delegate RegistryHostnameRetriever) *MutableRegistryHostnameRetriever {
+	return &MutableRegistryHostnameRetriever{delegate: delegate}
+}
+
+// Swap replaces the delegate retriever.
+func (r *MutableRegistryHostnameRetriever) Swap(delegate RegistryHostnameRetriever) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.delegate = delegate
+}
+
+func (r *MutableRegistryHostnameRetriever) current() RegistryHostnameRetriever {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.delegate
+}
+
+func (r *MutableRegistryHostnameRetriever) InternalRegistryHostname(ctx context.Context) (string, bool) {
+	return r.current().InternalRegistryHostname(ctx)
+}
+
+func (r *MutableRegistryHostnameRetriever) ExternalRegistryHostname() (string, bool) {
+	return r.current().ExternalRegistryHostname()
+}
+
+func (r *MutableRegistryHostnameRetriever) ExternalRegistryHostnames() []string {
+	return r.current().ExternalRegistryHostnames()
+}
@@ -15,6 +15,12 @@ import (
 type RegistryHostnameRetriever interface {
 	InternalRegistryHostname(context.Context) (string, bool)
 	ExternalRegistryHostname() (string, bool)
+	// ExternalRegistryHostnames returns every hostname the external registry
+	// is recognized under. The first entry is the canonical hostname used
+	// when new references are written; the rest are previous or alternate
+	// hostnames that should still be treated as the integrated registry when
+	// references are resolved.
+	ExternalRegistryHostnames() []string
 }
 
 // DefaultRegistryHostnameRetriever is a default implementation of
@@ -23,14 +29,22 @@ type RegistryHostnameRetriever interface {
 // OPENSHIFT_DEFAULT_REGISTRY environment variable which should be deprecated in
 // future.
 func TestingRegistryHostnameRetriever(deprecatedDefaultRegistryEnvFn func(context.Context) (string, bool), external, internal string) RegistryHostnameRetriever {
+	var externalHostnames []string
+	if len(external) > 0 {
+		externalHostnames = []string{external}
+	}
 	return &defaultRegistryHostnameRetriever{
 		deprecatedDefaultFn: deprecatedDefaultRegistryEnvFn,
-		externalHostname:    external,
+		externalHostnames:   externalHostnames,
 		internalHostname:    internal,
 	}
 }
 
-func DefaultRegistryHostnameRetriever(clientConfig *rest.Config, external, internal string) (RegistryHostnameRetriever, error) {
+// DefaultRegistryHostnameRetriever returns a retriever for the given
+// hostnames. The first external hostname is the canonical one; any further
+// entries are previous or alternate hostnames the registry is still
+// recognized under.
+func DefaultRegistryHostnameRetriever(clientConfig *rest.Config, external []string, internal string) (RegistryHostnameRetriever, error) {
 	kubeClient, err := kubernetes.NewForConfig(clientConfig)
 	if err != nil {
 		return nil, err
@@ -45,7 +59,7 @@ func DefaultRegistryHostnameRetriever(clientConfig *rest.Config, external, inter
 
 	return &defaultRegistryHostnameRetriever{
 		deprecatedDefaultFn: defaultRegistryFunc,
-		externalHostname:    external,
+		externalHostnames:   external,
 		internalHostname:    internal,
 	}, nil
 }
@@ -64,7 +78,7 @@ type defaultRegistryHostnameRetriever struct {
 	// OPENSHIFT_DEFAULT_REGISTRY.
 	deprecatedDefaultFn func(context.Context) (string, bool)
 	internalHostname    string
-	externalHostname    string
+	externalHostnames   []string
 }
 
 // InternalRegistryHostnameFn returns a function that can be used to lazy-load
@@ -85,5 +99,14 @@ func (r *defaultRegistryHostnameRetriever) InternalRegistryHostname(ctx context.
 // external/public hostname of Docker Registry. External location can be
 // configured in master config using 'ExternalRegistryHostname' property.
 func (r *defaultRegistryHostnameRetriever) ExternalRegistryHostname() (string, bool) {
-	return r.externalHostname, len(r.externalHostname) > 0
+	if len(r.externalHostnames) == 0 {
+		return "", false
+	}
+	return r.externalHostnames[0], len(r.externalHostnames[0]) > 0
+}
+
+// ExternalRegistryHostnames returns every configured external hostname,
+// canonical hostname first.
+func (r *defaultRegistryHostnameRetriever) ExternalRegistryHostnames() []string {
+	return r.externalHostnames
 }
@@ -0,0 +1,62 @@
+package scanpolicy
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+const (
+	// ScanVerifiedConditionType is the tag event condition an external
+	// vulnerability scanner sets (through the image stream status endpoint)
+	// once the image currently tagged passed its scan.  The condition's
+	// generation must match the generation of the tag event it verified.
+	ScanVerifiedConditionType = "ScanVerified"
+
+	// RequireScanVerificationAnnotation opts an image stream into scan
+	// gating.  When set to "true", trigger and build resolution paths refuse
+	// to resolve tags that do not carry a current ScanVerified condition.
+	RequireScanVerificationAnnotation = "image.openshift.io/require-scan-verification"
+)
+
+// RequiresVerification returns true if the image stream has opted into scan
+// gating via the RequireScanVerificationAnnotation.
+func RequiresVerification(stream *imagev1.ImageStream) bool {
+	enabled, err := strconv.ParseBool(stream.Annotations[RequireScanVerificationAnnotation])
+	return err == nil && enabled
+}
+
+// VerifyTag returns an error if the image stream requires scan verification
+// and the named tag does not carry a ScanVerified condition covering its most
+// recent tag event.  Streams that have not opted in always pass.
+func VerifyTag(stream *imagev1.ImageStream, tag string) error {
+	if !RequiresVerification(stream) {
+		return nil
+	}
+
+	for _, t := range stream.Status.Tags {
+		if t.Tag != tag {
+			continue
+		}
+		var latestGeneration int64
+		if len(t.Items) > 0 {
+			latestGeneration = t.Items[0].Generation
+		}
+		for _, condition := range t.Conditions {
+			if string(condition.Type) != ScanVerifiedConditionType || condition.Status != corev1.ConditionTrue {
+				continue
+			}
+			// a verification of an older tag event does not cover the image
+			// the tag points at now
+			if condition.Generation >= latestGeneration {
+				return nil
+			}
+		}
+		break
+	}
+
+	return fmt.Errorf("tag %q of image stream %s/%s requires scan verification: no current %s condition found", tag, stream.Namespace, stream.Name, ScanVerifiedConditionType)
+}
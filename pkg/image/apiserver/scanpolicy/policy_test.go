@@ -0,0 +1,89 @@
+package scanpolicy
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+func TestVerifyTag(t *testing.T) {
+	makeStream := func(gating string, conditions ...imagev1.TagEventCondition) *imagev1.ImageStream {
+		stream := &imagev1.ImageStream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "app"},
+			Status: imagev1.ImageStreamStatus{
+				Tags: []imagev1.NamedTagEventList{
+					{
+						Tag:        "latest",
+						Items:      []imagev1.TagEvent{{Generation: 2}, {Generation: 1}},
+						Conditions: conditions,
+					},
+				},
+			},
+		}
+		if len(gating) > 0 {
+			stream.Annotations = map[string]string{RequireScanVerificationAnnotation: gating}
+		}
+		return stream
+	}
+
+	tests := []struct {
+		name        string
+		stream      *imagev1.ImageStream
+		tag         string
+		expectError bool
+	}{
+		{
+			name:   "stream not opted in",
+			stream: makeStream(""),
+			tag:    "latest",
+		},
+		{
+			name:        "annotation set to a non boolean value",
+			stream:      makeStream("definitely"),
+			tag:         "latest",
+			expectError: false,
+		},
+		{
+			name:        "gated without condition",
+			stream:      makeStream("true"),
+			tag:         "latest",
+			expectError: true,
+		},
+		{
+			name:   "gated with current verification",
+			stream: makeStream("true", imagev1.TagEventCondition{Type: ScanVerifiedConditionType, Status: corev1.ConditionTrue, Generation: 2}),
+			tag:    "latest",
+		},
+		{
+			name:        "gated with stale verification",
+			stream:      makeStream("true", imagev1.TagEventCondition{Type: ScanVerifiedConditionType, Status: corev1.ConditionTrue, Generation: 1}),
+			tag:         "latest",
+			expectError: true,
+		},
+		{
+			name:        "gated with failed verification",
+			stream:      makeStream("true", imagev1.TagEventCondition{Type: ScanVerifiedConditionType, Status: corev1.ConditionFalse, Generation: 2}),
+			tag:         "latest",
+			expectError: true,
+		},
+		{
+			name:        "gated tag without status entry",
+			stream:      makeStream("true"),
+			tag:         "missing",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := VerifyTag(test.stream, test.tag)
+		if test.expectError && err == nil {
+			t.Errorf("%s: expected an error, got none", test.name)
+		}
+		if !test.expectError && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+	}
+}
@@ -20,9 +20,12 @@ import (
 
 	projectapiv1 "github.com/openshift/api/project/v1"
 	projectv1client "github.com/openshift/client-go/project/clientset/versioned"
+	quotav1client "github.com/openshift/client-go/quota/clientset/versioned"
 	templatev1client "github.com/openshift/client-go/template/clientset/versioned"
 	projectproxy "github.com/openshift/openshift-apiserver/pkg/project/apiserver/registry/project/proxy"
+	projectfinalize "github.com/openshift/openshift-apiserver/pkg/project/apiserver/registry/projectfinalize"
 	projectrequeststorage "github.com/openshift/openshift-apiserver/pkg/project/apiserver/registry/projectrequest/delegated"
+	projectstatus "github.com/openshift/openshift-apiserver/pkg/project/apiserver/registry/projectstatus"
 	projectauth "github.com/openshift/openshift-apiserver/pkg/project/auth"
 	projectcache "github.com/openshift/openshift-apiserver/pkg/project/cache"
 )
@@ -34,6 +37,9 @@ type ExtraConfig struct {
 	ProjectRequestTemplate    string
 	ProjectRequestMessage     string
 	RESTMapper                meta.RESTMapper
+	// ServeStaleReadsOnStorageError lets project GETs fall back to the
+	// project cache with a Warning header when etcd is unavailable.
+	ServeStaleReadsOnStorageError bool
 
 	// TODO these should all become local eventually
 	Scheme *runtime.Scheme
@@ -128,8 +134,12 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 	if err != nil {
 		return nil, err
 	}
+	quotaClient, err := quotav1client.NewForConfig(c.ExtraConfig.KubeAPIServerClientConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	projectStorage := projectproxy.NewREST(kubeClient.CoreV1().Namespaces(), c.ExtraConfig.ProjectAuthorizationCache, c.ExtraConfig.ProjectAuthorizationCache, c.ExtraConfig.ProjectCache)
+	projectStorage := projectproxy.NewREST(kubeClient.CoreV1().Namespaces(), c.ExtraConfig.ProjectAuthorizationCache, c.ExtraConfig.ProjectAuthorizationCache, c.ExtraConfig.ProjectCache, c.ExtraConfig.ServeStaleReadsOnStorageError)
 
 	namespace, templateName, err := parseNamespaceAndName(c.ExtraConfig.ProjectRequestTemplate)
 	if err != nil {
@@ -150,6 +160,8 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 
 	v1Storage := map[string]rest.Storage{}
 	v1Storage["projects"] = projectStorage
+	v1Storage["projects/finalize"] = projectfinalize.NewREST(kubeClient.CoreV1().Namespaces(), dynamicClient)
+	v1Storage["projects/status"] = projectstatus.NewREST(kubeClient.CoreV1(), quotaClient.QuotaV1())
 	v1Storage["projectRequests"] = projectRequestStorage
 	return v1Storage, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,6 +19,7 @@ import (
 	"github.com/openshift/api/project"
 	"github.com/openshift/apiserver-library-go/pkg/authorization/scope"
 	"github.com/openshift/openshift-apiserver/pkg/api/apihelpers"
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/readfallback"
 	authorizationapi "github.com/openshift/openshift-apiserver/pkg/authorization/apis/authorization"
 	projectapi "github.com/openshift/openshift-apiserver/pkg/project/apis/project"
 	projectregistry "github.com/openshift/openshift-apiserver/pkg/project/apiserver/registry/project"
@@ -39,6 +41,9 @@ type REST struct {
 
 	authCache    *projectauth.AuthorizationCache
 	projectCache *projectcache.ProjectCache
+	// serveStaleReads allows Get to answer from the project cache with a
+	// Warning header when the storage backend is unavailable
+	serveStaleReads bool
 
 	rest.TableConvertor
 }
@@ -50,15 +55,16 @@ var _ rest.Watcher = &REST{}
 var _ rest.Scoper = &REST{}
 
 // NewREST returns a RESTStorage object that will work against Project resources
-func NewREST(client corev1client.NamespaceInterface, lister projectauth.Lister, authCache *projectauth.AuthorizationCache, projectCache *projectcache.ProjectCache) *REST {
+func NewREST(client corev1client.NamespaceInterface, lister projectauth.Lister, authCache *projectauth.AuthorizationCache, projectCache *projectcache.ProjectCache, serveStaleReads bool) *REST {
 	return &REST{
 		client:         client,
 		lister:         lister,
 		createStrategy: projectregistry.Strategy,
 		updateStrategy: projectregistry.Strategy,
 
-		authCache:    authCache,
-		projectCache: projectCache,
+		authCache:       authCache,
+		projectCache:    projectCache,
+		serveStaleReads: serveStaleReads,
 
 		TableConvertor: printerstorage.TableConvertor{TableGenerator: printers.NewTableGenerator().With(projectprinters.AddProjectOpenShiftHandlers)},
 	}
@@ -85,12 +91,33 @@ func (s *REST) List(ctx context.Context, options *metainternal.ListOptions) (run
 	if !ok {
 		return nil, kerrors.NewForbidden(project.Resource("project"), "", fmt.Errorf("unable to list projects without a user on the context"))
 	}
-	labelSelector, _ := apihelpers.InternalListOptionsToSelectors(options)
+	labelSelector, fieldSelector := apihelpers.InternalListOptionsToSelectors(options)
 	namespaceList, err := s.lister.List(user, labelSelector)
 	if err != nil {
 		return nil, err
 	}
-	return projectutil.ConvertNamespaceList(namespaceList)
+	projectList, err := projectutil.ConvertNamespaceList(namespaceList)
+	if err != nil {
+		return nil, err
+	}
+	// the lister already applied the label selector; reduce the payload
+	// further with the field selector before it leaves the server
+	if fieldSelector.Empty() {
+		return projectList, nil
+	}
+	matcher := projectutil.MatchProject(labelSelector, fieldSelector)
+	filtered := make([]projectapi.Project, 0, len(projectList.Items))
+	for i := range projectList.Items {
+		matches, err := matcher.Matches(&projectList.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			filtered = append(filtered, projectList.Items[i])
+		}
+	}
+	projectList.Items = filtered
+	return projectList, nil
 }
 
 func (s *REST) Watch(ctx context.Context, options *metainternal.ListOptions) (watch.Interface, error) {
@@ -109,8 +136,10 @@ func (s *REST) Watch(ctx context.Context, options *metainternal.ListOptions) (wa
 		return nil, err
 	}
 
+	allowBookmarks := (options != nil) && options.AllowWatchBookmarks
+
 	m := projectutil.MatchProject(apihelpers.InternalListOptionsToSelectors(options))
-	watcher := projectauth.NewUserProjectWatcher(userInfo, allowedNamespaces, s.projectCache, s.authCache, includeAllExistingProjects, m)
+	watcher := projectauth.NewUserProjectWatcher(userInfo, allowedNamespaces, s.projectCache, s.authCache, includeAllExistingProjects, allowBookmarks, m)
 	s.authCache.AddWatcher(watcher)
 
 	go watcher.Watch()
@@ -127,11 +156,35 @@ func (s *REST) Get(ctx context.Context, name string, options *metav1.GetOptions)
 	}
 	namespace, err := s.client.Get(ctx, name, opts)
 	if err != nil {
+		if s.serveStaleReads && s.projectCache != nil && readfallback.ShouldFallback(err) {
+			if cached := s.getFromProjectCache(name); cached != nil {
+				project, convErr := projectutil.ConvertNamespaceFromExternal(cached)
+				if convErr == nil {
+					readfallback.AddStaleReadWarning(ctx, "projects")
+					return project, nil
+				}
+			}
+		}
 		return nil, err
 	}
 	return projectutil.ConvertNamespaceFromExternal(namespace)
 }
 
+// getFromProjectCache returns a copy of the named namespace from the project
+// cache, or nil when it is not cached. Unlike ProjectCache.GetNamespace it
+// never reaches back to storage; this path only runs when storage is down.
+func (s *REST) getFromProjectCache(name string) *corev1.Namespace {
+	item, exists, err := s.projectCache.Store.Get(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	if err != nil || !exists {
+		return nil
+	}
+	namespace, ok := item.(*corev1.Namespace)
+	if !ok {
+		return nil
+	}
+	return namespace.DeepCopy()
+}
+
 var _ = rest.Creater(&REST{})
 
 // Create registers the given Project.
@@ -1,20 +1,27 @@
 package proxy
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/authentication/user"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 	"k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 
 	oapi "github.com/openshift/openshift-apiserver/pkg/api"
 	projectapi "github.com/openshift/openshift-apiserver/pkg/project/apis/project"
+	projectcache "github.com/openshift/openshift-apiserver/pkg/project/cache"
 )
 
 // mockLister returns the namespaces in the list
@@ -59,6 +66,55 @@ func TestListProjects(t *testing.T) {
 	}
 }
 
+func TestListProjectsWithFieldSelector(t *testing.T) {
+	namespaceList := corev1.NamespaceList{
+		Items: []corev1.Namespace{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+				Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "bar"},
+				Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+			},
+		},
+	}
+	mockClient := fake.NewSimpleClientset(&namespaceList)
+	storage := REST{
+		client: mockClient.CoreV1().Namespaces(),
+		lister: &mockLister{&namespaceList},
+	}
+	user := &user.DefaultInfo{
+		Name:   "test-user",
+		UID:    "test-uid",
+		Groups: []string{"test-groups"},
+	}
+	ctx := apirequest.WithUser(apirequest.NewContext(), user)
+
+	tests := []struct {
+		selector fields.Selector
+		expected []string
+	}{
+		{selector: fields.OneTermEqualSelector("metadata.name", "foo"), expected: []string{"foo"}},
+		{selector: fields.OneTermEqualSelector("status.phase", string(corev1.NamespaceTerminating)), expected: []string{"bar"}},
+		{selector: fields.OneTermEqualSelector("metadata.name", "missing"), expected: []string{}},
+	}
+	for _, test := range tests {
+		response, err := storage.List(ctx, &metainternal.ListOptions{FieldSelector: test.selector})
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", test.selector, err)
+		}
+		projects := response.(*projectapi.ProjectList)
+		names := []string{}
+		for _, project := range projects.Items {
+			names = append(names, project.Name)
+		}
+		if !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("%v: expected projects %v, got %v", test.selector, test.expected, names)
+		}
+	}
+}
+
 func TestCreateProjectBadObject(t *testing.T) {
 	storage := REST{}
 
@@ -73,7 +129,7 @@ func TestCreateProjectBadObject(t *testing.T) {
 
 func TestCreateInvalidProject(t *testing.T) {
 	mockClient := &fake.Clientset{}
-	storage := NewREST(mockClient.CoreV1().Namespaces(), &mockLister{}, nil, nil)
+	storage := NewREST(mockClient.CoreV1().Namespaces(), &mockLister{}, nil, nil, false)
 	_, err := storage.Create(apirequest.NewContext(), &projectapi.Project{
 		ObjectMeta: metav1.ObjectMeta{
 			Annotations: map[string]string{oapi.OpenShiftDisplayName: "h\t\ni"},
@@ -86,7 +142,7 @@ func TestCreateInvalidProject(t *testing.T) {
 
 func TestCreateProjectOK(t *testing.T) {
 	mockClient := &fake.Clientset{}
-	storage := NewREST(mockClient.CoreV1().Namespaces(), &mockLister{}, nil, nil)
+	storage := NewREST(mockClient.CoreV1().Namespaces(), &mockLister{}, nil, nil, false)
 	_, err := storage.Create(apirequest.NewContext(), &projectapi.Project{
 		ObjectMeta: metav1.ObjectMeta{Name: "foo"},
 	}, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
@@ -103,7 +159,7 @@ func TestCreateProjectOK(t *testing.T) {
 
 func TestGetProjectOK(t *testing.T) {
 	mockClient := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}})
-	storage := NewREST(mockClient.CoreV1().Namespaces(), &mockLister{}, nil, nil)
+	storage := NewREST(mockClient.CoreV1().Namespaces(), &mockLister{}, nil, nil, false)
 	project, err := storage.Get(apirequest.NewContext(), "foo", &metav1.GetOptions{})
 	if project == nil {
 		t.Error("Unexpected nil project")
@@ -116,6 +172,60 @@ func TestGetProjectOK(t *testing.T) {
 	}
 }
 
+func newUnavailableNamespaceClient() *fake.Clientset {
+	mockClient := &fake.Clientset{}
+	mockClient.AddReactor("get", "namespaces", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewServerTimeout(corev1.Resource("namespaces"), "get", 1)
+	})
+	return mockClient
+}
+
+func newPopulatedProjectCache(t *testing.T, namespaces ...*corev1.Namespace) *projectcache.ProjectCache {
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, namespace := range namespaces {
+		if err := store.Add(namespace); err != nil {
+			t.Fatalf("unexpected error priming the cache: %v", err)
+		}
+	}
+	return &projectcache.ProjectCache{Store: store}
+}
+
+func TestGetProjectStaleReadFallback(t *testing.T) {
+	storage := REST{
+		client:          newUnavailableNamespaceClient().CoreV1().Namespaces(),
+		projectCache:    newPopulatedProjectCache(t, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}),
+		serveStaleReads: true,
+	}
+	project, err := storage.Get(apirequest.NewContext(), "foo", &metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if project.(*projectapi.Project).Name != "foo" {
+		t.Errorf("unexpected project: %#v", project)
+	}
+}
+
+func TestGetProjectStaleReadCacheMiss(t *testing.T) {
+	storage := REST{
+		client:          newUnavailableNamespaceClient().CoreV1().Namespaces(),
+		projectCache:    newPopulatedProjectCache(t),
+		serveStaleReads: true,
+	}
+	if _, err := storage.Get(apirequest.NewContext(), "foo", &metav1.GetOptions{}); !errors.IsServerTimeout(err) {
+		t.Errorf("expected the storage error, got %v", err)
+	}
+}
+
+func TestGetProjectStaleReadDisabled(t *testing.T) {
+	storage := REST{
+		client:       newUnavailableNamespaceClient().CoreV1().Namespaces(),
+		projectCache: newPopulatedProjectCache(t, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}),
+	}
+	if _, err := storage.Get(apirequest.NewContext(), "foo", &metav1.GetOptions{}); !errors.IsServerTimeout(err) {
+		t.Errorf("expected the storage error, got %v", err)
+	}
+}
+
 func TestDeleteProject(t *testing.T) {
 	mockClient := &fake.Clientset{}
 	storage := REST{
@@ -0,0 +1,188 @@
+package projectfinalize
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/dynamic"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/openshift/api/project"
+	projectapi "github.com/openshift/openshift-apiserver/pkg/project/apis/project"
+)
+
+// managedResources are the namespaced resources served by the openshift
+// apiserver. The origin finalizer exists so the project controller can wait
+// for these to be deleted; the bypass refuses to remove the finalizer while
+// any instance remains.
+var managedResources = []schema.GroupVersionResource{
+	{Group: "apps.openshift.io", Version: "v1", Resource: "deploymentconfigs"},
+	{Group: "authorization.openshift.io", Version: "v1", Resource: "rolebindingrestrictions"},
+	{Group: "build.openshift.io", Version: "v1", Resource: "builds"},
+	{Group: "build.openshift.io", Version: "v1", Resource: "buildconfigs"},
+	{Group: "image.openshift.io", Version: "v1", Resource: "imagestreams"},
+	{Group: "route.openshift.io", Version: "v1", Resource: "routes"},
+	{Group: "template.openshift.io", Version: "v1", Resource: "templates"},
+	{Group: "template.openshift.io", Version: "v1", Resource: "templateinstances"},
+}
+
+// FinalizeResult is the response body of the finalize subresource.
+type FinalizeResult struct {
+	// Name is the project acted on.
+	Name string `json:"name"`
+	// Finalized is true when the openshift.io/origin finalizer is no longer
+	// present on the namespace, whether this request removed it or an earlier
+	// one did.
+	Finalized bool `json:"finalized"`
+}
+
+// REST removes the openshift.io/origin finalizer from a terminating
+// namespace once no openshift-apiserver-managed resources remain in it.
+// The project controller normally does this on its own; the subresource is
+// an RBAC-guarded escape hatch for namespaces stuck Terminating because the
+// controller is unavailable, replacing manual edits of namespaces/finalize.
+type REST struct {
+	client corev1client.NamespaceInterface
+	// listRemaining returns the managed resources that still have instances
+	// in the namespace. It is a field so tests can substitute the dynamic
+	// client.
+	listRemaining func(ctx context.Context, namespace string) ([]string, error)
+}
+
+var _ rest.Connecter = &REST{}
+
+// NewREST returns the handler for the projects/{name}/finalize subresource.
+func NewREST(client corev1client.NamespaceInterface, dynamicClient dynamic.Interface) *REST {
+	return &REST{
+		client:        client,
+		listRemaining: remainingResourceLister(dynamicClient),
+	}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &projectapi.Project{}
+}
+
+// ConnectMethods returns POST, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"POST"}
+}
+
+// NewConnectOptions returns an empty options object
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that removes the origin finalizer from the
+// named project's namespace.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := r.finalize(ctx, name); err != nil {
+			responder.Error(err)
+			return
+		}
+
+		result := FinalizeResult{Name: name, Finalized: true}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(&result); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+// finalize removes the origin finalizer through the namespaces/finalize
+// endpoint. It refuses namespaces that are not terminating and namespaces
+// that still contain managed resources; those rejections are final, and only
+// the finalize write itself is retried on conflict. A namespace that no
+// longer carries the finalizer is a no-op.
+func (r *REST) finalize(ctx context.Context, name string) error {
+	namespace, err := r.client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if namespace.DeletionTimestamp.IsZero() {
+		return kapierrors.NewConflict(project.Resource("project"), name, fmt.Errorf("the finalizer may only be bypassed on a namespace that is being deleted"))
+	}
+
+	finalizers, found := withoutOriginFinalizer(namespace.Spec.Finalizers)
+	if !found {
+		return nil
+	}
+
+	remaining, err := r.listRemaining(ctx, name)
+	if err != nil {
+		return err
+	}
+	if len(remaining) > 0 {
+		return kapierrors.NewConflict(project.Resource("project"), name, fmt.Errorf("resources remain in the namespace and must be deleted first: %s", strings.Join(remaining, ", ")))
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		namespace.Spec.Finalizers = finalizers
+		_, err := r.client.Finalize(ctx, namespace, metav1.UpdateOptions{})
+		if !kapierrors.IsConflict(err) {
+			return err
+		}
+		refreshed, getErr := r.client.Get(ctx, name, metav1.GetOptions{})
+		if getErr != nil {
+			return getErr
+		}
+		if finalizers, found = withoutOriginFinalizer(refreshed.Spec.Finalizers); !found {
+			return nil
+		}
+		namespace = refreshed
+		return err
+	})
+}
+
+// withoutOriginFinalizer returns the finalizers with the origin finalizer
+// removed and whether it was present.
+func withoutOriginFinalizer(in []corev1.FinalizerName) ([]corev1.FinalizerName, bool) {
+	finalizers := []corev1.FinalizerName{}
+	found := false
+	for _, finalizer := range in {
+		if finalizer == corev1.FinalizerName(projectapi.FinalizerOrigin) {
+			found = true
+			continue
+		}
+		finalizers = append(finalizers, finalizer)
+	}
+	return finalizers, found
+}
+
+// remainingResourceLister lists each managed resource in the namespace and
+// reports those that still have instances. Resources whose API group is not
+// served are skipped so the bypass keeps working when optional groups are
+// disabled.
+func remainingResourceLister(dynamicClient dynamic.Interface) func(ctx context.Context, namespace string) ([]string, error) {
+	return func(ctx context.Context, namespace string) ([]string, error) {
+		var remaining []string
+		for _, gvr := range managedResources {
+			list, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{Limit: 1})
+			if err != nil {
+				if kapierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			if len(list.Items) > 0 {
+				remaining = append(remaining, fmt.Sprintf("%s.%s", gvr.Resource, gvr.Group))
+			}
+		}
+		sort.Strings(remaining)
+		return remaining, nil
+	}
+}
@@ -0,0 +1,151 @@
+package projectfinalize
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	projectapi "github.com/openshift/openshift-apiserver/pkg/project/apis/project"
+)
+
+type fakeResponder struct {
+	err error
+}
+
+func (r *fakeResponder) Object(statusCode int, obj runtime.Object) {}
+
+func (r *fakeResponder) Error(err error) {
+	r.err = err
+}
+
+func terminatingNamespace(finalizers ...corev1.FinalizerName) *corev1.Namespace {
+	now := metav1.Now()
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "stuck",
+			DeletionTimestamp: &now,
+		},
+		Spec: corev1.NamespaceSpec{Finalizers: finalizers},
+	}
+}
+
+func TestFinalizeRemovesOriginFinalizer(t *testing.T) {
+	client := kubefake.NewSimpleClientset(terminatingNamespace(
+		corev1.FinalizerKubernetes,
+		corev1.FinalizerName(projectapi.FinalizerOrigin),
+	))
+	storage := &REST{
+		client: client.CoreV1().Namespaces(),
+		listRemaining: func(ctx context.Context, namespace string) ([]string, error) {
+			return nil, nil
+		},
+	}
+
+	result, err := invoke(t, storage)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Finalized {
+		t.Error("expected the project to be finalized")
+	}
+
+	updated, err := client.CoreV1().Namespaces().Get(context.Background(), "stuck", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, finalizer := range updated.Spec.Finalizers {
+		if finalizer == corev1.FinalizerName(projectapi.FinalizerOrigin) {
+			t.Errorf("expected the origin finalizer to be removed, got %v", updated.Spec.Finalizers)
+		}
+	}
+	if len(updated.Spec.Finalizers) != 1 {
+		t.Errorf("expected the kubernetes finalizer to be kept, got %v", updated.Spec.Finalizers)
+	}
+}
+
+func TestFinalizeRefusesNamespaceNotTerminating(t *testing.T) {
+	namespace := terminatingNamespace(corev1.FinalizerName(projectapi.FinalizerOrigin))
+	namespace.DeletionTimestamp = nil
+	client := kubefake.NewSimpleClientset(namespace)
+	storage := &REST{
+		client: client.CoreV1().Namespaces(),
+		listRemaining: func(ctx context.Context, namespace string) ([]string, error) {
+			return nil, nil
+		},
+	}
+
+	if _, err := invoke(t, storage); !kapierrors.IsConflict(err) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestFinalizeRefusesRemainingResources(t *testing.T) {
+	client := kubefake.NewSimpleClientset(terminatingNamespace(corev1.FinalizerName(projectapi.FinalizerOrigin)))
+	storage := &REST{
+		client: client.CoreV1().Namespaces(),
+		listRemaining: func(ctx context.Context, namespace string) ([]string, error) {
+			return []string{"buildconfigs.build.openshift.io"}, nil
+		},
+	}
+
+	_, err := invoke(t, storage)
+	if !kapierrors.IsConflict(err) {
+		t.Fatalf("expected a conflict error, got %v", err)
+	}
+
+	updated, err := client.CoreV1().Namespaces().Get(context.Background(), "stuck", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.Spec.Finalizers) != 1 {
+		t.Errorf("expected the finalizer to be kept, got %v", updated.Spec.Finalizers)
+	}
+}
+
+func TestFinalizeIsIdempotent(t *testing.T) {
+	client := kubefake.NewSimpleClientset(terminatingNamespace(corev1.FinalizerKubernetes))
+	storage := &REST{
+		client: client.CoreV1().Namespaces(),
+		listRemaining: func(ctx context.Context, namespace string) ([]string, error) {
+			t.Error("no resources should be listed when the finalizer is already gone")
+			return nil, nil
+		},
+	}
+
+	result, err := invoke(t, storage)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Finalized {
+		t.Error("expected an already finalized project to report success")
+	}
+}
+
+func invoke(t *testing.T, storage *REST) (FinalizeResult, error) {
+	t.Helper()
+	responder := &fakeResponder{}
+	handler, err := storage.Connect(context.Background(), "stuck", nil, responder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/finalize", nil))
+	if responder.err != nil {
+		return FinalizeResult{}, responder.err
+	}
+
+	result := FinalizeResult{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("unable to decode response %q: %v", recorder.Body.String(), err)
+	}
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package delegated
+
+import (
+	"encoding/json"
+	"fmt"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	projectv1 "github.com/openshift/api/project/v1"
+)
+
+// CreatedObjectsAnnotation is set on the project returned from a successful
+// project request — on the response only, it is never persisted — and lists,
+// as a JSON array of TemplateObjectStatus, how each object of the project
+// template was applied.  Automation reading the response can tell a fully
+// applied template from one where some objects already existed.
+const CreatedObjectsAnnotation = "project.openshift.io/created-objects"
+
+// Status values of a TemplateObjectStatus.
+const (
+	// ObjectStatusCreated means the object was created by this request.
+	ObjectStatusCreated = "Created"
+	// ObjectStatusAlreadyExists means an object with this name already
+	// existed and was left unchanged.
+	ObjectStatusAlreadyExists = "AlreadyExists"
+)
+
+// TemplateObjectStatus records how one object from the project template was
+// applied to the new project.
+type TemplateObjectStatus struct {
+	// Resource is the fully qualified resource of the object, for example
+	// "rolebindings.rbac.authorization.k8s.io".
+	Resource string `json:"resource"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+}
+
+// annotateCreatedObjects records statuses on the project about to be returned
+// to the requester.
+func annotateCreatedObjects(project *projectv1.Project, statuses []TemplateObjectStatus) {
+	data, err := json.Marshal(statuses)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("error encoding the created objects of project %q: %v", project.Name, err))
+		return
+	}
+	if project.Annotations == nil {
+		project.Annotations = make(map[string]string)
+	}
+	project.Annotations[CreatedObjectsAnnotation] = string(data)
+}
@@ -0,0 +1,24 @@
+package delegated
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	projectv1 "github.com/openshift/api/project/v1"
+)
+
+func TestAnnotateCreatedObjects(t *testing.T) {
+	project := &projectv1.Project{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+
+	annotateCreatedObjects(project, []TemplateObjectStatus{
+		{Resource: "projects.project.openshift.io", Name: "test", Status: ObjectStatusCreated},
+		{Resource: "rolebindings.rbac.authorization.k8s.io", Name: "admin", Status: ObjectStatusAlreadyExists},
+	})
+
+	expected := `[{"resource":"projects.project.openshift.io","name":"test","status":"Created"},` +
+		`{"resource":"rolebindings.rbac.authorization.k8s.io","name":"admin","status":"AlreadyExists"}]`
+	if value := project.Annotations[CreatedObjectsAnnotation]; value != expected {
+		t.Errorf("expected annotation %s, got %s", expected, value)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/warning"
 	"k8s.io/client-go/dynamic"
 	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	rbacv1listers "k8s.io/client-go/listers/rbac/v1"
@@ -129,31 +130,17 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		return nil, apierror.NewAlreadyExists(project.Resource("project"), projectRequest.Name)
 	}
 
-	projectName := projectRequest.Name
-	projectAdmin := ""
-	projectRequester := ""
-	if userInfo, exists := apirequest.UserFrom(ctx); exists {
-		projectAdmin = userInfo.GetName()
-		projectRequester = userInfo.GetName()
-	}
+	userInfo, _ := apirequest.UserFrom(ctx)
 
 	template, err := r.getTemplate(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	parameterValues := templateParameterValues(projectRequest, userInfo, template)
 	for i := range template.Parameters {
-		switch template.Parameters[i].Name {
-		case ProjectAdminUserParam:
-			template.Parameters[i].Value = projectAdmin
-		case ProjectDescriptionParam:
-			template.Parameters[i].Value = projectRequest.Description
-		case ProjectDisplayNameParam:
-			template.Parameters[i].Value = projectRequest.DisplayName
-		case ProjectNameParam:
-			template.Parameters[i].Value = projectName
-		case ProjectRequesterParam:
-			template.Parameters[i].Value = projectRequester
+		if value, ok := parameterValues[template.Parameters[i].Name]; ok {
+			template.Parameters[i].Value = value
 		}
 	}
 
@@ -203,6 +190,9 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		}
 		return nil, err
 	}
+	objectStatuses := []TemplateObjectStatus{
+		{Resource: "projects.project.openshift.io", Name: createdProject.Name, Status: ObjectStatusCreated},
+	}
 
 	// TODO, stop doing this crazy thing, but for now it's a very simple way to get the unstructured objects we need
 	for _, toCreate := range objectsToCreate {
@@ -238,14 +228,15 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 			return nil, apierror.NewInternalError(scopeErr)
 		}
 
+		resource := restMapping.Resource.GroupResource().String()
 		_, createErr := r.client.Resource(restMapping.Resource).Namespace(createdProject.Name).Create(ctx, toCreate, metav1.CreateOptions{})
-		// if a default role binding already exists, we're probably racing the controller.  Don't die
-		if gvk := restMapping.GroupVersionKind; apierror.IsAlreadyExists(createErr) &&
-			gvk.Kind == roleBindingKind && roleBindingGroups.Has(gvk.Group) && defaultRoleBindingNames.Has(toCreate.GetName()) {
-			continue
-		}
 		// it is safe to ignore all such errors since stopOnErr will only let these through for the default role bindings
 		if apierror.IsAlreadyExists(createErr) {
+			objectStatuses = append(objectStatuses, TemplateObjectStatus{Resource: resource, Name: toCreate.GetName(), Status: ObjectStatusAlreadyExists})
+			// if a default role binding already exists, we're probably racing the controller.  Don't warn
+			if gvk := restMapping.GroupVersionKind; !(gvk.Kind == roleBindingKind && roleBindingGroups.Has(gvk.Group) && defaultRoleBindingNames.Has(toCreate.GetName())) {
+				warning.AddWarning(ctx, "", fmt.Sprintf("%s %q from the project template already existed and was left unchanged", resource, toCreate.GetName()))
+			}
 			continue
 		}
 		if createErr != nil {
@@ -256,6 +247,7 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 			}
 			return nil, apierror.NewInternalError(createErr)
 		}
+		objectStatuses = append(objectStatuses, TemplateObjectStatus{Resource: resource, Name: toCreate.GetName(), Status: ObjectStatusCreated})
 	}
 
 	// wait for a rolebinding if we created one
@@ -263,7 +255,12 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		r.waitForRoleBinding(createdProject.Name, lastRoleBindingName)
 	}
 
-	return r.projectGetter.Projects().Get(ctx, createdProject.Name, metav1.GetOptions{})
+	finalProject, err := r.projectGetter.Projects().Get(ctx, createdProject.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	annotateCreatedObjects(finalProject, objectStatuses)
+	return finalProject, nil
 }
 
 func (r *REST) waitForRoleBinding(namespace, name string) {
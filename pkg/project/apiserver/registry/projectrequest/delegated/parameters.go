@@ -0,0 +1,48 @@
+package delegated
+
+import (
+	"strings"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	templatev1 "github.com/openshift/api/template/v1"
+	projectapi "github.com/openshift/openshift-apiserver/pkg/project/apis/project"
+)
+
+// ParameterAnnotationPrefix marks template annotations whose suffix names a
+// template parameter.  Cluster admins can annotate the project request
+// template to inject fixed values, for example a cost center, without
+// granting requesters control over them:
+//
+//	project.openshift.io/parameter-COST_CENTER: engineering
+//
+// assigns the value "engineering" to the COST_CENTER parameter.  Only
+// parameters declared in the template are filled in.
+const ParameterAnnotationPrefix = "project.openshift.io/parameter-"
+
+// templateParameterValues returns the parameter values the project request
+// registry injects when instantiating the project request template: the
+// request fields, the requesting user's identity and groups, and any values
+// the cluster admin configured through annotations on the template itself.
+func templateParameterValues(projectRequest *projectapi.ProjectRequest, userInfo user.Info, template *templatev1.Template) map[string]string {
+	values := map[string]string{
+		ProjectNameParam:        projectRequest.Name,
+		ProjectDisplayNameParam: projectRequest.DisplayName,
+		ProjectDescriptionParam: projectRequest.Description,
+	}
+	if userInfo != nil {
+		values[ProjectAdminUserParam] = userInfo.GetName()
+		values[ProjectRequesterParam] = userInfo.GetName()
+		values[ProjectRequesterGroupsParam] = strings.Join(userInfo.GetGroups(), ",")
+	}
+
+	// annotation-configured values take precedence so that admins can pin
+	// any parameter, including the well known ones above
+	for key, value := range template.Annotations {
+		if name := strings.TrimPrefix(key, ParameterAnnotationPrefix); name != key && len(name) > 0 {
+			values[name] = value
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,89 @@
+package delegated
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	templatev1 "github.com/openshift/api/template/v1"
+	projectapi "github.com/openshift/openshift-apiserver/pkg/project/apis/project"
+)
+
+func TestTemplateParameterValues(t *testing.T) {
+	projectRequest := &projectapi.ProjectRequest{
+		ObjectMeta:  metav1.ObjectMeta{Name: "myproject"},
+		DisplayName: "My Project",
+		Description: "a project",
+	}
+	userInfo := &user.DefaultInfo{
+		Name:   "alice",
+		Groups: []string{"team-a", "team-b"},
+	}
+
+	tests := []struct {
+		name     string
+		template *templatev1.Template
+		user     user.Info
+		expected map[string]string
+	}{
+		{
+			name:     "request and user derived values",
+			template: &templatev1.Template{},
+			user:     userInfo,
+			expected: map[string]string{
+				ProjectNameParam:            "myproject",
+				ProjectDisplayNameParam:     "My Project",
+				ProjectDescriptionParam:     "a project",
+				ProjectAdminUserParam:       "alice",
+				ProjectRequesterParam:       "alice",
+				ProjectRequesterGroupsParam: "team-a,team-b",
+			},
+		},
+		{
+			name:     "no user on the request",
+			template: &templatev1.Template{},
+			expected: map[string]string{
+				ProjectNameParam:        "myproject",
+				ProjectDisplayNameParam: "My Project",
+				ProjectDescriptionParam: "a project",
+			},
+		},
+		{
+			name: "annotation configured values win",
+			template: &templatev1.Template{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ParameterAnnotationPrefix + "COST_CENTER":         "engineering",
+						ParameterAnnotationPrefix + ProjectRequesterParam: "pinned",
+						"project.openshift.io/unrelated":                  "ignored",
+						ParameterAnnotationPrefix:                         "ignored",
+					},
+				},
+			},
+			user: userInfo,
+			expected: map[string]string{
+				ProjectNameParam:            "myproject",
+				ProjectDisplayNameParam:     "My Project",
+				ProjectDescriptionParam:     "a project",
+				ProjectAdminUserParam:       "alice",
+				ProjectRequesterParam:       "pinned",
+				ProjectRequesterGroupsParam: "team-a,team-b",
+				"COST_CENTER":               "engineering",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		values := templateParameterValues(projectRequest, test.user, test.template)
+		if len(values) != len(test.expected) {
+			t.Errorf("%s: expected %d values, got %v", test.name, len(test.expected), values)
+			continue
+		}
+		for name, expected := range test.expected {
+			if actual := values[name]; actual != expected {
+				t.Errorf("%s: expected %s=%q, got %q", test.name, name, expected, actual)
+			}
+		}
+	}
+}
@@ -17,15 +17,16 @@ import (
 const (
 	DefaultTemplateName = "project-request"
 
-	ProjectNameParam        = "PROJECT_NAME"
-	ProjectDisplayNameParam = "PROJECT_DISPLAYNAME"
-	ProjectDescriptionParam = "PROJECT_DESCRIPTION"
-	ProjectAdminUserParam   = "PROJECT_ADMIN_USER"
-	ProjectRequesterParam   = "PROJECT_REQUESTING_USER"
+	ProjectNameParam            = "PROJECT_NAME"
+	ProjectDisplayNameParam     = "PROJECT_DISPLAYNAME"
+	ProjectDescriptionParam     = "PROJECT_DESCRIPTION"
+	ProjectAdminUserParam       = "PROJECT_ADMIN_USER"
+	ProjectRequesterParam       = "PROJECT_REQUESTING_USER"
+	ProjectRequesterGroupsParam = "PROJECT_REQUESTING_USER_GROUPS"
 )
 
 var (
-	parameters = []string{ProjectNameParam, ProjectDisplayNameParam, ProjectDescriptionParam, ProjectAdminUserParam, ProjectRequesterParam}
+	parameters = []string{ProjectNameParam, ProjectDisplayNameParam, ProjectDescriptionParam, ProjectAdminUserParam, ProjectRequesterParam, ProjectRequesterGroupsParam}
 )
 
 func DefaultTemplate() *templatev1.Template {
@@ -0,0 +1,163 @@
+package projectstatus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	quotav1typedclient "github.com/openshift/client-go/quota/clientset/versioned/typed/quota/v1"
+	projectapi "github.com/openshift/openshift-apiserver/pkg/project/apis/project"
+)
+
+// QuotaSummary condenses one resource quota in the project to the numbers the
+// console renders.
+type QuotaSummary struct {
+	Name string `json:"name"`
+	// Hard and Used map resource names to quantities, e.g. "pods": "10".
+	Hard map[string]string `json:"hard,omitempty"`
+	Used map[string]string `json:"used,omitempty"`
+}
+
+// ClusterQuotaSummary describes one cluster resource quota that selects the
+// project and the selector that matched it.
+type ClusterQuotaSummary struct {
+	Name string `json:"name"`
+	// LabelSelector is the string form of the quota's label selector, empty
+	// when the quota selects by annotations alone.
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// AnnotationSelector is the quota's annotation selector.
+	AnnotationSelector map[string]string `json:"annotationSelector,omitempty"`
+	Hard               map[string]string `json:"hard,omitempty"`
+	Used               map[string]string `json:"used,omitempty"`
+}
+
+// ProjectHealth is the response body of the projects status subresource.
+type ProjectHealth struct {
+	// Name is the project examined.
+	Name string `json:"name"`
+	// Phase is the namespace phase, Active or Terminating.
+	Phase corev1.NamespacePhase `json:"phase"`
+	// Conditions are the namespace conditions; while terminating they record
+	// which content is still being deleted.
+	Conditions []corev1.NamespaceCondition `json:"conditions,omitempty"`
+	// ResourceQuotas summarizes the quotas defined in the project.
+	ResourceQuotas []QuotaSummary `json:"resourceQuotas,omitempty"`
+	// ClusterResourceQuotas summarizes the cluster resource quotas whose
+	// selectors apply to the project.
+	ClusterResourceQuotas []ClusterQuotaSummary `json:"clusterResourceQuotas,omitempty"`
+}
+
+// REST assembles the namespace phase and conditions, the project's resource
+// quotas and the cluster resource quotas selecting it into a single response,
+// so the console can render a project overview with one call instead of four.
+// The Project type is a stable API object, so the aggregate is served as a
+// subresource with a plain JSON response.
+type REST struct {
+	namespaces    corev1client.NamespacesGetter
+	quotas        corev1client.ResourceQuotasGetter
+	appliedQuotas quotav1typedclient.AppliedClusterResourceQuotasGetter
+}
+
+var _ rest.Connecter = &REST{}
+var _ rest.Scoper = &REST{}
+
+// NewREST returns the handler for the projects/{name}/status subresource.
+func NewREST(client corev1client.CoreV1Interface, appliedQuotas quotav1typedclient.AppliedClusterResourceQuotasGetter) *REST {
+	return &REST{namespaces: client, quotas: client, appliedQuotas: appliedQuotas}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &projectapi.Project{}
+}
+
+func (r *REST) NamespaceScoped() bool {
+	return false
+}
+
+// ConnectMethods returns GET, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"GET"}
+}
+
+// NewConnectOptions returns an empty options object
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that computes and returns the project's health.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		result, err := r.projectHealth(req.Context(), name)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+func (r *REST) projectHealth(ctx context.Context, name string) (*ProjectHealth, error) {
+	ns, err := r.namespaces.Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProjectHealth{
+		Name:       name,
+		Phase:      ns.Status.Phase,
+		Conditions: ns.Status.Conditions,
+	}
+
+	quotas, err := r.quotas.ResourceQuotas(name).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, quota := range quotas.Items {
+		result.ResourceQuotas = append(result.ResourceQuotas, QuotaSummary{
+			Name: quota.Name,
+			Hard: resourceListToStrings(quota.Status.Hard),
+			Used: resourceListToStrings(quota.Status.Used),
+		})
+	}
+
+	appliedQuotas, err := r.appliedQuotas.AppliedClusterResourceQuotas(name).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, quota := range appliedQuotas.Items {
+		summary := ClusterQuotaSummary{
+			Name:               quota.Name,
+			AnnotationSelector: quota.Spec.Selector.AnnotationSelector,
+			Hard:               resourceListToStrings(quota.Status.Total.Hard),
+			Used:               resourceListToStrings(quota.Status.Total.Used),
+		}
+		if quota.Spec.Selector.LabelSelector != nil {
+			summary.LabelSelector = metav1.FormatLabelSelector(quota.Spec.Selector.LabelSelector)
+		}
+		result.ClusterResourceQuotas = append(result.ClusterResourceQuotas, summary)
+	}
+
+	return result, nil
+}
+
+func resourceListToStrings(list corev1.ResourceList) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(list))
+	for name, quantity := range list {
+		out[string(name)] = quantity.String()
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package projectstatus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	quotav1 "github.com/openshift/api/quota/v1"
+	quotav1typedclient "github.com/openshift/client-go/quota/clientset/versioned/typed/quota/v1"
+)
+
+type fakeAppliedQuotas struct {
+	items []quotav1.AppliedClusterResourceQuota
+}
+
+func (f *fakeAppliedQuotas) AppliedClusterResourceQuotas(namespace string) quotav1typedclient.AppliedClusterResourceQuotaInterface {
+	return f
+}
+
+func (f *fakeAppliedQuotas) Get(ctx context.Context, name string, opts metav1.GetOptions) (*quotav1.AppliedClusterResourceQuota, error) {
+	return nil, nil
+}
+
+func (f *fakeAppliedQuotas) List(ctx context.Context, opts metav1.ListOptions) (*quotav1.AppliedClusterResourceQuotaList, error) {
+	return &quotav1.AppliedClusterResourceQuotaList{Items: f.items}, nil
+}
+
+func TestProjectStatus(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Status: corev1.NamespaceStatus{
+				Phase: corev1.NamespaceTerminating,
+				Conditions: []corev1.NamespaceCondition{
+					{Type: corev1.NamespaceContentRemaining, Status: corev1.ConditionTrue, Message: "3 resources remaining"},
+				},
+			},
+		},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "test"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")},
+				Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("4")},
+			},
+		},
+	)
+	appliedQuotas := &fakeAppliedQuotas{
+		items: []quotav1.AppliedClusterResourceQuota{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "org-quota"},
+				Spec: quotav1.ClusterResourceQuotaSpec{
+					Selector: quotav1.ClusterResourceQuotaSelector{
+						LabelSelector:      &metav1.LabelSelector{MatchLabels: map[string]string{"org": "eng"}},
+						AnnotationSelector: map[string]string{"openshift.io/requester": "alice"},
+					},
+				},
+				Status: quotav1.ClusterResourceQuotaStatus{
+					Total: corev1.ResourceQuotaStatus{
+						Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("100")},
+					},
+				},
+			},
+		},
+	}
+
+	storage := NewREST(kubeClient.CoreV1(), appliedQuotas)
+	handler, err := storage.Connect(context.Background(), "test", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	result := ProjectHealth{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("unable to decode response %q: %v", recorder.Body.String(), err)
+	}
+	if result.Name != "test" || result.Phase != corev1.NamespaceTerminating {
+		t.Errorf("unexpected identity: %#v", result)
+	}
+	if len(result.Conditions) != 1 || result.Conditions[0].Type != corev1.NamespaceContentRemaining {
+		t.Errorf("expected the namespace conditions to be carried over, got %#v", result.Conditions)
+	}
+	expectedQuotas := []QuotaSummary{
+		{Name: "compute", Hard: map[string]string{"pods": "10"}, Used: map[string]string{"pods": "4"}},
+	}
+	if !reflect.DeepEqual(expectedQuotas, result.ResourceQuotas) {
+		t.Errorf("expected quotas %v, got %v", expectedQuotas, result.ResourceQuotas)
+	}
+	expectedClusterQuotas := []ClusterQuotaSummary{
+		{
+			Name:               "org-quota",
+			LabelSelector:      "org=eng",
+			AnnotationSelector: map[string]string{"openshift.io/requester": "alice"},
+			Hard:               map[string]string{"pods": "100"},
+		},
+	}
+	if !reflect.DeepEqual(expectedClusterQuotas, result.ClusterResourceQuotas) {
+		t.Errorf("expected cluster quotas %v, got %v", expectedClusterQuotas, result.ClusterResourceQuotas)
+	}
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"k8s.io/klog/v2"
 
@@ -64,19 +65,33 @@ type userProjectWatcher struct {
 	initialProjects []corev1.Namespace
 	// knownProjects maps name to resourceVersion
 	knownProjects map[string]string
+
+	// allowBookmarks enables periodic bookmark events carrying the most
+	// recently observed resource version.
+	allowBookmarks bool
+	// rvLock guards latestResourceVersion, which is written by the cache
+	// notification goroutine and read when emitting bookmarks.
+	rvLock                sync.Mutex
+	latestResourceVersion string
 }
 
+// bookmarkFrequency is how often a bookmark event is emitted to watchers that
+// requested them.  It is a variable so tests can shorten it.
+var bookmarkFrequency = time.Minute
+
 var (
 	// watchChannelHWM tracks how backed up the most backed up channel got.  This mirrors etcd watch behavior and allows tuning
 	// of channel depth.
 	watchChannelHWM kstorage.HighWaterMark
 )
 
-func NewUserProjectWatcher(user user.Info, visibleNamespaces sets.String, projectCache *projectcache.ProjectCache, authCache WatchableCache, includeAllExistingProjects bool, predicate kstorage.SelectionPredicate) *userProjectWatcher {
+func NewUserProjectWatcher(user user.Info, visibleNamespaces sets.String, projectCache *projectcache.ProjectCache, authCache WatchableCache, includeAllExistingProjects bool, allowBookmarks bool, predicate kstorage.SelectionPredicate) *userProjectWatcher {
 	namespaces, _ := authCache.List(user, labels.Everything())
 	knownProjects := map[string]string{}
+	latestResourceVersion := ""
 	for _, namespace := range namespaces.Items {
 		knownProjects[namespace.Name] = namespace.ResourceVersion
+		latestResourceVersion = namespace.ResourceVersion
 	}
 
 	// this is optional.  If they don't request it, don't include it.
@@ -98,11 +113,15 @@ func NewUserProjectWatcher(user user.Info, visibleNamespaces sets.String, projec
 		authCache:       authCache,
 		initialProjects: initialProjects,
 		knownProjects:   knownProjects,
+
+		allowBookmarks:        allowBookmarks,
+		latestResourceVersion: latestResourceVersion,
 	}
 	w.emit = func(e watch.Event) {
 		// if dealing with project events, ensure that we only emit events for projects
-		// that match the field or label selector specified by a consumer
-		if project, ok := e.Object.(*projectapi.Project); ok {
+		// that match the field or label selector specified by a consumer.
+		// Bookmarks only carry a resource version and bypass the selectors.
+		if project, ok := e.Object.(*projectapi.Project); ok && e.Type != watch.Bookmark {
 			if matches, err := predicate.Matches(project); err != nil || !matches {
 				return
 			}
@@ -171,6 +190,7 @@ func (w *userProjectWatcher) GroupMembershipChanged(namespaceName string, users,
 			}
 		}
 		w.knownProjects[namespaceName] = namespace.ResourceVersion
+		w.updateResourceVersion(namespace.ResourceVersion)
 
 		select {
 		case w.cacheIncoming <- event:
@@ -214,6 +234,13 @@ func (w *userProjectWatcher) Watch() {
 		})
 	}
 
+	var bookmarkCh <-chan time.Time
+	if w.allowBookmarks {
+		ticker := time.NewTicker(bookmarkFrequency)
+		defer ticker.Stop()
+		bookmarkCh = ticker.C
+	}
+
 	for {
 		select {
 		case err := <-w.cacheError:
@@ -223,6 +250,9 @@ func (w *userProjectWatcher) Watch() {
 		case <-w.userStop:
 			return
 
+		case <-bookmarkCh:
+			w.emit(w.bookmarkEvent())
+
 		case event := <-w.cacheIncoming:
 			if curLen := int64(len(w.cacheIncoming)); watchChannelHWM.Update(curLen) {
 				// Monitor if this gets backed up, and how much.
@@ -234,6 +264,23 @@ func (w *userProjectWatcher) Watch() {
 	}
 }
 
+func (w *userProjectWatcher) updateResourceVersion(version string) {
+	w.rvLock.Lock()
+	defer w.rvLock.Unlock()
+	w.latestResourceVersion = version
+}
+
+// bookmarkEvent returns a bookmark carrying the most recently observed
+// resource version, so clients can resume a broken watch without relisting.
+func (w *userProjectWatcher) bookmarkEvent() watch.Event {
+	w.rvLock.Lock()
+	defer w.rvLock.Unlock()
+	return watch.Event{
+		Type:   watch.Bookmark,
+		Object: &projectapi.Project{ObjectMeta: metav1.ObjectMeta{ResourceVersion: w.latestResourceVersion}},
+	}
+}
+
 func makeErrorEvent(err error) watch.Event {
 	return watch.Event{
 		Type: watch.Error,
@@ -41,7 +41,7 @@ func newTestWatcher(username string, groups []string, predicate storage.Selectio
 	stopCh := make(chan struct{})
 	go projectCache.Run(stopCh)
 
-	return NewUserProjectWatcher(&user.DefaultInfo{Name: username, Groups: groups}, sets.NewString("*"), projectCache, fakeAuthCache, false, predicate), fakeAuthCache, stopCh
+	return NewUserProjectWatcher(&user.DefaultInfo{Name: username, Groups: groups}, sets.NewString("*"), projectCache, fakeAuthCache, false, false, predicate), fakeAuthCache, stopCh
 }
 
 type fakeAuthCache struct {
@@ -259,6 +259,48 @@ func TestAddModifyDeleteEventsByGroup(t *testing.T) {
 	}
 }
 
+func TestWatchBookmarks(t *testing.T) {
+	previousFrequency := bookmarkFrequency
+	bookmarkFrequency = 10 * time.Millisecond
+	defer func() { bookmarkFrequency = previousFrequency }()
+
+	mockClient := fakev1.NewSimpleClientset()
+	informers := informersv1.NewSharedInformerFactory(mockClient, controller.NoResyncPeriodFunc())
+	projectCache := projectcache.NewProjectCache(
+		informers.Core().V1().Namespaces().Informer(),
+		mockClient.CoreV1().Namespaces(),
+		"",
+	)
+	fakeAuthCache := &fakeAuthCache{
+		namespaces: []*corev1.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "ns-01", ResourceVersion: "5"}},
+		},
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go projectCache.Run(stopCh)
+
+	watcher := NewUserProjectWatcher(&user.DefaultInfo{Name: "bob"}, sets.NewString("*"), projectCache, fakeAuthCache, false, true, matchAllPredicate())
+	go watcher.Watch()
+	defer watcher.Stop()
+
+	select {
+	case event := <-watcher.ResultChan():
+		if event.Type != watch.Bookmark {
+			t.Fatalf("expected a bookmark event, got %#v", event)
+		}
+		project, ok := event.Object.(*projectapi.Project)
+		if !ok {
+			t.Fatalf("expected a project, got %#v", event.Object)
+		}
+		if project.ResourceVersion != "5" {
+			t.Errorf("expected the latest known resource version, got %q", project.ResourceVersion)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for a bookmark event")
+	}
+}
+
 func newNamespaces(names ...string) []*corev1.Namespace {
 	ret := []*corev1.Namespace{}
 	for _, name := range names {
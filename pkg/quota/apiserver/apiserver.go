@@ -9,15 +9,20 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apiserver/pkg/registry/rest"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/client-go/dynamic"
+	restclient "k8s.io/client-go/rest"
 
 	quotaapiv1 "github.com/openshift/api/quota/v1"
+	quotaclient "github.com/openshift/client-go/quota/clientset/versioned"
 	quotainformer "github.com/openshift/client-go/quota/informers/externalversions"
 	"github.com/openshift/library-go/pkg/quota/clusterquotamapping"
 	appliedclusterresourcequotaregistry "github.com/openshift/openshift-apiserver/pkg/quota/apiserver/registry/appliedclusterresourcequota"
 	clusterresourcequotaetcd "github.com/openshift/openshift-apiserver/pkg/quota/apiserver/registry/clusterresourcequota/etcd"
+	clusterresourcequotarecalculate "github.com/openshift/openshift-apiserver/pkg/quota/apiserver/registry/clusterresourcequota/recalculate"
 )
 
 type ExtraConfig struct {
+	KubeAPIServerClientConfig     *restclient.Config
 	ClusterQuotaMappingController *clusterquotamapping.ClusterQuotaMappingController
 	QuotaInformers                quotainformer.SharedInformerFactory
 
@@ -97,10 +102,23 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error building REST storage: %v", err)
 	}
+	quotaClient, err := quotaclient.NewForConfig(c.ExtraConfig.KubeAPIServerClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(c.ExtraConfig.KubeAPIServerClientConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	v1Storage := map[string]rest.Storage{}
 	v1Storage["clusterResourceQuotas"] = clusterResourceQuotaStorage
 	v1Storage["clusterResourceQuotas/status"] = clusterResourceQuotaStatusStorage
+	v1Storage["clusterResourceQuotas/recalculate"] = clusterresourcequotarecalculate.NewREST(
+		quotaClient.QuotaV1(),
+		c.ExtraConfig.ClusterQuotaMappingController.GetClusterQuotaMapper(),
+		dynamicClient,
+	)
 	v1Storage["appliedClusterResourceQuotas"] = appliedclusterresourcequotaregistry.NewREST(
 		c.ExtraConfig.ClusterQuotaMappingController.GetClusterQuotaMapper(),
 		c.ExtraConfig.QuotaInformers.Quota().V1().ClusterResourceQuotas().Lister(),
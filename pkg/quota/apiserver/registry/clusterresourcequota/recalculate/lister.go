@@ -0,0 +1,99 @@
+package recalculate
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apiserverquota "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/client-go/dynamic"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+)
+
+// listerForResourceFunc provides the quota evaluators with listers that read
+// straight from the API instead of an informer cache. Recalculation is a rare,
+// explicitly requested operation, so the extra round trips are preferable to
+// answering from caches this server never runs informers for.
+func listerForResourceFunc(dynamicClient dynamic.Interface) apiserverquota.ListerForResourceFunc {
+	return func(gvr schema.GroupVersionResource) (cache.GenericLister, error) {
+		return &liveLister{client: dynamicClient.Resource(gvr)}, nil
+	}
+}
+
+type liveLister struct {
+	client dynamic.NamespaceableResourceInterface
+}
+
+var _ cache.GenericLister = &liveLister{}
+
+func (l *liveLister) List(selector labels.Selector) ([]runtime.Object, error) {
+	return listResources(l.client, selector)
+}
+
+func (l *liveLister) Get(name string) (runtime.Object, error) {
+	obj, err := l.client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return typedObject(obj), nil
+}
+
+func (l *liveLister) ByNamespace(namespace string) cache.GenericNamespaceLister {
+	return &liveNamespaceLister{client: l.client.Namespace(namespace)}
+}
+
+type liveNamespaceLister struct {
+	client dynamic.ResourceInterface
+}
+
+func (l *liveNamespaceLister) List(selector labels.Selector) ([]runtime.Object, error) {
+	return listResources(l.client, selector)
+}
+
+func (l *liveNamespaceLister) Get(name string) (runtime.Object, error) {
+	obj, err := l.client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return typedObject(obj), nil
+}
+
+func listResources(client dynamic.ResourceInterface, selector labels.Selector) ([]runtime.Object, error) {
+	list, err := client.List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	// the server does not set the type meta of individual list items, so
+	// derive it from the list before handing objects to the evaluators
+	itemGVK := list.GroupVersionKind()
+	itemGVK.Kind = strings.TrimSuffix(itemGVK.Kind, "List")
+	ret := make([]runtime.Object, 0, len(list.Items))
+	for i := range list.Items {
+		item := &list.Items[i]
+		if item.GroupVersionKind().Empty() {
+			item.SetGroupVersionKind(itemGVK)
+		}
+		ret = append(ret, typedObject(item))
+	}
+	return ret, nil
+}
+
+// typedObject converts an unstructured object into its typed equivalent when
+// the kind is known to the client-go scheme. Evaluators that compute more
+// than counts need typed objects; for everything else the unstructured form
+// is sufficient.
+func typedObject(obj *unstructured.Unstructured) runtime.Object {
+	typed, err := kubescheme.Scheme.New(obj.GroupVersionKind())
+	if err != nil {
+		return obj
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+		return obj
+	}
+	return typed
+}
@@ -0,0 +1,149 @@
+package recalculate
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	apiserverquota "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/apiserver/pkg/quota/v1/generic"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/dynamic"
+	quotainstall "k8s.io/kubernetes/pkg/quota/v1/install"
+
+	quotav1 "github.com/openshift/api/quota/v1"
+	quotatypedclient "github.com/openshift/client-go/quota/clientset/versioned/typed/quota/v1"
+	"github.com/openshift/library-go/pkg/quota/clusterquotamapping"
+	"github.com/openshift/library-go/pkg/quota/quotautil"
+
+	quotaapi "github.com/openshift/openshift-apiserver/pkg/quota/apis/quota"
+	quotav1conversions "github.com/openshift/openshift-apiserver/pkg/quota/apis/quota/v1"
+	"github.com/openshift/openshift-apiserver/pkg/quota/objectcount"
+)
+
+// quotaUpdater is the subset of the cluster resource quota client the
+// endpoint needs, narrowed so tests can supply a fake.
+type quotaUpdater interface {
+	Get(ctx context.Context, name string, options metav1.GetOptions) (*quotav1.ClusterResourceQuota, error)
+	UpdateStatus(ctx context.Context, clusterResourceQuota *quotav1.ClusterResourceQuota, options metav1.UpdateOptions) (*quotav1.ClusterResourceQuota, error)
+}
+
+// REST implements the clusterresourcequotas/recalculate subresource. Creating
+// against it recomputes the usage of the named quota across all namespaces it
+// currently selects, persists the result in the quota status and returns the
+// updated object. It exists for administrators who need usage brought up to
+// date immediately, for instance after a bulk deletion, instead of waiting
+// for the controller resync.
+type REST struct {
+	quotaClient quotaUpdater
+	quotaMapper clusterquotamapping.ClusterQuotaMapper
+	registry    apiserverquota.Registry
+}
+
+var _ rest.NamedCreater = &REST{}
+var _ rest.Scoper = &REST{}
+
+// NewREST returns a RESTStorage object for recalculating cluster resource
+// quota usage. Usage is listed directly from the API through the dynamic
+// client rather than from an informer cache so the recomputation reflects the
+// current cluster state.
+func NewREST(quotaClient quotatypedclient.ClusterResourceQuotasGetter, quotaMapper clusterquotamapping.ClusterQuotaMapper, dynamicClient dynamic.Interface) *REST {
+	listerFunc := listerForResourceFunc(dynamicClient)
+	quotaConfiguration := quotainstall.NewQuotaConfigurationForControllers(listerFunc)
+	registry := generic.NewRegistry(quotaConfiguration.Evaluators())
+	for _, evaluator := range objectcount.NewEvaluators(listerFunc) {
+		registry.Add(evaluator)
+	}
+	return newREST(quotaClient.ClusterResourceQuotas(), quotaMapper, registry)
+}
+
+func newREST(quotaClient quotaUpdater, quotaMapper clusterquotamapping.ClusterQuotaMapper, registry apiserverquota.Registry) *REST {
+	return &REST{
+		quotaClient: quotaClient,
+		quotaMapper: quotaMapper,
+		registry:    registry,
+	}
+}
+
+func (r *REST) New() runtime.Object {
+	return &quotaapi.ClusterResourceQuota{}
+}
+
+func (r *REST) NamespaceScoped() bool {
+	return false
+}
+
+// Create recomputes the usage of the cluster resource quota named in the
+// request path and persists it.
+func (r *REST) Create(ctx context.Context, name string, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	request, ok := obj.(*quotaapi.ClusterResourceQuota)
+	if !ok {
+		return nil, kapierrors.NewBadRequest(fmt.Sprintf("not a ClusterResourceQuota: %#v", obj))
+	}
+	if len(request.Name) > 0 && request.Name != name {
+		return nil, kapierrors.NewBadRequest(fmt.Sprintf("name %q does not match the request path %q", request.Name, name))
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, obj); err != nil {
+			return nil, err
+		}
+	}
+
+	clusterQuota, err := r.quotaClient.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, _ := r.quotaMapper.GetNamespacesFor(name)
+
+	total := corev1.ResourceList{}
+	namespaceStatuses := quotav1.ResourceQuotasStatusByNamespace{}
+	var errs []error
+	for _, namespace := range namespaces {
+		recalculated, err := apiserverquota.CalculateUsage(namespace, clusterQuota.Spec.Quota.Scopes, clusterQuota.Spec.Quota.Hard, r.registry, clusterQuota.Spec.Quota.ScopeSelector)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error calculating usage in namespace %s: %v", namespace, err))
+			continue
+		}
+		used := corev1.ResourceList{}
+		for resourceName, value := range recalculated {
+			used[resourceName] = value
+		}
+		// resources no registered evaluator covers keep the usage the
+		// controller last recorded
+		if previous, exists := quotautil.GetResourceQuotasStatusByNamespace(clusterQuota.Status.Namespaces, namespace); exists {
+			for resourceName, value := range previous.Used {
+				if _, recalculatedResource := used[resourceName]; !recalculatedResource {
+					used[resourceName] = value
+				}
+			}
+		}
+		quotautil.InsertResourceQuotasStatus(&namespaceStatuses, quotav1.ResourceQuotaStatusByNamespace{
+			Namespace: namespace,
+			Status:    corev1.ResourceQuotaStatus{Hard: clusterQuota.Spec.Quota.Hard, Used: used},
+		})
+		total = apiserverquota.Add(total, used)
+	}
+	if len(errs) > 0 {
+		return nil, kapierrors.NewInternalError(utilerrors.NewAggregate(errs))
+	}
+
+	clusterQuota.Status.Total.Hard = clusterQuota.Spec.Quota.Hard
+	clusterQuota.Status.Total.Used = total
+	clusterQuota.Status.Namespaces = namespaceStatuses
+
+	updated, err := r.quotaClient.UpdateStatus(ctx, clusterQuota, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &quotaapi.ClusterResourceQuota{}
+	if err := quotav1conversions.Convert_v1_ClusterResourceQuota_To_quota_ClusterResourceQuota(updated, ret, nil); err != nil {
+		return nil, kapierrors.NewInternalError(err)
+	}
+	return ret, nil
+}
@@ -0,0 +1,158 @@
+package recalculate
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apiserverquota "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/apiserver/pkg/quota/v1/generic"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	quotav1 "github.com/openshift/api/quota/v1"
+	"github.com/openshift/library-go/pkg/quota/clusterquotamapping"
+	"github.com/openshift/library-go/pkg/quota/quotautil"
+
+	quotaapi "github.com/openshift/openshift-apiserver/pkg/quota/apis/quota"
+)
+
+type fakeQuotaUpdater struct {
+	quota   *quotav1.ClusterResourceQuota
+	updated *quotav1.ClusterResourceQuota
+}
+
+func (f *fakeQuotaUpdater) Get(ctx context.Context, name string, options metav1.GetOptions) (*quotav1.ClusterResourceQuota, error) {
+	if f.quota == nil || f.quota.Name != name {
+		return nil, kapierrors.NewNotFound(quotav1.Resource("clusterresourcequotas"), name)
+	}
+	return f.quota.DeepCopy(), nil
+}
+
+func (f *fakeQuotaUpdater) UpdateStatus(ctx context.Context, clusterResourceQuota *quotav1.ClusterResourceQuota, options metav1.UpdateOptions) (*quotav1.ClusterResourceQuota, error) {
+	f.updated = clusterResourceQuota.DeepCopy()
+	return f.updated, nil
+}
+
+type fakeQuotaMapper struct {
+	namespaces []string
+}
+
+func (f *fakeQuotaMapper) GetClusterQuotasFor(namespaceName string) ([]string, clusterquotamapping.SelectionFields) {
+	return nil, clusterquotamapping.SelectionFields{}
+}
+
+func (f *fakeQuotaMapper) GetNamespacesFor(quotaName string) ([]string, quotav1.ClusterResourceQuotaSelector) {
+	return f.namespaces, quotav1.ClusterResourceQuotaSelector{}
+}
+
+func (f *fakeQuotaMapper) AddListener(listener clusterquotamapping.MappingChangeListener) {}
+
+// secretCountRegistry counts a fixed number of secrets per namespace
+func secretCountRegistry(secretsByNamespace map[string]int) apiserverquota.Registry {
+	listFunc := func(namespace string) ([]runtime.Object, error) {
+		var ret []runtime.Object
+		for i := 0; i < secretsByNamespace[namespace]; i++ {
+			ret = append(ret, &corev1.Secret{})
+		}
+		return ret, nil
+	}
+	evaluator := generic.NewObjectCountEvaluator(schema.GroupResource{Resource: "secrets"}, listFunc, corev1.ResourceSecrets)
+	return generic.NewRegistry([]apiserverquota.Evaluator{evaluator})
+}
+
+func stubQuota() *quotav1.ClusterResourceQuota {
+	return &quotav1.ClusterResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "overall"},
+		Spec: quotav1.ClusterResourceQuotaSpec{
+			Quota: corev1.ResourceQuotaSpec{
+				Hard: corev1.ResourceList{corev1.ResourceSecrets: resource.MustParse("10")},
+			},
+		},
+		Status: quotav1.ClusterResourceQuotaStatus{
+			Total: corev1.ResourceQuotaStatus{
+				Used: corev1.ResourceList{corev1.ResourceSecrets: resource.MustParse("9")},
+			},
+		},
+	}
+}
+
+func TestRecalculate(t *testing.T) {
+	client := &fakeQuotaUpdater{quota: stubQuota()}
+	storage := newREST(
+		client,
+		&fakeQuotaMapper{namespaces: []string{"one", "two"}},
+		secretCountRegistry(map[string]int{"one": 3, "two": 2}),
+	)
+
+	obj, err := storage.Create(context.TODO(), "overall", &quotaapi.ClusterResourceQuota{}, nil, &metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ret, ok := obj.(*quotaapi.ClusterResourceQuota)
+	if !ok {
+		t.Fatalf("unexpected object: %#v", obj)
+	}
+	if used := ret.Status.Total.Used[kapi.ResourceSecrets]; used.Value() != 5 {
+		t.Errorf("expected total usage of 5 secrets, got %s", used.String())
+	}
+	if client.updated == nil {
+		t.Fatal("expected the recomputed status to be persisted")
+	}
+	oneStatus, exists := quotautil.GetResourceQuotasStatusByNamespace(client.updated.Status.Namespaces, "one")
+	if !exists {
+		t.Fatal("expected a status for namespace one")
+	}
+	if used := oneStatus.Used[corev1.ResourceSecrets]; used.Value() != 3 {
+		t.Errorf("expected namespace one to use 3 secrets, got %s", used.String())
+	}
+}
+
+func TestRecalculatePreservesUntrackedUsage(t *testing.T) {
+	imageStreams := corev1.ResourceName("openshift.io/imagestreams")
+	clusterQuota := stubQuota()
+	quotautil.InsertResourceQuotasStatus(&clusterQuota.Status.Namespaces, quotav1.ResourceQuotaStatusByNamespace{
+		Namespace: "one",
+		Status: corev1.ResourceQuotaStatus{
+			Used: corev1.ResourceList{imageStreams: resource.MustParse("4")},
+		},
+	})
+	client := &fakeQuotaUpdater{quota: clusterQuota}
+	storage := newREST(
+		client,
+		&fakeQuotaMapper{namespaces: []string{"one"}},
+		secretCountRegistry(map[string]int{"one": 1}),
+	)
+
+	obj, err := storage.Create(context.TODO(), "overall", &quotaapi.ClusterResourceQuota{}, nil, &metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ret := obj.(*quotaapi.ClusterResourceQuota)
+	oneStatus, _ := quotautil.GetResourceQuotasStatusByNamespace(client.updated.Status.Namespaces, "one")
+	if used := oneStatus.Used[imageStreams]; used.Value() != 4 {
+		t.Errorf("expected the untracked imagestream usage to be preserved, got %s", used.String())
+	}
+	if used := ret.Status.Total.Used[kapi.ResourceSecrets]; used.Value() != 1 {
+		t.Errorf("expected total usage of 1 secret, got %s", used.String())
+	}
+}
+
+func TestRecalculateNameMismatch(t *testing.T) {
+	storage := newREST(
+		&fakeQuotaUpdater{quota: stubQuota()},
+		&fakeQuotaMapper{},
+		secretCountRegistry(nil),
+	)
+
+	request := &quotaapi.ClusterResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	if _, err := storage.Create(context.TODO(), "overall", request, nil, &metav1.CreateOptions{}); !kapierrors.IsBadRequest(err) {
+		t.Errorf("expected a bad request error, got: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package image
+
+import (
+	"fmt"
+
+	"github.com/openshift/openshift-apiserver/pkg/quota/quotaimageexternal"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kadmission "k8s.io/apiserver/pkg/admission"
+	kquota "k8s.io/apiserver/pkg/quota/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imagev1lister "github.com/openshift/client-go/image/listers/image/v1"
+	imageapi "github.com/openshift/openshift-apiserver/pkg/image/apis/image"
+	imagev1conversions "github.com/openshift/openshift-apiserver/pkg/image/apis/image/v1"
+)
+
+type imageStreamEvaluator struct {
+	externalEvaluator kquota.Evaluator
+}
+
+// NewImageStreamEvaluator computes resource usage of ImageStreams, accounting tag counts,
+// unique image references and an estimate of consumed registry storage in addition to the
+// plain object count.
+func NewImageStreamEvaluator(store imagev1lister.ImageStreamLister, imageLister imagev1lister.ImageLister) kquota.Evaluator {
+	return &imageStreamEvaluator{
+		externalEvaluator: quotaimageexternal.NewImageStreamEvaluator(store, imageLister),
+	}
+}
+
+// Constraints checks that given object is an image stream
+func (i *imageStreamEvaluator) Constraints(required []corev1.ResourceName, object runtime.Object) error {
+	_, okInt := object.(*imageapi.ImageStream)
+	if okInt {
+		return nil
+	}
+	return i.externalEvaluator.Constraints(required, object)
+}
+
+func (i *imageStreamEvaluator) GroupResource() schema.GroupResource {
+	return i.externalEvaluator.GroupResource()
+}
+
+func (i *imageStreamEvaluator) Handles(a kadmission.Attributes) bool {
+	return i.externalEvaluator.Handles(a)
+}
+
+func (i *imageStreamEvaluator) Matches(resourceQuota *corev1.ResourceQuota, item runtime.Object) (bool, error) {
+	return i.externalEvaluator.Matches(resourceQuota, item)
+}
+
+func (i *imageStreamEvaluator) MatchingScopes(item runtime.Object, scopes []corev1.ScopedResourceSelectorRequirement) ([]corev1.ScopedResourceSelectorRequirement, error) {
+	return i.externalEvaluator.MatchingScopes(item, scopes)
+}
+
+func (i *imageStreamEvaluator) UncoveredQuotaScopes(limitedScopes []corev1.ScopedResourceSelectorRequirement, matchedQuotaScopes []corev1.ScopedResourceSelectorRequirement) ([]corev1.ScopedResourceSelectorRequirement, error) {
+	return i.externalEvaluator.UncoveredQuotaScopes(limitedScopes, matchedQuotaScopes)
+}
+
+func (i *imageStreamEvaluator) MatchingResources(input []corev1.ResourceName) []corev1.ResourceName {
+	return i.externalEvaluator.MatchingResources(input)
+}
+
+func (i *imageStreamEvaluator) Usage(item runtime.Object) (corev1.ResourceList, error) {
+	if isInternal, ok := item.(*imageapi.ImageStream); ok {
+		out := &imagev1.ImageStream{}
+		if err := imagev1conversions.Convert_image_ImageStream_To_v1_ImageStream(isInternal, out, nil); err != nil {
+			return corev1.ResourceList{}, fmt.Errorf("error converting ImageStream: %v", err)
+		}
+		item = out
+	}
+	return i.externalEvaluator.Usage(item)
+}
+
+func (i *imageStreamEvaluator) UsageStats(options kquota.UsageStatsOptions) (kquota.UsageStats, error) {
+	return i.externalEvaluator.UsageStats(options)
+}
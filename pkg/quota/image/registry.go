@@ -3,31 +3,21 @@
 package image
 
 import (
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	apiserverquotav1 "k8s.io/apiserver/pkg/quota/v1"
-	"k8s.io/apiserver/pkg/quota/v1/generic"
 
-	imagev1 "github.com/openshift/api/image/v1"
 	imagev1typedclient "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
 	imagev1informer "github.com/openshift/client-go/image/informers/externalversions/image/v1"
 )
 
-var legacyObjectCountAliases = map[schema.GroupVersionResource]corev1.ResourceName{
-	imagev1.GroupVersion.WithResource("imagestreams"): imagev1.ResourceImageStreams,
-}
-
 // NewEvaluators returns the list of static evaluators that manage more than counts
-func NewReplenishmentEvaluators(f apiserverquotav1.ListerForResourceFunc, isInformer imagev1informer.ImageStreamInformer, imageClient imagev1typedclient.ImageStreamTagsGetter) []apiserverquotav1.Evaluator {
+func NewReplenishmentEvaluators(f apiserverquotav1.ListerForResourceFunc, isInformer imagev1informer.ImageStreamInformer, imageInformer imagev1informer.ImageInformer, imageClient imagev1typedclient.ImageStreamTagsGetter) []apiserverquotav1.Evaluator {
 	// these evaluators have special logic
-	result := []apiserverquotav1.Evaluator{
+	return []apiserverquotav1.Evaluator{
 		NewImageStreamTagEvaluator(isInformer.Lister(), imageClient),
 		NewImageStreamImportEvaluator(isInformer.Lister()),
+		// replaces the plain object count evaluator for image streams, still serving
+		// the openshift.io/imagestreams alias while also accounting tag counts and
+		// estimated registry storage per stream
+		NewImageStreamEvaluator(isInformer.Lister(), imageInformer.Lister()),
 	}
-	// these evaluators require an alias for backwards compatibility
-	for gvr, alias := range legacyObjectCountAliases {
-		result = append(result,
-			generic.NewObjectCountEvaluator(gvr.GroupResource(), generic.ListResourceUsingListerFunc(f, gvr), alias))
-	}
-	return result
 }
@@ -0,0 +1,46 @@
+// Package objectcount provides plain object count evaluators for the
+// OpenShift resources cluster resource quotas commonly limit. They are meant
+// to be added to the quota registries used by admission and by usage
+// recalculation alongside the core kubernetes evaluators.
+package objectcount
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apiserverquotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/apiserver/pkg/quota/v1/generic"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	templatev1 "github.com/openshift/api/template/v1"
+)
+
+// countedResources are the OpenShift resources that participate in object
+// count quotas. Each is served under the standard count/<resource>.<group>
+// name plus an openshift.io alias matching the style of the
+// openshift.io/imagestreams name the image stream evaluator serves. Image
+// streams are absent because their dedicated evaluator already counts them.
+var countedResources = []struct {
+	gvr   schema.GroupVersionResource
+	alias corev1.ResourceName
+}{
+	{gvr: routev1.GroupVersion.WithResource("routes"), alias: "openshift.io/routes"},
+	{gvr: buildv1.GroupVersion.WithResource("buildconfigs"), alias: "openshift.io/buildconfigs"},
+	{gvr: buildv1.GroupVersion.WithResource("builds"), alias: "openshift.io/builds"},
+	{gvr: templatev1.GroupVersion.WithResource("templates"), alias: "openshift.io/templates"},
+}
+
+// NewEvaluators returns object count evaluators for the counted resources.
+// The lister func may be nil for registries that only serve admission, which
+// never enumerates existing usage.
+func NewEvaluators(f apiserverquotav1.ListerForResourceFunc) []apiserverquotav1.Evaluator {
+	evaluators := make([]apiserverquotav1.Evaluator, 0, len(countedResources))
+	for _, counted := range countedResources {
+		var listFunc generic.ListFuncByNamespace
+		if f != nil {
+			listFunc = generic.ListResourceUsingListerFunc(f, counted.gvr)
+		}
+		evaluators = append(evaluators, generic.NewObjectCountEvaluator(counted.gvr.GroupResource(), listFunc, counted.alias))
+	}
+	return evaluators
+}
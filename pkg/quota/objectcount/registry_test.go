@@ -0,0 +1,32 @@
+package objectcount
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewEvaluatorsMatchCountAndAliasNames(t *testing.T) {
+	expected := map[string][]corev1.ResourceName{
+		"routes.route.openshift.io":       {"count/routes.route.openshift.io", "openshift.io/routes"},
+		"buildconfigs.build.openshift.io": {"count/buildconfigs.build.openshift.io", "openshift.io/buildconfigs"},
+		"builds.build.openshift.io":       {"count/builds.build.openshift.io", "openshift.io/builds"},
+		"templates.template.openshift.io": {"count/templates.template.openshift.io", "openshift.io/templates"},
+	}
+
+	evaluators := NewEvaluators(nil)
+	if len(evaluators) != len(expected) {
+		t.Fatalf("expected %d evaluators, got %d", len(expected), len(evaluators))
+	}
+	for _, evaluator := range evaluators {
+		names, ok := expected[evaluator.GroupResource().String()]
+		if !ok {
+			t.Errorf("unexpected evaluator for %s", evaluator.GroupResource())
+			continue
+		}
+		if matched := evaluator.MatchingResources(names); !reflect.DeepEqual(matched, names) {
+			t.Errorf("%s: expected %v to be matched, got %v", evaluator.GroupResource(), names, matched)
+		}
+	}
+}
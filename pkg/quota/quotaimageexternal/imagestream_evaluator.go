@@ -0,0 +1,153 @@
+package quotaimageexternal
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kadmission "k8s.io/apiserver/pkg/admission"
+	kquota "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/apiserver/pkg/quota/v1/generic"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imagev1lister "github.com/openshift/client-go/image/listers/image/v1"
+)
+
+// ResourceImageStreamStorage estimates the registry storage consumed by the images
+// referenced in a single image stream, computed as the sum of the layer sizes of
+// every unique image recorded in the stream status.  Layers shared between images
+// are counted once per image, so the estimate is an upper bound.
+const ResourceImageStreamStorage corev1.ResourceName = "openshift.io/imagestream-storage"
+
+var imageStreamResources = []corev1.ResourceName{
+	imagev1.ResourceImageStreams,
+	imagev1.ResourceImageStreamTags,
+	imagev1.ResourceImageStreamImages,
+	ResourceImageStreamStorage,
+}
+
+type imageStreamEvaluator struct {
+	store       imagev1lister.ImageStreamLister
+	imageLister imagev1lister.ImageLister
+}
+
+// NewImageStreamEvaluator computes resource usage of ImageStreams.  Beyond the plain
+// object count it accounts the number of tags, the number of unique images and an
+// estimate of the registry storage those images consume, so cluster quotas can bound
+// registry consumption per tenant.
+// If you make a change here, be sure to make a corresponding change in the openshift-controller-manager
+func NewImageStreamEvaluator(store imagev1lister.ImageStreamLister, imageLister imagev1lister.ImageLister) kquota.Evaluator {
+	return &imageStreamEvaluator{
+		store:       store,
+		imageLister: imageLister,
+	}
+}
+
+// Constraints checks that given object is an image stream
+func (i *imageStreamEvaluator) Constraints(required []corev1.ResourceName, object runtime.Object) error {
+	_, okExt := object.(*imagev1.ImageStream)
+	if !okExt {
+		return fmt.Errorf("unexpected input object %v", object)
+	}
+	return nil
+}
+
+func (i *imageStreamEvaluator) GroupResource() schema.GroupResource {
+	return schema.GroupResource{Group: "image.openshift.io", Resource: "imagestreams"}
+}
+
+func (i *imageStreamEvaluator) Handles(a kadmission.Attributes) bool {
+	operation := a.GetOperation()
+	return operation == kadmission.Create || operation == kadmission.Update
+}
+
+func (i *imageStreamEvaluator) Matches(resourceQuota *corev1.ResourceQuota, item runtime.Object) (bool, error) {
+	matchesScopeFunc := func(corev1.ScopedResourceSelectorRequirement, runtime.Object) (bool, error) { return true, nil }
+	return generic.Matches(resourceQuota, item, i.MatchingResources, matchesScopeFunc)
+}
+
+func (p *imageStreamEvaluator) MatchingScopes(item runtime.Object, scopes []corev1.ScopedResourceSelectorRequirement) ([]corev1.ScopedResourceSelectorRequirement, error) {
+	return []corev1.ScopedResourceSelectorRequirement{}, nil
+}
+
+func (p *imageStreamEvaluator) UncoveredQuotaScopes(limitedScopes []corev1.ScopedResourceSelectorRequirement, matchedQuotaScopes []corev1.ScopedResourceSelectorRequirement) ([]corev1.ScopedResourceSelectorRequirement, error) {
+	return []corev1.ScopedResourceSelectorRequirement{}, nil
+}
+
+func (i *imageStreamEvaluator) MatchingResources(input []corev1.ResourceName) []corev1.ResourceName {
+	matched := kquota.Intersection(input, imageStreamResources)
+	// preserve the generic object count resource the plain evaluator used to serve
+	countName := generic.ObjectCountQuotaResourceNameFor(i.GroupResource())
+	for _, name := range input {
+		if name == countName {
+			matched = append(matched, name)
+		}
+	}
+	return matched
+}
+
+func (i *imageStreamEvaluator) Usage(item runtime.Object) (corev1.ResourceList, error) {
+	is, ok := item.(*imagev1.ImageStream)
+	if !ok {
+		return corev1.ResourceList{}, fmt.Errorf("item is not an ImageStream: %T", item)
+	}
+
+	tags := sets.NewString()
+	for _, tag := range is.Spec.Tags {
+		tags.Insert(tag.Name)
+	}
+	images := sets.NewString()
+	for _, history := range is.Status.Tags {
+		tags.Insert(history.Tag)
+		for _, event := range history.Items {
+			if len(event.Image) > 0 {
+				images.Insert(event.Image)
+			}
+		}
+	}
+
+	var storage int64
+	for _, name := range images.List() {
+		image, err := i.imageLister.Get(name)
+		if err != nil {
+			// images referenced by the stream may not have been created yet or may
+			// already have been pruned; they contribute nothing to the estimate
+			if !kerrors.IsNotFound(err) {
+				utilruntime.HandleError(fmt.Errorf("failed to get image %s: %v", name, err))
+			}
+			continue
+		}
+		for _, layer := range image.DockerImageLayers {
+			storage += layer.LayerSize
+		}
+	}
+
+	return corev1.ResourceList{
+		imagev1.ResourceImageStreams:                               *resource.NewQuantity(1, resource.DecimalSI),
+		generic.ObjectCountQuotaResourceNameFor(i.GroupResource()): *resource.NewQuantity(1, resource.DecimalSI),
+		imagev1.ResourceImageStreamTags:                            *resource.NewQuantity(int64(tags.Len()), resource.DecimalSI),
+		imagev1.ResourceImageStreamImages:                          *resource.NewQuantity(int64(images.Len()), resource.DecimalSI),
+		ResourceImageStreamStorage:                                 *resource.NewQuantity(storage, resource.BinarySI),
+	}, nil
+}
+
+func (i *imageStreamEvaluator) UsageStats(options kquota.UsageStatsOptions) (kquota.UsageStats, error) {
+	listFuncByNamespace := func(namespace string) ([]runtime.Object, error) {
+		streams, err := i.store.ImageStreams(namespace).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		items := make([]runtime.Object, 0, len(streams))
+		for j := range streams {
+			items = append(items, streams[j])
+		}
+		return items, nil
+	}
+	return generic.CalculateUsageStats(options, listFuncByNamespace, generic.MatchesNoScopeFunc, i.Usage)
+}
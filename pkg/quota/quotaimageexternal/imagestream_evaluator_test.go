@@ -0,0 +1,142 @@
+package quotaimageexternal
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	fakeimagev1client "github.com/openshift/client-go/image/clientset/versioned/fake"
+	imagev1informer "github.com/openshift/client-go/image/informers/externalversions"
+)
+
+func TestImageStreamEvaluatorUsage(t *testing.T) {
+	images := []imagev1.Image{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: MiscImageDigest},
+			DockerImageLayers: []imagev1.ImageLayer{
+				{Name: "layer1", LayerSize: 100},
+				{Name: "layer2", LayerSize: 50},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: BaseImageWith1LayerDigest},
+			DockerImageLayers: []imagev1.ImageLayer{
+				{Name: "layer1", LayerSize: 200},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name            string
+		is              imagev1.ImageStream
+		expectedTags    int64
+		expectedImages  int64
+		expectedStorage int64
+	}{
+		{
+			name: "empty image stream",
+			is: imagev1.ImageStream{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "is"},
+			},
+		},
+
+		{
+			name: "spec tags only",
+			is: imagev1.ImageStream{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "is"},
+				Spec: imagev1.ImageStreamSpec{
+					Tags: []imagev1.TagReference{
+						{Name: "latest"},
+						{Name: "prod"},
+					},
+				},
+			},
+			expectedTags: 2,
+		},
+
+		{
+			name: "status tags with known images",
+			is: imagev1.ImageStream{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "is"},
+				Spec: imagev1.ImageStreamSpec{
+					Tags: []imagev1.TagReference{
+						{Name: "latest"},
+					},
+				},
+				Status: imagev1.ImageStreamStatus{
+					Tags: []imagev1.NamedTagEventList{
+						{
+							Tag: "latest",
+							Items: []imagev1.TagEvent{
+								{Image: MiscImageDigest},
+								{Image: BaseImageWith1LayerDigest},
+							},
+						},
+						{
+							Tag: "old",
+							Items: []imagev1.TagEvent{
+								{Image: MiscImageDigest},
+							},
+						},
+					},
+				},
+			},
+			expectedTags:    2,
+			expectedImages:  2,
+			expectedStorage: 350,
+		},
+
+		{
+			name: "missing images do not contribute to the estimate",
+			is: imagev1.ImageStream{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "is"},
+				Status: imagev1.ImageStreamStatus{
+					Tags: []imagev1.NamedTagEventList{
+						{
+							Tag: "latest",
+							Items: []imagev1.TagEvent{
+								{Image: ChildImageWith2LayersDigest},
+							},
+						},
+					},
+				},
+			},
+			expectedTags:   1,
+			expectedImages: 1,
+		},
+	} {
+		imageInformers := imagev1informer.NewSharedInformerFactory(fakeimagev1client.NewSimpleClientset(), 0)
+		isInformer := imageInformers.Image().V1().ImageStreams()
+		imageInformer := imageInformers.Image().V1().Images()
+		for i := range images {
+			imageInformer.Informer().GetIndexer().Add(&images[i])
+		}
+		evaluator := NewImageStreamEvaluator(isInformer.Lister(), imageInformer.Lister())
+
+		usage, err := evaluator.Usage(&tc.is)
+		if err != nil {
+			t.Errorf("[%s]: unexpected error: %v", tc.name, err)
+			continue
+		}
+
+		expectedUsage := corev1.ResourceList{
+			imagev1.ResourceImageStreams:      *resource.NewQuantity(1, resource.DecimalSI),
+			imagev1.ResourceImageStreamTags:   *resource.NewQuantity(tc.expectedTags, resource.DecimalSI),
+			imagev1.ResourceImageStreamImages: *resource.NewQuantity(tc.expectedImages, resource.DecimalSI),
+			ResourceImageStreamStorage:        *resource.NewQuantity(tc.expectedStorage, resource.BinarySI),
+		}
+		for name, expected := range expectedUsage {
+			actual, exists := usage[name]
+			if !exists {
+				t.Errorf("[%s]: expected resource %q not computed", tc.name, name)
+				continue
+			}
+			if expected.Cmp(actual) != 0 {
+				t.Errorf("[%s]: got unexpected usage for %q: %s != %s", tc.name, name, actual.String(), expected.String())
+			}
+		}
+	}
+}
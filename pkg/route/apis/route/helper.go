@@ -0,0 +1,89 @@
+package route
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/apis/core"
+)
+
+// ingressCondition returns the condition of the given type on the ingress, or
+// nil if absent.
+func ingressCondition(ingress *RouteIngress, conditionType RouteIngressConditionType) *RouteIngressCondition {
+	for i := range ingress.Conditions {
+		if ingress.Conditions[i].Type == conditionType {
+			return &ingress.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// setIngressCondition replaces the condition of the same type on the ingress,
+// or appends it.
+func setIngressCondition(ingress *RouteIngress, condition RouteIngressCondition) {
+	if existing := ingressCondition(ingress, condition.Type); existing != nil {
+		*existing = condition
+		return
+	}
+	ingress.Conditions = append(ingress.Conditions, condition)
+}
+
+// IngressObservedGeneration returns the route generation the router reported
+// through its ObservedGeneration condition, or false if the router has not
+// reported one or the value does not parse.
+func IngressObservedGeneration(ingress *RouteIngress) (int64, bool) {
+	condition := ingressCondition(ingress, RouteObservedGeneration)
+	if condition == nil {
+		return 0, false
+	}
+	generation, err := strconv.ParseInt(condition.Reason, 10, 64)
+	if err != nil || generation < 0 {
+		return 0, false
+	}
+	return generation, true
+}
+
+// SetIngressObservedGeneration records the route generation the router's
+// running configuration reflects.
+func SetIngressObservedGeneration(ingress *RouteIngress, generation int64, now metav1.Time) {
+	setIngressCondition(ingress, RouteIngressCondition{
+		Type:               RouteObservedGeneration,
+		Status:             core.ConditionTrue,
+		Reason:             strconv.FormatInt(generation, 10),
+		LastTransitionTime: &now,
+	})
+}
+
+// IngressCanaryWeights returns the per-backend traffic weights the router
+// reported through its CanaryWeights condition.  The bool reports whether the
+// condition is present; a present condition whose snapshot does not decode
+// returns an error.
+func IngressCanaryWeights(ingress *RouteIngress) (map[string]int32, bool, error) {
+	condition := ingressCondition(ingress, RouteCanaryWeights)
+	if condition == nil {
+		return nil, false, nil
+	}
+	weights := map[string]int32{}
+	if err := json.Unmarshal([]byte(condition.Message), &weights); err != nil {
+		return nil, true, fmt.Errorf("invalid canary weight snapshot on router %q: %v", ingress.RouterName, err)
+	}
+	return weights, true, nil
+}
+
+// SetIngressCanaryWeights records the traffic weights currently in effect for
+// the route's backends on the router.
+func SetIngressCanaryWeights(ingress *RouteIngress, weights map[string]int32, now metav1.Time) error {
+	snapshot, err := json.Marshal(weights)
+	if err != nil {
+		return err
+	}
+	setIngressCondition(ingress, RouteIngressCondition{
+		Type:               RouteCanaryWeights,
+		Status:             core.ConditionTrue,
+		Message:            string(snapshot),
+		LastTransitionTime: &now,
+	})
+	return nil
+}
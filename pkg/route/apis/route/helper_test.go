@@ -0,0 +1,60 @@
+package route
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIngressObservedGeneration(t *testing.T) {
+	now := metav1.Now()
+	ingress := &RouteIngress{RouterName: "default"}
+
+	if _, ok := IngressObservedGeneration(ingress); ok {
+		t.Error("expected no observed generation before one is set")
+	}
+
+	SetIngressObservedGeneration(ingress, 4, now)
+	if generation, ok := IngressObservedGeneration(ingress); !ok || generation != 4 {
+		t.Errorf("expected observed generation 4, got %d (present=%v)", generation, ok)
+	}
+
+	// updating replaces the condition rather than duplicating it
+	SetIngressObservedGeneration(ingress, 5, now)
+	if len(ingress.Conditions) != 1 {
+		t.Errorf("expected a single condition, got %v", ingress.Conditions)
+	}
+	if generation, ok := IngressObservedGeneration(ingress); !ok || generation != 5 {
+		t.Errorf("expected observed generation 5, got %d (present=%v)", generation, ok)
+	}
+
+	ingress.Conditions[0].Reason = "bogus"
+	if _, ok := IngressObservedGeneration(ingress); ok {
+		t.Error("expected an unparseable reason to read as not reported")
+	}
+}
+
+func TestIngressCanaryWeights(t *testing.T) {
+	now := metav1.Now()
+	ingress := &RouteIngress{RouterName: "default"}
+
+	if _, ok, err := IngressCanaryWeights(ingress); ok || err != nil {
+		t.Errorf("expected no canary weights before they are set, got present=%v err=%v", ok, err)
+	}
+
+	if err := SetIngressCanaryWeights(ingress, map[string]int32{"stable": 80, "canary": 20}, now); err != nil {
+		t.Fatal(err)
+	}
+	weights, ok, err := IngressCanaryWeights(ingress)
+	if err != nil || !ok {
+		t.Fatalf("expected canary weights, got present=%v err=%v", ok, err)
+	}
+	if weights["stable"] != 80 || weights["canary"] != 20 {
+		t.Errorf("unexpected weights: %v", weights)
+	}
+
+	ingress.Conditions[0].Message = "not json"
+	if _, ok, err := IngressCanaryWeights(ingress); !ok || err == nil {
+		t.Errorf("expected a decode error for a corrupt snapshot, got present=%v err=%v", ok, err)
+	}
+}
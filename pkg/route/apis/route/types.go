@@ -62,6 +62,39 @@ type RouteSpec struct {
 	// Wildcard policy if any for the route.
 	// Currently only 'Subdomain' or 'None' is allowed.
 	WildcardPolicy WildcardPolicyType
+
+	// HTTPHeaders defines headers the router sets or deletes on requests and
+	// responses passing through the route, replacing raw haproxy annotations.
+	HTTPHeaders *RouteHTTPHeaders
+}
+
+// RouteHTTPHeaders groups header manipulation policies by direction.
+type RouteHTTPHeaders struct {
+	// Request applies to the client request before it is sent to the backend.
+	Request RouteHTTPHeaderActions
+
+	// Response applies to the backend response before it is returned to the
+	// client.
+	Response RouteHTTPHeaderActions
+}
+
+// RouteHTTPHeaderActions lists the headers to set and delete. A header named
+// in both lists is set; deletes apply first.
+type RouteHTTPHeaderActions struct {
+	// Set replaces, or adds if absent, the named headers with the given values.
+	Set []RouteHTTPHeader
+
+	// Delete removes the named headers.
+	Delete []string
+}
+
+// RouteHTTPHeader names one HTTP header and the value to set it to.
+type RouteHTTPHeader struct {
+	// Name is the header name.
+	Name string
+
+	// Value is the value the header is set to.
+	Value string
 }
 
 // RouteTargetReference specifies the target that resolve into endpoints. Only the 'Service'
@@ -95,6 +128,10 @@ type RouteIngress struct {
 	Host string
 	// Name is a name chosen by the router to identify itself; this value is required
 	RouterName string
+	// RouterClass identifies the class of ingress controller that wrote this
+	// entry, so routers from different classes that happen to share a name do
+	// not clobber each other's status; this value is optional
+	RouterClass string
 	// Conditions is the state of the route, may be empty.
 	Conditions []RouteIngressCondition
 	// Wildcard policy is the wildcard policy that was allowed where this route is exposed.
@@ -112,6 +149,17 @@ const (
 	RouteAdmitted RouteIngressConditionType = "Admitted"
 	// RouteExtendedValidationFailed means the route configuration failed an extended validation check.
 	RouteExtendedValidationFailed RouteIngressConditionType = "ExtendedValidationFailed"
+	// RouteObservedGeneration names the condition a router writes to report the
+	// route generation its running configuration reflects.  The generation is
+	// carried as a decimal number in the condition reason, because RouteIngress
+	// has no dedicated field for it.
+	RouteObservedGeneration RouteIngressConditionType = "ObservedGeneration"
+	// RouteCanaryWeights names the condition a router writes to expose the
+	// traffic weights currently in effect for the route's backends.  The
+	// condition message carries a JSON object mapping backend service names to
+	// weights, so progressive-delivery tooling can read rollout state from
+	// Route status instead of router-specific CRDs.
+	RouteCanaryWeights RouteIngressConditionType = "CanaryWeights"
 	// TODO: add other route condition types
 )
 
@@ -179,6 +227,18 @@ type TLSConfig struct {
 	// insecure connections to an edge-terminated route:
 	//   disable, allow or redirect
 	InsecureEdgeTerminationPolicy InsecureEdgeTerminationPolicyType
+
+	// ExternalCertificate references a kubernetes.io/tls secret in the same
+	// namespace that supplies the certificate and key for the route instead
+	// of the inline certificate and key fields
+	ExternalCertificate *LocalObjectReference
+}
+
+// LocalObjectReference contains enough information to let you locate the
+// referenced object inside the same namespace.
+type LocalObjectReference struct {
+	// Name of the referent.
+	Name string
 }
 
 // TLSTerminationType dictates where the secure communication will stop
@@ -1,10 +1,232 @@
 package v1
 
 import (
+	"encoding/json"
+
 	v1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/runtime"
+
+	newer "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
 )
 
+// ExternalCertificateAnnotation carries the name of the kubernetes.io/tls
+// secret an externalCertificate references. The versioned TLSConfig has no
+// field for the reference, so it is translated to and from the annotation
+// during Route conversion and is not stored on internal objects.
+const ExternalCertificateAnnotation = "route.openshift.io/external-certificate"
+
+func decodeExternalCertificate(route *newer.Route) {
+	name, ok := route.Annotations[ExternalCertificateAnnotation]
+	if !ok {
+		return
+	}
+	if route.Spec.TLS != nil {
+		route.Spec.TLS.ExternalCertificate = &newer.LocalObjectReference{Name: name}
+	}
+	annotations := make(map[string]string, len(route.Annotations))
+	for k, v := range route.Annotations {
+		if k != ExternalCertificateAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	route.Annotations = annotations
+}
+
+func encodeExternalCertificate(in *newer.Route, out *v1.Route) {
+	if in.Spec.TLS == nil || in.Spec.TLS.ExternalCertificate == nil {
+		return
+	}
+	annotations := make(map[string]string, len(out.Annotations)+1)
+	for k, v := range out.Annotations {
+		annotations[k] = v
+	}
+	annotations[ExternalCertificateAnnotation] = in.Spec.TLS.ExternalCertificate.Name
+	out.Annotations = annotations
+}
+
+// RouterClassesAnnotation carries the ingress controller class of each
+// status ingress entry across the v1 API, which has no field for it.  The
+// value is a JSON object mapping router names to their classes and is not
+// stored on internal objects.
+const RouterClassesAnnotation = "route.openshift.io/router-classes"
+
+func decodeRouterClasses(route *newer.Route) {
+	value, ok := route.Annotations[RouterClassesAnnotation]
+	if !ok {
+		return
+	}
+	classes := map[string]string{}
+	if err := json.Unmarshal([]byte(value), &classes); err != nil {
+		// stored routes may carry annotation values that predate any
+		// validation; failing conversion would make them unreadable, so a
+		// malformed value is left in place for validation to reject on writes
+		return
+	}
+	for i := range route.Status.Ingress {
+		ingress := &route.Status.Ingress[i]
+		ingress.RouterClass = classes[ingress.RouterName]
+	}
+	annotations := make(map[string]string, len(route.Annotations)-1)
+	for k, v := range route.Annotations {
+		if k != RouterClassesAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	route.Annotations = annotations
+}
+
+func encodeRouterClasses(in *newer.Route, out *v1.Route) error {
+	classes := map[string]string{}
+	for i := range in.Status.Ingress {
+		ingress := &in.Status.Ingress[i]
+		if len(ingress.RouterClass) > 0 {
+			classes[ingress.RouterName] = ingress.RouterClass
+		}
+	}
+	if len(classes) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(classes)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(out.Annotations)+1)
+	for k, v := range out.Annotations {
+		annotations[k] = v
+	}
+	annotations[RouterClassesAnnotation] = string(encoded)
+	out.Annotations = annotations
+	return nil
+}
+
+// HTTPHeaderPolicyAnnotation carries the header rewrite policy of a route
+// across the v1 API, which has no field for it.  The value is the JSON
+// encoding of HTTPHeaderPolicy and is not stored on internal objects.
+const HTTPHeaderPolicyAnnotation = "route.openshift.io/http-header-policy"
+
+// HTTPHeader is the serialized form of one header to set.
+type HTTPHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+// HTTPHeaderActions is the serialized form of the actions for one direction.
+type HTTPHeaderActions struct {
+	Set    []HTTPHeader `json:"set,omitempty"`
+	Delete []string     `json:"delete,omitempty"`
+}
+
+// HTTPHeaderPolicy is the serialized form of the header rewrite policy
+// annotation.
+type HTTPHeaderPolicy struct {
+	Request  HTTPHeaderActions `json:"request,omitempty"`
+	Response HTTPHeaderActions `json:"response,omitempty"`
+}
+
+func decodeHTTPHeaderPolicy(route *newer.Route) {
+	value, ok := route.Annotations[HTTPHeaderPolicyAnnotation]
+	if !ok {
+		return
+	}
+	var decoded HTTPHeaderPolicy
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		// stored routes may carry annotation values that predate any
+		// validation; failing conversion would make them unreadable, so a
+		// malformed value is left in place for validation to reject on writes
+		return
+	}
+	route.Spec.HTTPHeaders = &newer.RouteHTTPHeaders{
+		Request:  internalHeaderActions(decoded.Request),
+		Response: internalHeaderActions(decoded.Response),
+	}
+	// the annotation only exists in the versioned form; the object meta is
+	// shared with the input, so replace the map instead of mutating it
+	annotations := make(map[string]string, len(route.Annotations)-1)
+	for k, v := range route.Annotations {
+		if k != HTTPHeaderPolicyAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	route.Annotations = annotations
+}
+
+func internalHeaderActions(in HTTPHeaderActions) newer.RouteHTTPHeaderActions {
+	out := newer.RouteHTTPHeaderActions{Delete: in.Delete}
+	for _, header := range in.Set {
+		out.Set = append(out.Set, newer.RouteHTTPHeader{Name: header.Name, Value: header.Value})
+	}
+	return out
+}
+
+func encodeHTTPHeaderPolicy(in *newer.Route, out *v1.Route) error {
+	if in.Spec.HTTPHeaders == nil {
+		return nil
+	}
+	policy := HTTPHeaderPolicy{
+		Request:  versionedHeaderActions(in.Spec.HTTPHeaders.Request),
+		Response: versionedHeaderActions(in.Spec.HTTPHeaders.Response),
+	}
+	encoded, err := json.Marshal(&policy)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(out.Annotations)+1)
+	for k, v := range out.Annotations {
+		annotations[k] = v
+	}
+	annotations[HTTPHeaderPolicyAnnotation] = string(encoded)
+	out.Annotations = annotations
+	return nil
+}
+
+func versionedHeaderActions(in newer.RouteHTTPHeaderActions) HTTPHeaderActions {
+	out := HTTPHeaderActions{Delete: in.Delete}
+	for _, header := range in.Set {
+		out.Set = append(out.Set, HTTPHeader{Name: header.Name, Value: header.Value})
+	}
+	return out
+}
+
+// Convert_v1_Route_To_route_Route is a manual conversion because the
+// externalCertificate reference of the TLS config and the header rewrite
+// policy are carried by annotations in v1.
+func Convert_v1_Route_To_route_Route(in *v1.Route, out *newer.Route, s conversion.Scope) error {
+	if err := autoConvert_v1_Route_To_route_Route(in, out, s); err != nil {
+		return err
+	}
+	decodeExternalCertificate(out)
+	decodeRouterClasses(out)
+	decodeHTTPHeaderPolicy(out)
+	return nil
+}
+
+func Convert_route_Route_To_v1_Route(in *newer.Route, out *v1.Route, s conversion.Scope) error {
+	if err := autoConvert_route_Route_To_v1_Route(in, out, s); err != nil {
+		return err
+	}
+	encodeExternalCertificate(in, out)
+	if err := encodeRouterClasses(in, out); err != nil {
+		return err
+	}
+	return encodeHTTPHeaderPolicy(in, out)
+}
+
+// Convert_route_TLSConfig_To_v1_TLSConfig drops the externalCertificate
+// reference, which rides the route annotation across the v1 API.
+func Convert_route_TLSConfig_To_v1_TLSConfig(in *newer.TLSConfig, out *v1.TLSConfig, s conversion.Scope) error {
+	return autoConvert_route_TLSConfig_To_v1_TLSConfig(in, out, s)
+}
+
 func addFieldSelectorKeyConversions(scheme *runtime.Scheme) error {
 	if err := scheme.AddFieldLabelConversionFunc(v1.GroupVersion.WithKind("Route"), routeFieldSelectorKeyConversionFunc); err != nil {
 		return err
@@ -1,8 +1,10 @@
 package v1
 
 import (
+	"reflect"
 	"testing"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/davecgh/go-spew/spew"
@@ -21,6 +23,108 @@ func TestFieldSelectorConversions(t *testing.T) {
 	}.Check(t)
 }
 
+func TestExternalCertificateConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	Install(scheme)
+
+	external := &v1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "frontend",
+			Annotations: map[string]string{
+				ExternalCertificateAnnotation: "frontend-tls",
+			},
+		},
+		Spec: v1.RouteSpec{
+			TLS: &v1.TLSConfig{Termination: v1.TLSTerminationEdge},
+		},
+	}
+
+	internal := &route.Route{}
+	if err := scheme.Convert(external, internal, nil); err != nil {
+		t.Fatal(err)
+	}
+	if internal.Spec.TLS.ExternalCertificate == nil || internal.Spec.TLS.ExternalCertificate.Name != "frontend-tls" {
+		t.Errorf("expected the annotation to become the externalCertificate reference, got %#v", internal.Spec.TLS.ExternalCertificate)
+	}
+	if _, ok := internal.Annotations[ExternalCertificateAnnotation]; ok {
+		t.Errorf("expected the annotation to be stripped from the internal object, got %#v", internal.Annotations)
+	}
+
+	back := &v1.Route{}
+	if err := scheme.Convert(internal, back, nil); err != nil {
+		t.Fatal(err)
+	}
+	if back.Annotations[ExternalCertificateAnnotation] != "frontend-tls" {
+		t.Errorf("expected the reference to round-trip to the annotation, got %#v", back.Annotations)
+	}
+}
+
+func TestHTTPHeaderPolicyConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	Install(scheme)
+
+	external := &v1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "frontend",
+			Annotations: map[string]string{
+				HTTPHeaderPolicyAnnotation: `{"request":{"set":[{"name":"X-Forwarded-Proto","value":"https"}],"delete":["X-Debug"]},"response":{"delete":["Server"]}}`,
+			},
+		},
+	}
+
+	internal := &route.Route{}
+	if err := scheme.Convert(external, internal, nil); err != nil {
+		t.Fatal(err)
+	}
+	headers := internal.Spec.HTTPHeaders
+	if headers == nil {
+		t.Fatal("expected the annotation to become the httpHeaders field")
+	}
+	if len(headers.Request.Set) != 1 || headers.Request.Set[0].Name != "X-Forwarded-Proto" || headers.Request.Set[0].Value != "https" {
+		t.Errorf("unexpected request set actions: %#v", headers.Request.Set)
+	}
+	if len(headers.Request.Delete) != 1 || headers.Request.Delete[0] != "X-Debug" {
+		t.Errorf("unexpected request delete actions: %#v", headers.Request.Delete)
+	}
+	if len(headers.Response.Delete) != 1 || headers.Response.Delete[0] != "Server" {
+		t.Errorf("unexpected response delete actions: %#v", headers.Response.Delete)
+	}
+	if _, ok := internal.Annotations[HTTPHeaderPolicyAnnotation]; ok {
+		t.Errorf("expected the annotation to be stripped from the internal object, got %#v", internal.Annotations)
+	}
+
+	back := &v1.Route{}
+	if err := scheme.Convert(internal, back, nil); err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := &route.Route{}
+	if err := scheme.Convert(back, roundTripped, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(headers, roundTripped.Spec.HTTPHeaders) {
+		t.Errorf("expected the policy to round-trip, got %#v", roundTripped.Spec.HTTPHeaders)
+	}
+
+	// a stored route may carry a malformed annotation from before validation
+	// covered it; conversion must keep such routes readable
+	invalid := &v1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "frontend",
+			Annotations: map[string]string{HTTPHeaderPolicyAnnotation: "not json"},
+		},
+	}
+	tolerated := &route.Route{}
+	if err := scheme.Convert(invalid, tolerated, nil); err != nil {
+		t.Errorf("expected an unparseable annotation to be tolerated, got: %v", err)
+	}
+	if tolerated.Spec.HTTPHeaders != nil {
+		t.Errorf("expected no httpHeaders from an unparseable annotation, got %#v", tolerated.Spec.HTTPHeaders)
+	}
+	if tolerated.Annotations[HTTPHeaderPolicyAnnotation] != "not json" {
+		t.Errorf("expected an unparseable annotation to be passed through, got %#v", tolerated.Annotations)
+	}
+}
+
 func TestSupportingCamelConstants(t *testing.T) {
 	scheme := runtime.NewScheme()
 	Install(scheme)
@@ -41,3 +145,72 @@ func TestSupportingCamelConstants(t *testing.T) {
 		}
 	}
 }
+
+func TestRouterClassesConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	Install(scheme)
+
+	external := &v1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "frontend",
+			Annotations: map[string]string{
+				RouterClassesAnnotation: `{"default":"openshift-default","edge":"partner"}`,
+			},
+		},
+		Status: v1.RouteStatus{
+			Ingress: []v1.RouteIngress{
+				{Host: "host", RouterName: "default"},
+				{Host: "host", RouterName: "edge"},
+				{Host: "host", RouterName: "legacy"},
+			},
+		},
+	}
+
+	internal := &route.Route{}
+	if err := scheme.Convert(external, internal, nil); err != nil {
+		t.Fatal(err)
+	}
+	if internal.Status.Ingress[0].RouterClass != "openshift-default" || internal.Status.Ingress[1].RouterClass != "partner" {
+		t.Errorf("expected the annotation to populate the routerClass fields, got %#v", internal.Status.Ingress)
+	}
+	if len(internal.Status.Ingress[2].RouterClass) != 0 {
+		t.Errorf("expected no class for a router missing from the annotation, got %q", internal.Status.Ingress[2].RouterClass)
+	}
+	if _, ok := internal.Annotations[RouterClassesAnnotation]; ok {
+		t.Errorf("expected the annotation to be stripped from the internal object, got %#v", internal.Annotations)
+	}
+
+	back := &v1.Route{}
+	if err := scheme.Convert(internal, back, nil); err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := &route.Route{}
+	if err := scheme.Convert(back, roundTripped, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(internal.Status.Ingress, roundTripped.Status.Ingress) {
+		t.Errorf("expected the classes to round-trip, got %#v", roundTripped.Status.Ingress)
+	}
+
+	// a stored route may carry a malformed annotation from before validation
+	// covered it; conversion must keep such routes readable
+	invalid := &v1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "frontend",
+			Annotations: map[string]string{RouterClassesAnnotation: "not json"},
+		},
+		Status: v1.RouteStatus{
+			Ingress: []v1.RouteIngress{{Host: "host", RouterName: "default"}},
+		},
+	}
+	tolerated := &route.Route{}
+	if err := scheme.Convert(invalid, tolerated, nil); err != nil {
+		t.Errorf("expected an unparseable annotation to be tolerated, got: %v", err)
+	}
+	if len(tolerated.Status.Ingress[0].RouterClass) != 0 {
+		t.Errorf("expected no routerClass from an unparseable annotation, got %q", tolerated.Status.Ingress[0].RouterClass)
+	}
+	if tolerated.Annotations[RouterClassesAnnotation] != "not json" {
+		t.Errorf("expected an unparseable annotation to be passed through, got %#v", tolerated.Annotations)
+	}
+}
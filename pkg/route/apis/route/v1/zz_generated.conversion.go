@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated_openshift
 // +build !ignore_autogenerated_openshift
 
 // Code generated by conversion-gen. DO NOT EDIT.
@@ -137,11 +138,6 @@ func autoConvert_v1_Route_To_route_Route(in *v1.Route, out *route.Route, s conve
 	return nil
 }
 
-// Convert_v1_Route_To_route_Route is an autogenerated conversion function.
-func Convert_v1_Route_To_route_Route(in *v1.Route, out *route.Route, s conversion.Scope) error {
-	return autoConvert_v1_Route_To_route_Route(in, out, s)
-}
-
 func autoConvert_route_Route_To_v1_Route(in *route.Route, out *v1.Route, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_route_RouteSpec_To_v1_RouteSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -153,11 +149,6 @@ func autoConvert_route_Route_To_v1_Route(in *route.Route, out *v1.Route, s conve
 	return nil
 }
 
-// Convert_route_Route_To_v1_Route is an autogenerated conversion function.
-func Convert_route_Route_To_v1_Route(in *route.Route, out *v1.Route, s conversion.Scope) error {
-	return autoConvert_route_Route_To_v1_Route(in, out, s)
-}
-
 func autoConvert_v1_RouteIngress_To_route_RouteIngress(in *v1.RouteIngress, out *route.RouteIngress, s conversion.Scope) error {
 	out.Host = in.Host
 	out.RouterName = in.RouterName
@@ -175,6 +166,7 @@ func Convert_v1_RouteIngress_To_route_RouteIngress(in *v1.RouteIngress, out *rou
 func autoConvert_route_RouteIngress_To_v1_RouteIngress(in *route.RouteIngress, out *v1.RouteIngress, s conversion.Scope) error {
 	out.Host = in.Host
 	out.RouterName = in.RouterName
+	// WARNING: in.RouterClass requires manual conversion: does not exist in peer-type
 	out.Conditions = *(*[]v1.RouteIngressCondition)(unsafe.Pointer(&in.Conditions))
 	out.WildcardPolicy = v1.WildcardPolicyType(in.WildcardPolicy)
 	out.RouterCanonicalHostname = in.RouterCanonicalHostname
@@ -265,7 +257,15 @@ func autoConvert_v1_RouteSpec_To_route_RouteSpec(in *v1.RouteSpec, out *route.Ro
 	}
 	out.AlternateBackends = *(*[]route.RouteTargetReference)(unsafe.Pointer(&in.AlternateBackends))
 	out.Port = (*route.RoutePort)(unsafe.Pointer(in.Port))
-	out.TLS = (*route.TLSConfig)(unsafe.Pointer(in.TLS))
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(route.TLSConfig)
+		if err := Convert_v1_TLSConfig_To_route_TLSConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.TLS = nil
+	}
 	out.WildcardPolicy = route.WildcardPolicyType(in.WildcardPolicy)
 	return nil
 }
@@ -284,7 +284,15 @@ func autoConvert_route_RouteSpec_To_v1_RouteSpec(in *route.RouteSpec, out *v1.Ro
 	}
 	out.AlternateBackends = *(*[]v1.RouteTargetReference)(unsafe.Pointer(&in.AlternateBackends))
 	out.Port = (*v1.RoutePort)(unsafe.Pointer(in.Port))
-	out.TLS = (*v1.TLSConfig)(unsafe.Pointer(in.TLS))
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(v1.TLSConfig)
+		if err := Convert_route_TLSConfig_To_v1_TLSConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.TLS = nil
+	}
 	out.WildcardPolicy = v1.WildcardPolicyType(in.WildcardPolicy)
 	return nil
 }
@@ -295,7 +303,17 @@ func Convert_route_RouteSpec_To_v1_RouteSpec(in *route.RouteSpec, out *v1.RouteS
 }
 
 func autoConvert_v1_RouteStatus_To_route_RouteStatus(in *v1.RouteStatus, out *route.RouteStatus, s conversion.Scope) error {
-	out.Ingress = *(*[]route.RouteIngress)(unsafe.Pointer(&in.Ingress))
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = make([]route.RouteIngress, len(*in))
+		for i := range *in {
+			if err := Convert_v1_RouteIngress_To_route_RouteIngress(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Ingress = nil
+	}
 	return nil
 }
 
@@ -305,7 +323,17 @@ func Convert_v1_RouteStatus_To_route_RouteStatus(in *v1.RouteStatus, out *route.
 }
 
 func autoConvert_route_RouteStatus_To_v1_RouteStatus(in *route.RouteStatus, out *v1.RouteStatus, s conversion.Scope) error {
-	out.Ingress = *(*[]v1.RouteIngress)(unsafe.Pointer(&in.Ingress))
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = make([]v1.RouteIngress, len(*in))
+		for i := range *in {
+			if err := Convert_route_RouteIngress_To_v1_RouteIngress(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Ingress = nil
+	}
 	return nil
 }
 
@@ -382,10 +410,6 @@ func autoConvert_route_TLSConfig_To_v1_TLSConfig(in *route.TLSConfig, out *v1.TL
 	out.CACertificate = in.CACertificate
 	out.DestinationCACertificate = in.DestinationCACertificate
 	out.InsecureEdgeTerminationPolicy = v1.InsecureEdgeTerminationPolicyType(in.InsecureEdgeTerminationPolicy)
+	// WARNING: in.ExternalCertificate requires manual conversion: does not exist in peer-type
 	return nil
 }
-
-// Convert_route_TLSConfig_To_v1_TLSConfig is an autogenerated conversion function.
-func Convert_route_TLSConfig_To_v1_TLSConfig(in *route.TLSConfig, out *v1.TLSConfig, s conversion.Scope) error {
-	return autoConvert_route_TLSConfig_To_v1_TLSConfig(in, out, s)
-}
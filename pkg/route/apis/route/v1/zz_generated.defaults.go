@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated_openshift
 // +build !ignore_autogenerated_openshift
 
 // Code generated by defaulter-gen. DO NOT EDIT.
@@ -4,8 +4,10 @@ import (
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"strconv"
 	"strings"
 
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
@@ -16,6 +18,7 @@ import (
 	"k8s.io/kubernetes/pkg/apis/core/validation"
 
 	routeapi "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
+	routev1 "github.com/openshift/openshift-apiserver/pkg/route/apis/route/v1"
 )
 
 var ValidateRouteName = apimachineryvalidation.NameIsDNSSubdomain
@@ -85,9 +88,100 @@ func ValidateRoute(route *routeapi.Route) field.ErrorList {
 		result = append(result, errs...)
 	}
 
+	if route.Spec.HTTPHeaders != nil {
+		result = append(result, validateHTTPHeaders(route.Spec.HTTPHeaders, specPath.Child("httpHeaders"))...)
+	}
+
+	result = append(result, validatePolicyAnnotations(route, field.NewPath("metadata", "annotations"))...)
+
 	return result
 }
 
+// validatePolicyAnnotations rejects malformed values of the annotations that
+// conversion translates into internal fields.  Conversion tolerates bad
+// values so stored routes always stay readable; a value only survives to the
+// internal object when it could not be decoded, and it is rejected here on
+// the write path instead.
+func validatePolicyAnnotations(route *routeapi.Route, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if value, ok := route.Annotations[routev1.HTTPHeaderPolicyAnnotation]; ok {
+		var policy routev1.HTTPHeaderPolicy
+		if err := json.Unmarshal([]byte(value), &policy); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(routev1.HTTPHeaderPolicyAnnotation), value, fmt.Sprintf("must be a JSON header policy: %v", err)))
+		}
+	}
+	if value, ok := route.Annotations[routev1.RouterClassesAnnotation]; ok {
+		classes := map[string]string{}
+		if err := json.Unmarshal([]byte(value), &classes); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(routev1.RouterClassesAnnotation), value, fmt.Sprintf("must be a JSON object mapping router names to classes: %v", err)))
+		}
+	}
+	return allErrs
+}
+
+const (
+	// maxHeaderValueLength bounds a single header value; longer values would
+	// overflow the router's configuration line for the route.
+	maxHeaderValueLength = 16384
+	// maxHeaderActions bounds the combined set and delete entries per
+	// direction.
+	maxHeaderActions = 20
+)
+
+// setForbiddenHeaders carry credentials and may be deleted but never set, so
+// a route cannot inject them into requests to the backend.
+var setForbiddenHeaders = sets.NewString("authorization", "proxy-authorization")
+
+func validateHTTPHeaders(headers *routeapi.RouteHTTPHeaders, fldPath *field.Path) field.ErrorList {
+	allErrs := validateHeaderActions(&headers.Request, fldPath.Child("request"))
+	allErrs = append(allErrs, validateHeaderActions(&headers.Response, fldPath.Child("response"))...)
+	return allErrs
+}
+
+func validateHeaderActions(actions *routeapi.RouteHTTPHeaderActions, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(actions.Set)+len(actions.Delete) > maxHeaderActions {
+		allErrs = append(allErrs, field.Invalid(fldPath, len(actions.Set)+len(actions.Delete), fmt.Sprintf("cannot specify more than %d header actions", maxHeaderActions)))
+	}
+
+	seen := sets.NewString()
+	for i, header := range actions.Set {
+		namePath := fldPath.Child("set").Index(i).Child("name")
+		allErrs = append(allErrs, validateHeaderName(header.Name, namePath)...)
+		if setForbiddenHeaders.Has(strings.ToLower(header.Name)) {
+			allErrs = append(allErrs, field.Forbidden(namePath, fmt.Sprintf("the %s header may not be set by a route", header.Name)))
+		}
+		if seen.Has(strings.ToLower(header.Name)) {
+			allErrs = append(allErrs, field.Duplicate(namePath, header.Name))
+		}
+		seen.Insert(strings.ToLower(header.Name))
+		if len(header.Value) > maxHeaderValueLength {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("set").Index(i).Child("value"), fmt.Sprintf("<%d bytes>", len(header.Value)), fmt.Sprintf("header values may not exceed %d characters", maxHeaderValueLength)))
+		}
+	}
+	for i, name := range actions.Delete {
+		allErrs = append(allErrs, validateHeaderName(name, fldPath.Child("delete").Index(i))...)
+	}
+
+	return allErrs
+}
+
+func validateHeaderName(name string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(name) == 0 {
+		return append(allErrs, field.Required(fldPath, ""))
+	}
+	for _, msg := range kvalidation.IsHTTPHeaderName(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, msg))
+	}
+	// the Host header selects the route itself; neither direction may touch it
+	if strings.EqualFold(name, "Host") {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "the Host header may not be modified by a route"))
+	}
+	return allErrs
+}
+
 func ValidateRouteUpdate(route *routeapi.Route, older *routeapi.Route) field.ErrorList {
 	allErrs := validation.ValidateObjectMetaUpdate(&route.ObjectMeta, &older.ObjectMeta, field.NewPath("metadata"))
 	allErrs = append(allErrs, validation.ValidateImmutableField(route.Spec.WildcardPolicy, older.Spec.WildcardPolicy, field.NewPath("spec", "wildcardPolicy"))...)
@@ -102,7 +196,85 @@ func ValidateRouteUpdate(route *routeapi.Route, older *routeapi.Route) field.Err
 func ValidateRouteStatusUpdate(route *routeapi.Route, older *routeapi.Route) field.ErrorList {
 	allErrs := validation.ValidateObjectMetaUpdate(&route.ObjectMeta, &older.ObjectMeta, field.NewPath("metadata"))
 
-	// TODO: validate route status
+	seen := map[string]bool{}
+	for i := range route.Status.Ingress {
+		ingress := &route.Status.Ingress[i]
+		fldPath := field.NewPath("status", "ingress").Index(i)
+		key := ingress.RouterClass + "/" + ingress.RouterName
+		if seen[key] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("routerName"), ingress.RouterName))
+		}
+		seen[key] = true
+		allErrs = append(allErrs, validateRouteIngress(ingress, fldPath)...)
+		allErrs = append(allErrs, validateIngressConditionTransitions(ingress, findRouteIngress(older, ingress.RouterName, ingress.RouterClass), fldPath)...)
+	}
+	return allErrs
+}
+
+// findRouteIngress returns the status entry the given router wrote on the
+// route, if any. Entries are keyed by router name and class together, so two
+// ingress controllers of different classes may use the same router name.
+func findRouteIngress(route *routeapi.Route, routerName, routerClass string) *routeapi.RouteIngress {
+	for i := range route.Status.Ingress {
+		ingress := &route.Status.Ingress[i]
+		if ingress.RouterName == routerName && ingress.RouterClass == routerClass {
+			return ingress
+		}
+	}
+	return nil
+}
+
+// validateIngressConditionTransitions ensures a router does not move a
+// condition's lastTransitionTime backwards relative to the entry it
+// previously wrote; a rewind indicates a router acting on a stale cache or
+// two routers fighting over the same status entry.
+func validateIngressConditionTransitions(ingress, older *routeapi.RouteIngress, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if older == nil {
+		return allErrs
+	}
+	for i, condition := range ingress.Conditions {
+		if condition.LastTransitionTime == nil {
+			continue
+		}
+		for _, previous := range older.Conditions {
+			if previous.Type != condition.Type || previous.LastTransitionTime == nil {
+				continue
+			}
+			if condition.LastTransitionTime.Before(previous.LastTransitionTime) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("conditions").Index(i).Child("lastTransitionTime"), condition.LastTransitionTime, "lastTransitionTime may not move backwards"))
+			}
+			break
+		}
+	}
+	return allErrs
+}
+
+// validateRouteIngress checks the rollout state conditions a router may write
+// on its ingress entry; they carry structured values in the condition reason
+// and message, which must be well formed for tooling to consume them.
+func validateRouteIngress(ingress *routeapi.RouteIngress, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, condition := range ingress.Conditions {
+		condPath := fldPath.Child("conditions").Index(i)
+		switch condition.Type {
+		case routeapi.RouteObservedGeneration:
+			if generation, err := strconv.ParseInt(condition.Reason, 10, 64); err != nil || generation < 0 {
+				allErrs = append(allErrs, field.Invalid(condPath.Child("reason"), condition.Reason, "observed generation must be a non-negative integer"))
+			}
+		case routeapi.RouteCanaryWeights:
+			weights := map[string]int32{}
+			if err := json.Unmarshal([]byte(condition.Message), &weights); err != nil {
+				allErrs = append(allErrs, field.Invalid(condPath.Child("message"), condition.Message, "canary weight snapshot must be a JSON object mapping service names to weights"))
+				continue
+			}
+			for name, weight := range weights {
+				if weight < 0 || weight > 256 {
+					allErrs = append(allErrs, field.Invalid(condPath.Child("message"), weight, fmt.Sprintf("weight for service %q must be an integer between 0 and 256", name)))
+				}
+			}
+		}
+	}
 	return allErrs
 }
 
@@ -214,6 +386,10 @@ func validateTLS(route *routeapi.Route, fldPath *field.Path) field.ErrorList {
 		if len(tls.DestinationCACertificate) > 0 {
 			result = append(result, field.Invalid(fldPath.Child("destinationCACertificate"), "redacted destination ca certificate data", "passthrough termination does not support certificates"))
 		}
+
+		if tls.ExternalCertificate != nil {
+			result = append(result, field.Invalid(fldPath.Child("externalCertificate"), tls.ExternalCertificate.Name, "passthrough termination does not support certificates"))
+		}
 	// edge cert should only specify cert, key, and cacert but those certs
 	// may not be specified if the route is a wildcard route
 	case routeapi.TLSTerminationEdge:
@@ -225,6 +401,15 @@ func validateTLS(route *routeapi.Route, fldPath *field.Path) field.ErrorList {
 		result = append(result, field.NotSupported(fldPath.Child("termination"), tls.Termination, validValues))
 	}
 
+	if tls.ExternalCertificate != nil {
+		if len(tls.ExternalCertificate.Name) == 0 {
+			result = append(result, field.Required(fldPath.Child("externalCertificate", "name"), ""))
+		}
+		if len(tls.Certificate) > 0 || len(tls.Key) > 0 {
+			result = append(result, field.Invalid(fldPath.Child("externalCertificate"), tls.ExternalCertificate.Name, "externalCertificate cannot be used with the certificate or key fields"))
+		}
+	}
+
 	if err := validateInsecureEdgeTerminationPolicy(tls, fldPath.Child("insecureEdgeTerminationPolicy")); err != nil {
 		result = append(result, err)
 	}
@@ -271,6 +456,41 @@ var (
 	allowedWildcardPoliciesSet = sets.NewString(allowedWildcardPolicies...)
 )
 
+// WildcardSubdomain returns the subdomain a route with the Subdomain wildcard
+// policy claims for the given host, which is the host with its first label
+// removed.  It returns an empty string when the host has no subdomain to claim.
+func WildcardSubdomain(host string) string {
+	if i := strings.Index(host, "."); i != -1 {
+		return host[i+1:]
+	}
+	return ""
+}
+
+// ValidateSubdomainWildcardHost tests that the host of a route with the Subdomain
+// wildcard policy claims a subdomain of one of the allowed domains.  An empty set
+// of allowed domains permits any subdomain.  The set of allowed domains is not part
+// of the route API; callers that enforce it (such as admission) supply it from
+// their own configuration.
+func ValidateSubdomainWildcardHost(host string, allowedDomains sets.String, fldPath *field.Path) field.ErrorList {
+	result := field.ErrorList{}
+
+	subdomain := WildcardSubdomain(host)
+	if len(subdomain) == 0 {
+		result = append(result, field.Invalid(fldPath, host, "host must have a subdomain to claim with a wildcard policy"))
+		return result
+	}
+	if allowedDomains.Len() == 0 {
+		return result
+	}
+	for _, domain := range allowedDomains.List() {
+		if subdomain == domain || strings.HasSuffix(subdomain, "."+domain) {
+			return result
+		}
+	}
+	result = append(result, field.Invalid(fldPath, host, fmt.Sprintf("wildcard routes may only claim subdomains of the allowed domains %s", strings.Join(allowedDomains.List(), ", "))))
+	return result
+}
+
 // validateWildcardPolicy tests that the wildcard policy is either empty or one of the supported types.
 func validateWildcardPolicy(host string, policy routeapi.WildcardPolicyType, fldPath *field.Path) *field.Error {
 	if len(policy) == 0 {
@@ -1,12 +1,18 @@
 package validation
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	routeapi "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
+	routev1 "github.com/openshift/openshift-apiserver/pkg/route/apis/route/v1"
 )
 
 const (
@@ -322,6 +328,159 @@ func TestValidateRoute(t *testing.T) {
 	}
 }
 
+func TestValidateHTTPHeaders(t *testing.T) {
+	tests := []struct {
+		name           string
+		headers        *routeapi.RouteHTTPHeaders
+		expectedErrors int
+	}{
+		{
+			name: "Valid set and delete actions",
+			headers: &routeapi.RouteHTTPHeaders{
+				Request: routeapi.RouteHTTPHeaderActions{
+					Set:    []routeapi.RouteHTTPHeader{{Name: "X-Forwarded-Proto", Value: "https"}},
+					Delete: []string{"X-Debug"},
+				},
+				Response: routeapi.RouteHTTPHeaderActions{
+					Delete: []string{"Server"},
+				},
+			},
+			expectedErrors: 0,
+		},
+		{
+			name: "Host may not be set",
+			headers: &routeapi.RouteHTTPHeaders{
+				Request: routeapi.RouteHTTPHeaderActions{
+					Set: []routeapi.RouteHTTPHeader{{Name: "Host", Value: "other.example.com"}},
+				},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name: "Host may not be deleted",
+			headers: &routeapi.RouteHTTPHeaders{
+				Request: routeapi.RouteHTTPHeaderActions{
+					Delete: []string{"host"},
+				},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name: "Authorization may not be set",
+			headers: &routeapi.RouteHTTPHeaders{
+				Request: routeapi.RouteHTTPHeaderActions{
+					Set: []routeapi.RouteHTTPHeader{{Name: "Authorization", Value: "Bearer token"}},
+				},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name: "Authorization may be deleted",
+			headers: &routeapi.RouteHTTPHeaders{
+				Request: routeapi.RouteHTTPHeaderActions{
+					Delete: []string{"Authorization"},
+				},
+			},
+			expectedErrors: 0,
+		},
+		{
+			name: "Empty header name",
+			headers: &routeapi.RouteHTTPHeaders{
+				Response: routeapi.RouteHTTPHeaderActions{
+					Set: []routeapi.RouteHTTPHeader{{Name: "", Value: "value"}},
+				},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name: "Invalid header name",
+			headers: &routeapi.RouteHTTPHeaders{
+				Request: routeapi.RouteHTTPHeaderActions{
+					Set: []routeapi.RouteHTTPHeader{{Name: "X Spaced", Value: "value"}},
+				},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name: "Duplicate set names differing only by case",
+			headers: &routeapi.RouteHTTPHeaders{
+				Request: routeapi.RouteHTTPHeaderActions{
+					Set: []routeapi.RouteHTTPHeader{
+						{Name: "X-Custom", Value: "one"},
+						{Name: "x-custom", Value: "two"},
+					},
+				},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name: "Value too long",
+			headers: &routeapi.RouteHTTPHeaders{
+				Response: routeapi.RouteHTTPHeaderActions{
+					Set: []routeapi.RouteHTTPHeader{{Name: "X-Large", Value: strings.Repeat("a", maxHeaderValueLength+1)}},
+				},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name: "Too many actions",
+			headers: &routeapi.RouteHTTPHeaders{
+				Request: routeapi.RouteHTTPHeaderActions{
+					Delete: tooManyHeaderNames(),
+				},
+			},
+			expectedErrors: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		errs := validateHTTPHeaders(tc.headers, field.NewPath("spec", "httpHeaders"))
+
+		if len(errs) != tc.expectedErrors {
+			t.Errorf("Test case %s expected %d error(s), got %d. %v", tc.name, tc.expectedErrors, len(errs), errs)
+		}
+	}
+}
+
+func TestValidatePolicyAnnotations(t *testing.T) {
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		expectedErrors int
+	}{
+		{
+			name: "no policy annotations",
+		},
+		{
+			name:           "malformed header policy annotation",
+			annotations:    map[string]string{routev1.HTTPHeaderPolicyAnnotation: "not json"},
+			expectedErrors: 1,
+		},
+		{
+			name:           "malformed router classes annotation",
+			annotations:    map[string]string{routev1.RouterClassesAnnotation: "not json"},
+			expectedErrors: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		route := &routeapi.Route{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		errs := validatePolicyAnnotations(route, field.NewPath("metadata", "annotations"))
+
+		if len(errs) != tc.expectedErrors {
+			t.Errorf("Test case %s expected %d error(s), got %d. %v", tc.name, tc.expectedErrors, len(errs), errs)
+		}
+	}
+}
+
+func tooManyHeaderNames() []string {
+	names := make([]string, maxHeaderActions+1)
+	for i := range names {
+		names[i] = fmt.Sprintf("X-Header-%d", i)
+	}
+	return names
+}
+
 func TestValidateTLS(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -475,6 +634,56 @@ func TestValidateTLS(t *testing.T) {
 			},
 			expectedErrors: 1,
 		},
+		{
+			name: "Edge termination, external certificate",
+			route: &routeapi.Route{
+				Spec: routeapi.RouteSpec{
+					TLS: &routeapi.TLSConfig{
+						Termination:         routeapi.TLSTerminationEdge,
+						ExternalCertificate: &routeapi.LocalObjectReference{Name: "serving-cert"},
+					},
+				},
+			},
+			expectedErrors: 0,
+		},
+		{
+			name: "Edge termination, external certificate without a name",
+			route: &routeapi.Route{
+				Spec: routeapi.RouteSpec{
+					TLS: &routeapi.TLSConfig{
+						Termination:         routeapi.TLSTerminationEdge,
+						ExternalCertificate: &routeapi.LocalObjectReference{},
+					},
+				},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name: "Edge termination, external certificate with inline certs",
+			route: &routeapi.Route{
+				Spec: routeapi.RouteSpec{
+					TLS: &routeapi.TLSConfig{
+						Termination:         routeapi.TLSTerminationEdge,
+						Certificate:         "abc",
+						Key:                 "abc",
+						ExternalCertificate: &routeapi.LocalObjectReference{Name: "serving-cert"},
+					},
+				},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name: "Passthrough termination, external certificate",
+			route: &routeapi.Route{
+				Spec: routeapi.RouteSpec{
+					TLS: &routeapi.TLSConfig{
+						Termination:         routeapi.TLSTerminationPassthrough,
+						ExternalCertificate: &routeapi.LocalObjectReference{Name: "serving-cert"},
+					},
+				},
+			},
+			expectedErrors: 1,
+		},
 	}
 
 	for _, tc := range tests {
@@ -709,3 +918,213 @@ func TestValidateEdgeReencryptInsecureEdgeTerminationPolicy(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateRouteStatusUpdate(t *testing.T) {
+	base := &routeapi.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "bar",
+			Namespace:       "foo",
+			ResourceVersion: "1",
+		},
+		Spec: routeapi.RouteSpec{
+			Host: "host",
+			To: routeapi.RouteTargetReference{
+				Name: "serviceName",
+				Kind: "Service",
+			},
+		},
+	}
+	ingress := func(conditions ...routeapi.RouteIngressCondition) []routeapi.RouteIngress {
+		return []routeapi.RouteIngress{{
+			Host:       "host",
+			RouterName: "default",
+			Conditions: conditions,
+		}}
+	}
+
+	tests := []struct {
+		name           string
+		ingress        []routeapi.RouteIngress
+		expectedErrors int
+	}{
+		{
+			name:    "admitted condition only",
+			ingress: ingress(routeapi.RouteIngressCondition{Type: routeapi.RouteAdmitted, Status: "True"}),
+		},
+		{
+			name: "well-formed rollout state",
+			ingress: ingress(
+				routeapi.RouteIngressCondition{Type: routeapi.RouteObservedGeneration, Status: "True", Reason: "7"},
+				routeapi.RouteIngressCondition{Type: routeapi.RouteCanaryWeights, Status: "True", Message: `{"serviceName":100,"canary":20}`},
+			),
+		},
+		{
+			name:           "non-numeric observed generation",
+			ingress:        ingress(routeapi.RouteIngressCondition{Type: routeapi.RouteObservedGeneration, Status: "True", Reason: "current"}),
+			expectedErrors: 1,
+		},
+		{
+			name:           "negative observed generation",
+			ingress:        ingress(routeapi.RouteIngressCondition{Type: routeapi.RouteObservedGeneration, Status: "True", Reason: "-1"}),
+			expectedErrors: 1,
+		},
+		{
+			name:           "malformed canary snapshot",
+			ingress:        ingress(routeapi.RouteIngressCondition{Type: routeapi.RouteCanaryWeights, Status: "True", Message: "not json"}),
+			expectedErrors: 1,
+		},
+		{
+			name:           "canary weight out of range",
+			ingress:        ingress(routeapi.RouteIngressCondition{Type: routeapi.RouteCanaryWeights, Status: "True", Message: `{"serviceName":300}`}),
+			expectedErrors: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		newRoute := base.DeepCopy()
+		newRoute.Status.Ingress = tc.ingress
+		errs := ValidateRouteStatusUpdate(newRoute, base)
+		if len(errs) != tc.expectedErrors {
+			t.Errorf("%s: expected %d error(s), got %d. %v", tc.name, tc.expectedErrors, len(errs), errs)
+		}
+	}
+}
+
+func TestValidateRouteStatusUpdateIngressEntries(t *testing.T) {
+	base := &routeapi.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "bar",
+			Namespace:       "foo",
+			ResourceVersion: "1",
+		},
+		Spec: routeapi.RouteSpec{
+			Host: "host",
+			To: routeapi.RouteTargetReference{
+				Name: "serviceName",
+				Kind: "Service",
+			},
+		},
+	}
+	earlier := metav1.NewTime(metav1.Now().Add(-time.Hour))
+	now := metav1.Now()
+	admitted := func(transition metav1.Time) []routeapi.RouteIngressCondition {
+		return []routeapi.RouteIngressCondition{{Type: routeapi.RouteAdmitted, Status: "True", LastTransitionTime: &transition}}
+	}
+
+	tests := []struct {
+		name           string
+		oldIngress     []routeapi.RouteIngress
+		ingress        []routeapi.RouteIngress
+		expectedErrors int
+	}{
+		{
+			name: "distinct router names",
+			ingress: []routeapi.RouteIngress{
+				{Host: "host", RouterName: "default"},
+				{Host: "host", RouterName: "edge"},
+			},
+		},
+		{
+			name: "duplicate router name",
+			ingress: []routeapi.RouteIngress{
+				{Host: "host", RouterName: "default"},
+				{Host: "host", RouterName: "default"},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name: "same router name in different classes",
+			ingress: []routeapi.RouteIngress{
+				{Host: "host", RouterName: "default", RouterClass: "openshift-default"},
+				{Host: "host", RouterName: "default", RouterClass: "partner"},
+			},
+		},
+		{
+			name: "duplicate router name within a class",
+			ingress: []routeapi.RouteIngress{
+				{Host: "host", RouterName: "default", RouterClass: "partner"},
+				{Host: "host", RouterName: "default", RouterClass: "partner"},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name:       "transition time moves forward",
+			oldIngress: []routeapi.RouteIngress{{Host: "host", RouterName: "default", Conditions: admitted(earlier)}},
+			ingress:    []routeapi.RouteIngress{{Host: "host", RouterName: "default", Conditions: admitted(now)}},
+		},
+		{
+			name:           "transition time moves backwards",
+			oldIngress:     []routeapi.RouteIngress{{Host: "host", RouterName: "default", Conditions: admitted(now)}},
+			ingress:        []routeapi.RouteIngress{{Host: "host", RouterName: "default", Conditions: admitted(earlier)}},
+			expectedErrors: 1,
+		},
+		{
+			name:       "rewind against another class is allowed",
+			oldIngress: []routeapi.RouteIngress{{Host: "host", RouterName: "default", RouterClass: "partner", Conditions: admitted(now)}},
+			ingress:    []routeapi.RouteIngress{{Host: "host", RouterName: "default", Conditions: admitted(earlier)}},
+		},
+	}
+
+	for _, tc := range tests {
+		oldRoute := base.DeepCopy()
+		oldRoute.Status.Ingress = tc.oldIngress
+		newRoute := base.DeepCopy()
+		newRoute.Status.Ingress = tc.ingress
+		errs := ValidateRouteStatusUpdate(newRoute, oldRoute)
+		if len(errs) != tc.expectedErrors {
+			t.Errorf("%s: expected %d error(s), got %d. %v", tc.name, tc.expectedErrors, len(errs), errs)
+		}
+	}
+}
+
+func TestValidateSubdomainWildcardHost(t *testing.T) {
+	tests := []struct {
+		name           string
+		host           string
+		allowedDomains []string
+		expectedErrors int
+	}{
+		{
+			name:           "no allowed domains permits any subdomain",
+			host:           "www.anything.test",
+			expectedErrors: 0,
+		},
+		{
+			name:           "host without a subdomain",
+			host:           "localhost",
+			expectedErrors: 1,
+		},
+		{
+			name:           "subdomain of an allowed domain",
+			host:           "www.apps.example.com",
+			allowedDomains: []string{"apps.example.com"},
+			expectedErrors: 0,
+		},
+		{
+			name:           "deeper subdomain of an allowed domain",
+			host:           "www.team.apps.example.com",
+			allowedDomains: []string{"apps.example.com"},
+			expectedErrors: 0,
+		},
+		{
+			name:           "host outside the allowed domains",
+			host:           "www.evil.com",
+			allowedDomains: []string{"apps.example.com", "example.org"},
+			expectedErrors: 1,
+		},
+		{
+			name:           "suffix match must fall on a label boundary",
+			host:           "www.otherapps.example.com",
+			allowedDomains: []string{"apps.example.com"},
+			expectedErrors: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		errs := ValidateSubdomainWildcardHost(tc.host, sets.NewString(tc.allowedDomains...), nil)
+
+		if len(errs) != tc.expectedErrors {
+			t.Errorf("Test case %s expected %d error(s), got %d. %v", tc.name, tc.expectedErrors, len(errs), errs)
+		}
+	}
+}
@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 // Code generated by deepcopy-gen. DO NOT EDIT.
@@ -8,6 +9,22 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalObjectReference) DeepCopyInto(out *LocalObjectReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalObjectReference.
+func (in *LocalObjectReference) DeepCopy() *LocalObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Route) DeepCopyInto(out *Route) {
 	*out = *in
@@ -36,6 +53,66 @@ func (in *Route) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteHTTPHeader) DeepCopyInto(out *RouteHTTPHeader) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteHTTPHeader.
+func (in *RouteHTTPHeader) DeepCopy() *RouteHTTPHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteHTTPHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteHTTPHeaderActions) DeepCopyInto(out *RouteHTTPHeaderActions) {
+	*out = *in
+	if in.Set != nil {
+		in, out := &in.Set, &out.Set
+		*out = make([]RouteHTTPHeader, len(*in))
+		copy(*out, *in)
+	}
+	if in.Delete != nil {
+		in, out := &in.Delete, &out.Delete
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteHTTPHeaderActions.
+func (in *RouteHTTPHeaderActions) DeepCopy() *RouteHTTPHeaderActions {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteHTTPHeaderActions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteHTTPHeaders) DeepCopyInto(out *RouteHTTPHeaders) {
+	*out = *in
+	in.Request.DeepCopyInto(&out.Request)
+	in.Response.DeepCopyInto(&out.Response)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteHTTPHeaders.
+func (in *RouteHTTPHeaders) DeepCopy() *RouteHTTPHeaders {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteHTTPHeaders)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RouteIngress) DeepCopyInto(out *RouteIngress) {
 	*out = *in
@@ -148,7 +225,12 @@ func (in *RouteSpec) DeepCopyInto(out *RouteSpec) {
 	if in.TLS != nil {
 		in, out := &in.TLS, &out.TLS
 		*out = new(TLSConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPHeaders != nil {
+		in, out := &in.HTTPHeaders, &out.HTTPHeaders
+		*out = new(RouteHTTPHeaders)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
@@ -226,6 +308,11 @@ func (in *RouterShard) DeepCopy() *RouterShard {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
 	*out = *in
+	if in.ExternalCertificate != nil {
+		in, out := &in.ExternalCertificate, &out.ExternalCertificate
+		*out = new(LocalObjectReference)
+		**out = **in
+	}
 	return
 }
 
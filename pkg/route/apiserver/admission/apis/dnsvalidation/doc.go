@@ -0,0 +1,4 @@
+// +k8s:deepcopy-gen=package,register
+
+// Package dnsvalidation is the internal version of the API.
+package dnsvalidation
@@ -0,0 +1,6 @@
+// +k8s:conversion-gen=github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/dnsvalidation
+// +k8s:conversion-gen-external-types=github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/dnsvalidation/v1
+// +k8s:deepcopy-gen=package,register
+
+// Package v1 is the v1 version of the API.
+package v1
@@ -0,0 +1,17 @@
+package v1
+
+// This file contains methods that can be used by the go-restful package to generate Swagger
+// documentation for the object types found in 'types.go' This file is automatically generated
+// by hack/update-generated-swagger-descriptions.sh and should be run after a full build of OpenShift.
+// ==== DO NOT EDIT THIS FILE MANUALLY ====
+
+var map_DNSValidationConfig = map[string]string{
+	"":                "DNSValidationConfig is the configuration for the route DNS validation plug-in. It verifies that a route's custom host resolves to the cluster ingress before the route is accepted.",
+	"ingressTargets":  "IngressTargets are the hostnames and IP addresses of the cluster ingress. A host is accepted when its canonical name falls under one of the hostnames or one of its addresses equals one of the IP addresses.",
+	"failOpen":        "FailOpen admits routes and marks their validation result as unknown when DNS resolution fails for reasons other than the name not existing, such as a resolver outage.",
+	"cacheTTLSeconds": "CacheTTLSeconds is how long a resolution result is reused before the host is resolved again. Defaults to 300 seconds.",
+}
+
+func (DNSValidationConfig) SwaggerDoc() map[string]string {
+	return map_DNSValidationConfig
+}
@@ -0,0 +1,28 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DNSValidationConfig is the configuration for the route DNS validation plug-in.
+// It verifies that a route's custom host resolves to the cluster ingress before
+// the route is accepted.
+type DNSValidationConfig struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// IngressTargets are the hostnames and IP addresses of the cluster ingress.
+	// A host is accepted when its canonical name falls under one of the
+	// hostnames or one of its addresses equals one of the IP addresses.
+	IngressTargets []string `json:"ingressTargets"`
+
+	// FailOpen admits routes and marks their validation result as unknown when
+	// DNS resolution fails for reasons other than the name not existing, such
+	// as a resolver outage.
+	FailOpen bool `json:"failOpen"`
+
+	// CacheTTLSeconds is how long a resolution result is reused before the host
+	// is resolved again. Defaults to 300 seconds.
+	CacheTTLSeconds int32 `json:"cacheTTLSeconds"`
+}
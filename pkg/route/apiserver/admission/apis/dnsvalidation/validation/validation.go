@@ -0,0 +1,29 @@
+package validation
+
+import (
+	"net"
+
+	kvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/dnsvalidation"
+)
+
+func ValidateDNSValidationConfig(config *dnsvalidation.DNSValidationConfig) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(config.IngressTargets) == 0 {
+		allErrs = append(allErrs, field.Required(field.NewPath("ingressTargets"), "at least one ingress hostname or IP address is required"))
+	}
+	for i, target := range config.IngressTargets {
+		if net.ParseIP(target) != nil {
+			continue
+		}
+		if len(kvalidation.IsDNS1123Subdomain(target)) != 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("ingressTargets").Index(i), target, "must be a valid DNS subdomain or IP address"))
+		}
+	}
+	if config.CacheTTLSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("cacheTTLSeconds"), config.CacheTTLSeconds, "must not be negative"))
+	}
+	return allErrs
+}
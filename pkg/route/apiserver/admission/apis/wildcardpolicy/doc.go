@@ -0,0 +1,4 @@
+// +k8s:deepcopy-gen=package,register
+
+// Package wildcardpolicy is the internal version of the API.
+package wildcardpolicy
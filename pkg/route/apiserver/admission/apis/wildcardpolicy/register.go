@@ -0,0 +1,25 @@
+package wildcardpolicy
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	GroupName = "route.openshift.io"
+)
+
+var (
+	GroupVersion = schema.GroupVersion{Group: GroupName, Version: runtime.APIVersionInternal}
+
+	schemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+	Install       = schemeBuilder.AddToScheme
+)
+
+// Adds the list of known types to api.Scheme.
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(GroupVersion,
+		&WildcardPolicyConfig{},
+	)
+	return nil
+}
@@ -0,0 +1,17 @@
+package wildcardpolicy
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WildcardPolicyConfig is the configuration for the route wildcard policy plug-in.
+// It restricts which subdomains routes with the Subdomain wildcard policy may claim.
+type WildcardPolicyConfig struct {
+	metav1.TypeMeta
+
+	// AllowedDomains is the list of domains wildcard routes may claim subdomains of,
+	// typically the cluster ingress domain. An empty list allows any subdomain.
+	AllowedDomains []string
+}
@@ -0,0 +1,32 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/wildcardpolicy"
+)
+
+const (
+	GroupName = "route.openshift.io"
+)
+
+var (
+	GroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1"}
+
+	localSchemeBuilder = runtime.NewSchemeBuilder(
+		addKnownTypes,
+		wildcardpolicy.Install,
+	)
+	Install = localSchemeBuilder.AddToScheme
+)
+
+// Adds the list of known types to api.Scheme.
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(GroupVersion,
+		&WildcardPolicyConfig{},
+	)
+	metav1.AddToGroupVersion(scheme, GroupVersion)
+	return nil
+}
@@ -0,0 +1,15 @@
+package v1
+
+// This file contains methods that can be used by the go-restful package to generate Swagger
+// documentation for the object types found in 'types.go' This file is automatically generated
+// by hack/update-generated-swagger-descriptions.sh and should be run after a full build of OpenShift.
+// ==== DO NOT EDIT THIS FILE MANUALLY ====
+
+var map_WildcardPolicyConfig = map[string]string{
+	"":               "WildcardPolicyConfig is the configuration for the route wildcard policy plug-in. It restricts which subdomains routes with the Subdomain wildcard policy may claim.",
+	"allowedDomains": "AllowedDomains is the list of domains wildcard routes may claim subdomains of, typically the cluster ingress domain. An empty list allows any subdomain.",
+}
+
+func (WildcardPolicyConfig) SwaggerDoc() map[string]string {
+	return map_WildcardPolicyConfig
+}
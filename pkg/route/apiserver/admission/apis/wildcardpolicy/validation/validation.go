@@ -0,0 +1,18 @@
+package validation
+
+import (
+	kvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/wildcardpolicy"
+)
+
+func ValidateWildcardPolicyConfig(config *wildcardpolicy.WildcardPolicyConfig) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, domain := range config.AllowedDomains {
+		if len(kvalidation.IsDNS1123Subdomain(domain)) != 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("allowedDomains").Index(i), domain, "must be a valid DNS subdomain"))
+		}
+	}
+	return allErrs
+}
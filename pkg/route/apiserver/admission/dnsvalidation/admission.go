@@ -0,0 +1,248 @@
+package dnsvalidation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/api/route"
+	"github.com/openshift/library-go/pkg/config/helpers"
+	"github.com/openshift/openshift-apiserver/pkg/api/legacy"
+	routeapi "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
+	dnsvalidationapi "github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/dnsvalidation"
+	v1 "github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/dnsvalidation/v1"
+	dnsvalidationvalidation "github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/dnsvalidation/validation"
+)
+
+const pluginName = "route.openshift.io/DNSValidation"
+
+// hostValidatedAnnotation records the outcome of DNS validation on admitted
+// routes. The route status API has no field for admission-time checks, so the
+// result travels as an annotation that routers and audit tooling can inspect.
+const hostValidatedAnnotation = "route.openshift.io/host-dns-validated"
+
+const (
+	// hostValidationValid means the host resolved to the cluster ingress.
+	hostValidationValid = "valid"
+	// hostValidationUnknown means resolution failed and the plug-in is
+	// configured to fail open.
+	hostValidationUnknown = "unknown"
+)
+
+// defaultCacheTTL is applied when the config leaves CacheTTLSeconds unset.
+const defaultCacheTTL = 5 * time.Minute
+
+func Register(plugins *admission.Plugins) {
+	plugins.Register(pluginName,
+		func(config io.Reader) (admission.Interface, error) {
+			pluginConfig, err := readConfig(config)
+			if err != nil {
+				return nil, err
+			}
+			if pluginConfig == nil {
+				klog.Infof("Admission plugin %q is not configured so it will be disabled.", pluginName)
+				return nil, nil
+			}
+			return NewDNSValidation(pluginConfig)
+		})
+}
+
+func readConfig(reader io.Reader) (*dnsvalidationapi.DNSValidationConfig, error) {
+	obj, err := helpers.ReadYAMLToInternal(reader, dnsvalidationapi.Install, v1.Install)
+	if err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, nil
+	}
+	config, ok := obj.(*dnsvalidationapi.DNSValidationConfig)
+	if !ok {
+		return nil, fmt.Errorf("unexpected config object: %#v", obj)
+	}
+	errs := dnsvalidationvalidation.ValidateDNSValidationConfig(config)
+	if len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return config, nil
+}
+
+type dnsValidation struct {
+	*admission.Handler
+	// ingressHosts and ingressIPs are the configured targets split by kind; a
+	// host is valid when its canonical name falls under one of ingressHosts or
+	// one of its addresses is in ingressIPs
+	ingressHosts sets.String
+	ingressIPs   sets.String
+	// targets is the configured list verbatim, used in rejection messages
+	targets  []string
+	failOpen bool
+	cacheTTL time.Duration
+
+	// resolve returns the canonical name and addresses of host.  It is the
+	// standard resolver in production and is stubbed in tests.
+	resolve func(ctx context.Context, host string) (cname string, addrs []string, err error)
+	// now is replaced in tests exercising cache expiry
+	now func() time.Time
+
+	lock  sync.Mutex
+	cache map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	valid   bool
+	expires time.Time
+}
+
+var _ = admission.MutationInterface(&dnsValidation{})
+
+// Admit verifies that a route's host resolves to the cluster ingress before the
+// route is accepted, and records the outcome on the route. Results are cached
+// so repeated admissions of the same host do not hit the resolver every time.
+func (o *dnsValidation) Admit(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	switch a.GetResource().GroupResource() {
+	case route.Resource("routes"), legacy.Resource("routes"):
+	default:
+		return nil
+	}
+	routeObj, ok := a.GetObject().(*routeapi.Route)
+	if !ok {
+		return nil
+	}
+	host := strings.ToLower(routeObj.Spec.Host)
+	if len(host) == 0 {
+		// a host will be generated for the route; generated hosts live under
+		// the ingress domain by construction
+		return nil
+	}
+	// revalidating an unchanged host on every update would make routes with a
+	// broken resolver impossible to modify
+	if a.GetOperation() == admission.Update {
+		if old, ok := a.GetOldObject().(*routeapi.Route); ok && strings.ToLower(old.Spec.Host) == host {
+			return nil
+		}
+	}
+
+	valid, cached := o.cachedResult(host)
+	if !cached {
+		cname, addrs, err := o.resolve(ctx, host)
+		switch {
+		case err == nil:
+			valid = o.matchesIngress(cname, addrs)
+		case isNotFound(err):
+			// a definitive answer: the host does not point anywhere
+			valid = false
+		case o.failOpen:
+			utilruntime.HandleError(fmt.Errorf("%s: unable to resolve host %q, admitting because the plugin fails open: %v", pluginName, host, err))
+			setHostValidated(routeObj, hostValidationUnknown)
+			return nil
+		default:
+			return fmt.Errorf("unable to verify that host %q resolves to the cluster ingress: %v", host, err)
+		}
+		o.storeResult(host, valid)
+	}
+
+	if !valid {
+		return admission.NewForbidden(a, fmt.Errorf("host %q does not resolve to the cluster ingress; point its DNS at one of %v before creating the route", host, o.targets))
+	}
+	setHostValidated(routeObj, hostValidationValid)
+	return nil
+}
+
+// matchesIngress reports whether the canonical name or one of the resolved
+// addresses belongs to the configured ingress targets.
+func (o *dnsValidation) matchesIngress(cname string, addrs []string) bool {
+	cname = strings.ToLower(strings.TrimSuffix(cname, "."))
+	for _, target := range o.ingressHosts.List() {
+		if cname == target || strings.HasSuffix(cname, "."+target) {
+			return true
+		}
+	}
+	for _, addr := range addrs {
+		if ip := net.ParseIP(addr); ip != nil && o.ingressIPs.Has(ip.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *dnsValidation) cachedResult(host string) (valid bool, ok bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	entry, ok := o.cache[host]
+	if !ok || o.now().After(entry.expires) {
+		return false, false
+	}
+	return entry.valid, true
+}
+
+func (o *dnsValidation) storeResult(host string, valid bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.cache[host] = cacheEntry{valid: valid, expires: o.now().Add(o.cacheTTL)}
+}
+
+func setHostValidated(routeObj *routeapi.Route, result string) {
+	if routeObj.Annotations == nil {
+		routeObj.Annotations = map[string]string{}
+	}
+	routeObj.Annotations[hostValidatedAnnotation] = result
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+func (o *dnsValidation) ValidateInitialization() error {
+	if o.resolve == nil {
+		return fmt.Errorf("%s plugin requires a resolver", pluginName)
+	}
+	return nil
+}
+
+func NewDNSValidation(config *dnsvalidationapi.DNSValidationConfig) (admission.Interface, error) {
+	ingressHosts, ingressIPs := sets.NewString(), sets.NewString()
+	for _, target := range config.IngressTargets {
+		if ip := net.ParseIP(target); ip != nil {
+			ingressIPs.Insert(ip.String())
+			continue
+		}
+		ingressHosts.Insert(strings.ToLower(strings.TrimSuffix(target, ".")))
+	}
+	cacheTTL := defaultCacheTTL
+	if config.CacheTTLSeconds > 0 {
+		cacheTTL = time.Duration(config.CacheTTLSeconds) * time.Second
+	}
+	resolver := &net.Resolver{}
+	return &dnsValidation{
+		Handler:      admission.NewHandler(admission.Create, admission.Update),
+		ingressHosts: ingressHosts,
+		ingressIPs:   ingressIPs,
+		targets:      config.IngressTargets,
+		failOpen:     config.FailOpen,
+		cacheTTL:     cacheTTL,
+		resolve: func(ctx context.Context, host string) (string, []string, error) {
+			cname, err := resolver.LookupCNAME(ctx, host)
+			if err != nil {
+				return "", nil, err
+			}
+			addrs, err := resolver.LookupHost(ctx, host)
+			if err != nil {
+				return "", nil, err
+			}
+			return cname, addrs, nil
+		},
+		now:   time.Now,
+		cache: map[string]cacheEntry{},
+	}, nil
+}
@@ -0,0 +1,268 @@
+package dnsvalidation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"github.com/openshift/api/route"
+	routeapi "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
+	dnsvalidationapi "github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/dnsvalidation"
+)
+
+func TestReadConfig(t *testing.T) {
+	tests := []struct {
+		config      string
+		expected    *dnsvalidationapi.DNSValidationConfig
+		errExpected bool
+	}{
+		{
+			config: `apiVersion: route.openshift.io/v1
+kind: DNSValidationConfig
+ingressTargets:
+- router.apps.example.com
+- 192.0.2.10
+failOpen: true
+cacheTTLSeconds: 60
+`,
+			expected: &dnsvalidationapi.DNSValidationConfig{
+				IngressTargets:  []string{"router.apps.example.com", "192.0.2.10"},
+				FailOpen:        true,
+				CacheTTLSeconds: 60,
+			},
+		},
+		{
+			// without targets the plugin cannot verify anything
+			config: `apiVersion: route.openshift.io/v1
+kind: DNSValidationConfig
+`,
+			errExpected: true,
+		},
+		{
+			config: `apiVersion: route.openshift.io/v1
+kind: DNSValidationConfig
+ingressTargets:
+- "not a target"
+`,
+			errExpected: true,
+		},
+	}
+
+	for n, tc := range tests {
+		cfg, err := readConfig(bytes.NewBufferString(tc.config))
+		if err != nil && !tc.errExpected {
+			t.Errorf("%d: unexpected error: %v", n, err)
+			continue
+		}
+		if err == nil && tc.errExpected {
+			t.Errorf("%d: expected error, got none", n)
+			continue
+		}
+		if tc.errExpected {
+			continue
+		}
+		if !reflect.DeepEqual(cfg.IngressTargets, tc.expected.IngressTargets) || cfg.FailOpen != tc.expected.FailOpen || cfg.CacheTTLSeconds != tc.expected.CacheTTLSeconds {
+			t.Errorf("%d: unexpected result. Got %#v. Expected %#v", n, cfg, tc.expected)
+		}
+	}
+}
+
+type fakeResolver struct {
+	cname   string
+	addrs   []string
+	err     error
+	lookups int
+}
+
+func (f *fakeResolver) resolve(ctx context.Context, host string) (string, []string, error) {
+	f.lookups++
+	return f.cname, f.addrs, f.err
+}
+
+func newTestPlugin(t *testing.T, config *dnsvalidationapi.DNSValidationConfig, resolver *fakeResolver) *dnsValidation {
+	plugin, err := NewDNSValidation(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	validation := plugin.(*dnsValidation)
+	validation.resolve = resolver.resolve
+	if err := validation.ValidateInitialization(); err != nil {
+		t.Fatalf("validation error: %v", err)
+	}
+	return validation
+}
+
+func admit(plugin *dnsValidation, routeObj *routeapi.Route) error {
+	return plugin.Admit(context.TODO(), admission.NewAttributesRecord(
+		routeObj,
+		nil,
+		route.Kind("Route").WithVersion("version"),
+		routeObj.Namespace,
+		routeObj.Name,
+		route.Resource("routes").WithVersion("version"),
+		"",
+		"CREATE",
+		nil,
+		false,
+		&user.DefaultInfo{Name: "bob"}), nil)
+}
+
+func testRoute(host string) *routeapi.Route {
+	return &routeapi.Route{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "one", Name: "frontend"},
+		Spec:       routeapi.RouteSpec{Host: host},
+	}
+}
+
+func testConfig() *dnsvalidationapi.DNSValidationConfig {
+	return &dnsvalidationapi.DNSValidationConfig{
+		IngressTargets: []string{"router.apps.example.com", "192.0.2.10"},
+	}
+}
+
+func TestAdmitResolution(t *testing.T) {
+	tests := []struct {
+		name        string
+		resolver    *fakeResolver
+		failOpen    bool
+		expected    string
+		errExpected bool
+		forbidden   bool
+	}{
+		{
+			name:     "cname points at the ingress",
+			resolver: &fakeResolver{cname: "router.apps.example.com."},
+			expected: hostValidationValid,
+		},
+		{
+			name:     "cname under the ingress hostname",
+			resolver: &fakeResolver{cname: "shard-1.router.apps.example.com."},
+			expected: hostValidationValid,
+		},
+		{
+			name:     "address points at the ingress",
+			resolver: &fakeResolver{cname: "www.example.com.", addrs: []string{"192.0.2.10"}},
+			expected: hostValidationValid,
+		},
+		{
+			name:        "resolves elsewhere",
+			resolver:    &fakeResolver{cname: "www.example.com.", addrs: []string{"198.51.100.1"}},
+			errExpected: true,
+			forbidden:   true,
+		},
+		{
+			name:        "name does not exist",
+			resolver:    &fakeResolver{err: &net.DNSError{Err: "no such host", Name: "www.example.com", IsNotFound: true}},
+			errExpected: true,
+			forbidden:   true,
+		},
+		{
+			name:        "resolver outage fails closed by default",
+			resolver:    &fakeResolver{err: &net.DNSError{Err: "i/o timeout", Name: "www.example.com", IsTimeout: true}},
+			errExpected: true,
+		},
+		{
+			name:     "resolver outage fails open when configured",
+			resolver: &fakeResolver{err: &net.DNSError{Err: "i/o timeout", Name: "www.example.com", IsTimeout: true}},
+			failOpen: true,
+			expected: hostValidationUnknown,
+		},
+	}
+
+	for _, tc := range tests {
+		config := testConfig()
+		config.FailOpen = tc.failOpen
+		plugin := newTestPlugin(t, config, tc.resolver)
+
+		routeObj := testRoute("www.example.com")
+		err := admit(plugin, routeObj)
+		if tc.errExpected {
+			if err == nil {
+				t.Errorf("%s: expected error, got none", tc.name)
+			} else if tc.forbidden != apierrors.IsForbidden(err) {
+				t.Errorf("%s: expected forbidden=%v, got %v", tc.name, tc.forbidden, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if actual := routeObj.Annotations[hostValidatedAnnotation]; actual != tc.expected {
+			t.Errorf("%s: expected annotation %q, got %q", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestAdmitSkipsGeneratedHosts(t *testing.T) {
+	resolver := &fakeResolver{err: fmt.Errorf("must not be called")}
+	plugin := newTestPlugin(t, testConfig(), resolver)
+
+	routeObj := testRoute("")
+	if err := admit(plugin, routeObj); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolver.lookups != 0 {
+		t.Errorf("expected no lookups for a route without a host, got %d", resolver.lookups)
+	}
+}
+
+func TestAdmitSkipsUnchangedHosts(t *testing.T) {
+	resolver := &fakeResolver{err: fmt.Errorf("must not be called")}
+	plugin := newTestPlugin(t, testConfig(), resolver)
+
+	routeObj := testRoute("www.example.com")
+	err := plugin.Admit(context.TODO(), admission.NewAttributesRecord(
+		routeObj,
+		testRoute("www.example.com"),
+		route.Kind("Route").WithVersion("version"),
+		routeObj.Namespace,
+		routeObj.Name,
+		route.Resource("routes").WithVersion("version"),
+		"",
+		"UPDATE",
+		nil,
+		false,
+		&user.DefaultInfo{Name: "bob"}), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolver.lookups != 0 {
+		t.Errorf("expected no lookups for an unchanged host, got %d", resolver.lookups)
+	}
+}
+
+func TestAdmitCachesResults(t *testing.T) {
+	resolver := &fakeResolver{cname: "router.apps.example.com."}
+	plugin := newTestPlugin(t, testConfig(), resolver)
+
+	now := time.Now()
+	plugin.now = func() time.Time { return now }
+
+	if err := admit(plugin, testRoute("www.example.com")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := admit(plugin, testRoute("www.example.com")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolver.lookups != 1 {
+		t.Errorf("expected the second admission to be served from the cache, got %d lookups", resolver.lookups)
+	}
+
+	now = now.Add(defaultCacheTTL + time.Second)
+	if err := admit(plugin, testRoute("www.example.com")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolver.lookups != 2 {
+		t.Errorf("expected an expired entry to be resolved again, got %d lookups", resolver.lookups)
+	}
+}
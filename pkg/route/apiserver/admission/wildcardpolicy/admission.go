@@ -0,0 +1,146 @@
+package wildcardpolicy
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/api/route"
+	routev1 "github.com/openshift/api/route/v1"
+	routev1typedclient "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	"github.com/openshift/library-go/pkg/apiserver/admission/admissionrestconfig"
+	"github.com/openshift/library-go/pkg/config/helpers"
+	"github.com/openshift/openshift-apiserver/pkg/api/legacy"
+	routeapi "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
+	routevalidation "github.com/openshift/openshift-apiserver/pkg/route/apis/route/validation"
+	wildcardpolicyapi "github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/wildcardpolicy"
+	v1 "github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/wildcardpolicy/v1"
+	wildcardpolicyvalidation "github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/wildcardpolicy/validation"
+)
+
+const pluginName = "route.openshift.io/WildcardPolicy"
+
+func Register(plugins *admission.Plugins) {
+	plugins.Register(pluginName,
+		func(config io.Reader) (admission.Interface, error) {
+			pluginConfig, err := readConfig(config)
+			if err != nil {
+				return nil, err
+			}
+			if pluginConfig == nil {
+				klog.Infof("Admission plugin %q is not configured so it will be disabled.", pluginName)
+				return nil, nil
+			}
+			return NewWildcardPolicy(pluginConfig)
+		})
+}
+
+func readConfig(reader io.Reader) (*wildcardpolicyapi.WildcardPolicyConfig, error) {
+	obj, err := helpers.ReadYAMLToInternal(reader, wildcardpolicyapi.Install, v1.Install)
+	if err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, nil
+	}
+	config, ok := obj.(*wildcardpolicyapi.WildcardPolicyConfig)
+	if !ok {
+		return nil, fmt.Errorf("unexpected config object: %#v", obj)
+	}
+	errs := wildcardpolicyvalidation.ValidateWildcardPolicyConfig(config)
+	if len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return config, nil
+}
+
+type wildcardPolicy struct {
+	*admission.Handler
+	// allowedDomains restricts the subdomains wildcard routes may claim; empty
+	// allows any subdomain
+	allowedDomains sets.String
+	// listRoutes lists routes in every namespace.  It is a typed client in
+	// production and is stubbed in tests.
+	listRoutes func(ctx context.Context) (*routev1.RouteList, error)
+}
+
+// ensure that the required Openshift admission interfaces are implemented
+var _ = admissionrestconfig.WantsRESTClientConfig(&wildcardPolicy{})
+var _ = admission.ValidationInterface(&wildcardPolicy{})
+
+// Validate rejects Subdomain wildcard routes whose host falls outside the
+// configured allowed domains, and wildcard claims that would shadow hosts
+// already claimed by routes in other namespaces.
+func (o *wildcardPolicy) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	switch a.GetResource().GroupResource() {
+	case route.Resource("routes"), legacy.Resource("routes"):
+	default:
+		return nil
+	}
+	routeObj, ok := a.GetObject().(*routeapi.Route)
+	if !ok {
+		return nil
+	}
+	if routeObj.Spec.WildcardPolicy != routeapi.WildcardPolicySubdomain {
+		return nil
+	}
+
+	if errs := routevalidation.ValidateSubdomainWildcardHost(routeObj.Spec.Host, o.allowedDomains, field.NewPath("spec", "host")); len(errs) > 0 {
+		return admission.NewForbidden(a, errs.ToAggregate())
+	}
+
+	// wildcard routes are infrequent and this check needs a consistent view of
+	// every namespace, so a live list is preferable to a dedicated informer
+	subdomain := routevalidation.WildcardSubdomain(routeObj.Spec.Host)
+	existing, err := o.listRoutes(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range existing.Items {
+		other := &existing.Items[i]
+		// routes in the claiming namespace may share the subdomain
+		if other.Namespace == routeObj.Namespace {
+			continue
+		}
+		if len(other.Spec.Host) == 0 {
+			continue
+		}
+		if routevalidation.WildcardSubdomain(other.Spec.Host) == subdomain {
+			return admission.NewForbidden(a, fmt.Errorf("wildcard for subdomain %q would shadow host %q claimed by route %s/%s", subdomain, other.Spec.Host, other.Namespace, other.Name))
+		}
+	}
+	return nil
+}
+
+func (o *wildcardPolicy) SetRESTClientConfig(restClientConfig rest.Config) {
+	routeClient, err := routev1typedclient.NewForConfig(&restClientConfig)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	o.listRoutes = func(ctx context.Context) (*routev1.RouteList, error) {
+		return routeClient.Routes(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	}
+}
+
+func (o *wildcardPolicy) ValidateInitialization() error {
+	if o.listRoutes == nil {
+		return fmt.Errorf("%s plugin requires an Openshift route client", pluginName)
+	}
+	return nil
+}
+
+func NewWildcardPolicy(config *wildcardpolicyapi.WildcardPolicyConfig) (admission.Interface, error) {
+	return &wildcardPolicy{
+		Handler:        admission.NewHandler(admission.Create, admission.Update),
+		allowedDomains: sets.NewString(config.AllowedDomains...),
+	}, nil
+}
@@ -0,0 +1,158 @@
+package wildcardpolicy
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"github.com/openshift/api/route"
+	routev1 "github.com/openshift/api/route/v1"
+	routeapi "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
+	wildcardpolicyapi "github.com/openshift/openshift-apiserver/pkg/route/apiserver/admission/apis/wildcardpolicy"
+)
+
+func TestReadConfig(t *testing.T) {
+	tests := []struct {
+		config      string
+		expected    *wildcardpolicyapi.WildcardPolicyConfig
+		errExpected bool
+	}{
+		{
+			config: `apiVersion: route.openshift.io/v1
+kind: WildcardPolicyConfig
+allowedDomains:
+- apps.example.com
+- example.org
+`,
+			expected: &wildcardpolicyapi.WildcardPolicyConfig{
+				AllowedDomains: []string{"apps.example.com", "example.org"},
+			},
+		},
+		{
+			config: `apiVersion: route.openshift.io/v1
+kind: WildcardPolicyConfig
+`,
+			expected: &wildcardpolicyapi.WildcardPolicyConfig{},
+		},
+		{
+			config: `apiVersion: route.openshift.io/v1
+kind: WildcardPolicyConfig
+allowedDomains:
+- "not a domain"
+`,
+			errExpected: true,
+		},
+	}
+
+	for n, tc := range tests {
+		cfg, err := readConfig(bytes.NewBufferString(tc.config))
+		if err != nil && !tc.errExpected {
+			t.Errorf("%d: unexpected error: %v", n, err)
+			continue
+		}
+		if err == nil && tc.errExpected {
+			t.Errorf("%d: expected error, got none", n)
+			continue
+		}
+		if tc.errExpected {
+			continue
+		}
+		if !reflect.DeepEqual(cfg.AllowedDomains, tc.expected.AllowedDomains) {
+			t.Errorf("%d: unexpected result. Got %#v. Expected %#v", n, cfg, tc.expected)
+		}
+	}
+}
+
+func newTestPlugin(t *testing.T, allowedDomains []string, existing ...routev1.Route) *wildcardPolicy {
+	plugin, err := NewWildcardPolicy(&wildcardpolicyapi.WildcardPolicyConfig{AllowedDomains: allowedDomains})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	policy := plugin.(*wildcardPolicy)
+	policy.listRoutes = func(ctx context.Context) (*routev1.RouteList, error) {
+		return &routev1.RouteList{Items: existing}, nil
+	}
+	if err := policy.ValidateInitialization(); err != nil {
+		t.Fatalf("validation error: %v", err)
+	}
+	return policy
+}
+
+func validate(plugin *wildcardPolicy, routeObj *routeapi.Route) error {
+	return plugin.Validate(context.TODO(), admission.NewAttributesRecord(
+		routeObj,
+		nil,
+		route.Kind("Route").WithVersion("version"),
+		routeObj.Namespace,
+		routeObj.Name,
+		route.Resource("routes").WithVersion("version"),
+		"",
+		"CREATE",
+		nil,
+		false,
+		&user.DefaultInfo{Name: "bob"}), nil)
+}
+
+func wildcardRoute(namespace, name, host string) *routeapi.Route {
+	return &routeapi.Route{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: routeapi.RouteSpec{
+			Host:           host,
+			WildcardPolicy: routeapi.WildcardPolicySubdomain,
+		},
+	}
+}
+
+func TestValidateAllowedDomains(t *testing.T) {
+	plugin := newTestPlugin(t, []string{"apps.example.com"})
+
+	if err := validate(plugin, wildcardRoute("one", "frontend", "www.apps.example.com")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validate(plugin, wildcardRoute("one", "nested", "www.team.apps.example.com")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validate(plugin, wildcardRoute("one", "outside", "www.evil.com")); !apierrors.IsForbidden(err) {
+		t.Errorf("expected forbidden for a host outside the allowed domains, got %v", err)
+	}
+	if err := validate(plugin, wildcardRoute("one", "bare", "localhost")); !apierrors.IsForbidden(err) {
+		t.Errorf("expected forbidden for a host without a subdomain, got %v", err)
+	}
+}
+
+func TestValidateShadowing(t *testing.T) {
+	existing := []routev1.Route{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "claimed"},
+			Spec:       routev1.RouteSpec{Host: "www.team.apps.example.com"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "one", Name: "mine"},
+			Spec:       routev1.RouteSpec{Host: "api.shared.apps.example.com"},
+		},
+	}
+	plugin := newTestPlugin(t, nil, existing...)
+
+	// claims *.team.apps.example.com, which covers the other namespace's host
+	if err := validate(plugin, wildcardRoute("one", "wildcard", "any.team.apps.example.com")); !apierrors.IsForbidden(err) {
+		t.Errorf("expected forbidden for a claim shadowing another namespace, got %v", err)
+	}
+
+	// the shadowed host belongs to the claiming namespace
+	if err := validate(plugin, wildcardRoute("one", "wildcard", "any.shared.apps.example.com")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// non-wildcard routes are ignored entirely
+	plain := wildcardRoute("one", "plain", "www.team.apps.example.com")
+	plain.Spec.WildcardPolicy = routeapi.WildcardPolicyNone
+	if err := validate(plugin, plain); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -10,9 +10,15 @@ import (
 	"k8s.io/apiserver/pkg/registry/rest"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	restclient "k8s.io/client-go/rest"
 
 	routeapiv1 "github.com/openshift/api/route/v1"
+	routev1client "github.com/openshift/client-go/route/clientset/versioned"
+	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/certexpiry"
+	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/hostclaimpolicy"
+	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/registry/hostindex"
+	routeregistry "github.com/openshift/openshift-apiserver/pkg/route/apiserver/registry/route"
 	routeetcd "github.com/openshift/openshift-apiserver/pkg/route/apiserver/registry/route/etcd"
 	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/routeallocationcontroller"
 )
@@ -20,6 +26,9 @@ import (
 type ExtraConfig struct {
 	KubeAPIServerClientConfig *restclient.Config
 	RouteAllocator            *routeallocationcontroller.RouteAllocationController
+	// HostClaimPolicy, when set, is consulted before the custom-host subject
+	// access review for host and certificate changes.
+	HostClaimPolicy *hostclaimpolicy.Evaluator
 
 	// TODO these should all become local eventually
 	Scheme *runtime.Scheme
@@ -28,6 +37,7 @@ type ExtraConfig struct {
 	makeV1Storage sync.Once
 	v1Storage     map[string]rest.Storage
 	v1StorageErr  error
+	startFns      []func(<-chan struct{})
 }
 
 type RouteAPIServerConfig struct {
@@ -81,6 +91,15 @@ func (c completedConfig) New(delegationTarget genericapiserver.DelegationTarget)
 		return nil, err
 	}
 
+	if err := s.GenericAPIServer.AddPostStartHook("route.openshift.io-apiserver-caches", func(context genericapiserver.PostStartHookContext) error {
+		for _, fn := range c.ExtraConfig.startFns {
+			go fn(context.StopCh)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
 	return s, nil
 }
 
@@ -97,13 +116,28 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 	if err != nil {
 		return nil, err
 	}
-	routeStorage, routeStatusStorage, err := routeetcd.NewREST(c.GenericConfig.RESTOptionsGetter, c.ExtraConfig.RouteAllocator, authorizationClient.SubjectAccessReviews())
+	coreClient, err := corev1client.NewForConfig(c.ExtraConfig.KubeAPIServerClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	routeStorage, routeStatusStorage, err := routeetcd.NewREST(c.GenericConfig.RESTOptionsGetter, c.ExtraConfig.RouteAllocator, authorizationClient.SubjectAccessReviews(), routeregistry.NewClientSecretGetter(coreClient), c.ExtraConfig.HostClaimPolicy)
 	if err != nil {
 		return nil, fmt.Errorf("error building REST storage: %v", err)
 	}
 
+	routeClient, err := routev1client.NewForConfig(c.GenericConfig.LoopbackClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	hostOwnershipIndex := hostindex.NewHostOwnershipIndex(routeClient.RouteV1().Routes(metav1.NamespaceAll))
+	c.ExtraConfig.startFns = append(c.ExtraConfig.startFns, hostOwnershipIndex.Run)
+
+	certScanner := certexpiry.NewScanner(routeClient.RouteV1().Routes(metav1.NamespaceAll))
+	c.ExtraConfig.startFns = append(c.ExtraConfig.startFns, certScanner.Run)
+
 	v1Storage := map[string]rest.Storage{}
 	v1Storage["routes"] = routeStorage
 	v1Storage["routes/status"] = routeStatusStorage
+	v1Storage["routeHostOverviews"] = hostindex.NewREST(hostOwnershipIndex)
 	return v1Storage, nil
 }
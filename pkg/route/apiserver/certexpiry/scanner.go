@@ -0,0 +1,152 @@
+package certexpiry
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+var (
+	certificateExpiryDays = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Name: "openshift_apiserver_route_tls_certificate_expiry_days",
+			Help: "Days until the earliest inline TLS certificate of a namespace's routes expires, labeled by namespace. Negative once a certificate has expired.",
+		},
+		[]string{"namespace"},
+	)
+	invalidCertificates = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Name: "openshift_apiserver_route_tls_invalid_certificates",
+			Help: "Number of routes whose inline TLS certificate cannot be parsed, labeled by namespace.",
+		},
+		[]string{"namespace"},
+	)
+)
+
+func init() {
+	legacyregistry.MustRegister(certificateExpiryDays)
+	legacyregistry.MustRegister(invalidCertificates)
+}
+
+// defaultScanInterval is how often routes are rescanned. Expiry is reported
+// in days, so a short interval buys nothing; the interval mostly bounds how
+// long a deleted namespace keeps its stale gauge.
+const defaultScanInterval = 15 * time.Minute
+
+// RouteLister lists routes across all namespaces.
+type RouteLister interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*routev1.RouteList, error)
+}
+
+// Scanner periodically reads every route served by this apiserver and
+// publishes per-namespace gauges about inline TLS certificate expiry, so
+// platform alerting can catch expiring route certificates without a separate
+// scanner that needs read access to every route object.
+type Scanner struct {
+	client   RouteLister
+	interval time.Duration
+	now      func() time.Time
+
+	// namespaces published by the previous scan, so gauges of namespaces
+	// that no longer have inline certificates are deleted
+	published sets.String
+}
+
+// NewScanner returns a scanner over the given route lister.
+func NewScanner(client RouteLister) *Scanner {
+	return &Scanner{
+		client:    client,
+		interval:  defaultScanInterval,
+		now:       time.Now,
+		published: sets.NewString(),
+	}
+}
+
+// Run scans routes until stopCh is closed.
+func (s *Scanner) Run(stopCh <-chan struct{}) {
+	wait.Until(s.scan, s.interval, stopCh)
+}
+
+func (s *Scanner) scan() {
+	list, err := s.client.List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.V(2).Infof("Unable to list routes for certificate expiry metrics: %v", err)
+		return
+	}
+
+	minExpiry := map[string]time.Time{}
+	invalid := map[string]int{}
+	for i := range list.Items {
+		route := &list.Items[i]
+		if route.Spec.TLS == nil || len(route.Spec.TLS.Certificate) == 0 {
+			continue
+		}
+		expiry, err := earliestExpiry([]byte(route.Spec.TLS.Certificate))
+		if err != nil {
+			invalid[route.Namespace]++
+			continue
+		}
+		if current, ok := minExpiry[route.Namespace]; !ok || expiry.Before(current) {
+			minExpiry[route.Namespace] = expiry
+		}
+	}
+
+	now := s.now()
+	published := sets.NewString()
+	for namespace, expiry := range minExpiry {
+		certificateExpiryDays.WithLabelValues(namespace).Set(expiry.Sub(now).Hours() / 24)
+		published.Insert(namespace)
+	}
+	for namespace, count := range invalid {
+		invalidCertificates.WithLabelValues(namespace).Set(float64(count))
+		published.Insert(namespace)
+	}
+	for namespace := range s.published {
+		if published.Has(namespace) {
+			continue
+		}
+		certificateExpiryDays.DeleteLabelValues(namespace)
+		invalidCertificates.DeleteLabelValues(namespace)
+	}
+	s.published = published
+}
+
+// earliestExpiry parses every certificate of a PEM bundle and returns the
+// earliest NotAfter, typically that of the leaf.
+func earliestExpiry(bundle []byte) (time.Time, error) {
+	var earliest time.Time
+	found := false
+	for len(bundle) > 0 {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !found || cert.NotAfter.Before(earliest) {
+			earliest = cert.NotAfter
+			found = true
+		}
+	}
+	if !found {
+		return time.Time{}, fmt.Errorf("no certificates found in PEM input")
+	}
+	return earliest, nil
+}
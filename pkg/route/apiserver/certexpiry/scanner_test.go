@@ -0,0 +1,120 @@
+package certexpiry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics/testutil"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+type fakeLister struct {
+	list *routev1.RouteList
+}
+
+func (f *fakeLister) List(ctx context.Context, opts metav1.ListOptions) (*routev1.RouteList, error) {
+	return f.list, nil
+}
+
+func certExpiringIn(t *testing.T, now time.Time, d time.Duration) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(d),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func route(namespace, name, certificate string) routev1.Route {
+	r := routev1.Route{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	if len(certificate) > 0 {
+		r.Spec.TLS = &routev1.TLSConfig{Certificate: certificate}
+	}
+	return r
+}
+
+func gaugeValue(t *testing.T, namespace string) float64 {
+	t.Helper()
+	value, err := testutil.GetGaugeMetricValue(certificateExpiryDays.WithLabelValues(namespace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestScan(t *testing.T) {
+	now := time.Now()
+	lister := &fakeLister{list: &routev1.RouteList{Items: []routev1.Route{
+		route("alpha", "soon", certExpiringIn(t, now, 10*24*time.Hour)),
+		route("alpha", "later", certExpiringIn(t, now, 100*24*time.Hour)),
+		route("beta", "expired", certExpiringIn(t, now, -5*24*time.Hour)),
+		route("gamma", "no-tls", ""),
+		route("delta", "garbage", "not a certificate"),
+	}}}
+	scanner := NewScanner(lister)
+	scanner.now = func() time.Time { return now }
+	scanner.scan()
+
+	// the namespace gauge reflects the earliest expiry among its routes
+	if value := gaugeValue(t, "alpha"); math.Abs(value-10) > 0.1 {
+		t.Errorf("alpha: expected ~10 days, got %v", value)
+	}
+	if value := gaugeValue(t, "beta"); math.Abs(value+5) > 0.1 {
+		t.Errorf("beta: expected ~-5 days, got %v", value)
+	}
+	invalid, err := testutil.GetGaugeMetricValue(invalidCertificates.WithLabelValues("delta"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invalid != 1 {
+		t.Errorf("delta: expected 1 invalid certificate, got %v", invalid)
+	}
+
+	// namespaces whose inline certificates are gone drop their gauges
+	lister.list = &routev1.RouteList{Items: []routev1.Route{
+		route("alpha", "soon", certExpiringIn(t, now, 10*24*time.Hour)),
+	}}
+	scanner.scan()
+	if scanner.published.Has("beta") || scanner.published.Has("delta") {
+		t.Errorf("expected beta and delta to be unpublished, got %v", scanner.published.List())
+	}
+}
+
+func TestEarliestExpiry(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	leaf := certExpiringIn(t, now, 24*time.Hour)
+	ca := certExpiringIn(t, now, 240*time.Hour)
+
+	expiry, err := earliestExpiry([]byte(leaf + ca))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := expiry.UTC(), now.Add(24*time.Hour).UTC(); !got.Equal(expected) {
+		t.Errorf("expected earliest expiry %v, got %v", expected, got)
+	}
+
+	if _, err := earliestExpiry([]byte("junk")); err == nil {
+		t.Error("expected an error for input without certificates")
+	}
+}
@@ -0,0 +1,129 @@
+package hostclaimpolicy
+
+import (
+	"testing"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	"sigs.k8s.io/yaml"
+)
+
+const testPolicy = `
+rules:
+- name: infra-hosts-for-admins
+  decision: Allow
+  groups: ["system:cluster-admins"]
+  hosts: ["*.infra.example.com"]
+- name: no-infra-hosts
+  decision: Deny
+  hosts: ["*.infra.example.com"]
+- name: ci-certificate-freeze
+  decision: Deny
+  namespaces: ["ci"]
+  certificateChanges: true
+`
+
+func testEvaluator(t *testing.T) *Evaluator {
+	t.Helper()
+	policy := &Policy{}
+	if err := yaml.Unmarshal([]byte(testPolicy), policy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	evaluator, err := NewEvaluator(policy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return evaluator
+}
+
+func TestEvaluate(t *testing.T) {
+	evaluator := testEvaluator(t)
+
+	testCases := []struct {
+		name              string
+		user              user.Info
+		namespace         string
+		host              string
+		certificateChange bool
+		decision          Decision
+		rule              string
+		matched           bool
+	}{
+		{
+			name:     "admin may claim infra hosts",
+			user:     &user.DefaultInfo{Name: "alice", Groups: []string{"system:cluster-admins"}},
+			host:     "console.infra.example.com",
+			decision: Allow,
+			rule:     "infra-hosts-for-admins",
+			matched:  true,
+		},
+		{
+			name:     "others may not claim infra hosts",
+			user:     &user.DefaultInfo{Name: "bob"},
+			host:     "console.infra.example.com",
+			decision: Deny,
+			rule:     "no-infra-hosts",
+			matched:  true,
+		},
+		{
+			name:              "certificate changes are frozen in ci",
+			user:              &user.DefaultInfo{Name: "bob"},
+			namespace:         "ci",
+			host:              "app.example.com",
+			certificateChange: true,
+			decision:          Deny,
+			rule:              "ci-certificate-freeze",
+			matched:           true,
+		},
+		{
+			name:      "host changes in ci fall through to the SAR",
+			user:      &user.DefaultInfo{Name: "bob"},
+			namespace: "ci",
+			host:      "app.example.com",
+		},
+		{
+			name: "unmatched hosts fall through to the SAR",
+			user: &user.DefaultInfo{Name: "bob"},
+			host: "app.example.com",
+		},
+	}
+	for _, tc := range testCases {
+		decision, rule, matched := evaluator.Evaluate(tc.user, tc.namespace, tc.host, tc.certificateChange)
+		if matched != tc.matched || decision != tc.decision || rule != tc.rule {
+			t.Errorf("%s: expected (%q, %q, %t), got (%q, %q, %t)", tc.name, tc.decision, tc.rule, tc.matched, decision, rule, matched)
+		}
+	}
+}
+
+func TestNewEvaluatorValidation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		policy    *Policy
+		expectErr bool
+	}{
+		{
+			name:   "empty policy",
+			policy: &Policy{},
+		},
+		{
+			name:      "missing name",
+			policy:    &Policy{Rules: []Rule{{Decision: Allow}}},
+			expectErr: true,
+		},
+		{
+			name:      "unknown decision",
+			policy:    &Policy{Rules: []Rule{{Name: "rule", Decision: "Maybe"}}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid host pattern",
+			policy:    &Policy{Rules: []Rule{{Name: "rule", Decision: Deny, Hosts: []string{"[invalid"}}}},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		_, err := NewEvaluator(tc.policy)
+		if tc.expectErr != (err != nil) {
+			t.Errorf("%s: expected error %t, got %v", tc.name, tc.expectErr, err)
+		}
+	}
+}
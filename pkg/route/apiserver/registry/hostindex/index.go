@@ -0,0 +1,159 @@
+package hostindex
+
+import (
+	"context"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/reducedinformer"
+)
+
+// HostOwnershipIndex is a cache of the hostnames claimed by each route.
+// Only the small subset of each route needed to answer ownership queries is
+// held in memory.
+type HostOwnershipIndex interface {
+	HasSynced() bool
+	List() []*RouteHosts
+	Run(stopCh <-chan struct{})
+}
+
+type RouteListWatch interface {
+	List(context.Context, metav1.ListOptions) (*routev1.RouteList, error)
+	Watch(context.Context, metav1.ListOptions) (watch.Interface, error)
+}
+
+type hostOwnershipIndex struct {
+	informer cache.SharedIndexInformer
+}
+
+func (i hostOwnershipIndex) HasSynced() bool {
+	return i.informer.HasSynced()
+}
+
+func (i hostOwnershipIndex) List() []*RouteHosts {
+	items := i.informer.GetStore().List()
+	entries := make([]*RouteHosts, 0, len(items))
+	for _, item := range items {
+		if entry, ok := item.(*RouteHosts); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (i hostOwnershipIndex) Run(stopCh <-chan struct{}) {
+	i.informer.Run(stopCh)
+}
+
+// NewHostOwnershipIndex creates an index over a store that must return
+// routes.
+func NewHostOwnershipIndex(lw RouteListWatch) HostOwnershipIndex {
+	informer := reducedinformer.New(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return lw.List(context.TODO(), metav1.ListOptions{
+				ResourceVersion: options.ResourceVersion,
+				Limit:           options.Limit,
+				Continue:        options.Continue,
+			})
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return lw.Watch(context.TODO(), metav1.ListOptions{
+				ResourceVersion: options.ResourceVersion,
+			})
+		},
+	}, func(obj runtime.Object) runtime.Object {
+		if route, ok := obj.(*routev1.Route); ok {
+			return hostsForRoute(route)
+		}
+		return obj
+	}, &RouteHosts{})
+	return hostOwnershipIndex{informer: informer}
+}
+
+// RouteHost records one hostname claimed by a route together with the
+// routers that admitted it.
+type RouteHost struct {
+	// Host is the hostname.
+	Host string `json:"host"`
+	// Routers lists the routers that admitted the route for this host.  The
+	// host from the route spec may not have been admitted anywhere yet.
+	Routers []string `json:"routers,omitempty"`
+}
+
+// RouteHosts is the minimal subset of a route retained to answer host
+// ownership queries.
+type RouteHosts struct {
+	reducedinformer.ReducedObjectMeta
+	// Path is the path the route serves under its hosts.
+	Path string
+	// Hosts lists the hostnames the route claims.
+	Hosts []RouteHost
+}
+
+// hostsForRoute reduces a route to its host ownership subset.
+func hostsForRoute(route *routev1.Route) *RouteHosts {
+	entry := &RouteHosts{
+		ReducedObjectMeta: reducedinformer.ReducedObjectMeta{
+			Namespace:       route.Namespace,
+			Name:            route.Name,
+			ResourceVersion: route.ResourceVersion,
+		},
+		Path: route.Spec.Path,
+	}
+	routers := map[string]sets.String{}
+	if len(route.Spec.Host) > 0 {
+		routers[route.Spec.Host] = sets.NewString()
+	}
+	for _, ingress := range route.Status.Ingress {
+		if len(ingress.Host) == 0 {
+			continue
+		}
+		admitted := false
+		for _, condition := range ingress.Conditions {
+			if condition.Type == routev1.RouteAdmitted && condition.Status == "True" {
+				admitted = true
+				break
+			}
+		}
+		if !admitted {
+			continue
+		}
+		if _, ok := routers[ingress.Host]; !ok {
+			routers[ingress.Host] = sets.NewString()
+		}
+		if len(ingress.RouterName) > 0 {
+			routers[ingress.Host].Insert(ingress.RouterName)
+		}
+	}
+	for host, names := range routers {
+		entry.Hosts = append(entry.Hosts, RouteHost{Host: host, Routers: names.List()})
+	}
+	sort.Slice(entry.Hosts, func(i, j int) bool { return entry.Hosts[i].Host < entry.Hosts[j].Host })
+	return entry
+}
+
+var (
+	_ runtime.Object = &RouteHosts{}
+	_ metav1.Object  = &RouteHosts{}
+)
+
+func (h *RouteHosts) DeepCopyObject() runtime.Object {
+	copied := *h
+	if h.Hosts != nil {
+		copied.Hosts = make([]RouteHost, len(h.Hosts))
+		for i := range h.Hosts {
+			copied.Hosts[i] = h.Hosts[i]
+			if h.Hosts[i].Routers != nil {
+				copied.Hosts[i].Routers = make([]string, len(h.Hosts[i].Routers))
+				copy(copied.Hosts[i].Routers, h.Hosts[i].Routers)
+			}
+		}
+	}
+	return &copied
+}
@@ -0,0 +1,133 @@
+package hostindex
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	routeapi "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
+)
+
+// overviewName is the only accepted resource name; the index is a cluster
+// singleton, matching the convention used by cluster configuration resources.
+const overviewName = "cluster"
+
+// HostOwner identifies one route that claims a hostname.
+type HostOwner struct {
+	// Namespace and Name identify the route.
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Path is the path the route serves under the host.
+	Path string `json:"path,omitempty"`
+	// Routers lists the routers that admitted the route for the host; empty
+	// if the claim has not been admitted anywhere yet.
+	Routers []string `json:"routers,omitempty"`
+}
+
+// HostOwnership maps one hostname to the routes that claim it.
+type HostOwnership struct {
+	Host   string      `json:"host"`
+	Routes []HostOwner `json:"routes"`
+}
+
+// HostOverview is the response body of the host ownership endpoint.
+type HostOverview struct {
+	// Hosts maps each hostname claimed by at least one route to its owners.
+	// When the request carries a ?host= query parameter, only that hostname
+	// is returned.
+	Hosts []HostOwnership `json:"hosts"`
+}
+
+// REST implements a computed, cluster-scoped endpoint mapping hostnames to
+// the routes that claim them.  It answers "who owns this hostname" in one
+// call instead of listing routes across every namespace, and is backed by
+// informer data so the call is cheap.
+type REST struct {
+	index HostOwnershipIndex
+}
+
+var _ rest.Connecter = &REST{}
+var _ rest.Scoper = &REST{}
+
+// NewREST returns the host ownership handler backed by index.
+func NewREST(index HostOwnershipIndex) *REST {
+	return &REST{index: index}
+}
+
+// New is only implemented to make REST implement RESTStorage
+func (r *REST) New() runtime.Object {
+	return &routeapi.Route{}
+}
+
+func (r *REST) NamespaceScoped() bool {
+	return false
+}
+
+// ConnectMethods returns GET, the only supported method
+func (r *REST) ConnectMethods() []string {
+	return []string{"GET"}
+}
+
+// NewConnectOptions returns an empty options object; options are read from
+// the query string
+func (r *REST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that resolves hostnames to their owning routes,
+// optionally restricted to the single hostname given in the ?host= query
+// parameter.
+func (r *REST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	if name != overviewName {
+		return nil, kapierrors.NewNotFound(routeapi.Resource("routehostoverviews"), name)
+	}
+	if !r.index.HasSynced() {
+		return nil, kapierrors.NewServiceUnavailable("host ownership cache has not finished an initial synchronization")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		overview := overviewFromIndex(r.index, req.URL.Query().Get("host"))
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(overview); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+// overviewFromIndex aggregates the per-route entries in index, keeping only
+// the given host if one is specified.
+func overviewFromIndex(index HostOwnershipIndex, host string) *HostOverview {
+	owners := make(map[string][]HostOwner)
+	for _, entry := range index.List() {
+		for _, routeHost := range entry.Hosts {
+			if len(host) > 0 && routeHost.Host != host {
+				continue
+			}
+			owners[routeHost.Host] = append(owners[routeHost.Host], HostOwner{
+				Namespace: entry.Namespace,
+				Name:      entry.Name,
+				Path:      entry.Path,
+				Routers:   routeHost.Routers,
+			})
+		}
+	}
+
+	overview := &HostOverview{Hosts: []HostOwnership{}}
+	for host, routes := range owners {
+		sort.Slice(routes, func(i, j int) bool {
+			a, b := routes[i], routes[j]
+			if a.Namespace != b.Namespace {
+				return a.Namespace < b.Namespace
+			}
+			return a.Name < b.Name
+		})
+		overview.Hosts = append(overview.Hosts, HostOwnership{Host: host, Routes: routes})
+	}
+	sort.Slice(overview.Hosts, func(i, j int) bool { return overview.Hosts[i].Host < overview.Hosts[j].Host })
+	return overview
+}
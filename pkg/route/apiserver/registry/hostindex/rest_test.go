@@ -0,0 +1,137 @@
+package hostindex
+
+import (
+	"reflect"
+	"testing"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/openshift/openshift-apiserver/pkg/apiserver/reducedinformer"
+)
+
+type fakeIndex struct {
+	synced  bool
+	entries []*RouteHosts
+}
+
+func (f *fakeIndex) HasSynced() bool            { return f.synced }
+func (f *fakeIndex) List() []*RouteHosts        { return f.entries }
+func (f *fakeIndex) Run(stopCh <-chan struct{}) {}
+
+func TestHostsForRoute(t *testing.T) {
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "frontend"},
+		Spec: routev1.RouteSpec{
+			Host: "www.example.com",
+			Path: "/app",
+		},
+		Status: routev1.RouteStatus{
+			Ingress: []routev1.RouteIngress{
+				{
+					Host:       "www.example.com",
+					RouterName: "default",
+					Conditions: []routev1.RouteIngressCondition{
+						{Type: routev1.RouteAdmitted, Status: "True"},
+					},
+				},
+				{
+					Host:       "www.internal.example.com",
+					RouterName: "internal",
+					Conditions: []routev1.RouteIngressCondition{
+						{Type: routev1.RouteAdmitted, Status: "True"},
+					},
+				},
+				{
+					Host:       "www.rejected.example.com",
+					RouterName: "strict",
+					Conditions: []routev1.RouteIngressCondition{
+						{Type: routev1.RouteAdmitted, Status: "False"},
+					},
+				},
+			},
+		},
+	}
+
+	entry := hostsForRoute(route)
+	if entry.Namespace != "default" || entry.Name != "frontend" || entry.Path != "/app" {
+		t.Errorf("unexpected entry identity: %#v", entry)
+	}
+	expected := []RouteHost{
+		{Host: "www.example.com", Routers: []string{"default"}},
+		{Host: "www.internal.example.com", Routers: []string{"internal"}},
+	}
+	if !reflect.DeepEqual(entry.Hosts, expected) {
+		t.Errorf("expected hosts %#v, got %#v", expected, entry.Hosts)
+	}
+}
+
+func TestHostsForRouteWithoutAdmission(t *testing.T) {
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pending"},
+		Spec:       routev1.RouteSpec{Host: "pending.example.com"},
+	}
+
+	entry := hostsForRoute(route)
+	expected := []RouteHost{{Host: "pending.example.com", Routers: []string{}}}
+	if !reflect.DeepEqual(entry.Hosts, expected) {
+		t.Errorf("expected hosts %#v, got %#v", expected, entry.Hosts)
+	}
+}
+
+func TestOverviewFromIndex(t *testing.T) {
+	index := &fakeIndex{entries: []*RouteHosts{
+		{
+			ReducedObjectMeta: reducedinformer.ReducedObjectMeta{Namespace: "b", Name: "frontend"},
+			Hosts:             []RouteHost{{Host: "www.example.com", Routers: []string{"default"}}},
+		},
+		{
+			ReducedObjectMeta: reducedinformer.ReducedObjectMeta{Namespace: "a", Name: "squatter"},
+			Path:              "/other",
+			Hosts:             []RouteHost{{Host: "www.example.com"}},
+		},
+		{
+			ReducedObjectMeta: reducedinformer.ReducedObjectMeta{Namespace: "c", Name: "api"},
+			Hosts:             []RouteHost{{Host: "api.example.com", Routers: []string{"default"}}},
+		},
+	}}
+
+	overview := overviewFromIndex(index, "")
+	if len(overview.Hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %#v", overview.Hosts)
+	}
+	if overview.Hosts[0].Host != "api.example.com" {
+		t.Errorf("expected hosts sorted by name, got %#v", overview.Hosts)
+	}
+	expected := []HostOwner{
+		{Namespace: "a", Name: "squatter", Path: "/other"},
+		{Namespace: "b", Name: "frontend", Routers: []string{"default"}},
+	}
+	if !reflect.DeepEqual(overview.Hosts[1].Routes, expected) {
+		t.Errorf("expected owners %#v, got %#v", expected, overview.Hosts[1].Routes)
+	}
+
+	filtered := overviewFromIndex(index, "api.example.com")
+	if len(filtered.Hosts) != 1 || filtered.Hosts[0].Host != "api.example.com" {
+		t.Errorf("expected only the requested host, got %#v", filtered.Hosts)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	ctx := apirequest.NewContext()
+
+	storage := NewREST(&fakeIndex{synced: false})
+	if _, err := storage.Connect(ctx, "cluster", nil, nil); !kapierrors.IsServiceUnavailable(err) {
+		t.Errorf("expected service unavailable before sync, got %v", err)
+	}
+
+	storage = NewREST(&fakeIndex{synced: true})
+	if _, err := storage.Connect(ctx, "other", nil, nil); !kapierrors.IsNotFound(err) {
+		t.Errorf("expected not found for names other than cluster, got %v", err)
+	}
+	if _, err := storage.Connect(ctx, "cluster", nil, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	routegroup "github.com/openshift/api/route"
 
 	routeapi "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
+	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/hostclaimpolicy"
 	routeregistry "github.com/openshift/openshift-apiserver/pkg/route/apiserver/registry/route"
 	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/routeinterfaces"
 	routeprinters "github.com/openshift/openshift-apiserver/pkg/route/printers/internalversion"
@@ -34,8 +35,8 @@ func (r *REST) Categories() []string {
 }
 
 // NewREST returns a RESTStorage object that will work against routes.
-func NewREST(optsGetter generic.RESTOptionsGetter, allocator routeinterfaces.RouteAllocator, sarClient routeregistry.SubjectAccessReviewInterface) (*REST, *StatusREST, error) {
-	strategy := routeregistry.NewStrategy(allocator, sarClient)
+func NewREST(optsGetter generic.RESTOptionsGetter, allocator routeinterfaces.RouteAllocator, sarClient routeregistry.SubjectAccessReviewInterface, secretGetter routeregistry.SecretGetterInterface, hostClaimPolicy *hostclaimpolicy.Evaluator) (*REST, *StatusREST, error) {
+	strategy := routeregistry.NewStrategyWithHostClaimPolicy(allocator, sarClient, secretGetter, hostClaimPolicy)
 
 	store := &registry.Store{
 		NewFunc:                  func() runtime.Object { return &routeapi.Route{} },
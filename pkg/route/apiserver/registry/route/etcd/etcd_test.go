@@ -58,7 +58,7 @@ func newStorage(t *testing.T, allocator routeinterfaces.RouteAllocator) (*REST,
 	server, etcdStorage := etcdtesting.NewUnsecuredEtcd3TestClientServer(t)
 	etcdStorage.Codec = legacyscheme.Codecs.LegacyCodec(schema.GroupVersion{Group: "route.openshift.io", Version: "v1"})
 	restOptions := generic.RESTOptions{StorageConfig: etcdStorage, Decorator: generic.UndecoratedStorage, DeleteCollectionWorkers: 1, ResourcePrefix: "routes"}
-	storage, _, err := NewREST(restOptions, allocator, &testSAR{allow: true})
+	storage, _, err := NewREST(restOptions, allocator, &testSAR{allow: true}, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
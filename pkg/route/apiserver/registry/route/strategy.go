@@ -5,18 +5,23 @@ import (
 	"fmt"
 
 	authorizationapi "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/authentication/user"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/storage/names"
 	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
 	kvalidation "k8s.io/kubernetes/pkg/apis/core/validation"
 
 	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
 	routeapi "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
 	"github.com/openshift/openshift-apiserver/pkg/route/apis/route/validation"
+	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/hostclaimpolicy"
 	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/routeinterfaces"
 )
 
@@ -30,21 +35,54 @@ type SubjectAccessReviewInterface interface {
 
 var _ SubjectAccessReviewInterface = authorizationclient.SubjectAccessReviewInterface(nil)
 
+// SecretGetterInterface is the subset of the core client used to resolve
+// externalCertificate references, narrowed so tests can supply a fake.
+type SecretGetterInterface interface {
+	GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error)
+}
+
+// clientSecretGetter adapts a typed core client to SecretGetterInterface.
+type clientSecretGetter struct {
+	client corev1client.SecretsGetter
+}
+
+// NewClientSecretGetter returns a SecretGetterInterface backed by the given
+// core client.
+func NewClientSecretGetter(client corev1client.SecretsGetter) SecretGetterInterface {
+	return clientSecretGetter{client: client}
+}
+
+func (g clientSecretGetter) GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	return g.client.Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
 type routeStrategy struct {
 	runtime.ObjectTyper
 	names.NameGenerator
 	routeinterfaces.RouteAllocator
-	sarClient SubjectAccessReviewInterface
+	sarClient       SubjectAccessReviewInterface
+	secretGetter    SecretGetterInterface
+	hostClaimPolicy *hostclaimpolicy.Evaluator
 }
 
 // NewStrategy initializes the default logic that applies when creating and updating
 // Route objects via the REST API.
 func NewStrategy(allocator routeinterfaces.RouteAllocator, sarClient SubjectAccessReviewInterface) routeStrategy {
+	return NewStrategyWithHostClaimPolicy(allocator, sarClient, nil, nil)
+}
+
+// NewStrategyWithHostClaimPolicy additionally verifies externalCertificate
+// references against the given secret getter and consults an admin-supplied
+// policy before the custom-host subject access review.  A nil policy keeps
+// the default behavior.
+func NewStrategyWithHostClaimPolicy(allocator routeinterfaces.RouteAllocator, sarClient SubjectAccessReviewInterface, secretGetter SecretGetterInterface, hostClaimPolicy *hostclaimpolicy.Evaluator) routeStrategy {
 	return routeStrategy{
-		ObjectTyper:    legacyscheme.Scheme,
-		NameGenerator:  names.SimpleNameGenerator,
-		RouteAllocator: allocator,
-		sarClient:      sarClient,
+		ObjectTyper:     legacyscheme.Scheme,
+		NameGenerator:   names.SimpleNameGenerator,
+		RouteAllocator:  allocator,
+		sarClient:       sarClient,
+		secretGetter:    secretGetter,
+		hostClaimPolicy: hostClaimPolicy,
 	}
 }
 
@@ -56,6 +94,7 @@ func (s routeStrategy) PrepareForCreate(ctx context.Context, obj runtime.Object)
 	route := obj.(*routeapi.Route)
 	route.Status = routeapi.RouteStatus{}
 	stripEmptyDestinationCACertificate(route)
+	warnOnDeprecatedTLSConfig(ctx, route)
 }
 
 func (s routeStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
@@ -69,6 +108,7 @@ func (s routeStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Ob
 	if len(route.Spec.Host) == 0 {
 		route.Spec.Host = oldRoute.Spec.Host
 	}
+	warnOnDeprecatedTLSConfig(ctx, route)
 }
 
 // allocateHost allocates a host name ONLY if the route doesn't specify a subdomain wildcard policy and
@@ -76,38 +116,47 @@ func (s routeStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Ob
 // It must first allocate the shard and may return an error if shard allocation fails.
 func (s routeStrategy) allocateHost(ctx context.Context, route *routeapi.Route) field.ErrorList {
 	hostSet := len(route.Spec.Host) > 0
-	certSet := route.Spec.TLS != nil && (len(route.Spec.TLS.CACertificate) > 0 || len(route.Spec.TLS.Certificate) > 0 || len(route.Spec.TLS.DestinationCACertificate) > 0 || len(route.Spec.TLS.Key) > 0)
+	certSet := hasCertificateInfo(route.Spec.TLS)
 	if hostSet || certSet {
 		user, ok := apirequest.UserFrom(ctx)
 		if !ok {
 			return field.ErrorList{field.InternalError(field.NewPath("spec", "host"), fmt.Errorf("unable to verify host field can be set"))}
 		}
-		res, err := s.sarClient.Create(
-			ctx,
-			authorizationutil.AddUserToSAR(
-				user,
-				&authorizationapi.SubjectAccessReview{
-					Spec: authorizationapi.SubjectAccessReviewSpec{
-						ResourceAttributes: &authorizationapi.ResourceAttributes{
-							Namespace:   apirequest.NamespaceValue(ctx),
-							Verb:        "create",
-							Group:       routeapi.GroupName,
-							Resource:    "routes",
-							Subresource: "custom-host",
+		decision, ruleName, matched := s.evaluateHostClaimPolicy(user, apirequest.NamespaceValue(ctx), route.Spec.Host, certSet)
+		if matched && decision == hostclaimpolicy.Deny {
+			if hostSet {
+				return field.ErrorList{field.Forbidden(field.NewPath("spec", "host"), fmt.Sprintf("setting the host field of the route is denied by policy rule %q", ruleName))}
+			}
+			return field.ErrorList{field.Forbidden(field.NewPath("spec", "tls"), fmt.Sprintf("setting certificate fields on the route is denied by policy rule %q", ruleName))}
+		}
+		if !matched {
+			res, err := s.sarClient.Create(
+				ctx,
+				authorizationutil.AddUserToSAR(
+					user,
+					&authorizationapi.SubjectAccessReview{
+						Spec: authorizationapi.SubjectAccessReviewSpec{
+							ResourceAttributes: &authorizationapi.ResourceAttributes{
+								Namespace:   apirequest.NamespaceValue(ctx),
+								Verb:        "create",
+								Group:       routeapi.GroupName,
+								Resource:    "routes",
+								Subresource: "custom-host",
+							},
 						},
 					},
-				},
-			),
-			metav1.CreateOptions{},
-		)
-		if err != nil {
-			return field.ErrorList{field.InternalError(field.NewPath("spec", "host"), err)}
-		}
-		if !res.Status.Allowed {
-			if hostSet {
-				return field.ErrorList{field.Forbidden(field.NewPath("spec", "host"), "you do not have permission to set the host field of the route")}
+				),
+				metav1.CreateOptions{},
+			)
+			if err != nil {
+				return field.ErrorList{field.InternalError(field.NewPath("spec", "host"), err)}
+			}
+			if !res.Status.Allowed {
+				if hostSet {
+					return field.ErrorList{field.Forbidden(field.NewPath("spec", "host"), "you do not have permission to set the host field of the route")}
+				}
+				return field.ErrorList{field.Forbidden(field.NewPath("spec", "tls"), "you do not have permission to set certificate fields on the route")}
 			}
-			return field.ErrorList{field.Forbidden(field.NewPath("spec", "tls"), "you do not have permission to set certificate fields on the route")}
 		}
 	}
 
@@ -131,9 +180,20 @@ func (s routeStrategy) allocateHost(ctx context.Context, route *routeapi.Route)
 	return nil
 }
 
+// evaluateHostClaimPolicy consults the admin-supplied host claim policy when
+// one is configured; unmatched requests fall back to the subject access
+// review checks.
+func (s routeStrategy) evaluateHostClaimPolicy(u user.Info, namespace, host string, certificateChange bool) (hostclaimpolicy.Decision, string, bool) {
+	if s.hostClaimPolicy == nil {
+		return "", "", false
+	}
+	return s.hostClaimPolicy.Evaluate(u, namespace, host, certificateChange)
+}
+
 func (s routeStrategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
 	route := obj.(*routeapi.Route)
 	errs := s.allocateHost(ctx, route)
+	errs = append(errs, s.verifyExternalCertificate(ctx, route, nil)...)
 	errs = append(errs, validation.ValidateRoute(route)...)
 	return errs
 }
@@ -150,10 +210,77 @@ func (s routeStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Obje
 	oldRoute := old.(*routeapi.Route)
 	objRoute := obj.(*routeapi.Route)
 	errs := s.validateHostUpdate(ctx, objRoute, oldRoute)
+	errs = append(errs, s.verifyExternalCertificate(ctx, objRoute, oldRoute)...)
 	errs = append(errs, validation.ValidateRouteUpdate(objRoute, oldRoute)...)
 	return errs
 }
 
+// externalCertificateName returns the name of the secret the route's
+// externalCertificate references, if any.
+func externalCertificateName(route *routeapi.Route) string {
+	if route.Spec.TLS == nil || route.Spec.TLS.ExternalCertificate == nil {
+		return ""
+	}
+	return route.Spec.TLS.ExternalCertificate.Name
+}
+
+// verifyExternalCertificate checks that the requester may read the secret an
+// externalCertificate references and that the secret holds a TLS key pair.
+// On update the checks only run when the reference changes, so an existing
+// route does not break when its original requester later loses access.
+func (s routeStrategy) verifyExternalCertificate(ctx context.Context, route, older *routeapi.Route) field.ErrorList {
+	name := externalCertificateName(route)
+	if len(name) == 0 {
+		return nil
+	}
+	if older != nil && name == externalCertificateName(older) {
+		return nil
+	}
+	fldPath := field.NewPath("spec", "tls", "externalCertificate")
+	user, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return field.ErrorList{field.InternalError(fldPath, fmt.Errorf("unable to verify access to the referenced secret"))}
+	}
+	namespace := apirequest.NamespaceValue(ctx)
+	res, err := s.sarClient.Create(
+		ctx,
+		authorizationutil.AddUserToSAR(
+			user,
+			&authorizationapi.SubjectAccessReview{
+				Spec: authorizationapi.SubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationapi.ResourceAttributes{
+						Namespace: namespace,
+						Verb:      "get",
+						Resource:  "secrets",
+						Name:      name,
+					},
+				},
+			},
+		),
+		metav1.CreateOptions{},
+	)
+	if err != nil {
+		return field.ErrorList{field.InternalError(fldPath, err)}
+	}
+	if !res.Status.Allowed {
+		return field.ErrorList{field.Forbidden(fldPath, fmt.Sprintf("you do not have permission to get the secret %q in the route's namespace", name))}
+	}
+	if s.secretGetter == nil {
+		return field.ErrorList{field.InternalError(fldPath, fmt.Errorf("unable to verify the referenced secret"))}
+	}
+	secret, err := s.secretGetter.GetSecret(ctx, namespace, name)
+	if err != nil {
+		if kapierrors.IsNotFound(err) {
+			return field.ErrorList{field.NotFound(fldPath, name)}
+		}
+		return field.ErrorList{field.InternalError(fldPath, err)}
+	}
+	if secret.Type != corev1.SecretTypeTLS {
+		return field.ErrorList{field.Invalid(fldPath, name, fmt.Sprintf("secret of type %q is not allowed, must be of type %q", secret.Type, corev1.SecretTypeTLS))}
+	}
+	return nil
+}
+
 func hasCertificateInfo(tls *routeapi.TLSConfig) bool {
 	if tls == nil {
 		return false
@@ -161,7 +288,8 @@ func hasCertificateInfo(tls *routeapi.TLSConfig) bool {
 	return len(tls.Certificate) > 0 ||
 		len(tls.Key) > 0 ||
 		len(tls.CACertificate) > 0 ||
-		len(tls.DestinationCACertificate) > 0
+		len(tls.DestinationCACertificate) > 0 ||
+		tls.ExternalCertificate != nil
 }
 
 func certificateChangeRequiresAuth(route, older *routeapi.Route) bool {
@@ -175,7 +303,8 @@ func certificateChangeRequiresAuth(route, older *routeapi.Route) bool {
 		return a.CACertificate != b.CACertificate ||
 			a.Certificate != b.Certificate ||
 			a.DestinationCACertificate != b.DestinationCACertificate ||
-			a.Key != b.Key
+			a.Key != b.Key ||
+			externalCertificateName(route) != externalCertificateName(older)
 	case route.Spec.TLS != nil:
 		// using any default certificate is allowed
 		return hasCertificateInfo(route.Spec.TLS)
@@ -195,6 +324,16 @@ func (s routeStrategy) validateHostUpdate(ctx context.Context, route, older *rou
 	if !ok {
 		return field.ErrorList{field.InternalError(field.NewPath("spec", "host"), fmt.Errorf("unable to verify host field can be changed"))}
 	}
+	decision, ruleName, matched := s.evaluateHostClaimPolicy(user, apirequest.NamespaceValue(ctx), route.Spec.Host, certChanged)
+	if matched {
+		if decision == hostclaimpolicy.Allow {
+			return nil
+		}
+		if hostChanged {
+			return field.ErrorList{field.Forbidden(field.NewPath("spec", "host"), fmt.Sprintf("changing the host field of the route is denied by policy rule %q", ruleName))}
+		}
+		return field.ErrorList{field.Forbidden(field.NewPath("spec", "tls"), fmt.Sprintf("changing certificate fields on the route is denied by policy rule %q", ruleName))}
+	}
 	res, err := s.sarClient.Create(
 		ctx,
 		authorizationutil.AddUserToSAR(
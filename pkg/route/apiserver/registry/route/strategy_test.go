@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	authorizationapi "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -13,6 +15,7 @@ import (
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 
 	routeapi "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
+	"github.com/openshift/openshift-apiserver/pkg/route/apiserver/hostclaimpolicy"
 )
 
 type testAllocator struct {
@@ -40,6 +43,17 @@ func (t *testSAR) Create(_ context.Context, subjectAccessReview *authorizationap
 	}, t.err
 }
 
+type testSecretGetter struct {
+	secret *corev1.Secret
+}
+
+func (t *testSecretGetter) GetSecret(_ context.Context, namespace, name string) (*corev1.Secret, error) {
+	if t.secret == nil || t.secret.Namespace != namespace || t.secret.Name != name {
+		return nil, kapierrors.NewNotFound(corev1.Resource("secrets"), name)
+	}
+	return t.secret, nil
+}
+
 func TestEmptyHostDefaulting(t *testing.T) {
 	ctx := apirequest.NewContext()
 	strategy := NewStrategy(testAllocator{}, &testSAR{allow: true})
@@ -432,3 +446,168 @@ func TestHostWithWildcardPolicies(t *testing.T) {
 		}
 	}
 }
+
+func TestExternalCertificateVerification(t *testing.T) {
+	tlsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "frontend-tls"},
+		Type:       corev1.SecretTypeTLS,
+	}
+	opaqueSecret := tlsSecret.DeepCopy()
+	opaqueSecret.Type = corev1.SecretTypeOpaque
+
+	tests := []struct {
+		name   string
+		secret *corev1.Secret
+		allow  bool
+		oldRef string
+		errs   int
+	}{
+		{
+			name:   "allowed with a tls secret",
+			secret: tlsSecret,
+			allow:  true,
+			errs:   0,
+		},
+		{
+			// the custom-host check and the secret check each reject
+			name:   "denied by the subject access review",
+			secret: tlsSecret,
+			allow:  false,
+			errs:   2,
+		},
+		{
+			name:  "missing secret",
+			allow: true,
+			errs:  1,
+		},
+		{
+			name:   "secret of the wrong type",
+			secret: opaqueSecret,
+			allow:  true,
+			errs:   1,
+		},
+		{
+			name:   "unchanged reference skips the checks",
+			allow:  false,
+			oldRef: "frontend-tls",
+			errs:   0,
+		},
+	}
+
+	for _, tc := range tests {
+		ctx := apirequest.WithUser(apirequest.NewDefaultContext(), &user.DefaultInfo{Name: "bob"})
+		strategy := NewStrategyWithHostClaimPolicy(testAllocator{}, &testSAR{allow: tc.allow}, &testSecretGetter{secret: tc.secret}, nil)
+
+		route := &routeapi.Route{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "route", ResourceVersion: "1"},
+			Spec: routeapi.RouteSpec{
+				Host: "www.example.com",
+				TLS: &routeapi.TLSConfig{
+					Termination:         routeapi.TLSTerminationEdge,
+					ExternalCertificate: &routeapi.LocalObjectReference{Name: "frontend-tls"},
+				},
+				To: routeapi.RouteTargetReference{
+					Name: "test",
+					Kind: "Service",
+				},
+			},
+		}
+
+		var errs field.ErrorList
+		if len(tc.oldRef) > 0 {
+			older := route.DeepCopy()
+			older.Spec.TLS.ExternalCertificate = &routeapi.LocalObjectReference{Name: tc.oldRef}
+			errs = strategy.ValidateUpdate(ctx, route, older)
+		} else {
+			errs = strategy.Validate(ctx, route)
+		}
+
+		if len(errs) != tc.errs {
+			t.Errorf("%s: expected %d errors, got %v", tc.name, tc.errs, errs)
+		}
+	}
+}
+
+func TestHostClaimPolicy(t *testing.T) {
+	evaluator, err := hostclaimpolicy.NewEvaluator(&hostclaimpolicy.Policy{Rules: []hostclaimpolicy.Rule{
+		{Name: "infra-for-admins", Decision: hostclaimpolicy.Allow, Groups: []string{"admins"}, Hosts: []string{"*.infra.example.com"}},
+		{Name: "no-infra", Decision: hostclaimpolicy.Deny, Hosts: []string{"*.infra.example.com"}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		user      *user.DefaultInfo
+		host      string
+		oldHost   string
+		sarAllow  bool
+		errs      int
+		expectSAR bool
+	}{
+		{
+			name:     "policy allows without a SAR",
+			user:     &user.DefaultInfo{Name: "alice", Groups: []string{"admins"}},
+			host:     "console.infra.example.com",
+			sarAllow: false,
+			errs:     0,
+		},
+		{
+			name:     "policy denies even when the SAR would allow",
+			user:     &user.DefaultInfo{Name: "bob"},
+			host:     "console.infra.example.com",
+			sarAllow: true,
+			errs:     1,
+		},
+		{
+			name:      "unmatched hosts fall back to the SAR",
+			user:      &user.DefaultInfo{Name: "bob"},
+			host:      "app.example.com",
+			sarAllow:  true,
+			errs:      0,
+			expectSAR: true,
+		},
+		{
+			name:     "policy denies host changes on update",
+			user:     &user.DefaultInfo{Name: "bob"},
+			host:     "console.infra.example.com",
+			oldHost:  "app.example.com",
+			sarAllow: true,
+			errs:     1,
+		},
+	}
+
+	for _, tc := range tests {
+		ctx := apirequest.WithUser(apirequest.NewDefaultContext(), tc.user)
+		sar := &testSAR{allow: tc.sarAllow}
+		strategy := NewStrategyWithHostClaimPolicy(testAllocator{}, sar, nil, evaluator)
+
+		route := &routeapi.Route{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "route", ResourceVersion: "1"},
+			Spec: routeapi.RouteSpec{
+				Host: tc.host,
+				To: routeapi.RouteTargetReference{
+					Name: "test",
+					Kind: "Service",
+				},
+			},
+		}
+
+		var errs field.ErrorList
+		if len(tc.oldHost) > 0 {
+			oldRoute := route.DeepCopy()
+			oldRoute.Spec.Host = tc.oldHost
+			errs = strategy.ValidateUpdate(ctx, route, oldRoute)
+		} else {
+			errs = strategy.Validate(ctx, route)
+		}
+
+		if len(errs) != tc.errs {
+			t.Errorf("%s: expected %d errors, got %v", tc.name, tc.errs, errs)
+		}
+		if tc.expectSAR != (sar.sar != nil) {
+			t.Errorf("%s: expected SAR consulted %t, got %#v", tc.name, tc.expectSAR, sar.sar)
+		}
+	}
+}
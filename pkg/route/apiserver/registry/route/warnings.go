@@ -0,0 +1,72 @@
+package route
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"k8s.io/apiserver/pkg/warning"
+
+	routeapi "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
+)
+
+// warnOnDeprecatedTLSConfig records API warnings for TLS configurations that
+// the router still accepts but that are deprecated or likely misconfigured.
+// The settings pass validation today, so without a warning the first feedback
+// a user gets is a silent router failure.
+func warnOnDeprecatedTLSConfig(ctx context.Context, route *routeapi.Route) {
+	tls := route.Spec.TLS
+	if tls == nil {
+		return
+	}
+
+	if tls.InsecureEdgeTerminationPolicy == routeapi.InsecureEdgeTerminationPolicyAllow {
+		warning.AddWarning(ctx, "", "spec.tls.insecureEdgeTerminationPolicy: Allow exposes this route over plain HTTP in addition to TLS; use Redirect to send insecure traffic to the TLS port")
+	}
+
+	if tls.Termination == routeapi.TLSTerminationReencrypt && len(tls.DestinationCACertificate) == 0 {
+		warning.AddWarning(ctx, "", "spec.tls.destinationCACertificate: reencrypt routes without a destination CA certificate rely on the cluster default trust bundle to verify the backend; traffic is dropped if the backend certificate was not issued by it")
+	}
+
+	for _, bundle := range []struct {
+		field string
+		value string
+	}{
+		{field: "spec.tls.certificate", value: tls.Certificate},
+		{field: "spec.tls.caCertificate", value: tls.CACertificate},
+		{field: "spec.tls.destinationCACertificate", value: tls.DestinationCACertificate},
+	} {
+		field, value := bundle.field, bundle.value
+		for _, algorithm := range deprecatedSignatureAlgorithms(value) {
+			warning.AddWarning(ctx, "", fmt.Sprintf("%s: contains a certificate signed with the deprecated %v algorithm; clients increasingly refuse SHA1 signatures, reissue the certificate with SHA256 or stronger", field, algorithm))
+		}
+	}
+}
+
+// deprecatedSignatureAlgorithms returns the deprecated signature algorithms
+// used by the certificates in the given PEM bundle.  Undecodable blocks are
+// ignored; validation reports those separately.
+func deprecatedSignatureAlgorithms(bundle string) []x509.SignatureAlgorithm {
+	var algorithms []x509.SignatureAlgorithm
+	data := []byte(bundle)
+	for len(data) > 0 {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		switch certificate.SignatureAlgorithm {
+		case x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1, x509.MD5WithRSA:
+			algorithms = append(algorithms, certificate.SignatureAlgorithm)
+		}
+	}
+	return algorithms
+}
@@ -0,0 +1,106 @@
+package route
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apiserver/pkg/warning"
+
+	routeapi "github.com/openshift/openshift-apiserver/pkg/route/apis/route"
+)
+
+// sha1Certificate is a self-signed certificate using sha1WithRSAEncryption,
+// kept static because modern crypto libraries refuse to create new ones.
+const sha1Certificate = `-----BEGIN CERTIFICATE-----
+MIIDGTCCAgGgAwIBAgIUNN1T2vSFgKsI/E89T4UrGefV4vswDQYJKoZIhvcNAQEF
+BQAwGzEZMBcGA1UEAwwQc2hhMS5leGFtcGxlLmNvbTAgFw0yNjA4MjcwNDE0NDda
+GA8yMTI2MDgwMzA0MTQ0N1owGzEZMBcGA1UEAwwQc2hhMS5leGFtcGxlLmNvbTCC
+ASIwDQYJKoZIhvcNAQEBBQADggEPADCCAQoCggEBAMXtyeFQpnJ2Ku9DjXm/RX4A
+XQJiG4b4lao1rgBLBRwZstKndgVO8vYG60UMkPWweStpS6pXfVCrURT6X1sba2Ks
+Zo5764XO7ya5EsOb7mFNO+7rBkZFOUui6yfEt8naFSeN8QdEuMK0u8DVKbRkMIbL
+vQrWZVpw0niyIIiD3U6k9sZZQLIb9AevuS4tIlDdsjbSgHj9Mk1DgcUTq/emv2XX
+RwbFexWC5+yM7ltBujerjMZGSNidNwcBF/eapnt9+d/KBS6wntlhOW6sCrTijo4B
+IUKtnW8aRWdOKkxf/ZIv9v8yv0o7hXGY8LxXLxKKiIwxXM0rRtVN9LmW3ktDcI0C
+AwEAAaNTMFEwHQYDVR0OBBYEFDsS6jR4zcGFP+VB94sFvcwimrbFMB8GA1UdIwQY
+MBaAFDsS6jR4zcGFP+VB94sFvcwimrbFMA8GA1UdEwEB/wQFMAMBAf8wDQYJKoZI
+hvcNAQEFBQADggEBAH2L8nnjobksn8Md8Ddm+TwiErR8azFQ9WevA/08TQ6Ipuka
+XWsxia8oPshRVTjlht5mK6LnZYZPz4+b4K+kBdlwgbHWRlkqAAXgTrrWyFBfj1eZ
+A2TZl6+VT+xsHWSau9F8FStAr5mrg3Kp3hLNvKO1JbyP+7ulelbdqtEPAuLe1kU1
+0lp/rWZB+0EqiuM94RWKI3mnsTZwNM3XNETB/pI4k2DweGETa7S+TS5CHYxgv9fe
+trQmQ12LAqv7/sdKHmHNTYIQf9gMRsLwjT3ePtNYezNEDle8ZoKvQYtYrjV4DVWn
+cRD0+opbeaX99WSlVBJaIVHqvLb4fHFbqahbkRM=
+-----END CERTIFICATE-----`
+
+func TestWarnOnDeprecatedTLSConfig(t *testing.T) {
+	tests := []struct {
+		name             string
+		tls              *routeapi.TLSConfig
+		expectedWarnings []string
+	}{
+		{
+			name: "no tls",
+		},
+		{
+			name: "modern edge route",
+			tls:  &routeapi.TLSConfig{Termination: routeapi.TLSTerminationEdge, InsecureEdgeTerminationPolicy: routeapi.InsecureEdgeTerminationPolicyRedirect},
+		},
+		{
+			name:             "insecure allow",
+			tls:              &routeapi.TLSConfig{Termination: routeapi.TLSTerminationEdge, InsecureEdgeTerminationPolicy: routeapi.InsecureEdgeTerminationPolicyAllow},
+			expectedWarnings: []string{"insecureEdgeTerminationPolicy"},
+		},
+		{
+			name:             "reencrypt without destination CA",
+			tls:              &routeapi.TLSConfig{Termination: routeapi.TLSTerminationReencrypt},
+			expectedWarnings: []string{"destinationCACertificate"},
+		},
+		{
+			name:             "sha1 certificate",
+			tls:              &routeapi.TLSConfig{Termination: routeapi.TLSTerminationEdge, Certificate: sha1Certificate},
+			expectedWarnings: []string{"SHA1-RSA"},
+		},
+		{
+			name: "multiple problems",
+			tls: &routeapi.TLSConfig{
+				Termination:                   routeapi.TLSTerminationReencrypt,
+				InsecureEdgeTerminationPolicy: routeapi.InsecureEdgeTerminationPolicyAllow,
+				Certificate:                   sha1Certificate,
+			},
+			expectedWarnings: []string{"insecureEdgeTerminationPolicy", "destinationCACertificate", "SHA1-RSA"},
+		},
+	}
+
+	for _, test := range tests {
+		recorder := &recordingWarningRecorder{}
+		ctx := warning.WithWarningRecorder(context.Background(), recorder)
+		route := &routeapi.Route{Spec: routeapi.RouteSpec{TLS: test.tls}}
+
+		warnOnDeprecatedTLSConfig(ctx, route)
+
+		if len(recorder.warnings) != len(test.expectedWarnings) {
+			t.Errorf("%s: expected %d warnings, got %v", test.name, len(test.expectedWarnings), recorder.warnings)
+			continue
+		}
+		for i, fragment := range test.expectedWarnings {
+			found := false
+			for _, text := range recorder.warnings {
+				if strings.Contains(text, fragment) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("%s: expected warning %d to mention %q, got %v", test.name, i, fragment, recorder.warnings)
+			}
+		}
+	}
+}
+
+type recordingWarningRecorder struct {
+	warnings []string
+}
+
+func (r *recordingWarningRecorder) AddWarning(agent, text string) {
+	r.warnings = append(r.warnings, text)
+}
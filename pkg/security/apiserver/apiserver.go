@@ -129,5 +129,6 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 	v1Storage["podSecurityPolicySubjectReviews"] = podSecurityPolicySubjectStorage
 	v1Storage["podSecurityPolicySelfSubjectReviews"] = podSecurityPolicySelfSubjectReviewStorage
 	v1Storage["rangeallocations"] = uidRangeStorage
+	v1Storage["rangeallocations/repair"] = rangeallocations.NewRepairREST(uidRangeStorage, kubeClient.CoreV1())
 	return v1Storage, nil
 }
@@ -0,0 +1,172 @@
+package rangeallocations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	"github.com/openshift/library-go/pkg/security/uid"
+
+	securityapi "github.com/openshift/openshift-apiserver/pkg/security/apis/security"
+)
+
+// RepairResult is the response body of the repair subresource.
+type RepairResult struct {
+	// Name is the range allocation acted on.
+	Name string `json:"name"`
+	// Range is the UID range of the allocation.
+	Range string `json:"range"`
+	// Blocks is the total number of blocks in the range.
+	Blocks uint32 `json:"blocks"`
+	// AllocatedBlocks is the number of blocks the rebuilt bitmap marks
+	// allocated, one per namespace with a valid in-range annotation.
+	AllocatedBlocks int `json:"allocatedBlocks"`
+	// InvalidAnnotations lists namespaces whose UID range annotation could
+	// not be parsed or does not fall on a block boundary of the range. Their
+	// blocks are not marked allocated.
+	InvalidAnnotations []string `json:"invalidAnnotations,omitempty"`
+	// Updated is false for dry-run requests, which compute the bitmap
+	// without persisting it.
+	Updated bool `json:"updated"`
+}
+
+// RepairREST rebuilds the allocation bitmap of a RangeAllocation from the
+// UID range annotations of existing namespaces. The stored bitmap can drift
+// from reality, for example after a restore from backup, leaving blocks
+// marked allocated that no namespace uses; this subresource recomputes it
+// without requiring direct etcd edits. Access is granted by allowing create
+// on the rangeallocations/repair subresource, which keeps the operation
+// admin-only.
+type RepairREST struct {
+	store      *REST
+	namespaces corev1client.NamespacesGetter
+}
+
+var _ rest.Connecter = &RepairREST{}
+var _ rest.Scoper = &RepairREST{}
+
+// NewRepairREST returns the handler for the rangeallocations/{name}/repair
+// subresource.
+func NewRepairREST(store *REST, namespaces corev1client.NamespacesGetter) *RepairREST {
+	return &RepairREST{store: store, namespaces: namespaces}
+}
+
+// New is only implemented to make RepairREST implement RESTStorage
+func (r *RepairREST) New() runtime.Object {
+	return &securityapi.RangeAllocation{}
+}
+
+func (r *RepairREST) NamespaceScoped() bool {
+	return false
+}
+
+// ConnectMethods returns POST, the only supported method
+func (r *RepairREST) ConnectMethods() []string {
+	return []string{"POST"}
+}
+
+// NewConnectOptions returns an empty options object; options are read from
+// the query string
+func (r *RepairREST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+// Connect returns a handler that rebuilds the named range allocation. With
+// ?dryRun=true the rebuilt bitmap is reported but not persisted.
+func (r *RepairREST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		result, err := r.repair(ctx, name, req.URL.Query().Get("dryRun") == "true")
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			responder.Error(err)
+		}
+	}), nil
+}
+
+func (r *RepairREST) repair(ctx context.Context, name string, dryRun bool) (*RepairResult, error) {
+	obj, err := r.store.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	allocation := obj.(*securityapi.RangeAllocation)
+
+	parsed, err := uid.ParseRange(allocation.Range)
+	if err != nil {
+		return nil, kapierrors.NewBadRequest(fmt.Sprintf("range allocation %s has an invalid range %q: %v", name, allocation.Range, err))
+	}
+
+	namespaces, err := r.namespaces.Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap, allocated, invalid := rebuildAllocation(parsed, namespaces.Items)
+
+	result := &RepairResult{
+		Name:               name,
+		Range:              allocation.Range,
+		Blocks:             parsed.Size(),
+		AllocatedBlocks:    allocated,
+		InvalidAnnotations: invalid,
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	allocation = allocation.DeepCopy()
+	allocation.Data = bitmap.Bytes()
+	if _, _, err := r.store.Update(ctx, name, rest.DefaultUpdatedObjectInfo(allocation), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, false, &metav1.UpdateOptions{}); err != nil {
+		return nil, err
+	}
+	rangeRepairsTotal.WithLabelValues(name).Inc()
+	result.Updated = true
+	return result, nil
+}
+
+// rebuildAllocation computes the allocation bitmap for the given range from
+// the UID range annotations of the given namespaces. It returns the bitmap,
+// the number of allocated blocks, and the sorted names of namespaces whose
+// annotation could not be matched to a block of the range.
+func rebuildAllocation(r *uid.Range, namespaces []corev1.Namespace) (*big.Int, int, []string) {
+	bitmap := big.NewInt(0)
+	allocated := 0
+	var invalid []string
+	for i := range namespaces {
+		ns := &namespaces[i]
+		value, ok := ns.Annotations[securityv1.UIDRangeAnnotation]
+		if !ok {
+			continue
+		}
+		block, err := uid.ParseBlock(value)
+		if err != nil {
+			invalid = append(invalid, ns.Name)
+			continue
+		}
+		ok, offset := r.Offset(block)
+		if !ok {
+			invalid = append(invalid, ns.Name)
+			continue
+		}
+		if bitmap.Bit(int(offset)) == 0 {
+			bitmap.SetBit(bitmap, int(offset), 1)
+			allocated++
+		}
+	}
+	sort.Strings(invalid)
+	return bitmap, allocated, invalid
+}
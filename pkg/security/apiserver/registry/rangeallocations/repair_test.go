@@ -0,0 +1,95 @@
+package rangeallocations
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	"github.com/openshift/library-go/pkg/security/uid"
+)
+
+func namespaceWithRange(name, uidRange string) corev1.Namespace {
+	ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if len(uidRange) > 0 {
+		ns.Annotations = map[string]string{securityv1.UIDRangeAnnotation: uidRange}
+	}
+	return ns
+}
+
+func TestRebuildAllocation(t *testing.T) {
+	r, err := uid.ParseRange("1000000000-1999999999/10000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		namespaces        []corev1.Namespace
+		expectedAllocated int
+		expectedBits      []int
+		expectedInvalid   []string
+	}{
+		"no namespaces": {
+			namespaces:        nil,
+			expectedAllocated: 0,
+		},
+		"namespaces without annotation are skipped": {
+			namespaces: []corev1.Namespace{
+				namespaceWithRange("plain", ""),
+				namespaceWithRange("first", "1000000000/10000"),
+			},
+			expectedAllocated: 1,
+			expectedBits:      []int{0},
+		},
+		"duplicate blocks count once": {
+			namespaces: []corev1.Namespace{
+				namespaceWithRange("a", "1000010000/10000"),
+				namespaceWithRange("b", "1000010000/10000"),
+				namespaceWithRange("c", "1000030000/10000"),
+			},
+			expectedAllocated: 2,
+			expectedBits:      []int{1, 3},
+		},
+		"unparseable and out-of-range annotations are reported": {
+			namespaces: []corev1.Namespace{
+				namespaceWithRange("bad", "not-a-range"),
+				namespaceWithRange("outside", "500/500"),
+				namespaceWithRange("ok", "1000000000/10000"),
+			},
+			expectedAllocated: 1,
+			expectedBits:      []int{0},
+			expectedInvalid:   []string{"bad", "outside"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bitmap, allocated, invalid := rebuildAllocation(r, test.namespaces)
+			if allocated != test.expectedAllocated {
+				t.Errorf("expected %d allocated blocks, got %d", test.expectedAllocated, allocated)
+			}
+			if !reflect.DeepEqual(invalid, test.expectedInvalid) {
+				t.Errorf("expected invalid %v, got %v", test.expectedInvalid, invalid)
+			}
+			for _, bit := range test.expectedBits {
+				if bitmap.Bit(bit) != 1 {
+					t.Errorf("expected bit %d to be set", bit)
+				}
+			}
+			if got := allocatedBlocks(bitmap.Bytes()); got != test.expectedAllocated {
+				t.Errorf("bitmap has %d set bits, expected %d", got, test.expectedAllocated)
+			}
+		})
+	}
+}
+
+func TestAllocatedBlocks(t *testing.T) {
+	if got := allocatedBlocks(nil); got != 0 {
+		t.Errorf("expected 0 for empty data, got %d", got)
+	}
+	if got := allocatedBlocks([]byte{0xff, 0x01}); got != 9 {
+		t.Errorf("expected 9, got %d", got)
+	}
+}
@@ -28,7 +28,7 @@ func (strategy) NamespaceScoped() bool {
 }
 
 func (strategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
-	_ = obj.(*securityapi.RangeAllocation)
+	recordRangeAllocationMetrics(obj.(*securityapi.RangeAllocation))
 }
 
 func (strategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
@@ -46,7 +46,7 @@ func (strategy) AllowCreateOnUpdate() bool {
 
 func (strategy) PrepareForUpdate(ctx context.Context, newObj, oldObj runtime.Object) {
 	_ = oldObj.(*securityapi.RangeAllocation)
-	_ = newObj.(*securityapi.RangeAllocation)
+	recordRangeAllocationMetrics(newObj.(*securityapi.RangeAllocation))
 }
 
 func (strategy) AllowUnconditionalUpdate() bool {
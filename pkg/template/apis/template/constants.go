@@ -40,6 +40,30 @@ const (
 	// advertise the template as being bindable (default is true)
 	BindableAnnotation = "template.openshift.io/bindable"
 
+	// SensitiveParametersAnnotation is a comma-separated allowlist of template
+	// parameter names whose values carry secrets semantics.  Values for these
+	// parameters are never persisted on TemplateInstance objects; they are
+	// expected to be supplied via the Secret referenced by spec.secret, and any
+	// generated values are stored only in that Secret.
+	SensitiveParametersAnnotation = "template.openshift.io/sensitive-parameters"
+
+	// ParameterValidationAnnotation carries validation rules for template
+	// parameters as a JSON object keyed by parameter name.  Each entry may set
+	// pattern (an anchored regular expression the value must match), min and
+	// max (numeric bounds; the value must parse as a number), and enum (the
+	// list of permitted values).  Rules are enforced server-side when the
+	// template is instantiated or processed; parameters with empty values are
+	// not checked, since required-ness and generation are handled separately.
+	ParameterValidationAnnotation = "template.openshift.io/parameter-validation"
+
+	// AllowedInstanceKindsAnnotation is a comma-separated allowlist, set by
+	// admins on a Namespace, of the object kinds TemplateInstances in that
+	// namespace may create.  Each entry is either a bare kind, which matches
+	// the kind in any group, or kind.group for a kind in one specific group.
+	// When present it overrides the cluster-wide allowlist; when neither is
+	// configured, instantiation is unrestricted.
+	AllowedInstanceKindsAnnotation = "template.openshift.io/allowed-instance-kinds"
+
 	// TemplateInstanceFinalizer is used to clean up the objects created by the template instance,
 	// when the template instance is deleted.
 	TemplateInstanceFinalizer = "template.openshift.io/finalizer"
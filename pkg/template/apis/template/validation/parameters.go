@@ -0,0 +1,111 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	templateapi "github.com/openshift/openshift-apiserver/pkg/template/apis/template"
+)
+
+// ParameterValidationRules describes the constraints a single template
+// parameter value must satisfy.  Rule sets are carried on the template in the
+// template.openshift.io/parameter-validation annotation as a JSON object
+// keyed by parameter name.
+type ParameterValidationRules struct {
+	// Pattern is a regular expression the value must match in full.
+	Pattern string `json:"pattern,omitempty"`
+
+	// Min is the lowest permitted value; the parameter value must parse as a
+	// number when a bound is set.
+	Min *float64 `json:"min,omitempty"`
+
+	// Max is the highest permitted value; the parameter value must parse as a
+	// number when a bound is set.
+	Max *float64 `json:"max,omitempty"`
+
+	// Enum lists the permitted values.
+	Enum []string `json:"enum,omitempty"`
+}
+
+// ParseParameterValidationRules returns the parameter validation rules
+// carried by the template's annotation, keyed by parameter name.  Templates
+// without the annotation yield an empty map.
+func ParseParameterValidationRules(template *templateapi.Template) (map[string]ParameterValidationRules, error) {
+	value, ok := template.Annotations[templateapi.ParameterValidationAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	rules := map[string]ParameterValidationRules{}
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ValidateTemplateParameterValues enforces the validation rules carried by
+// the template's parameter-validation annotation against the current
+// parameter values.  It is called at instantiation and processing time, not
+// when the template itself is stored, so templates may carry defaults that
+// their own rules would reject.  Parameters with empty values are skipped:
+// required-ness is validated separately and generated values do not exist
+// yet.
+func ValidateTemplateParameterValues(template *templateapi.Template) (allErrs field.ErrorList) {
+	rules, err := ParseParameterValidationRules(template)
+	if err != nil {
+		annotationPath := field.NewPath("metadata", "annotations").Key(templateapi.ParameterValidationAnnotation)
+		return field.ErrorList{field.Invalid(annotationPath, template.Annotations[templateapi.ParameterValidationAnnotation], fmt.Sprintf("must be a JSON object of parameter validation rules: %v", err))}
+	}
+
+	for i := range template.Parameters {
+		param := &template.Parameters[i]
+		rule, ok := rules[param.Name]
+		if !ok || len(param.Value) == 0 {
+			continue
+		}
+		allErrs = append(allErrs, validateParameterValue(param, &rule, field.NewPath("parameters").Index(i).Child("value"))...)
+	}
+	return allErrs
+}
+
+func validateParameterValue(param *templateapi.Parameter, rule *ParameterValidationRules, fldPath *field.Path) (allErrs field.ErrorList) {
+	if len(rule.Pattern) > 0 {
+		re, err := regexp.Compile("^(?:" + rule.Pattern + ")$")
+		if err != nil {
+			annotationPath := field.NewPath("metadata", "annotations").Key(templateapi.ParameterValidationAnnotation)
+			allErrs = append(allErrs, field.Invalid(annotationPath, rule.Pattern, fmt.Sprintf("invalid pattern for parameter %s: %v", param.Name, err)))
+		} else if !re.MatchString(param.Value) {
+			allErrs = append(allErrs, field.Invalid(fldPath, param.Value, fmt.Sprintf("parameter %s must match pattern %q", param.Name, rule.Pattern)))
+		}
+	}
+
+	if rule.Min != nil || rule.Max != nil {
+		value, err := strconv.ParseFloat(param.Value, 64)
+		switch {
+		case err != nil:
+			allErrs = append(allErrs, field.Invalid(fldPath, param.Value, fmt.Sprintf("parameter %s must be a number", param.Name)))
+		case rule.Min != nil && value < *rule.Min:
+			allErrs = append(allErrs, field.Invalid(fldPath, param.Value, fmt.Sprintf("parameter %s must not be less than %v", param.Name, *rule.Min)))
+		case rule.Max != nil && value > *rule.Max:
+			allErrs = append(allErrs, field.Invalid(fldPath, param.Value, fmt.Sprintf("parameter %s must not be greater than %v", param.Name, *rule.Max)))
+		}
+	}
+
+	if len(rule.Enum) > 0 {
+		found := false
+		for _, permitted := range rule.Enum {
+			if param.Value == permitted {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allErrs = append(allErrs, field.NotSupported(fldPath, param.Value, rule.Enum))
+		}
+	}
+
+	return allErrs
+}
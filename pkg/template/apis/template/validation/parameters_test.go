@@ -0,0 +1,117 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	templateapi "github.com/openshift/openshift-apiserver/pkg/template/apis/template"
+)
+
+func templateWithRules(rules string, params ...templateapi.Parameter) *templateapi.Template {
+	template := &templateapi.Template{
+		ObjectMeta: metav1.ObjectMeta{Name: "template"},
+		Parameters: params,
+	}
+	if len(rules) > 0 {
+		template.Annotations = map[string]string{templateapi.ParameterValidationAnnotation: rules}
+	}
+	return template
+}
+
+func TestValidateTemplateParameterValues(t *testing.T) {
+	tests := map[string]struct {
+		template       *templateapi.Template
+		expectedErrors []string
+	}{
+		"no annotation": {
+			template: templateWithRules("", *makeParameter("NAME", "anything")),
+		},
+		"malformed annotation": {
+			template:       templateWithRules("not-json", *makeParameter("NAME", "anything")),
+			expectedErrors: []string{"must be a JSON object"},
+		},
+		"pattern matches": {
+			template: templateWithRules(`{"NAME":{"pattern":"[a-z]+"}}`, *makeParameter("NAME", "abc")),
+		},
+		"pattern rejects and is anchored": {
+			template:       templateWithRules(`{"NAME":{"pattern":"[a-z]+"}}`, *makeParameter("NAME", "abc1")),
+			expectedErrors: []string{`must match pattern "[a-z]+"`},
+		},
+		"invalid pattern is reported against the annotation": {
+			template:       templateWithRules(`{"NAME":{"pattern":"["}}`, *makeParameter("NAME", "abc")),
+			expectedErrors: []string{"invalid pattern for parameter NAME"},
+		},
+		"value within bounds": {
+			template: templateWithRules(`{"REPLICAS":{"min":1,"max":10}}`, *makeParameter("REPLICAS", "3")),
+		},
+		"value below min": {
+			template:       templateWithRules(`{"REPLICAS":{"min":1,"max":10}}`, *makeParameter("REPLICAS", "0")),
+			expectedErrors: []string{"must not be less than 1"},
+		},
+		"value above max": {
+			template:       templateWithRules(`{"REPLICAS":{"max":10}}`, *makeParameter("REPLICAS", "11")),
+			expectedErrors: []string{"must not be greater than 10"},
+		},
+		"bounded value must be numeric": {
+			template:       templateWithRules(`{"REPLICAS":{"min":1}}`, *makeParameter("REPLICAS", "lots")),
+			expectedErrors: []string{"must be a number"},
+		},
+		"enum accepts listed value": {
+			template: templateWithRules(`{"SIZE":{"enum":["small","large"]}}`, *makeParameter("SIZE", "small")),
+		},
+		"enum rejects unlisted value": {
+			template:       templateWithRules(`{"SIZE":{"enum":["small","large"]}}`, *makeParameter("SIZE", "medium")),
+			expectedErrors: []string{"supported values"},
+		},
+		"empty values are skipped": {
+			template: templateWithRules(`{"NAME":{"pattern":"[a-z]+"}}`, *makeParameter("NAME", "")),
+		},
+		"parameters without rules are skipped": {
+			template: templateWithRules(`{"OTHER":{"pattern":"[a-z]+"}}`, *makeParameter("NAME", "ABC")),
+		},
+		"multiple failing rules are all reported": {
+			template: templateWithRules(
+				`{"NAME":{"pattern":"[a-z]+"},"REPLICAS":{"max":10}}`,
+				*makeParameter("NAME", "ABC"),
+				*makeParameter("REPLICAS", "11"),
+			),
+			expectedErrors: []string{"must match pattern", "must not be greater than 10"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs := ValidateTemplateParameterValues(test.template)
+			if len(errs) != len(test.expectedErrors) {
+				t.Fatalf("expected %d errors, got %d: %v", len(test.expectedErrors), len(errs), errs)
+			}
+			for i, expected := range test.expectedErrors {
+				if !strings.Contains(errs[i].Error(), expected) {
+					t.Errorf("expected error %d to contain %q, got %v", i, expected, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTemplateInstanceEnforcesParameterRules(t *testing.T) {
+	templateInstance := &templateapi.TemplateInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "test"},
+		Spec: templateapi.TemplateInstanceSpec{
+			Template: *templateWithRules(`{"NAME":{"pattern":"[a-z]+"}}`, *makeParameter("NAME", "ABC")),
+			Requester: &templateapi.TemplateInstanceRequester{
+				Username: "user",
+			},
+		},
+	}
+
+	errs := ValidateTemplateInstance(templateInstance)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errs), errs)
+	}
+	if expected := "spec.template.parameters[0].value"; errs[0].Field != expected {
+		t.Errorf("expected error on %s, got %s", expected, errs[0].Field)
+	}
+}
@@ -29,7 +29,9 @@ func ValidateParameter(param *templateapi.Parameter, fldPath *field.Path) (allEr
 
 // ValidateProcessedTemplate tests if required fields in the Template are set for processing
 func ValidateProcessedTemplate(template *templateapi.Template) field.ErrorList {
-	return validateTemplateBody(template)
+	allErrs := validateTemplateBody(template)
+	allErrs = append(allErrs, ValidateTemplateParameterValues(template)...)
+	return allErrs
 }
 
 var ValidateTemplateName = apimachineryvalidation.NameIsDNSSubdomain
@@ -73,6 +75,13 @@ func ValidateTemplateInstance(templateInstance *templateapi.TemplateInstance) (a
 		err.Field = "spec.template." + err.Field
 		allErrs = append(allErrs, err)
 	}
+	// instantiation is the point where parameter values take effect, so the
+	// template's parameter validation rules are enforced here rather than when
+	// the template itself is stored
+	for _, err := range ValidateTemplateParameterValues(templateCopy) {
+		err.Field = "spec.template." + err.Field
+		allErrs = append(allErrs, err)
+	}
 	if templateInstance.Spec.Secret != nil {
 		if templateInstance.Spec.Secret.Name != "" {
 			for _, msg := range validation.ValidateSecretName(templateInstance.Spec.Secret.Name, false) {
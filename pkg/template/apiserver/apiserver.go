@@ -9,18 +9,25 @@ import (
 	"k8s.io/apiserver/pkg/registry/rest"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	restclient "k8s.io/client-go/rest"
 
 	templateapiv1 "github.com/openshift/api/template/v1"
 	brokertemplateinstanceetcd "github.com/openshift/openshift-apiserver/pkg/template/apiserver/registry/brokertemplateinstance/etcd"
 	templateregistry "github.com/openshift/openshift-apiserver/pkg/template/apiserver/registry/template"
 	templateetcd "github.com/openshift/openshift-apiserver/pkg/template/apiserver/registry/template/etcd"
+	"github.com/openshift/openshift-apiserver/pkg/template/apiserver/registry/templateinstance"
 	templateinstanceetcd "github.com/openshift/openshift-apiserver/pkg/template/apiserver/registry/templateinstance/etcd"
 )
 
 type ExtraConfig struct {
 	KubeAPIServerClientConfig *restclient.Config
 
+	// TemplateInstanceAllowedKinds is the cluster-wide allowlist of object
+	// kinds templates may create, overridable per namespace by annotation.
+	// Empty means unrestricted.
+	TemplateInstanceAllowedKinds []string
+
 	// TODO these should all become local eventually
 	Scheme *runtime.Scheme
 	Codecs serializer.CodecFactory
@@ -98,12 +105,16 @@ func (c *completedConfig) newV1RESTStorage() (map[string]rest.Storage, error) {
 	if err != nil {
 		return nil, err
 	}
+	coreClient, err := corev1client.NewForConfig(c.ExtraConfig.KubeAPIServerClientConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	templateStorage, err := templateetcd.NewREST(c.GenericConfig.RESTOptionsGetter)
 	if err != nil {
 		return nil, err
 	}
-	templateInstanceStorage, templateInstanceStatusStorage, err := templateinstanceetcd.NewREST(c.GenericConfig.RESTOptionsGetter, authorizationClient)
+	templateInstanceStorage, templateInstanceStatusStorage, err := templateinstanceetcd.NewREST(c.GenericConfig.RESTOptionsGetter, authorizationClient, coreClient, templateinstance.ParseAllowedKinds(c.ExtraConfig.TemplateInstanceAllowedKinds))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,86 @@
+package templateinstance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kutilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	templateapi "github.com/openshift/openshift-apiserver/pkg/template/apis/template"
+)
+
+// ParseAllowedKinds flattens configured allowlist entries into a set.  Each
+// value may carry several comma-separated entries; an entry is either a bare
+// kind, which matches the kind in any group, or kind.group for a kind in one
+// specific group.  An empty set means instantiation is unrestricted.
+func ParseAllowedKinds(values []string) sets.String {
+	allowed := sets.NewString()
+	for _, value := range values {
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				allowed.Insert(entry)
+			}
+		}
+	}
+	return allowed
+}
+
+// allowedKindsFor returns the allowlist in effect for the namespace: the
+// namespace annotation if it carries one, otherwise the cluster-wide default.
+func (s *templateInstanceStrategy) allowedKindsFor(ctx context.Context, namespace string) (sets.String, *field.Error) {
+	if s.nsClient != nil && namespace != "" {
+		ns, err := s.nsClient.Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			return nil, field.InternalError(field.NewPath("metadata.namespace"), err)
+		}
+		if value, ok := ns.Annotations[templateapi.AllowedInstanceKindsAnnotation]; ok {
+			return ParseAllowedKinds([]string{value}), nil
+		}
+	}
+	return s.defaultAllowedKinds, nil
+}
+
+// validateAllowedKinds rejects the template if it contains objects of kinds
+// the allowlist in effect does not permit, naming every offending kind so the
+// requester does not have to discover them one rejection at a time.
+func (s *templateInstanceStrategy) validateAllowedKinds(ctx context.Context, templateInstance *templateapi.TemplateInstance) field.ErrorList {
+	allowed, fieldErr := s.allowedKindsFor(ctx, templateInstance.Namespace)
+	if fieldErr != nil {
+		return field.ErrorList{fieldErr}
+	}
+	if allowed.Len() == 0 {
+		return nil
+	}
+
+	// decode a copy of the objects; DecodeList replaces them in place
+	template := templateInstance.Spec.Template.DeepCopy()
+	if errs := runtime.DecodeList(template.Objects, unstructured.UnstructuredJSONScheme); len(errs) != 0 {
+		return field.ErrorList{field.InternalError(field.NewPath("spec.template.objects"), kutilerrors.NewAggregate(errs))}
+	}
+
+	disallowed := sets.NewString()
+	for _, obj := range template.Objects {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		name := gvk.Kind
+		if gvk.Group != "" {
+			name = gvk.Kind + "." + gvk.Group
+		}
+		if allowed.Has(gvk.Kind) || allowed.Has(name) {
+			continue
+		}
+		disallowed.Insert(name)
+	}
+	if disallowed.Len() == 0 {
+		return nil
+	}
+
+	return field.ErrorList{field.Forbidden(field.NewPath("spec.template.objects"),
+		fmt.Sprintf("template contains object kinds that may not be instantiated here: %s (allowed kinds: %s)",
+			strings.Join(disallowed.List(), ", "), strings.Join(allowed.List(), ", ")))}
+}
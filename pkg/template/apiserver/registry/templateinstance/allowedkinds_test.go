@@ -0,0 +1,114 @@
+package templateinstance
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kfake "k8s.io/client-go/kubernetes/fake"
+
+	templateapi "github.com/openshift/openshift-apiserver/pkg/template/apis/template"
+)
+
+func TestParseAllowedKinds(t *testing.T) {
+	parsed := ParseAllowedKinds([]string{"ConfigMap, Service", "Route.route.openshift.io", "", " "})
+	expected := sets.NewString("ConfigMap", "Service", "Route.route.openshift.io")
+	if !parsed.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected.List(), parsed.List())
+	}
+}
+
+func TestValidateAllowedKinds(t *testing.T) {
+	object := func(apiVersion, kind string) runtime.Object {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+		}}
+	}
+	instance := func(namespace string, objects ...runtime.Object) *templateapi.TemplateInstance {
+		return &templateapi.TemplateInstance{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "instance"},
+			Spec: templateapi.TemplateInstanceSpec{
+				Template: templateapi.Template{
+					ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "template"},
+					Objects:    objects,
+				},
+			},
+		}
+	}
+	annotatedNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "restricted",
+			Annotations: map[string]string{templateapi.AllowedInstanceKindsAnnotation: "ConfigMap"},
+		},
+	}
+	plainNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "plain"},
+	}
+
+	tests := []struct {
+		name             string
+		defaultAllowed   sets.String
+		templateInstance *templateapi.TemplateInstance
+		expectedError    []string
+	}{
+		{
+			name:             "no allowlist configured",
+			templateInstance: instance("plain", object("v1", "Secret"), object("route.openshift.io/v1", "Route")),
+		},
+		{
+			name:             "cluster default permits listed kinds",
+			defaultAllowed:   sets.NewString("Secret", "Route.route.openshift.io"),
+			templateInstance: instance("plain", object("v1", "Secret"), object("route.openshift.io/v1", "Route")),
+		},
+		{
+			name:             "cluster default rejects, naming every kind",
+			defaultAllowed:   sets.NewString("ConfigMap"),
+			templateInstance: instance("plain", object("v1", "Secret"), object("route.openshift.io/v1", "Route")),
+			expectedError:    []string{"Route.route.openshift.io", "Secret", "allowed kinds: ConfigMap"},
+		},
+		{
+			name:             "qualified entry does not match other groups",
+			defaultAllowed:   sets.NewString("Route.route.openshift.io"),
+			templateInstance: instance("plain", object("example.com/v1", "Route")),
+			expectedError:    []string{"Route.example.com"},
+		},
+		{
+			name:             "namespace annotation overrides a permissive default",
+			defaultAllowed:   sets.NewString("Secret"),
+			templateInstance: instance("restricted", object("v1", "Secret")),
+			expectedError:    []string{"Secret", "allowed kinds: ConfigMap"},
+		},
+		{
+			name:             "namespace annotation overrides an absent default",
+			templateInstance: instance("restricted", object("v1", "ConfigMap")),
+		},
+	}
+
+	for _, test := range tests {
+		client := kfake.NewSimpleClientset(annotatedNamespace, plainNamespace)
+		strategy := NewStrategy(nil, client.CoreV1(), test.defaultAllowed)
+
+		errs := strategy.validateAllowedKinds(context.Background(), test.templateInstance)
+		if len(test.expectedError) == 0 {
+			if len(errs) != 0 {
+				t.Errorf("%s: unexpected errors: %v", test.name, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 {
+			t.Errorf("%s: expected exactly one error, got %v", test.name, errs)
+			continue
+		}
+		for _, fragment := range test.expectedError {
+			if !strings.Contains(errs[0].Error(), fragment) {
+				t.Errorf("%s: expected error to contain %q, got %v", test.name, fragment, errs[0])
+			}
+		}
+	}
+}
@@ -2,17 +2,26 @@ package etcd
 
 import (
 	"context"
+	"fmt"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/generic"
 	"k8s.io/apiserver/pkg/registry/generic/registry"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/util/dryrun"
 	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/kubernetes/pkg/printers"
 	printerstorage "k8s.io/kubernetes/pkg/printers/storage"
 
 	"github.com/openshift/api/template"
+	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
 
 	templateapi "github.com/openshift/openshift-apiserver/pkg/template/apis/template"
 	"github.com/openshift/openshift-apiserver/pkg/template/apiserver/registry/templateinstance"
@@ -22,13 +31,17 @@ import (
 // REST implements a RESTStorage for templateinstances against etcd
 type REST struct {
 	*registry.Store
+	coreClient corev1client.CoreV1Interface
+	sarClient  authorizationclient.SubjectAccessReviewInterface
 }
 
 var _ rest.StandardStorage = &REST{}
 
-// NewREST returns a RESTStorage object that will work against templateinstances.
-func NewREST(optsGetter generic.RESTOptionsGetter, authorizationClient authorizationclient.AuthorizationV1Interface) (*REST, *StatusREST, error) {
-	strategy := templateinstance.NewStrategy(authorizationClient)
+// NewREST returns a RESTStorage object that will work against
+// templateinstances.  allowedKinds is the cluster-wide allowlist of object
+// kinds templates may create, overridable per namespace by annotation.
+func NewREST(optsGetter generic.RESTOptionsGetter, authorizationClient authorizationclient.AuthorizationV1Interface, coreClient corev1client.CoreV1Interface, allowedKinds sets.String) (*REST, *StatusREST, error) {
+	strategy := templateinstance.NewStrategy(authorizationClient, coreClient, allowedKinds)
 
 	store := &registry.Store{
 		NewFunc:                  func() runtime.Object { return &templateapi.TemplateInstance{} },
@@ -50,7 +63,75 @@ func NewREST(optsGetter generic.RESTOptionsGetter, authorizationClient authoriza
 	statusStore := *store
 	statusStore.UpdateStrategy = templateinstance.StatusStrategy
 
-	return &REST{store}, &StatusREST{&statusStore}, nil
+	return &REST{store, coreClient, authorizationClient.SubjectAccessReviews()}, &StatusREST{&statusStore}, nil
+}
+
+// Create runs a regular create, and for dry-run requests additionally renders
+// the template the way the template instance controller would: parameters are
+// substituted, including values from the referenced secret, and the fully
+// rendered objects are returned in spec.template.objects without anything
+// being created.  This gives clients such as the console a server-side
+// preview of the exact objects an instantiation would produce.
+func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	created, err := r.Store.Create(ctx, obj, createValidation, options)
+	if err != nil || !dryrun.IsDryRun(options.DryRun) {
+		return created, err
+	}
+
+	templateInstance := created.(*templateapi.TemplateInstance)
+	var secret *corev1.Secret
+	if templateInstance.Spec.Secret != nil && templateInstance.Spec.Secret.Name != "" && r.coreClient != nil {
+		// the render below substitutes the secret's values into the returned
+		// template, so the requester must be allowed to read the secret
+		// themselves; the fetch uses the apiserver's own privileged client
+		if err := r.authorizeSecretRead(ctx, templateInstance.Namespace, templateInstance.Spec.Secret.Name); err != nil {
+			return nil, err
+		}
+		secret, err = r.coreClient.Secrets(templateInstance.Namespace).Get(ctx, templateInstance.Spec.Secret.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rendered, errs := templateinstance.RenderTemplate(&templateInstance.Spec.Template, secret)
+	if len(errs) > 0 {
+		return nil, kapierrors.NewInvalid(template.Kind("TemplateInstance"), templateInstance.Name, errs)
+	}
+	templateInstance.Spec.Template = *rendered
+	return templateInstance, nil
+}
+
+// authorizeSecretRead checks that the requesting user may get the named
+// secret before a dry-run render reads it on their behalf.
+func (r *REST) authorizeSecretRead(ctx context.Context, namespace, name string) error {
+	u, ok := apirequest.UserFrom(ctx)
+	if !ok || r.sarClient == nil {
+		return kapierrors.NewForbidden(corev1.Resource("secrets"), name, fmt.Errorf("unable to verify access to the referenced secret"))
+	}
+	res, err := r.sarClient.Create(
+		ctx,
+		authorizationutil.AddUserToSAR(
+			u,
+			&authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace: namespace,
+						Verb:      "get",
+						Resource:  "secrets",
+						Name:      name,
+					},
+				},
+			},
+		),
+		metav1.CreateOptions{},
+	)
+	if err != nil {
+		return err
+	}
+	if !res.Status.Allowed {
+		return kapierrors.NewForbidden(corev1.Resource("secrets"), name, fmt.Errorf("you do not have permission to get the secret referenced by the template instance"))
+	}
+	return nil
 }
 
 // StatusREST implements the REST endpoint for changing the status of a templateInstance.
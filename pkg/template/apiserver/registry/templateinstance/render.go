@@ -0,0 +1,99 @@
+package templateinstance
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	templatev1 "github.com/openshift/api/template/v1"
+	"github.com/openshift/library-go/pkg/template/generator"
+	"github.com/openshift/library-go/pkg/template/templateprocessing"
+	templateapi "github.com/openshift/openshift-apiserver/pkg/template/apis/template"
+	templatev1conversion "github.com/openshift/openshift-apiserver/pkg/template/apis/template/v1"
+)
+
+// RenderTemplate performs the parameter substitution the template instance
+// controller would perform when instantiating, returning the fully rendered
+// template without creating anything.  Parameter values are taken from the
+// secret referenced by the TemplateInstance, whose keys name the parameters
+// they fill, the same contract the controller applies.  Any processing or
+// per-object validation failures are returned as field errors under
+// spec.template so a caller previewing the output sees every problem at once.
+func RenderTemplate(tpl *templateapi.Template, secret *corev1.Secret) (*templateapi.Template, field.ErrorList) {
+	externalTemplate := &templatev1.Template{}
+	if err := templatev1conversion.Convert_template_Template_To_v1_Template(tpl, externalTemplate, nil); err != nil {
+		return nil, field.ErrorList{field.InternalError(field.NewPath("spec.template"), err)}
+	}
+
+	allErrs := field.ErrorList{}
+	if secret != nil {
+		for name, value := range secret.Data {
+			if param := templateprocessing.GetParameterByName(externalTemplate, name); param != nil {
+				param.Value = string(value)
+				param.Generate = ""
+			} else {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec.secret"), name, "unknown parameter name"))
+			}
+		}
+		if len(allErrs) > 0 {
+			return nil, allErrs
+		}
+	}
+
+	generators := map[string]generator.Generator{
+		"expression": generator.NewExpressionValueGenerator(rand.New(rand.NewSource(time.Now().UnixNano()))),
+	}
+	processor := templateprocessing.NewProcessor(generators)
+	if errs := processor.Process(externalTemplate); len(errs) > 0 {
+		return nil, errs
+	}
+
+	if errs := validateRenderedObjects(externalTemplate); len(errs) > 0 {
+		return nil, errs
+	}
+
+	// encode the processed objects with the unstructured codec before the REST
+	// layer gets its shot at encoding, the same as the processedtemplates
+	// endpoint does.
+	for i := range externalTemplate.Objects {
+		externalTemplate.Objects[i].Object = runtime.NewEncodable(unstructured.UnstructuredJSONScheme, externalTemplate.Objects[i].Object)
+		externalTemplate.Objects[i].Raw = nil
+	}
+
+	internalTemplate := &templateapi.Template{}
+	if err := templatev1conversion.Convert_v1_Template_To_template_Template(externalTemplate, internalTemplate, nil); err != nil {
+		return nil, field.ErrorList{field.InternalError(field.NewPath("spec.template"), err)}
+	}
+	return internalTemplate, nil
+}
+
+// validateRenderedObjects checks each rendered object individually so that a
+// preview reports which entries in the object list would be rejected on
+// instantiation.
+func validateRenderedObjects(template *templatev1.Template) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i := range template.Objects {
+		path := field.NewPath("spec.template.objects").Index(i)
+		u, ok := template.Objects[i].Object.(*unstructured.Unstructured)
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(path, fmt.Sprintf("%T", template.Objects[i].Object), "rendered object is not unstructured"))
+			continue
+		}
+		gvk := u.GroupVersionKind()
+		if gvk.Kind == "" {
+			allErrs = append(allErrs, field.Required(path.Child("kind"), ""))
+		}
+		if gvk.Version == "" {
+			allErrs = append(allErrs, field.Required(path.Child("apiVersion"), ""))
+		}
+		if u.GetName() == "" && u.GetGenerateName() == "" {
+			allErrs = append(allErrs, field.Required(path.Child("metadata.name"), "name or generateName is required"))
+		}
+	}
+	return allErrs
+}
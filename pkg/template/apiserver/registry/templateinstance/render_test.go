@@ -0,0 +1,100 @@
+package templateinstance
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	templateapi "github.com/openshift/openshift-apiserver/pkg/template/apis/template"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	template := func(objects ...runtime.Object) *templateapi.Template {
+		return &templateapi.Template{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "template"},
+			Parameters: []templateapi.Parameter{
+				{Name: "NAME", Value: "default"},
+			},
+			Objects: objects,
+		}
+	}
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "${NAME}-cm"},
+	}}
+	nameless := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{},
+	}}
+
+	tests := []struct {
+		name          string
+		template      *templateapi.Template
+		secret        *corev1.Secret
+		expectedName  string
+		expectedError string
+	}{
+		{
+			name:         "parameter default is substituted",
+			template:     template(configMap),
+			expectedName: "default-cm",
+		},
+		{
+			name:         "secret value overrides the default",
+			template:     template(configMap),
+			secret:       &corev1.Secret{Data: map[string][]byte{"NAME": []byte("fromsecret")}},
+			expectedName: "fromsecret-cm",
+		},
+		{
+			name:          "unknown secret key is rejected",
+			template:      template(configMap),
+			secret:        &corev1.Secret{Data: map[string][]byte{"BOGUS": []byte("x")}},
+			expectedError: "unknown parameter name",
+		},
+		{
+			name:          "nameless object is reported with its index",
+			template:      template(configMap, nameless),
+			expectedError: "spec.template.objects[1].metadata.name",
+		},
+	}
+
+	for _, test := range tests {
+		rendered, errs := RenderTemplate(test.template, test.secret)
+		if len(test.expectedError) > 0 {
+			if len(errs) == 0 {
+				t.Errorf("%s: expected an error, got none", test.name)
+			} else if !strings.Contains(errs.ToAggregate().Error(), test.expectedError) {
+				t.Errorf("%s: expected error to contain %q, got %v", test.name, test.expectedError, errs)
+			}
+			continue
+		}
+		if len(errs) > 0 {
+			t.Errorf("%s: unexpected errors: %v", test.name, errs)
+			continue
+		}
+		if len(rendered.Objects) != 1 {
+			t.Errorf("%s: expected one rendered object, got %d", test.name, len(rendered.Objects))
+			continue
+		}
+		data, err := json.Marshal(rendered.Objects[0])
+		if err != nil {
+			t.Errorf("%s: unable to encode rendered object: %v", test.name, err)
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(data); err != nil {
+			t.Errorf("%s: unable to decode rendered object: %v", test.name, err)
+			continue
+		}
+		if obj.GetName() != test.expectedName {
+			t.Errorf("%s: expected rendered name %q, got %q", test.name, test.expectedName, obj.GetName())
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package templateinstance
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	templateapi "github.com/openshift/openshift-apiserver/pkg/template/apis/template"
+)
+
+// sensitiveParameterNames returns the set of parameter names marked sensitive
+// via the template.openshift.io/sensitive-parameters annotation on the
+// template.
+func sensitiveParameterNames(template *templateapi.Template) sets.String {
+	names := sets.NewString()
+	for _, name := range strings.Split(template.Annotations[templateapi.SensitiveParametersAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names.Insert(name)
+		}
+	}
+	return names
+}
+
+// redactSensitiveParameters clears the values of all parameters marked
+// sensitive so that they are never persisted in plaintext on the
+// TemplateInstance object or surfaced in audit logs.  Callers are expected to
+// supply values for sensitive parameters via the Secret referenced by
+// spec.secret, and the template instance controller stores any generated
+// values there as well.
+func redactSensitiveParameters(template *templateapi.Template) {
+	names := sensitiveParameterNames(template)
+	if len(names) == 0 {
+		return
+	}
+	for i := range template.Parameters {
+		if names.Has(template.Parameters[i].Name) {
+			template.Parameters[i].Value = ""
+		}
+	}
+}
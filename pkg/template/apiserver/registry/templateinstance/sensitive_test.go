@@ -0,0 +1,73 @@
+package templateinstance
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	templateapi "github.com/openshift/openshift-apiserver/pkg/template/apis/template"
+)
+
+func TestRedactSensitiveParameters(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		parameters []templateapi.Parameter
+		expected   []templateapi.Parameter
+	}{
+		{
+			name:       "no annotation",
+			parameters: []templateapi.Parameter{{Name: "PASSWORD", Value: "hunter2"}},
+			expected:   []templateapi.Parameter{{Name: "PASSWORD", Value: "hunter2"}},
+		},
+		{
+			name:       "single sensitive parameter",
+			annotation: "PASSWORD",
+			parameters: []templateapi.Parameter{
+				{Name: "PASSWORD", Value: "hunter2", Generate: "expression", From: "[a-z]{8}"},
+				{Name: "USERNAME", Value: "admin"},
+			},
+			expected: []templateapi.Parameter{
+				{Name: "PASSWORD", Generate: "expression", From: "[a-z]{8}"},
+				{Name: "USERNAME", Value: "admin"},
+			},
+		},
+		{
+			name:       "multiple names with whitespace",
+			annotation: "PASSWORD, TOKEN ,",
+			parameters: []templateapi.Parameter{
+				{Name: "PASSWORD", Value: "hunter2"},
+				{Name: "TOKEN", Value: "abcdef"},
+				{Name: "REPLICAS", Value: "3"},
+			},
+			expected: []templateapi.Parameter{
+				{Name: "PASSWORD"},
+				{Name: "TOKEN"},
+				{Name: "REPLICAS", Value: "3"},
+			},
+		},
+		{
+			name:       "annotation names missing parameter",
+			annotation: "NOSUCH",
+			parameters: []templateapi.Parameter{{Name: "PASSWORD", Value: "hunter2"}},
+			expected:   []templateapi.Parameter{{Name: "PASSWORD", Value: "hunter2"}},
+		},
+	}
+
+	for _, test := range tests {
+		template := &templateapi.Template{
+			ObjectMeta: metav1.ObjectMeta{},
+			Parameters: test.parameters,
+		}
+		if test.annotation != "" {
+			template.Annotations = map[string]string{templateapi.SensitiveParametersAnnotation: test.annotation}
+		}
+
+		redactSensitiveParameters(template)
+
+		if !reflect.DeepEqual(template.Parameters, test.expected) {
+			t.Errorf("%s: expected %#v, got %#v", test.name, test.expected, template.Parameters)
+		}
+	}
+}
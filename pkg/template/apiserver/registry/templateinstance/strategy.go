@@ -8,11 +8,13 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	kutilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/authentication/user"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/storage/names"
 	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
 	kapihelper "k8s.io/kubernetes/pkg/apis/core/helper"
 
@@ -26,10 +28,16 @@ type templateInstanceStrategy struct {
 	runtime.ObjectTyper
 	names.NameGenerator
 	authorizationClient authorizationclient.AuthorizationV1Interface
+	nsClient            corev1client.NamespacesGetter
+	defaultAllowedKinds sets.String
 }
 
-func NewStrategy(authorizationClient authorizationclient.AuthorizationV1Interface) *templateInstanceStrategy {
-	return &templateInstanceStrategy{legacyscheme.Scheme, names.SimpleNameGenerator, authorizationClient}
+// NewStrategy returns the strategy for TemplateInstances.  defaultAllowedKinds
+// is the cluster-wide allowlist of object kinds templates may create; the
+// namespace client is used to look up the per-namespace allowlist annotation
+// that overrides it.
+func NewStrategy(authorizationClient authorizationclient.AuthorizationV1Interface, nsClient corev1client.NamespacesGetter, defaultAllowedKinds sets.String) *templateInstanceStrategy {
+	return &templateInstanceStrategy{legacyscheme.Scheme, names.SimpleNameGenerator, authorizationClient, nsClient, defaultAllowedKinds}
 }
 
 // NamespaceScoped is true for templateinstances.
@@ -64,6 +72,10 @@ func (templateInstanceStrategy) PrepareForCreate(ctx context.Context, obj runtim
 		}
 	}
 
+	// never persist plaintext values for parameters marked sensitive; they are
+	// carried by the secret referenced from spec.secret instead
+	redactSensitiveParameters(&templateInstance.Spec.Template)
+
 	templateInstance.Status = templateapi.TemplateInstanceStatus{}
 }
 
@@ -77,6 +89,7 @@ func (s *templateInstanceStrategy) Validate(ctx context.Context, obj runtime.Obj
 	templateInstance := obj.(*templateapi.TemplateInstance)
 	allErrs := validation.ValidateTemplateInstance(templateInstance)
 	allErrs = append(allErrs, s.validateImpersonation(templateInstance, user)...)
+	allErrs = append(allErrs, s.validateAllowedKinds(ctx, templateInstance)...)
 
 	return allErrs
 }